@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,19 +12,36 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/webhooks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/generic_oidc"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/local"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/di_containers"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/notify"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/secrets"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/server"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
 	storage "github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/postgres"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/postgres/utils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/tracing"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/worker"
 )
 
+// rotateAESKeyCommand Подкоманда перешифрования паролей в БД текущим AES-ключом.
+const rotateAESKeyCommand = "rotate-aes-key"
+
+// rotateAESKeyBatchSize Количество паролей, перешифровываемых в одной транзакции.
+const rotateAESKeyBatchSize = 100
+
 // "Сборка" и запуск проекта.
 func main() {
 	// recover для логирования паник в main
@@ -41,6 +57,12 @@ func main() {
 		log.Println("Не удалось загрузить .env:", errEnv)
 	}
 
+	// подкоманда убирается из аргументов до разбора флагов, флаги после нее применяются как обычно
+	rotateAESKey := len(os.Args) > 1 && os.Args[1] == rotateAESKeyCommand
+	if rotateAESKey {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// инициализация конфигурации сервера
 	srvConfig := config.InitConfig()
 
@@ -49,35 +71,100 @@ func main() {
 	// отложенное закрытие ресурса (актуально если используется файл для логирования)
 	defer logger.Log.(*logger.SlogAdapter).Close()
 
-	// декодируем AES-ключ, используемый для шифрования данных в БД
-	AESKeyStr := srvConfig.AESKey
-	AESKeyBytes, err := base64.StdEncoding.DecodeString(AESKeyStr)
+	// роль по умолчанию должна быть известна приложению, иначе пользователи без ролей в Keycloak не получат доступа
+	if !models.IsValidRole(models.Role(srvConfig.DefaultRole)) {
+		logger.Log.Error("Неизвестная роль по умолчанию", logger.String("role", srvConfig.DefaultRole))
+		os.Exit(1)
+	}
+
+	if !models.IsValidWinRMAuth(srvConfig.WinRMAuth) {
+		logger.Log.Error("Неизвестный механизм аутентификации WinRM", logger.String("auth", srvConfig.WinRMAuth))
+		os.Exit(1)
+	}
+
+	if _, err := netutils.ParseIPNets(srvConfig.TrustedProxies); err != nil {
+		logger.Log.Error("Неверный список доверенных прокси", logger.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	// без секрета события Keycloak не принимаются: иначе любой мог бы отправить поддельное событие удаления пользователя
+	if srvConfig.WebhookSecret == "" {
+		if srvConfig.AuthProvider == "keycloak" {
+			logger.Log.Warn("Секрет вебхуков Keycloak не задан, эндпоинт /keycloak-events отключен")
+		}
+	} else {
+		if srvConfig.WebhookAuthMode != webhooks.AuthModeHMAC && srvConfig.WebhookAuthMode != webhooks.AuthModeSecret {
+			logger.Log.Error("Неизвестный способ проверки вебхуков", logger.String("mode", srvConfig.WebhookAuthMode))
+			os.Exit(1)
+		}
+
+		if _, err := netutils.ParseIPNets(srvConfig.WebhookAllowedIPs); err != nil {
+			logger.Log.Error("Неверный список адресов отправителей вебхуков", logger.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	// инициализация трассировки OpenTelemetry (без адреса коллектора экспорт отключен)
+	tracingShutdown, err := tracing.Init(context.Background(), tracing.Config{
+		Endpoint:    srvConfig.TracingEndpoint,
+		Insecure:    srvConfig.TracingInsecure,
+		SampleRatio: srvConfig.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Log.Error("Не удалось инициализировать трассировку", logger.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	// набор AES-ключей для шифрования данных в БД: текущий ключ и предыдущие версии для расшифровки
+	AESKeys, err := utils.ParseKeyRing(srvConfig.AESKey, srvConfig.AESKeyID, srvConfig.AESPreviousKeys)
 	if err != nil {
-		logger.Log.Error("Не удалось декодировать AES-ключ из конфигурации", logger.String("err", err.Error()))
+		logger.Log.Error("Неверная конфигурация AES-ключей", logger.String("err", err.Error()))
 		os.Exit(1)
 	}
 
-	// инициализация хранилища (PostgreSQL) с переданным AES-ключом
-	pgStorage, err := postgres.InitStorage(srvConfig.DatabaseURI, AESKeyBytes)
+	// инициализация хранилища (PostgreSQL) с переданными AES-ключами
+	pgStorage, err := postgres.InitStorage(srvConfig.DatabaseURI, AESKeys)
 	if err != nil {
 		logger.Log.Error("Не удалось инициировать хранилище (БД)", logger.String("err", err.Error()))
 		os.Exit(1)
 	}
 
+	// перешифрование паролей текущим ключом выполняется отдельным запуском, сервер при этом не стартует
+	if rotateAESKey {
+		count, err := pgStorage.RotateAESKey(context.Background(), rotateAESKeyBatchSize)
+		if err != nil {
+			logger.Log.Error("Не удалось перешифровать пароли", logger.Int("count", count), logger.String("err", err.Error()))
+			os.Exit(1)
+		}
+
+		logger.Log.Info("Пароли перешифрованы текущим AES-ключом",
+			logger.Int("key_id", AESKeys.CurrentID()), logger.Int("count", count))
+		pgStorage.DB.Close()
+		return
+	}
+
 	var handlersStorage storage.Storage = pgStorage
 	var workersStorage storage.WorkerStorage = pgStorage
 
-	authAdapter, err := keycloak.NewKeycloakAdapter(context.Background(), keycloak.KeycloakConfig{
-		IssuerURL:       srvConfig.KeycloakBaseURL + "/realms/" + srvConfig.KeycloakRealmName,
-		ClientID:        srvConfig.KeycloakClientID,
-		SkipIssuerCheck: srvConfig.SkipIssuerCheck,
-	})
-
+	authAdapter, err := newAuthProvider(context.Background(), srvConfig)
 	if err != nil {
-		logger.Log.Warn("Не удалось инициализировать адаптер Keycloak: %v", logger.String("err", err.Error()))
+		logger.Log.Error("Не удалось инициализировать провайдера аутентификации",
+			logger.String("provider", srvConfig.AuthProvider),
+			logger.String("err", err.Error()))
 		os.Exit(1)
 	}
 
+	// при встроенной аутентификации создаем первого администратора, роль admin ему назначается всегда
+	if srvConfig.AuthProvider == "local" {
+		err = local.BootstrapAdmin(context.Background(), pgStorage, srvConfig.LocalAdminLogin, srvConfig.LocalAdminPassword)
+		if err != nil {
+			logger.Log.Error("Не удалось создать первого администратора", logger.String("err", err.Error()))
+			os.Exit(1)
+		}
+
+		srvConfig.AdminUsers = append(srvConfig.AdminUsers, srvConfig.LocalAdminLogin)
+	}
+
 	var broadcaster broadcast.Broadcaster
 
 	if srvConfig.WebInterface {
@@ -87,7 +174,7 @@ func main() {
 		// Если планируется использовать только API без фронтенда - broadcaster можно убрать из зависимостей AppHandler.
 		// Инициализировав broadcaster в main далее он используется в ServiceBroadcastWorker.
 		broadcaster = broadcast.NewR3labsSSEAdapter(
-			broadcast.MakeTopicResolver(authAdapter),
+			broadcast.MakeTopicResolver(authAdapter, handlersStorage, srvConfig.SSECookieAuth),
 		)
 	} else {
 		broadcaster = broadcast.NewNoopAdapter(func(r *http.Request) (string, error) { return "noop", nil })
@@ -107,10 +194,59 @@ func main() {
 	// создаем сетевой чекер
 	netChecker := netutils.NewNetworkChecker()
 
+	// экспорт событий безопасности (аудит, неудачная аутентификация) в syslog/SIEM, если задан адрес приемника
+	var eventSink siem.Sink = siem.NewNoopSink()
+	var siemExporter *siem.Exporter
+
+	if srvConfig.SyslogAddress != "" {
+		siemExporter, err = siem.NewExporter(siem.Config{
+			Address:     srvConfig.SyslogAddress,
+			Network:     srvConfig.SyslogNetwork,
+			Format:      srvConfig.SyslogFormat,
+			TLSCAFile:   srvConfig.SyslogTLSCAFile,
+			TLSInsecure: srvConfig.SyslogTLSInsecure,
+			BufferSize:  srvConfig.SyslogBufferSize,
+		})
+		if err != nil {
+			logger.Log.Error("Не удалось настроить экспорт событий в syslog", logger.String("err", err.Error()))
+			os.Exit(1)
+		}
+		eventSink = siemExporter
+	}
+
+	// источник паролей серверов: по умолчанию пароли хранятся в БД, при использовании Vault
+	// серверы с secret_path получают учетные данные из KV-хранилища
+	var secretProvider secrets.Provider = secrets.NewDatabaseProvider()
+	var vaultProvider *secrets.VaultProvider
+
+	switch srvConfig.SecretProvider {
+	case secrets.ProviderPostgres:
+		// пароли хранятся только в БД, ссылки на секреты не поддерживаются
+	case secrets.ProviderVault:
+		vaultProvider, err = secrets.NewVaultProvider(secrets.VaultConfig{
+			Address:   srvConfig.VaultAddress,
+			Token:     srvConfig.VaultToken,
+			Namespace: srvConfig.VaultNamespace,
+			Mount:     srvConfig.VaultKVMount,
+			CacheTTL:  srvConfig.VaultCacheTTL,
+		})
+		if err == nil {
+			err = vaultProvider.Check(context.Background())
+		}
+		if err != nil {
+			logger.Log.Error("Не удалось подключиться к Vault", logger.String("err", err.Error()))
+			os.Exit(1)
+		}
+		secretProvider = vaultProvider
+	default:
+		logger.Log.Error("Неизвестный провайдер секретов", logger.String("provider", srvConfig.SecretProvider))
+		os.Exit(1)
+	}
+
 	// создаём handlersContainer — контейнер зависимостей для всех хендлеров,
 	// передаём в него хранилище, кеш статусов, конфиг сервера, провайдер аутентификации,
 	// SSE адаптер и инструмент проверки серверов по сети
-	handlersContainer := di_containers.NewHandlersContainer(handlersStorage, statusCache, srvConfig, broadcaster, authAdapter, netChecker)
+	handlersContainer := di_containers.NewHandlersContainer(handlersStorage, statusCache, srvConfig, broadcaster, authAdapter, netChecker, eventSink, secretProvider)
 
 	// запуск HTTP-сервера,
 	// передаём готовый handlersContainer, содержащий все зависимости
@@ -122,7 +258,8 @@ func main() {
 	// - воркер worker.ServiceBroadcastWorker периодически опрашивает БД и публикует обновления статусов служб через SSE,
 	// - воркер worker.ServerStatusWorker периодически достает из БД слайс всех серверов и получает их статус, сохраняя его в in-memory хранилище,
 	// - воркер worker.StatusBroadcastWorker периодически "дергает" in-memory хранилище статусов серверов
-	// и публикует статусы серверов пользователей через SSE
+	// и публикует статусы серверов пользователей через SSE,
+	// - воркер worker.ReportWorker рассылает сводные отчеты пользователям (если включен)
 	workersCtx, workersCtxCancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
 
@@ -132,7 +269,7 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		worker.ServerStatusWorker(workersCtx, workersStorage, statusCache, netChecker, srvConfig.WinRMPort, statusWorkerInterval, poolSize)
+		worker.ServerStatusWorker(workersCtx, workersStorage, statusCache, netChecker, config.NewWinRMConfig(srvConfig, 10*time.Second), statusWorkerInterval, poolSize)
 	}()
 
 	// если работаем с web-интерфейсом - запускаем воркер ServiceBroadcastWorker для публикации статусов служб через SSE
@@ -157,6 +294,73 @@ func main() {
 		}()
 	}
 
+	// если задана периодичность отчетов и настроен хотя бы один канал уведомлений -
+	// запускаем воркер ReportWorker для рассылки сводных отчетов пользователям
+	if srvConfig.ReportPeriod != "" {
+		reportPeriod := models.ReportPeriod(srvConfig.ReportPeriod)
+
+		var notifiers []notify.Notifier
+		if srvConfig.SMTPHost != "" {
+			notifiers = append(notifiers, notify.NewSMTPNotifier(srvConfig.SMTPHost, srvConfig.SMTPPort,
+				srvConfig.SMTPUsername, srvConfig.SMTPPassword, srvConfig.SMTPFrom))
+		}
+		if srvConfig.NotifyWebhookURL != "" {
+			notifiers = append(notifiers, notify.NewWebhookNotifier(srvConfig.NotifyWebhookURL))
+		}
+
+		switch {
+		case !reportPeriod.IsValid():
+			logger.Log.Warn("Неверная периодичность отчетов, рассылка отключена", logger.String("period", srvConfig.ReportPeriod))
+		case len(notifiers) == 0:
+			logger.Log.Warn("Не настроен ни один канал уведомлений, рассылка отчетов отключена")
+		default:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				worker.ReportWorker(workersCtx, handlersStorage, report.NewBuilder(handlersStorage), notify.NewMultiNotifier(notifiers...), reportPeriod)
+			}()
+		}
+	}
+
+	// сверка пользователей с Keycloak исправляет расхождения после пропущенных вебхуков
+	if srvConfig.AuthProvider == "keycloak" && srvConfig.KeycloakSyncSecret != "" && srvConfig.KeycloakSyncInterval > 0 {
+		syncClientID := srvConfig.KeycloakSyncClientID
+		if syncClientID == "" {
+			syncClientID = srvConfig.KeycloakClientID
+		}
+
+		adminClient := keycloak.NewAdminClient(keycloak.AdminConfig{
+			BaseURL:      srvConfig.KeycloakBaseURL,
+			Realm:        srvConfig.KeycloakRealmName,
+			ClientID:     syncClientID,
+			ClientSecret: srvConfig.KeycloakSyncSecret,
+		})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.UserSyncWorker(workersCtx, handlersStorage, adminClient, srvConfig.KeycloakSyncInterval)
+		}()
+	}
+
+	// запуск экспорта событий в syslog
+	if siemExporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			siemExporter.Run(workersCtx)
+		}()
+	}
+
+	// продление токена Vault
+	if vaultProvider != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vaultProvider.Run(workersCtx)
+		}()
+	}
+
 	// канал системных сигналов
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -222,5 +426,45 @@ func main() {
 	}
 	logger.Log.Info("Успешное закрытие соединения с БД")
 
+	// отправка оставшихся спанов в коллектор
+	tracingShutdownCtx, tracingShutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer tracingShutdownCancel()
+
+	if err = tracingShutdown(tracingShutdownCtx); err != nil {
+		logger.Log.Warn("Ошибка остановки трассировки", logger.String("err", err.Error()))
+	}
+
 	logger.Log.Info("Приложение завершено")
 }
+
+// newAuthProvider Создает провайдера аутентификации, выбранного в конфигурации:
+// Keycloak, любой OIDC-провайдер или встроенную аутентификацию.
+func newAuthProvider(ctx context.Context, srvConfig *config.Config) (auth.AuthProvider, error) {
+	switch srvConfig.AuthProvider {
+	case "keycloak":
+		return keycloak.NewKeycloakAdapter(ctx, keycloak.KeycloakConfig{
+			IssuerURL:       srvConfig.KeycloakBaseURL + "/realms/" + srvConfig.KeycloakRealmName,
+			ClientID:        srvConfig.KeycloakClientID,
+			SkipIssuerCheck: srvConfig.SkipIssuerCheck,
+		})
+	case "oidc":
+		return generic_oidc.NewOIDCAdapter(ctx, generic_oidc.OIDCConfig{
+			IssuerURL:       srvConfig.OIDCIssuerURL,
+			Audience:        srvConfig.OIDCAudience,
+			SkipIssuerCheck: srvConfig.SkipIssuerCheck,
+			Claims: generic_oidc.ClaimMapping{
+				ID:    srvConfig.OIDCIDClaim,
+				Login: srvConfig.OIDCLoginClaim,
+				Email: srvConfig.OIDCEmailClaim,
+				Roles: srvConfig.OIDCRolesClaim,
+			},
+		})
+	case "local":
+		return local.NewLocalAdapter(local.LocalConfig{
+			Secret:    srvConfig.LocalJWTSecret,
+			AccessTTL: srvConfig.LocalAccessTokenTTL,
+		})
+	default:
+		return nil, fmt.Errorf("неизвестный провайдер аутентификации %q: допустимы keycloak, oidc или local", srvConfig.AuthProvider)
+	}
+}
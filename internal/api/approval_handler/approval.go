@@ -0,0 +1,376 @@
+package approval_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// ApprovalHandler Обработчик запросов на подтверждение остановки и перезапуска критичных служб.
+type ApprovalHandler struct {
+	storage   storage.Storage
+	publisher broadcast.Broadcaster
+	executors map[string]http.Handler // обработчики действий со службами (stop, restart)
+}
+
+// NewApprovalHandler Конструктор ApprovalHandler. Подтвержденное действие выполняется
+// обработчиком из executors — тем же, что обслуживает обычные запросы управления службой.
+func NewApprovalHandler(storage storage.Storage, publisher broadcast.Broadcaster, executors map[string]http.Handler) *ApprovalHandler {
+	return &ApprovalHandler{
+		storage:   storage,
+		publisher: publisher,
+		executors: executors,
+	}
+}
+
+// GetApprovals Возвращает запросы на подтверждение из команд пользователя.
+// Параметр status (pending, approved, rejected, executed, failed, expired) фильтрует запросы по статусу.
+func (h *ApprovalHandler) GetApprovals(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.IsValidApprovalStatus(status) {
+		response.ErrorJSON(w, http.StatusBadRequest, "Неизвестный статус запроса на подтверждение")
+		return
+	}
+
+	approvals, err := h.storage.ListControlApprovals(r.Context(), creds.UserID, status)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении запросов на подтверждение", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении запросов на подтверждение")
+		return
+	}
+
+	// API-токен может быть ограничен отдельными серверами
+	if creds.APIToken != nil {
+		approvals = slices.DeleteFunc(approvals, func(approval *models.ControlApproval) bool {
+			return !creds.APIToken.AllowsServer(approval.ServerID)
+		})
+	}
+
+	// если запросов нет - возвращаем пустой срез
+	if len(approvals) == 0 {
+		approvals = []*models.ControlApproval{}
+	}
+
+	response.JSON(w, http.StatusOK, approvals)
+}
+
+// GetApproval Возвращает запрос на подтверждение.
+func (h *ApprovalHandler) GetApproval(w http.ResponseWriter, r *http.Request) {
+	approval, ok := h.getApproval(w, r)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, http.StatusOK, approval)
+}
+
+// Approve Подтверждение запроса другим участником команды с правом на это действие со службой.
+// Действие выполняется сразу после подтверждения, результат сохраняется в запросе.
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	comment, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	approval, ok := h.getApproval(w, r)
+	if !ok {
+		return
+	}
+
+	models.SetAuditTarget(ctx, approval.ServerID, approval.ServiceID, approvalAuditTarget(approval))
+
+	if approval.Status != models.ApprovalStatusPending {
+		response.ErrorJSON(w, http.StatusConflict, "Запрос уже рассмотрен или истек")
+		return
+	}
+
+	// принцип "четырех глаз": автор запроса не может подтвердить его сам
+	if approval.RequestedBy == creds.UserID {
+		response.ErrorJSON(w, http.StatusForbidden, "Нельзя подтвердить собственный запрос")
+		return
+	}
+
+	role, ok := h.teamRole(w, r, approval)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.storage.ListServicePermissions(ctx, approval.ServiceID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении правил доступа к службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при проверке прав доступа к службе")
+		return
+	}
+
+	if !models.ResolveServiceCapabilities(creds.UserID, role, permissions).Allows(approval.Action) {
+		response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав для управления службой")
+		return
+	}
+
+	executor, ok := h.executors[approval.Action]
+	if !ok {
+		logger.Log.Error("Нет обработчика для подтвержденного действия", logger.String("action", approval.Action))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Действие не поддерживается")
+		return
+	}
+
+	if !h.decide(w, r, approval, models.ApprovalStatusApproved, comment) {
+		return
+	}
+
+	// выполняем действие от имени подтвердившего пользователя обычным путем управления службой
+	status, result := execute(r, executor, approval, role)
+
+	approval.Status = models.ApprovalStatusExecuted
+	if status >= http.StatusBadRequest {
+		approval.Status = models.ApprovalStatusFailed
+	}
+	approval.Result = result
+
+	// результат сохраняется, даже если клиент уже отключился
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err = h.storage.FinishControlApproval(finishCtx, approval.ID, approval.Status, approval.Result); err != nil {
+		logger.Log.Error("Ошибка при сохранении результата подтвержденного действия",
+			logger.Int64("approvalID", approval.ID),
+			logger.String("err", err.Error()))
+	}
+
+	logger.Log.Info("Запрос на подтверждение выполнен",
+		logger.String("login", creds.Login),
+		logger.Int64("approvalID", approval.ID),
+		logger.String("status", approval.Status))
+
+	broadcast.PublishApproval(finishCtx, h.storage, h.publisher, approval)
+
+	response.JSON(w, http.StatusOK, approval)
+}
+
+// Reject Отклонение запроса участником команды с ролью operator и выше.
+// Автор может отозвать собственный запрос независимо от роли.
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	comment, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	approval, ok := h.getApproval(w, r)
+	if !ok {
+		return
+	}
+
+	models.SetAuditTarget(ctx, approval.ServerID, approval.ServiceID, approvalAuditTarget(approval))
+
+	if approval.Status != models.ApprovalStatusPending {
+		response.ErrorJSON(w, http.StatusConflict, "Запрос уже рассмотрен или истек")
+		return
+	}
+
+	if approval.RequestedBy != creds.UserID {
+		if _, ok = h.teamRole(w, r, approval); !ok {
+			return
+		}
+	}
+
+	if !h.decide(w, r, approval, models.ApprovalStatusRejected, comment) {
+		return
+	}
+
+	logger.Log.Info("Запрос на подтверждение отклонен",
+		logger.String("login", creds.Login),
+		logger.Int64("approvalID", approval.ID))
+
+	broadcast.PublishApproval(ctx, h.storage, h.publisher, approval)
+
+	response.JSON(w, http.StatusOK, approval)
+}
+
+// getApproval Получает запрос на подтверждение из URL. При ошибке пишет ответ и возвращает false.
+func (h *ApprovalHandler) getApproval(w http.ResponseWriter, r *http.Request) (*models.ControlApproval, bool) {
+	creds := models.GetContextCreds(r.Context())
+
+	approvalID, err := strconv.ParseInt(chi.URLParam(r, "approvalID"), 10, 64)
+	if err != nil || approvalID <= 0 {
+		response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id запроса на подтверждение")
+		return nil, false
+	}
+
+	approval, err := h.storage.GetControlApproval(r.Context(), approvalID, creds.UserID)
+	if err != nil {
+		var errApprovalNotFound *errs.ErrApprovalNotFound
+
+		if errors.As(err, &errApprovalNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Запрос на подтверждение не найден")
+			return nil, false
+		}
+
+		logger.Log.Warn("Ошибка при получении запроса на подтверждение", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении запроса на подтверждение")
+		return nil, false
+	}
+
+	if creds.APIToken != nil && !creds.APIToken.AllowsServer(approval.ServerID) {
+		response.ErrorJSON(w, http.StatusNotFound, "Запрос на подтверждение не найден")
+		return nil, false
+	}
+
+	return approval, true
+}
+
+// teamRole Возвращает роль пользователя, суженную до его роли в команде сервера,
+// и проверяет, что она позволяет рассматривать запросы (operator и выше).
+func (h *ApprovalHandler) teamRole(w http.ResponseWriter, r *http.Request, approval *models.ControlApproval) (models.Role, bool) {
+	creds := models.GetContextCreds(r.Context())
+
+	teamRole, err := h.storage.GetServerTeamRole(r.Context(), approval.ServerID, creds.UserID)
+	if err != nil {
+		var errServerNotFound *errs.ErrServerNotFound
+
+		if errors.As(err, &errServerNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Сервер не найден")
+			return "", false
+		}
+
+		logger.Log.Warn("Ошибка при получении роли пользователя в команде", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при проверке прав доступа")
+		return "", false
+	}
+
+	role := models.MinRole(creds.Role, teamRole)
+	if !role.Allows(models.RoleOperator) {
+		response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав")
+		return "", false
+	}
+
+	return role, true
+}
+
+// decide Сохраняет решение по запросу. При ошибке пишет ответ и возвращает false.
+func (h *ApprovalHandler) decide(w http.ResponseWriter, r *http.Request, approval *models.ControlApproval, status string, comment string) bool {
+	creds := models.GetContextCreds(r.Context())
+
+	err := h.storage.DecideControlApproval(r.Context(), approval.ID, status, creds.UserID, creds.Login, comment)
+	if err != nil {
+		var errApprovalNotPending *errs.ErrApprovalNotPending
+
+		if errors.As(err, &errApprovalNotPending) {
+			response.ErrorJSON(w, http.StatusConflict, "Запрос уже рассмотрен или истек")
+			return false
+		}
+
+		logger.Log.Error("Ошибка при сохранении решения по запросу на подтверждение", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при сохранении решения по запросу")
+		return false
+	}
+
+	now := time.Now()
+
+	approval.Status = status
+	approval.DecidedBy = creds.UserID
+	approval.DecidedLogin = creds.Login
+	approval.DecisionComment = comment
+	approval.DecidedAt = &now
+
+	return true
+}
+
+// execute Выполняет подтвержденное действие обработчиком управления службой
+// и возвращает код его ответа и сообщение.
+func execute(r *http.Request, executor http.Handler, approval *models.ControlApproval, role models.Role) (int, string) {
+	ctx := context.WithValue(r.Context(), contextkeys.ServerID, approval.ServerID)
+	ctx = context.WithValue(ctx, contextkeys.ServiceID, approval.ServiceID)
+	ctx = context.WithValue(ctx, contextkeys.Role, role)
+	ctx = context.WithValue(ctx, contextkeys.ApprovalID, approval.ID)
+
+	req := r.Clone(ctx)
+	req.Body = http.NoBody
+	req.ContentLength = 0
+
+	rec := &resultRecorder{header: http.Header{}}
+	executor.ServeHTTP(rec, req)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(rec.body.Bytes(), &payload)
+
+	return rec.status, payload.Message
+}
+
+// resultRecorder Сохраняет ответ обработчика, выполняющего подтвержденное действие.
+type resultRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *resultRecorder) Header() http.Header {
+	return rr.header
+}
+
+func (rr *resultRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+
+	return rr.body.Write(b)
+}
+
+func (rr *resultRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+}
+
+// decodeComment Читает необязательный комментарий из тела запроса. При ошибке пишет ответ и возвращает false.
+func decodeComment(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var comment models.ApprovalComment
+
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil && !errors.Is(err, io.EOF) {
+		logger.Log.Debug("Неверный формат комментария к запросу на подтверждение", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return "", false
+	}
+
+	if err := comment.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+
+	return strings.TrimSpace(comment.Comment), true
+}
+
+// approvalAuditTarget Описание запроса на подтверждение для журнала аудита.
+func approvalAuditTarget(approval *models.ControlApproval) string {
+	return fmt.Sprintf("%s: %s (approval %d)", approval.DisplayedName, approval.Action, approval.ID)
+}
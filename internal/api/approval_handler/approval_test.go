@@ -0,0 +1,344 @@
+package approval_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// newRequest Создает запрос пользователя user-2 с ролью role и параметром approvalID роутера Chi.
+func newRequest(body any, role models.Role, approvalID string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/user/approvals", &buf)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("approvalID", approvalID)
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextkeys.Login, "bob")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "user-2")
+	ctx = context.WithValue(ctx, contextkeys.Role, role)
+
+	return r.WithContext(ctx)
+}
+
+// pendingApproval Возвращает ожидающий запрос на остановку службы от пользователя user-1.
+func pendingApproval() *models.ControlApproval {
+	return &models.ControlApproval{
+		ID:            3,
+		TeamID:        7,
+		ServerID:      100,
+		ServiceID:     10,
+		DisplayedName: "Database",
+		Action:        models.ControlActionStop,
+		Status:        models.ApprovalStatusPending,
+		RequestedBy:   "user-1",
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+}
+
+// TestGetApprovals Проверяет получение списка запросов на подтверждение.
+func TestGetApprovals(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		approvals      []*models.ControlApproval
+		storageErr     error
+		expectedStatus int
+		expectedLen    int
+	}{
+		{"все запросы", "", []*models.ControlApproval{pendingApproval()}, nil, http.StatusOK, 1},
+		{"фильтр по статусу", models.ApprovalStatusPending, nil, nil, http.StatusOK, 0},
+		{"неизвестный статус", "unknown", nil, nil, http.StatusBadRequest, 0},
+		{"ошибка хранилища", "", nil, errors.New("db error"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockStorage.EXPECT().ListControlApprovals(gomock.Any(), "user-2", tt.status).Return(tt.approvals, tt.storageErr)
+			}
+
+			r := newRequest(nil, models.RoleViewer, "")
+			r.URL.RawQuery = "status=" + tt.status
+
+			w := httptest.NewRecorder()
+			NewApprovalHandler(mockStorage, broadcast.NewNoopAdapter(nil), nil).GetApprovals(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var got []*models.ControlApproval
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.NotNil(t, got)
+				assert.Len(t, got, tt.expectedLen)
+			}
+		})
+	}
+}
+
+// TestApprove Проверяет подтверждение запроса и выполнение действия.
+func TestApprove(t *testing.T) {
+	tests := []struct {
+		name           string
+		approvalID     string
+		role           models.Role
+		setup          func(s *storageMocks.MockStorage, a *models.ControlApproval)
+		executorStatus int
+		expectedStatus int
+		expectedResult string
+	}{
+		{
+			name:       "подтверждение и успешное выполнение",
+			approvalID: "3",
+			role:       models.RoleAdmin,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleOperator, nil)
+				s.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).Return(nil, nil)
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusApproved, "user-2", "bob", "ок").Return(nil)
+				s.EXPECT().FinishControlApproval(gomock.Any(), int64(3), models.ApprovalStatusExecuted, "готово").Return(nil)
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).Return(nil, nil)
+			},
+			executorStatus: http.StatusOK,
+			expectedStatus: http.StatusOK,
+			expectedResult: models.ApprovalStatusExecuted,
+		},
+		{
+			name:       "действие завершилось ошибкой",
+			approvalID: "3",
+			role:       models.RoleOperator,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleAdmin, nil)
+				s.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).Return(nil, nil)
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusApproved, "user-2", "bob", "ок").Return(nil)
+				s.EXPECT().FinishControlApproval(gomock.Any(), int64(3), models.ApprovalStatusFailed, "готово").Return(nil)
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).Return(nil, nil)
+			},
+			executorStatus: http.StatusBadGateway,
+			expectedStatus: http.StatusOK,
+			expectedResult: models.ApprovalStatusFailed,
+		},
+		{
+			name:           "некорректный id",
+			approvalID:     "abc",
+			role:           models.RoleOperator,
+			setup:          func(s *storageMocks.MockStorage, a *models.ControlApproval) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "запрос не найден",
+			approvalID: "3",
+			role:       models.RoleOperator,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetControlApproval(gomock.Any(), int64(3), "user-2").
+					Return(nil, errs.NewErrApprovalNotFound(3, "user-2", nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:       "собственный запрос",
+			approvalID: "3",
+			role:       models.RoleAdmin,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				a.RequestedBy = "user-2"
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "запрос уже рассмотрен",
+			approvalID: "3",
+			role:       models.RoleAdmin,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				a.Status = models.ApprovalStatusExpired
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:       "наблюдатель в команде сервера",
+			approvalID: "3",
+			role:       models.RoleAdmin,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleViewer, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "правила доступа запрещают действие",
+			approvalID: "3",
+			role:       models.RoleOperator,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleOperator, nil)
+				s.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).
+					Return([]*models.ServicePermission{{ServiceID: 10, Role: models.RoleAdmin, Stop: true}}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "запрос рассмотрен параллельно",
+			approvalID: "3",
+			role:       models.RoleOperator,
+			setup: func(s *storageMocks.MockStorage, a *models.ControlApproval) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleOperator, nil)
+				s.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).Return(nil, nil)
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusApproved, "user-2", "bob", "ок").
+					Return(errs.NewErrApprovalNotPending(3))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			approval := pendingApproval()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			if tt.name != "запрос не найден" && tt.approvalID == "3" {
+				mockStorage.EXPECT().GetControlApproval(gomock.Any(), int64(3), "user-2").Return(approval, nil)
+			}
+			tt.setup(mockStorage, approval)
+
+			executed := false
+			executor := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				executed = true
+
+				// действие выполняется от имени подтвердившего пользователя с ролью в команде
+				creds := models.GetContextCreds(r.Context())
+				assert.Equal(t, "user-2", creds.UserID)
+				assert.Equal(t, int64(100), creds.ServerID)
+				assert.Equal(t, int64(10), creds.ServiceID)
+				assert.Equal(t, int64(3), creds.ApprovalID)
+				assert.Equal(t, models.RoleOperator, creds.Role)
+
+				if tt.executorStatus >= http.StatusBadRequest {
+					response.ErrorJSON(w, tt.executorStatus, "готово")
+					return
+				}
+				response.SuccessJSON(w, tt.executorStatus, "готово")
+			})
+
+			handler := NewApprovalHandler(mockStorage, broadcast.NewNoopAdapter(nil),
+				map[string]http.Handler{models.ControlActionStop: executor})
+
+			w := httptest.NewRecorder()
+			handler.Approve(w, newRequest(models.ApprovalComment{Comment: "ок"}, tt.role, tt.approvalID))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedResult != "", executed)
+
+			if tt.expectedResult != "" {
+				var got models.ControlApproval
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, tt.expectedResult, got.Status)
+				assert.Equal(t, "готово", got.Result)
+				assert.Equal(t, "bob", got.DecidedLogin)
+				assert.NotNil(t, got.DecidedAt)
+			}
+		})
+	}
+}
+
+// TestReject Проверяет отклонение и отзыв запроса.
+func TestReject(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestedBy    string
+		setup          func(s *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name:        "отклонение оператором команды",
+			requestedBy: "user-1",
+			setup: func(s *storageMocks.MockStorage) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleOperator, nil)
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusRejected, "user-2", "bob", "").Return(nil)
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "отзыв собственного запроса",
+			requestedBy: "user-2",
+			setup: func(s *storageMocks.MockStorage) {
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusRejected, "user-2", "bob", "").Return(nil)
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "наблюдатель не может отклонить чужой запрос",
+			requestedBy: "user-1",
+			setup: func(s *storageMocks.MockStorage) {
+				s.EXPECT().GetServerTeamRole(gomock.Any(), int64(100), "user-2").Return(models.RoleViewer, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:        "ошибка сохранения решения",
+			requestedBy: "user-2",
+			setup: func(s *storageMocks.MockStorage) {
+				s.EXPECT().DecideControlApproval(gomock.Any(), int64(3), models.ApprovalStatusRejected, "user-2", "bob", "").
+					Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			approval := pendingApproval()
+			approval.RequestedBy = tt.requestedBy
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().GetControlApproval(gomock.Any(), int64(3), "user-2").Return(approval, nil)
+			tt.setup(mockStorage)
+
+			w := httptest.NewRecorder()
+			NewApprovalHandler(mockStorage, broadcast.NewNoopAdapter(nil), nil).
+				Reject(w, newRequest(nil, models.RoleOperator, "3"))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var got models.ControlApproval
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, models.ApprovalStatusRejected, got.Status)
+			}
+		})
+	}
+}
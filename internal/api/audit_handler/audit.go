@@ -0,0 +1,169 @@
+package audit_handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// AuditHandler Обработчик для просмотра журнала аудита.
+type AuditHandler struct {
+	storage storage.Storage
+}
+
+// NewAuditHandler Конструктор AuditHandler.
+func NewAuditHandler(storage storage.Storage) *AuditHandler {
+	return &AuditHandler{
+		storage: storage,
+	}
+}
+
+// GetAudit Возвращает страницу журнала аудита текущего пользователя.
+//
+// Параметры запроса (все необязательные):
+//   - action — действие (add_server, edit_server, delete_server, test_server, add_service, delete_service, start, stop, restart,
+//     set_service_permission, delete_service_permission, set_service_critical, request_approval, approve_control, reject_control,
+//     add_team, delete_team, invite_member, delete_invitation, accept_invitation, edit_member, delete_member,
+//     add_api_token, delete_api_token, add_credential_profile, edit_credential_profile, delete_credential_profile,
+//     test_credential_profile, add_user, change_password),
+//   - server_id, service_id — идентификаторы объекта,
+//   - result — success или failure,
+//   - from, to — границы периода [from, to) в формате RFC3339,
+//   - limit — размер страницы (по умолчанию 50, максимум 500), offset — смещение.
+func (h *AuditHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter.UserID = creds.UserID
+
+	h.writePage(w, r, filter)
+}
+
+// GetAllAudit Возвращает страницу журнала аудита всех пользователей (для администраторов).
+// Помимо параметров GetAudit принимает user_id для выборки по одному пользователю.
+func (h *AuditHandler) GetAllAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter.UserID = query.Get("user_id")
+
+	h.writePage(w, r, filter)
+}
+
+// writePage Получает страницу журнала по фильтру и пишет ее в ответ.
+func (h *AuditHandler) writePage(w http.ResponseWriter, r *http.Request, filter *models.AuditFilter) {
+	creds := models.GetContextCreds(r.Context())
+
+	entries, total, err := h.storage.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		logger.Log.Error("Ошибка получения журнала аудита",
+			logger.String("login", creds.Login),
+			logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка получения журнала аудита")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.AuditPage{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	})
+}
+
+// parseAuditFilter Разбирает параметры запроса в фильтр журнала аудита.
+func parseAuditFilter(query url.Values) (*models.AuditFilter, error) {
+	filter := &models.AuditFilter{
+		Limit: models.AuditDefaultLimit,
+	}
+
+	if v := query.Get("action"); v != "" {
+		if !models.IsValidAuditAction(v) {
+			return nil, errors.New("Неверное значение параметра action")
+		}
+		filter.Action = v
+	}
+
+	var err error
+
+	if filter.ServerID, err = parsePositiveInt64(query.Get("server_id")); err != nil {
+		return nil, errors.New("Неверное значение параметра server_id")
+	}
+
+	if filter.ServiceID, err = parsePositiveInt64(query.Get("service_id")); err != nil {
+		return nil, errors.New("Неверное значение параметра service_id")
+	}
+
+	switch query.Get("result") {
+	case "":
+	case "success":
+		success := true
+		filter.Success = &success
+	case "failure":
+		success := false
+		filter.Success = &success
+	default:
+		return nil, errors.New("Неверное значение параметра result, допустимые значения: success, failure")
+	}
+
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, errors.New("Неверный формат параметра from, ожидается RFC3339")
+		}
+	}
+
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, errors.New("Неверный формат параметра to, ожидается RFC3339")
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return nil, errors.New("Неверное значение параметра limit")
+		}
+		filter.Limit = min(limit, models.AuditMaxLimit)
+	}
+
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, errors.New("Неверное значение параметра offset")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+// parsePositiveInt64 Разбирает необязательный положительный идентификатор (пустая строка — 0).
+func parsePositiveInt64(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid id")
+	}
+
+	return id, nil
+}
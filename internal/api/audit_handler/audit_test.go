@@ -0,0 +1,215 @@
+package audit_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// Создание контекста с данными о пользователе.
+func createContextWithCreds(login, userID string) context.Context {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, contextkeys.Login, login)
+	ctx = context.WithValue(ctx, contextkeys.UserID, userID)
+	return ctx
+}
+
+// TestParseAuditFilter Проверяет разбор параметров выборки журнала аудита.
+func TestParseAuditFilter(t *testing.T) {
+	success := true
+	failure := false
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    *models.AuditFilter
+		expectError bool
+	}{
+		{
+			name:     "значения по умолчанию",
+			query:    "",
+			expected: &models.AuditFilter{Limit: models.AuditDefaultLimit},
+		},
+		{
+			name:  "все параметры",
+			query: "action=stop&server_id=1&service_id=2&result=success&from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z&limit=10&offset=20",
+			expected: &models.AuditFilter{
+				Action: "stop", ServerID: 1, ServiceID: 2, Success: &success,
+				From: from, To: to, Limit: 10, Offset: 20,
+			},
+		},
+		{
+			name:     "неуспешные действия",
+			query:    "result=failure",
+			expected: &models.AuditFilter{Success: &failure, Limit: models.AuditDefaultLimit},
+		},
+		{
+			name:     "limit ограничивается максимумом",
+			query:    "limit=100000",
+			expected: &models.AuditFilter{Limit: models.AuditMaxLimit},
+		},
+		{name: "неизвестное действие", query: "action=format_disk", expectError: true},
+		{name: "неверный server_id", query: "server_id=abc", expectError: true},
+		{name: "отрицательный service_id", query: "service_id=-1", expectError: true},
+		{name: "неверный result", query: "result=maybe", expectError: true},
+		{name: "неверный from", query: "from=yesterday", expectError: true},
+		{name: "неверный to", query: "to=2025-01-02", expectError: true},
+		{name: "нулевой limit", query: "limit=0", expectError: true},
+		{name: "отрицательный offset", query: "offset=-5", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/audit?"+tt.query, nil)
+
+			filter, err := parseAuditFilter(r.URL.Query())
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+// TestAuditHandler_GetAudit Проверяет получение журнала аудита пользователя.
+func TestAuditHandler_GetAudit(t *testing.T) {
+	entries := []*models.AuditEntry{
+		{ID: 2, UserID: "user-1", Login: "tester", Action: models.ControlActionStop, ServerID: 1, ServiceID: 2, Success: false, Error: "Сервер недоступен"},
+		{ID: 1, UserID: "user-1", Login: "tester", Action: models.AuditActionAddServer, ServerID: 1, Success: true},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		setupMock  func(m *storageMocks.MockStorage)
+		wantStatus int
+		checkBody  func(t *testing.T, body []byte)
+	}{
+		{
+			name:  "успешное получение - только записи пользователя",
+			query: "?limit=2&action=stop",
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().ListAuditEntries(gomock.Any(), &models.AuditFilter{UserID: "user-1", Action: "stop", Limit: 2}).
+					Return(entries, 5, nil)
+			},
+			wantStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var page models.AuditPage
+				require.NoError(t, json.Unmarshal(body, &page))
+				assert.Equal(t, 5, page.Total)
+				assert.Equal(t, 2, page.Limit)
+				require.Len(t, page.Entries, 2)
+				assert.Equal(t, "Сервер недоступен", page.Entries[0].Error)
+			},
+		},
+		{
+			name:  "параметр user_id игнорируется",
+			query: "?user_id=user-2",
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().ListAuditEntries(gomock.Any(), &models.AuditFilter{UserID: "user-1", Limit: models.AuditDefaultLimit}).
+					Return([]*models.AuditEntry{}, 0, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неверные параметры",
+			query:      "?result=maybe",
+			setupMock:  func(m *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "ошибка хранилища",
+			query: "",
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().ListAuditEntries(gomock.Any(), gomock.Any()).Return(nil, 0, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			h := NewAuditHandler(mockStorage)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/audit"+tt.query, nil)
+			req = req.WithContext(createContextWithCreds("tester", "user-1"))
+			rr := httptest.NewRecorder()
+
+			h.GetAudit(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.checkBody != nil {
+				tt.checkBody(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+// TestAuditHandler_GetAllAudit Проверяет получение журнала аудита всех пользователей.
+func TestAuditHandler_GetAllAudit(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expectUserID string
+	}{
+		{"все пользователи", "", ""},
+		{"один пользователь", "?user_id=user-2", "user-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().
+				ListAuditEntries(gomock.Any(), &models.AuditFilter{UserID: tt.expectUserID, Limit: models.AuditDefaultLimit}).
+				Return([]*models.AuditEntry{}, 0, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/audit"+tt.query, nil)
+			req = req.WithContext(createContextWithCreds("admin", "admin-id"))
+			rr := httptest.NewRecorder()
+
+			NewAuditHandler(mockStorage).GetAllAudit(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{"entries":[],"total":0,"limit":50,"offset":0}`, rr.Body.String())
+		})
+	}
+
+	t.Run("неверные параметры", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?limit=abc", nil)
+		rr := httptest.NewRecorder()
+
+		NewAuditHandler(nil).GetAllAudit(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
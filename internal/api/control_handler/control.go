@@ -2,12 +2,19 @@ package control_handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
@@ -15,28 +22,38 @@ import (
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/tracing"
 )
 
 // ControlHandler Обрабатывает запросы управления службами (start, stop, restart, status).
 type ControlHandler struct {
 	storage       storage.Storage
-	clientFactory service_control.ClientFactory // фабрика для создания WinRM клиентов
+	clientFactory service_control.ClientFactory  // фабрика для создания WinRM клиентов
+	manager       service_control.ServiceManager // управление службами через WinRM клиент
 	checker       netutils.Checker
-	winRMPort     string
+	winRMConfig   *config.WinRMConfig
+	publisher     broadcast.Broadcaster // рассылка запросов на подтверждение по SSE
+	approvalTTL   time.Duration         // срок ожидания подтверждения действия с критичной службой
 }
 
 // NewControlHandler Конструктор ControlHandler.
 func NewControlHandler(
 	storage storage.Storage,
 	clientFactory service_control.ClientFactory,
+	manager service_control.ServiceManager,
 	checker netutils.Checker,
-	winRMPort string,
+	winRMConfig *config.WinRMConfig,
+	publisher broadcast.Broadcaster,
+	approvalTTL time.Duration,
 ) *ControlHandler {
 	return &ControlHandler{
 		storage:       storage,
 		clientFactory: clientFactory,
+		manager:       manager,
 		checker:       checker,
-		winRMPort:     winRMPort,
+		winRMConfig:   winRMConfig,
+		publisher:     publisher,
+		approvalTTL:   approvalTTL,
 	}
 }
 
@@ -45,6 +62,11 @@ func (h *ControlHandler) ServiceStop(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	creds := models.GetContextCreds(ctx)
 
+	// проверяем правила доступа к службе до любых обращений к серверу
+	if !h.checkServicePermission(ctx, w, creds, models.ControlActionStop) {
+		return
+	}
+
 	// получаем сервер с паролем
 	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
 
@@ -85,15 +107,28 @@ func (h *ControlHandler) ServiceStop(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// остановка критичной службы выполняется только после подтверждения другим пользователем
+	if service.Critical && creds.ApprovalID == 0 {
+		h.requestApproval(w, r, creds, server, service, models.ControlActionStop)
+		return
+	}
+
+	// команды на сервере выполняются только для допустимого имени службы
+	if err = service_control.ValidateServiceName(service.ServiceName); err != nil {
+		logger.Log.Warn("Недопустимое имя службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Недопустимое имя службы")
+		return
+	}
+
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно остановить службу", server.Address, server.ID))
 		response.ErrorJSON(w, http.StatusBadGateway, fmt.Sprintf("Сервер недоступен"))
 		return
 	}
 
 	// создаём WinRM клиент
-	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -105,10 +140,7 @@ func (h *ControlHandler) ServiceStop(w http.ResponseWriter, r *http.Request) {
 	statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	statusCmd := fmt.Sprintf("sc query \"%s\"", service.ServiceName)
-	stopCmd := fmt.Sprintf("sc stop \"%s\"", service.ServiceName)
-
-	result, err := client.RunCommand(statusCtx, statusCmd)
+	state, err := h.manager.Query(statusCtx, client, service.ServiceName)
 	if err != nil {
 		logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s`, id=%d на сервере `%s`, id=%d",
 			service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
@@ -117,9 +149,7 @@ func (h *ControlHandler) ServiceStop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status := utils.GetStatus(result)
-
-	switch status {
+	switch state.State {
 	case utils.ServiceRunning, utils.ServiceStartPending:
 		// пробуем остановить
 
@@ -127,22 +157,10 @@ func (h *ControlHandler) ServiceStop(w http.ResponseWriter, r *http.Request) {
 		stopCtx, cancelStop := context.WithTimeout(ctx, 30*time.Second)
 		defer cancelStop()
 
-		var stdout string
-
-		// получаем вывод после выполнения команды остановки
-		if stdout, err = client.RunCommand(stopCtx, stopCmd); err != nil {
+		if err = h.manager.Stop(stopCtx, client, service.ServiceName); err != nil {
 			logger.Log.Warn(fmt.Sprintf("Не удалось остановить службу `%s`, id=%d на сервере `%s`, id=%d",
 				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
-			response.ErrorJSON(w, http.StatusInternalServerError, "Не удалось остановить службу")
-			return
-		}
-
-		// проверяем вывод на FAILED, .т.е на ошибку остановки службы
-		// (если exit code != 0, то в выводе точно будет FAILED)
-		if serviceErr := errs.ParseServiceError(stdout); serviceErr != nil {
-			logger.Log.Warn(fmt.Sprintf("Не удалось остановить службу `%s`, id=%d на сервере `%s`, id=%d",
-				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", serviceErr.Error()))
-			response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+			respondControlError(w, err, "Не удалось остановить службу")
 			return
 		}
 
@@ -201,6 +219,11 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	creds := models.GetContextCreds(ctx)
 
+	// проверяем правила доступа к службе до любых обращений к серверу
+	if !h.checkServicePermission(ctx, w, creds, models.ControlActionStart) {
+		return
+	}
+
 	// получаем сервер с паролем
 	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
 
@@ -241,15 +264,22 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// команды на сервере выполняются только для допустимого имени службы
+	if err = service_control.ValidateServiceName(service.ServiceName); err != nil {
+		logger.Log.Warn("Недопустимое имя службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Недопустимое имя службы")
+		return
+	}
+
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно запустить службу", server.Address, server.ID))
 		response.ErrorJSON(w, http.StatusBadGateway, fmt.Sprintf("Сервер недоступен"))
 		return
 	}
 
 	// создаём WinRM клиент
-	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -257,14 +287,11 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	statusCmd := fmt.Sprintf("sc query \"%s\"", service.ServiceName)
-	startCmd := fmt.Sprintf("sc start \"%s\"", service.ServiceName)
-
 	// контекст для получения статуса
 	statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := client.RunCommand(statusCtx, statusCmd)
+	state, err := h.manager.Query(statusCtx, client, service.ServiceName)
 	if err != nil {
 		logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s`, id=%d на сервере `%s`, id=%d",
 			service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
@@ -273,9 +300,7 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status := utils.GetStatus(result)
-
-	switch status {
+	switch state.State {
 	case utils.ServiceStopped, utils.ServiceStopPending:
 		// пробуем запустить
 
@@ -283,22 +308,10 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 		startCtx, cancelStart := context.WithTimeout(ctx, 30*time.Second)
 		defer cancelStart()
 
-		var stdout string
-
-		// получаем вывод после выполнения команды запуска
-		if stdout, err = client.RunCommand(startCtx, startCmd); err != nil {
+		if err = h.manager.Start(startCtx, client, service.ServiceName); err != nil {
 			logger.Log.Warn(fmt.Sprintf("Не удалось запустить службу `%s`, id=%d на сервере `%s`, id=%d",
 				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
-			response.ErrorJSON(w, http.StatusInternalServerError, "Не удалось запустить службу")
-			return
-		}
-
-		// проверяем вывод на FAILED, .т.е на ошибку запуска службы
-		// (если exit code != 0, то в выводе точно будет FAILED)
-		if serviceErr := errs.ParseServiceError(stdout); serviceErr != nil {
-			logger.Log.Warn(fmt.Sprintf("Не удалось запустить службу `%s`, id=%d на сервере `%s`, id=%d",
-				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", serviceErr.Error()))
-			response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+			respondControlError(w, err, "Не удалось запустить службу")
 			return
 		}
 
@@ -352,11 +365,14 @@ func (h *ControlHandler) ServiceStart(w http.ResponseWriter, r *http.Request) {
 
 // ServiceRestart Перезапуск службы.
 func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request) {
-	var stdout string
-
 	ctx := r.Context()
 	creds := models.GetContextCreds(ctx)
 
+	// проверяем правила доступа к службе до любых обращений к серверу
+	if !h.checkServicePermission(ctx, w, creds, models.ControlActionRestart) {
+		return
+	}
+
 	// получаем сервер с паролем
 	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
 
@@ -397,15 +413,28 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// перезапуск критичной службы выполняется только после подтверждения другим пользователем
+	if service.Critical && creds.ApprovalID == 0 {
+		h.requestApproval(w, r, creds, server, service, models.ControlActionRestart)
+		return
+	}
+
+	// команды на сервере выполняются только для допустимого имени службы
+	if err = service_control.ValidateServiceName(service.ServiceName); err != nil {
+		logger.Log.Warn("Недопустимое имя службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Недопустимое имя службы")
+		return
+	}
+
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно перезапустить службу", server.Address, server.ID))
 		response.ErrorJSON(w, http.StatusBadGateway, fmt.Sprintf("Сервер недоступен"))
 		return
 	}
 
 	// создаём WinRM клиент
-	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -413,15 +442,11 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	statusCmd := fmt.Sprintf("sc query \"%s\"", service.ServiceName)
-	stopCmd := fmt.Sprintf("sc stop \"%s\"", service.ServiceName)
-	startCmd := fmt.Sprintf("sc start \"%s\"", service.ServiceName)
-
 	// контекст для получения статуса
 	statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := client.RunCommand(statusCtx, statusCmd)
+	state, err := h.manager.Query(statusCtx, client, service.ServiceName)
 	if err != nil {
 		logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s`, id=%d на сервере `%s`, id=%d",
 			service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
@@ -430,9 +455,7 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	status := utils.GetStatus(result)
-
-	switch status {
+	switch state.State {
 	case utils.ServiceRunning:
 		// сначала пробуем остановить
 
@@ -440,20 +463,10 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		stopCtx, cancelStop := context.WithTimeout(ctx, 30*time.Second)
 		defer cancelStop()
 
-		if stdout, err = client.RunCommand(stopCtx, stopCmd); err != nil {
+		if err = h.manager.Stop(stopCtx, client, service.ServiceName); err != nil {
 			logger.Log.Warn(fmt.Sprintf("Не удалось остановить службу `%s`, id=%d на сервере `%s`, id=%d",
 				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
-			response.ErrorJSON(w, http.StatusInternalServerError,
-				fmt.Sprintf("Не удалось остановить службу `%s`", service.DisplayedName))
-			return
-		}
-
-		// проверяем вывод на FAILED, .т.е на ошибку остановки службы
-		// (если exit code != 0, то в выводе точно будет FAILED)
-		if serviceErr := errs.ParseServiceError(stdout); serviceErr != nil {
-			logger.Log.Warn(fmt.Sprintf("Не удалось остановить службу `%s`, id=%d на сервере `%s`, id=%d",
-				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", serviceErr.Error()))
-			response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+			respondControlError(w, err, fmt.Sprintf("Не удалось остановить службу `%s`", service.DisplayedName))
 			return
 		}
 
@@ -479,18 +492,10 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		startCtx, cancelStart := context.WithTimeout(ctx, 30*time.Second)
 		defer cancelStart()
 
-		if stdout, err = client.RunCommand(startCtx, startCmd); err != nil {
-			response.ErrorJSON(w, http.StatusInternalServerError,
-				fmt.Sprintf("Не удалось запустить службу `%s`", service.DisplayedName))
-			return
-		}
-
-		// проверяем вывод на FAILED, .т.е на ошибку запуска службы
-		// (если exit code != 0, то в выводе точно будет FAILED)
-		if serviceErr := errs.ParseServiceError(stdout); serviceErr != nil {
+		if err = h.manager.Start(startCtx, client, service.ServiceName); err != nil {
 			logger.Log.Warn(fmt.Sprintf("Не удалось запустить службу `%s`, id=%d на сервере `%s`, id=%d",
-				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", serviceErr.Error()))
-			response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
+			respondControlError(w, err, fmt.Sprintf("Не удалось запустить службу `%s`", service.DisplayedName))
 			return
 		}
 
@@ -520,20 +525,10 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 		startCtx, cancelStart := context.WithTimeout(ctx, 30*time.Second)
 		defer cancelStart()
 
-		if stdout, err = client.RunCommand(startCtx, startCmd); err != nil {
+		if err = h.manager.Start(startCtx, client, service.ServiceName); err != nil {
 			logger.Log.Warn(fmt.Sprintf("Не удалось запустить службу `%s`, id=%d на сервере `%s`, id=%d",
 				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
-			response.ErrorJSON(w, http.StatusInternalServerError,
-				fmt.Sprintf("Не удалось запустить службу `%s`", service.DisplayedName))
-			return
-		}
-
-		// проверяем вывод на FAILED, .т.е на ошибку запуска службы
-		// (если exit code != 0, то в выводе точно будет FAILED)
-		if serviceErr := errs.ParseServiceError(stdout); serviceErr != nil {
-			logger.Log.Warn(fmt.Sprintf("Не удалось запустить службу `%s`, id=%d на сервере `%s`, id=%d",
-				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", serviceErr.Error()))
-			response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+			respondControlError(w, err, fmt.Sprintf("Не удалось запустить службу `%s`", service.DisplayedName))
 			return
 		}
 
@@ -571,9 +566,110 @@ func (h *ControlHandler) ServiceRestart(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// checkServicePermission Проверяет, что правила доступа к службе разрешают пользователю действие.
+// При отказе или ошибке пишет ответ и возвращает false.
+func (h *ControlHandler) checkServicePermission(ctx context.Context, w http.ResponseWriter, creds *models.ContextCredentials, action string) bool {
+	permissions, err := h.storage.ListServicePermissions(ctx, creds.ServiceID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении правил доступа к службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при проверке прав доступа к службе")
+		return false
+	}
+
+	if !models.ResolveServiceCapabilities(creds.UserID, creds.Role, permissions).Allows(action) {
+		logger.Log.Warn("Правила доступа к службе запрещают действие",
+			logger.String("login", creds.Login),
+			logger.String("role", string(creds.Role)),
+			logger.Int64("serviceID", creds.ServiceID),
+			logger.String("action", action))
+		response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав для управления службой")
+		return false
+	}
+
+	return true
+}
+
+// requestApproval Создает запрос на подтверждение действия с критичной службой вместо его выполнения
+// и рассылает его участникам команды. Необязательный комментарий передается в теле запроса.
+func (h *ControlHandler) requestApproval(w http.ResponseWriter, r *http.Request, creds *models.ContextCredentials, server *models.Server, service *models.Service, action string) {
+	ctx := r.Context()
+
+	var comment models.ApprovalComment
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&comment); err != nil && !errors.Is(err, io.EOF) {
+			response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+			return
+		}
+	}
+
+	if err := comment.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// в журнал аудита попадает создание запроса, а не выполнение действия
+	models.SetAuditAction(ctx, models.AuditActionRequestApproval)
+
+	approval, err := h.storage.AddControlApproval(ctx, models.ControlApproval{
+		ServerID:       creds.ServerID,
+		ServiceID:      creds.ServiceID,
+		Action:         action,
+		RequestedBy:    creds.UserID,
+		RequestedLogin: creds.Login,
+		RequestComment: strings.TrimSpace(comment.Comment),
+		ExpiresAt:      time.Now().Add(h.approvalTTL),
+	})
+
+	var ErrDuplicatedApproval *errs.ErrDuplicatedApproval
+
+	if err != nil {
+		switch {
+		case errors.As(err, &ErrDuplicatedApproval):
+			response.ErrorJSON(w, http.StatusConflict,
+				fmt.Sprintf("Действие со службой `%s` уже ожидает подтверждения", service.DisplayedName))
+			return
+		default:
+			logger.Log.Error("Ошибка при создании запроса на подтверждение", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании запроса на подтверждение")
+			return
+		}
+	}
+
+	approval.TeamID = server.TeamID
+	approval.DisplayedName = service.DisplayedName
+
+	logger.Log.Info(fmt.Sprintf("Действие `%s` со службой `%s`, id=%d на сервере `%s`, id=%d ожидает подтверждения",
+		action, service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID),
+		logger.String("login", creds.Login),
+		logger.Int64("approvalID", approval.ID))
+
+	broadcast.PublishApproval(ctx, h.storage, h.publisher, approval)
+
+	response.JSON(w, http.StatusAccepted, approval)
+}
+
+// respondControlError Отвечает на ошибку команды управления службой: ошибка самой службы (код Win32)
+// возвращается пользователю, остальные ошибки (связь с сервером и т.п.) — общим сообщением message.
+func respondControlError(w http.ResponseWriter, err error, message string) {
+	var serviceErr *errs.ServiceError
+
+	if errors.As(err, &serviceErr) {
+		response.ErrorJSON(w, http.StatusBadRequest, serviceErr.Error())
+		return
+	}
+
+	response.ErrorJSON(w, http.StatusInternalServerError, message)
+}
+
 // Вспомогательный метод для ожидания статуса
-func (h *ControlHandler) waitForServiceStatus(ctx context.Context, client service_control.Client, serviceName string, expectedStatus int) error {
-	statusCmd := fmt.Sprintf("sc query \"%s\"", serviceName)
+func (h *ControlHandler) waitForServiceStatus(ctx context.Context, client service_control.Client, serviceName string, expectedStatus int) (err error) {
+	ctx, span := tracing.Start(ctx, "control.waitForServiceStatus",
+		tracing.AttrServiceName.String(serviceName),
+		attribute.Int("swsm.service.expected_status", expectedStatus),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	backoff := 100 * time.Millisecond
 	maxBackoff := 5 * time.Second
 
@@ -582,12 +678,12 @@ func (h *ControlHandler) waitForServiceStatus(ctx context.Context, client servic
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-time.After(backoff):
-			result, err := client.RunCommand(ctx, statusCmd)
+			state, err := h.manager.Query(ctx, client, serviceName)
 			if err != nil {
 				return err
 			}
 
-			currentStatus := utils.GetStatus(result)
+			currentStatus := state.State
 
 			if currentStatus == expectedStatus {
 				return nil
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,12 +14,16 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	broadcastMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	netutilsMock "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
 	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
@@ -26,6 +31,11 @@ func init() {
 	logger.InitLogger("error", "stdout")
 }
 
+// serviceState Состояние службы, которое возвращает мок ServiceManager.
+func serviceState(state int) *models.WindowsServiceState {
+	return &models.WindowsServiceState{Name: "TestService", State: state, Status: utils.GetStatusByINT(state)}
+}
+
 // createContextWithCreds Создаёт контекст с учётными данными пользователя.
 func createContextWithCreds(login, userID string, serverID, serviceID int64) context.Context {
 	ctx := context.Background()
@@ -33,9 +43,17 @@ func createContextWithCreds(login, userID string, serverID, serviceID int64) con
 	ctx = context.WithValue(ctx, contextkeys.UserID, userID)
 	ctx = context.WithValue(ctx, contextkeys.ServerID, serverID)
 	ctx = context.WithValue(ctx, contextkeys.ServiceID, serviceID)
+	ctx = context.WithValue(ctx, contextkeys.Role, models.RoleOperator)
 	return ctx
 }
 
+// newMockStorage Создаёт мок хранилища, в котором у служб нет правил доступа (управление разрешено).
+func newMockStorage(ctrl *gomock.Controller) *storageMocks.MockStorage {
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().ListServicePermissions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return mockStorage
+}
+
 // ============================================================================
 // ServiceStop
 // ============================================================================
@@ -45,9 +63,10 @@ func TestServiceStopErrServerNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	// возвращаем ErrServerNotFound
@@ -55,7 +74,7 @@ func TestServiceStopErrServerNotFound(t *testing.T) {
 		GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
 		Return(nil, errs.NewErrServerNotFound(100, "any-id-user-1", errors.New("server not in database")))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	// создаём запрос с контекстом пользователя
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
@@ -79,9 +98,10 @@ func TestServiceStopGetServerGenericError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	// generic ошибка (не специфичная ErrServerNotFound)
@@ -89,7 +109,7 @@ func TestServiceStopGetServerGenericError(t *testing.T) {
 		GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
 		Return(nil, errors.New("database connection timeout"))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
@@ -111,9 +131,10 @@ func TestServiceStopErrServiceNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	// получаем сервер успешно
@@ -132,7 +153,7 @@ func TestServiceStopErrServiceNotFound(t *testing.T) {
 		GetService(gomock.Any(), int64(100), int64(10), "any-id-user-1").
 		Return(nil, errs.NewErrServiceNotFound("any-id-user-1", 100, 10, errors.New("service not found")))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
@@ -154,9 +175,10 @@ func TestServiceStopGetServiceGenericError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	// получаем сервер успешно
@@ -175,7 +197,7 @@ func TestServiceStopGetServiceGenericError(t *testing.T) {
 		GetService(gomock.Any(), int64(100), int64(10), "any-id-user-1").
 		Return(nil, errors.New("database read error"))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
@@ -192,14 +214,63 @@ func TestServiceStopGetServiceGenericError(t *testing.T) {
 	assert.Equal(t, "Ошибка при получении информации о службе", got.Message)
 }
 
+// TestServiceStopInvalidServiceName Проверяет, что команды не выполняются для службы с недопустимым именем.
+func TestServiceStopInvalidServiceName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := newMockStorage(ctrl)
+	mockChecker := netutilsMock.NewMockChecker(ctrl)
+	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
+	mockWinRMPort := "5985"
+
+	mockStorage.EXPECT().
+		GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+		Return(&models.Server{
+			ID:       100,
+			Name:     "TestServer",
+			Address:  "192.168.1.1",
+			Username: "admin",
+			Password: "password",
+		}, nil)
+
+	// имя службы, сохраненное до введения проверки имен
+	mockStorage.EXPECT().
+		GetService(gomock.Any(), int64(100), int64(10), "any-id-user-1").
+		Return(&models.Service{
+			ID:            10,
+			ServiceName:   `spooler" & calc & "`,
+			DisplayedName: "Test Service",
+		}, nil)
+
+	// ни проверки сервера, ни создания клиента быть не должно
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
+
+	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
+	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ServiceStop(w, r)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	var got response.APIError
+	json.NewDecoder(res.Body).Decode(&got)
+	assert.Equal(t, "Недопустимое имя службы", got.Message)
+}
+
 // TestServiceStopCheckWinRMFalse Проверяет обработку недоступного хоста.
 func TestServiceStopCheckWinRMFalse(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
@@ -226,10 +297,10 @@ func TestServiceStopCheckWinRMFalse(t *testing.T) {
 
 	// хост недоступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(false)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -251,9 +322,10 @@ func TestServiceStopCreateClientError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockWinRMPort := "5985"
 
 	ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
@@ -280,15 +352,15 @@ func TestServiceStopCreateClientError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// ошибка создания клиента
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("WinRM authentication failed"))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -309,9 +381,10 @@ func TestServiceStopQueryStatusError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -339,20 +412,20 @@ func TestServiceStopQueryStatusError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	// ошибка при sc query
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "TestService"`).
-		Return("", errors.New("WinRM connection timeout"))
+	// ошибка при получении состояния
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "TestService").
+		Return(nil, errors.New("WinRM connection timeout"))
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -373,9 +446,10 @@ func TestServiceStopRunningSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -403,28 +477,28 @@ func TestServiceStopRunningSuccess(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность вызовов:
-	// 1. sc query (начальный статус) - возвращает RUNNING
-	// 2. sc stop - успешно, пустой вывод
-	// 3. sc query (в waitForServiceStatus) - возвращает STOPPED
+	// 1. Query (начальный статус) - возвращает RUNNING
+	// 2. Stop - успешно
+	// 3. Query (в waitForServiceStatus) - возвращает STOPPED
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
 	)
 
 	// обновляем статус в БД
@@ -432,7 +506,7 @@ func TestServiceStopRunningSuccess(t *testing.T) {
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Остановлена").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -453,9 +527,10 @@ func TestServiceStopAlreadyStopped(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -483,25 +558,25 @@ func TestServiceStopAlreadyStopped(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	// sc query возвращает STOPPED
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "TestService"`).
-		Return("STATE : 1 STOPPED", nil)
+	// Query возвращает STOPPED
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "TestService").
+		Return(serviceState(utils.ServiceStopped), nil)
 
 	// обновляем статус в БД
 	mockStorage.EXPECT().
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Остановлена").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -517,14 +592,15 @@ func TestServiceStopAlreadyStopped(t *testing.T) {
 	assert.Equal(t, "Служба `Test Service` уже остановлена", got.Message)
 }
 
-// TestServiceStopRunCommandError Проверяет ошибку при sc stop команде.
+// TestServiceStopRunCommandError Проверяет ошибку при выполнении остановки.
 func TestServiceStopRunCommandError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -552,27 +628,27 @@ func TestServiceStopRunCommandError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - успешно, RUNNING
-	// 2. sc stop - ошибка транспорта
+	// 1. Query (начальный статус) - успешно, RUNNING
+	// 2. Stop - ошибка транспорта
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", errors.New("WinRM transport error")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(errors.New("WinRM transport error")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -588,14 +664,15 @@ func TestServiceStopRunCommandError(t *testing.T) {
 	assert.Equal(t, "Не удалось остановить службу", got.Message)
 }
 
-// TestServiceStopParseServiceError Проверяет парсинг ошибки из sc stop вывода.
-func TestServiceStopParseServiceError(t *testing.T) {
+// TestServiceStopServiceError Проверяет возврат ошибки службы Windows при остановке.
+func TestServiceStopServiceError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -623,27 +700,27 @@ func TestServiceStopParseServiceError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query - RUNNING
-	// 2. sc stop - возвращает FAILED 1061
+	// 1. Query - RUNNING
+	// 2. Stop - ошибка службы 1061
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("[SC] ControlService FAILED 1061:\n\nThe service cannot accept control messages at this time.", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(errs.NewServiceError(errs.ParseErrorCode(1061), 1061)),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -656,8 +733,8 @@ func TestServiceStopParseServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 	var got response.APIError
 	json.NewDecoder(res.Body).Decode(&got)
-	// Проверяем, что ошибка парсится корректно (код 1061)
-	assert.NotEmpty(t, got.Message)
+	// ошибка службы возвращается пользователю с кодом Win32
+	assert.Equal(t, "Код 1061, The service cannot accept control messages at this time", got.Message)
 }
 
 // TestServiceStopWaitForStatusError Проверяет ошибку при ожидании статуса.
@@ -665,9 +742,10 @@ func TestServiceStopWaitForStatusError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -695,31 +773,31 @@ func TestServiceStopWaitForStatusError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - RUNNING
-	// 2. sc stop - успешно
-	// 3. sc query (в waitForServiceStatus) - ошибка
+	// 1. Query (начальный статус) - RUNNING
+	// 2. Stop - успешно
+	// 3. Query (в waitForServiceStatus) - ошибка
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("", errors.New("WinRM connection lost")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(nil, errors.New("WinRM connection lost")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/stop", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -744,9 +822,10 @@ func TestServiceStartRunningSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -774,28 +853,28 @@ func TestServiceStartRunningSuccess(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - STOPPED
-	// 2. sc start - успешно
-	// 3. sc query (в waitForServiceStatus) - RUNNING
+	// 1. Query (начальный статус) - STOPPED
+	// 2. Start - успешно
+	// 3. Query (в waitForServiceStatus) - RUNNING
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
 	)
 
 	// обновляем статус в БД
@@ -803,7 +882,7 @@ func TestServiceStartRunningSuccess(t *testing.T) {
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Работает").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/start", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -824,9 +903,10 @@ func TestServiceStartAlreadyRunning(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -854,25 +934,25 @@ func TestServiceStartAlreadyRunning(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	// sc query возвращает RUNNING
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "TestService"`).
-		Return("STATE : 4 RUNNING", nil)
+	// Query возвращает RUNNING
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "TestService").
+		Return(serviceState(utils.ServiceRunning), nil)
 
 	// обновляем статус в БД
 	mockStorage.EXPECT().
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Работает").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/start", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -888,14 +968,15 @@ func TestServiceStartAlreadyRunning(t *testing.T) {
 	assert.Equal(t, "Служба `Test Service` уже запущена", got.Message)
 }
 
-// TestServiceStartRunCommandError Проверяет ошибку при sc start команде.
+// TestServiceStartRunCommandError Проверяет ошибку при выполнении запуска.
 func TestServiceStartRunCommandError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -923,27 +1004,27 @@ func TestServiceStartRunCommandError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - STOPPED
-	// 2. sc start - ошибка транспорта
+	// 1. Query (начальный статус) - STOPPED
+	// 2. Start - ошибка транспорта
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", errors.New("WinRM transport error")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(errors.New("WinRM transport error")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/start", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -959,14 +1040,15 @@ func TestServiceStartRunCommandError(t *testing.T) {
 	assert.Equal(t, "Не удалось запустить службу", got.Message)
 }
 
-// TestServiceStartParseServiceError Проверяет парсинг ошибки из sc start вывода.
-func TestServiceStartParseServiceError(t *testing.T) {
+// TestServiceStartServiceError Проверяет возврат ошибки службы Windows при запуске.
+func TestServiceStartServiceError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -994,27 +1076,27 @@ func TestServiceStartParseServiceError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query - STOPPED
-	// 2. sc start - возвращает FAILED 1051 (зависимые службы)
+	// 1. Query - STOPPED
+	// 2. Start - ошибка службы 1051 (зависимые службы)
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("[SC] ControlService FAILED 1051:\n\nA stop control has been sent to a service that other running services are dependent on.", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(errs.NewServiceError(errs.ParseErrorCode(1051), 1051)),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/start", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1035,9 +1117,10 @@ func TestServiceStartWaitForStatusError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1065,31 +1148,31 @@ func TestServiceStartWaitForStatusError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - STOPPED
-	// 2. sc start - успешно
-	// 3. sc query (в waitForServiceStatus) - ошибка
+	// 1. Query (начальный статус) - STOPPED
+	// 2. Start - успешно
+	// 3. Query (в waitForServiceStatus) - ошибка
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("", errors.New("WinRM connection lost")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(nil, errors.New("WinRM connection lost")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/start", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1114,9 +1197,10 @@ func TestServiceRestartRunningSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1144,36 +1228,36 @@ func TestServiceRestartRunningSuccess(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - RUNNING
-	// 2. sc stop - успешно
-	// 3. sc query (ожидание остановки) - STOPPED
-	// 4. sc start - успешно
-	// 5. sc query (ожидание запуска) - RUNNING
+	// 1. Query (начальный статус) - RUNNING
+	// 2. Stop - успешно
+	// 3. Query (ожидание остановки) - STOPPED
+	// 4. Start - успешно
+	// 5. Query (ожидание запуска) - RUNNING
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
 	)
 
 	// обновляем статус в БД дважды (остановка и запуск)
@@ -1185,7 +1269,7 @@ func TestServiceRestartRunningSuccess(t *testing.T) {
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Работает").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1206,9 +1290,10 @@ func TestServiceRestartStoppedSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1236,28 +1321,28 @@ func TestServiceRestartStoppedSuccess(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - STOPPED
-	// 2. sc start - успешно
-	// 3. sc query (ожидание запуска) - RUNNING
+	// 1. Query (начальный статус) - STOPPED
+	// 2. Start - успешно
+	// 3. Query (ожидание запуска) - RUNNING
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
 	)
 
 	// обновляем статус в БД
@@ -1265,7 +1350,7 @@ func TestServiceRestartStoppedSuccess(t *testing.T) {
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Работает").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1281,14 +1366,15 @@ func TestServiceRestartStoppedSuccess(t *testing.T) {
 	assert.Equal(t, "Служба `Test Service` перезапущена", got.Message)
 }
 
-// TestServiceRestartRunCommandStopError Проверяет ошибку при sc stop (для RUNNING).
+// TestServiceRestartRunCommandStopError Проверяет ошибку при остановке (для RUNNING).
 func TestServiceRestartRunCommandStopError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1316,27 +1402,27 @@ func TestServiceRestartRunCommandStopError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - RUNNING
-	// 2. sc stop - ошибка транспорта
+	// 1. Query (начальный статус) - RUNNING
+	// 2. Stop - ошибка транспорта
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", errors.New("WinRM connection error")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(errors.New("WinRM connection error")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1352,14 +1438,15 @@ func TestServiceRestartRunCommandStopError(t *testing.T) {
 	assert.Equal(t, "Не удалось остановить службу `Test Service`", got.Message)
 }
 
-// TestServiceRestartParseServiceErrorStop Проверяет парсинг ошибки из sc stop вывода.
-func TestServiceRestartParseServiceErrorStop(t *testing.T) {
+// TestServiceRestartServiceErrorStop Проверяет возврат ошибки службы Windows при остановке.
+func TestServiceRestartServiceErrorStop(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1387,27 +1474,27 @@ func TestServiceRestartParseServiceErrorStop(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query - RUNNING
-	// 2. sc stop - возвращает FAILED 1061
+	// 1. Query - RUNNING
+	// 2. Stop - ошибка службы 1061
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("[SC] ControlService FAILED 1061:\n\nThe service cannot accept control messages at this time.", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(errs.NewServiceError(errs.ParseErrorCode(1061), 1061)),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1428,9 +1515,10 @@ func TestServiceRestartWaitForStopError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1458,31 +1546,31 @@ func TestServiceRestartWaitForStopError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - RUNNING
-	// 2. sc stop - успешно
-	// 3. sc query (ожидание остановки) - ошибка
+	// 1. Query (начальный статус) - RUNNING
+	// 2. Stop - успешно
+	// 3. Query (ожидание остановки) - ошибка
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("", errors.New("WinRM connection lost")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(nil, errors.New("WinRM connection lost")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1503,9 +1591,10 @@ func TestServiceRestartRunCommandStartError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1533,32 +1622,32 @@ func TestServiceRestartRunCommandStartError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - RUNNING
-	// 2. sc stop - успешно
-	// 3. sc query (ожидание остановки) - STOPPED
-	// 4. sc start - ошибка транспорта
+	// 1. Query (начальный статус) - RUNNING
+	// 2. Stop - успешно
+	// 3. Query (ожидание остановки) - STOPPED
+	// 4. Start - ошибка транспорта
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 4 RUNNING", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc stop "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", errors.New("service startup failed")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceRunning), nil),
+		mockManager.EXPECT().
+			Stop(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(errors.New("service startup failed")),
 	)
 
 	// БД будет обновлён для остановки
@@ -1566,7 +1655,7 @@ func TestServiceRestartRunCommandStartError(t *testing.T) {
 		ChangeServiceStatus(gomock.Any(), int64(100), "TestService", "Остановлена").
 		Return(nil)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1582,14 +1671,15 @@ func TestServiceRestartRunCommandStartError(t *testing.T) {
 	assert.Equal(t, "Не удалось запустить службу `Test Service`", got.Message)
 }
 
-// TestServiceRestartParseServiceErrorStart Проверяет парсинг ошибки из sc start вывода.
-func TestServiceRestartParseServiceErrorStart(t *testing.T) {
+// TestServiceRestartServiceErrorStart Проверяет возврат ошибки службы Windows при запуске.
+func TestServiceRestartServiceErrorStart(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1617,27 +1707,27 @@ func TestServiceRestartParseServiceErrorStart(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query - STOPPED
-	// 2. sc start - возвращает FAILED 1052
+	// 1. Query - STOPPED
+	// 2. Start - ошибка службы 1052
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("[SC] ControlService FAILED 1052:\n\nThe requested control is not valid for this service.", nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(errs.NewServiceError(errs.ParseErrorCode(1052), 1052)),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1658,9 +1748,10 @@ func TestServiceRestartWaitForStartError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockChecker := netutilsMock.NewMockChecker(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockWinRMPort := "5985"
 
@@ -1688,31 +1779,31 @@ func TestServiceRestartWaitForStartError(t *testing.T) {
 
 	// хост доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.1", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// клиент создан успешно
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.1", "admin", "password").
+		CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Последовательность:
-	// 1. sc query (начальный статус) - STOPPED
-	// 2. sc start - успешно
-	// 3. sc query (ожидание запуска) - ошибка
+	// 1. Query (начальный статус) - STOPPED
+	// 2. Start - успешно
+	// 3. Query (ожидание запуска) - ошибка
 	gomock.InOrder(
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("STATE : 1 STOPPED", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc start "TestService"`).
-			Return("", nil),
-		mockClient.EXPECT().
-			RunCommand(gomock.Any(), `sc query "TestService"`).
-			Return("", errors.New("WinRM connection lost")),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(serviceState(utils.ServiceStopped), nil),
+		mockManager.EXPECT().
+			Start(gomock.Any(), mockClient, "TestService").
+			Return(nil),
+		mockManager.EXPECT().
+			Query(gomock.Any(), mockClient, "TestService").
+			Return(nil, errors.New("WinRM connection lost")),
 	)
 
-	handler := NewControlHandler(mockStorage, mockClientFactory, mockChecker, mockWinRMPort)
+	handler := NewControlHandler(mockStorage, mockClientFactory, mockManager, mockChecker, &config.WinRMConfig{Port: mockWinRMPort}, broadcast.NewNoopAdapter(nil), time.Hour)
 
 	r := httptest.NewRequest(http.MethodPost, "/service/restart", nil).WithContext(ctx)
 	w := httptest.NewRecorder()
@@ -1727,3 +1818,197 @@ func TestServiceRestartWaitForStartError(t *testing.T) {
 	json.NewDecoder(res.Body).Decode(&got)
 	assert.Equal(t, "Служба `Test Service` не запустилась в ожидаемое время", got.Message)
 }
+
+// ============================================================================
+// Правила доступа к службе
+// ============================================================================
+
+// TestServiceControlPermissions Проверяет, что правила доступа к службе проверяются до обращения к серверу.
+func TestServiceControlPermissions(t *testing.T) {
+	criticalService := []*models.ServicePermission{
+		{ID: 1, ServiceID: 10, Role: models.RoleAdmin, Start: true, Stop: true, Restart: true},
+		{ID: 2, ServiceID: 10, UserID: "any-id-user-2", Restart: true},
+	}
+
+	tests := []struct {
+		name           string
+		userID         string
+		role           models.Role
+		action         string
+		permissions    []*models.ServicePermission
+		permissionsErr error
+		expectedStatus int
+	}{
+		{"нет правил: оператор может остановить", "any-id-user-1", models.RoleOperator, models.ControlActionStop, nil, nil, http.StatusNotFound},
+		{"нет правил: наблюдатель не может запустить", "any-id-user-1", models.RoleViewer, models.ControlActionStart, nil, nil, http.StatusForbidden},
+		{"критичная служба: оператору запрещено", "any-id-user-1", models.RoleOperator, models.ControlActionStop, criticalService, nil, http.StatusForbidden},
+		{"критичная служба: администратору разрешено", "any-id-user-1", models.RoleAdmin, models.ControlActionStop, criticalService, nil, http.StatusNotFound},
+		{"критичная служба: пользователю разрешен перезапуск", "any-id-user-2", models.RoleOperator, models.ControlActionRestart, criticalService, nil, http.StatusNotFound},
+		{"критичная служба: пользователю запрещен запуск", "any-id-user-2", models.RoleOperator, models.ControlActionStart, criticalService, nil, http.StatusForbidden},
+		{"ошибка получения правил", "any-id-user-1", models.RoleOperator, models.ControlActionRestart, nil, errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).Return(tt.permissions, tt.permissionsErr)
+
+			// если действие разрешено, хендлер переходит к получению сервера
+			if tt.expectedStatus == http.StatusNotFound {
+				mockStorage.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), tt.userID).
+					Return(nil, errs.NewErrServerNotFound(100, tt.userID, errors.New("server not in database")))
+			}
+
+			handler := NewControlHandler(mockStorage, serviceControlMocks.NewMockClientFactory(ctrl), serviceControlMocks.NewMockServiceManager(ctrl), netutilsMock.NewMockChecker(ctrl), &config.WinRMConfig{Port: "5985"}, broadcast.NewNoopAdapter(nil), time.Hour)
+
+			ctx := createContextWithCreds("user", tt.userID, 100, 10)
+			ctx = context.WithValue(ctx, contextkeys.Role, tt.role)
+			r := httptest.NewRequest(http.MethodPost, "/service/"+tt.action, nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			switch tt.action {
+			case models.ControlActionStart:
+				handler.ServiceStart(w, r)
+			case models.ControlActionStop:
+				handler.ServiceStop(w, r)
+			case models.ControlActionRestart:
+				handler.ServiceRestart(w, r)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// ============================================================================
+// Критичные службы
+// ============================================================================
+
+// TestServiceControlCriticalApproval Проверяет, что остановка и перезапуск критичной службы
+// создают запрос на подтверждение вместо выполнения действия.
+func TestServiceControlCriticalApproval(t *testing.T) {
+	server := &models.Server{ID: 100, TeamID: 7, Name: "srv", Address: "10.0.0.1", Username: "admin", Password: "secret"}
+	service := &models.Service{ID: 10, DisplayedName: "Database", ServiceName: "MSSQLSERVER", Critical: true}
+
+	tests := []struct {
+		name           string
+		action         string
+		body           string
+		approvalID     int64
+		setup          func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker)
+		expectedStatus int
+	}{
+		{
+			name:   "остановка создает запрос и рассылает его команде",
+			action: models.ControlActionStop,
+			setup: func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {
+				s.EXPECT().AddControlApproval(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a models.ControlApproval) (*models.ControlApproval, error) {
+						assert.Equal(t, models.ControlActionStop, a.Action)
+						assert.Equal(t, "any-id-user-1", a.RequestedBy)
+						assert.WithinDuration(t, time.Now().Add(time.Hour), a.ExpiresAt, time.Minute)
+						a.ID, a.Status = 1, models.ApprovalStatusPending
+						return &a, nil
+					})
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).
+					Return([]*models.TeamMember{{UserID: "any-id-user-1"}, {UserID: "any-id-user-2"}}, nil)
+				b.EXPECT().Publish("user-any-id-user-1:approvals", gomock.Any()).Return(nil)
+				b.EXPECT().Publish("user-any-id-user-2:approvals", gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "перезапуск с комментарием",
+			action: models.ControlActionRestart,
+			body:   `{"comment":" плановое обновление "}`,
+			setup: func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {
+				s.EXPECT().AddControlApproval(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a models.ControlApproval) (*models.ControlApproval, error) {
+						assert.Equal(t, models.ControlActionRestart, a.Action)
+						assert.Equal(t, "плановое обновление", a.RequestComment)
+						a.ID = 2
+						return &a, nil
+					})
+				s.EXPECT().ListTeamMembers(gomock.Any(), int64(7)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "неверный формат комментария",
+			action:         models.ControlActionStop,
+			body:           `{"comment":`,
+			setup:          func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "действие уже ожидает подтверждения",
+			action: models.ControlActionStop,
+			setup: func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {
+				s.EXPECT().AddControlApproval(gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewErrDuplicatedApproval(10, models.ControlActionStop, errors.New("duplicate key")))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "ошибка создания запроса",
+			action: models.ControlActionRestart,
+			setup: func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {
+				s.EXPECT().AddControlApproval(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "подтвержденное действие выполняется",
+			action:     models.ControlActionStop,
+			approvalID: 1,
+			setup: func(s *storageMocks.MockStorage, b *broadcastMocks.MockBroadcaster, c *netutilsMock.MockChecker) {
+				c.EXPECT().CheckWinRM(gomock.Any(), server.Address, "5985", false, gomock.Any()).Return(false)
+			},
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := newMockStorage(ctrl)
+			mockBroadcaster := broadcastMocks.NewMockBroadcaster(ctrl)
+			mockChecker := netutilsMock.NewMockChecker(ctrl)
+
+			mockStorage.EXPECT().GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").Return(server, nil)
+			mockStorage.EXPECT().GetService(gomock.Any(), int64(100), int64(10), "any-id-user-1").Return(service, nil)
+			tt.setup(mockStorage, mockBroadcaster, mockChecker)
+
+			handler := NewControlHandler(mockStorage, serviceControlMocks.NewMockClientFactory(ctrl), serviceControlMocks.NewMockServiceManager(ctrl), mockChecker, &config.WinRMConfig{Port: "5985"}, mockBroadcaster, time.Hour)
+
+			ctx := createContextWithCreds("user", "any-id-user-1", 100, 10)
+			if tt.approvalID != 0 {
+				ctx = context.WithValue(ctx, contextkeys.ApprovalID, tt.approvalID)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/service/"+tt.action, strings.NewReader(tt.body)).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			if tt.action == models.ControlActionStop {
+				handler.ServiceStop(w, r)
+			} else {
+				handler.ServiceRestart(w, r)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusAccepted {
+				var approval models.ControlApproval
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&approval))
+				assert.Equal(t, int64(7), approval.TeamID)
+				assert.Equal(t, "Database", approval.DisplayedName)
+			}
+		})
+	}
+}
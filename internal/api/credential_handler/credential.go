@@ -0,0 +1,323 @@
+package credential_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// maxParallelTests Максимальное количество серверов, проверяемых одновременно при проверке профиля.
+const maxParallelTests = 10
+
+// CredentialHandler Обработчик для управления профилями учетных данных команды.
+type CredentialHandler struct {
+	storage       storage.Storage
+	fingerprinter service_control.Fingerprinter
+	clientPool    service_control.ClientPool // пул WinRM клиентов, сбрасываемый для связанных серверов при смене учетных данных
+}
+
+// NewCredentialHandler Конструктор CredentialHandler.
+func NewCredentialHandler(storage storage.Storage, fingerprinter service_control.Fingerprinter, clientPool service_control.ClientPool) *CredentialHandler {
+	return &CredentialHandler{
+		storage:       storage,
+		fingerprinter: fingerprinter,
+		clientPool:    clientPool,
+	}
+}
+
+// GetProfiles Возвращает список профилей учетных данных команды (без паролей).
+func (h *CredentialHandler) GetProfiles(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	profiles, err := h.storage.ListCredentialProfiles(r.Context(), creds.TeamID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении профилей учетных данных", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении профилей учетных данных")
+		return
+	}
+
+	if len(profiles) == 0 {
+		profiles = []*models.CredentialProfile{}
+	}
+
+	response.JSON(w, http.StatusOK, profiles)
+}
+
+// GetProfile Возвращает профиль учетных данных команды (без пароля).
+func (h *CredentialHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	profileID, ok := parseProfileID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.storage.GetCredentialProfile(r.Context(), profileID, creds.TeamID)
+	if err != nil {
+		writeProfileError(w, err, "Ошибка при получении профиля учетных данных")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// CreateProfile Создание профиля учетных данных команды.
+func (h *CredentialHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var profile models.CredentialProfile
+
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		logger.Log.Debug("Неверный формат запроса для создания профиля учетных данных", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := profile.CreateValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile.TeamID = creds.TeamID
+	profile.Name = strings.TrimSpace(profile.Name)
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profile.Name))
+
+	created, err := h.storage.AddCredentialProfile(ctx, profile)
+	if err != nil {
+		writeProfileError(w, err, "Ошибка создания профиля учетных данных")
+		return
+	}
+
+	logger.Log.Debug("Профиль учетных данных создан",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.Int64("profileID", created.ID))
+
+	response.JSON(w, http.StatusCreated, created)
+}
+
+// EditProfile Редактирование профиля учетных данных команды.
+// Новые логин и пароль сразу применяются ко всем связанным серверам.
+func (h *CredentialHandler) EditProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	profileID, ok := parseProfileID(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.CredentialProfile
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Log.Debug("Неверный формат запроса для редактирования профиля учетных данных", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := input.UpdateValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profileAuditSubject(profileID, input.Name)))
+
+	edited, err := h.storage.EditCredentialProfile(ctx, &input, profileID, creds.TeamID)
+	if err != nil {
+		writeProfileError(w, err, "Ошибка при обновлении профиля учетных данных")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profileAuditSubject(profileID, edited.Name)))
+
+	// клиенты связанных серверов со старыми учетными данными больше не используются
+	h.invalidateProfileServers(r, profileID, creds.TeamID)
+
+	logger.Log.Debug("Профиль учетных данных отредактирован",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.Int64("profileID", profileID),
+		logger.Int("servers", edited.ServersCount))
+
+	response.JSON(w, http.StatusOK, edited)
+}
+
+// DelProfile Удаление профиля учетных данных команды. Профиль, к которому привязаны серверы, не удаляется.
+func (h *CredentialHandler) DelProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	profileID, ok := parseProfileID(w, r)
+	if !ok {
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profileAuditSubject(profileID, "")))
+
+	if err := h.storage.DelCredentialProfile(ctx, profileID, creds.TeamID); err != nil {
+		writeProfileError(w, err, "Ошибка при удалении профиля учетных данных")
+		return
+	}
+
+	logger.Log.Debug("Профиль учетных данных удален",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.Int64("profileID", profileID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestProfile Проверяет учетные данные профиля на всех связанных серверах (подключением по WinRM).
+// В теле запроса можно передать новые логин, пароль, домен или механизм аутентификации,
+// чтобы проверить их перед сменой учетных данных профиля. Профиль при этом не изменяется.
+func (h *CredentialHandler) TestProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	profileID, ok := parseProfileID(w, r)
+	if !ok {
+		return
+	}
+
+	// тело запроса необязательно
+	var candidate models.CredentialProfile
+
+	if err := json.NewDecoder(r.Body).Decode(&candidate); err != nil && !errors.Is(err, io.EOF) {
+		logger.Log.Debug("Неверный формат запроса для проверки профиля учетных данных", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := candidate.UpdateValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profileAuditSubject(profileID, "")))
+
+	profile, err := h.storage.GetCredentialProfileWithPassword(ctx, profileID, creds.TeamID)
+	if err != nil {
+		writeProfileError(w, err, "Ошибка при получении профиля учетных данных")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, profileAuditSubject(profileID, profile.Name)))
+
+	profile.Merge(candidate)
+
+	servers, err := h.storage.ListCredentialProfileServers(ctx, profileID, creds.TeamID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении серверов профиля учетных данных", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении серверов профиля учетных данных")
+		return
+	}
+
+	results := make([]models.CredentialTestResult, len(servers))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxParallelTests)
+
+	for i, server := range servers {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, server *models.Server) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			server.CredentialAuth = profile.Auth
+
+			result := models.CredentialTestResult{ServerID: server.ID, Name: server.Name, Address: server.Address}
+
+			_, err := h.fingerprinter.GetFingerprint(ctx, server.Address, profile.Login(), profile.Password, server.ConnectionSettings())
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+
+			results[i] = result
+		}(i, server)
+	}
+
+	wg.Wait()
+
+	logger.Log.Debug("Профиль учетных данных проверен на связанных серверах",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.Int64("profileID", profileID),
+		logger.Int("servers", len(servers)))
+
+	response.JSON(w, http.StatusOK, results)
+}
+
+// invalidateProfileServers Сбрасывает клиентов в пуле для всех серверов, связанных с профилем.
+// Ошибка получения серверов не прерывает запрос: профиль уже сохранен, а клиенты со старым паролем
+// не переиспользуются, так как пароль входит в ключ клиента.
+func (h *CredentialHandler) invalidateProfileServers(r *http.Request, profileID, teamID int64) {
+	servers, err := h.storage.ListCredentialProfileServers(r.Context(), profileID, teamID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении серверов профиля учетных данных", logger.String("err", err.Error()))
+		return
+	}
+
+	for _, server := range servers {
+		h.clientPool.Invalidate(server.Address)
+	}
+}
+
+// parseProfileID Извлекает id профиля учетных данных из URL. При ошибке пишет ответ и возвращает false.
+func parseProfileID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "profileID"), 10, 64)
+	if err != nil || id <= 0 {
+		response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id профиля учетных данных")
+		return 0, false
+	}
+
+	return id, true
+}
+
+// profileAuditSubject Описание профиля учетных данных для журнала аудита.
+func profileAuditSubject(profileID int64, name string) string {
+	subject := "credential profile " + strconv.FormatInt(profileID, 10)
+	if name == "" {
+		return subject
+	}
+
+	return subject + " (" + name + ")"
+}
+
+// writeProfileError Преобразует ошибку работы с профилем учетных данных в HTTP-ответ.
+func writeProfileError(w http.ResponseWriter, err error, msg string) {
+	var (
+		errProfileNotFound   *errs.ErrCredentialProfileNotFound
+		errDuplicatedProfile *errs.ErrDuplicatedCredentialProfile
+		errProfileInUse      *errs.ErrCredentialProfileInUse
+	)
+
+	switch {
+	case errors.As(err, &errProfileNotFound):
+		response.ErrorJSON(w, http.StatusNotFound, "Профиль учетных данных не найден")
+	case errors.As(err, &errDuplicatedProfile):
+		response.ErrorJSON(w, http.StatusConflict, "Профиль учетных данных с таким названием уже существует")
+	case errors.As(err, &errProfileInUse):
+		response.ErrorJSON(w, http.StatusConflict, "Профиль учетных данных используется серверами")
+	default:
+		logger.Log.Warn(msg, logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,349 @@
+package credential_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// newRequest Создает запрос с данными пользователя, команды и параметрами URL роутера Chi.
+func newRequest(method string, body any, params map[string]string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(method, "/api/user/teams/5/credentials", &buf)
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextkeys.Login, "alice")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "user-1")
+	ctx = context.WithValue(ctx, contextkeys.Role, models.RoleAdmin)
+	ctx = context.WithValue(ctx, contextkeys.TeamID, int64(5))
+
+	return r.WithContext(ctx)
+}
+
+// TestGetProfiles Проверяет получение списка профилей учетных данных команды.
+func TestGetProfiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		profiles       []*models.CredentialProfile
+		storageErr     error
+		expectedStatus int
+		expectedLen    int
+	}{
+		{"список профилей", []*models.CredentialProfile{{ID: 1, Name: "DC"}, {ID: 2, Name: "SQL"}}, nil, http.StatusOK, 2},
+		{"нет профилей", nil, nil, http.StatusOK, 0},
+		{"ошибка хранилища", nil, errors.New("db error"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().ListCredentialProfiles(gomock.Any(), int64(5)).Return(tt.profiles, tt.storageErr)
+
+			w := httptest.NewRecorder()
+			NewCredentialHandler(mockStorage, nil, nil).GetProfiles(w, newRequest(http.MethodGet, nil, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var got []*models.CredentialProfile
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.NotNil(t, got)
+				assert.Len(t, got, tt.expectedLen)
+			}
+		})
+	}
+}
+
+// TestCreateProfile Проверяет создание профиля учетных данных.
+func TestCreateProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное создание",
+			body: models.CredentialProfile{Name: "  DC  ", Username: "monitor", Password: "secret", Domain: "CORP"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AddCredentialProfile(gomock.Any(), models.CredentialProfile{
+					TeamID: 5, Name: "DC", Username: "monitor", Password: "secret", Domain: "CORP",
+				}).Return(&models.CredentialProfile{ID: 3, TeamID: 5, Name: "DC"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "без пароля",
+			body:           models.CredentialProfile{Name: "DC", Username: "monitor"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "домен в логине и отдельно",
+			body:           models.CredentialProfile{Name: "DC", Username: `CORP\monitor`, Password: "secret", Domain: "CORP"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "невалидный JSON",
+			body:           "{invalid}",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "дубликат названия",
+			body: models.CredentialProfile{Name: "DC", Username: "monitor", Password: "secret"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AddCredentialProfile(gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewErrDuplicatedCredentialProfile("DC", errors.New("duplicate")))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			NewCredentialHandler(mockStorage, nil, nil).CreateProfile(w, newRequest(http.MethodPost, tt.body, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestEditProfile Проверяет редактирование (ротацию пароля) профиля учетных данных.
+func TestEditProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		profileID      string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		invalidated    []string // адреса серверов, клиенты которых сбрасываются в пуле
+		expectedStatus int
+	}{
+		{
+			name:      "смена пароля",
+			profileID: "3",
+			body:      models.CredentialProfile{Password: "rotated"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().EditCredentialProfile(gomock.Any(), &models.CredentialProfile{Password: "rotated"}, int64(3), int64(5)).
+					Return(&models.CredentialProfile{ID: 3, Name: "DC", ServersCount: 2}, nil)
+				m.EXPECT().ListCredentialProfileServers(gomock.Any(), int64(3), int64(5)).
+					Return([]*models.Server{{ID: 1, Address: "dc01"}, {ID: 2, Address: "dc02"}}, nil)
+			},
+			invalidated:    []string{"dc01", "dc02"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "ошибка получения связанных серверов",
+			profileID: "3",
+			body:      models.CredentialProfile{Password: "rotated"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().EditCredentialProfile(gomock.Any(), gomock.Any(), int64(3), int64(5)).
+					Return(&models.CredentialProfile{ID: 3, Name: "DC", ServersCount: 2}, nil)
+				m.EXPECT().ListCredentialProfileServers(gomock.Any(), int64(3), int64(5)).
+					Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "некорректный id",
+			profileID:      "abc",
+			body:           models.CredentialProfile{Password: "rotated"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "профиль не найден",
+			profileID: "3",
+			body:      models.CredentialProfile{Password: "rotated"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().EditCredentialProfile(gomock.Any(), gomock.Any(), int64(3), int64(5)).
+					Return(nil, errs.NewErrCredentialProfileNotFound(3, 5, nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			mockClientPool := serviceControlMocks.NewMockClientPool(ctrl)
+			for _, address := range tt.invalidated {
+				mockClientPool.EXPECT().Invalidate(address)
+			}
+
+			w := httptest.NewRecorder()
+			NewCredentialHandler(mockStorage, nil, mockClientPool).EditProfile(w,
+				newRequest(http.MethodPatch, tt.body, map[string]string{"profileID": tt.profileID}))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestDelProfile Проверяет удаление профиля учетных данных.
+func TestDelProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		storageErr     error
+		expectedStatus int
+	}{
+		{"успешное удаление", nil, http.StatusNoContent},
+		{"профиль не найден", errs.NewErrCredentialProfileNotFound(3, 5, nil), http.StatusNotFound},
+		{"профиль используется серверами", errs.NewErrCredentialProfileInUse(3, errors.New("fk")), http.StatusConflict},
+		{"ошибка хранилища", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().DelCredentialProfile(gomock.Any(), int64(3), int64(5)).Return(tt.storageErr)
+
+			w := httptest.NewRecorder()
+			NewCredentialHandler(mockStorage, nil, nil).DelProfile(w,
+				newRequest(http.MethodDelete, nil, map[string]string{"profileID": "3"}))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestTestProfile Проверяет проверку учетных данных профиля на связанных серверах.
+func TestTestProfile(t *testing.T) {
+	ntlm := models.WinRMAuthNTLM
+	kerberos := models.WinRMAuthKerberos
+
+	profile := &models.CredentialProfile{ID: 3, TeamID: 5, Name: "DC", Username: "monitor", Password: "secret", Domain: "CORP", Auth: &ntlm}
+	servers := []*models.Server{
+		{ID: 1, Name: "DC1", Address: "10.0.0.1"},
+		{ID: 2, Name: "DC2", Address: "10.0.0.2", WinRM: models.WinRMSettings{Auth: &kerberos}},
+	}
+
+	tests := []struct {
+		name               string
+		body               any
+		setupFingerprinter func(m *serviceControlMocks.MockFingerprinter)
+		expected           []models.CredentialTestResult
+	}{
+		{
+			name: "текущие учетные данные профиля",
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
+				// механизм аутентификации сервера важнее механизма профиля
+				m.EXPECT().GetFingerprint(gomock.Any(), "10.0.0.1", `CORP\monitor`, "secret", models.WinRMSettings{Auth: &ntlm}).
+					Return(uuid.New(), nil)
+				m.EXPECT().GetFingerprint(gomock.Any(), "10.0.0.2", `CORP\monitor`, "secret", models.WinRMSettings{Auth: &kerberos}).
+					Return(uuid.Nil, errors.New("access denied"))
+			},
+			expected: []models.CredentialTestResult{
+				{ServerID: 1, Name: "DC1", Address: "10.0.0.1", Success: true},
+				{ServerID: 2, Name: "DC2", Address: "10.0.0.2", Error: "access denied"},
+			},
+		},
+		{
+			name: "новый пароль перед ротацией",
+			body: models.CredentialProfile{Password: "rotated"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
+				m.EXPECT().GetFingerprint(gomock.Any(), gomock.Any(), `CORP\monitor`, "rotated", gomock.Any()).
+					Return(uuid.New(), nil).Times(2)
+			},
+			expected: []models.CredentialTestResult{
+				{ServerID: 1, Name: "DC1", Address: "10.0.0.1", Success: true},
+				{ServerID: 2, Name: "DC2", Address: "10.0.0.2", Success: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// копии, чтобы проверки не влияли друг на друга
+			p := *profile
+			list := make([]*models.Server, 0, len(servers))
+			for _, server := range servers {
+				s := *server
+				list = append(list, &s)
+			}
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().GetCredentialProfileWithPassword(gomock.Any(), int64(3), int64(5)).Return(&p, nil)
+			mockStorage.EXPECT().ListCredentialProfileServers(gomock.Any(), int64(3), int64(5)).Return(list, nil)
+
+			mockFingerprinter := serviceControlMocks.NewMockFingerprinter(ctrl)
+			tt.setupFingerprinter(mockFingerprinter)
+
+			w := httptest.NewRecorder()
+			NewCredentialHandler(mockStorage, mockFingerprinter, nil).TestProfile(w,
+				newRequest(http.MethodPost, tt.body, map[string]string{"profileID": "3"}))
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var got []models.CredentialTestResult
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("профиль не найден", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := storageMocks.NewMockStorage(ctrl)
+		mockStorage.EXPECT().GetCredentialProfileWithPassword(gomock.Any(), int64(3), int64(5)).
+			Return(nil, errs.NewErrCredentialProfileNotFound(3, 5, nil))
+
+		w := httptest.NewRecorder()
+		NewCredentialHandler(mockStorage, nil, nil).TestProfile(w,
+			newRequest(http.MethodPost, nil, map[string]string{"profileID": "3"}))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
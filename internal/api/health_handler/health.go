@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
@@ -101,6 +102,13 @@ func (h *HealthHandler) ServersStatuses(w http.ResponseWriter, r *http.Request)
 
 	servers, err := h.storage.ListServers(ctx, creds.UserID)
 
+	// API-токен может быть ограничен отдельными серверами
+	if creds.APIToken != nil {
+		servers = slices.DeleteFunc(servers, func(server *models.Server) bool {
+			return !creds.APIToken.AllowsServer(server.ID)
+		})
+	}
+
 	// если серверов у пользователя нет - возвращаем пустой срез серверов
 	if len(servers) == 0 {
 		servers = []*models.Server{}
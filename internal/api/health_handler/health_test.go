@@ -292,6 +292,7 @@ func TestHealthHandler_ServerStatus(t *testing.T) {
 func TestHealthHandler_ServersStatuses(t *testing.T) {
 	tests := []struct {
 		name              string
+		apiToken          *models.APIToken
 		setupMock         func(m *storageMocks.MockStorage, c *statusCacheStorageMocks.MockStatusCacheStorage)
 		wantStatus        int
 		wantStatusContent []models.ServerStatus
@@ -553,6 +554,21 @@ func TestHealthHandler_ServersStatuses(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "API-токен, ограниченный сервером, видит только его статус",
+			apiToken: &models.APIToken{ID: 1, ServerIDs: []int64{2}, Restricted: true},
+			setupMock: func(m *storageMocks.MockStorage, c *statusCacheStorageMocks.MockStatusCacheStorage) {
+				m.EXPECT().
+					ListServers(gomock.Any(), "any-id-user-1").
+					Return([]*models.Server{{ID: 1, Address: "192.168.1.1"}, {ID: 2, Address: "192.168.1.2"}}, nil)
+
+				c.EXPECT().Get(int64(2)).Return(models.ServerStatus{ServerID: 2, Address: "192.168.1.2", Status: models.StatusOK}, true)
+			},
+			wantStatus: http.StatusOK,
+			wantStatusContent: []models.ServerStatus{
+				{ServerID: 2, Address: "192.168.1.2", Status: models.StatusOK},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -565,6 +581,9 @@ func TestHealthHandler_ServersStatuses(t *testing.T) {
 			mockChecker := netutilsMocks.NewMockChecker(ctrl)
 
 			mockCtx := createContextWithCreds("test", "any-id-user-1", int64(0))
+			if tt.apiToken != nil {
+				mockCtx = context.WithValue(mockCtx, contextkeys.APIToken, tt.apiToken)
+			}
 
 			tt.setupMock(mockStorage, mockCacheStorage)
 
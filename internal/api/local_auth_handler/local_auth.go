@@ -0,0 +1,283 @@
+package local_auth_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/local"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// LocalAuthHandler Обработчик встроенной аутентификации (AUTH_PROVIDER=local):
+// вход, обновление токенов, выход, смена пароля и создание пользователей.
+type LocalAuthHandler struct {
+	storage    storage.Storage
+	adapter    *local.LocalAdapter
+	refreshTTL time.Duration
+	sink       siem.Sink
+}
+
+// NewLocalAuthHandler Конструктор LocalAuthHandler.
+func NewLocalAuthHandler(storage storage.Storage, adapter *local.LocalAdapter, refreshTTL time.Duration, sink siem.Sink) *LocalAuthHandler {
+	return &LocalAuthHandler{
+		storage:    storage,
+		adapter:    adapter,
+		refreshTTL: refreshTTL,
+		sink:       sink,
+	}
+}
+
+// Login Вход по логину и паролю. Возвращает access-токен и refresh-токен.
+// Неудачные попытки передаются в SIEM.
+func (h *LocalAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var credentials models.Credentials
+
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		logger.Log.Debug("Неверный формат запроса на вход", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	credentials.Login = strings.TrimSpace(credentials.Login)
+	if credentials.Login == "" || credentials.Password == "" {
+		response.ErrorJSON(w, http.StatusBadRequest, "Необходимо указать логин и пароль")
+		return
+	}
+
+	user, err := h.storage.GetLocalUser(r.Context(), credentials.Login)
+	if err != nil {
+		var errWrongLogin *errs.ErrWrongLogin
+
+		if !errors.As(err, &errWrongLogin) {
+			logger.Log.Error("Ошибка при получении пользователя", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+			return
+		}
+
+		// сравнение с заглушкой, чтобы время ответа не выдавало существование логина
+		user = &models.LocalUser{}
+	}
+
+	if !local.CheckPassword(user.PasswordHash, credentials.Password) {
+		logger.Log.Debug("Неверный логин или пароль", logger.String("login", credentials.Login))
+
+		event := siem.NewAuthFailureEvent(middleware.ClientIP(r), r.URL.Path, "invalid credentials")
+		event.Login = credentials.Login
+		h.sink.Export(event)
+
+		response.ErrorJSON(w, http.StatusUnauthorized, "Неверный логин или пароль")
+		return
+	}
+
+	tokens, ok := h.issueTokens(w, r, user.User)
+	if !ok {
+		return
+	}
+
+	logger.Log.Info("Пользователь вошел", logger.String("login", user.Login))
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// Refresh Обмен refresh-токена на новую пару токенов. Использованный refresh-токен становится недействительным.
+func (h *LocalAuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := decodeRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.storage.UseRefreshToken(r.Context(), local.HashRefreshToken(refreshToken))
+	if err != nil {
+		var errNotFound *errs.ErrRefreshTokenNotFound
+
+		if errors.As(err, &errNotFound) {
+			h.sink.Export(siem.NewAuthFailureEvent(middleware.ClientIP(r), r.URL.Path, "invalid refresh token"))
+			response.ErrorJSON(w, http.StatusUnauthorized, "Сессия истекла, войдите заново")
+			return
+		}
+
+		logger.Log.Error("Ошибка при проверке refresh-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return
+	}
+
+	tokens, ok := h.issueTokens(w, r, *user)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// Logout Выход: refresh-токен отзывается. Выданный access-токен действует до истечения своего срока.
+func (h *LocalAuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := decodeRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.storage.DelRefreshToken(r.Context(), local.HashRefreshToken(refreshToken)); err != nil {
+		logger.Log.Error("Ошибка при отзыве refresh-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangePassword Смена пароля текущего пользователя. Все его refresh-токены отзываются.
+func (h *LocalAuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	// пароль может сменить только сам пользователь, а не автоматизация с его API-токеном
+	if creds.APIToken != nil {
+		response.ErrorJSON(w, http.StatusForbidden, "Смена пароля по API-токену запрещена")
+		return
+	}
+
+	var request models.PasswordChange
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log.Debug("Неверный формат запроса на смену пароля", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.storage.GetLocalUser(ctx, creds.Login)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении пользователя", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при смене пароля")
+		return
+	}
+
+	if !local.CheckPassword(user.PasswordHash, request.OldPassword) {
+		response.ErrorJSON(w, http.StatusForbidden, "Неверный текущий пароль")
+		return
+	}
+
+	hash, err := local.HashPassword(request.NewPassword)
+	if err != nil {
+		logger.Log.Error("Ошибка при вычислении хэша пароля", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при смене пароля")
+		return
+	}
+
+	if err = h.storage.SetUserPassword(ctx, user.ID, hash); err != nil {
+		logger.Log.Error("Ошибка при смене пароля", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при смене пароля")
+		return
+	}
+
+	logger.Log.Info("Пользователь сменил пароль", logger.String("login", creds.Login))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateUser Создание пользователя встроенной аутентификации администратором.
+// Роль пользователя определяется DEFAULT_ROLE и ADMIN_USERS.
+func (h *LocalAuthHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request models.NewLocalUser
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log.Debug("Неверный формат запроса на создание пользователя", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	request.Login = strings.TrimSpace(request.Login)
+	request.Email = strings.TrimSpace(request.Email)
+
+	models.SetAuditTarget(ctx, 0, 0, "user "+request.Login)
+
+	if err := request.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hash, err := local.HashPassword(request.Password)
+	if err != nil {
+		logger.Log.Error("Ошибка при вычислении хэша пароля", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании пользователя")
+		return
+	}
+
+	user := &models.User{ID: uuid.NewString(), Login: request.Login, Email: request.Email}
+
+	if err = h.storage.CreateLocalUser(ctx, user, hash); err != nil {
+		var errUserExists *errs.ErrUserAlreadyExists
+
+		if errors.As(err, &errUserExists) {
+			response.ErrorJSON(w, http.StatusConflict, "Пользователь с таким логином уже существует")
+			return
+		}
+
+		logger.Log.Error("Ошибка при создании пользователя", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании пользователя")
+		return
+	}
+
+	logger.Log.Info("Создан пользователь", logger.String("login", user.Login))
+
+	response.JSON(w, http.StatusCreated, user)
+}
+
+// issueTokens Выпускает пару токенов пользователя и сохраняет хэш refresh-токена.
+func (h *LocalAuthHandler) issueTokens(w http.ResponseWriter, r *http.Request, user models.User) (*models.AuthTokens, bool) {
+	accessToken, expiresAt, err := h.adapter.IssueAccessToken(user)
+	if err != nil {
+		logger.Log.Error("Ошибка выпуска access-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return nil, false
+	}
+
+	refreshToken, err := local.GenerateRefreshToken()
+	if err != nil {
+		logger.Log.Error("Ошибка выпуска refresh-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return nil, false
+	}
+
+	err = h.storage.AddRefreshToken(r.Context(), user.ID, local.HashRefreshToken(refreshToken), time.Now().Add(h.refreshTTL))
+	if err != nil {
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return nil, false
+	}
+
+	return &models.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}, true
+}
+
+// decodeRefreshToken Читает refresh-токен из тела запроса.
+func decodeRefreshToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var request models.RefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		response.ErrorJSON(w, http.StatusBadRequest, "Необходимо указать refresh_token")
+		return "", false
+	}
+
+	return request.RefreshToken, true
+}
@@ -0,0 +1,359 @@
+package local_auth_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/local"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	siemMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/siem/mocks"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// newTestHandler Создает обработчик с адаптером на тестовом секрете.
+func newTestHandler(t *testing.T, storage *storageMocks.MockStorage, sink siem.Sink) (*LocalAuthHandler, *local.LocalAdapter) {
+	adapter, err := local.NewLocalAdapter(local.LocalConfig{Secret: "0123456789abcdef0123456789abcdef", AccessTTL: 15 * time.Minute})
+	require.NoError(t, err)
+
+	return NewLocalAuthHandler(storage, adapter, 24*time.Hour, sink), adapter
+}
+
+// newRequest Создает запрос с телом body и (если задан login) данными пользователя в контексте.
+func newRequest(method string, body any, login string, apiToken *models.APIToken) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(method, "/api/auth/login", &buf)
+
+	if login != "" {
+		ctx := context.WithValue(r.Context(), contextkeys.Login, login)
+		ctx = context.WithValue(ctx, contextkeys.UserID, "user-1")
+		if apiToken != nil {
+			ctx = context.WithValue(ctx, contextkeys.APIToken, apiToken)
+		}
+		r = r.WithContext(ctx)
+	}
+
+	return r
+}
+
+// TestLogin Проверяет вход по логину и паролю.
+func TestLogin(t *testing.T) {
+	hash, err := local.HashPassword("correct-password")
+	require.NoError(t, err)
+
+	aliceUser := &models.LocalUser{User: models.User{ID: "user-1", Login: "alice"}, PasswordHash: hash}
+
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectFailure  bool
+		expectedStatus int
+	}{
+		{
+			name: "успешный вход",
+			body: models.Credentials{Login: " alice ", Password: "correct-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "alice").Return(aliceUser, nil)
+				m.EXPECT().AddRefreshToken(gomock.Any(), "user-1", gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "неверный пароль",
+			body: models.Credentials{Login: "alice", Password: "wrong-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "alice").Return(aliceUser, nil)
+			},
+			expectFailure:  true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "неизвестный логин",
+			body: models.Credentials{Login: "bob", Password: "correct-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "bob").
+					Return(nil, errs.NewErrWrongLoginOrPassword(errors.New("no rows")))
+			},
+			expectFailure:  true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "пользователь внешнего провайдера без пароля",
+			body: models.Credentials{Login: "carol", Password: "any-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "carol").
+					Return(&models.LocalUser{User: models.User{ID: "user-3", Login: "carol"}}, nil)
+			},
+			expectFailure:  true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "пустой пароль",
+			body:           models.Credentials{Login: "alice"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ошибка БД",
+			body: models.Credentials{Login: "alice", Password: "correct-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "alice").Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockSink := siemMocks.NewMockSink(ctrl)
+			tt.setupStorage(mockStorage)
+
+			if tt.expectFailure {
+				mockSink.EXPECT().Export(gomock.Any()).Do(func(event *siem.Event) {
+					assert.Equal(t, siem.EventTypeAuthFailure, event.Type)
+					assert.Equal(t, "invalid credentials", event.Error)
+					assert.NotEmpty(t, event.Login)
+				})
+			}
+
+			handler, adapter := newTestHandler(t, mockStorage, mockSink)
+
+			w := httptest.NewRecorder()
+			handler.Login(w, newRequest(http.MethodPost, tt.body, "", nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var tokens models.AuthTokens
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&tokens))
+				assert.Equal(t, "Bearer", tokens.TokenType)
+				assert.NotEmpty(t, tokens.RefreshToken)
+
+				claims, err := adapter.ValidateToken(context.Background(), tokens.AccessToken)
+				require.NoError(t, err)
+				assert.Equal(t, "user-1", claims.ID)
+				assert.Equal(t, "alice", claims.Login)
+			}
+		})
+	}
+}
+
+// TestRefresh Проверяет обмен refresh-токена на новую пару токенов.
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное обновление",
+			body: models.RefreshRequest{RefreshToken: "refresh-1"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().UseRefreshToken(gomock.Any(), local.HashRefreshToken("refresh-1")).
+					Return(&models.User{ID: "user-1", Login: "alice"}, nil)
+				m.EXPECT().AddRefreshToken(gomock.Any(), "user-1", gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "токен использован или истек",
+			body: models.RefreshRequest{RefreshToken: "refresh-1"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().UseRefreshToken(gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewErrRefreshTokenNotFound(errors.New("no rows")))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "без токена",
+			body:           models.RefreshRequest{},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			handler, _ := newTestHandler(t, mockStorage, siem.NewNoopSink())
+
+			w := httptest.NewRecorder()
+			handler.Refresh(w, newRequest(http.MethodPost, tt.body, "", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestLogout Проверяет отзыв refresh-токена при выходе.
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().DelRefreshToken(gomock.Any(), local.HashRefreshToken("refresh-1")).Return(nil)
+
+	handler, _ := newTestHandler(t, mockStorage, siem.NewNoopSink())
+
+	w := httptest.NewRecorder()
+	handler.Logout(w, newRequest(http.MethodPost, models.RefreshRequest{RefreshToken: "refresh-1"}, "", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// TestChangePassword Проверяет смену пароля текущего пользователя.
+func TestChangePassword(t *testing.T) {
+	hash, err := local.HashPassword("old-password")
+	require.NoError(t, err)
+
+	aliceUser := &models.LocalUser{User: models.User{ID: "user-1", Login: "alice"}, PasswordHash: hash}
+
+	tests := []struct {
+		name           string
+		body           any
+		apiToken       *models.APIToken
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "пароль изменен",
+			body: models.PasswordChange{OldPassword: "old-password", NewPassword: "new-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "alice").Return(aliceUser, nil)
+				m.EXPECT().SetUserPassword(gomock.Any(), "user-1", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, newHash string) error {
+						assert.True(t, local.CheckPassword(newHash, "new-password"))
+						return nil
+					})
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "неверный текущий пароль",
+			body: models.PasswordChange{OldPassword: "wrong-password", NewPassword: "new-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "alice").Return(aliceUser, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "слишком короткий новый пароль",
+			body:           models.PasswordChange{OldPassword: "old-password", NewPassword: "short"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "запрос с API-токеном",
+			body:           models.PasswordChange{OldPassword: "old-password", NewPassword: "new-password"},
+			apiToken:       &models.APIToken{ID: 1},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			handler, _ := newTestHandler(t, mockStorage, siem.NewNoopSink())
+
+			w := httptest.NewRecorder()
+			handler.ChangePassword(w, newRequest(http.MethodPost, tt.body, "alice", tt.apiToken))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestCreateUser Проверяет создание пользователя администратором.
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "пользователь создан",
+			body: models.NewLocalUser{Login: " bob ", Email: "bob@example.com", Password: "bob-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().CreateLocalUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *models.User, hash string) error {
+						assert.Equal(t, "bob", user.Login)
+						assert.NotEmpty(t, user.ID)
+						assert.True(t, local.CheckPassword(hash, "bob-password"))
+						return nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "логин занят",
+			body: models.NewLocalUser{Login: "bob", Password: "bob-password"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().CreateLocalUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errs.NewErrUserAlreadyExists("bob", errors.New("duplicate")))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "без пароля",
+			body:           models.NewLocalUser{Login: "bob"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			handler, _ := newTestHandler(t, mockStorage, siem.NewNoopSink())
+
+			w := httptest.NewRecorder()
+			handler.CreateUser(w, newRequest(http.MethodPost, tt.body, "admin", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package report_handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+)
+
+// ReportHandler Обработчик для получения сводных отчетов пользователя.
+type ReportHandler struct {
+	builder *report.Builder
+}
+
+// NewReportHandler Конструктор ReportHandler.
+func NewReportHandler(builder *report.Builder) *ReportHandler {
+	return &ReportHandler{
+		builder: builder,
+	}
+}
+
+// GetReport Возвращает сводный отчет пользователя за период.
+//
+// Параметры запроса:
+//   - period — daily (по умолчанию) или weekly,
+//   - format — json (по умолчанию), csv или html,
+//   - to — конец периода в формате RFC3339 (по умолчанию — текущий момент).
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	creds := models.GetContextCreds(ctx)
+
+	query := r.URL.Query()
+
+	period := models.ReportPeriod(query.Get("period"))
+	if period == "" {
+		period = models.ReportDaily
+	}
+	if !period.IsValid() {
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный период отчета, допустимые значения: daily, weekly")
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "html" {
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат отчета, допустимые значения: json, csv, html")
+		return
+	}
+
+	to := time.Now()
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат параметра to, ожидается RFC3339")
+			return
+		}
+		to = parsed
+	}
+
+	user := &models.User{ID: creds.UserID, Login: creds.Login}
+
+	rep, err := h.builder.Build(ctx, user, period, to)
+	if err != nil {
+		logger.Log.Error("Ошибка формирования отчета",
+			logger.String("login", creds.Login),
+			logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка формирования отчета")
+		return
+	}
+
+	if format == "json" {
+		response.JSON(w, http.StatusOK, rep)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+		err = report.RenderCSV(&buf, rep)
+	} else {
+		err = report.RenderHTML(&buf, rep)
+	}
+
+	if err != nil {
+		logger.Log.Error("Ошибка вывода отчета",
+			logger.String("login", creds.Login),
+			logger.String("format", format),
+			logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка формирования отчета")
+		return
+	}
+
+	filename := fmt.Sprintf("swsm-report-%s-%s.%s", period, to.Format("2006-01-02"), format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,153 @@
+package report_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// Создание контекста с данными о пользователе.
+func createContextWithCreds(login, userID string) context.Context {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, contextkeys.Login, login)
+	ctx = context.WithValue(ctx, contextkeys.UserID, userID)
+	return ctx
+}
+
+// TestReportHandler_GetReport Проверяет получение отчета в разных форматах и обработку ошибок.
+func TestReportHandler_GetReport(t *testing.T) {
+	to := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	// expectBuild Настраивает мок хранилища на успешное формирование отчета за период.
+	expectBuild := func(m *storageMocks.MockStorage, from time.Time) {
+		m.EXPECT().ListServers(gomock.Any(), "user-1").Return([]*models.Server{{ID: 1, Name: "srv1"}}, nil)
+		m.EXPECT().ListServerStatusHistory(gomock.Any(), "user-1", from, to).Return(nil, nil)
+		m.EXPECT().ListServiceStatusHistory(gomock.Any(), "user-1", from, to).Return(nil, nil)
+		m.EXPECT().ListControlActions(gomock.Any(), "user-1", from, to).Return(nil, nil)
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		setupMock       func(m *storageMocks.MockStorage)
+		wantStatus      int
+		wantContentType string
+		checkBody       func(t *testing.T, body []byte)
+	}{
+		{
+			name:  "json за сутки",
+			query: "?to=2025-01-08T00:00:00Z",
+			setupMock: func(m *storageMocks.MockStorage) {
+				expectBuild(m, to.Add(-24*time.Hour))
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			checkBody: func(t *testing.T, body []byte) {
+				var rep models.Report
+				require.NoError(t, json.Unmarshal(body, &rep))
+				assert.Equal(t, "tester", rep.Login)
+				assert.Equal(t, models.ReportDaily, rep.Period)
+				assert.Equal(t, 1, rep.ServersMonitored)
+			},
+		},
+		{
+			name:  "csv за неделю",
+			query: "?period=weekly&format=csv&to=2025-01-08T00:00:00Z",
+			setupMock: func(m *storageMocks.MockStorage) {
+				expectBuild(m, to.Add(-7*24*time.Hour))
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			checkBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "tester")
+			},
+		},
+		{
+			name:  "html",
+			query: "?format=html&to=2025-01-08T00:00:00Z",
+			setupMock: func(m *storageMocks.MockStorage) {
+				expectBuild(m, to.Add(-24*time.Hour))
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			checkBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "<td>srv1</td>")
+			},
+		},
+		{
+			name:       "неверный период",
+			query:      "?period=monthly",
+			setupMock:  func(m *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "неверный формат",
+			query:      "?format=pdf",
+			setupMock:  func(m *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "неверный параметр to",
+			query:      "?to=yesterday",
+			setupMock:  func(m *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "ошибка хранилища",
+			query: "?to=2025-01-08T00:00:00Z",
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "user-1").Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			h := NewReportHandler(report.NewBuilder(mockStorage))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/reports"+tt.query, nil)
+			req = req.WithContext(createContextWithCreds("tester", "user-1"))
+			rr := httptest.NewRecorder()
+
+			h.GetReport(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+			}
+
+			if tt.wantContentType != "" && tt.wantContentType != "application/json" {
+				assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			}
+
+			if tt.checkBody != nil {
+				tt.checkBody(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package server_handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 
 	//"github.com/trsv-dev/simple-windows-services-monitor/internal/api"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
@@ -12,6 +15,7 @@ import (
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/secrets"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
@@ -20,13 +24,19 @@ import (
 type ServerHandler struct {
 	storage       storage.Storage
 	fingerprinter service_control.Fingerprinter
+	diagnoser     service_control.Diagnoser
+	secrets       secrets.Provider
+	clientPool    service_control.ClientPool // пул WinRM клиентов, сбрасываемый при редактировании и удалении сервера
 }
 
 // NewServerHandler Конструктор ServerHandler.
-func NewServerHandler(storage storage.Storage, fingerprinter service_control.Fingerprinter) *ServerHandler {
+func NewServerHandler(storage storage.Storage, fingerprinter service_control.Fingerprinter, diagnoser service_control.Diagnoser, secrets secrets.Provider, clientPool service_control.ClientPool) *ServerHandler {
 	return &ServerHandler{
 		storage:       storage,
 		fingerprinter: fingerprinter,
+		diagnoser:     diagnoser,
+		secrets:       secrets,
+		clientPool:    clientPool,
 	}
 }
 
@@ -49,7 +59,38 @@ func (h *ServerHandler) AddServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fingerprint, err := h.fingerprinter.GetFingerprint(ctx, server.Address, server.Username, server.Password)
+	models.SetAuditTarget(ctx, 0, 0, models.ServerAuditTarget(server.Name, server.Address))
+
+	// команда, в которую добавляется сервер
+	status, msg := h.resolveServerTeam(ctx, &server, creds)
+	if status != 0 {
+		response.ErrorJSON(w, status, msg)
+		return
+	}
+
+	// логин, пароль и механизм аутентификации берутся из профиля учетных данных команды сервера
+	if server.HasCredentialProfile() {
+		status, msg := h.applyCredentialProfile(ctx, &server, *server.CredentialProfileID, server.TeamID)
+		if status != 0 {
+			response.ErrorJSON(w, status, msg)
+			return
+		}
+	}
+
+	// секрет должен находиться в пространстве команды сервера
+	if err := server.SecretValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// учетные данные из секрета используются только для подключения и не сохраняются в БД
+	connection := server
+	if status, msg := h.resolveSecret(ctx, &connection); status != 0 {
+		response.ErrorJSON(w, status, msg)
+		return
+	}
+
+	fingerprint, err := h.fingerprinter.GetFingerprint(ctx, connection.Address, connection.Username, connection.Password, connection.ConnectionSettings())
 	if err != nil {
 		logger.Log.Error("Ошибка получения UUID сервера", logger.String("err", err.Error()))
 		response.ErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения UUID сервера"))
@@ -74,6 +115,8 @@ func (h *ServerHandler) AddServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	models.SetAuditTarget(ctx, createdServer.ID, 0, "")
+
 	logger.Log.Debug("Сервер успешно добавлен пользователем",
 		logger.String("login", creds.Login), logger.String("address", server.Address))
 
@@ -86,6 +129,213 @@ func (h *ServerHandler) AddServer(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// resolveServerTeam Определяет команду нового сервера: если команда не указана — личная команда
+// пользователя, иначе проверяет, что пользователь является администратором указанной команды.
+// Возвращает HTTP-статус и сообщение об ошибке или 0, если команда определена.
+func (h *ServerHandler) resolveServerTeam(ctx context.Context, server *models.Server, creds *models.ContextCredentials) (int, string) {
+	if server.TeamID == 0 {
+		teamID, err := h.storage.GetDefaultTeamID(ctx, creds.UserID)
+		if err != nil {
+			var errTeamNotFound *errs.ErrTeamNotFound
+
+			// пользователь не администрирует ни одной команды (например, удалил личную)
+			if errors.As(err, &errTeamNotFound) {
+				return http.StatusBadRequest, "Необходимо указать команду сервера"
+			}
+
+			logger.Log.Error("Ошибка получения команды пользователя по умолчанию",
+				logger.String("login", creds.Login), logger.String("err", err.Error()))
+			return http.StatusInternalServerError, "Ошибка добавления сервера"
+		}
+
+		server.TeamID = teamID
+		return 0, ""
+	}
+
+	teamRole, err := h.storage.GetTeamRole(ctx, server.TeamID, creds.UserID)
+	if err != nil {
+		var errTeamNotFound *errs.ErrTeamNotFound
+
+		if errors.As(err, &errTeamNotFound) {
+			return http.StatusNotFound, "Команда не найдена"
+		}
+
+		logger.Log.Error("Ошибка получения роли в команде", logger.String("err", err.Error()))
+		return http.StatusInternalServerError, "Ошибка добавления сервера"
+	}
+
+	if !teamRole.Allows(models.RoleAdmin) {
+		logger.Log.Warn("Недостаточно прав для добавления сервера в команду",
+			logger.String("login", creds.Login),
+			logger.Int64("teamID", server.TeamID),
+			logger.String("role", string(teamRole)))
+		return http.StatusForbidden, "Недостаточно прав"
+	}
+
+	return 0, ""
+}
+
+// applyCredentialProfile Подставляет в сервер логин, пароль и механизм аутентификации профиля учетных данных команды.
+// Возвращает HTTP-статус и сообщение об ошибке или 0, если профиль применен.
+func (h *ServerHandler) applyCredentialProfile(ctx context.Context, server *models.Server, profileID, teamID int64) (int, string) {
+	profile, err := h.storage.GetCredentialProfileWithPassword(ctx, profileID, teamID)
+	if err != nil {
+		var errProfileNotFound *errs.ErrCredentialProfileNotFound
+
+		if errors.As(err, &errProfileNotFound) {
+			return http.StatusNotFound, "Профиль учетных данных не найден"
+		}
+
+		logger.Log.Error("Ошибка получения профиля учетных данных", logger.String("err", err.Error()))
+		return http.StatusInternalServerError, "Ошибка получения профиля учетных данных"
+	}
+
+	server.CredentialProfileID = &profile.ID
+	server.Username = profile.Login()
+	server.Password = profile.Password
+	server.CredentialAuth = profile.Auth
+
+	return 0, ""
+}
+
+// resolveSecret Подставляет в сервер логин и пароль из секрета, на который он ссылается.
+// Возвращает HTTP-статус и сообщение об ошибке или 0, если учетные данные получены.
+func (h *ServerHandler) resolveSecret(ctx context.Context, server *models.Server) (int, string) {
+	if !server.HasSecret() {
+		return 0, ""
+	}
+
+	if err := h.secrets.ResolveServer(ctx, server); err != nil {
+		return secretErrorStatus(err)
+	}
+
+	if server.Username == "" {
+		return http.StatusBadRequest, "Необходимо указать логин сервера или задать его в секрете"
+	}
+
+	return 0, ""
+}
+
+// secretErrorStatus Возвращает HTTP-статус и сообщение для ошибки получения учетных данных из хранилища секретов.
+func secretErrorStatus(err error) (int, string) {
+	var (
+		errSecretNotFound *errs.ErrSecretNotFound
+		errDisabled       *errs.ErrSecretProviderDisabled
+	)
+
+	switch {
+	case errors.As(err, &errSecretNotFound):
+		// отсутствующий секрет и секрет вне пространства команды не различаются
+		return http.StatusBadRequest, "Секрет не найден или недоступен"
+	case errors.As(err, &errDisabled):
+		return http.StatusBadRequest, "Внешнее хранилище секретов не настроено"
+	default:
+		logger.Log.Error("Ошибка получения учетных данных из хранилища секретов", logger.String("err", err.Error()))
+		return http.StatusInternalServerError, "Ошибка получения учетных данных из хранилища секретов"
+	}
+}
+
+// TestConnection Пошаговая проверка подключения к серверу до его добавления.
+// Принимает те же адрес, учетные данные (логин и пароль, профиль или секрет) и параметры WinRM,
+// что и добавление сервера, и возвращает отчет по этапам: DNS, ICMP, TCP, WinRM, аутентификация,
+// PowerShell и fingerprint. Отчет возвращается со статусом 200 и при неудачной проверке.
+func (h *ServerHandler) TestConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var server models.Server
+
+	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
+		logger.Log.Debug("Неверный формат запроса для проверки подключения к серверу", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := server.ConnectionValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.ServerAuditTarget(server.Name, server.Address))
+
+	// профиль учетных данных и секрет принадлежат команде, в которую будет добавлен сервер
+	if server.HasCredentialProfile() || server.HasSecret() {
+		if status, msg := h.resolveServerTeam(ctx, &server, creds); status != 0 {
+			response.ErrorJSON(w, status, msg)
+			return
+		}
+	}
+
+	if server.HasCredentialProfile() {
+		if status, msg := h.applyCredentialProfile(ctx, &server, *server.CredentialProfileID, server.TeamID); status != 0 {
+			response.ErrorJSON(w, status, msg)
+			return
+		}
+	}
+
+	if err := server.SecretValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if status, msg := h.resolveSecret(ctx, &server); status != 0 {
+		response.ErrorJSON(w, status, msg)
+		return
+	}
+
+	report := h.diagnoser.Diagnose(ctx, server.Address, server.Username, server.Password, server.ConnectionSettings())
+
+	logger.Log.Debug("Проверено подключение к серверу",
+		logger.String("login", creds.Login),
+		logger.String("address", server.Address),
+		logger.String("success", strconv.FormatBool(report.Success)))
+
+	response.JSON(w, http.StatusOK, report)
+}
+
+// TestServerConnection Пошаговая проверка подключения к добавленному серверу с его сохраненными
+// учетными данными и параметрами WinRM.
+func (h *ServerHandler) TestServerConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
+	if err != nil {
+		var (
+			errServerNotFound *errs.ErrServerNotFound
+			errSecretNotFound *errs.ErrSecretNotFound
+			errDisabled       *errs.ErrSecretProviderDisabled
+		)
+
+		switch {
+		case errors.As(err, &errServerNotFound):
+			logger.Log.Warn("Сервер не найден",
+				logger.String("login", creds.Login),
+				logger.Int64("serverID", creds.ServerID),
+				logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusNotFound, "Сервер не найден")
+		case errors.As(err, &errSecretNotFound), errors.As(err, &errDisabled):
+			status, msg := secretErrorStatus(err)
+			response.ErrorJSON(w, status, msg)
+		default:
+			logger.Log.Warn("Ошибка при получении информации о сервере", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении информации о сервере")
+		}
+		return
+	}
+
+	models.SetAuditTarget(ctx, server.ID, 0, models.ServerAuditTarget(server.Name, server.Address))
+
+	report := h.diagnoser.Diagnose(ctx, server.Address, server.Username, server.Password, server.ConnectionSettings())
+
+	logger.Log.Debug("Проверено подключение к серверу",
+		logger.String("login", creds.Login),
+		logger.Int64("serverID", server.ID),
+		logger.String("success", strconv.FormatBool(report.Success)))
+
+	response.JSON(w, http.StatusOK, report)
+}
+
 // EditServer Редактирование пользовательского сервера.
 func (h *ServerHandler) EditServer(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -113,6 +363,8 @@ func (h *ServerHandler) EditServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	oldAddress := old.Address
+
 	// читаем данные из входящего JSON с обновленной информацией о сервере
 	var input models.Server
 
@@ -132,21 +384,72 @@ func (h *ServerHandler) EditServer(w http.ResponseWriter, r *http.Request) {
 		old.Name = input.Name
 	}
 
-	if input.Username != "" {
+	switch {
+	// привязка к профилю учетных данных (в т.ч. смена профиля), ссылка на секрет при этом удаляется
+	case input.HasCredentialProfile():
+		status, msg := h.applyCredentialProfile(ctx, old, *input.CredentialProfileID, old.TeamID)
+		if status != 0 {
+			response.ErrorJSON(w, status, msg)
+			return
+		}
+		old.SecretPath = nil
+	// привязка к секрету (в т.ч. смена секрета), профиль учетных данных при этом отвязывается
+	case input.HasSecret():
+		old.SecretPath = input.SecretPath
+		old.CredentialProfileID = nil
+		old.CredentialAuth = nil
+		old.Password = ""
+		if input.Username != "" {
+			old.Username = input.Username
+		}
+	// отвязка от профиля или секрета: сервер снова использует собственные логин и пароль
+	case input.CredentialProfileID != nil || input.SecretPath != nil:
+		old.CredentialProfileID = nil
+		old.CredentialAuth = nil
+		old.SecretPath = nil
 		old.Username = input.Username
+		old.Password = input.Password
+	case old.HasCredentialProfile() && (input.Username != "" || input.Password != ""):
+		response.ErrorJSON(w, http.StatusBadRequest, "Логин и пароль сервера задаются профилем учетных данных")
+		return
+	case old.HasSecret() && input.Password != "":
+		response.ErrorJSON(w, http.StatusBadRequest, "Пароль сервера задается секретом")
+		return
+	default:
+		if input.Username != "" {
+			old.Username = input.Username
+		}
+
+		if input.Password != "" {
+			old.Password = input.Password
+		}
 	}
 
-	if input.Password != "" {
-		old.Password = input.Password
+	// параметры WinRM обновляются по отдельности, нулевой порт или таймаут сбрасывает параметр к глобальному
+	old.WinRM = old.WinRM.Merge(input.WinRM)
+
+	// проверяются итоговые параметры: например, insecure допустим только если сервер остается на HTTPS
+	if err = old.WinRM.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if input.Address != "" {
-		password := old.Password
-		if input.Password != "" {
-			password = input.Password
+	// секрет должен находиться в пространстве команды сервера
+	if err = old.SecretValidation(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// учетные данные из нового секрета нужны для проверки fingerprint
+	if input.HasSecret() {
+		if status, msg := h.resolveSecret(ctx, old); status != 0 {
+			response.ErrorJSON(w, status, msg)
+			return
 		}
+	}
 
-		fingerprint, err := h.fingerprinter.GetFingerprint(ctx, input.Address, input.Username, password)
+	if input.Address != "" {
+		fingerprint, err := h.fingerprinter.GetFingerprint(ctx, input.Address, old.Username, old.Password, old.ConnectionSettings())
 		if err != nil {
 			logger.Log.Error("Ошибка получения UUID сервера", logger.String("err", err.Error()))
 			response.ErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения UUID сервера"))
@@ -180,6 +483,9 @@ func (h *ServerHandler) EditServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// клиенты со старыми учетными данными или по старому адресу больше не используются
+	h.clientPool.Invalidate(oldAddress)
+
 	logger.Log.Debug("Сервер успешно отредактирован пользователем", logger.String("login", creds.Login),
 		logger.Int64("serverID", creds.ServerID))
 
@@ -197,7 +503,11 @@ func (h *ServerHandler) DelServer(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	creds := models.GetContextCreds(ctx)
 
-	err := h.storage.DelServer(ctx, creds.ServerID, creds.UserID)
+	// адрес нужен, чтобы после удаления сбросить клиентов сервера в пуле
+	server, err := h.storage.GetServer(ctx, creds.ServerID, creds.UserID)
+	if err == nil {
+		err = h.storage.DelServer(ctx, creds.ServerID, creds.UserID)
+	}
 
 	var ErrServerNotFound *errs.ErrServerNotFound
 
@@ -218,6 +528,8 @@ func (h *ServerHandler) DelServer(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.clientPool.Invalidate(server.Address)
+
 	logger.Log.Debug("Сервер успешно удален пользователем", logger.String("login", creds.Login),
 		logger.Int64("serverID", creds.ServerID))
 	//response.SuccessJSON(w, http.StatusAccepted, "Сервер успешно удален")
@@ -271,6 +583,13 @@ func (h *ServerHandler) GetServerList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// API-токен может быть ограничен отдельными серверами
+	if token := models.GetContextCreds(ctx).APIToken; token != nil {
+		servers = slices.DeleteFunc(servers, func(server *models.Server) bool {
+			return !token.AllowsServer(server.ID)
+		})
+	}
+
 	// если серверов у пользователя нет - возвращаем пустой срез серверов
 	if len(servers) == 0 {
 		servers = []*models.Server{}
@@ -13,11 +13,13 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	secretsMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/secrets/mocks"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
 	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
@@ -41,6 +43,10 @@ func TestAddServer(t *testing.T) {
 	defer ctrl.Finish()
 
 	testFingerprint := uuid.New()
+	profileID := int64(3)
+	ntlm := models.WinRMAuthNTLM
+	secretPath := "teams/10/servers/web-01"
+	foreignSecretPath := "teams/20/servers/web-01"
 
 	tests := []struct {
 		name               string
@@ -49,6 +55,7 @@ func TestAddServer(t *testing.T) {
 		body               interface{}
 		setupFingerprinter func(m *serviceControlMocks.MockFingerprinter)
 		setupStorage       func(m *storageMocks.MockStorage)
+		setupSecrets       func(m *secretsMocks.MockProvider) // nil - провайдер секретов не вызывается
 		wantStatus         int
 		wantErrorResp      *response.APIError
 		wantResponseFields []string
@@ -91,11 +98,13 @@ func TestAddServer(t *testing.T) {
 			},
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password").
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
 					Return(uuid.Nil, errors.New("connection failed"))
 			},
-			setupStorage: func(m *storageMocks.MockStorage) {},
-			wantStatus:   http.StatusInternalServerError,
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			wantStatus: http.StatusInternalServerError,
 			wantErrorResp: &response.APIError{
 				Code:    http.StatusInternalServerError,
 				Message: "Ошибка получения UUID сервера",
@@ -113,10 +122,11 @@ func TestAddServer(t *testing.T) {
 			},
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password").
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
 					Return(testFingerprint, nil)
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
 				m.EXPECT().
 					AddServer(gomock.Any(), gomock.Any(), "any-id-user-1").
 					Return(nil, errs.NewErrDuplicatedServer("192.168.1.1", errors.New("duplicate")))
@@ -139,10 +149,11 @@ func TestAddServer(t *testing.T) {
 			},
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password").
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
 					Return(testFingerprint, nil)
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
 				m.EXPECT().
 					AddServer(gomock.Any(), gomock.Any(), "any-id-user-1").
 					Return(nil, errors.New("database error"))
@@ -165,10 +176,11 @@ func TestAddServer(t *testing.T) {
 			},
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password").
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
 					Return(testFingerprint, nil)
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
 				m.EXPECT().
 					AddServer(gomock.Any(), gomock.Any(), "any-id-user-1").
 					Return(&models.Server{
@@ -182,6 +194,277 @@ func TestAddServer(t *testing.T) {
 			wantStatus:         http.StatusCreated,
 			wantResponseFields: []string{"id", "name", "address", "username", "fingerprint"},
 		},
+		{
+			name:   "успешное добавление сервера в указанную команду",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				TeamID:   20,
+				Name:     "TestServer",
+				Address:  "192.168.1.1",
+				Username: "admin",
+				Password: "password",
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
+				m.EXPECT().
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
+					Return(testFingerprint, nil)
+			},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(20), "any-id-user-1").Return(models.RoleAdmin, nil)
+				m.EXPECT().
+					AddServer(gomock.Any(), gomock.AssignableToTypeOf(models.Server{}), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server models.Server, _ string) (*models.Server, error) {
+						assert.Equal(t, int64(20), server.TeamID)
+						server.ID = 1
+						return &server, nil
+					})
+			},
+			wantStatus:         http.StatusCreated,
+			wantResponseFields: []string{"id", "team_id", "name", "address"},
+		},
+		{
+			name:   "недостаточно прав в указанной команде",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				TeamID:   20,
+				Name:     "TestServer",
+				Address:  "192.168.1.1",
+				Username: "admin",
+				Password: "password",
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(20), "any-id-user-1").Return(models.RoleOperator, nil)
+			},
+			wantStatus: http.StatusForbidden,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusForbidden,
+				Message: "Недостаточно прав",
+			},
+		},
+		{
+			name:   "пользователь не состоит в указанной команде",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				TeamID:   20,
+				Name:     "TestServer",
+				Address:  "192.168.1.1",
+				Username: "admin",
+				Password: "password",
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(20), "any-id-user-1").
+					Return(models.Role(""), errs.NewErrTeamNotFound(20, "any-id-user-1", errors.New("no rows")))
+			},
+			wantStatus: http.StatusNotFound,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusNotFound,
+				Message: "Команда не найдена",
+			},
+		},
+		{
+			name:   "добавление сервера с профилем учетных данных",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:                "TestServer",
+				Address:             "192.168.1.1",
+				CredentialProfileID: &profileID,
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
+				// логин, пароль и механизм аутентификации берутся из профиля
+				m.EXPECT().
+					GetFingerprint(gomock.Any(), "192.168.1.1", `CORP\monitor`, "secret", models.WinRMSettings{Auth: &ntlm}).
+					Return(testFingerprint, nil)
+			},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+				m.EXPECT().GetCredentialProfileWithPassword(gomock.Any(), profileID, int64(10)).
+					Return(&models.CredentialProfile{ID: profileID, TeamID: 10, Username: "monitor", Password: "secret", Domain: "CORP", Auth: &ntlm}, nil)
+				m.EXPECT().
+					AddServer(gomock.Any(), gomock.AssignableToTypeOf(models.Server{}), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server models.Server, _ string) (*models.Server, error) {
+						// механизм аутентификации профиля не записывается в параметры сервера
+						assert.Nil(t, server.WinRM.Auth)
+						server.ID = 1
+						server.Password = ""
+						return &server, nil
+					})
+			},
+			wantStatus:         http.StatusCreated,
+			wantResponseFields: []string{"id", "username", "credential_profile_id"},
+		},
+		{
+			name:   "профиль учетных данных другой команды",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:                "TestServer",
+				Address:             "192.168.1.1",
+				CredentialProfileID: &profileID,
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+				m.EXPECT().GetCredentialProfileWithPassword(gomock.Any(), profileID, int64(10)).
+					Return(nil, errs.NewErrCredentialProfileNotFound(profileID, 10, nil))
+			},
+			wantStatus: http.StatusNotFound,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusNotFound,
+				Message: "Профиль учетных данных не найден",
+			},
+		},
+		{
+			name:   "логин вместе с профилем учетных данных",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:                "TestServer",
+				Address:             "192.168.1.1",
+				Username:            "admin",
+				CredentialProfileID: &profileID,
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage:       func(m *storageMocks.MockStorage) {},
+			wantStatus:         http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "логин и пароль сервера задаются профилем учетных данных",
+			},
+		},
+		{
+			name:   "добавление сервера с секретом",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:       "TestServer",
+				Address:    "192.168.1.1",
+				SecretPath: &secretPath,
+			},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, server *models.Server) error {
+						server.Username = "admin"
+						server.Password = "vault-password"
+						return nil
+					})
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
+				m.EXPECT().
+					GetFingerprint(gomock.Any(), "192.168.1.1", "admin", "vault-password", models.WinRMSettings{}).
+					Return(testFingerprint, nil)
+			},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+				m.EXPECT().
+					AddServer(gomock.Any(), gomock.AssignableToTypeOf(models.Server{}), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server models.Server, _ string) (*models.Server, error) {
+						// учетные данные из секрета не передаются в хранилище
+						assert.Empty(t, server.Username)
+						assert.Empty(t, server.Password)
+						require.NotNil(t, server.SecretPath)
+						assert.Equal(t, secretPath, *server.SecretPath)
+						server.ID = 1
+						return &server, nil
+					})
+			},
+			wantStatus:         http.StatusCreated,
+			wantResponseFields: []string{"id", "secret_path"},
+		},
+		{
+			name:   "секрет не найден",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:       "TestServer",
+				Address:    "192.168.1.1",
+				Username:   "admin",
+				SecretPath: &secretPath,
+			},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					Return(errs.NewErrSecretNotFound(secretPath, nil))
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "Секрет не найден или недоступен",
+			},
+		},
+		{
+			name:   "секрет другой команды",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:       "TestServer",
+				Address:    "192.168.1.1",
+				Username:   "admin",
+				SecretPath: &foreignSecretPath,
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "путь к секрету должен начинаться с teams/10/",
+			},
+		},
+		{
+			name:   "секрет без логина и логин не указан",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:       "TestServer",
+				Address:    "192.168.1.1",
+				SecretPath: &secretPath,
+			},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, server *models.Server) error {
+						server.Password = "vault-password"
+						return nil
+					})
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "Необходимо указать логин сервера или задать его в секрете",
+			},
+		},
+		{
+			name:   "пароль вместе с секретом",
+			login:  "user",
+			userID: "any-id-user-1",
+			body: models.Server{
+				Name:       "TestServer",
+				Address:    "192.168.1.1",
+				Username:   "admin",
+				Password:   "password",
+				SecretPath: &secretPath,
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage:       func(m *storageMocks.MockStorage) {},
+			wantStatus:         http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "пароль сервера задается секретом",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,10 +472,15 @@ func TestAddServer(t *testing.T) {
 			mockFingerprinter := serviceControlMocks.NewMockFingerprinter(ctrl)
 			mockStorage := storageMocks.NewMockStorage(ctrl)
 
+			mockSecrets := secretsMocks.NewMockProvider(ctrl)
+
 			tt.setupFingerprinter(mockFingerprinter)
 			tt.setupStorage(mockStorage)
+			if tt.setupSecrets != nil {
+				tt.setupSecrets(mockSecrets)
+			}
 
-			handler := NewServerHandler(mockStorage, mockFingerprinter)
+			handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), mockSecrets, serviceControlMocks.NewMockClientPool(ctrl))
 
 			body, _ := json.Marshal(tt.body)
 			r := httptest.NewRequest(http.MethodPost, "/servers", bytes.NewBuffer(body))
@@ -239,6 +527,7 @@ func TestEditServer(t *testing.T) {
 		body               interface{}
 		setupFingerprinter func(m *serviceControlMocks.MockFingerprinter)
 		setupStorage       func(m *storageMocks.MockStorage)
+		setupSecrets       func(m *secretsMocks.MockProvider) // nil - провайдер секретов не вызывается
 		wantStatus         int
 		wantErrorResp      *response.APIError
 	}{
@@ -309,6 +598,44 @@ func TestEditServer(t *testing.T) {
 				Message: "необходимо указать имя сервера (минимум 3 символа)",
 			},
 		},
+		{
+			name:               "отключение проверки сертификата на сервере с HTTP",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"winrm": map[string]any{"insecure": true}},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				https := false
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, Username: "admin", Password: "password", WinRM: models.WinRMSettings{HTTPS: &https}}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "отключение проверки сертификата допустимо только для HTTPS",
+			},
+		},
+		{
+			name:               "Kerberos на сервере с HTTP",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"winrm": map[string]any{"auth": "kerberos"}},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				https := false
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, Username: "admin", Password: "password", WinRM: models.WinRMSettings{HTTPS: &https}}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "аутентификация Kerberos допустима только для HTTPS",
+			},
+		},
 		{
 			name:     "валидация - невалидный IP адрес",
 			login:    "user",
@@ -432,7 +759,7 @@ func TestEditServer(t *testing.T) {
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				differentFingerprint := uuid.New()
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "password").
+					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "password", models.WinRMSettings{}).
 					Return(differentFingerprint, nil)
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
@@ -467,7 +794,7 @@ func TestEditServer(t *testing.T) {
 				differentFingerprint := uuid.New()
 				// должен быть вызван с новым паролем!
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "newpassword").
+					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "newpassword", models.WinRMSettings{}).
 					Return(differentFingerprint, nil)
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
@@ -501,7 +828,7 @@ func TestEditServer(t *testing.T) {
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				// должен быть вызван со старым паролем!
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "oldpassword").
+					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "oldpassword", models.WinRMSettings{}).
 					Return(testFingerprint, nil) // совпадает с fingerprint старого адреса
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
@@ -639,7 +966,7 @@ func TestEditServer(t *testing.T) {
 			},
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {
 				m.EXPECT().
-					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "newpassword").
+					GetFingerprint(gomock.Any(), "192.168.1.2", "admin", "newpassword", models.WinRMSettings{}).
 					Return(uuid.Nil, errors.New("invalid credentials"))
 			},
 			setupStorage: func(m *storageMocks.MockStorage) {
@@ -690,6 +1017,182 @@ func TestEditServer(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:               "привязка сервера к профилю учетных данных",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"credential_profile_id": 3},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{
+						ID:          100,
+						TeamID:      10,
+						Name:        "TestServer",
+						Address:     "192.168.1.1",
+						Username:    "admin",
+						Password:    "password",
+						Fingerprint: testFingerprint,
+					}, nil)
+				m.EXPECT().GetCredentialProfileWithPassword(gomock.Any(), int64(3), int64(10)).
+					Return(&models.CredentialProfile{ID: 3, TeamID: 10, Username: "monitor", Password: "secret"}, nil)
+
+				m.EXPECT().
+					EditServer(gomock.Any(), gomock.Any(), int64(100), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server *models.Server, _ int64, _ string) (*models.Server, error) {
+						require.NotNil(t, server.CredentialProfileID)
+						assert.Equal(t, int64(3), *server.CredentialProfileID)
+						return server, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:               "отвязка сервера от профиля учетных данных",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"credential_profile_id": 0, "username": "admin", "password": "own"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				profileID := int64(3)
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{
+						ID:                  100,
+						TeamID:              10,
+						Name:                "TestServer",
+						Address:             "192.168.1.1",
+						Username:            "monitor",
+						Password:            "secret",
+						Fingerprint:         testFingerprint,
+						CredentialProfileID: &profileID,
+					}, nil)
+
+				m.EXPECT().
+					EditServer(gomock.Any(), gomock.Any(), int64(100), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server *models.Server, _ int64, _ string) (*models.Server, error) {
+						assert.Nil(t, server.CredentialProfileID)
+						assert.Equal(t, "admin", server.Username)
+						assert.Equal(t, "own", server.Password)
+						return server, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:               "смена пароля сервера, связанного с профилем",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               models.Server{Password: "newpassword"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				profileID := int64(3)
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, Username: "monitor", Password: "secret", CredentialProfileID: &profileID}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "Логин и пароль сервера задаются профилем учетных данных",
+			},
+		},
+		{
+			name:     "привязка сервера к секрету",
+			login:    "user",
+			userID:   "any-id-user-1",
+			serverID: 100,
+			body:     map[string]any{"secret_path": "teams/10/servers/web-01"},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, server *models.Server) error {
+						server.Password = "vault-password"
+						return nil
+					})
+			},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				profileID := int64(3)
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, TeamID: 10, Username: `CORP\monitor`, Password: "secret", CredentialProfileID: &profileID}, nil)
+
+				m.EXPECT().
+					EditServer(gomock.Any(), gomock.Any(), int64(100), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server *models.Server, _ int64, _ string) (*models.Server, error) {
+						// профиль учетных данных отвязывается
+						assert.Nil(t, server.CredentialProfileID)
+						require.NotNil(t, server.SecretPath)
+						assert.Equal(t, "teams/10/servers/web-01", *server.SecretPath)
+						return server, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:               "привязка сервера к секрету другой команды",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"secret_path": "teams/20/servers/web-01"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, TeamID: 10, Username: "admin", Password: "secret"}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "путь к секрету должен начинаться с teams/10/",
+			},
+		},
+		{
+			name:               "отвязка сервера от секрета",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               map[string]any{"secret_path": "", "username": "admin", "password": "own"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				secretPath := "teams/10/servers/web-01"
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, TeamID: 10, Username: "admin", Password: "vault-password", SecretPath: &secretPath}, nil)
+
+				m.EXPECT().
+					EditServer(gomock.Any(), gomock.Any(), int64(100), "any-id-user-1").
+					DoAndReturn(func(_ context.Context, server *models.Server, _ int64, _ string) (*models.Server, error) {
+						assert.Nil(t, server.SecretPath)
+						assert.Equal(t, "own", server.Password)
+						return server, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:               "смена пароля сервера, использующего секрет",
+			login:              "user",
+			userID:             "any-id-user-1",
+			serverID:           100,
+			body:               models.Server{Password: "newpassword"},
+			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				secretPath := "teams/10/servers/web-01"
+				m.EXPECT().
+					GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, TeamID: 10, Username: "admin", Password: "vault-password", SecretPath: &secretPath}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResp: &response.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "Пароль сервера задается секретом",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -697,10 +1200,21 @@ func TestEditServer(t *testing.T) {
 			mockFingerprinter := serviceControlMocks.NewMockFingerprinter(ctrl)
 			mockStorage := storageMocks.NewMockStorage(ctrl)
 
+			mockSecrets := secretsMocks.NewMockProvider(ctrl)
+
 			tt.setupFingerprinter(mockFingerprinter)
 			tt.setupStorage(mockStorage)
+			if tt.setupSecrets != nil {
+				tt.setupSecrets(mockSecrets)
+			}
 
-			handler := NewServerHandler(mockStorage, mockFingerprinter)
+			// после успешного редактирования клиенты сервера удаляются из пула
+			mockClientPool := serviceControlMocks.NewMockClientPool(ctrl)
+			if tt.wantStatus == http.StatusOK {
+				mockClientPool.EXPECT().Invalidate(gomock.Any())
+			}
+
+			handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), mockSecrets, mockClientPool)
 
 			body, _ := json.Marshal(tt.body)
 			r := httptest.NewRequest(http.MethodPut, "/servers/100", bytes.NewBuffer(body))
@@ -738,6 +1252,7 @@ func TestDelServer(t *testing.T) {
 		serverID           int64
 		setupFingerprinter func(m *serviceControlMocks.MockFingerprinter)
 		setupStorage       func(m *storageMocks.MockStorage)
+		expectInvalidate   bool
 		wantStatus         int
 		wantErrorResp      *response.APIError
 	}{
@@ -749,8 +1264,8 @@ func TestDelServer(t *testing.T) {
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
 			setupStorage: func(m *storageMocks.MockStorage) {
 				m.EXPECT().
-					DelServer(gomock.Any(), int64(100), "any-id-user-1").
-					Return(errs.NewErrServerNotFound(100, "any-id-user-1", errors.New("not found")))
+					GetServer(gomock.Any(), int64(100), "any-id-user-1").
+					Return(nil, errs.NewErrServerNotFound(100, "any-id-user-1", errors.New("not found")))
 			},
 			wantStatus: http.StatusNotFound,
 			wantErrorResp: &response.APIError{
@@ -765,6 +1280,9 @@ func TestDelServer(t *testing.T) {
 			serverID:           100,
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
 			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().
+					GetServer(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, Address: "srv01"}, nil)
 				m.EXPECT().
 					DelServer(gomock.Any(), int64(100), "any-id-user-1").
 					Return(errors.New("db connection error"))
@@ -782,11 +1300,15 @@ func TestDelServer(t *testing.T) {
 			serverID:           100,
 			setupFingerprinter: func(m *serviceControlMocks.MockFingerprinter) {},
 			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().
+					GetServer(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{ID: 100, Address: "srv01"}, nil)
 				m.EXPECT().
 					DelServer(gomock.Any(), int64(100), "any-id-user-1").
 					Return(nil)
 			},
-			wantStatus: http.StatusNoContent,
+			expectInvalidate: true,
+			wantStatus:       http.StatusNoContent,
 		},
 	}
 
@@ -798,7 +1320,13 @@ func TestDelServer(t *testing.T) {
 			tt.setupFingerprinter(mockFingerprinter)
 			tt.setupStorage(mockStorage)
 
-			handler := NewServerHandler(mockStorage, mockFingerprinter)
+			// клиенты удаленного сервера сбрасываются в пуле
+			mockClientPool := serviceControlMocks.NewMockClientPool(ctrl)
+			if tt.expectInvalidate {
+				mockClientPool.EXPECT().Invalidate("srv01")
+			}
+
+			handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), secretsMocks.NewMockProvider(ctrl), mockClientPool)
 
 			r := httptest.NewRequest(http.MethodDelete, "/servers/100", nil)
 			ctx := createContextWithCreds(tt.login, tt.userID, tt.serverID)
@@ -884,7 +1412,7 @@ func TestGetServer(t *testing.T) {
 			tt.setupFingerprinter(mockFingerprinter)
 			tt.setupStorage(mockStorage)
 
-			handler := NewServerHandler(mockStorage, mockFingerprinter)
+			handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), secretsMocks.NewMockProvider(ctrl), serviceControlMocks.NewMockClientPool(ctrl))
 
 			r := httptest.NewRequest(http.MethodGet, "/servers/100", nil)
 			ctx := createContextWithCreds(tt.login, tt.userID, tt.serverID)
@@ -984,7 +1512,7 @@ func TestGetServerList(t *testing.T) {
 			tt.setupFingerprinter(mockFingerprinter)
 			tt.setupStorage(mockStorage)
 
-			handler := NewServerHandler(mockStorage, mockFingerprinter)
+			handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), secretsMocks.NewMockProvider(ctrl), serviceControlMocks.NewMockClientPool(ctrl))
 
 			r := httptest.NewRequest(http.MethodGet, "/servers", nil)
 			ctx := context.Background()
@@ -1016,9 +1544,241 @@ func TestNewServerHandler(t *testing.T) {
 	mockStorage := storageMocks.NewMockStorage(ctrl)
 	mockFingerprinter := serviceControlMocks.NewMockFingerprinter(ctrl)
 
-	handler := NewServerHandler(mockStorage, mockFingerprinter)
+	handler := NewServerHandler(mockStorage, mockFingerprinter, serviceControlMocks.NewMockDiagnoser(ctrl), secretsMocks.NewMockProvider(ctrl), serviceControlMocks.NewMockClientPool(ctrl))
 
 	assert.NotNil(t, handler, "handler не должен быть nil")
 	assert.NotNil(t, handler.storage, "storage должен быть инициализирован")
 	assert.NotNil(t, handler.fingerprinter, "fingerprinter должен быть инициализирован")
 }
+
+// TestTestConnection Проверяет пошаговую проверку подключения к серверу перед добавлением.
+func TestTestConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secretPath := "teams/10/servers/web-01"
+	foreignSecretPath := "teams/20/servers/web-01"
+
+	tests := []struct {
+		name          string
+		body          interface{}
+		setupStorage  func(m *storageMocks.MockStorage)
+		setupSecrets  func(m *secretsMocks.MockProvider)
+		setupDiagnose func(m *serviceControlMocks.MockDiagnoser)
+		wantStatus    int
+		wantMessage   string
+		wantSuccess   bool
+	}{
+		{
+			name:          "невалидный JSON",
+			body:          "{invalid}",
+			setupStorage:  func(m *storageMocks.MockStorage) {},
+			setupSecrets:  func(m *secretsMocks.MockProvider) {},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusBadRequest,
+			wantMessage:   "Неверный формат запроса",
+		},
+		{
+			name:          "валидация не пройдена - пустой логин",
+			body:          models.Server{Address: "192.168.1.1", Password: "password"},
+			setupStorage:  func(m *storageMocks.MockStorage) {},
+			setupSecrets:  func(m *secretsMocks.MockProvider) {},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusBadRequest,
+			wantMessage:   "необходимо указать логин",
+		},
+		{
+			name:         "отчет о неудачной проверке",
+			body:         models.Server{Address: "192.168.1.1", Username: "admin", Password: "password"},
+			setupStorage: func(m *storageMocks.MockStorage) {},
+			setupSecrets: func(m *secretsMocks.MockProvider) {},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {
+				m.EXPECT().
+					Diagnose(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{}).
+					Return(&models.ConnectionTestReport{
+						Address: "192.168.1.1",
+						Steps: []models.ConnectionTestStep{
+							{Name: models.ConnectionStepResolve, Status: models.ConnectionStepOK},
+							{Name: models.ConnectionStepTCP, Status: models.ConnectionStepFailed, Hint: "порт 5985 закрыт"},
+						},
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "учетные данные из секрета",
+			body: models.Server{Address: "192.168.1.1", SecretPath: &secretPath},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, server *models.Server) error {
+						server.Username, server.Password = "vault-admin", "vault-password"
+						return nil
+					})
+			},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {
+				m.EXPECT().
+					Diagnose(gomock.Any(), "192.168.1.1", "vault-admin", "vault-password", models.WinRMSettings{}).
+					Return(&models.ConnectionTestReport{Success: true, Address: "192.168.1.1"})
+			},
+			wantStatus:  http.StatusOK,
+			wantSuccess: true,
+		},
+		{
+			name: "секрет не найден",
+			body: models.Server{Address: "192.168.1.1", SecretPath: &secretPath},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			setupSecrets: func(m *secretsMocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).Return(errs.NewErrSecretNotFound(secretPath, nil))
+			},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusBadRequest,
+			wantMessage:   "Секрет не найден или недоступен",
+		},
+		{
+			name: "секрет другой команды",
+			body: models.Server{Address: "192.168.1.1", SecretPath: &foreignSecretPath},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetDefaultTeamID(gomock.Any(), "any-id-user-1").Return(int64(10), nil)
+			},
+			setupSecrets:  func(m *secretsMocks.MockProvider) {},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusBadRequest,
+			wantMessage:   "путь к секрету должен начинаться с teams/10/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockSecrets := secretsMocks.NewMockProvider(ctrl)
+			mockDiagnoser := serviceControlMocks.NewMockDiagnoser(ctrl)
+
+			tt.setupStorage(mockStorage)
+			tt.setupSecrets(mockSecrets)
+			tt.setupDiagnose(mockDiagnoser)
+
+			handler := NewServerHandler(mockStorage, serviceControlMocks.NewMockFingerprinter(ctrl), mockDiagnoser, mockSecrets, serviceControlMocks.NewMockClientPool(ctrl))
+
+			body, _ := json.Marshal(tt.body)
+			r := httptest.NewRequest(http.MethodPost, "/servers/test", bytes.NewBuffer(body))
+			r = r.WithContext(createContextWithCreds("user", "any-id-user-1", 0))
+
+			w := httptest.NewRecorder()
+			handler.TestConnection(w, r)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+
+			if tt.wantMessage != "" {
+				var got response.APIError
+				json.NewDecoder(res.Body).Decode(&got)
+				assert.Equal(t, tt.wantMessage, got.Message)
+				return
+			}
+
+			var got models.ConnectionTestReport
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+			assert.Equal(t, tt.wantSuccess, got.Success)
+			assert.Equal(t, "192.168.1.1", got.Address)
+		})
+	}
+}
+
+// TestTestServerConnection Проверяет пошаговую проверку подключения к добавленному серверу.
+func TestTestServerConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	port := 5986
+
+	tests := []struct {
+		name          string
+		setupStorage  func(m *storageMocks.MockStorage)
+		setupDiagnose func(m *serviceControlMocks.MockDiagnoser)
+		wantStatus    int
+		wantMessage   string
+	}{
+		{
+			name: "сервер не найден",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(nil, errs.NewErrServerNotFound(100, "any-id-user-1", errors.New("not found")))
+			},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusNotFound,
+			wantMessage:   "Сервер не найден",
+		},
+		{
+			name: "хранилище секретов не настроено",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(nil, errs.NewErrSecretProviderDisabled("teams/10/servers/web-01"))
+			},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {},
+			wantStatus:    http.StatusBadRequest,
+			wantMessage:   "Внешнее хранилище секретов не настроено",
+		},
+		{
+			name: "проверка с сохраненными параметрами подключения",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(100), "any-id-user-1").
+					Return(&models.Server{
+						ID:       100,
+						Name:     "TestServer",
+						Address:  "192.168.1.1",
+						Username: "admin",
+						Password: "password",
+						WinRM:    models.WinRMSettings{Port: &port},
+					}, nil)
+			},
+			setupDiagnose: func(m *serviceControlMocks.MockDiagnoser) {
+				m.EXPECT().
+					Diagnose(gomock.Any(), "192.168.1.1", "admin", "password", models.WinRMSettings{Port: &port}).
+					Return(&models.ConnectionTestReport{Success: true, Address: "192.168.1.1", Port: "5986"})
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockDiagnoser := serviceControlMocks.NewMockDiagnoser(ctrl)
+
+			tt.setupStorage(mockStorage)
+			tt.setupDiagnose(mockDiagnoser)
+
+			handler := NewServerHandler(mockStorage, serviceControlMocks.NewMockFingerprinter(ctrl), mockDiagnoser, secretsMocks.NewMockProvider(ctrl), serviceControlMocks.NewMockClientPool(ctrl))
+
+			r := httptest.NewRequest(http.MethodPost, "/servers/100/test", nil)
+			r = r.WithContext(createContextWithCreds("user", "any-id-user-1", 100))
+
+			w := httptest.NewRecorder()
+			handler.TestServerConnection(w, r)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+
+			if tt.wantMessage != "" {
+				var got response.APIError
+				json.NewDecoder(res.Body).Decode(&got)
+				assert.Equal(t, tt.wantMessage, got.Message)
+				return
+			}
+
+			var got models.ConnectionTestReport
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+			assert.True(t, got.Success)
+			assert.Equal(t, "5986", got.Port)
+		})
+	}
+}
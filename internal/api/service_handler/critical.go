@@ -0,0 +1,69 @@
+package service_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// serviceCriticalRequest Тело запроса на изменение признака критичной службы.
+type serviceCriticalRequest struct {
+	Critical *bool `json:"critical"`
+}
+
+// SetServiceCritical Отметка службы как критичной или снятие отметки.
+// Остановка и перезапуск критичной службы выполняются только после подтверждения другим пользователем.
+func (h *ServiceHandler) SetServiceCritical(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var request serviceCriticalRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log.Debug("Неверный формат запроса для признака критичной службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if request.Critical == nil {
+		response.ErrorJSON(w, http.StatusBadRequest, "Необходимо указать признак critical")
+		return
+	}
+
+	err := h.storage.SetServiceCritical(ctx, creds.ServerID, creds.ServiceID, creds.UserID, *request.Critical)
+	if err != nil {
+		var errServiceNotFound *errs.ErrServiceNotFound
+
+		if errors.As(err, &errServiceNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Служба не найдена")
+			return
+		}
+
+		logger.Log.Warn("Ошибка при изменении признака критичной службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при изменении признака критичной службы")
+		return
+	}
+
+	service, err := h.storage.GetService(ctx, creds.ServerID, creds.ServiceID, creds.UserID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении информации о службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении информации о службе")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0,
+		models.ServiceAuditTarget(service.DisplayedName, service.ServiceName)+": critical="+strconv.FormatBool(service.Critical))
+
+	logger.Log.Debug("Признак критичной службы изменен",
+		logger.String("login", creds.Login),
+		logger.Int64("serviceID", creds.ServiceID),
+		logger.String("critical", strconv.FormatBool(service.Critical)))
+
+	response.JSON(w, http.StatusOK, service)
+}
@@ -0,0 +1,161 @@
+package service_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// GetServicePermissions Получение правил доступа к управлению службой.
+func (h *ServiceHandler) GetServicePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	if !h.serviceExists(w, r, creds) {
+		return
+	}
+
+	permissions, err := h.storage.ListServicePermissions(ctx, creds.ServiceID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении правил доступа к службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении правил доступа к службе")
+		return
+	}
+
+	if len(permissions) == 0 {
+		permissions = []*models.ServicePermission{}
+	}
+
+	response.JSON(w, http.StatusOK, permissions)
+}
+
+// SetServicePermission Создание или обновление правила доступа к службе для пользователя или роли.
+// Пока у службы нет ни одного правила, ею может управлять любой оператор команды.
+func (h *ServiceHandler) SetServicePermission(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var permission models.ServicePermission
+
+	if err := json.NewDecoder(r.Body).Decode(&permission); err != nil {
+		logger.Log.Debug("Неверный формат запроса для правила доступа к службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := permission.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	permission.ServiceID = creds.ServiceID
+
+	if !h.serviceExists(w, r, creds) {
+		return
+	}
+
+	// правило можно выдать только участнику команды, которой принадлежит сервер
+	if permission.UserID != "" {
+		_, err := h.storage.GetServerTeamRole(ctx, creds.ServerID, permission.UserID)
+		if err != nil {
+			var errServerNotFound *errs.ErrServerNotFound
+
+			if errors.As(err, &errServerNotFound) {
+				response.ErrorJSON(w, http.StatusBadRequest, "Пользователь не состоит в команде сервера")
+				return
+			}
+
+			logger.Log.Warn("Ошибка при проверке участника команды", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при сохранении правила доступа к службе")
+			return
+		}
+	}
+
+	saved, err := h.storage.SetServicePermission(ctx, permission)
+	if err != nil {
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при сохранении правила доступа к службе")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, permissionAuditTarget(saved))
+
+	logger.Log.Debug("Правило доступа к службе сохранено",
+		logger.String("login", creds.Login),
+		logger.Int64("serviceID", creds.ServiceID),
+		logger.Int64("permissionID", saved.ID))
+
+	response.JSON(w, http.StatusOK, saved)
+}
+
+// DelServicePermission Удаление правила доступа к службе.
+func (h *ServiceHandler) DelServicePermission(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	permissionID, err := strconv.ParseInt(chi.URLParam(r, "permissionID"), 10, 64)
+	if err != nil || permissionID <= 0 {
+		response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id правила доступа")
+		return
+	}
+
+	if !h.serviceExists(w, r, creds) {
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, "permission "+strconv.FormatInt(permissionID, 10))
+
+	err = h.storage.DelServicePermission(ctx, creds.ServiceID, permissionID)
+	if err != nil {
+		var errPermissionNotFound *errs.ErrServicePermissionNotFound
+
+		if errors.As(err, &errPermissionNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Правило доступа не найдено")
+			return
+		}
+
+		logger.Log.Warn("Ошибка при удалении правила доступа к службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при удалении правила доступа к службе")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceExists Проверяет, что служба из URL принадлежит серверу команды пользователя.
+// При ошибке пишет ответ и возвращает false.
+func (h *ServiceHandler) serviceExists(w http.ResponseWriter, r *http.Request, creds *models.ContextCredentials) bool {
+	_, err := h.storage.GetService(r.Context(), creds.ServerID, creds.ServiceID, creds.UserID)
+	if err != nil {
+		var errServiceNotFound *errs.ErrServiceNotFound
+
+		if errors.As(err, &errServiceNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Служба не найдена")
+			return false
+		}
+
+		logger.Log.Warn("Ошибка при получении информации о службе", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении информации о службе")
+		return false
+	}
+
+	return true
+}
+
+// permissionAuditTarget Описание правила доступа для журнала аудита.
+func permissionAuditTarget(p *models.ServicePermission) string {
+	subject := "role " + string(p.Role)
+	if p.UserID != "" {
+		subject = "user " + p.UserID
+	}
+
+	return subject + ": start=" + strconv.FormatBool(p.Start) +
+		" stop=" + strconv.FormatBool(p.Stop) +
+		" restart=" + strconv.FormatBool(p.Restart)
+}
@@ -0,0 +1,229 @@
+package service_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// newPermissionsRequest Создает запрос к правилам доступа службы 10 на сервере 1.
+func newPermissionsRequest(method string, body any, role models.Role, params map[string]string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(method, "/servers/1/services/10/permissions", &buf)
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextkeys.Login, "testuser")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "any-id-user-1")
+	ctx = context.WithValue(ctx, contextkeys.Role, role)
+	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
+	ctx = context.WithValue(ctx, contextkeys.ServiceID, int64(10))
+
+	return r.WithContext(ctx)
+}
+
+// TestGetServiceCapabilities Проверяет заполнение доступных действий в ответе GetService.
+func TestGetServiceCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		role         models.Role
+		permissions  []*models.ServicePermission
+		expectedCaps models.ServiceCapabilities
+	}{
+		{"нет правил: оператор", models.RoleOperator, nil, models.ServiceCapabilities{Start: true, Stop: true, Restart: true}},
+		{"нет правил: наблюдатель", models.RoleViewer, nil, models.ServiceCapabilities{}},
+		{
+			name: "правило для роли admin",
+			role: models.RoleOperator,
+			permissions: []*models.ServicePermission{
+				{ServiceID: 10, Role: models.RoleAdmin, Start: true, Stop: true, Restart: true},
+			},
+			expectedCaps: models.ServiceCapabilities{},
+		},
+		{
+			name: "правило для пользователя и роли",
+			role: models.RoleOperator,
+			permissions: []*models.ServicePermission{
+				{ServiceID: 10, Role: models.RoleOperator, Restart: true},
+				{ServiceID: 10, UserID: "any-id-user-1", Start: true},
+			},
+			expectedCaps: models.ServiceCapabilities{Start: true, Restart: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").
+				Return(&models.Service{ID: 10, ServiceName: "MSSQLSERVER", DisplayedName: "SQL Server"}, nil)
+			mockStorage.EXPECT().ListServicePermissions(gomock.Any(), int64(10)).Return(tt.permissions, nil)
+
+			handler := NewServiceHandler(mockStorage, nil, nil, nil, nil, &config.WinRMConfig{Port: "5985"})
+
+			w := httptest.NewRecorder()
+			handler.GetService(w, newPermissionsRequest(http.MethodGet, nil, tt.role, nil))
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var got models.Service
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			require.NotNil(t, got.Capabilities)
+			assert.Equal(t, tt.expectedCaps, *got.Capabilities)
+		})
+	}
+}
+
+// TestSetServicePermission Проверяет сохранение правила доступа к службе.
+func TestSetServicePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "правило для роли",
+			body: models.ServicePermission{Role: models.RoleAdmin, Start: true, Stop: true, Restart: true},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").Return(&models.Service{ID: 10}, nil)
+				m.EXPECT().SetServicePermission(gomock.Any(), models.ServicePermission{
+					ServiceID: 10, Role: models.RoleAdmin, Start: true, Stop: true, Restart: true,
+				}).Return(&models.ServicePermission{ID: 1, ServiceID: 10, Role: models.RoleAdmin, Start: true, Stop: true, Restart: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "правило для участника команды",
+			body: models.ServicePermission{UserID: "any-id-user-2", Restart: true},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").Return(&models.Service{ID: 10}, nil)
+				m.EXPECT().GetServerTeamRole(gomock.Any(), int64(1), "any-id-user-2").Return(models.RoleOperator, nil)
+				m.EXPECT().SetServicePermission(gomock.Any(), gomock.Any()).
+					Return(&models.ServicePermission{ID: 2, ServiceID: 10, UserID: "any-id-user-2", Restart: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "пользователь не состоит в команде",
+			body: models.ServicePermission{UserID: "stranger", Restart: true},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").Return(&models.Service{ID: 10}, nil)
+				m.EXPECT().GetServerTeamRole(gomock.Any(), int64(1), "stranger").
+					Return(models.Role(""), errs.NewErrServerNotFound(1, "stranger", errors.New("no rows")))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "указаны и пользователь, и роль",
+			body:           models.ServicePermission{UserID: "any-id-user-2", Role: models.RoleOperator},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "служба не найдена",
+			body: models.ServicePermission{Role: models.RoleAdmin, Stop: true},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").
+					Return(nil, errs.NewErrServiceNotFound("any-id-user-1", 1, 10, nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			handler := NewServiceHandler(mockStorage, nil, nil, nil, nil, &config.WinRMConfig{Port: "5985"})
+
+			w := httptest.NewRecorder()
+			handler.SetServicePermission(w, newPermissionsRequest(http.MethodPut, tt.body, models.RoleAdmin, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestDelServicePermission Проверяет удаление правила доступа к службе.
+func TestDelServicePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		permissionID   string
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name:         "успешное удаление",
+			permissionID: "3",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").Return(&models.Service{ID: 10}, nil)
+				m.EXPECT().DelServicePermission(gomock.Any(), int64(10), int64(3)).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:         "правило не найдено",
+			permissionID: "3",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetService(gomock.Any(), int64(1), int64(10), "any-id-user-1").Return(&models.Service{ID: 10}, nil)
+				m.EXPECT().DelServicePermission(gomock.Any(), int64(10), int64(3)).
+					Return(errs.NewErrServicePermissionNotFound(10, 3, nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "некорректный id",
+			permissionID:   "-1",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			handler := NewServiceHandler(mockStorage, nil, nil, nil, nil, &config.WinRMConfig{Port: "5985"})
+
+			w := httptest.NewRecorder()
+			r := newPermissionsRequest(http.MethodDelete, nil, models.RoleAdmin, map[string]string{"permissionID": tt.permissionID})
+			handler.DelServicePermission(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
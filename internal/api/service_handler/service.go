@@ -10,12 +10,12 @@ import (
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
-	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/worker"
 )
@@ -24,25 +24,28 @@ import (
 type ServiceHandler struct {
 	storage                storage.Storage
 	clientFactory          service_control.ClientFactory
+	manager                service_control.ServiceManager
 	checker                netutils.Checker
 	serviceStatusesChecker worker.StatusesChecker
-	winRMPort              string
+	winRMConfig            *config.WinRMConfig
 }
 
 // NewServiceHandler Конструктор ServiceHandler.
 func NewServiceHandler(
 	storage storage.Storage,
 	clientFactory service_control.ClientFactory,
+	manager service_control.ServiceManager,
 	checker netutils.Checker,
 	serviceStatusesChecker worker.StatusesChecker,
-	winRMPort string,
+	winRMConfig *config.WinRMConfig,
 ) *ServiceHandler {
 	return &ServiceHandler{
 		storage:                storage,
 		clientFactory:          clientFactory,
+		manager:                manager,
 		checker:                checker,
 		serviceStatusesChecker: serviceStatusesChecker,
-		winRMPort:              winRMPort,
+		winRMConfig:            winRMConfig,
 	}
 }
 
@@ -74,14 +77,14 @@ func (h *ServiceHandler) ListOfServices(w http.ResponseWriter, r *http.Request)
 	}
 
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно запустить службу", server.Address, server.ID))
 		response.ErrorJSON(w, http.StatusBadGateway, fmt.Sprintf("Сервер недоступен"))
 		return
 	}
 
 	// создаём WinRM клиент
-	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -89,10 +92,7 @@ func (h *ServiceHandler) ListOfServices(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	listOfServicesCmd := `powershell -Command "` +
-		`Get-Service | ` +
-		`ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName } } | ` +
-		`ConvertTo-Json -Compress"`
+	listOfServicesCmd := service_control.ListServicesCommand()
 
 	// контекст для получения списка служб удаленного сервера
 	listOfServicesCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -149,6 +149,14 @@ func (h *ServiceHandler) AddService(w http.ResponseWriter, r *http.Request) {
 	rawServiceName := service.ServiceName
 	service.ServiceName = strings.ToLower(strings.TrimSpace(strings.Trim(service.ServiceName, "\"'`«»“”‘’")))
 
+	// имя службы передается в команды на удаленном сервере, поэтому допускаются только безопасные символы
+	if err := service_control.ValidateServiceName(service.ServiceName); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.ServiceAuditTarget(service.DisplayedName, service.ServiceName))
+
 	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
 
 	var ErrServerNotFound *errs.ErrServerNotFound
@@ -171,7 +179,7 @@ func (h *ServiceHandler) AddService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно добавить службу", server.Address, server.ID))
 
 		w.Header().Set("Content-Type", "application/json")
@@ -180,7 +188,7 @@ func (h *ServiceHandler) AddService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// создаём WinRM клиент
-	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -188,32 +196,33 @@ func (h *ServiceHandler) AddService(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	statusCmd := fmt.Sprintf("sc query \"%s\"", service.ServiceName)
-
 	// контекст для получения статуса
 	statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := client.RunCommand(statusCtx, statusCmd)
-	if err != nil {
-		logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s` на сервере `%s`, id=%d",
-			service.DisplayedName, server.Name, creds.ServerID), logger.String("err", err.Error()))
+	state, err := h.manager.Query(statusCtx, client, service.ServiceName)
 
-		response.ErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("Не удалось получить статус службы `%s`", service.DisplayedName))
-		return
-	}
+	var serviceErr *errs.ServiceError
 
-	// проверяем, существует ли вообще такая служба на сервере
-	if !isServiceExists(result) {
-		logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s` на сервере `%s`, address=%s, id=%d",
-			service.DisplayedName, server.Name, server.Address, creds.ServerID))
+	if err != nil {
+		switch {
+		// проверяем, существует ли вообще такая служба на сервере
+		case errors.As(err, &serviceErr) && serviceErr.Code == errs.CodeServiceDoesNotExist:
+			logger.Log.Warn(fmt.Sprintf("Служба `%s` не найдена на сервере `%s`, address=%s, id=%d",
+				service.DisplayedName, server.Name, server.Address, creds.ServerID))
 
-		response.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("Служба `%s` не найдена на сервере", rawServiceName))
-		return
+			response.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("Служба `%s` не найдена на сервере", rawServiceName))
+			return
+		default:
+			logger.Log.Warn(fmt.Sprintf("Не удалось получить статус службы `%s` на сервере `%s`, id=%d",
+				service.DisplayedName, server.Name, creds.ServerID), logger.String("err", err.Error()))
+
+			response.ErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("Не удалось получить статус службы `%s`", service.DisplayedName))
+			return
+		}
 	}
 
-	statusFromServer := utils.GetStatusByINT(utils.GetStatus(result))
-	service.Status = statusFromServer
+	service.Status = state.Status
 	service.UpdatedAt = time.Now()
 
 	createdService, err := h.storage.AddService(ctx, creds.ServerID, creds.UserID, service)
@@ -239,6 +248,8 @@ func (h *ServiceHandler) AddService(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	models.SetAuditTarget(ctx, 0, createdService.ID, "")
+
 	logger.Log.Debug("Служба успешно добавлена на сервер", logger.String("serviceName", service.ServiceName), logger.Int64("serverID", creds.ServerID))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -306,6 +317,14 @@ func (h *ServiceHandler) GetService(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// действия управления службой, доступные текущему пользователю
+	permissions, err := h.storage.ListServicePermissions(ctx, service.ID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении правил доступа к службе", logger.String("err", err.Error()))
+	} else {
+		setCapabilities(creds, []*models.Service{service}, permissions)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	// не выставляем w.WriteHeader(http.StatusOK), т.к. NewEncoder(w).Encode() сам вернет http.StatusOK
 	if err = json.NewEncoder(w).Encode(service); err != nil {
@@ -315,6 +334,102 @@ func (h *ServiceHandler) GetService(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetServiceConfig Получение конфигурации службы (тип запуска, учетная запись, путь к исполняемому файлу,
+// зависимости) с удаленного сервера.
+func (h *ServiceHandler) GetServiceConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	// получаем сервер с паролем
+	server, err := h.storage.GetServerWithPassword(ctx, creds.ServerID, creds.UserID)
+
+	var ErrServerNotFound *errs.ErrServerNotFound
+
+	if err != nil {
+		switch {
+		case errors.As(err, &ErrServerNotFound):
+			logger.Log.Warn("Сервер не найден",
+				logger.String("login", creds.Login),
+				logger.String("userID", ErrServerNotFound.UserID),
+				logger.Int64("serverID", ErrServerNotFound.ServerID),
+				logger.String("err", ErrServerNotFound.Err.Error()))
+			response.ErrorJSON(w, http.StatusNotFound, "Сервер не найден")
+			return
+		default:
+			logger.Log.Warn("Ошибка при получении информации о сервере", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении информации о сервере")
+			return
+		}
+	}
+
+	// получаем службу
+	service, err := h.storage.GetService(ctx, creds.ServerID, creds.ServiceID, creds.UserID)
+
+	var ErrServiceNotFound *errs.ErrServiceNotFound
+
+	if err != nil {
+		switch {
+		case errors.As(err, &ErrServiceNotFound):
+			logger.Log.Warn("Служба не найдена", logger.String("err", ErrServiceNotFound.Err.Error()))
+			response.ErrorJSON(w, http.StatusNotFound, "Служба не найдена")
+			return
+		default:
+			logger.Log.Error("Ошибка при получении информации о службе", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении информации о службе")
+			return
+		}
+	}
+
+	// команды на сервере выполняются только для допустимого имени службы
+	if err = service_control.ValidateServiceName(service.ServiceName); err != nil {
+		logger.Log.Warn("Недопустимое имя службы", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Недопустимое имя службы")
+		return
+	}
+
+	// проверяем доступность сервера, если недоступен - возвращаем ошибку
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
+		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно получить конфигурацию службы", server.Address, server.ID))
+		response.ErrorJSON(w, http.StatusBadGateway, "Сервер недоступен")
+		return
+	}
+
+	// создаём WinRM клиент
+	client, err := h.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
+
+	if err != nil {
+		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка подключения к серверу")
+		return
+	}
+
+	// контекст для получения конфигурации
+	configCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	serviceConfig, err := h.manager.Config(configCtx, client, service.ServiceName)
+
+	var serviceErr *errs.ServiceError
+
+	if err != nil {
+		switch {
+		case errors.As(err, &serviceErr) && serviceErr.Code == errs.CodeServiceDoesNotExist:
+			logger.Log.Warn(fmt.Sprintf("Служба `%s` не найдена на сервере `%s`, id=%d",
+				service.DisplayedName, server.Name, creds.ServerID))
+			response.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("Служба `%s` не найдена на сервере", service.DisplayedName))
+			return
+		default:
+			logger.Log.Warn(fmt.Sprintf("Не удалось получить конфигурацию службы `%s`, id=%d на сервере `%s`, id=%d",
+				service.DisplayedName, creds.ServiceID, server.Name, creds.ServerID), logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError,
+				fmt.Sprintf("Не удалось получить конфигурацию службы `%s`", service.DisplayedName))
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, serviceConfig)
+}
+
 // GetServicesList Получение списка служб сервера, принадлежащего пользователю.
 func (h *ServiceHandler) GetServicesList(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -343,6 +458,14 @@ func (h *ServiceHandler) GetServicesList(w http.ResponseWriter, r *http.Request)
 
 	if len(services) == 0 {
 		services = []*models.Service{}
+	} else {
+		// действия управления службами, доступные текущему пользователю
+		permissions, err := h.storage.ListServerServicePermissions(ctx, creds.ServerID)
+		if err != nil {
+			logger.Log.Warn("Ошибка при получении правил доступа к службам сервера", logger.String("err", err.Error()))
+		} else {
+			setCapabilities(creds, services, permissions)
+		}
 	}
 
 	// если запрос пришел без параметра ?actual=true - просто временем каждую службу в списке
@@ -384,7 +507,7 @@ func (h *ServiceHandler) GetServicesList(w http.ResponseWriter, r *http.Request)
 	}
 
 	// проверяем доступность сервера, если недоступен - возвращаем службы и заголовок "X-Is-Updated" = false
-	if !h.checker.CheckWinRM(ctx, server.Address, h.winRMPort, 0) {
+	if !service_control.IsWinRMAvailable(ctx, h.checker, h.winRMConfig, server.Address, server.WinRM) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s, id=%d недоступен. Невозможно обновить статус служб с сервера", server.Address, server.ID))
 
 		w.Header().Set("Content-Type", "application/json")
@@ -437,16 +560,15 @@ func (h *ServiceHandler) GetServicesList(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// Вспомогательная функция проверки результата запроса на получение статуса службы
-func isServiceExists(result string) bool {
-	// если явно присутствует код 1060 - служба отсутствует
-	if strings.Contains(result, "1060") {
-		return false
-		// искомые маркеры наличия службы
-	} else if strings.Contains(result, "STATE") || strings.Contains(result, "SERVICE_NAME:") {
-		return true
+// setCapabilities Заполняет у служб действия управления, доступные текущему пользователю, по правилам доступа.
+func setCapabilities(creds *models.ContextCredentials, services []*models.Service, permissions []*models.ServicePermission) {
+	byService := make(map[int64][]*models.ServicePermission)
+	for _, p := range permissions {
+		byService[p.ServiceID] = append(byService[p.ServiceID], p)
 	}
 
-	// если ничего не понятно - считаем что такой службы нет на сервере
-	return false
+	for _, service := range services {
+		caps := models.ResolveServiceCapabilities(creds.UserID, creds.Role, byService[service.ID])
+		service.Capabilities = &caps
+	}
 }
@@ -13,12 +13,15 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	netutilsMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
 	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 	workerMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/worker/mocks"
 )
@@ -27,18 +30,27 @@ func init() {
 	logger.InitLogger("error", "stdout")
 }
 
+// newMockStorage Создаёт мок хранилища, в котором у служб нет правил доступа.
+func newMockStorage(ctrl *gomock.Controller) *storageMocks.MockStorage {
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().ListServicePermissions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockStorage.EXPECT().ListServerServicePermissions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return mockStorage
+}
+
 // TestNewServiceHandler Проверяет создание ServiceHandler.
 func TestNewServiceHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	// проверяем что handler создан
 	assert.NotNil(t, handler)
@@ -52,14 +64,15 @@ func TestListOfServicesSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	r := httptest.NewRequest(http.MethodGet, "/services/available", nil)
 	w := httptest.NewRecorder()
@@ -82,11 +95,11 @@ func TestListOfServicesSuccess(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// Сервер возвращает список служб в новом JSON формате
@@ -95,7 +108,7 @@ func TestListOfServicesSuccess(t *testing.T) {
 						{"name":"wuauserv","display_name":"Windows Update"}]`
 
 	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `powershell -Command "Get-Service | ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName } } | ConvertTo-Json -Compress"`).
+		RunCommand(gomock.Any(), service_control.ListServicesCommand()).
 		Return(servicesOutput, nil)
 
 	handler.ListOfServices(w, r)
@@ -135,13 +148,14 @@ func TestListOfServicesServerNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetServerWithPassword(gomock.Any(), int64(1), "any-id-user-1").
@@ -171,13 +185,14 @@ func TestListOfServicesDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetServerWithPassword(gomock.Any(), int64(1), "any-id-user-1").
@@ -203,8 +218,9 @@ func TestListOfServicesServerUnreachable(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -217,7 +233,7 @@ func TestListOfServicesServerUnreachable(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -232,7 +248,7 @@ func TestListOfServicesServerUnreachable(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(false)
 
 	handler.ListOfServices(w, r)
@@ -247,8 +263,9 @@ func TestListOfServicesClientFactoryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -261,7 +278,7 @@ func TestListOfServicesClientFactoryError(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -276,12 +293,12 @@ func TestListOfServicesClientFactoryError(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// ошибка создания клиента
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("connection error"))
 
 	handler.ListOfServices(w, r)
@@ -296,8 +313,9 @@ func TestListOfServicesRunCommandError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -311,7 +329,7 @@ func TestListOfServicesRunCommandError(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -326,16 +344,16 @@ func TestListOfServicesRunCommandError(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// ошибка выполнения команды
 	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `powershell -Command "Get-Service | ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName } } | ConvertTo-Json -Compress"`).
+		RunCommand(gomock.Any(), service_control.ListServicesCommand()).
 		Return("", errors.New("command failed"))
 
 	handler.ListOfServices(w, r)
@@ -350,8 +368,9 @@ func TestListOfServicesEmptyList(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -365,7 +384,7 @@ func TestListOfServicesEmptyList(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -380,16 +399,16 @@ func TestListOfServicesEmptyList(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// сервер возвращает пустой результат
 	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `powershell -Command "Get-Service | ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName } } | ConvertTo-Json -Compress"`).
+		RunCommand(gomock.Any(), service_control.ListServicesCommand()).
 		Return("", nil)
 
 	handler.ListOfServices(w, r)
@@ -412,13 +431,14 @@ func TestAddServiceInvalidJSON(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	// невалидный JSON
 	body := []byte(`{invalid json}`)
@@ -442,13 +462,14 @@ func TestAddServiceInvalidServiceData(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	tests := []struct {
 		name    string
@@ -468,6 +489,27 @@ func TestAddServiceInvalidServiceData(t *testing.T) {
 				DisplayedName: "",
 			},
 		},
+		{
+			name: "имя службы с разделителем команд",
+			service: models.Service{
+				ServiceName:   `spooler" & calc & "`,
+				DisplayedName: "Test",
+			},
+		},
+		{
+			name: "имя службы с подвыражением PowerShell",
+			service: models.Service{
+				ServiceName:   "$(Remove-Item C:\\ -Recurse)",
+				DisplayedName: "Test",
+			},
+		},
+		{
+			name: "имя службы с кавычкой внутри",
+			service: models.Service{
+				ServiceName:   "spooler'; calc; '",
+				DisplayedName: "Test",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -494,13 +536,14 @@ func TestAddServiceServerNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetServerWithPassword(gomock.Any(), int64(1), "any-id-user-1").
@@ -536,13 +579,14 @@ func TestAddServiceDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetServerWithPassword(gomock.Any(), int64(1), "any-id-user-1").
@@ -574,8 +618,9 @@ func TestAddServiceServerUnreachable(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -594,7 +639,7 @@ func TestAddServiceServerUnreachable(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -609,7 +654,7 @@ func TestAddServiceServerUnreachable(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(false)
 
 	handler.AddService(w, r)
@@ -625,8 +670,9 @@ func TestAddServiceClientFactoryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -645,7 +691,7 @@ func TestAddServiceClientFactoryError(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -660,12 +706,12 @@ func TestAddServiceClientFactoryError(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	// ошибка создания клиента
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("connection error"))
 
 	handler.AddService(w, r)
@@ -680,8 +726,9 @@ func TestAddServiceRunCommandError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -701,7 +748,7 @@ func TestAddServiceRunCommandError(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -716,17 +763,17 @@ func TestAddServiceRunCommandError(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// ошибка выполнения команды
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("", errors.New("command failed"))
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(nil, errors.New("command failed"))
 
 	handler.AddService(w, r)
 
@@ -740,8 +787,9 @@ func TestAddServiceNotExistsOnServer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -761,7 +809,7 @@ func TestAddServiceNotExistsOnServer(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -776,17 +824,17 @@ func TestAddServiceNotExistsOnServer(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	// команда возвращает код 1060 (служба не найдена)
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("QueryServiceConfig FAILED 1060", nil)
+	// служба не установлена на сервере (код 1060)
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(nil, errs.NewServiceError(errs.ParseErrorCode(errs.CodeServiceDoesNotExist), errs.CodeServiceDoesNotExist))
 
 	handler.AddService(w, r)
 
@@ -800,8 +848,9 @@ func TestAddServiceDuplicateService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -821,7 +870,7 @@ func TestAddServiceDuplicateService(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -836,17 +885,17 @@ func TestAddServiceDuplicateService(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	// команда возвращает успешный результат
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("SERVICE_NAME: testservice\nSTATE: 4 RUNNING", nil)
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(&models.WindowsServiceState{Name: "testservice", State: utils.ServiceRunning, Status: "Работает"}, nil)
 
 	// ошибка дублирования в БД
 	mockStorage.EXPECT().
@@ -868,8 +917,9 @@ func TestAddServiceServerNotFoundInAddService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -889,7 +939,7 @@ func TestAddServiceServerNotFoundInAddService(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -904,16 +954,16 @@ func TestAddServiceServerNotFoundInAddService(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("SERVICE_NAME: testservice\nSTATE: 4 RUNNING", nil)
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(&models.WindowsServiceState{Name: "testservice", State: utils.ServiceRunning, Status: "Работает"}, nil)
 
 	// сервер не найден при добавлении службы
 	mockStorage.EXPECT().
@@ -936,8 +986,9 @@ func TestAddServiceDatabaseErrorInAddService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -957,7 +1008,7 @@ func TestAddServiceDatabaseErrorInAddService(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -972,16 +1023,16 @@ func TestAddServiceDatabaseErrorInAddService(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("SERVICE_NAME: testservice\nSTATE: 4 RUNNING", nil)
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(&models.WindowsServiceState{Name: "testservice", State: utils.ServiceRunning, Status: "Работает"}, nil)
 
 	// обычная ошибка БД
 	mockStorage.EXPECT().
@@ -1000,8 +1051,9 @@ func TestAddServiceSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
@@ -1021,7 +1073,7 @@ func TestAddServiceSuccess(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	server := &models.Server{
 		ID:       1,
@@ -1044,16 +1096,16 @@ func TestAddServiceSuccess(t *testing.T) {
 		Return(server, nil)
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockClientFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	mockClient.EXPECT().
-		RunCommand(gomock.Any(), `sc query "testservice"`).
-		Return("SERVICE_NAME: testservice\nSTATE: 4 RUNNING", nil)
+	mockManager.EXPECT().
+		Query(gomock.Any(), mockClient, "testservice").
+		Return(&models.WindowsServiceState{Name: "testservice", State: utils.ServiceRunning, Status: "Работает"}, nil)
 
 	mockStorage.EXPECT().
 		AddService(gomock.Any(), int64(1), "any-id-user-1", gomock.Any()).
@@ -1093,13 +1145,14 @@ func TestAddServiceNameNormalization(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage := newMockStorage(ctrl)
 			mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+			mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 			mockChecker := netutilsMocks.NewMockChecker(ctrl)
 			mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 			mockWinRMPort := "5985"
 
-			handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+			handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 			mockStorage.EXPECT().
 				GetServerWithPassword(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1136,13 +1189,14 @@ func TestDelServiceSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		DelService(gomock.Any(), int64(1), int64(1), "any-id-user-1").
@@ -1169,13 +1223,14 @@ func TestDelServiceNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		DelService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1207,13 +1262,14 @@ func TestDelServiceDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		DelService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1239,13 +1295,14 @@ func TestGetServiceSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	service := &models.Service{
 		ID:            1,
@@ -1285,13 +1342,14 @@ func TestGetServiceNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1323,13 +1381,14 @@ func TestGetServiceDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		GetService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1350,18 +1409,116 @@ func TestGetServiceDatabaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+// TestGetServiceConfig Проверяет получение конфигурации службы с удаленного сервера.
+func TestGetServiceConfig(t *testing.T) {
+	server := &models.Server{ID: 1, Name: "srv", Address: "192.168.1.1", Username: "admin", Password: "password"}
+
+	serviceConfig := &models.WindowsServiceConfig{
+		Name:        "Spooler",
+		DisplayName: "Print Spooler",
+		StartType:   models.ServiceStartAuto,
+		Account:     "LocalSystem",
+		BinaryPath:  `C:\Windows\System32\spoolsv.exe`,
+		DependsOn:   []string{"RPCSS", "http"},
+	}
+
+	tests := []struct {
+		name           string
+		serviceName    string
+		setupManager   func(m *serviceControlMocks.MockServiceManager, client *serviceControlMocks.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "конфигурация получена",
+			serviceName: "spooler",
+			setupManager: func(m *serviceControlMocks.MockServiceManager, client *serviceControlMocks.MockClient) {
+				m.EXPECT().Config(gomock.Any(), client, "spooler").Return(serviceConfig, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"start_type":"auto"`,
+		},
+		{
+			name:        "служба не установлена на сервере",
+			serviceName: "spooler",
+			setupManager: func(m *serviceControlMocks.MockServiceManager, client *serviceControlMocks.MockClient) {
+				m.EXPECT().Config(gomock.Any(), client, "spooler").
+					Return(nil, errs.NewServiceError(errs.ParseErrorCode(errs.CodeServiceDoesNotExist), errs.CodeServiceDoesNotExist))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "не найдена на сервере",
+		},
+		{
+			name:        "ошибка выполнения команды",
+			serviceName: "spooler",
+			setupManager: func(m *serviceControlMocks.MockServiceManager, client *serviceControlMocks.MockClient) {
+				m.EXPECT().Config(gomock.Any(), client, "spooler").Return(nil, errors.New("WinRM connection lost"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Не удалось получить конфигурацию службы",
+		},
+		{
+			name:           "недопустимое имя службы",
+			serviceName:    `spooler" & calc & "`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Недопустимое имя службы",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := newMockStorage(ctrl)
+			mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+			mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
+			mockChecker := netutilsMocks.NewMockChecker(ctrl)
+			mockClient := serviceControlMocks.NewMockClient(ctrl)
+
+			mockStorage.EXPECT().GetServerWithPassword(gomock.Any(), int64(1), "any-id-user-1").Return(server, nil)
+			mockStorage.EXPECT().GetService(gomock.Any(), int64(1), int64(1), "any-id-user-1").
+				Return(&models.Service{ID: 1, ServiceName: tt.serviceName, DisplayedName: "Print Spooler"}, nil)
+
+			if tt.setupManager != nil {
+				mockChecker.EXPECT().CheckWinRM(gomock.Any(), "192.168.1.1", "5985", false, time.Duration(0)).Return(true)
+				mockClientFactory.EXPECT().CreateClient("192.168.1.1", "admin", "password", models.WinRMSettings{}).Return(mockClient, nil)
+				tt.setupManager(mockManager, mockClient)
+			}
+
+			handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker,
+				workerMocks.NewMockStatusesChecker(ctrl), &config.WinRMConfig{Port: "5985"})
+
+			r := httptest.NewRequest(http.MethodGet, "/services/1/config", nil)
+			w := httptest.NewRecorder()
+
+			ctx := context.WithValue(r.Context(), contextkeys.Login, "testuser")
+			ctx = context.WithValue(ctx, contextkeys.UserID, "any-id-user-1")
+			ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
+			ctx = context.WithValue(ctx, contextkeys.ServiceID, int64(1))
+			r = r.WithContext(ctx)
+
+			handler.GetServiceConfig(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
 // TestGetServicesListSuccess Проверяет успешное получение списка.
 func TestGetServicesListSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1398,13 +1555,14 @@ func TestGetServicesListEmpty(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		ListServices(gomock.Any(), int64(1), "any-id-user-1").
@@ -1435,13 +1593,14 @@ func TestGetServicesListServerNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		ListServices(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1469,13 +1628,14 @@ func TestGetServicesListDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	mockStorage.EXPECT().
 		ListServices(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -1500,13 +1660,14 @@ func TestGetServicesListWithActualTrueServerNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1546,13 +1707,14 @@ func TestGetServicesListWithActualTrueDatabaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1588,8 +1750,9 @@ func TestGetServicesListWithActualTrueServerUnreachable(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -1602,7 +1765,7 @@ func TestGetServicesListWithActualTrueServerUnreachable(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1628,7 +1791,7 @@ func TestGetServicesListWithActualTrueServerUnreachable(t *testing.T) {
 
 	// сервер недоступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, gomock.Any()).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, gomock.Any()).
 		Return(false)
 
 	handler.GetServicesList(w, r)
@@ -1650,8 +1813,9 @@ func TestGetServicesListWithActualTrueWorkerFailed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -1664,7 +1828,7 @@ func TestGetServicesListWithActualTrueWorkerFailed(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1690,7 +1854,7 @@ func TestGetServicesListWithActualTrueWorkerFailed(t *testing.T) {
 
 	// сервер доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, gomock.Any()).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, gomock.Any()).
 		Return(true)
 
 	// worker не смог обновить статусы (возвращает false)
@@ -1717,8 +1881,9 @@ func TestGetServicesListWithActualTrueBatchUpdateFailed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -1731,7 +1896,7 @@ func TestGetServicesListWithActualTrueBatchUpdateFailed(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1762,7 +1927,7 @@ func TestGetServicesListWithActualTrueBatchUpdateFailed(t *testing.T) {
 
 	// сервер доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, gomock.Any()).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, gomock.Any()).
 		Return(true)
 
 	// worker успешно вернул обновлённые статусы
@@ -1793,8 +1958,9 @@ func TestGetServicesListWithActualTrueSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
@@ -1807,7 +1973,7 @@ func TestGetServicesListWithActualTrueSuccess(t *testing.T) {
 	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
 	r = r.WithContext(ctx)
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1838,7 +2004,7 @@ func TestGetServicesListWithActualTrueSuccess(t *testing.T) {
 
 	// сервер доступен
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, gomock.Any()).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, gomock.Any()).
 		Return(true)
 
 	// worker успешно вернул обновлённые статусы
@@ -1870,13 +2036,14 @@ func TestGetServicesListWithActualFalse(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service1", DisplayedName: "Service 1", Status: "running"},
@@ -1922,13 +2089,14 @@ func TestGetServicesListWithActualTrueEmptyServices(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage := newMockStorage(ctrl)
 	mockClientFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockManager := serviceControlMocks.NewMockServiceManager(ctrl)
 	mockChecker := netutilsMocks.NewMockChecker(ctrl)
 	mockStatusesWorker := workerMocks.NewMockStatusesChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	handler := NewServiceHandler(mockStorage, mockClientFactory, mockChecker, mockStatusesWorker, mockWinRMPort)
+	handler := NewServiceHandler(mockStorage, mockClientFactory, mockManager, mockChecker, mockStatusesWorker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	// пустой список служб
 	services := []*models.Service{}
@@ -1963,45 +2131,3 @@ func TestGetServicesListWithActualTrueEmptyServices(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(responseServices))
 }
-
-// TestIsServiceExistsVariants Проверяет все варианты isServiceExists.
-func TestIsServiceExistsVariants(t *testing.T) {
-	tests := []struct {
-		name     string
-		result   string
-		expected bool
-	}{
-		{
-			name:     "с маркером STATE",
-			result:   "SERVICE_NAME: test\nSTATE: 4 RUNNING",
-			expected: true,
-		},
-		{
-			name:     "с маркером SERVICE_NAME",
-			result:   "SERVICE_NAME: someservice",
-			expected: true,
-		},
-		{
-			name:     "код ошибки 1060",
-			result:   "QueryServiceConfig FAILED 1060",
-			expected: false,
-		},
-		{
-			name:     "пустой результат",
-			result:   "",
-			expected: false,
-		},
-		{
-			name:     "неизвестный результат",
-			result:   "Some random text",
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isServiceExists(tt.result)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
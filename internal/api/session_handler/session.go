@@ -1,22 +1,47 @@
 package session_handler
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
 
+// TokenExp Срок жизни куки, если провайдер не сообщил время окончания действия токена.
 const TokenExp = time.Hour * 24
 
+// csrfTokenLen Длина CSRF-токена в байтах (до кодирования в base64).
+const csrfTokenLen = 32
+
 type SessionHandler struct {
 	AuthProvider auth.AuthProvider
+	storage      storage.Storage
+	ticketTTL    time.Duration
+}
+
+func NewSessionHandler(AuthProvider auth.AuthProvider, storage storage.Storage, ticketTTL time.Duration) *SessionHandler {
+	return &SessionHandler{AuthProvider: AuthProvider, storage: storage, ticketTTL: ticketTTL}
 }
 
-func NewSessionHandler(AuthProvider auth.AuthProvider) *SessionHandler {
-	return &SessionHandler{AuthProvider: AuthProvider}
+// sseTicketRequest Тело запроса на выдачу билета SSE.
+type sseTicketRequest struct {
+	Stream string `json:"stream"`
+}
+
+// sseTicketResponse Выданный билет SSE.
+type sseTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // SetSessionCookie устанавливает httpOnly куку с JWT токеном.
@@ -42,9 +67,15 @@ func NewSessionHandler(AuthProvider auth.AuthProvider) *SessionHandler {
 //     - HttpOnly: защищает от кражи через XSS (JavaScript не может прочитать куку).
 //     - Secure: кука передаётся только по HTTPS (в продакшене обязательно).
 //     - SameSite=Lax: защита от CSRF, но не блокирует переходы с внешних сайтов.
-//     - Expires: 24 часа (можно синхронизировать с временем жизни токена).
+//     - Expires: время окончания действия токена (exp), кука не переживает токен.
 //  5. После этого все SSE-соединения (например, /user/broadcasting?stream=servers)
 //     автоматически отправляют эту куку, и сервер может аутентифицировать пользователя.
+//  6. Вместе с JWT выставляется кука с CSRF-токеном (не HttpOnly), он же возвращается в ответе.
+//     Изменяющие запросы, аутентифицированные только кукой, должны повторять его
+//     в заголовке X-CSRF-Token (см. middleware.CSRFMiddleware).
+//
+// Клиентам, которым не нужна кука, стоит подключаться к SSE по одноразовому билету (см. IssueSSETicket),
+// подключение по куке можно отключить параметром SSE_COOKIE_AUTH.
 func (h *SessionHandler) SetSessionCookie(w http.ResponseWriter, r *http.Request) {
 	// извлекаем токен из заголовка Authorization: Bearer <token>
 	authHeader := r.Header.Get("Authorization")
@@ -61,19 +92,123 @@ func (h *SessionHandler) SetSessionCookie(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// кука живет не дольше токена
+	expires := claims.ExpiresAt
+	if expires.IsZero() {
+		expires = time.Now().Add(TokenExp)
+	}
+
+	// CSRF-токен сохраняется при обновлении сессии, чтобы не сломать уже отправленные запросы
+	csrfToken := ""
+	if cookie, err := r.Cookie(middleware.CSRFCookie); err == nil && len(cookie.Value) == base64.RawURLEncoding.EncodedLen(csrfTokenLen) {
+		csrfToken = cookie.Value
+	} else {
+		csrfToken, err = newCSRFToken()
+		if err != nil {
+			logger.Log.Error("Не удалось создать CSRF-токен", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+			return
+		}
+	}
+
 	// устанавливаем HttpOnly куку,
 	// флаг HttpOnly защищает от XSS, Secure требует HTTPS, SameSite=Lax даёт базовую защиту от CSRF.
-	cookie := http.Cookie{
-		Name:     "JWT",
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookie,
 		Value:    token,
-		Expires:  time.Now().Add(TokenExp),
+		Expires:  expires,
 		Path:     "/",
 		HttpOnly: true,
 		Secure:   true,
 		SameSite: http.SameSiteLaxMode,
+	})
+
+	// CSRF-токен должен быть доступен JavaScript фронтенда
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.CSRFCookie,
+		Value:    csrfToken,
+		Expires:  expires,
+		Path:     "/",
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{"Status": "OK", "csrf_token": csrfToken})
+}
+
+// ClearSessionCookie Завершение сессии браузера: удаляет куки с JWT и CSRF-токеном.
+// Сам токен остается действительным до истечения срока, выход у провайдера выполняет фронтенд.
+func (h *SessionHandler) ClearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{middleware.SessionCookie, middleware.CSRFCookie} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Expires:  time.Unix(0, 0),
+			MaxAge:   -1,
+			Path:     "/",
+			HttpOnly: name == middleware.SessionCookie,
+			Secure:   true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IssueSSETicket Выдает одноразовый билет для подключения к SSE-потоку stream.
+//
+// Билет заменяет сессионную куку: фронтенд получает его обычным запросом с заголовком
+// Authorization и подключается к /api/user/broadcasting?stream=<stream>&ticket=<ticket>
+// без withCredentials. Билет действует ticketTTL, принимается один раз и только для
+// потока, на который выдан, в хранилище сохраняется только его хэш.
+//
+// Билет не выдается по API-токену: SSE-поток не учитывает ограничения токена (серверы, роль),
+// поэтому токен, ограниченный одним сервером, получал бы события всех серверов пользователя.
+func (h *SessionHandler) IssueSSETicket(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	if creds.APIToken != nil {
+		response.ErrorJSON(w, http.StatusForbidden, "Билет SSE нельзя получить по API-токену")
+		return
+	}
+
+	var request sseTicketRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log.Debug("Неверный формат запроса на выдачу билета SSE", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := broadcast.ValidateStream(request.Stream); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, "Неизвестный тип потока")
+		return
 	}
 
-	http.SetCookie(w, &cookie)
+	ticket, err := broadcast.GenerateTicket()
+	if err != nil {
+		logger.Log.Error("Не удалось создать билет SSE", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return
+	}
+
+	expiresAt := time.Now().Add(h.ticketTTL)
+
+	if err = h.storage.AddSSETicket(ctx, broadcast.HashTicket(ticket), creds.UserID, request.Stream, expiresAt); err != nil {
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, sseTicketResponse{Ticket: ticket, ExpiresAt: expiresAt})
+}
+
+// newCSRFToken Создает случайный CSRF-токен.
+func newCSRFToken() (string, error) {
+	buf := make([]byte, csrfTokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
 
-	response.JSON(w, http.StatusOK, map[string]string{"Status": "OK"})
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
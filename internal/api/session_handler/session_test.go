@@ -1,17 +1,30 @@
 package session_handler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	appModels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
 // Test_SetSessionCookie_Success
 // Проверяет, что при валидном токене выставляется HttpOnly кука JWT и возвращается 200 OK.
 func Test_SetSessionCookie_Success(t *testing.T) {
@@ -35,7 +48,7 @@ func Test_SetSessionCookie_Success(t *testing.T) {
 		}, nil)
 
 	// создаём тестируемый хендлер
-	h := NewSessionHandler(mockAuth)
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
 
 	// собираем запрос с заголовком Authorization: Bearer <token>
 	req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
@@ -109,7 +122,7 @@ func Test_SetSessionCookie_MissingAuthorization(t *testing.T) {
 	// в этом кейсе ValidateToken вызываться не должен
 	mockAuth.EXPECT().ValidateToken(gomock.Any(), gomock.Any()).Times(0)
 
-	h := NewSessionHandler(mockAuth)
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
 
 	// запрос без заголовка Authorization
 	req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
@@ -148,7 +161,7 @@ func Test_SetSessionCookie_InvalidToken_Error(t *testing.T) {
 		ValidateToken(gomock.Any(), token).
 		Return(nil, errors.New("invalid token"))
 
-	h := NewSessionHandler(mockAuth)
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -188,7 +201,7 @@ func Test_SetSessionCookie_InvalidToken_EmptyID(t *testing.T) {
 			Login: "some-login",
 		}, nil)
 
-	h := NewSessionHandler(mockAuth)
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -208,3 +221,252 @@ func Test_SetSessionCookie_InvalidToken_EmptyID(t *testing.T) {
 		t.Fatalf("куки не должны устанавливаться при пустом ID в claims, но получены: %#v", res.Cookies())
 	}
 }
+
+// findCookie Возвращает куку с заданным именем из ответа.
+func findCookie(res *http.Response, name string) *http.Cookie {
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Test_SetSessionCookie_ExpiresWithToken
+// Проверяет, что кука истекает вместе с токеном и вместе с ней выставляется CSRF-токен.
+func Test_SetSessionCookie_ExpiresWithToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthProvider(ctrl)
+
+	expiresAt := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+	mockAuth.EXPECT().ValidateToken(gomock.Any(), "valid-jwt-token").
+		Return(&models.UserClaims{ID: "any-id-user-1", Login: "testuser", ExpiresAt: expiresAt}, nil)
+
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
+	req.Header.Set("Authorization", "Bearer valid-jwt-token")
+	rr := httptest.NewRecorder()
+
+	h.SetSessionCookie(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("ожидался статус %d, получен %d, body: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	jwtCookie := findCookie(res, middleware.SessionCookie)
+	if jwtCookie == nil || !jwtCookie.Expires.Equal(expiresAt) {
+		t.Fatalf("ожидалась кука JWT с Expires=%v, получена %#v", expiresAt, jwtCookie)
+	}
+
+	csrfCookie := findCookie(res, middleware.CSRFCookie)
+	if csrfCookie == nil || csrfCookie.Value == "" {
+		t.Fatalf("ожидалась кука с CSRF-токеном, получены: %#v", res.Cookies())
+	}
+	if csrfCookie.HttpOnly {
+		t.Fatalf("кука с CSRF-токеном должна быть доступна JavaScript")
+	}
+	if !csrfCookie.Expires.Equal(expiresAt) {
+		t.Fatalf("ожидалось Expires=%v у куки CSRF-токена, получено %v", expiresAt, csrfCookie.Expires)
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("не удалось разобрать ответ: %v", err)
+	}
+	if body["csrf_token"] != csrfCookie.Value {
+		t.Fatalf("ожидался csrf_token %q в ответе, получен %q", csrfCookie.Value, body["csrf_token"])
+	}
+}
+
+// Test_SetSessionCookie_KeepsCSRFToken
+// Проверяет, что при обновлении сессии существующий CSRF-токен сохраняется.
+func Test_SetSessionCookie_KeepsCSRFToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthProvider(ctrl)
+	mockAuth.EXPECT().ValidateToken(gomock.Any(), gomock.Any()).
+		Return(&models.UserClaims{ID: "any-id-user-1", Login: "testuser"}, nil).Times(2)
+
+	h := NewSessionHandler(mockAuth, nil, time.Minute)
+
+	existing, err := newCSRFToken()
+	if err != nil {
+		t.Fatalf("не удалось создать CSRF-токен: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cookie    string
+		wantReuse bool
+	}{
+		{name: "действующий токен сохраняется", cookie: existing, wantReuse: true},
+		{name: "поврежденный токен заменяется", cookie: "short", wantReuse: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/session", nil)
+			req.Header.Set("Authorization", "Bearer valid-jwt-token")
+			req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: tt.cookie})
+			rr := httptest.NewRecorder()
+
+			h.SetSessionCookie(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			csrfCookie := findCookie(res, middleware.CSRFCookie)
+			if csrfCookie == nil {
+				t.Fatalf("ожидалась кука с CSRF-токеном")
+			}
+			if (csrfCookie.Value == tt.cookie) != tt.wantReuse {
+				t.Fatalf("CSRF-токен %q, исходный %q, ожидалось сохранение: %v", csrfCookie.Value, tt.cookie, tt.wantReuse)
+			}
+		})
+	}
+}
+
+// Test_ClearSessionCookie
+// Проверяет, что при выходе куки JWT и CSRF-токена удаляются.
+func Test_ClearSessionCookie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewSessionHandler(mocks.NewMockAuthProvider(ctrl), nil, time.Minute)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/user/session", nil)
+	rr := httptest.NewRecorder()
+
+	h.ClearSessionCookie(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("ожидался статус %d, получен %d", http.StatusNoContent, rr.Code)
+	}
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	for _, name := range []string{middleware.SessionCookie, middleware.CSRFCookie} {
+		cookie := findCookie(res, name)
+		if cookie == nil {
+			t.Fatalf("ожидалось удаление куки %s", name)
+		}
+		if cookie.Value != "" || cookie.MaxAge >= 0 {
+			t.Fatalf("кука %s должна быть удалена, получена %#v", name, cookie)
+		}
+	}
+}
+
+// Test_IssueSSETicket
+// Проверяет выдачу одноразового билета SSE: в хранилище сохраняется хэш выданного билета.
+func Test_IssueSSETicket(t *testing.T) {
+	// хэш и срок действия сохраненного билета, чтобы сравнить их с ответом
+	var savedHash string
+	var savedExpiresAt time.Time
+
+	tests := []struct {
+		name       string
+		body       string
+		apiToken   *appModels.APIToken
+		setupMock  func(storage *storageMocks.MockStorage)
+		wantStatus int
+	}{
+		{
+			name: "билет выдан",
+			body: `{"stream":"services"}`,
+			setupMock: func(storage *storageMocks.MockStorage) {
+				storage.EXPECT().
+					AddSSETicket(gomock.Any(), gomock.Any(), "user-1", "services", gomock.Any()).
+					DoAndReturn(func(_ context.Context, ticketHash, _, _ string, expiresAt time.Time) error {
+						savedHash, savedExpiresAt = ticketHash, expiresAt
+						return nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "запрос по API-токену, ограниченному сервером",
+			body:       `{"stream":"servers"}`,
+			apiToken:   &appModels.APIToken{ID: 1, ServerIDs: []int64{7}, Restricted: true},
+			setupMock:  func(storage *storageMocks.MockStorage) {},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "запрос по API-токену без ограничений",
+			body:       `{"stream":"services"}`,
+			apiToken:   &appModels.APIToken{ID: 2},
+			setupMock:  func(storage *storageMocks.MockStorage) {},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "неизвестный поток",
+			body:       `{"stream":"unknown"}`,
+			setupMock:  func(storage *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "неверный формат запроса",
+			body:       `{"stream":`,
+			setupMock:  func(storage *storageMocks.MockStorage) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ошибка хранилища",
+			body: `{"stream":"servers"}`,
+			setupMock: func(storage *storageMocks.MockStorage) {
+				storage.EXPECT().
+					AddSSETicket(gomock.Any(), gomock.Any(), "user-1", "servers", gomock.Any()).
+					Return(errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			h := NewSessionHandler(mocks.NewMockAuthProvider(ctrl), mockStorage, 30*time.Second)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/broadcasting/ticket", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), contextkeys.UserID, "user-1"))
+			if tt.apiToken != nil {
+				req = req.WithContext(context.WithValue(req.Context(), contextkeys.APIToken, tt.apiToken))
+			}
+			rr := httptest.NewRecorder()
+
+			before := time.Now()
+			h.IssueSSETicket(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("ожидался статус %d, получен %d, body: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var resp sseTicketResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("не удалось разобрать ответ: %v", err)
+			}
+			if resp.Ticket == "" || broadcast.HashTicket(resp.Ticket) != savedHash {
+				t.Fatalf("в хранилище должен сохраняться хэш выданного билета")
+			}
+			if !resp.ExpiresAt.Equal(savedExpiresAt) || resp.ExpiresAt.Before(before.Add(30*time.Second)) {
+				t.Fatalf("неверный срок действия билета: %v", resp.ExpiresAt)
+			}
+		})
+	}
+}
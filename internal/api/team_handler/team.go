@@ -0,0 +1,393 @@
+package team_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// TeamHandler Обработчик для управления командами, их участниками и приглашениями.
+type TeamHandler struct {
+	storage storage.Storage
+}
+
+// NewTeamHandler Конструктор TeamHandler.
+func NewTeamHandler(storage storage.Storage) *TeamHandler {
+	return &TeamHandler{
+		storage: storage,
+	}
+}
+
+// memberRoleRequest Тело запроса на изменение роли участника команды.
+type memberRoleRequest struct {
+	Role models.Role `json:"role"`
+}
+
+// GetTeams Возвращает список команд текущего пользователя с его ролью в каждой из них.
+func (h *TeamHandler) GetTeams(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	teams, err := h.storage.ListTeams(r.Context(), creds.UserID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении списка команд", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении списка команд")
+		return
+	}
+
+	// если команд нет - возвращаем пустой срез
+	if len(teams) == 0 {
+		teams = []*models.Team{}
+	}
+
+	response.JSON(w, http.StatusOK, teams)
+}
+
+// CreateTeam Создание команды. Создатель становится ее администратором.
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var team models.Team
+
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		logger.Log.Debug("Неверный формат запроса для создания команды", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := team.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	team.Name = strings.TrimSpace(team.Name)
+	models.SetAuditTarget(ctx, 0, 0, team.Name)
+
+	createdTeam, err := h.storage.CreateTeam(ctx, team, creds.UserID)
+	if err != nil {
+		logger.Log.Error("Ошибка создания команды", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка создания команды")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(createdTeam.ID, createdTeam.Name))
+
+	logger.Log.Debug("Команда успешно создана пользователем",
+		logger.String("login", creds.Login), logger.Int64("teamID", createdTeam.ID))
+
+	response.JSON(w, http.StatusCreated, createdTeam)
+}
+
+// DelTeam Удаление команды вместе с ее серверами и службами.
+func (h *TeamHandler) DelTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, ""))
+
+	err := h.storage.DelTeam(ctx, creds.TeamID)
+	if err != nil {
+		var errTeamNotFound *errs.ErrTeamNotFound
+
+		if errors.As(err, &errTeamNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "Команда не найдена")
+			return
+		}
+
+		logger.Log.Warn("Ошибка при удалении команды", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при удалении команды")
+		return
+	}
+
+	logger.Log.Debug("Команда успешно удалена пользователем",
+		logger.String("login", creds.Login), logger.Int64("teamID", creds.TeamID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMembers Возвращает список участников команды.
+func (h *TeamHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	members, err := h.storage.ListTeamMembers(r.Context(), creds.TeamID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении списка участников команды", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении списка участников команды")
+		return
+	}
+
+	if len(members) == 0 {
+		members = []*models.TeamMember{}
+	}
+
+	response.JSON(w, http.StatusOK, members)
+}
+
+// SetMemberRole Изменение роли участника команды.
+func (h *TeamHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+	memberID := chi.URLParam(r, "userID")
+
+	var req memberRoleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Debug("Неверный формат запроса для изменения роли участника", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if !models.IsValidRole(req.Role) {
+		response.ErrorJSON(w, http.StatusBadRequest, "необходимо указать роль: viewer, operator или admin")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, memberID+" -> "+string(req.Role)))
+
+	err := h.storage.SetTeamMemberRole(ctx, creds.TeamID, memberID, req.Role)
+	if err != nil {
+		writeMemberError(w, err, "Ошибка при изменении роли участника")
+		return
+	}
+
+	logger.Log.Debug("Роль участника команды изменена",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.String("memberID", memberID),
+		logger.String("role", string(req.Role)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DelMember Исключение участника из команды. Администратор может исключить любого участника,
+// остальные — только покинуть команду сами.
+func (h *TeamHandler) DelMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+	memberID := chi.URLParam(r, "userID")
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, memberID))
+
+	if memberID != creds.UserID && !creds.Role.Allows(models.RoleAdmin) {
+		logger.Log.Warn("Недостаточно прав для исключения участника команды",
+			logger.String("login", creds.Login),
+			logger.Int64("teamID", creds.TeamID),
+			logger.String("role", string(creds.Role)))
+		response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав")
+		return
+	}
+
+	err := h.storage.DelTeamMember(ctx, creds.TeamID, memberID)
+	if err != nil {
+		writeMemberError(w, err, "Ошибка при исключении участника")
+		return
+	}
+
+	logger.Log.Debug("Участник исключен из команды",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.String("memberID", memberID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateInvitation Приглашение пользователя в команду по логину.
+func (h *TeamHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	var invitation models.TeamInvitation
+
+	if err := json.NewDecoder(r.Body).Decode(&invitation); err != nil {
+		logger.Log.Debug("Неверный формат запроса для приглашения в команду", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := invitation.Validate(); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	invitation.TeamID = creds.TeamID
+	invitation.Login = strings.TrimSpace(invitation.Login)
+	invitation.ExpiresAt = time.Now().Add(models.TeamInvitationTTL)
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, invitation.Login+" -> "+string(invitation.Role)))
+
+	created, err := h.storage.AddTeamInvitation(ctx, invitation, creds.UserID)
+	if err != nil {
+		var errDuplicatedInvitation *errs.ErrDuplicatedInvitation
+
+		if errors.As(err, &errDuplicatedInvitation) {
+			response.ErrorJSON(w, http.StatusConflict, "Пользователь уже приглашен или состоит в команде")
+			return
+		}
+
+		logger.Log.Error("Ошибка создания приглашения", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка создания приглашения")
+		return
+	}
+
+	logger.Log.Debug("Пользователь приглашен в команду",
+		logger.String("login", creds.Login),
+		logger.Int64("teamID", creds.TeamID),
+		logger.String("invitee", invitation.Login))
+
+	response.JSON(w, http.StatusCreated, created)
+}
+
+// GetInvitations Возвращает список действующих приглашений в команду.
+func (h *TeamHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	invitations, err := h.storage.ListTeamInvitations(r.Context(), creds.TeamID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении приглашений команды", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении приглашений")
+		return
+	}
+
+	writeInvitations(w, invitations)
+}
+
+// DelInvitation Отзыв приглашения в команду.
+func (h *TeamHandler) DelInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	invitationID, ok := parseInvitationID(w, r)
+	if !ok {
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(creds.TeamID, "invitation "+strconv.FormatInt(invitationID, 10)))
+
+	if err := h.storage.DelTeamInvitation(ctx, creds.TeamID, invitationID); err != nil {
+		writeInvitationError(w, err, "Ошибка при отзыве приглашения")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUserInvitations Возвращает действующие приглашения текущего пользователя.
+func (h *TeamHandler) GetUserInvitations(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	invitations, err := h.storage.ListUserInvitations(r.Context(), creds.Login)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении приглашений пользователя", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении приглашений")
+		return
+	}
+
+	writeInvitations(w, invitations)
+}
+
+// AcceptInvitation Принятие приглашения: пользователь становится участником команды.
+func (h *TeamHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	invitationID, ok := parseInvitationID(w, r)
+	if !ok {
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, "invitation "+strconv.FormatInt(invitationID, 10))
+
+	team, err := h.storage.AcceptTeamInvitation(ctx, invitationID, creds.UserID, creds.Login)
+	if err != nil {
+		writeInvitationError(w, err, "Ошибка при принятии приглашения")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, models.TeamAuditTarget(team.ID, team.Name))
+
+	logger.Log.Debug("Пользователь вступил в команду",
+		logger.String("login", creds.Login), logger.Int64("teamID", team.ID))
+
+	response.JSON(w, http.StatusOK, team)
+}
+
+// DeclineInvitation Отклонение приглашения текущим пользователем.
+func (h *TeamHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	invitationID, ok := parseInvitationID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.storage.DeclineTeamInvitation(r.Context(), invitationID, creds.Login); err != nil {
+		writeInvitationError(w, err, "Ошибка при отклонении приглашения")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseInvitationID Извлекает id приглашения из URL. При ошибке пишет ответ и возвращает false.
+func parseInvitationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "invitationID"), 10, 64)
+	if err != nil || id <= 0 {
+		response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id приглашения")
+		return 0, false
+	}
+
+	return id, true
+}
+
+// writeInvitations Пишет список приглашений (пустой срез вместо null).
+func writeInvitations(w http.ResponseWriter, invitations []*models.TeamInvitation) {
+	if len(invitations) == 0 {
+		invitations = []*models.TeamInvitation{}
+	}
+
+	response.JSON(w, http.StatusOK, invitations)
+}
+
+// writeMemberError Преобразует ошибку изменения состава команды в HTTP-ответ.
+func writeMemberError(w http.ResponseWriter, err error, msg string) {
+	var (
+		errTeamNotFound   *errs.ErrTeamNotFound
+		errMemberNotFound *errs.ErrTeamMemberNotFound
+		errLastTeamAdmin  *errs.ErrLastTeamAdmin
+	)
+
+	switch {
+	case errors.As(err, &errTeamNotFound):
+		response.ErrorJSON(w, http.StatusNotFound, "Команда не найдена")
+	case errors.As(err, &errMemberNotFound):
+		response.ErrorJSON(w, http.StatusNotFound, "Участник не найден")
+	case errors.As(err, &errLastTeamAdmin):
+		response.ErrorJSON(w, http.StatusConflict, "В команде должен остаться хотя бы один администратор")
+	default:
+		logger.Log.Warn(msg, logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// writeInvitationError Преобразует ошибку работы с приглашением в HTTP-ответ.
+func writeInvitationError(w http.ResponseWriter, err error, msg string) {
+	var errInvitationNotFound *errs.ErrInvitationNotFound
+
+	if errors.As(err, &errInvitationNotFound) {
+		response.ErrorJSON(w, http.StatusNotFound, "Приглашение не найдено")
+		return
+	}
+
+	logger.Log.Warn(msg, logger.String("err", err.Error()))
+	response.ErrorJSON(w, http.StatusInternalServerError, msg)
+}
@@ -0,0 +1,384 @@
+package team_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// newRequest Создает запрос с данными пользователя, команды и параметрами URL роутера Chi.
+func newRequest(method string, body any, role models.Role, params map[string]string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(method, "/api/user/teams", &buf)
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextkeys.Login, "alice")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "user-1")
+	ctx = context.WithValue(ctx, contextkeys.Role, role)
+	ctx = context.WithValue(ctx, contextkeys.TeamID, int64(5))
+
+	return r.WithContext(ctx)
+}
+
+// TestGetTeams Проверяет получение списка команд пользователя.
+func TestGetTeams(t *testing.T) {
+	tests := []struct {
+		name           string
+		teams          []*models.Team
+		storageErr     error
+		expectedStatus int
+		expectedLen    int
+	}{
+		{"список команд", []*models.Team{{ID: 1, Name: "alice", Role: models.RoleAdmin}, {ID: 5, Name: "Ops", Role: models.RoleViewer}}, nil, http.StatusOK, 2},
+		{"нет команд", nil, nil, http.StatusOK, 0},
+		{"ошибка хранилища", nil, errors.New("db error"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().ListTeams(gomock.Any(), "user-1").Return(tt.teams, tt.storageErr)
+
+			w := httptest.NewRecorder()
+			NewTeamHandler(mockStorage).GetTeams(w, newRequest(http.MethodGet, nil, models.RoleViewer, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var got []*models.Team
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.NotNil(t, got)
+				assert.Len(t, got, tt.expectedLen)
+			}
+		})
+	}
+}
+
+// TestCreateTeam Проверяет создание команды.
+func TestCreateTeam(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное создание",
+			body: models.Team{Name: "  Ops  "},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().CreateTeam(gomock.Any(), models.Team{Name: "Ops"}, "user-1").
+					Return(&models.Team{ID: 5, Name: "Ops", Role: models.RoleAdmin}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "пустое название",
+			body:           models.Team{Name: " "},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "невалидный JSON",
+			body:           "{invalid}",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ошибка хранилища",
+			body: models.Team{Name: "Ops"},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().CreateTeam(gomock.Any(), gomock.Any(), "user-1").Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			NewTeamHandler(mockStorage).CreateTeam(w, newRequest(http.MethodPost, tt.body, models.RoleViewer, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestSetMemberRole Проверяет изменение роли участника команды.
+func TestSetMemberRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное изменение",
+			body: memberRoleRequest{Role: models.RoleOperator},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().SetTeamMemberRole(gomock.Any(), int64(5), "user-2", models.RoleOperator).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "неизвестная роль",
+			body:           memberRoleRequest{Role: "owner"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "последний администратор",
+			body: memberRoleRequest{Role: models.RoleViewer},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().SetTeamMemberRole(gomock.Any(), int64(5), "user-2", models.RoleViewer).
+					Return(errs.NewErrLastTeamAdmin(5))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "участник не найден",
+			body: memberRoleRequest{Role: models.RoleViewer},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().SetTeamMemberRole(gomock.Any(), int64(5), "user-2", models.RoleViewer).
+					Return(errs.NewErrTeamMemberNotFound(5, "user-2", nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			r := newRequest(http.MethodPatch, tt.body, models.RoleAdmin, map[string]string{"userID": "user-2"})
+			NewTeamHandler(mockStorage).SetMemberRole(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestDelMember Проверяет исключение участника и выход из команды.
+func TestDelMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           models.Role
+		memberID       string
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name:     "администратор исключает участника",
+			role:     models.RoleAdmin,
+			memberID: "user-2",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DelTeamMember(gomock.Any(), int64(5), "user-2").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:     "наблюдатель покидает команду",
+			role:     models.RoleViewer,
+			memberID: "user-1",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DelTeamMember(gomock.Any(), int64(5), "user-1").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "оператор исключает другого участника",
+			role:           models.RoleOperator,
+			memberID:       "user-2",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "последний администратор покидает команду",
+			role:     models.RoleAdmin,
+			memberID: "user-1",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DelTeamMember(gomock.Any(), int64(5), "user-1").Return(errs.NewErrLastTeamAdmin(5))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			r := newRequest(http.MethodDelete, nil, tt.role, map[string]string{"userID": tt.memberID})
+			NewTeamHandler(mockStorage).DelMember(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestCreateInvitation Проверяет приглашение пользователя в команду.
+func TestCreateInvitation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           any
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное приглашение",
+			body: models.TeamInvitation{Login: " bob ", Role: models.RoleOperator},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AddTeamInvitation(gomock.Any(), gomock.Any(), "user-1").
+					DoAndReturn(func(_ context.Context, inv models.TeamInvitation, _ string) (*models.TeamInvitation, error) {
+						assert.Equal(t, int64(5), inv.TeamID)
+						assert.Equal(t, "bob", inv.Login)
+						assert.WithinDuration(t, time.Now().Add(models.TeamInvitationTTL), inv.ExpiresAt, time.Minute)
+						inv.ID = 7
+						return &inv, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "не указана роль",
+			body:           models.TeamInvitation{Login: "bob"},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "повторное приглашение",
+			body: models.TeamInvitation{Login: "bob", Role: models.RoleViewer},
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AddTeamInvitation(gomock.Any(), gomock.Any(), "user-1").
+					Return(nil, errs.NewErrDuplicatedInvitation("bob", errors.New("duplicate")))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			NewTeamHandler(mockStorage).CreateInvitation(w, newRequest(http.MethodPost, tt.body, models.RoleAdmin, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestAcceptInvitation Проверяет принятие приглашения в команду.
+func TestAcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name           string
+		invitationID   string
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name:         "успешное принятие",
+			invitationID: "7",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AcceptTeamInvitation(gomock.Any(), int64(7), "user-1", "alice").
+					Return(&models.Team{ID: 5, Name: "Ops", Role: models.RoleOperator}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "некорректный id",
+			invitationID:   "abc",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "приглашение не найдено",
+			invitationID: "7",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AcceptTeamInvitation(gomock.Any(), int64(7), "user-1", "alice").
+					Return(nil, errs.NewErrInvitationNotFound(7, nil))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			r := newRequest(http.MethodPost, nil, models.RoleViewer, map[string]string{"invitationID": tt.invitationID})
+			NewTeamHandler(mockStorage).AcceptInvitation(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestDeclineInvitation Проверяет отклонение приглашения в команду.
+func TestDeclineInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().DeclineTeamInvitation(gomock.Any(), int64(7), "alice").Return(nil)
+
+	w := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, nil, models.RoleViewer, map[string]string{"invitationID": "7"})
+	NewTeamHandler(mockStorage).DeclineInvitation(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
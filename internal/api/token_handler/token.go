@@ -0,0 +1,188 @@
+package token_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/apitoken"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// TokenHandler Обработчик для управления персональными API-токенами пользователя.
+type TokenHandler struct {
+	storage storage.Storage
+}
+
+// NewTokenHandler Конструктор TokenHandler.
+func NewTokenHandler(storage storage.Storage) *TokenHandler {
+	return &TokenHandler{
+		storage: storage,
+	}
+}
+
+// createTokenRequest Тело запроса на создание API-токена.
+type createTokenRequest struct {
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	ServerIDs []int64   `json:"server_ids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GetTokens Возвращает API-токены текущего пользователя (без самих токенов).
+func (h *TokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	tokens, err := h.storage.ListAPITokens(r.Context(), creds.UserID)
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении API-токенов", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении API-токенов")
+		return
+	}
+
+	// если токенов нет - возвращаем пустой срез
+	if len(tokens) == 0 {
+		tokens = []*models.APIToken{}
+	}
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// CreateToken Создание API-токена. Токен возвращается в ответе один раз, в хранилище сохраняется только его хэш.
+// Токен с областью control может создать пользователь с ролью не ниже operator,
+// ограничить токен можно только серверами, доступными пользователю.
+func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	// API-токен не может выпускать новые токены
+	if creds.APIToken != nil {
+		response.ErrorJSON(w, http.StatusForbidden, "Создание API-токенов по API-токену запрещено")
+		return
+	}
+
+	var request createTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Log.Debug("Неверный формат запроса на создание API-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+
+	serverIDs := slices.Compact(slices.Sorted(slices.Values(request.ServerIDs)))
+	if serverIDs == nil {
+		serverIDs = []int64{}
+	}
+
+	token := models.APIToken{
+		UserID:     creds.UserID,
+		Name:       strings.TrimSpace(request.Name),
+		Scope:      request.Scope,
+		ServerIDs:  serverIDs,
+		Restricted: len(serverIDs) > 0,
+		ExpiresAt:  request.ExpiresAt,
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, "api token "+token.Name)
+
+	if err := token.Validate(time.Now()); err != nil {
+		response.ErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !creds.Role.Allows(token.Role()) {
+		response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав для создания токена с областью "+token.Scope)
+		return
+	}
+
+	for _, serverID := range token.ServerIDs {
+		if _, err := h.storage.GetServer(ctx, serverID, creds.UserID); err != nil {
+			var errServerNotFound *errs.ErrServerNotFound
+
+			if errors.As(err, &errServerNotFound) {
+				response.ErrorJSON(w, http.StatusBadRequest, "Сервер с id "+strconv.FormatInt(serverID, 10)+" не найден")
+				return
+			}
+
+			logger.Log.Warn("Ошибка при получении сервера", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании API-токена")
+			return
+		}
+	}
+
+	secret, err := apitoken.Generate()
+	if err != nil {
+		logger.Log.Error("Ошибка генерации API-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании API-токена")
+		return
+	}
+
+	token.Prefix = apitoken.DisplayPrefix(secret)
+
+	created, err := h.storage.AddAPIToken(ctx, token, apitoken.Hash(secret))
+	if err != nil {
+		var errDuplicated *errs.ErrDuplicatedAPIToken
+
+		if errors.As(err, &errDuplicated) {
+			response.ErrorJSON(w, http.StatusConflict, "API-токен с таким названием уже существует")
+			return
+		}
+
+		logger.Log.Warn("Ошибка при создании API-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при создании API-токена")
+		return
+	}
+
+	logger.Log.Info("Создан API-токен",
+		logger.String("login", creds.Login),
+		logger.Int64("tokenID", created.ID),
+		logger.String("scope", created.Scope))
+
+	response.JSON(w, http.StatusCreated, models.CreatedAPIToken{APIToken: *created, Token: secret})
+}
+
+// DelToken Отзыв (удаление) API-токена текущего пользователя.
+func (h *TokenHandler) DelToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	creds := models.GetContextCreds(ctx)
+
+	// API-токен не может отзывать токены владельца
+	if creds.APIToken != nil {
+		response.ErrorJSON(w, http.StatusForbidden, "Отзыв API-токенов по API-токену запрещен")
+		return
+	}
+
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenID"), 10, 64)
+	if err != nil || tokenID <= 0 {
+		response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id API-токена")
+		return
+	}
+
+	models.SetAuditTarget(ctx, 0, 0, "api token "+strconv.FormatInt(tokenID, 10))
+
+	if err = h.storage.DelAPIToken(ctx, tokenID, creds.UserID); err != nil {
+		var errNotFound *errs.ErrAPITokenNotFound
+
+		if errors.As(err, &errNotFound) {
+			response.ErrorJSON(w, http.StatusNotFound, "API-токен не найден")
+			return
+		}
+
+		logger.Log.Warn("Ошибка при отзыве API-токена", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при отзыве API-токена")
+		return
+	}
+
+	logger.Log.Info("API-токен отозван", logger.String("login", creds.Login), logger.Int64("tokenID", tokenID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
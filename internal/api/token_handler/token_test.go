@@ -0,0 +1,263 @@
+package token_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/apitoken"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// newRequest Создает запрос с данными пользователя и параметрами URL роутера Chi.
+func newRequest(method string, body any, role models.Role, token *models.APIToken, params map[string]string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+
+	r := httptest.NewRequest(method, "/api/user/tokens", &buf)
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, contextkeys.Login, "alice")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "user-1")
+	ctx = context.WithValue(ctx, contextkeys.Role, role)
+	if token != nil {
+		ctx = context.WithValue(ctx, contextkeys.APIToken, token)
+	}
+
+	return r.WithContext(ctx)
+}
+
+// TestGetTokens Проверяет получение списка API-токенов пользователя.
+func TestGetTokens(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokens         []*models.APIToken
+		storageErr     error
+		expectedStatus int
+		expectedLen    int
+	}{
+		{"список токенов", []*models.APIToken{{ID: 1, Name: "ci"}, {ID: 2, Name: "backup"}}, nil, http.StatusOK, 2},
+		{"нет токенов", nil, nil, http.StatusOK, 0},
+		{"ошибка хранилища", nil, errors.New("db error"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().ListAPITokens(gomock.Any(), "user-1").Return(tt.tokens, tt.storageErr)
+
+			w := httptest.NewRecorder()
+			NewTokenHandler(mockStorage).GetTokens(w, newRequest(http.MethodGet, nil, models.RoleViewer, nil, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var got []*models.APIToken
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.NotNil(t, got)
+				assert.Len(t, got, tt.expectedLen)
+			}
+		})
+	}
+}
+
+// TestCreateToken Проверяет создание API-токена.
+func TestCreateToken(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		body           any
+		role           models.Role
+		apiToken       *models.APIToken
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "успешное создание токена с ограничением серверами",
+			body: createTokenRequest{Name: " ci ", Scope: models.APITokenScopeControl, ServerIDs: []int64{100, 7, 100}, ExpiresAt: expiresAt},
+			role: models.RoleOperator,
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServer(gomock.Any(), int64(7), "user-1").Return(&models.Server{ID: 7}, nil)
+				m.EXPECT().GetServer(gomock.Any(), int64(100), "user-1").Return(&models.Server{ID: 100}, nil)
+				m.EXPECT().AddAPIToken(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, token models.APIToken, tokenHash string) (*models.APIToken, error) {
+						assert.Equal(t, "ci", token.Name)
+						assert.Equal(t, "user-1", token.UserID)
+						assert.Equal(t, []int64{7, 100}, token.ServerIDs)
+						assert.True(t, token.Restricted)
+						assert.True(t, strings.HasPrefix(token.Prefix, apitoken.Prefix))
+						assert.Len(t, tokenHash, 64)
+						token.ID = 3
+						return &token, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "токен управления для наблюдателя",
+			body:           createTokenRequest{Name: "ci", Scope: models.APITokenScopeControl, ExpiresAt: expiresAt},
+			role:           models.RoleViewer,
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "создание по API-токену",
+			body:           createTokenRequest{Name: "ci", Scope: models.APITokenScopeRead, ExpiresAt: expiresAt},
+			role:           models.RoleOperator,
+			apiToken:       &models.APIToken{ID: 1, Scope: models.APITokenScopeControl},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "неизвестная область действия",
+			body:           createTokenRequest{Name: "ci", Scope: "admin", ExpiresAt: expiresAt},
+			role:           models.RoleAdmin,
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "без срока действия",
+			body:           createTokenRequest{Name: "ci", Scope: models.APITokenScopeRead},
+			role:           models.RoleViewer,
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "невалидный JSON",
+			body:           "{invalid}",
+			role:           models.RoleViewer,
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "сервер недоступен пользователю",
+			body: createTokenRequest{Name: "ci", Scope: models.APITokenScopeRead, ServerIDs: []int64{9}, ExpiresAt: expiresAt},
+			role: models.RoleViewer,
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServer(gomock.Any(), int64(9), "user-1").
+					Return(nil, errs.NewErrServerNotFound(9, "user-1", errors.New("no rows")))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "токен с таким названием уже существует",
+			body: createTokenRequest{Name: "ci", Scope: models.APITokenScopeRead, ExpiresAt: expiresAt},
+			role: models.RoleViewer,
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().AddAPIToken(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewErrDuplicatedAPIToken("ci", errors.New("duplicate")))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			NewTokenHandler(mockStorage).CreateToken(w, newRequest(http.MethodPost, tt.body, tt.role, tt.apiToken, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusCreated {
+				var got models.CreatedAPIToken
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, int64(3), got.ID)
+				assert.True(t, apitoken.IsAPIToken(got.Token))
+				assert.Equal(t, apitoken.DisplayPrefix(got.Token), got.Prefix)
+			}
+		})
+	}
+}
+
+// TestDelToken Проверяет отзыв API-токена.
+func TestDelToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokenID        string
+		apiToken       *models.APIToken
+		setupStorage   func(m *storageMocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name:    "успешный отзыв",
+			tokenID: "3",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DelAPIToken(gomock.Any(), int64(3), "user-1").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "токен не найден",
+			tokenID: "3",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DelAPIToken(gomock.Any(), int64(3), "user-1").
+					Return(errs.NewErrAPITokenNotFound(3, errors.New("no rows")))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "отзыв по API-токену с областью read",
+			tokenID:        "3",
+			apiToken:       &models.APIToken{ID: 1, Scope: models.APITokenScopeRead},
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "некорректный id",
+			tokenID:        "abc",
+			setupStorage:   func(m *storageMocks.MockStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			w := httptest.NewRecorder()
+			r := newRequest(http.MethodDelete, nil, models.RoleViewer, tt.apiToken, map[string]string{"tokenID": tt.tokenID})
+			NewTokenHandler(mockStorage).DelToken(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
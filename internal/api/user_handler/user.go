@@ -0,0 +1,57 @@
+package user_handler
+
+import (
+	"net/http"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// UserHandler Обработчик для получения сведений о пользователях.
+type UserHandler struct {
+	storage storage.Storage
+}
+
+// NewUserHandler Конструктор UserHandler.
+func NewUserHandler(storage storage.Storage) *UserHandler {
+	return &UserHandler{
+		storage: storage,
+	}
+}
+
+// CurrentUser Сведения о текущем пользователе.
+type CurrentUser struct {
+	ID    string      `json:"id"`
+	Login string      `json:"login"`
+	Role  models.Role `json:"role"`
+}
+
+// GetMe Возвращает ID, логин и роль текущего пользователя (например, чтобы фронтенд скрывал недоступные действия).
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	creds := models.GetContextCreds(r.Context())
+
+	response.JSON(w, http.StatusOK, CurrentUser{
+		ID:    creds.UserID,
+		Login: creds.Login,
+		Role:  creds.Role,
+	})
+}
+
+// GetUsers Возвращает список всех пользователей (для администраторов).
+func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.storage.ListUsers(r.Context())
+	if err != nil {
+		logger.Log.Warn("Ошибка при получении списка пользователей", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка при получении списка пользователей")
+		return
+	}
+
+	// если пользователей нет - возвращаем пустой срез
+	if len(users) == 0 {
+		users = []*models.User{}
+	}
+
+	response.JSON(w, http.StatusOK, users)
+}
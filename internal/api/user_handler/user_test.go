@@ -0,0 +1,95 @@
+package user_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// TestGetMe Проверяет получение сведений о текущем пользователе.
+func TestGetMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewUserHandler(storageMocks.NewMockStorage(ctrl))
+
+	ctx := context.WithValue(context.Background(), contextkeys.Login, "operator")
+	ctx = context.WithValue(ctx, contextkeys.UserID, "user-1")
+	ctx = context.WithValue(ctx, contextkeys.Role, models.RoleOperator)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/user/me", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	h.GetMe(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got CurrentUser
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	assert.Equal(t, CurrentUser{ID: "user-1", Login: "operator", Role: models.RoleOperator}, got)
+}
+
+// TestGetUsers Проверяет получение списка пользователей.
+func TestGetUsers(t *testing.T) {
+	tests := []struct {
+		name           string
+		users          []*models.User
+		storageErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "список пользователей",
+			users:          []*models.User{{ID: "user-1", Login: "admin"}, {ID: "user-2", Login: "viewer", Email: "viewer@example.com"}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":"user-1","login":"admin"},{"id":"user-2","login":"viewer","email":"viewer@example.com"}]`,
+		},
+		{
+			name:           "нет пользователей",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "ошибка хранилища",
+			storageErr:     errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().ListUsers(gomock.Any()).Return(tt.users, tt.storageErr)
+
+			h := NewUserHandler(mockStorage)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			w := httptest.NewRecorder()
+
+			h.GetUsers(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
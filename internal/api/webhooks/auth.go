@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// Способы проверки подлинности событий Keycloak.
+const (
+	// AuthModeHMAC Подпись тела запроса HMAC-SHA256 общим секретом в заголовке SignatureHeader.
+	AuthModeHMAC = "hmac"
+	// AuthModeSecret Общий секрет в заголовке SecretHeader.
+	AuthModeSecret = "secret"
+)
+
+const (
+	// SignatureHeader Заголовок с подписью тела запроса (hex, допускается префикс "sha256=").
+	SignatureHeader = "X-Keycloak-Signature"
+	// SecretHeader Заголовок с общим секретом.
+	SecretHeader = "X-Webhook-Secret"
+)
+
+// processedEventRetention Минимальный срок хранения идентификаторов обработанных событий.
+const processedEventRetention = 24 * time.Hour
+
+// maxBodySize Максимальный размер тела события.
+const maxBodySize = 1 << 20
+
+// WebhookConfig Настройки проверки событий Keycloak.
+type WebhookConfig struct {
+	Secret     string        // общий секрет, без него все события отклоняются
+	AuthMode   string        // AuthModeHMAC или AuthModeSecret
+	AllowedIPs []string      // адреса и подсети отправителей, пустой список - без ограничений
+	MaxAge     time.Duration // допустимое расхождение времени события с текущим, 0 - без проверки
+}
+
+// eventMeta Общие для UserEvent и AdminEvent поля, используемые для защиты от повторов.
+type eventMeta struct {
+	ID   string `json:"id"`
+	Time int64  `json:"time"` // миллисекунды Unix
+}
+
+// ipAllowed Проверка адреса отправителя по списку разрешенных подсетей.
+func (wh *Webhook) ipAllowed(addr string) bool {
+	if !wh.restrictIPs {
+		return true
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	return netutils.ContainsIP(wh.allowedNets, ip)
+}
+
+// authenticate Проверка подлинности запроса общим секретом или подписью тела.
+func (wh *Webhook) authenticate(r *http.Request, body []byte) bool {
+	if wh.secret == "" {
+		return false
+	}
+
+	if wh.authMode == AuthModeSecret {
+		return subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), []byte(wh.secret)) == 1
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(SignatureHeader), "sha256="))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+
+	return hmac.Equal(signature, Sign(wh.secret, body))
+}
+
+// Sign Подпись тела события HMAC-SHA256.
+func Sign(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// checkEventTime Проверка времени события: слишком старые события и события из будущего отклоняются.
+func (wh *Webhook) checkEventTime(eventTime int64, now time.Time) error {
+	if wh.maxAge <= 0 {
+		return nil
+	}
+
+	if eventTime <= 0 {
+		return fmt.Errorf("время события не указано")
+	}
+
+	diff := now.Sub(time.UnixMilli(eventTime))
+	if diff > wh.maxAge || diff < -wh.maxAge {
+		return fmt.Errorf("время события отличается от текущего на %s", diff.Round(time.Second))
+	}
+
+	return nil
+}
+
+// eventKey Идентификатор события для защиты от повторной обработки. Если отправитель
+// не передает идентификатор, используется хэш тела события.
+func eventKey(meta eventMeta, body []byte) string {
+	if meta.ID != "" {
+		return meta.ID
+	}
+
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// statusWriter Обертка над http.ResponseWriter для получения кода ответа обработчика события.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,262 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// deleteEventBody Тело AdminEvent удаления пользователя с заданным временем события.
+func deleteEventBody(eventTime time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"id": "event-1", "time": %d, "operationType": "DELETE", "resourceType": "USER", "resourcePath": "users/user-1"}`,
+		eventTime.UnixMilli()))
+}
+
+// Test_HandleEvent_Authentication Проверяет отклонение событий без верной подписи, секрета
+// или от неразрешенного адреса. Хранилище при этом не вызывается.
+func Test_HandleEvent_Authentication(t *testing.T) {
+	body := deleteEventBody(time.Now())
+
+	tests := []struct {
+		name       string
+		cfg        WebhookConfig
+		setHeaders func(r *http.Request)
+		wantCode   int
+	}{
+		{
+			name:       "нет подписи",
+			cfg:        WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC},
+			setHeaders: func(r *http.Request) {},
+			wantCode:   http.StatusUnauthorized,
+		},
+		{
+			name: "подпись другим секретом",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SignatureHeader, hex.EncodeToString(Sign("other-secret", body)))
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "подпись не в hex",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SignatureHeader, "not-hex")
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "неверный общий секрет",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeSecret},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SecretHeader, "wrong")
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "секрет не задан",
+			cfg:  WebhookConfig{AuthMode: AuthModeSecret},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SecretHeader, "")
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "адрес не в списке разрешенных",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC, AllowedIPs: []string{"10.0.0.0/8"}},
+			setHeaders: func(r *http.Request) {
+				r.RemoteAddr = "192.168.1.10:40000"
+				signRequest(r, body)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "поддельный заголовок с разрешенным адресом",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC, AllowedIPs: []string{"10.0.0.0/8"}},
+			setHeaders: func(r *http.Request) {
+				r.RemoteAddr = "192.168.1.10:40000"
+				r.Header.Set("X-Real-IP", "10.1.2.3")
+				r.Header.Set("X-Forwarded-For", "10.1.2.3")
+				signRequest(r, body)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// ни одно обращение к хранилищу не ожидается
+			mockStorage := mocks.NewMockStorage(ctrl)
+
+			wh := NewWebhook(mockStorage, tt.cfg, siem.NewNoopSink())
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+			tt.setHeaders(req)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+// Test_HandleEvent_AcceptedSources Проверяет прием событий с общим секретом, с префиксом подписи
+// и от адреса из списка разрешенных.
+func Test_HandleEvent_AcceptedSources(t *testing.T) {
+	body := deleteEventBody(time.Now())
+
+	tests := []struct {
+		name       string
+		cfg        WebhookConfig
+		setHeaders func(r *http.Request)
+	}{
+		{
+			name: "общий секрет",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeSecret},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SecretHeader, testWebhookSecret)
+			},
+		},
+		{
+			name: "подпись с префиксом sha256=",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC},
+			setHeaders: func(r *http.Request) {
+				r.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(Sign(testWebhookSecret, body)))
+			},
+		},
+		{
+			name: "адрес из разрешенной подсети",
+			cfg:  WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC, AllowedIPs: []string{"198.51.100.1", "10.0.0.0/8"}},
+			setHeaders: func(r *http.Request) {
+				r.RemoteAddr = "10.1.2.3:40000"
+				signRequest(r, body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().AddWebhookEvent(gomock.Any(), "event-1", gomock.Any()).Return(nil)
+			mockStorage.EXPECT().DeleteUser(gomock.Any(), "user-1").Return(nil)
+
+			wh := NewWebhook(mockStorage, tt.cfg, siem.NewNoopSink())
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+			tt.setHeaders(req)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+		})
+	}
+}
+
+// Test_HandleEvent_ReplayProtection Проверяет отклонение устаревших событий, событий из будущего
+// и повторно доставленных событий.
+func Test_HandleEvent_ReplayProtection(t *testing.T) {
+	cfg := WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC, MaxAge: 5 * time.Minute}
+
+	tests := []struct {
+		name         string
+		body         []byte
+		setupStorage func(m *mocks.MockStorage)
+		wantCode     int
+	}{
+		{
+			name:         "событие устарело",
+			body:         deleteEventBody(time.Now().Add(-10 * time.Minute)),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusBadRequest,
+		},
+		{
+			name:         "событие из будущего",
+			body:         deleteEventBody(time.Now().Add(10 * time.Minute)),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusBadRequest,
+		},
+		{
+			name:         "время события не указано",
+			body:         []byte(`{"id": "event-1", "operationType": "DELETE", "resourceType": "USER", "resourcePath": "users/user-1"}`),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusBadRequest,
+		},
+		{
+			name: "событие уже обработано",
+			body: deleteEventBody(time.Now()),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().AddWebhookEvent(gomock.Any(), "event-1", gomock.Any()).
+					Return(errs.NewErrWebhookEventDuplicate("event-1", errors.New("duplicate")))
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "ошибка сохранения события",
+			body: deleteEventBody(time.Now()),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().AddWebhookEvent(gomock.Any(), "event-1", gomock.Any()).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "ошибка обработки снимает отметку события",
+			body: deleteEventBody(time.Now()),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().AddWebhookEvent(gomock.Any(), "event-1", gomock.Any()).Return(nil)
+				m.EXPECT().DeleteUser(gomock.Any(), "user-1").Return(errors.New("db error"))
+				m.EXPECT().DelWebhookEvent(gomock.Any(), "event-1").Return(nil)
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			wh := NewWebhook(mockStorage, cfg, siem.NewNoopSink())
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(tt.body))
+			signRequest(req, tt.body)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+// Test_eventKey Проверяет выбор идентификатора события.
+func Test_eventKey(t *testing.T) {
+	assert.Equal(t, "event-1", eventKey(eventMeta{ID: "event-1"}, []byte(`{}`)))
+
+	key := eventKey(eventMeta{}, []byte(`{"time": 1}`))
+	assert.Contains(t, key, "sha256:")
+	assert.Equal(t, key, eventKey(eventMeta{}, []byte(`{"time": 1}`)))
+	assert.NotEqual(t, key, eventKey(eventMeta{}, []byte(`{"time": 2}`)))
+}
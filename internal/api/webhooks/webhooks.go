@@ -5,30 +5,75 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
 	models2 "github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
 
 type Webhook struct {
-	storage storage.Storage
+	storage     storage.Storage
+	sink        siem.Sink
+	secret      string
+	authMode    string
+	restrictIPs bool
+	allowedNets []*net.IPNet
+	maxAge      time.Duration
 }
 
-func NewWebhook(storage storage.Storage) *Webhook {
-	return &Webhook{storage: storage}
+// NewWebhook Конструктор Webhook. Неверные адреса в списке разрешенных пропускаются
+// (список проверяется при запуске через netutils.ParseIPNets).
+func NewWebhook(storage storage.Storage, cfg WebhookConfig, sink siem.Sink) *Webhook {
+	wh := &Webhook{
+		storage:     storage,
+		sink:        sink,
+		secret:      cfg.Secret,
+		authMode:    cfg.AuthMode,
+		restrictIPs: len(cfg.AllowedIPs) > 0,
+		maxAge:      cfg.MaxAge,
+	}
+
+	for _, item := range cfg.AllowedIPs {
+		ipNets, err := netutils.ParseIPNets([]string{item})
+		if err != nil {
+			logger.Log.Error("Адрес пропущен в списке разрешенных отправителей вебхуков", logger.String("err", err.Error()))
+			continue
+		}
+		wh.allowedNets = append(wh.allowedNets, ipNets...)
+	}
+
+	return wh
 }
 
 // HandleEvent Автоопределение и обработка событий из Keycloak.
+// Принимаются только события от разрешенных адресов с верной подписью (или секретом),
+// временем события в допустимом окне и еще не обработанные ранее.
 func (wh *Webhook) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	// Читаем тело для логирования и парсинга
-	body, err := io.ReadAll(r.Body)
+	// адрес соединения; заголовки X-Real-IP и X-Forwarded-For учитываются только от доверенных прокси
+	// (TrustedProxyMiddleware), иначе отправитель мог бы выдать себя за Keycloak
+	clientIP := middleware.ClientIP(r)
+
+	if !wh.ipAllowed(clientIP) {
+		logger.Log.Warn("Вебхук от неразрешенного адреса", logger.String("ip", clientIP))
+		wh.sink.Export(siem.NewAuthFailureEvent(clientIP, r.URL.Path, "webhook source not allowed"))
+		response.ErrorJSON(w, http.StatusForbidden, "Доступ запрещён")
+		return
+	}
+
+	// Читаем тело для проверки подписи, логирования и парсинга
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
 
 	if err != nil {
 		logger.Log.Warn("Не удалось прочитать тело вебхука", logger.String("err", err.Error()))
@@ -36,6 +81,13 @@ func (wh *Webhook) HandleEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !wh.authenticate(r, body) {
+		logger.Log.Warn("Неверная подпись вебхука", logger.String("ip", clientIP))
+		wh.sink.Export(siem.NewAuthFailureEvent(clientIP, r.URL.Path, "invalid webhook signature"))
+		response.ErrorJSON(w, http.StatusUnauthorized, "Неверная подпись запроса")
+		return
+	}
+
 	// пробуем определить тип события по наличию поля "type"
 	var raw map[string]json.RawMessage
 	if err := json.Unmarshal(body, &raw); err != nil {
@@ -49,16 +101,65 @@ func (wh *Webhook) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	_, isUserEvent := raw["type"]
 	_, isAdminEvent := raw["operationType"]
 
-	switch {
-	case isUserEvent:
-		wh.handleUserEvent(w, r, body)
-	case isAdminEvent:
-		wh.handleAdminEvent(w, r, body)
-	default:
+	if !isUserEvent && !isAdminEvent {
 		logger.Log.Debug("Неизвестный тип события (нет type или operationType)")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
+
+	var meta eventMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		logger.Log.Warn("Неверный формат идентификатора или времени события", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	// защита от повторной отправки перехваченного события
+	now := time.Now()
+	if err := wh.checkEventTime(meta.Time, now); err != nil {
+		logger.Log.Warn("Событие отклонено по времени",
+			logger.String("ip", clientIP),
+			logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusBadRequest, "Событие устарело")
+		return
+	}
+
+	// идентификатор хранится не меньше допустимого возраста события: более старые повторы отклоняются по времени
+	retention := processedEventRetention
+	if wh.maxAge > retention {
+		retention = wh.maxAge
+	}
+
+	key := eventKey(meta, body)
+	if err := wh.storage.AddWebhookEvent(r.Context(), key, now.Add(retention)); err != nil {
+		var errDuplicate *errs.ErrWebhookEventDuplicate
+		if errors.As(err, &errDuplicate) {
+			logger.Log.Debug("Событие уже обработано", logger.String("eventID", key))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		logger.Log.Error("Ошибка сохранения события вебхука", logger.String("err", err.Error()))
+		response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка обработки события")
+		return
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+	if isUserEvent {
+		wh.handleUserEvent(sw, r, body)
+	} else {
+		wh.handleAdminEvent(sw, r, body)
+	}
+
+	// при ошибке обработки снимаем отметку, чтобы Keycloak мог доставить событие повторно
+	if sw.status >= http.StatusInternalServerError {
+		if err := wh.storage.DelWebhookEvent(context.WithoutCancel(r.Context()), key); err != nil {
+			logger.Log.Error("Не удалось снять отметку об обработке события",
+				logger.String("eventID", key),
+				logger.String("err", err.Error()))
+		}
+	}
 }
 
 // Обработчик событий UserEvent.
@@ -87,7 +188,7 @@ func (wh *Webhook) handleUserEvent(w http.ResponseWriter, r *http.Request, body
 		}
 
 		// создание пользователя в БД
-		if err := wh.storage.CreateUser(r.Context(), &models.User{ID: ID, Login: login}); err != nil {
+		if err := wh.storage.CreateUser(r.Context(), &models.User{ID: ID, Login: login, Email: event.Details["email"]}); err != nil {
 			var userExistsErr *errs.ErrUserAlreadyExists
 
 			if errors.As(err, &userExistsErr) {
@@ -108,6 +209,25 @@ func (wh *Webhook) handleUserEvent(w http.ResponseWriter, r *http.Request, body
 			logger.String("username", login),
 			logger.String("ip", event.IPAddress))
 
+	// пользователь удалил свою учетную запись в Keycloak
+	case "DELETE_ACCOUNT":
+		if err := wh.handleUserDelete(r.Context(), event.UserID); err != nil {
+			var errNotFound *errs.ErrUserIDNotFound
+			if errors.As(err, &errNotFound) {
+				logger.Log.Debug("Пользователь уже удалён", logger.String("userID", event.UserID))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			logger.Log.Error("Ошибка удаления пользователя",
+				logger.String("userID", event.UserID),
+				logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка удаления пользователя")
+			return
+		}
+
+		logger.Log.Info("Пользователь удалил учётную запись", logger.String("userID", event.UserID))
+
 	// вход в swsm
 	case "LOGIN":
 		logger.Log.Debug("Пользователь вошёл",
@@ -199,7 +319,7 @@ func (wh *Webhook) handleAdminEvent(w http.ResponseWriter, r *http.Request, body
 		}
 
 		// создание пользователя в БД
-		if err := wh.storage.CreateUser(r.Context(), &models.User{ID: userID, Login: userRep.Username}); err != nil {
+		if err := wh.storage.CreateUser(r.Context(), &models.User{ID: userID, Login: userRep.Username, Email: userRep.Email}); err != nil {
 			var userExistsErr *errs.ErrUserAlreadyExists
 
 			if errors.As(err, &userExistsErr) {
@@ -218,10 +338,66 @@ func (wh *Webhook) handleAdminEvent(w http.ResponseWriter, r *http.Request, body
 		logger.Log.Info("Пользователь создан (admin event)",
 			logger.String("userID", userID))
 
-	// обновление данных пользователя
+	// обновление данных пользователя: смена логина или email, отключение и включение
 	case event.OperationType == "UPDATE" && event.ResourceType == "USER":
-		logger.Log.Debug("Данные пользователя обновлены",
-			logger.String("userID", userID))
+		if event.Representation == "" {
+			// без "Include representation" изменения придут только при сверке с Keycloak
+			logger.Log.Warn("Admin Event UPDATE без representation", logger.String("userID", userID))
+			break
+		}
+
+		var userRep models2.UserRepresentation
+		if err := json.Unmarshal([]byte(event.Representation), &userRep); err != nil {
+			logger.Log.Warn("Не удалось распарсить User representation", logger.String("err", err.Error()))
+			response.ErrorJSON(w, http.StatusBadRequest, "Ошибка чтения запроса")
+			return
+		}
+
+		user := &models.User{ID: userID, Login: userRep.Username, Email: userRep.Email, Disabled: userRep.Disabled()}
+		if err := wh.storage.UpdateUser(r.Context(), user); err != nil {
+			var errNotFound *errs.ErrUserIDNotFound
+			var userExistsErr *errs.ErrUserAlreadyExists
+
+			switch {
+			case errors.As(err, &errNotFound):
+				// пользователь не заходил в SWSM или событие REGISTER было пропущено
+				logger.Log.Debug("Обновлён пользователь, отсутствующий в БД", logger.String("userID", userID))
+			case errors.As(err, &userExistsErr):
+				// повтор события не поможет, расхождение исправит сверка после освобождения логина
+				logger.Log.Warn("Логин пользователя занят другим пользователем",
+					logger.String("userID", userID),
+					logger.String("login", userRep.Username))
+			default:
+				logger.Log.Error("Ошибка обновления пользователя",
+					logger.String("userID", userID),
+					logger.String("err", err.Error()))
+				response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка обновления пользователя")
+				return
+			}
+			break
+		}
+
+		logger.Log.Info("Данные пользователя обновлены (admin event)",
+			logger.String("userID", userID),
+			logger.String("login", userRep.Username),
+			logger.String("disabled", strconv.FormatBool(user.Disabled)))
+
+	// изменение ролей пользователя: сохраненная роль сбрасывается до следующего входа,
+	// а его API-токены до этого работают с ролью по умолчанию
+	case event.IsUserRoleChange():
+		if err := wh.storage.SetUserRole(r.Context(), userID, ""); err != nil {
+			var errNotFound *errs.ErrUserIDNotFound
+			if !errors.As(err, &errNotFound) {
+				logger.Log.Error("Ошибка сброса роли пользователя",
+					logger.String("userID", userID),
+					logger.String("err", err.Error()))
+				response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка обновления пользователя")
+				return
+			}
+			break
+		}
+
+		logger.Log.Info("Роль пользователя сброшена (admin event)", logger.String("userID", userID))
 
 	// Неизвестные admin events
 	default:
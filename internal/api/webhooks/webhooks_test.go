@@ -2,9 +2,11 @@ package webhooks
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
@@ -19,6 +22,22 @@ func init() {
 	logger.InitLogger("error", "stdout")
 }
 
+const testWebhookSecret = "test-secret"
+
+// newTestWebhook Webhook с проверкой подписи по testWebhookSecret, без проверки времени события
+// и с хранилищем, принимающим любое событие как новое.
+func newTestWebhook(mockStorage *mocks.MockStorage) *Webhook {
+	mockStorage.EXPECT().AddWebhookEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStorage.EXPECT().DelWebhookEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return NewWebhook(mockStorage, WebhookConfig{Secret: testWebhookSecret, AuthMode: AuthModeHMAC}, siem.NewNoopSink())
+}
+
+// signRequest Подпись тела запроса секретом testWebhookSecret.
+func signRequest(req *http.Request, body []byte) {
+	req.Header.Set(SignatureHeader, hex.EncodeToString(Sign(testWebhookSecret, body)))
+}
+
 // Test_HandleEvent_UserEvent_RegisterSuccess Проверяет успешную обработку UserEvent REGISTER.
 func Test_HandleEvent_UserEvent_RegisterSuccess(t *testing.T) {
 	// Подготавливаем тело запроса: Keycloak user event REGISTER
@@ -48,10 +67,11 @@ func Test_HandleEvent_UserEvent_RegisterSuccess(t *testing.T) {
 		Return(nil)
 
 	// Создаём экземпляр Webhook с замоканным storage
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	// Собираем HTTP‑запрос
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	// ResponseRecorder - "фейковый" http.ResponseWriter для тестов
 	rr := httptest.NewRecorder()
 
@@ -64,6 +84,44 @@ func Test_HandleEvent_UserEvent_RegisterSuccess(t *testing.T) {
 	}
 }
 
+// Test_HandleEvent_UserEvent_RegisterWithEmail Проверяет сохранение email пользователя при REGISTER.
+func Test_HandleEvent_UserEvent_RegisterWithEmail(t *testing.T) {
+	body := []byte(`{
+        "type": "REGISTER",
+        "userId": "any-id-user-1",
+        "details": {
+            "username": "testuser",
+            "email": "testuser@example.com"
+        }
+    }`)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	mockStorage.
+		EXPECT().
+		CreateUser(gomock.Any(), &models.User{
+			ID:    "any-id-user-1",
+			Login: "testuser",
+			Email: "testuser@example.com",
+		}).
+		Return(nil)
+
+	wh := newTestWebhook(mockStorage)
+
+	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
+	rr := httptest.NewRecorder()
+
+	wh.HandleEvent(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", http.StatusNoContent, rr.Code, rr.Body.String())
+	}
+}
+
 // Test_HandleEvent_UserEvent_Register_NoUsername Проверяет случай, когда в details нет username.
 func Test_HandleEvent_UserEvent_Register_NoUsername(t *testing.T) {
 	body := []byte(`{
@@ -85,9 +143,10 @@ func Test_HandleEvent_UserEvent_Register_NoUsername(t *testing.T) {
 		CreateUser(gomock.Any(), gomock.Any()).
 		Times(0)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -128,9 +187,10 @@ func Test_HandleEvent_UserEvent_Register_UserAlreadyExists(t *testing.T) {
 		}).
 		Return(userExistsErr)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -162,9 +222,10 @@ func Test_HandleEvent_UserEvent_Register_InternalError(t *testing.T) {
 		CreateUser(gomock.Any(), gomock.Any()).
 		Return(errors.New("db failure"))
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -195,9 +256,10 @@ func Test_HandleEvent_AdminEvent_Delete_Success(t *testing.T) {
 		DeleteUser(gomock.Any(), "any-id-user-1").
 		Return(nil)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -231,9 +293,10 @@ func Test_HandleEvent_AdminEvent_Delete_UserNotFound(t *testing.T) {
 		DeleteUser(gomock.Any(), "any-id-user-1").
 		Return(notFoundErr)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -262,9 +325,10 @@ func Test_HandleEvent_AdminEvent_Delete_InternalError(t *testing.T) {
 		DeleteUser(gomock.Any(), "any-id-user-1").
 		Return(errors.New("db failure"))
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -298,9 +362,46 @@ func Test_HandleEvent_AdminEvent_Create_Success(t *testing.T) {
 		}).
 		Return(nil)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
+
+	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
+	rr := httptest.NewRecorder()
+
+	wh.HandleEvent(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", http.StatusNoContent, rr.Code, rr.Body.String())
+	}
+}
+
+// Test_HandleEvent_AdminEvent_CreateWithEmail Проверяет сохранение email пользователя при CREATE USER.
+func Test_HandleEvent_AdminEvent_CreateWithEmail(t *testing.T) {
+	body := []byte(`{
+        "operationType": "CREATE",
+        "resourceType": "USER",
+        "resourcePath": "users/any-id-user-1",
+        "representation": "{\"id\":\"any-id-user-1\",\"username\":\"testuser\",\"email\":\"testuser@example.com\"}"
+    }`)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	mockStorage.
+		EXPECT().
+		CreateUser(gomock.Any(), &models.User{
+			ID:    "any-id-user-1",
+			Login: "testuser",
+			Email: "testuser@example.com",
+		}).
+		Return(nil)
+
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -337,9 +438,10 @@ func Test_HandleEvent_AdminEvent_Create_UserAlreadyExists(t *testing.T) {
 		}).
 		Return(userExistsErr)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -369,9 +471,10 @@ func Test_HandleEvent_AdminEvent_Create_InternalError(t *testing.T) {
 		CreateUser(gomock.Any(), gomock.Any()).
 		Return(errors.New("db failure"))
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -393,9 +496,10 @@ func Test_HandleEvent_InvalidJSON(t *testing.T) {
 	mockStorage.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
 	mockStorage.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Times(0)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -416,9 +520,10 @@ func Test_HandleEvent_UnknownEventType(t *testing.T) {
 	mockStorage.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
 	mockStorage.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Times(0)
 
-	wh := NewWebhook(mockStorage)
+	wh := newTestWebhook(mockStorage)
 
 	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+	signRequest(req, body)
 	rr := httptest.NewRecorder()
 
 	wh.HandleEvent(rr, req)
@@ -428,3 +533,205 @@ func Test_HandleEvent_UnknownEventType(t *testing.T) {
 		t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", http.StatusNoContent, rr.Code, rr.Body.String())
 	}
 }
+
+// Test_HandleEvent_AdminEvent_Update Проверяет синхронизацию логина, email и отключения пользователя по UPDATE USER.
+func Test_HandleEvent_AdminEvent_Update(t *testing.T) {
+	updateBody := func(representation string) []byte {
+		return []byte(`{
+        "operationType": "UPDATE",
+        "resourceType": "USER",
+        "resourcePath": "users/any-id-user-1",
+        "representation": ` + strconv.Quote(representation) + `
+    }`)
+	}
+
+	tests := []struct {
+		name         string
+		body         []byte
+		setupStorage func(m *mocks.MockStorage)
+		wantCode     int
+	}{
+		{
+			name: "смена логина и email",
+			body: updateBody(`{"id":"any-id-user-1","username":"renamed","email":"renamed@example.com","enabled":true}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), &models.User{ID: "any-id-user-1", Login: "renamed", Email: "renamed@example.com"}).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "отключение пользователя",
+			body: updateBody(`{"id":"any-id-user-1","username":"testuser","enabled":false}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), &models.User{ID: "any-id-user-1", Login: "testuser", Disabled: true}).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "пользователь отсутствует в БД",
+			body: updateBody(`{"id":"any-id-user-1","username":"testuser"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(errs.NewErrUserIDNotFound("any-id-user-1"))
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "логин занят другим пользователем",
+			body: updateBody(`{"id":"any-id-user-1","username":"taken"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(errs.NewErrUserAlreadyExists("taken", errors.New("duplicate")))
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "ошибка БД",
+			body: updateBody(`{"id":"any-id-user-1","username":"testuser"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "без representation",
+			body:         []byte(`{"operationType": "UPDATE", "resourceType": "USER", "resourcePath": "users/any-id-user-1"}`),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusNoContent,
+		},
+		{
+			name:         "неверный representation",
+			body:         updateBody(`{invalid`),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			wh := newTestWebhook(mockStorage)
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(tt.body))
+			signRequest(req, tt.body)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+// Test_HandleEvent_AdminEvent_RoleMapping Проверяет сброс сохраненной роли при изменении ролей пользователя.
+func Test_HandleEvent_AdminEvent_RoleMapping(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         []byte
+		setupStorage func(m *mocks.MockStorage)
+		wantCode     int
+	}{
+		{
+			name: "назначение роли realm",
+			body: []byte(`{"operationType": "CREATE", "resourceType": "REALM_ROLE_MAPPING", "resourcePath": "users/any-id-user-1/role-mappings/realm"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().SetUserRole(gomock.Any(), "any-id-user-1", models.Role("")).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "исключение из группы",
+			body: []byte(`{"operationType": "DELETE", "resourceType": "GROUP_MEMBERSHIP", "resourcePath": "users/any-id-user-1/groups/group-1"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().SetUserRole(gomock.Any(), "any-id-user-1", models.Role("")).Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:         "роли группы не относятся к пользователю",
+			body:         []byte(`{"operationType": "CREATE", "resourceType": "REALM_ROLE_MAPPING", "resourcePath": "groups/group-1/role-mappings/realm"}`),
+			setupStorage: func(m *mocks.MockStorage) {},
+			wantCode:     http.StatusNoContent,
+		},
+		{
+			name: "пользователь отсутствует в БД",
+			body: []byte(`{"operationType": "DELETE", "resourceType": "CLIENT_ROLE_MAPPING", "resourcePath": "users/any-id-user-1/role-mappings/clients/client-1"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().SetUserRole(gomock.Any(), "any-id-user-1", models.Role("")).Return(errs.NewErrUserIDNotFound("any-id-user-1"))
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "ошибка БД",
+			body: []byte(`{"operationType": "CREATE", "resourceType": "REALM_ROLE_MAPPING", "resourcePath": "users/any-id-user-1/role-mappings/realm"}`),
+			setupStorage: func(m *mocks.MockStorage) {
+				m.EXPECT().SetUserRole(gomock.Any(), "any-id-user-1", models.Role("")).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupStorage(mockStorage)
+
+			wh := newTestWebhook(mockStorage)
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(tt.body))
+			signRequest(req, tt.body)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+// Test_HandleEvent_UserEvent_DeleteAccount Проверяет удаление пользователя по событию DELETE_ACCOUNT.
+func Test_HandleEvent_UserEvent_DeleteAccount(t *testing.T) {
+	body := []byte(`{"type": "DELETE_ACCOUNT", "userId": "any-id-user-1"}`)
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantCode  int
+	}{
+		{name: "пользователь удалён", deleteErr: nil, wantCode: http.StatusNoContent},
+		{name: "пользователь уже удалён", deleteErr: errs.NewErrUserIDNotFound("any-id-user-1"), wantCode: http.StatusNoContent},
+		{name: "ошибка БД", deleteErr: errors.New("db error"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().DeleteUser(gomock.Any(), "any-id-user-1").Return(tt.deleteErr)
+
+			wh := newTestWebhook(mockStorage)
+
+			req := httptest.NewRequest(http.MethodPost, "/keycloak-events", bytes.NewReader(body))
+			signRequest(req, body)
+			rr := httptest.NewRecorder()
+
+			wh.HandleEvent(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("ожидался статус %d, получен %d, тело ответа: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
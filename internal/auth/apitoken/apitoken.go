@@ -0,0 +1,50 @@
+// Package apitoken содержит выпуск и хэширование персональных API-токенов пользователей.
+// Токен — случайная строка с префиксом Prefix, по которому он отличается от JWT Keycloak.
+// В БД хранится только SHA-256 хэш токена: токен содержит 256 бит случайных данных,
+// поэтому медленное хэширование (как для паролей) не требуется.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Prefix Префикс всех API-токенов.
+const Prefix = "swsm_"
+
+// displayLen Количество символов токена (вместе с префиксом), которые показываются в списке токенов.
+const displayLen = len(Prefix) + 6
+
+// Generate Выпускает новый API-токен.
+func Generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать API-токен: %w", err)
+	}
+
+	return Prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash Возвращает хэш токена для хранения и поиска в БД.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken Проверяет, что строка является API-токеном, а не JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// DisplayPrefix Возвращает начало токена, по которому пользователь узнает его в списке.
+func DisplayPrefix(token string) string {
+	if len(token) <= displayLen {
+		return token
+	}
+
+	return token[:displayLen]
+}
@@ -0,0 +1,44 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate Проверяет формат и уникальность выпускаемых токенов.
+func TestGenerate(t *testing.T) {
+	first, err := Generate()
+	require.NoError(t, err)
+
+	second, err := Generate()
+	require.NoError(t, err)
+
+	assert.True(t, IsAPIToken(first))
+	assert.Len(t, first, len(Prefix)+43)
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, Hash(first), Hash(second))
+}
+
+// TestHash Проверяет, что хэш детерминирован и не содержит сам токен.
+func TestHash(t *testing.T) {
+	token := "swsm_abcdef"
+
+	assert.Equal(t, Hash(token), Hash(token))
+	assert.Len(t, Hash(token), 64)
+	assert.NotContains(t, Hash(token), "abcdef")
+}
+
+// TestIsAPIToken Проверяет отличие API-токена от JWT.
+func TestIsAPIToken(t *testing.T) {
+	assert.True(t, IsAPIToken("swsm_abc"))
+	assert.False(t, IsAPIToken("eyJhbGciOiJSUzI1NiJ9.payload.sig"))
+	assert.False(t, IsAPIToken(""))
+}
+
+// TestDisplayPrefix Проверяет начало токена, показываемое в списке.
+func TestDisplayPrefix(t *testing.T) {
+	assert.Equal(t, "swsm_abcdef", DisplayPrefix("swsm_abcdefghijk"))
+	assert.Equal(t, "swsm_ab", DisplayPrefix("swsm_ab"))
+}
@@ -0,0 +1,172 @@
+package generic_oidc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// Клеймы по умолчанию.
+const (
+	DefaultIDClaim    = "sub"
+	DefaultLoginClaim = "preferred_username"
+	DefaultEmailClaim = "email"
+)
+
+// OIDCAdapter Реализует auth.AuthProvider для любого OIDC-провайдера (Authentik, Dex, Zitadel и т.д.).
+type OIDCAdapter struct {
+	verifier *oidc.IDTokenVerifier
+	mapping  ClaimMapping
+}
+
+// ClaimMapping Имена клеймов, из которых берутся данные пользователя.
+// Вложенные клеймы указываются через точку, например: realm_access.roles.
+type ClaimMapping struct {
+	ID    string // неизменяемый идентификатор пользователя (users.id)
+	Login string // логин для отображения
+	Email string // необязательный email для уведомлений
+	Roles string // необязательный список ролей приложения (viewer, operator, admin)
+}
+
+// OIDCConfig Конфигурация для создания адаптера.
+type OIDCConfig struct {
+	IssuerURL       string
+	Audience        string // ожидаемое значение клейма aud (обычно client_id приложения)
+	SkipIssuerCheck bool
+	Claims          ClaimMapping
+}
+
+// NewOIDCAdapter Конструктор адаптера OIDCAdapter. Незаданные клеймы ID, Login и Email заменяются значениями по умолчанию.
+func NewOIDCAdapter(ctx context.Context, config OIDCConfig) (*OIDCAdapter, error) {
+	if config.Audience == "" {
+		return nil, fmt.Errorf("не указана аудитория (aud) токенов OIDC")
+	}
+
+	providerCtx := ctx
+	if config.SkipIssuerCheck {
+		// при локальной разработке в контейнерах, когда в конфиге SkipIssuerCheck=true
+		providerCtx = oidc.InsecureIssuerURLContext(ctx, config.IssuerURL)
+	}
+
+	// создаём провайдер (загружает JWKS, openid-конфиг)
+	provider, err := oidc.NewProvider(providerCtx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания провайдера oidc: %w", err)
+	}
+
+	return &OIDCAdapter{
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.Audience}),
+		mapping:  config.Claims.withDefaults(),
+	}, nil
+}
+
+// ValidateToken Реализует интерфейс auth.AuthProvider.
+func (a *OIDCAdapter) ValidateToken(ctx context.Context, rawToken string) (*models.UserClaims, error) {
+
+	// верификация токена (подпись, exp, iss, aud)
+	idToken, err := a.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка верификации токена: %w", err)
+	}
+
+	var claims map[string]any
+
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга claims: %w", err)
+	}
+
+	userClaims, err := parseUserClaims(claims, a.mapping)
+	if err != nil {
+		return nil, err
+	}
+	userClaims.ExpiresAt = idToken.Expiry
+
+	return userClaims, nil
+}
+
+// withDefaults Возвращает копию с клеймами по умолчанию вместо незаданных.
+func (m ClaimMapping) withDefaults() ClaimMapping {
+	if m.ID == "" {
+		m.ID = DefaultIDClaim
+	}
+	if m.Login == "" {
+		m.Login = DefaultLoginClaim
+	}
+	if m.Email == "" {
+		m.Email = DefaultEmailClaim
+	}
+
+	return m
+}
+
+// Вспомогательная функция. Извлекает из claims данные пользователя согласно mapping.
+func parseUserClaims(claims map[string]any, mapping ClaimMapping) (*models.UserClaims, error) {
+	id, _ := lookupClaim(claims, mapping.ID).(string)
+	if id == "" {
+		return nil, fmt.Errorf("отсутствует обязательный клейм '%s'", mapping.ID)
+	}
+
+	login, _ := lookupClaim(claims, mapping.Login).(string)
+	if login == "" {
+		return nil, fmt.Errorf("отсутствует обязательный клейм '%s'", mapping.Login)
+	}
+
+	email, _ := lookupClaim(claims, mapping.Email).(string)
+
+	var roles []appmodels.Role
+	if mapping.Roles != "" {
+		roles = parseRoles(lookupClaim(claims, mapping.Roles))
+	}
+
+	return &models.UserClaims{ID: id, Login: login, Email: email, Roles: roles}, nil
+}
+
+// Вспомогательная функция. Возвращает значение клейма по пути через точку или nil, если его нет.
+func lookupClaim(claims map[string]any, path string) any {
+	var value any = claims
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		if value, ok = object[key]; !ok {
+			return nil
+		}
+	}
+
+	return value
+}
+
+// Вспомогательная функция. Возвращает известные приложению роли из клейма-массива
+// или строки с ролями, разделенными пробелами (как в клейме scope).
+func parseRoles(value any) []appmodels.Role {
+	var names []string
+
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	case string:
+		names = strings.Fields(v)
+	}
+
+	var roles []appmodels.Role
+	for _, name := range names {
+		role := appmodels.Role(name)
+		if appmodels.IsValidRole(role) && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
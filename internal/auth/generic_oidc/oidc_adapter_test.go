@@ -0,0 +1,135 @@
+package generic_oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestNewOIDCAdapter Тесты конструктора.
+func TestNewOIDCAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ошибка без аудитории", func(t *testing.T) {
+		adapter, err := NewOIDCAdapter(ctx, OIDCConfig{IssuerURL: "http://localhost:9999"})
+
+		assert.Error(t, err)
+		assert.Nil(t, adapter)
+	})
+
+	t.Run("ошибка при недоступном провайдере", func(t *testing.T) {
+		adapter, err := NewOIDCAdapter(ctx, OIDCConfig{IssuerURL: "http://localhost:9999/nonexistent", Audience: "swsm"})
+
+		assert.Error(t, err)
+		assert.Nil(t, adapter)
+	})
+
+	t.Run("успешное создание с клеймами по умолчанию", func(t *testing.T) {
+		var oidcServer *httptest.Server
+
+		// минимальный мок-сервер с OIDC-конфигом
+		oidcServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/.well-known/openid-configuration" {
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"issuer":   oidcServer.URL,
+					"jwks_uri": oidcServer.URL + "/jwks",
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string][]interface{}{"keys": {}})
+		}))
+		defer oidcServer.Close()
+
+		adapter, err := NewOIDCAdapter(ctx, OIDCConfig{
+			IssuerURL: oidcServer.URL,
+			Audience:  "swsm",
+			Claims:    ClaimMapping{Login: "email"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, ClaimMapping{ID: "sub", Login: "email", Email: "email"}, adapter.mapping)
+
+		claims, err := adapter.ValidateToken(ctx, "not.a.valid.jwt")
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+}
+
+// TestParseUserClaims Тесты извлечения данных пользователя согласно сопоставлению клеймов.
+func TestParseUserClaims(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		mapping   ClaimMapping
+		wantID    string
+		wantLogin string
+		wantEmail string
+		wantRoles []appmodels.Role
+		wantErr   bool
+	}{
+		{
+			name:      "клеймы по умолчанию",
+			raw:       `{"sub": "u-1", "preferred_username": "alice", "email": "alice@example.com"}`,
+			mapping:   ClaimMapping{}.withDefaults(),
+			wantID:    "u-1",
+			wantLogin: "alice",
+			wantEmail: "alice@example.com",
+		},
+		{
+			name:      "роли из массива групп (Authentik, Dex)",
+			raw:       `{"sub": "u-1", "nickname": "alice", "groups": ["admins", "operator", "viewer", "operator"]}`,
+			mapping:   ClaimMapping{Login: "nickname", Roles: "groups"}.withDefaults(),
+			wantID:    "u-1",
+			wantLogin: "alice",
+			wantRoles: []appmodels.Role{appmodels.RoleOperator, appmodels.RoleViewer},
+		},
+		{
+			name:      "вложенные клеймы и роли строкой",
+			raw:       `{"sub": "u-1", "user": {"id": "ext-7", "name": "bob"}, "app": {"roles": "admin offline"}}`,
+			mapping:   ClaimMapping{ID: "user.id", Login: "user.name", Roles: "app.roles"}.withDefaults(),
+			wantID:    "ext-7",
+			wantLogin: "bob",
+			wantRoles: []appmodels.Role{appmodels.RoleAdmin},
+		},
+		{
+			name:    "нет клейма логина",
+			raw:     `{"sub": "u-1", "email": "alice@example.com"}`,
+			mapping: ClaimMapping{}.withDefaults(),
+			wantErr: true,
+		},
+		{
+			name:    "идентификатор не строка",
+			raw:     `{"sub": 42, "preferred_username": "alice"}`,
+			mapping: ClaimMapping{}.withDefaults(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.raw), &claims))
+
+			got, err := parseUserClaims(claims, tt.mapping)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, got.ID)
+			assert.Equal(t, tt.wantLogin, got.Login)
+			assert.Equal(t, tt.wantEmail, got.Email)
+			assert.Equal(t, tt.wantRoles, got.Roles)
+		})
+	}
+}
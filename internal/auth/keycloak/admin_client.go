@@ -0,0 +1,147 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// adminPageSize Количество пользователей, запрашиваемых у Keycloak за один запрос.
+const adminPageSize = 100
+
+// AdminClient Клиент REST API администрирования Keycloak для чтения пользователей realm.
+// Токен получается по client credentials: у сервисной учетной записи клиента должна быть
+// роль view-users клиента realm-management.
+type AdminClient struct {
+	baseURL      string
+	realm        string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// AdminConfig Конфигурация клиента REST API администрирования Keycloak.
+type AdminConfig struct {
+	BaseURL      string // http(s)://<host>:<port>, без realm
+	Realm        string
+	ClientID     string
+	ClientSecret string
+}
+
+// NewAdminClient Конструктор AdminClient.
+func NewAdminClient(config AdminConfig) *AdminClient {
+	return &AdminClient{
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		realm:        config.Realm,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListUsers Возвращает всех пользователей realm. Пользователь, отключенный в Keycloak, отмечается Disabled.
+func (c *AdminClient) ListUsers(ctx context.Context) ([]*appmodels.User, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*appmodels.User
+
+	for first := 0; ; first += adminPageSize {
+		page, err := c.listUsersPage(ctx, token, first)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rep := range page {
+			users = append(users, &appmodels.User{
+				ID:       rep.ID,
+				Login:    rep.Username,
+				Email:    rep.Email,
+				Disabled: rep.Disabled(),
+			})
+		}
+
+		if len(page) < adminPageSize {
+			return users, nil
+		}
+	}
+}
+
+// Получает одну страницу пользователей realm.
+func (c *AdminClient) listUsersPage(ctx context.Context, token string, first int) ([]models.UserRepresentation, error) {
+	query := url.Values{
+		"first":               {strconv.Itoa(first)},
+		"max":                 {strconv.Itoa(adminPageSize)},
+		"briefRepresentation": {"true"},
+	}
+	endpoint := fmt.Sprintf("%s/admin/realms/%s/users?%s", c.baseURL, url.PathEscape(c.realm), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса списка пользователей: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var page []models.UserRepresentation
+	if err = c.do(req, &page); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка пользователей Keycloak: %w", err)
+	}
+
+	return page, nil
+}
+
+// Получает токен сервисной учетной записи клиента (grant_type=client_credentials).
+func (c *AdminClient) accessToken(ctx context.Context) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+	}
+	endpoint := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", c.baseURL, url.PathEscape(c.realm))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("ошибка создания запроса токена: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = c.do(req, &token); err != nil {
+		return "", fmt.Errorf("ошибка получения токена Keycloak: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("keycloak не вернул access_token")
+	}
+
+	return token.AccessToken, nil
+}
+
+// Выполняет запрос и разбирает JSON-ответ в result.
+func (c *AdminClient) do(req *http.Request, result any) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("неожиданный статус %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,102 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// newKeycloakStandIn Заглушка Keycloak: выдает токен по client credentials и отдает totalUsers
+// пользователей постранично, каждый третий пользователь отключен.
+func newKeycloakStandIn(t *testing.T, totalUsers int) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /realms/swsm/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_secret") != "sync-secret" {
+			http.Error(w, `{"error":"unauthorized_client"}`, http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "admin-token", "expires_in": 300})
+	})
+
+	mux.HandleFunc("GET /admin/realms/swsm/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("max"))
+
+		page := []map[string]any{}
+		for i := first; i < totalUsers && i < first+limit; i++ {
+			page = append(page, map[string]any{
+				"id":       fmt.Sprintf("user-%d", i),
+				"username": fmt.Sprintf("login-%d", i),
+				"email":    fmt.Sprintf("login-%d@example.com", i),
+				"enabled":  i%3 != 0,
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestAdminClient_ListUsers Проверяет постраничное получение пользователей realm.
+func TestAdminClient_ListUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalUsers int
+	}{
+		{name: "пустой realm", totalUsers: 0},
+		{name: "одна неполная страница", totalUsers: 5},
+		{name: "ровно одна страница", totalUsers: adminPageSize},
+		{name: "несколько страниц", totalUsers: adminPageSize*2 + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newKeycloakStandIn(t, tt.totalUsers)
+
+			client := NewAdminClient(AdminConfig{BaseURL: server.URL + "/", Realm: "swsm", ClientID: "swsm-sync", ClientSecret: "sync-secret"})
+
+			users, err := client.ListUsers(context.Background())
+			require.NoError(t, err)
+			require.Len(t, users, tt.totalUsers)
+
+			if tt.totalUsers > 1 {
+				assert.Equal(t, &appmodels.User{ID: "user-0", Login: "login-0", Email: "login-0@example.com", Disabled: true}, users[0])
+				assert.Equal(t, &appmodels.User{ID: "user-1", Login: "login-1", Email: "login-1@example.com"}, users[1])
+			}
+		})
+	}
+}
+
+// TestAdminClient_ListUsers_Unauthorized Проверяет ошибку при неверном секрете клиента.
+func TestAdminClient_ListUsers_Unauthorized(t *testing.T) {
+	server := newKeycloakStandIn(t, 1)
+
+	client := NewAdminClient(AdminConfig{BaseURL: server.URL, Realm: "swsm", ClientID: "swsm-sync", ClientSecret: "wrong"})
+
+	users, err := client.ListUsers(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Nil(t, users)
+}
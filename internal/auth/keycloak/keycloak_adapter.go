@@ -3,14 +3,17 @@ package keycloak
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
 // KeycloakAdapter Реализует auth.AuthProvider через OIDC/Keycloak.
 type KeycloakAdapter struct {
 	verifier *oidc.IDTokenVerifier
+	clientID string
 }
 
 // KeycloakConfig Конфигурация для создания адаптера.
@@ -42,6 +45,7 @@ func NewKeycloakAdapter(ctx context.Context, config KeycloakConfig) (*KeycloakAd
 
 	return &KeycloakAdapter{
 		verifier: verifier,
+		clientID: config.ClientID,
 	}, nil
 }
 
@@ -61,11 +65,18 @@ func (k *KeycloakAdapter) ValidateToken(ctx context.Context, rawToken string) (*
 		return nil, fmt.Errorf("ошибка парсинга claims: %w", err)
 	}
 
-	return parseUserClaims(claims)
+	userClaims, err := parseUserClaims(claims, k.clientID)
+	if err != nil {
+		return nil, err
+	}
+	userClaims.ExpiresAt = idToken.Expiry
+
+	return userClaims, nil
 }
 
-// Вспомогательная функция. Извлекает нужные поля из claims и возвращает UserClaims с ID и Login.
-func parseUserClaims(claims models.Claims) (*models.UserClaims, error) {
+// Вспомогательная функция. Извлекает нужные поля из claims и возвращает UserClaims с ID, Login и ролями.
+// Роли берутся из ролей realm (realm_access) и ролей клиента clientID (resource_access).
+func parseUserClaims(claims models.Claims, clientID string) (*models.UserClaims, error) {
 	if claims.Sub == "" {
 		return nil, fmt.Errorf("отсутствует обязательный клейм 'sub'")
 	}
@@ -79,5 +90,23 @@ func parseUserClaims(claims models.Claims) (*models.UserClaims, error) {
 	login := claims.PreferredUsername
 	id := claims.Sub
 
-	return &models.UserClaims{ID: id, Login: login}, nil
+	return &models.UserClaims{ID: id, Login: login, Email: claims.Email, Roles: parseRoles(claims, clientID)}, nil
+}
+
+// Вспомогательная функция. Возвращает известные приложению роли из realm_access и resource_access клиента.
+func parseRoles(claims models.Claims, clientID string) []appmodels.Role {
+	names := claims.RealmAccess.Roles
+	if client, ok := claims.ResourceAccess[clientID]; ok {
+		names = append(slices.Clone(names), client.Roles...)
+	}
+
+	var roles []appmodels.Role
+	for _, name := range names {
+		role := appmodels.Role(name)
+		if appmodels.IsValidRole(role) && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
 // TestNewKeycloakAdapter Тесты конструктора.
@@ -146,9 +147,10 @@ func TestKeycloakAdapter_ValidateToken_ErrorCases(t *testing.T) {
 // TestParseUserClaims Тесты парсинга UserClaims.
 func TestParseUserClaims(t *testing.T) {
 	tests := []struct {
-		name    string
-		claims  models.Claims
-		wantErr bool
+		name      string
+		claims    models.Claims
+		wantRoles []appmodels.Role
+		wantErr   bool
 	}{
 		{
 			name: "ok",
@@ -157,6 +159,31 @@ func TestParseUserClaims(t *testing.T) {
 				PreferredUsername: "testuser",
 			},
 		},
+		{
+			name: "роли realm и клиента",
+			claims: models.Claims{
+				Sub:               "any-id-user-1",
+				PreferredUsername: "testuser",
+				RealmAccess:       models.RolesClaim{Roles: []string{"offline_access", "viewer"}},
+				ResourceAccess: map[string]models.RolesClaim{
+					"swsm":          {Roles: []string{"operator", "viewer"}},
+					"other-client":  {Roles: []string{"admin"}},
+					"realm-account": {Roles: []string{"manage-account"}},
+				},
+			},
+			wantRoles: []appmodels.Role{appmodels.RoleViewer, appmodels.RoleOperator},
+		},
+		{
+			name: "только роли клиента",
+			claims: models.Claims{
+				Sub:               "any-id-user-1",
+				PreferredUsername: "testuser",
+				ResourceAccess: map[string]models.RolesClaim{
+					"swsm": {Roles: []string{"admin"}},
+				},
+			},
+			wantRoles: []appmodels.Role{appmodels.RoleAdmin},
+		},
 		{
 			name: "no sub",
 			claims: models.Claims{
@@ -175,7 +202,7 @@ func TestParseUserClaims(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res, err := parseUserClaims(tt.claims)
+			res, err := parseUserClaims(tt.claims, "swsm")
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -185,6 +212,7 @@ func TestParseUserClaims(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, tt.claims.Sub, res.ID)
 			require.Equal(t, tt.claims.PreferredUsername, res.Login)
+			require.Equal(t, tt.wantRoles, res.Roles)
 		})
 	}
 }
@@ -12,13 +12,28 @@ type KeycloakAdminEvent struct {
 	Representation string `json:"representation"`
 }
 
-// ExtractUserID Вспомогательная функция. Извлекает UUID пользователя из resourcePath.
+// ExtractUserID Вспомогательная функция. Извлекает UUID пользователя из resourcePath
+// ("users/{id}" или вложенного ресурса пользователя, например "users/{id}/role-mappings/realm").
 func (ae *KeycloakAdminEvent) ExtractUserID() string {
 	parts := strings.Split(ae.ResourcePath, "/")
 
+	if len(parts) > 1 && parts[0] == "users" {
+		return parts[1]
+	}
+
 	if len(parts) > 0 {
 		return parts[len(parts)-1]
 	}
 
 	return ""
 }
+
+// IsUserRoleChange Проверяет, что событие изменяет роли или группы пользователя.
+func (ae *KeycloakAdminEvent) IsUserRoleChange() bool {
+	switch ae.ResourceType {
+	case "REALM_ROLE_MAPPING", "CLIENT_ROLE_MAPPING", "GROUP_MEMBERSHIP":
+		return strings.HasPrefix(ae.ResourcePath, "users/")
+	default:
+		return false
+	}
+}
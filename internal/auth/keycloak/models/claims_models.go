@@ -1,11 +1,25 @@
 package models
 
+import (
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
 // Claims - минимальный набор OIDC/JWT клеймов,
 // необходимых приложению для идентификации пользователя.
 // Используется как промежуточная структура при разборе токена.
 type Claims struct {
-	Sub               string `json:"sub"`
-	PreferredUsername string `json:"preferred_username"`
+	Sub               string                `json:"sub"`
+	PreferredUsername string                `json:"preferred_username"`
+	Email             string                `json:"email"`
+	RealmAccess       RolesClaim            `json:"realm_access"`
+	ResourceAccess    map[string]RolesClaim `json:"resource_access"`
+}
+
+// RolesClaim Список ролей Keycloak (realm_access или роли клиента в resource_access).
+type RolesClaim struct {
+	Roles []string `json:"roles"`
 }
 
 // UserClaims - доменная модель аутентифицированного пользователя,
@@ -13,4 +27,8 @@ type Claims struct {
 type UserClaims struct {
 	ID    string
 	Login string
+	Email string        // может быть пустым, если провайдер не передает email
+	Roles []models.Role // роли приложения, назначенные пользователю в провайдере
+	// ExpiresAt Окончание действия токена (нулевое, если провайдер его не сообщает)
+	ExpiresAt time.Time
 }
@@ -18,4 +18,10 @@ type UserRepresentation struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Enabled  *bool  `json:"enabled,omitempty"` // nil - признак не передан
+}
+
+// Disabled Пользователь отключен в Keycloak.
+func (u *UserRepresentation) Disabled() bool {
+	return u.Enabled != nil && !*u.Enabled
 }
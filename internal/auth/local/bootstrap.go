@@ -0,0 +1,61 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// BootstrapAdmin Создает первого пользователя с паролем password, если пользователя login еще нет,
+// или задает пароль существующему пользователю без пароля. Уже заданный пароль не перезаписывается,
+// поэтому смена пароля через API сохраняется после перезапуска. Пустой пароль отключает создание.
+func BootstrapAdmin(ctx context.Context, users storage.LocalAuthStorage, login string, password string) error {
+	if password == "" {
+		return nil
+	}
+
+	if err := models.ValidatePassword(password); err != nil {
+		return fmt.Errorf("пароль первого администратора: %w", err)
+	}
+
+	user, err := users.GetLocalUser(ctx, login)
+	if err != nil {
+		var errWrongLogin *errs.ErrWrongLogin
+		if !errors.As(err, &errWrongLogin) {
+			return err
+		}
+	}
+
+	if user != nil && user.PasswordHash != "" {
+		return nil
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if user != nil {
+		if err = users.SetUserPassword(ctx, user.ID, hash); err != nil {
+			return err
+		}
+
+		logger.Log.Info("Задан пароль первого администратора", logger.String("login", login))
+		return nil
+	}
+
+	if err = users.CreateLocalUser(ctx, &models.User{ID: uuid.NewString(), Login: login}, hash); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Создан первый администратор", logger.String("login", login))
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// TestBootstrapAdmin Проверяет создание первого администратора.
+func TestBootstrapAdmin(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		setupMock func(m *mocks.MockStorage)
+		wantErr   bool
+	}{
+		{
+			name:      "пароль не задан - создание отключено",
+			setupMock: func(m *mocks.MockStorage) {},
+		},
+		{
+			name:      "слишком короткий пароль",
+			password:  "short",
+			setupMock: func(m *mocks.MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name:     "пользователя нет - создается",
+			password: "admin-password",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "admin").
+					Return(nil, errs.NewErrWrongLoginOrPassword(errors.New("no rows")))
+				m.EXPECT().CreateLocalUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *models.User, hash string) error {
+						assert.Equal(t, "admin", user.Login)
+						assert.NotEmpty(t, user.ID)
+						assert.True(t, CheckPassword(hash, "admin-password"))
+						return nil
+					})
+			},
+		},
+		{
+			name:     "пользователь без пароля - пароль задается",
+			password: "admin-password",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "admin").
+					Return(&models.LocalUser{User: models.User{ID: "user-1", Login: "admin"}}, nil)
+				m.EXPECT().SetUserPassword(gomock.Any(), "user-1", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "пароль уже задан - не перезаписывается",
+			password: "admin-password",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "admin").
+					Return(&models.LocalUser{User: models.User{ID: "user-1", Login: "admin"}, PasswordHash: "hash"}, nil)
+			},
+		},
+		{
+			name:     "ошибка БД",
+			password: "admin-password",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetLocalUser(gomock.Any(), "admin").Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			err := BootstrapAdmin(context.Background(), mockStorage, "admin", tt.password)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package local содержит встроенную аутентификацию без внешнего провайдера (AUTH_PROVIDER=local):
+// пароли пользователей хранятся в БД SWSM в виде хэшей bcrypt, приложение само выпускает
+// подписанные (HS256) access-токены и одноразовые refresh-токены.
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// Issuer Издатель (iss) access-токенов встроенной аутентификации.
+const Issuer = "swsm"
+
+// SecretMinLen Минимальная длина секрета подписи токенов.
+const SecretMinLen = 32
+
+// dummyHash Хэш, с которым сравнивается пароль несуществующего пользователя,
+// чтобы время ответа не выдавало существование логина.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("swsm-dummy-password"), bcrypt.DefaultCost)
+
+// LocalAdapter Реализует auth.AuthProvider для встроенной аутентификации.
+type LocalAdapter struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+// LocalConfig Конфигурация для создания адаптера.
+type LocalConfig struct {
+	Secret    string        // секрет подписи access-токенов (не короче SecretMinLen)
+	AccessTTL time.Duration // срок действия access-токена
+}
+
+// accessClaims Клеймы access-токена.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email,omitempty"`
+}
+
+// NewLocalAdapter Конструктор адаптера LocalAdapter.
+func NewLocalAdapter(config LocalConfig) (*LocalAdapter, error) {
+	if len(config.Secret) < SecretMinLen {
+		return nil, fmt.Errorf("секрет подписи токенов должен быть не короче %d символов", SecretMinLen)
+	}
+
+	if config.AccessTTL <= 0 {
+		return nil, errors.New("срок действия access-токена должен быть положительным")
+	}
+
+	return &LocalAdapter{
+		secret:    []byte(config.Secret),
+		accessTTL: config.AccessTTL,
+	}, nil
+}
+
+// ValidateToken Реализует интерфейс auth.AuthProvider.
+// Роли в токене не передаются: роль определяется DEFAULT_ROLE и ADMIN_USERS.
+func (a *LocalAdapter) ValidateToken(_ context.Context, rawToken string) (*models.UserClaims, error) {
+	var claims accessClaims
+
+	_, err := jwt.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (any, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("неожиданный алгоритм подписи: %v", token.Header["alg"])
+		}
+		return a.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка верификации токена: %w", err)
+	}
+
+	if !claims.VerifyIssuer(Issuer, true) {
+		return nil, fmt.Errorf("неверный издатель токена: %q", claims.Issuer)
+	}
+
+	// токен без срока действия не принимается
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("отсутствует обязательный клейм 'exp'")
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("отсутствует обязательный клейм 'sub'")
+	}
+
+	if claims.PreferredUsername == "" {
+		return nil, errors.New("отсутствует обязательный клейм 'preferred_username'")
+	}
+
+	return &models.UserClaims{ID: claims.Subject, Login: claims.PreferredUsername, Email: claims.Email, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+// IssueAccessToken Выпускает подписанный access-токен пользователя и возвращает время окончания его действия.
+func (a *LocalAdapter) IssueAccessToken(user appmodels.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(a.accessTTL)
+
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.NewString(),
+		},
+		PreferredUsername: user.Login,
+		Email:             user.Email,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("не удалось подписать access-токен: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// HashPassword Возвращает bcrypt-хэш пароля для хранения в БД.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("не удалось вычислить хэш пароля: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword Проверяет пароль по хэшу. Пустой хэш (пароль не задан) сравнивается с заглушкой
+// и всегда дает false, чтобы время проверки не зависело от наличия пароля.
+func CheckPassword(hash string, password string) bool {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// GenerateRefreshToken Выпускает новый refresh-токен.
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать refresh-токен: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashRefreshToken Возвращает хэш refresh-токена для хранения и поиска в БД.
+// Токен содержит 256 бит случайных данных, поэтому достаточно SHA-256.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// TestNewLocalAdapter Тесты конструктора.
+func TestNewLocalAdapter(t *testing.T) {
+	_, err := NewLocalAdapter(LocalConfig{Secret: "short", AccessTTL: time.Minute})
+	assert.Error(t, err)
+
+	_, err = NewLocalAdapter(LocalConfig{Secret: testSecret})
+	assert.Error(t, err)
+
+	adapter, err := NewLocalAdapter(LocalConfig{Secret: testSecret, AccessTTL: time.Minute})
+	require.NoError(t, err)
+	assert.NotNil(t, adapter)
+}
+
+// TestLocalAdapter_ValidateToken Проверяет выпуск и проверку access-токенов.
+func TestLocalAdapter_ValidateToken(t *testing.T) {
+	adapter, err := NewLocalAdapter(LocalConfig{Secret: testSecret, AccessTTL: 15 * time.Minute})
+	require.NoError(t, err)
+
+	// sign Подписывает произвольные клеймы секретом secret.
+	sign := func(method jwt.SigningMethod, claims accessClaims, secret any) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(secret)
+		require.NoError(t, err)
+		return token
+	}
+
+	validClaims := func() accessClaims {
+		return accessClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    Issuer,
+				Subject:   "user-1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+			PreferredUsername: "alice",
+		}
+	}
+
+	t.Run("выпущенный токен принимается", func(t *testing.T) {
+		token, expiresAt, err := adapter.IssueAccessToken(appmodels.User{ID: "user-1", Login: "alice", Email: "alice@example.com"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+
+		claims, err := adapter.ValidateToken(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.ID)
+		assert.Equal(t, "alice", claims.Login)
+		assert.Equal(t, "alice@example.com", claims.Email)
+		assert.WithinDuration(t, expiresAt, claims.ExpiresAt, time.Second)
+		assert.Empty(t, claims.Roles)
+	})
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{
+			name: "чужой секрет",
+			token: func() string {
+				return sign(jwt.SigningMethodHS256, validClaims(), []byte("another-secret-another-secret-00"))
+			},
+		},
+		{
+			name:  "алгоритм none",
+			token: func() string { return sign(jwt.SigningMethodNone, validClaims(), jwt.UnsafeAllowNoneSignatureType) },
+		},
+		{
+			name:  "другой алгоритм HMAC",
+			token: func() string { return sign(jwt.SigningMethodHS512, validClaims(), []byte(testSecret)) },
+		},
+		{
+			name: "истекший токен",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return sign(jwt.SigningMethodHS256, claims, []byte(testSecret))
+			},
+		},
+		{
+			name: "без срока действия",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = nil
+				return sign(jwt.SigningMethodHS256, claims, []byte(testSecret))
+			},
+		},
+		{
+			name: "другой издатель",
+			token: func() string {
+				claims := validClaims()
+				claims.Issuer = "keycloak"
+				return sign(jwt.SigningMethodHS256, claims, []byte(testSecret))
+			},
+		},
+		{
+			name: "без логина",
+			token: func() string {
+				claims := validClaims()
+				claims.PreferredUsername = ""
+				return sign(jwt.SigningMethodHS256, claims, []byte(testSecret))
+			},
+		},
+		{
+			name:  "не JWT",
+			token: func() string { return "not.a.jwt" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := adapter.ValidateToken(context.Background(), tt.token())
+			assert.Error(t, err)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+// TestPasswords Проверяет хэширование и проверку паролей.
+func TestPasswords(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+	assert.False(t, CheckPassword("", "correct horse"))
+}
+
+// TestRefreshTokens Проверяет выпуск и хэширование refresh-токенов.
+func TestRefreshTokens(t *testing.T) {
+	first, err := GenerateRefreshToken()
+	require.NoError(t, err)
+	second, err := GenerateRefreshToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, HashRefreshToken(first), 64)
+	assert.Equal(t, HashRefreshToken(first), HashRefreshToken(first))
+	assert.NotEqual(t, HashRefreshToken(first), HashRefreshToken(second))
+}
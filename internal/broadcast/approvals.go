@@ -0,0 +1,42 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TeamMembersLister Источник участников команды, которым рассылаются события.
+type TeamMembersLister interface {
+	ListTeamMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error)
+}
+
+// PublishApproval Публикует запрос на подтверждение действия со службой в поток approvals
+// каждого участника команды. Ошибки только логируются: рассылка не должна влиять на ответ пользователю.
+func PublishApproval(ctx context.Context, members TeamMembersLister, publisher Broadcaster, approval *models.ControlApproval) {
+	b, err := json.Marshal(approval)
+	if err != nil {
+		logger.Log.Error("Ошибка сериализации запроса на подтверждение", logger.String("err", err.Error()))
+		return
+	}
+
+	teamMembers, err := members.ListTeamMembers(ctx, approval.TeamID)
+	if err != nil {
+		logger.Log.Error("Ошибка получения участников команды для рассылки запроса на подтверждение",
+			logger.Int64("teamID", approval.TeamID),
+			logger.String("err", err.Error()))
+		return
+	}
+
+	for _, member := range teamMembers {
+		topic := fmt.Sprintf("user-%s:approvals", member.UserID)
+		if err = publisher.Publish(topic, b); err != nil {
+			logger.Log.Warn("Ошибка публикации запроса на подтверждение",
+				logger.String("topic", topic),
+				logger.String("err", err.Error()))
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 	authMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/auth/mocks"
 	broadcasterMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast/mocks"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
 func init() {
@@ -462,6 +464,8 @@ func TestMakeTopicResolver(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	expectActiveUsers(mockStorage)
 
 	tests := []struct {
 		name         string
@@ -553,9 +557,7 @@ func TestMakeTopicResolver(t *testing.T) {
 				return r
 			},
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "kc-no-stream-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:   "",
 			wantErr:     true,
@@ -569,9 +571,7 @@ func TestMakeTopicResolver(t *testing.T) {
 				return r
 			},
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "kc-unknown-stream-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:   "",
 			wantErr:     true,
@@ -583,7 +583,7 @@ func TestMakeTopicResolver(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMock()
 
-			resolver := MakeTopicResolver(mockAuthProvider)
+			resolver := MakeTopicResolver(mockAuthProvider, mockStorage, true)
 			r := tt.setupRequest()
 
 			topic, err := resolver(r)
@@ -609,6 +609,8 @@ func TestHTTPHandlerWithKeycloakResolver(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	expectActiveUsers(mockStorage)
 
 	tests := []struct {
 		name       string
@@ -646,7 +648,7 @@ func TestHTTPHandlerWithKeycloakResolver(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMock()
 
-			resolver := MakeTopicResolver(mockAuthProvider)
+			resolver := MakeTopicResolver(mockAuthProvider, mockStorage, true)
 			adapter := NewR3labsSSEAdapter(resolver)
 			defer adapter.Close()
 
@@ -917,6 +919,8 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	expectActiveUsers(mockStorage)
 
 	tests := []struct {
 		name           string
@@ -952,9 +956,7 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 			name:  "stream=SERVER (верхний регистр) невалиден",
 			query: "/events?stream=SERVER",
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "jwt-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:      "",
 			wantErr:        true,
@@ -964,9 +966,7 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 			name:  "stream= (пустое значение) невалиден",
 			query: "/events?stream=",
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "jwt-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:      "",
 			wantErr:        true,
@@ -976,9 +976,7 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 			name:  "stream=events (неверное значение) невалиден",
 			query: "/events?stream=events",
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "jwt-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:      "",
 			wantErr:        true,
@@ -988,9 +986,7 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 			name:  "stream с пробелом невалиден",
 			query: "/events?stream=services%20",
 			setupMock: func() {
-				mockAuthProvider.EXPECT().
-					ValidateToken(gomock.Any(), "jwt-token").
-					Return(&models.UserClaims{ID: "any-id-user-1", Login: "user"}, nil)
+				// ValidateToken НЕ вызывается: поток проверяется до аутентификации
 			},
 			wantTopic:      "",
 			wantErr:        true,
@@ -1013,7 +1009,7 @@ func TestTopicResolver_StreamValidation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMock()
 
-			resolver := MakeTopicResolver(mockAuthProvider)
+			resolver := MakeTopicResolver(mockAuthProvider, mockStorage, true)
 			r := httptest.NewRequest(http.MethodGet, tt.query, nil)
 			r.AddCookie(&http.Cookie{Name: "JWT", Value: "jwt-token"})
 
@@ -1040,6 +1036,8 @@ func TestTopicResolver_IDValidation(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	expectActiveUsers(mockStorage)
 
 	tests := []struct {
 		name           string
@@ -1075,7 +1073,7 @@ func TestTopicResolver_IDValidation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMock()
 
-			resolver := MakeTopicResolver(mockAuthProvider)
+			resolver := MakeTopicResolver(mockAuthProvider, mockStorage, true)
 			r := httptest.NewRequest(http.MethodGet, "/events?stream=services", nil)
 			r.AddCookie(&http.Cookie{Name: "JWT", Value: "jwt-token"})
 
@@ -1094,3 +1092,13 @@ func TestTopicResolver_IDValidation(t *testing.T) {
 		})
 	}
 }
+
+// expectActiveUsers Настраивает хранилище так, что любой пользователь существует и не заблокирован.
+func expectActiveUsers(mockStorage *storageMocks.MockStorage) {
+	mockStorage.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, userID string) (*appmodels.User, error) {
+			return &appmodels.User{ID: userID}, nil
+		}).
+		AnyTimes()
+}
@@ -0,0 +1,44 @@
+package broadcast
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// TicketParam Параметр запроса EventSource с одноразовым билетом SSE.
+const TicketParam = "ticket"
+
+// ValidateStream Проверяет тип потока (servers / services / approvals).
+func ValidateStream(stream string) error {
+	if stream == "" {
+		return errors.New("параметр запроса stream обязателен")
+	}
+
+	switch stream {
+	case "servers", "services", "approvals":
+		return nil
+	default:
+		return errors.New("неизвестный тип потока")
+	}
+}
+
+// GenerateTicket Выпускает новый билет SSE.
+func GenerateTicket() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать билет SSE: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashTicket Возвращает хэш билета для хранения и поиска в БД.
+// Билет содержит 256 бит случайных данных, поэтому достаточно SHA-256.
+func HashTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
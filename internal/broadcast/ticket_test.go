@@ -0,0 +1,154 @@
+package broadcast
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
+	authMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/auth/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	appmodels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// TestGenerateTicket Проверяет выпуск и хэширование билетов SSE.
+func TestGenerateTicket(t *testing.T) {
+	first, err := GenerateTicket()
+	require.NoError(t, err)
+	second, err := GenerateTicket()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+	assert.Len(t, HashTicket(first), 64)
+	assert.Equal(t, HashTicket(first), HashTicket(first))
+	assert.NotEqual(t, HashTicket(first), HashTicket(second))
+}
+
+// TestMakeTopicResolver_Ticket Проверяет определение пользователя по одноразовому билету SSE.
+func TestMakeTopicResolver_Ticket(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		cookie       string
+		cookieAuth   bool
+		setupMock    func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage)
+		wantTopic    string
+		checkErrMsg  string
+		checkErrType bool
+	}{
+		{
+			name:       "действительный билет",
+			url:        "/events?stream=services&ticket=ticket-1",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				storage.EXPECT().UseSSETicket(gomock.Any(), HashTicket("ticket-1"), "services").Return("user-1", nil)
+			},
+			wantTopic: "user-user-1:services",
+		},
+		{
+			name:       "билет имеет приоритет над кукой",
+			url:        "/events?stream=approvals&ticket=ticket-1",
+			cookie:     "jwt-token",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				storage.EXPECT().UseSSETicket(gomock.Any(), HashTicket("ticket-1"), "approvals").Return("user-1", nil)
+			},
+			wantTopic: "user-user-1:approvals",
+		},
+		{
+			name:       "билет использован, истек или выдан на другой поток",
+			url:        "/events?stream=servers&ticket=ticket-1",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				storage.EXPECT().UseSSETicket(gomock.Any(), HashTicket("ticket-1"), "servers").
+					Return("", errs.NewErrSSETicketNotFound(nil))
+			},
+			checkErrType: true,
+		},
+		{
+			name:       "неизвестный поток: билет не расходуется",
+			url:        "/events?stream=unknown&ticket=ticket-1",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				// UseSSETicket НЕ вызывается
+			},
+			checkErrMsg: "неизвестный тип потока",
+		},
+		{
+			name:       "аутентификация кукой отключена",
+			url:        "/events?stream=services",
+			cookie:     "jwt-token",
+			cookieAuth: false,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				// ValidateToken НЕ вызывается
+			},
+			checkErrMsg: "параметр запроса ticket обязателен",
+		},
+		{
+			name:       "кука заблокированного пользователя",
+			url:        "/events?stream=services",
+			cookie:     "jwt-token",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				auth.EXPECT().ValidateToken(gomock.Any(), "jwt-token").
+					Return(&models.UserClaims{ID: "user-1", Login: "user"}, nil)
+				storage.EXPECT().GetUser(gomock.Any(), "user-1").
+					Return(&appmodels.User{ID: "user-1", Login: "user", Disabled: true}, nil)
+			},
+			checkErrMsg: "пользователь заблокирован",
+		},
+		{
+			name:       "пользователь куки не найден",
+			url:        "/events?stream=services",
+			cookie:     "jwt-token",
+			cookieAuth: true,
+			setupMock: func(auth *authMocks.MockAuthProvider, storage *storageMocks.MockStorage) {
+				auth.EXPECT().ValidateToken(gomock.Any(), "jwt-token").
+					Return(&models.UserClaims{ID: "user-1", Login: "user"}, nil)
+				storage.EXPECT().GetUser(gomock.Any(), "user-1").
+					Return(nil, errs.NewErrUserIDNotFound("user-1"))
+			},
+			checkErrMsg: "user-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			tt.setupMock(mockAuthProvider, mockStorage)
+
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "JWT", Value: tt.cookie})
+			}
+
+			topic, err := MakeTopicResolver(mockAuthProvider, mockStorage, tt.cookieAuth)(r)
+
+			if tt.wantTopic != "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantTopic, topic)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, "", topic)
+			if tt.checkErrMsg != "" {
+				assert.Contains(t, err.Error(), tt.checkErrMsg)
+			}
+			if tt.checkErrType {
+				var errNotFound *errs.ErrSSETicketNotFound
+				assert.True(t, errors.As(err, &errNotFound))
+			}
+		})
+	}
+}
@@ -1,43 +1,81 @@
 package broadcast
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
+// SubscriberStorage Хранилище, по которому определяется пользователь SSE-подключения.
+type SubscriberStorage interface {
+	UseSSETicket(ctx context.Context, ticketHash string, stream string) (string, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+}
+
 // MakeTopicResolver возвращает resolver.
-func MakeTopicResolver(authProvider auth.AuthProvider) TopicResolver {
+// Пользователь определяется по одноразовому билету из параметра ticket, выданному на запрошенный поток.
+// Если билет не передан и cookieAuth включен, пользователь определяется по JWT из сессионной куки.
+func MakeTopicResolver(authProvider auth.AuthProvider, storage SubscriberStorage, cookieAuth bool) TopicResolver {
 	return func(r *http.Request) (string, error) {
-		c, err := r.Cookie("JWT")
-		if err != nil {
+		// тип потока (servers / services / approvals)
+		stream := r.URL.Query().Get("stream")
+		if err := ValidateStream(stream); err != nil {
 			return "", err
 		}
-		token := c.Value
 
-		claims, err := authProvider.ValidateToken(r.Context(), token)
+		var (
+			userID string
+			err    error
+		)
+
+		switch ticket := r.URL.Query().Get(TicketParam); {
+		case ticket != "":
+			userID, err = storage.UseSSETicket(r.Context(), HashTicket(ticket), stream)
+		case cookieAuth:
+			userID, err = cookieUserID(r, authProvider, storage)
+		default:
+			err = errors.New("параметр запроса ticket обязателен")
+		}
+
 		if err != nil {
 			return "", err
 		}
-		if claims.ID == "" {
+		if userID == "" {
 			return "", errors.New("неверный id пользователя")
 		}
 
-		// тип потока (servers / services)
-		stream := r.URL.Query().Get("stream")
-		if stream == "" {
-			return "", errors.New("параметр запроса stream обязателен")
-		}
+		return fmt.Sprintf("user-%s:%s", userID, stream), nil
+	}
+}
 
-		switch stream {
-		case "servers", "services":
-			// OK
-		default:
-			return "", errors.New("неизвестный тип потока")
-		}
+// cookieUserID Возвращает id пользователя по JWT из сессионной куки.
+// Заблокированный пользователь не может подключиться, даже если его токен еще действителен.
+func cookieUserID(r *http.Request, authProvider auth.AuthProvider, storage SubscriberStorage) (string, error) {
+	c, err := r.Cookie(middleware.SessionCookie)
+	if err != nil {
+		return "", err
+	}
 
-		return fmt.Sprintf("user-%s:%s", claims.ID, stream), nil
+	claims, err := authProvider.ValidateToken(r.Context(), c.Value)
+	if err != nil {
+		return "", err
 	}
+	if claims.ID == "" {
+		return "", errors.New("неверный id пользователя")
+	}
+
+	user, err := storage.GetUser(r.Context(), claims.ID)
+	if err != nil {
+		return "", err
+	}
+	if user.Disabled {
+		return "", errors.New("пользователь заблокирован")
+	}
+
+	return user.ID, nil
 }
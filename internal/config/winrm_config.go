@@ -1,13 +1,23 @@
 package config
 
-import "time"
+import (
+	"strconv"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
 
 // WinRMConfig Структура конфигурации для создания WinRM клиента.
 type WinRMConfig struct {
-	Port          string
-	UseHTTPS      bool
-	InsecureHTTPS bool
-	Timeout       time.Duration
+	Port           string
+	UseHTTPS       bool
+	InsecureHTTPS  bool
+	Timeout        time.Duration
+	ConnectTimeout time.Duration // таймаут проверки доступности WinRM, 0 - таймаут проверки по умолчанию
+	Auth           string        // механизм аутентификации: basic, ntlm, kerberos
+	Krb5Config     string        // путь к krb5.conf
+	Krb5Keytab     string        // путь к keytab, пустое значение - аутентификация Kerberos по паролю
+	Krb5Realm      string        // realm пользователей, пустое значение - default_realm из krb5.conf
 }
 
 // NewWinRMConfig Конструктор, возвращающий конфиг с параметрами для создания WinRM клиента.
@@ -17,5 +27,41 @@ func NewWinRMConfig(srvConfig *Config, timeout time.Duration) *WinRMConfig {
 		UseHTTPS:      srvConfig.WinRMUseHTTPS,
 		InsecureHTTPS: srvConfig.WinRMInsecureForHTTPS,
 		Timeout:       timeout,
+		Auth:          srvConfig.WinRMAuth,
+		Krb5Config:    srvConfig.WinRMKrb5Config,
+		Krb5Keytab:    srvConfig.WinRMKrb5Keytab,
+		Krb5Realm:     srvConfig.WinRMKrb5Realm,
+	}
+}
+
+// ForServer Возвращает конфиг подключения к конкретному серверу:
+// заданные для сервера параметры заменяют глобальные.
+func (c *WinRMConfig) ForServer(settings models.WinRMSettings) *WinRMConfig {
+	cfg := *c
+
+	if settings.Auth != nil {
+		cfg.Auth = *settings.Auth
+	}
+
+	if settings.Port != nil {
+		cfg.Port = strconv.Itoa(*settings.Port)
+	}
+
+	if settings.HTTPS != nil {
+		cfg.UseHTTPS = *settings.HTTPS
+	}
+
+	if settings.Insecure != nil {
+		cfg.InsecureHTTPS = *settings.Insecure
+	}
+
+	if settings.Timeout != nil {
+		cfg.Timeout = time.Duration(*settings.Timeout) * time.Second
+	}
+
+	if settings.ConnectTimeout != nil {
+		cfg.ConnectTimeout = time.Duration(*settings.ConnectTimeout) * time.Second
 	}
+
+	return &cfg
 }
@@ -45,3 +45,51 @@ type serviceID struct{}
 // ServiceID — единственный экземпляр ключа id, который нужно использовать для сохранения
 // и получения значения id службы из context.Context.
 var ServiceID = serviceID{}
+
+// auditEntry — это уникальный тип ключа для хранения записи журнала аудита текущего запроса в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type auditEntry struct{}
+
+// AuditEntry — единственный экземпляр ключа auditEntry, который нужно использовать для сохранения
+// и получения записи журнала аудита из context.Context.
+var AuditEntry = auditEntry{}
+
+// role — это уникальный тип ключа для хранения роли пользователя в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type role struct{}
+
+// Role — единственный экземпляр ключа role, который нужно использовать для сохранения
+// и получения роли пользователя из context.Context.
+var Role = role{}
+
+// teamID — это уникальный тип ключа для хранения id команды в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type teamID struct{}
+
+// TeamID — единственный экземпляр ключа teamID, который нужно использовать для сохранения
+// и получения значения id команды из context.Context.
+var TeamID = teamID{}
+
+// approvalID — это уникальный тип ключа для хранения id подтвержденного запроса на действие со службой в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type approvalID struct{}
+
+// ApprovalID — единственный экземпляр ключа approvalID, который нужно использовать для сохранения
+// и получения id подтвержденного запроса из context.Context.
+var ApprovalID = approvalID{}
+
+// apiToken — это уникальный тип ключа для хранения API-токена, которым аутентифицирован запрос, в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type apiToken struct{}
+
+// APIToken — единственный экземпляр ключа apiToken, который нужно использовать для сохранения
+// и получения API-токена из context.Context.
+var APIToken = apiToken{}
+
+// email — это уникальный тип ключа для хранения email пользователя из токена провайдера в контексте.
+// Определяем новый тип struct{}, чтобы избежать конфликтов с другими ключами.
+type email struct{}
+
+// Email — единственный экземпляр ключа email, который нужно использовать для сохранения
+// и получения email пользователя из context.Context.
+var Email = email{}
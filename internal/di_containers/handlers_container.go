@@ -1,60 +1,161 @@
 package di_containers
 
 import (
+	"net"
+	"net/http"
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/app_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/approval_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/audit_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/control_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/credential_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/health_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/local_auth_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/report_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/server_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/service_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/session_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/team_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/token_handler"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/user_handler"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/webhooks"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/local"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/secrets"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/tracing"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/worker"
 )
 
 // HandlersContainer Контейнер со всеми хендлерами приложения (и их зависимостями).
 type HandlersContainer struct {
-	Storage         storage.Storage
-	ServerHandler   *server_handler.ServerHandler
-	ServiceHandler  *service_handler.ServiceHandler
-	ControlHandler  *control_handler.ControlHandler
-	SessionHandler  *session_handler.SessionHandler
-	HealthHandler   *health_handler.HealthHandler
-	AppHandler      *app_handler.AppHandler
-	WebhooksHandler *webhooks.Webhook
+	Storage           storage.Storage
+	ServerHandler     *server_handler.ServerHandler
+	ServiceHandler    *service_handler.ServiceHandler
+	ControlHandler    *control_handler.ControlHandler
+	SessionHandler    *session_handler.SessionHandler
+	HealthHandler     *health_handler.HealthHandler
+	AppHandler        *app_handler.AppHandler
+	WebhooksHandler   *webhooks.Webhook
+	ReportHandler     *report_handler.ReportHandler
+	AuditHandler      *audit_handler.AuditHandler
+	UserHandler       *user_handler.UserHandler
+	TeamHandler       *team_handler.TeamHandler
+	TokenHandler      *token_handler.TokenHandler
+	CredentialHandler *credential_handler.CredentialHandler
+	ApprovalHandler   *approval_handler.ApprovalHandler
+	LocalAuthHandler  *local_auth_handler.LocalAuthHandler // nil, если встроенная аутентификация не используется
+	RolePolicy        models.RolePolicy                    // правила определения роли пользователя
+	JITProvisioning   bool                                 // создание пользователя при первом входе
+	EventSink         siem.Sink                            // получатель событий безопасности (SIEM)
+	TrustedProxies    []*net.IPNet                         // обратные прокси, которым доверяются заголовки с адресом клиента
+	MetricsHandler    http.Handler                         // nil, если эндпоинт /metrics отключен
 }
 
 // NewHandlersContainer Конструктор контейнера с зависимостями для хендлеров.
-func NewHandlersContainer(storage storage.Storage, statusCache health_storage.StatusCacheStorage, srvConfig *config.Config, broadcaster broadcast.Broadcaster, authProvider auth.AuthProvider, netChecker netutils.Checker) *HandlersContainer {
+func NewHandlersContainer(pgStorage storage.Storage, statusCache health_storage.StatusCacheStorage, srvConfig *config.Config, broadcaster broadcast.Broadcaster, authProvider auth.AuthProvider, checker netutils.Checker, eventSink siem.Sink, secretProvider secrets.Provider) *HandlersContainer {
+	// зависимости хендлеров оборачиваются в спаны трассировки
+	// (без настроенного экспортера спаны никуда не отправляются),
+	// учетные данные серверов, ссылающихся на секрет, подставляются провайдером секретов
+	storage := tracing.NewStorage(secrets.NewStorage(pgStorage, secretProvider))
+	netChecker := tracing.NewChecker(checker)
+
 	winRMConfig := config.NewWinRMConfig(srvConfig, 10*time.Second)
-	clientFactory := service_control.NewWinRMClientFactory(winRMConfig)
-	fingerprinter := service_control.NewWinRMFingerprinter(clientFactory, netChecker, winRMConfig.Port)
-	serviceStatusesChecker := worker.NewServiceStatusesChecker(clientFactory)
-
-	serverHandler := server_handler.NewServerHandler(storage, fingerprinter)
-	serviceHandler := service_handler.NewServiceHandler(storage, clientFactory, netChecker, serviceStatusesChecker, winRMConfig.Port)
-	controlHandler := control_handler.NewControlHandler(storage, clientFactory, netChecker, winRMConfig.Port)
-	sessionHandler := session_handler.NewSessionHandler(authProvider)
+	winRMFactory := service_control.NewWinRMClientFactory(winRMConfig)
+
+	// хендлеры и воркер статусов служб переиспользуют WinRM клиентов через общий пул,
+	// проверки подключения и получение UUID сервера всегда выполняются новым клиентом
+	clientPool := service_control.NewWinRMClientPool(winRMFactory, srvConfig.WinRMPoolMaxPerHost, srvConfig.WinRMPoolIdleTimeout)
+	clientFactory := tracing.NewClientFactory(clientPool)
+	fingerprinter := service_control.NewWinRMFingerprinter(tracing.NewClientFactory(winRMFactory), netChecker, winRMConfig)
+	diagnoser := service_control.NewWinRMDiagnoser(tracing.NewClientFactory(winRMFactory), netChecker, winRMConfig)
+	serviceManager := service_control.NewCIMServiceManager()
+	serviceStatusesChecker := worker.NewServiceStatusesChecker(clientFactory, serviceManager)
+
+	serverHandler := server_handler.NewServerHandler(storage, fingerprinter, diagnoser, secretProvider, clientPool)
+	serviceHandler := service_handler.NewServiceHandler(storage, clientFactory, serviceManager, netChecker, serviceStatusesChecker, winRMConfig)
+	controlHandler := control_handler.NewControlHandler(storage, clientFactory, serviceManager, netChecker, winRMConfig, broadcaster, srvConfig.ApprovalTTL)
+	sessionHandler := session_handler.NewSessionHandler(authProvider, storage, srvConfig.SSETicketTTL)
 	healthHandler := health_handler.NewHealthHandler(storage, statusCache, netChecker)
 	appHandler := app_handler.NewAppHandler(authProvider, broadcaster)
-	webhooksHAndler := webhooks.NewWebhook(storage)
+	reportHandler := report_handler.NewReportHandler(report.NewBuilder(storage))
+	auditHandler := audit_handler.NewAuditHandler(storage)
+	userHandler := user_handler.NewUserHandler(storage)
+	teamHandler := team_handler.NewTeamHandler(storage)
+	tokenHandler := token_handler.NewTokenHandler(storage)
+	credentialHandler := credential_handler.NewCredentialHandler(storage, fingerprinter, clientPool)
+
+	// подтвержденные действия с критичными службами выполняются тем же путем, что и обычные,
+	// с записью в журнал аудита
+	approvalHandler := approval_handler.NewApprovalHandler(storage, broadcaster, map[string]http.Handler{
+		models.ControlActionStop: middleware.AuditMiddleware(storage, eventSink, models.ControlActionStop)(
+			http.HandlerFunc(controlHandler.ServiceStop)),
+		models.ControlActionRestart: middleware.AuditMiddleware(storage, eventSink, models.ControlActionRestart)(
+			http.HandlerFunc(controlHandler.ServiceRestart)),
+	})
+
+	// эндпоинты входа, выхода и смены пароля нужны только при встроенной аутентификации
+	var localAuthHandler *local_auth_handler.LocalAuthHandler
+	if localAdapter, ok := authProvider.(*local.LocalAdapter); ok {
+		localAuthHandler = local_auth_handler.NewLocalAuthHandler(storage, localAdapter, srvConfig.LocalRefreshTokenTTL, eventSink)
+	}
+
+	// события Keycloak принимаются только при заданном секрете вебхуков
+	var webhooksHandler *webhooks.Webhook
+	if srvConfig.WebhookSecret != "" {
+		webhooksHandler = webhooks.NewWebhook(storage, webhooks.WebhookConfig{
+			Secret:     srvConfig.WebhookSecret,
+			AuthMode:   srvConfig.WebhookAuthMode,
+			AllowedIPs: srvConfig.WebhookAllowedIPs,
+			MaxAge:     srvConfig.WebhookMaxAge,
+		}, eventSink)
+	}
+
+	// эндпоинт /metrics включается только при заданном токене доступа
+	var metricsHandler http.Handler
+	if srvConfig.MetricsToken != "" {
+		metrics.Registry.MustRegister(metrics.NewStatusCollector(statusCache, pgStorage))
+		metricsHandler = metrics.Handler(srvConfig.MetricsToken)
+	}
+
+	// список проверяется при запуске, поэтому ошибка здесь не возникает
+	trustedProxies, _ := netutils.ParseIPNets(srvConfig.TrustedProxies)
 
 	return &HandlersContainer{
-		Storage:         storage,
-		ServerHandler:   serverHandler,
-		ServiceHandler:  serviceHandler,
-		ControlHandler:  controlHandler,
-		SessionHandler:  sessionHandler,
-		HealthHandler:   healthHandler,
-		AppHandler:      appHandler,
-		WebhooksHandler: webhooksHAndler,
+		Storage:           storage,
+		ServerHandler:     serverHandler,
+		ServiceHandler:    serviceHandler,
+		ControlHandler:    controlHandler,
+		SessionHandler:    sessionHandler,
+		HealthHandler:     healthHandler,
+		AppHandler:        appHandler,
+		WebhooksHandler:   webhooksHandler,
+		ReportHandler:     reportHandler,
+		AuditHandler:      auditHandler,
+		UserHandler:       userHandler,
+		TeamHandler:       teamHandler,
+		TokenHandler:      tokenHandler,
+		CredentialHandler: credentialHandler,
+		ApprovalHandler:   approvalHandler,
+		LocalAuthHandler:  localAuthHandler,
+		RolePolicy: models.RolePolicy{
+			DefaultRole: models.Role(srvConfig.DefaultRole),
+			AdminLogins: srvConfig.AdminUsers,
+		},
+		JITProvisioning: srvConfig.JITProvisioning,
+		EventSink:       eventSink,
+		TrustedProxies:  trustedProxies,
+		MetricsHandler:  metricsHandler,
 	}
 }
@@ -0,0 +1,29 @@
+package errs
+
+import "fmt"
+
+// ErrAESKeyNotFound Кастомная ошибка, сообщающая о том, что данные зашифрованы версией AES-ключа,
+// отсутствующей в конфигурации.
+type ErrAESKeyNotFound struct {
+	KeyID int
+	Err   error
+}
+
+func (nf *ErrAESKeyNotFound) Error() string {
+	return fmt.Sprintf("AES-ключ версии %d не задан (AES_KEY_ID/AES_PREVIOUS_KEYS). Ошибка: %v", nf.KeyID, nf.Err)
+}
+
+func (nf *ErrAESKeyNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrAESKeyNotFound(keyID int, err error) *ErrAESKeyNotFound {
+	if err == nil {
+		err = fmt.Errorf("AES-ключ не найден")
+	}
+
+	return &ErrAESKeyNotFound{
+		KeyID: keyID,
+		Err:   err,
+	}
+}
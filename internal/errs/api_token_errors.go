@@ -0,0 +1,49 @@
+package errs
+
+import "fmt"
+
+// ErrAPITokenNotFound Кастомная ошибка, сообщающая о том, что API-токен не найден (отозван, истек или принадлежит другому пользователю).
+type ErrAPITokenNotFound struct {
+	Err     error
+	TokenID int64
+}
+
+func (nf *ErrAPITokenNotFound) Error() string {
+	return fmt.Sprintf("API-токен id=%d не найден. Ошибка: %s", nf.TokenID, nf.Err)
+}
+
+func (nf *ErrAPITokenNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrAPITokenNotFound(tokenID int64, err error) *ErrAPITokenNotFound {
+	if err == nil {
+		err = fmt.Errorf("API-токен не найден")
+	}
+
+	return &ErrAPITokenNotFound{
+		Err:     err,
+		TokenID: tokenID,
+	}
+}
+
+// ErrDuplicatedAPIToken Кастомная ошибка, сообщающая о том, что у пользователя уже есть API-токен с таким названием.
+type ErrDuplicatedAPIToken struct {
+	Name string
+	Err  error
+}
+
+func (da *ErrDuplicatedAPIToken) Error() string {
+	return fmt.Sprintf("API-токен `%s` уже существует. Ошибка: %v", da.Name, da.Err)
+}
+
+func (da *ErrDuplicatedAPIToken) Unwrap() error {
+	return da.Err
+}
+
+func NewErrDuplicatedAPIToken(name string, err error) *ErrDuplicatedAPIToken {
+	return &ErrDuplicatedAPIToken{
+		Name: name,
+		Err:  err,
+	}
+}
@@ -0,0 +1,69 @@
+package errs
+
+import "fmt"
+
+// ErrApprovalNotFound Кастомная ошибка, сообщающая о том, что запрос на подтверждение не найден
+// (был удален вместе со службой или относится к команде, в которой пользователь не состоит).
+type ErrApprovalNotFound struct {
+	Err        error
+	ApprovalID int64
+	UserID     string
+}
+
+func (nf *ErrApprovalNotFound) Error() string {
+	return fmt.Sprintf("Запрос на подтверждение id=%d не найден среди запросов пользователя id=%s. Ошибка: %s", nf.ApprovalID, nf.UserID, nf.Err)
+}
+
+func (nf *ErrApprovalNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrApprovalNotFound(approvalID int64, userID string, err error) *ErrApprovalNotFound {
+	if err == nil {
+		err = fmt.Errorf("запрос на подтверждение не найден")
+	}
+
+	return &ErrApprovalNotFound{
+		Err:        err,
+		ApprovalID: approvalID,
+		UserID:     userID,
+	}
+}
+
+// ErrApprovalNotPending Кастомная ошибка, сообщающая о том, что по запросу уже принято решение или истек срок ожидания.
+type ErrApprovalNotPending struct {
+	ApprovalID int64
+}
+
+func (np *ErrApprovalNotPending) Error() string {
+	return fmt.Sprintf("Запрос на подтверждение id=%d уже рассмотрен или истек", np.ApprovalID)
+}
+
+func NewErrApprovalNotPending(approvalID int64) *ErrApprovalNotPending {
+	return &ErrApprovalNotPending{
+		ApprovalID: approvalID,
+	}
+}
+
+// ErrDuplicatedApproval Кастомная ошибка, сообщающая о том, что такое же действие со службой уже ожидает подтверждения.
+type ErrDuplicatedApproval struct {
+	ServiceID int64
+	Action    string
+	Err       error
+}
+
+func (da *ErrDuplicatedApproval) Error() string {
+	return fmt.Sprintf("Действие `%s` со службой id=%d уже ожидает подтверждения. Ошибка: %v", da.Action, da.ServiceID, da.Err)
+}
+
+func (da *ErrDuplicatedApproval) Unwrap() error {
+	return da.Err
+}
+
+func NewErrDuplicatedApproval(serviceID int64, action string, err error) *ErrDuplicatedApproval {
+	return &ErrDuplicatedApproval{
+		ServiceID: serviceID,
+		Action:    action,
+		Err:       err,
+	}
+}
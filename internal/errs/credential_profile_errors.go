@@ -0,0 +1,73 @@
+package errs
+
+import "fmt"
+
+// ErrCredentialProfileNotFound Кастомная ошибка, сообщающая о том, что профиль учетных данных не найден
+// (был удален или принадлежит другой команде).
+type ErrCredentialProfileNotFound struct {
+	Err       error
+	ProfileID int64
+	TeamID    int64
+}
+
+func (nf *ErrCredentialProfileNotFound) Error() string {
+	return fmt.Sprintf("Профиль учетных данных id=%d не найден в команде id=%d. Ошибка: %v", nf.ProfileID, nf.TeamID, nf.Err)
+}
+
+func (nf *ErrCredentialProfileNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrCredentialProfileNotFound(profileID, teamID int64, err error) *ErrCredentialProfileNotFound {
+	if err == nil {
+		err = fmt.Errorf("профиль учетных данных не найден")
+	}
+
+	return &ErrCredentialProfileNotFound{
+		Err:       err,
+		ProfileID: profileID,
+		TeamID:    teamID,
+	}
+}
+
+// ErrDuplicatedCredentialProfile Кастомная ошибка, сообщающая о том, что профиль с таким названием уже есть в команде.
+type ErrDuplicatedCredentialProfile struct {
+	Name string
+	Err  error
+}
+
+func (dp *ErrDuplicatedCredentialProfile) Error() string {
+	return fmt.Sprintf("Профиль учетных данных %s уже существует. Ошибка: %v", dp.Name, dp.Err)
+}
+
+func (dp *ErrDuplicatedCredentialProfile) Unwrap() error {
+	return dp.Err
+}
+
+func NewErrDuplicatedCredentialProfile(name string, err error) *ErrDuplicatedCredentialProfile {
+	return &ErrDuplicatedCredentialProfile{
+		Name: name,
+		Err:  err,
+	}
+}
+
+// ErrCredentialProfileInUse Кастомная ошибка, сообщающая о том, что к профилю учетных данных привязаны серверы.
+type ErrCredentialProfileInUse struct {
+	ProfileID int64
+	Err       error
+}
+
+func (iu *ErrCredentialProfileInUse) Error() string {
+	return fmt.Sprintf("К профилю учетных данных id=%d привязаны серверы. Ошибка: %v", iu.ProfileID, iu.Err)
+}
+
+func (iu *ErrCredentialProfileInUse) Unwrap() error {
+	return iu.Err
+}
+
+func NewErrCredentialProfileInUse(profileID int64, err error) *ErrCredentialProfileInUse {
+	return &ErrCredentialProfileInUse{
+		ProfileID: profileID,
+		Err:       err,
+	}
+}
@@ -0,0 +1,26 @@
+package errs
+
+import "fmt"
+
+// ErrRefreshTokenNotFound Кастомная ошибка, сообщающая о том, что refresh-токен не найден (использован, отозван или истек).
+type ErrRefreshTokenNotFound struct {
+	Err error
+}
+
+func (nf *ErrRefreshTokenNotFound) Error() string {
+	return fmt.Sprintf("Refresh-токен не найден. Ошибка: %v", nf.Err)
+}
+
+func (nf *ErrRefreshTokenNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrRefreshTokenNotFound(err error) *ErrRefreshTokenNotFound {
+	if err == nil {
+		err = fmt.Errorf("refresh-токен не найден")
+	}
+
+	return &ErrRefreshTokenNotFound{
+		Err: err,
+	}
+}
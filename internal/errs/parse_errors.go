@@ -1,45 +1,29 @@
 package errs
 
-import (
-	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
-)
+import "fmt"
 
-var re = regexp.MustCompile(`(?im)^\s*\[SC\].*?\bFAILED\b(?:\s+with\s+error)?\s+(\d+)\s*:`)
-
-// ParseServiceError ServiceControlError Парсит ошибку из вывода sc команды.
-func ParseServiceError(output string) *ServiceError {
-	output = strings.TrimSpace(output)
-
-	if output == "" {
-		return nil
-	}
-
-	// Ищем паттерн "[SC] ... FAILED XXXX:"
-	matches := re.FindStringSubmatch(output)
-
-	if len(matches) > 1 {
-		code, _ := strconv.Atoi(matches[1])
-		msg := ParseErrorCode(code)
-
-		return NewServiceError(msg, code)
-	}
-
-	return nil
-}
+// CodeServiceDoesNotExist Код ошибки Windows: служба не установлена на сервере.
+const CodeServiceDoesNotExist = 1060
 
 // ParseErrorCode Преобразует код ошибки Windows в понятное описание.
 func ParseErrorCode(code int) string {
 	errorMap := map[int]string{
 		1:    "Invalid function",
 		2:    "File not found",
+		3:    "The system cannot find the path specified",
 		5:    "Access denied",
+		50:   "The request is not supported",
 		87:   "Invalid parameter",
+		123:  "The filename, directory name, or volume label syntax is incorrect",
 		1051: "A stop control has been sent to a service that other running services are dependent on",
 		1052: "The requested control is not valid for this service",
+		1053: "The service did not respond to the start or control request in a timely fashion",
+		1054: "A thread could not be created for the service",
+		1055: "The service database is locked",
+		1056: "An instance of the service is already running",
+		1057: "The account name is invalid or does not exist, or the password is invalid for the account name specified",
 		1058: "The service cannot be started, either because it is disabled or because it has no enabled devices associated with it.",
+		1059: "Circular service dependency was specified",
 		1060: "The specified service does not exist as an installed service",
 		1061: "The service cannot accept control messages at this time",
 		1062: "The service has not been started",
@@ -0,0 +1,43 @@
+package errs
+
+import "fmt"
+
+// ErrSecretNotFound Кастомная ошибка, сообщающая о том, что секрет с учетными данными сервера
+// не найден во внешнем хранилище секретов.
+type ErrSecretNotFound struct {
+	Path string
+	Err  error
+}
+
+func (nf *ErrSecretNotFound) Error() string {
+	return fmt.Sprintf("Секрет %s не найден. Ошибка: %v", nf.Path, nf.Err)
+}
+
+func (nf *ErrSecretNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrSecretNotFound(path string, err error) *ErrSecretNotFound {
+	if err == nil {
+		err = fmt.Errorf("секрет не найден")
+	}
+
+	return &ErrSecretNotFound{
+		Path: path,
+		Err:  err,
+	}
+}
+
+// ErrSecretProviderDisabled Кастомная ошибка, сообщающая о том, что сервер ссылается на секрет,
+// а внешнее хранилище секретов не настроено.
+type ErrSecretProviderDisabled struct {
+	Path string
+}
+
+func (d *ErrSecretProviderDisabled) Error() string {
+	return fmt.Sprintf("Сервер использует секрет %s, но внешнее хранилище секретов не настроено (SECRET_PROVIDER)", d.Path)
+}
+
+func NewErrSecretProviderDisabled(path string) *ErrSecretProviderDisabled {
+	return &ErrSecretProviderDisabled{Path: path}
+}
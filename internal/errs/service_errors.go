@@ -65,3 +65,48 @@ func NewServiceError(message string, code int) *ServiceError {
 func (se *ServiceError) Error() string {
 	return fmt.Sprintf("Код %d, %s", se.Code, se.Message)
 }
+
+// ErrServicePermissionNotFound Кастомная ошибка, сообщающая о том, что правило доступа к службе не найдено.
+type ErrServicePermissionNotFound struct {
+	Err          error
+	ServiceID    int64
+	PermissionID int64
+}
+
+func (nf *ErrServicePermissionNotFound) Error() string {
+	return fmt.Sprintf("Правило доступа id=%d не найдено среди правил службы id=%d. Ошибка: %s", nf.PermissionID, nf.ServiceID, nf.Err)
+}
+
+func (nf *ErrServicePermissionNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrServicePermissionNotFound(serviceID int64, permissionID int64, err error) *ErrServicePermissionNotFound {
+	if err == nil {
+		err = fmt.Errorf("правило доступа не найдено")
+	}
+
+	return &ErrServicePermissionNotFound{
+		Err:          err,
+		ServiceID:    serviceID,
+		PermissionID: permissionID,
+	}
+}
+
+// ErrInvalidServiceName Кастомная ошибка, сообщающая о том, что имя службы содержит недопустимые символы
+// и не может быть передано в команду на удаленном сервере.
+type ErrInvalidServiceName struct {
+	ServiceName string
+	Reason      string
+}
+
+func (in *ErrInvalidServiceName) Error() string {
+	return fmt.Sprintf("недопустимое имя службы %q: %s", in.ServiceName, in.Reason)
+}
+
+func NewErrInvalidServiceName(serviceName, reason string) *ErrInvalidServiceName {
+	return &ErrInvalidServiceName{
+		ServiceName: serviceName,
+		Reason:      reason,
+	}
+}
@@ -0,0 +1,26 @@
+package errs
+
+import "fmt"
+
+// ErrSSETicketNotFound Кастомная ошибка, сообщающая о том, что билет SSE не найден (использован, истек или выдан на другой поток).
+type ErrSSETicketNotFound struct {
+	Err error
+}
+
+func (nf *ErrSSETicketNotFound) Error() string {
+	return fmt.Sprintf("Билет SSE не найден. Ошибка: %v", nf.Err)
+}
+
+func (nf *ErrSSETicketNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrSSETicketNotFound(err error) *ErrSSETicketNotFound {
+	if err == nil {
+		err = fmt.Errorf("билет SSE не найден")
+	}
+
+	return &ErrSSETicketNotFound{
+		Err: err,
+	}
+}
@@ -0,0 +1,118 @@
+package errs
+
+import "fmt"
+
+// ErrTeamNotFound Кастомная ошибка, сообщающая о том, что команда не найдена (была удалена или пользователь не состоит в ней).
+type ErrTeamNotFound struct {
+	Err    error
+	TeamID int64
+	UserID string
+}
+
+func (nf *ErrTeamNotFound) Error() string {
+	return fmt.Sprintf("Команда id=%d не найдена среди команд пользователя id=%s. Ошибка: %s", nf.TeamID, nf.UserID, nf.Err)
+}
+
+func (nf *ErrTeamNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrTeamNotFound(teamID int64, userID string, err error) *ErrTeamNotFound {
+	if err == nil {
+		err = fmt.Errorf("команда не найдена")
+	}
+
+	return &ErrTeamNotFound{
+		Err:    err,
+		TeamID: teamID,
+		UserID: userID,
+	}
+}
+
+// ErrTeamMemberNotFound Кастомная ошибка, сообщающая о том, что пользователь не состоит в команде.
+type ErrTeamMemberNotFound struct {
+	Err    error
+	TeamID int64
+	UserID string
+}
+
+func (nf *ErrTeamMemberNotFound) Error() string {
+	return fmt.Sprintf("Пользователь id=%s не состоит в команде id=%d. Ошибка: %s", nf.UserID, nf.TeamID, nf.Err)
+}
+
+func (nf *ErrTeamMemberNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrTeamMemberNotFound(teamID int64, userID string, err error) *ErrTeamMemberNotFound {
+	if err == nil {
+		err = fmt.Errorf("участник не найден")
+	}
+
+	return &ErrTeamMemberNotFound{
+		Err:    err,
+		TeamID: teamID,
+		UserID: userID,
+	}
+}
+
+// ErrLastTeamAdmin Кастомная ошибка, сообщающая о попытке удалить или понизить последнего администратора команды.
+type ErrLastTeamAdmin struct {
+	TeamID int64
+}
+
+func (la *ErrLastTeamAdmin) Error() string {
+	return fmt.Sprintf("В команде id=%d должен остаться хотя бы один администратор", la.TeamID)
+}
+
+func NewErrLastTeamAdmin(teamID int64) *ErrLastTeamAdmin {
+	return &ErrLastTeamAdmin{
+		TeamID: teamID,
+	}
+}
+
+// ErrDuplicatedInvitation Кастомная ошибка, сообщающая о том, что пользователь уже приглашен в команду или состоит в ней.
+type ErrDuplicatedInvitation struct {
+	Login string
+	Err   error
+}
+
+func (di *ErrDuplicatedInvitation) Error() string {
+	return fmt.Sprintf("Пользователь `%s` уже приглашен в команду или состоит в ней. Ошибка: %v", di.Login, di.Err)
+}
+
+func (di *ErrDuplicatedInvitation) Unwrap() error {
+	return di.Err
+}
+
+func NewErrDuplicatedInvitation(login string, err error) *ErrDuplicatedInvitation {
+	return &ErrDuplicatedInvitation{
+		Login: login,
+		Err:   err,
+	}
+}
+
+// ErrInvitationNotFound Кастомная ошибка, сообщающая о том, что приглашение не найдено (принято, отклонено, отозвано или истекло).
+type ErrInvitationNotFound struct {
+	Err          error
+	InvitationID int64
+}
+
+func (nf *ErrInvitationNotFound) Error() string {
+	return fmt.Sprintf("Приглашение id=%d не найдено. Ошибка: %s", nf.InvitationID, nf.Err)
+}
+
+func (nf *ErrInvitationNotFound) Unwrap() error {
+	return nf.Err
+}
+
+func NewErrInvitationNotFound(invitationID int64, err error) *ErrInvitationNotFound {
+	if err == nil {
+		err = fmt.Errorf("приглашение не найдено")
+	}
+
+	return &ErrInvitationNotFound{
+		Err:          err,
+		InvitationID: invitationID,
+	}
+}
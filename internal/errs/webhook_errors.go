@@ -0,0 +1,24 @@
+package errs
+
+import "fmt"
+
+// ErrWebhookEventDuplicate Кастомная ошибка, сообщающая о том, что событие вебхука уже обработано.
+type ErrWebhookEventDuplicate struct {
+	EventID string
+	Err     error
+}
+
+func (d *ErrWebhookEventDuplicate) Error() string {
+	return fmt.Sprintf("Событие %s уже обработано. Ошибка: %v", d.EventID, d.Err)
+}
+
+func (d *ErrWebhookEventDuplicate) Unwrap() error {
+	return d.Err
+}
+
+func NewErrWebhookEventDuplicate(eventID string, err error) *ErrWebhookEventDuplicate {
+	return &ErrWebhookEventDuplicate{
+		EventID: eventID,
+		Err:     err,
+	}
+}
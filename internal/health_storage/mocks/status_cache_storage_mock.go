@@ -61,6 +61,20 @@ func (mr *MockStatusCacheStorageMockRecorder) Get(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatusCacheStorage)(nil).Get), arg0)
 }
 
+// GetAll mocks base method.
+func (m *MockStatusCacheStorage) GetAll() []models.ServerStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]models.ServerStatus)
+	return ret0
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockStatusCacheStorageMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockStatusCacheStorage)(nil).GetAll))
+}
+
 // GetAllServerStatusesByUser mocks base method.
 func (m *MockStatusCacheStorage) GetAllServerStatusesByUser(arg0 string) []models.ServerStatus {
 	m.ctrl.T.Helper()
@@ -76,9 +90,11 @@ func (mr *MockStatusCacheStorageMockRecorder) GetAllServerStatusesByUser(arg0 in
 }
 
 // Set mocks base method.
-func (m *MockStatusCacheStorage) Set(arg0 models.ServerStatus) {
+func (m *MockStatusCacheStorage) Set(arg0 models.ServerStatus) bool {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Set", arg0)
+	ret := m.ctrl.Call(m, "Set", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
 }
 
 // Set indicates an expected call of Set.
@@ -22,16 +22,19 @@ func NewStatusCache() *StatusCache {
 }
 
 // Set Метод для сохранения статуса сервера в in-memory хранилище.
-func (sc *StatusCache) Set(s models.ServerStatus) {
+// Возвращает true, если статус сервера изменился (или сервер добавлен впервые).
+func (sc *StatusCache) Set(s models.ServerStatus) bool {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
 	old, ok := sc.cache[s.ServerID]
 	if ok && old.Status == s.Status {
-		return
+		return false
 	}
 
 	sc.cache[s.ServerID] = s
+
+	return true
 }
 
 // Get Метод для извлечения статуса сервера из in-memory хранилище.
@@ -67,3 +70,17 @@ func (sc *StatusCache) GetAllServerStatusesByUser(userID string) []models.Server
 
 	return res
 }
+
+// GetAll Получение статусов всех серверов.
+func (sc *StatusCache) GetAll() []models.ServerStatus {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	res := make([]models.ServerStatus, 0, len(sc.cache))
+
+	for _, s := range sc.cache {
+		res = append(res, s)
+	}
+
+	return res
+}
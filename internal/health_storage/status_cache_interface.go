@@ -5,8 +5,9 @@ import "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 //go:generate mockgen -destination=mocks/status_cache_storage_mock.go -package=mocks . StatusCacheStorage
 
 type StatusCacheStorage interface {
-	Set(s models.ServerStatus)
+	Set(s models.ServerStatus) bool
 	Get(id int64) (models.ServerStatus, bool)
 	Delete(id int64)
 	GetAllServerStatusesByUser(userID string) []models.ServerStatus
+	GetAll() []models.ServerStatus
 }
@@ -89,6 +89,33 @@ func TestStatusCacheSetOverwrite(t *testing.T) {
 	assert.Equal(t, "192.168.2.20", cache.cache[1].Address)
 }
 
+// TestStatusCacheSetReportsChange Проверяет, что Set сообщает о смене статуса сервера.
+func TestStatusCacheSetReportsChange(t *testing.T) {
+	cache := NewStatusCache()
+
+	// первое добавление — это смена статуса
+	assert.True(t, cache.Set(createTestServerStatus(1, "192.168.1.10", models.StatusOK)))
+
+	// повторная запись того же статуса не является сменой
+	assert.False(t, cache.Set(createTestServerStatus(1, "192.168.1.10", models.StatusOK)))
+
+	// новый статус — смена
+	assert.True(t, cache.Set(createTestServerStatus(1, "192.168.1.10", models.StatusDegraded)))
+}
+
+// TestStatusCacheGetAll Проверяет получение статусов всех серверов.
+func TestStatusCacheGetAll(t *testing.T) {
+	cache := NewStatusCache()
+	assert.Empty(t, cache.GetAll())
+
+	status1 := createTestServerStatus(1, "192.168.1.10", models.StatusOK)
+	status2 := createTestServerStatus(2, "192.168.1.20", models.StatusUnreachable)
+	cache.Set(status1)
+	cache.Set(status2)
+
+	assert.ElementsMatch(t, []models.ServerStatus{status1, status2}, cache.GetAll())
+}
+
 // TestStatusCacheSetDifferentAddressSameID Проверяет обновление адреса для одного ServerID.
 func TestStatusCacheSetDifferentAddressSameID(t *testing.T) {
 	// подготавливаем кэш с разными адресами для одного id
@@ -18,6 +18,7 @@ func WarmUpStatusCache(ctx context.Context, storage storage.WorkerStorage, statu
 		statusCache.Set(models.ServerStatus{
 			ServerID: server.ServerID,
 			UserID:   server.UserID,
+			TeamID:   server.TeamID,
 			Address:  server.Address,
 		})
 	}
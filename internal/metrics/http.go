@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// statusRecorder Обертка над http.ResponseWriter для получения кода ответа.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush Необходим для работы SSE через обертку.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// HTTPMiddleware Middleware для сбора времени обработки HTTP-запросов.
+//
+// В метку route пишется шаблон маршрута chi (например, /api/user/servers/{serverID}),
+// а не фактический путь, чтобы не плодить серии по идентификаторам.
+func HTTPMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		start := time.Now()
+		h.ServeHTTP(rec, r)
+
+		route := "unknown"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		HTTPRequestDuration.
+			WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// Handler Возвращает обработчик /metrics, защищенный токеном.
+// Токен передается в заголовке "Authorization: Bearer <token>".
+func Handler(token string) http.Handler {
+	metricsHandler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		metricsHandler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "swsm"
+
+// Результаты операций в метках метрик.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry Реестр метрик приложения, отдаваемых на /metrics.
+// Используется собственный реестр вместо prometheus.DefaultRegisterer,
+// чтобы на /metrics попадали только явно зарегистрированные метрики.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// ControlActions Количество действий управления службами (start/stop/restart) по результату.
+	ControlActions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "control_actions_total",
+		Help:      "Количество действий управления службами по типу действия и результату.",
+	}, []string{"action", "result"})
+
+	// WinRMCommandDuration Время выполнения команд на удаленных серверах через WinRM.
+	WinRMCommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "winrm_command_duration_seconds",
+		Help:      "Время выполнения команд через WinRM.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"result"})
+
+	// HTTPRequestDuration Время обработки HTTP-запросов по маршрутам.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Время обработки HTTP-запросов.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// WorkerPoolQueueDepth Количество задач в очереди пула воркеров проверки статусов серверов.
+	WorkerPoolQueueDepth = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "status_worker_pool_queue_depth",
+		Help:      "Количество задач в очереди пула воркеров проверки статусов серверов.",
+	})
+
+	// WorkerPoolSkipped Количество задач, не принятых пулом воркеров (очередь переполнена или пул остановлен).
+	WorkerPoolSkipped = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "status_worker_pool_skipped_total",
+		Help:      "Количество задач, не принятых пулом воркеров проверки статусов серверов.",
+	}, []string{"reason"})
+
+	// SIEMEvents Количество событий безопасности, переданных в syslog (sent) или отброшенных (dropped).
+	SIEMEvents = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "siem_events_total",
+		Help:      "Количество событий безопасности, переданных в syslog или отброшенных.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Result Возвращает метку результата операции по ее ошибке.
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+
+	return ResultSuccess
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	statusCacheStorageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// TestResult Проверяет метку результата операции.
+func TestResult(t *testing.T) {
+	assert.Equal(t, ResultSuccess, Result(nil))
+	assert.Equal(t, ResultFailure, Result(errors.New("err")))
+}
+
+// TestStatusCollector Проверяет сбор статусов серверов и служб.
+func TestStatusCollector(t *testing.T) {
+	tests := []struct {
+		name      string
+		setupMock func(c *statusCacheStorageMocks.MockStatusCacheStorage, s *storageMocks.MockStorage)
+		expected  string
+	}{
+		{
+			name: "статусы серверов и служб",
+			setupMock: func(c *statusCacheStorageMocks.MockStatusCacheStorage, s *storageMocks.MockStorage) {
+				c.EXPECT().GetAll().Return([]models.ServerStatus{
+					{ServerID: 1, UserID: "user-1", TeamID: 10, Address: "10.0.0.1", Status: models.StatusDegraded},
+				})
+				s.EXPECT().ListServicesStates(gomock.Any()).Return([]*models.ServiceState{
+					{ServiceID: 5, ServerID: 1, TeamID: 10, ServiceName: "spooler", Status: "Работает"},
+					{ServiceID: 6, ServerID: 1, TeamID: 10, ServiceName: "bits", Status: "Остановлена"},
+				}, nil)
+			},
+			expected: `
+# HELP swsm_server_status Текущий статус сервера: 1 для текущего статуса, 0 для остальных.
+# TYPE swsm_server_status gauge
+swsm_server_status{address="10.0.0.1",server_id="1",status="Degraded",team_id="10"} 1
+swsm_server_status{address="10.0.0.1",server_id="1",status="OK",team_id="10"} 0
+swsm_server_status{address="10.0.0.1",server_id="1",status="Unknown",team_id="10"} 0
+swsm_server_status{address="10.0.0.1",server_id="1",status="Unreachable",team_id="10"} 0
+# HELP swsm_service_up Состояние службы: 1 — служба работает, 0 — в любом другом состоянии.
+# TYPE swsm_service_up gauge
+swsm_service_up{server_id="1",service_id="5",service_name="spooler",status="Работает",team_id="10"} 1
+swsm_service_up{server_id="1",service_id="6",service_name="bits",status="Остановлена",team_id="10"} 0
+`,
+		},
+		{
+			name: "непроверенный сервер и ошибка хранилища",
+			setupMock: func(c *statusCacheStorageMocks.MockStatusCacheStorage, s *storageMocks.MockStorage) {
+				c.EXPECT().GetAll().Return([]models.ServerStatus{
+					{ServerID: 2, UserID: "user-1", TeamID: 10, Address: "10.0.0.2"},
+				})
+				s.EXPECT().ListServicesStates(gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expected: `
+# HELP swsm_server_status Текущий статус сервера: 1 для текущего статуса, 0 для остальных.
+# TYPE swsm_server_status gauge
+swsm_server_status{address="10.0.0.2",server_id="2",status="Degraded",team_id="10"} 0
+swsm_server_status{address="10.0.0.2",server_id="2",status="OK",team_id="10"} 0
+swsm_server_status{address="10.0.0.2",server_id="2",status="Unknown",team_id="10"} 1
+swsm_server_status{address="10.0.0.2",server_id="2",status="Unreachable",team_id="10"} 0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cache := statusCacheStorageMocks.NewMockStatusCacheStorage(ctrl)
+			storage := storageMocks.NewMockStorage(ctrl)
+			tt.setupMock(cache, storage)
+
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(NewStatusCollector(cache, storage))
+
+			err := testutil.GatherAndCompare(registry, strings.NewReader(tt.expected), "swsm_server_status", "swsm_service_up")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestHTTPMiddleware Проверяет сбор времени обработки запросов по шаблону маршрута.
+func TestHTTPMiddleware(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(HTTPMiddleware)
+	router.Get("/servers/{serverID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	before := testutil.CollectAndCount(HTTPRequestDuration)
+
+	r := httptest.NewRequest(http.MethodGet, "/servers/42", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// серия создается по шаблону маршрута, а не по фактическому пути
+	assert.Equal(t, before+1, testutil.CollectAndCount(HTTPRequestDuration))
+
+	observer, err := HTTPRequestDuration.GetMetricWithLabelValues(http.MethodGet, "/servers/{serverID}", "404")
+	require.NoError(t, err)
+	assert.NotNil(t, observer)
+}
+
+// TestHandler Проверяет защиту эндпоинта /metrics токеном.
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"верный токен", "Bearer secret", http.StatusOK},
+		{"неверный токен", "Bearer wrong", http.StatusUnauthorized},
+		{"без заголовка", "", http.StatusUnauthorized},
+		{"не Bearer схема", "Basic secret", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			Handler("secret").ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "go_goroutines")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// все возможные статусы сервера, для каждого отдается отдельная серия (1 — текущий статус, 0 — нет)
+var serverStatuses = []models.Status{models.StatusOK, models.StatusDegraded, models.StatusUnreachable, models.StatusUnknown}
+
+var (
+	serverStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "server_status"),
+		"Текущий статус сервера: 1 для текущего статуса, 0 для остальных.",
+		[]string{"server_id", "team_id", "address", "status"}, nil,
+	)
+
+	serviceUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "service_up"),
+		"Состояние службы: 1 — служба работает, 0 — в любом другом состоянии.",
+		[]string{"server_id", "service_id", "team_id", "service_name", "status"}, nil,
+	)
+)
+
+// StatusCollector Собирает статусы серверов (из in-memory кэша) и служб (из хранилища)
+// в момент запроса метрик.
+type StatusCollector struct {
+	statusCache health_storage.StatusCacheStorage
+	storage     storage.Storage
+	timeout     time.Duration
+}
+
+// NewStatusCollector Конструктор StatusCollector.
+func NewStatusCollector(statusCache health_storage.StatusCacheStorage, storage storage.Storage) *StatusCollector {
+	return &StatusCollector{
+		statusCache: statusCache,
+		storage:     storage,
+		timeout:     5 * time.Second,
+	}
+}
+
+// Describe Реализация prometheus.Collector.
+func (c *StatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- serverStatusDesc
+	ch <- serviceUpDesc
+}
+
+// Collect Реализация prometheus.Collector.
+func (c *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.statusCache.GetAll() {
+		// сервер, который еще ни разу не проверялся воркером, считаем Unknown
+		current := s.Status
+		if current == "" {
+			current = models.StatusUnknown
+		}
+
+		for _, status := range serverStatuses {
+			var v float64
+			if status == current {
+				v = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(serverStatusDesc, prometheus.GaugeValue, v,
+				strconv.FormatInt(s.ServerID, 10), strconv.FormatInt(s.TeamID, 10), s.Address, status.String())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	states, err := c.storage.ListServicesStates(ctx)
+	if err != nil {
+		logger.Log.Warn("Не удалось получить статусы служб для метрик", logger.String("err", err.Error()))
+		return
+	}
+
+	running := utils.GetStatusByINT(utils.ServiceRunning)
+
+	for _, s := range states {
+		var v float64
+		if s.Status == running {
+			v = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(serviceUpDesc, prometheus.GaugeValue, v,
+			strconv.FormatInt(s.ServerID, 10), strconv.FormatInt(s.ServiceID, 10), strconv.FormatInt(s.TeamID, 10), s.ServiceName, s.Status)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// APITokenServerScopeMiddleware Ограничивает доступ по API-токену серверами, указанными при его создании.
+// Для запросов с JWT ничего не проверяет. Должен идти после ParseServerIDMiddleware.
+func APITokenServerScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		creds := models.GetContextCreds(r.Context())
+
+		if creds.APIToken != nil && !creds.APIToken.AllowsServer(creds.ServerID) {
+			logger.Log.Debug("Сервер недоступен по API-токену",
+				logger.Int64("serverID", creds.ServerID),
+				logger.Int64("tokenID", creds.APIToken.ID))
+			response.ErrorJSON(w, http.StatusNotFound, "Сервер не найден")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RejectAPITokenMiddleware Запрещает действие по API-токену: маршрут доступен только самому пользователю
+// (сессии, членство в командах и приглашения). Область read сводит роль токена к viewer,
+// которой такие маршруты доступны, поэтому без этой проверки токен только для чтения мог бы их изменять.
+func RejectAPITokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		creds := models.GetContextCreds(r.Context())
+
+		if creds.APIToken != nil {
+			logger.Log.Debug("Действие недоступно по API-токену",
+				logger.String("path", r.URL.Path),
+				logger.Int64("tokenID", creds.APIToken.ID))
+			response.ErrorJSON(w, http.StatusForbidden, "Действие недоступно по API-токену")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestAPITokenServerScopeMiddleware Проверяет ограничение доступа по API-токену списком серверов.
+func TestAPITokenServerScopeMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          *models.APIToken
+		expectedStatus int
+	}{
+		{
+			name:           "запрос с JWT",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "токен без ограничения серверов",
+			token:          &models.APIToken{ID: 1},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "сервер входит в список токена",
+			token:          &models.APIToken{ID: 1, ServerIDs: []int64{7, 100}, Restricted: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "сервер не входит в список токена",
+			token:          &models.APIToken{ID: 1, ServerIDs: []int64{7}, Restricted: true},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "все серверы ограниченного токена удалены",
+			token:          &models.APIToken{ID: 1, ServerIDs: []int64{}, Restricted: true},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), contextkeys.ServerID, int64(100))
+			if tt.token != nil {
+				ctx = context.WithValue(ctx, contextkeys.APIToken, tt.token)
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/servers/100", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			APITokenServerScopeMiddleware(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestRejectAPITokenMiddleware Проверяет запрет действий по API-токену.
+func TestRejectAPITokenMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          *models.APIToken
+		expectedStatus int
+	}{
+		{
+			name:           "запрос с JWT",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "токен с областью read",
+			token:          &models.APIToken{ID: 1, Scope: models.APITokenScopeRead},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "токен с областью control",
+			token:          &models.APIToken{ID: 1, Scope: models.APITokenScopeControl},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != nil {
+				ctx = context.WithValue(ctx, contextkeys.APIToken, tt.token)
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/teams", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			RejectAPITokenMiddleware(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// maxAuditErrorBody Максимальный размер тела ответа с ошибкой, из которого извлекается текст ошибки.
+const maxAuditErrorBody = 4096
+
+// auditResponseWriter Подменяет http.ResponseWriter для получения кода ответа
+// и текста ошибки, возвращенной хендлером.
+type auditResponseWriter struct {
+	*LoggingResponseWriter
+	errBody bytes.Buffer
+}
+
+func (a *auditResponseWriter) Write(b []byte) (int, error) {
+	// тело сохраняем только для ответов с ошибкой
+	if a.responseData.status >= http.StatusBadRequest {
+		if free := maxAuditErrorBody - a.errBody.Len(); free > 0 {
+			a.errBody.Write(b[:min(len(b), free)])
+		}
+	}
+
+	return a.LoggingResponseWriter.Write(b)
+}
+
+// AuditMiddleware Записывает в журнал аудита действие пользователя с сервером или службой:
+// кто, что и над каким объектом сделал, с какого адреса, результат, текст ошибки и длительность.
+// Действие считается успешным, если хендлер ответил кодом < 400.
+//
+// Описание существующего объекта (сервера или службы) определяется до вызова хендлера,
+// чтобы сохраниться и при его удалении. Хендлеры, создающие объекты, дополняют запись
+// через models.SetAuditTarget.
+//
+// Запись также передается в SIEM (sink), независимо от успеха записи в БД.
+// Ошибка записи в журнал не влияет на ответ пользователю — она только логируется.
+func AuditMiddleware(storage storage.Storage, sink siem.Sink, action string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			creds := models.GetContextCreds(r.Context())
+			if creds.UserID == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			entry := &models.AuditEntry{
+				UserID:    creds.UserID,
+				Login:     creds.Login,
+				Action:    action,
+				ServerID:  creds.ServerID,
+				ServiceID: creds.ServiceID,
+				Target:    auditTarget(r.Context(), storage, creds),
+				IP:        ClientIP(r),
+
+				APITokenID: creds.APITokenID(),
+			}
+
+			data := responseData{}
+
+			aw := &auditResponseWriter{
+				LoggingResponseWriter: &LoggingResponseWriter{
+					ResponseWriter: w,
+					responseData:   &data,
+				},
+			}
+
+			start := time.Now()
+			h.ServeHTTP(aw, r.WithContext(models.WithAuditEntry(r.Context(), entry)))
+			entry.DurationMs = time.Since(start).Milliseconds()
+
+			// если хендлер не вызывал WriteHeader явно — ответ 200
+			status := data.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			entry.Success = status < http.StatusBadRequest
+			if !entry.Success {
+				entry.Error = auditErrorMessage(aw.errBody.Bytes(), status)
+			}
+
+			// хендлер мог заменить действие (models.SetAuditAction)
+			if models.IsControlAction(entry.Action) {
+				result := metrics.ResultSuccess
+				if !entry.Success {
+					result = metrics.ResultFailure
+				}
+				metrics.ControlActions.WithLabelValues(entry.Action, result).Inc()
+			}
+
+			sink.Export(siem.NewAuditEvent(entry))
+
+			// запрос мог быть уже отменен клиентом, а запись в журнал нужна все равно
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
+			defer cancel()
+
+			if err := storage.AddAuditEntry(ctx, entry); err != nil {
+				logger.Log.Warn("Не удалось записать действие в журнал аудита",
+					logger.String("action", entry.Action),
+					logger.String("login", creds.Login),
+					logger.String("err", err.Error()))
+			}
+		})
+	}
+}
+
+// auditTarget Возвращает описание сервера или службы из запроса для журнала аудита.
+// Если объект не найден — пустую строку (хендлер в этом случае вернет ошибку сам).
+func auditTarget(ctx context.Context, storage storage.Storage, creds *models.ContextCredentials) string {
+	switch {
+	case creds.ServiceID != 0:
+		service, err := storage.GetService(ctx, creds.ServerID, creds.ServiceID, creds.UserID)
+		if err != nil {
+			logger.Log.Debug("Не удалось получить службу для журнала аудита", logger.String("err", err.Error()))
+			return ""
+		}
+		return models.ServiceAuditTarget(service.DisplayedName, service.ServiceName)
+
+	case creds.ServerID != 0:
+		server, err := storage.GetServer(ctx, creds.ServerID, creds.UserID)
+		if err != nil {
+			logger.Log.Debug("Не удалось получить сервер для журнала аудита", logger.String("err", err.Error()))
+			return ""
+		}
+		return models.ServerAuditTarget(server.Name, server.Address)
+	}
+
+	return ""
+}
+
+// auditErrorMessage Извлекает текст ошибки из ответа хендлера (response.APIError).
+func auditErrorMessage(body []byte, status int) string {
+	var apiErr response.APIError
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+
+	return http.StatusText(status)
+}
+
+// ClientIP Возвращает адрес клиента — адрес соединения. За доверенным обратным прокси
+// его заменяет адресом из заголовков TrustedProxyMiddleware, сами заголовки здесь не читаются.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	siemMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/siem/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// TestAuditMiddleware_ControlActions Проверяет запись действий управления службами в журнал аудита.
+func TestAuditMiddleware_ControlActions(t *testing.T) {
+	tests := []struct {
+		name          string
+		withCreds     bool
+		handlerStatus int // 0 - хендлер не вызывает WriteHeader
+		handlerError  string
+		storageErr    error
+		expectRecord  bool
+		expectSuccess bool
+		expectError   string
+	}{
+		{
+			name:          "успешное действие",
+			withCreds:     true,
+			handlerStatus: http.StatusOK,
+			expectRecord:  true,
+			expectSuccess: true,
+		},
+		{
+			name:          "хендлер не вызывал WriteHeader",
+			withCreds:     true,
+			handlerStatus: 0,
+			expectRecord:  true,
+			expectSuccess: true,
+		},
+		{
+			name:          "неуспешное действие - текст ошибки из ответа",
+			withCreds:     true,
+			handlerStatus: http.StatusBadGateway,
+			handlerError:  "Сервер недоступен",
+			expectRecord:  true,
+			expectSuccess: false,
+			expectError:   "Сервер недоступен",
+		},
+		{
+			name:          "неуспешное действие без тела ответа",
+			withCreds:     true,
+			handlerStatus: http.StatusConflict,
+			expectRecord:  true,
+			expectSuccess: false,
+			expectError:   "Conflict",
+		},
+		{
+			name:          "ошибка записи журнала не влияет на ответ",
+			withCreds:     true,
+			handlerStatus: http.StatusOK,
+			storageErr:    errors.New("db error"),
+			expectRecord:  true,
+			expectSuccess: true,
+		},
+		{
+			name:          "нет данных в контексте - запись не производится",
+			withCreds:     false,
+			handlerStatus: http.StatusOK,
+			expectRecord:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			mockSink := siemMocks.NewMockSink(ctrl)
+
+			if tt.expectRecord {
+				// событие передается в SIEM даже при ошибке записи в БД
+				mockSink.EXPECT().Export(gomock.Any()).Do(func(event *siem.Event) {
+					assert.Equal(t, siem.EventTypeAudit, event.Type)
+					assert.Equal(t, "tester", event.Login)
+					assert.Equal(t, models.ControlActionStop, event.Action)
+					assert.Equal(t, tt.expectSuccess, event.Success)
+				})
+
+				mockStorage.EXPECT().GetService(gomock.Any(), int64(1), int64(2), "user-123").
+					Return(&models.Service{DisplayedName: "Диспетчер печати", ServiceName: "spooler"}, nil)
+
+				mockStorage.EXPECT().
+					AddAuditEntry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+						assert.Equal(t, "user-123", entry.UserID)
+						assert.Equal(t, "tester", entry.Login)
+						assert.Equal(t, models.ControlActionStop, entry.Action)
+						assert.Equal(t, int64(1), entry.ServerID)
+						assert.Equal(t, int64(2), entry.ServiceID)
+						assert.Equal(t, "Диспетчер печати (spooler)", entry.Target)
+						assert.Equal(t, "192.0.2.1", entry.IP)
+						assert.Equal(t, tt.expectSuccess, entry.Success)
+						assert.Equal(t, tt.expectError, entry.Error)
+						assert.GreaterOrEqual(t, entry.DurationMs, int64(0))
+						return tt.storageErr
+					})
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case tt.handlerError != "":
+					response.ErrorJSON(w, tt.handlerStatus, tt.handlerError)
+				case tt.handlerStatus != 0:
+					w.WriteHeader(tt.handlerStatus)
+				}
+			})
+
+			handler := AuditMiddleware(mockStorage, mockSink, models.ControlActionStop)(next)
+
+			r := httptest.NewRequest(http.MethodPost, "/stop", nil)
+			if tt.withCreds {
+				ctx := context.WithValue(r.Context(), contextkeys.UserID, "user-123")
+				ctx = context.WithValue(ctx, contextkeys.Login, "tester")
+				ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
+				ctx = context.WithValue(ctx, contextkeys.ServiceID, int64(2))
+				r = r.WithContext(ctx)
+			}
+
+			result := metrics.ResultSuccess
+			if !tt.expectSuccess {
+				result = metrics.ResultFailure
+			}
+			counter := metrics.ControlActions.WithLabelValues(models.ControlActionStop, result)
+			before := testutil.ToFloat64(counter)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			// действие учитывается в метриках только вместе с записью в журнал
+			if tt.expectRecord {
+				assert.Equal(t, before+1, testutil.ToFloat64(counter))
+			} else {
+				assert.Equal(t, before, testutil.ToFloat64(counter))
+			}
+
+			expectedStatus := tt.handlerStatus
+			if expectedStatus == 0 {
+				expectedStatus = http.StatusOK
+			}
+			assert.Equal(t, expectedStatus, w.Code)
+
+			if tt.handlerError != "" {
+				assert.Contains(t, w.Body.String(), tt.handlerError)
+			}
+		})
+	}
+}
+
+// TestAuditMiddleware_Targets Проверяет определение объекта действия для журнала аудита.
+func TestAuditMiddleware_Targets(t *testing.T) {
+	tests := []struct {
+		name         string
+		action       string
+		serverID     int64
+		serviceID    int64
+		setupMock    func(m *mocks.MockStorage)
+		handler      http.HandlerFunc
+		expectServer int64
+		expectTarget string
+	}{
+		{
+			name:     "удаление сервера - описание до удаления",
+			action:   models.AuditActionDelServer,
+			serverID: 1,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetServer(gomock.Any(), int64(1), "user-123").
+					Return(&models.Server{Name: "srv1", Address: "10.0.0.1"}, nil)
+			},
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			expectServer: 1,
+			expectTarget: "srv1 (10.0.0.1)",
+		},
+		{
+			name:     "сервер не найден - пустое описание",
+			action:   models.AuditActionEditServer,
+			serverID: 1,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetServer(gomock.Any(), int64(1), "user-123").Return(nil, errors.New("not found"))
+			},
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			expectServer: 1,
+		},
+		{
+			name:      "добавление сервера - хендлер дополняет запись",
+			action:    models.AuditActionAddServer,
+			setupMock: func(m *mocks.MockStorage) {},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				models.SetAuditTarget(r.Context(), 0, 0, "srv2 (10.0.0.2)")
+				models.SetAuditTarget(r.Context(), 7, 0, "")
+				w.WriteHeader(http.StatusCreated)
+			},
+			expectServer: 7,
+			expectTarget: "srv2 (10.0.0.2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			mockStorage.EXPECT().AddAuditEntry(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+					assert.Equal(t, tt.action, entry.Action)
+					assert.Equal(t, tt.expectServer, entry.ServerID)
+					assert.Equal(t, tt.expectTarget, entry.Target)
+					return nil
+				})
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			ctx := context.WithValue(r.Context(), contextkeys.UserID, "user-123")
+			if tt.serverID != 0 {
+				ctx = context.WithValue(ctx, contextkeys.ServerID, tt.serverID)
+			}
+			r = r.WithContext(ctx)
+
+			AuditMiddleware(mockStorage, siem.NewNoopSink(), tt.action)(tt.handler).ServeHTTP(httptest.NewRecorder(), r)
+		})
+	}
+}
+
+// TestAuditMiddleware_ReplacedAction Проверяет, что действие, замененное хендлером
+// (запрос на подтверждение вместо остановки критичной службы), не учитывается в метриках управления.
+func TestAuditMiddleware_ReplacedAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().AddAuditEntry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+			assert.Equal(t, models.AuditActionRequestApproval, entry.Action)
+			assert.True(t, entry.Success)
+			return nil
+		})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		models.SetAuditAction(r.Context(), models.AuditActionRequestApproval)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/stop", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextkeys.UserID, "user-123"))
+
+	counter := metrics.ControlActions.WithLabelValues(models.ControlActionStop, metrics.ResultSuccess)
+	before := testutil.ToFloat64(counter)
+
+	AuditMiddleware(mockStorage, siem.NewNoopSink(), models.ControlActionStop)(next).ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, before, testutil.ToFloat64(counter))
+}
+
+// TestClientIP Проверяет определение адреса клиента.
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:51234"
+	assert.Equal(t, "10.0.0.5", ClientIP(r))
+
+	// заголовки прокси учитывает только TrustedProxyMiddleware
+	r.Header.Set("X-Real-IP", "203.0.113.7")
+	assert.Equal(t, "10.0.0.5", ClientIP(r))
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "pipe"
+	assert.Equal(t, "pipe", ClientIP(r))
+}
@@ -1,8 +1,9 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
-	"strings"
+	"net/url"
 )
 
 // CorsMiddleware - middleware для поддержки CORS с cookie аутентификацией
@@ -27,9 +28,7 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Разрешить весь диапазон локальной сети
-		if !isAllowed && (strings.HasPrefix(origin, "http://192.168.") ||
-			strings.HasPrefix(origin, "http://10.") ||
-			strings.HasPrefix(origin, "http://172.")) {
+		if !isAllowed && isPrivateNetworkOrigin(origin) {
 			isAllowed = true
 		}
 
@@ -41,7 +40,7 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		// Разрешаем нужные заголовки
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Authorization, X-Requested-With, "+CSRFHeader)
 
 		// Разрешаем HTTP методы
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
@@ -61,3 +60,16 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// isPrivateNetworkOrigin Проверяет, что origin - http-адрес из частной сети IPv4 (10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16).
+// Хост разбирается как IP-адрес, поэтому доменные имена вроде 172.example.com не подходят.
+func isPrivateNetworkOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != "http" || u.User != nil || (u.Path != "" && u.Path != "/") {
+		return false
+	}
+
+	ip := net.ParseIP(u.Hostname())
+
+	return ip != nil && ip.To4() != nil && ip.IsPrivate()
+}
@@ -88,6 +88,13 @@ func TestCorsMiddlewareAllowedOrigins(t *testing.T) {
 			wantHeaderSet:   true,
 			wantNextCalled:  true,
 		},
+		{
+			name:            "192.168.1.10 с портом",
+			origin:          "http://192.168.1.10:8080",
+			wantAllowOrigin: "http://192.168.1.10:8080",
+			wantHeaderSet:   true,
+			wantNextCalled:  true,
+		},
 		{
 			name:            "172.31.255.255",
 			origin:          "http://172.31.255.255",
@@ -164,6 +171,36 @@ func TestCorsMiddlewareDisallowedOrigins(t *testing.T) {
 			wantAllowOrigin: "",
 			wantNextCalled:  true,
 		},
+		{
+			name:            "домен, начинающийся с 172.",
+			origin:          "http://172.evil.com",
+			wantAllowOrigin: "",
+			wantNextCalled:  true,
+		},
+		{
+			name:            "домен, начинающийся с 10.",
+			origin:          "http://10.0.0.1.evil.com",
+			wantAllowOrigin: "",
+			wantNextCalled:  true,
+		},
+		{
+			name:            "домен, начинающийся с 192.168.",
+			origin:          "http://192.168.1.1.nip.example",
+			wantAllowOrigin: "",
+			wantNextCalled:  true,
+		},
+		{
+			name:            "172.32.x.x вне частного диапазона",
+			origin:          "http://172.32.0.1",
+			wantAllowOrigin: "",
+			wantNextCalled:  true,
+		},
+		{
+			name:            "учетные данные в origin",
+			origin:          "http://evil.com@192.168.1.1",
+			wantAllowOrigin: "",
+			wantNextCalled:  true,
+		},
 	}
 
 	for _, tt := range tests {
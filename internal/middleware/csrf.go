@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+)
+
+const (
+	// SessionCookie Кука с JWT для аутентификации запросов браузера без заголовка Authorization (SSE).
+	SessionCookie = "JWT"
+	// CSRFCookie Кука с CSRF-токеном. Не HttpOnly: фронтенд читает ее и повторяет значение в заголовке CSRFHeader.
+	CSRFCookie = "XSRF-TOKEN"
+	// CSRFHeader Заголовок с CSRF-токеном.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRFMiddleware Защита от CSRF по схеме double-submit для запросов, аутентифицированных кукой.
+//
+// Изменяющий запрос (не GET, HEAD, OPTIONS) без заголовка Authorization, но с кукой сессии
+// принимается только если заголовок CSRFHeader совпадает с кукой CSRFCookie: чужой сайт может
+// заставить браузер отправить куки, но не может их прочитать и подставить значение в заголовок.
+// Запросы с заголовком Authorization: Bearer браузер сам не подставляет, поэтому они не проверяются;
+// запросы с другой схемой авторизации проверяются как запросы с кукой (LoginIDToContextMiddleware их отклоняет).
+func CSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie(SessionCookie); err != nil || cookie.Value == "" {
+			// без куки запрос не будет аутентифицирован, дальше он получит 401
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		csrfCookie, err := r.Cookie(CSRFCookie)
+		header := r.Header.Get(CSRFHeader)
+
+		if err != nil || csrfCookie.Value == "" || header == "" ||
+			subtle.ConstantTimeCompare([]byte(header), []byte(csrfCookie.Value)) != 1 {
+			logger.Log.Warn("Запрос отклонен: неверный CSRF-токен",
+				logger.String("method", r.Method),
+				logger.String("path", r.URL.Path),
+				logger.String("ip", ClientIP(r)))
+			response.ErrorJSON(w, http.StatusForbidden, "Неверный CSRF-токен")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCSRFMiddleware Проверяет double-submit защиту запросов, аутентифицированных кукой.
+func TestCSRFMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		authorization  string
+		sessionCookie  string
+		csrfCookie     string
+		csrfHeader     string
+		wantStatus     int
+		wantNextCalled bool
+	}{
+		{
+			name:           "GET с кукой без CSRF-токена",
+			method:         http.MethodGet,
+			sessionCookie:  "jwt",
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:           "POST с заголовком Authorization",
+			method:         http.MethodPost,
+			authorization:  "Bearer jwt",
+			sessionCookie:  "jwt",
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:          "POST с заголовком Basic и кукой без CSRF-токена",
+			method:        http.MethodPost,
+			authorization: "Basic eDp4",
+			sessionCookie: "jwt",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:           "POST без куки сессии",
+			method:         http.MethodPost,
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:           "POST с кукой и верным CSRF-токеном",
+			method:         http.MethodPost,
+			sessionCookie:  "jwt",
+			csrfCookie:     "csrf-token",
+			csrfHeader:     "csrf-token",
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:          "POST с кукой без CSRF-токена",
+			method:        http.MethodPost,
+			sessionCookie: "jwt",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "DELETE с кукой без заголовка CSRF-токена",
+			method:        http.MethodDelete,
+			sessionCookie: "jwt",
+			csrfCookie:    "csrf-token",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "PATCH с кукой и другим CSRF-токеном",
+			method:        http.MethodPatch,
+			sessionCookie: "jwt",
+			csrfCookie:    "csrf-token",
+			csrfHeader:    "forged",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "POST с заголовком без куки CSRF-токена",
+			method:        http.MethodPost,
+			sessionCookie: "jwt",
+			csrfHeader:    "csrf-token",
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nextCalled bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/user/servers", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.sessionCookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.sessionCookie})
+			}
+			if tt.csrfCookie != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				req.Header.Set(CSRFHeader, tt.csrfHeader)
+			}
+
+			rr := httptest.NewRecorder()
+			CSRFMiddleware(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantNextCalled, nextCalled)
+		})
+	}
+}
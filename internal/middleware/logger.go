@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/tracing"
 )
 
 // Структура для хранения данных ответа.
@@ -47,6 +48,7 @@ func (l *LoggingResponseWriter) Flush() {
 }
 
 // LogMiddleware Middleware для логирования всех запросов.
+// Здесь же начинается корневой спан трассировки запроса.
 func LogMiddleware(h http.Handler) http.Handler {
 	f := func(w http.ResponseWriter, r *http.Request) {
 		data := responseData{
@@ -59,16 +61,27 @@ func LogMiddleware(h http.Handler) http.Handler {
 			responseData:   &data,
 		}
 
+		ctx, span := tracing.StartHTTPSpan(r)
+		r = r.WithContext(ctx)
+
 		start := time.Now()
 		h.ServeHTTP(&lw, r)
 		duration := time.Since(start)
 
+		// хендлер, не вызывавший WriteHeader, отвечает 200
+		status := data.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		tracing.EndHTTPSpan(span, r, status)
+
 		logger.Log.Debug("Got incoming HTTP request",
 			logger.String("uri", r.RequestURI),
 			logger.String("method", r.Method),
 			logger.String("status", strconv.Itoa(data.status)),
 			logger.String("duration", duration.String()),
 			logger.String("size", strconv.Itoa(data.size)),
+			logger.String("trace_id", tracing.TraceID(ctx)),
 			//logger.String("remote_addr", r.RemoteAddr),
 		)
 	}
@@ -8,7 +8,10 @@ import (
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/apitoken"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 	//"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/jwt"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
@@ -17,7 +20,14 @@ import (
 // LoginIDToContextMiddleware Middleware, который извлекает логин пользователя из токена,
 // валидирует его и, если пользователь существует и токен валиден добавляет логин и UserID в контекст запроса.
 // Это позволяет в дальнейшем получить логин и UserID из контекста (request.Context) в других обработчиках.
-func LoginIDToContextMiddleware(authProvider auth.AuthProvider) func(http.Handler) http.Handler {
+// Итоговая роль пользователя определяется по ролям из токена согласно rolePolicy и также добавляется в контекст.
+//
+// Вместо JWT Keycloak может быть передан персональный API-токен (с префиксом apitoken.Prefix):
+// он проверяется по хэшу в tokens, роль определяется его областью действия (read или control)
+// и ограничивается ролью владельца при последнем интерактивном входе, а сам токен добавляется в контекст для ограничения доступа к серверам и записи в журнал аудита.
+//
+// Неудачные попытки аутентификации передаются в SIEM (sink).
+func LoginIDToContextMiddleware(authProvider auth.AuthProvider, tokens storage.APITokenStorage, sink siem.Sink, rolePolicy models.RolePolicy) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var token string
@@ -25,11 +35,19 @@ func LoginIDToContextMiddleware(authProvider auth.AuthProvider) func(http.Handle
 			// извлекаем токен
 			authHeader := r.Header.Get("Authorization")
 
-			if strings.HasPrefix(authHeader, "Bearer ") {
+			switch {
+			case strings.HasPrefix(authHeader, "Bearer "):
 				token = strings.TrimPrefix(authHeader, "Bearer ")
-			} else {
+			case authHeader != "":
+				// заголовок с другой схемой не заменяется кукой: иначе запрос с чужого сайта
+				// с произвольным заголовком Authorization прошел бы мимо CSRF-проверки с кукой пользователя
+				logger.Log.Debug("Пользователь не аутентифицирован", logger.String("err", "неподдерживаемая схема авторизации"))
+				sink.Export(siem.NewAuthFailureEvent(ClientIP(r), r.URL.Path, "unsupported authorization scheme"))
+				response.ErrorJSON(w, http.StatusUnauthorized, "Пользователь не аутентифицирован")
+				return
+			default:
 				// если заголовка нет, пробуем куку JWT
-				cookie, err := r.Cookie("JWT")
+				cookie, err := r.Cookie(SessionCookie)
 				if err == nil && cookie.Value != "" {
 					token = cookie.Value
 				}
@@ -37,25 +55,52 @@ func LoginIDToContextMiddleware(authProvider auth.AuthProvider) func(http.Handle
 			if token == "" {
 				// нет заголовка или неверный формат
 				logger.Log.Debug("Пользователь не аутентифицирован", logger.String("err", errors.New("хедер авторизации отсутствует или поврежден").Error()))
+				sink.Export(siem.NewAuthFailureEvent(ClientIP(r), r.URL.Path, "missing token"))
 				response.ErrorJSON(w, http.StatusUnauthorized, "Пользователь не аутентифицирован")
 				return
 			}
 
+			if apitoken.IsAPIToken(token) {
+				apiToken, err := tokens.UseAPIToken(r.Context(), apitoken.Hash(token))
+				if err != nil {
+					logger.Log.Debug("Ошибка проверки API-токена", logger.String("err", err.Error()))
+					sink.Export(siem.NewAuthFailureEvent(ClientIP(r), r.URL.Path, "invalid api token"))
+					response.ErrorJSON(w, http.StatusUnauthorized, "Пользователь не аутентифицирован")
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), contextkeys.Login, apiToken.Login)
+				ctx = context.WithValue(ctx, contextkeys.UserID, apiToken.UserID)
+				// роль токена не выше текущей роли владельца: права, отозванные у пользователя, не остаются у его токенов
+				ctx = context.WithValue(ctx, contextkeys.Role, apiToken.EffectiveRole(rolePolicy))
+				ctx = context.WithValue(ctx, contextkeys.APIToken, apiToken)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			claims, err := authProvider.ValidateToken(r.Context(), token)
 			if err != nil {
 				// если не удалось извлечь логин - ошибка сервера
 				logger.Log.Debug("Ошибка идентификации пользователя", logger.String("err", err.Error()))
+				sink.Export(siem.NewAuthFailureEvent(ClientIP(r), r.URL.Path, "invalid token"))
 				response.ErrorJSON(w, http.StatusUnauthorized, "Пользователь не аутентифицирован")
 				return
 			}
 
-			claimUser := &models.User{ID: claims.ID, Login: claims.Login}
+			claimUser := &models.User{ID: claims.ID, Login: claims.Login, Email: claims.Email}
 
 			// добавляем login и UserID в контекст запроса под ключом
 			// `contextkeys.Login` и `contextkeys.UserID` соответственно
 			ctxWithLogin := context.WithValue(r.Context(), contextkeys.Login, claimUser.Login)
 			ctxWithId := context.WithValue(ctxWithLogin, contextkeys.UserID, claimUser.ID)
-			r = r.WithContext(ctxWithId)
+
+			// добавляем итоговую роль пользователя под ключом `contextkeys.Role`
+			ctxWithRole := context.WithValue(ctxWithId, contextkeys.Role, rolePolicy.Resolve(claimUser.Login, claims.Roles))
+
+			// email из токена нужен для создания пользователя при первом входе (JIT)
+			ctxWithEmail := context.WithValue(ctxWithRole, contextkeys.Email, claimUser.Email)
+			r = r.WithContext(ctxWithEmail)
 
 			// передаём управление следующему обработчику, уже с модифицированным запросом
 			next.ServeHTTP(w, r)
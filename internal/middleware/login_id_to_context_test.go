@@ -9,9 +9,14 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/apitoken"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/auth/keycloak/models"
 	authMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/auth/mocks"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	appModels "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+	siemMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/siem/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
 // TestAuthMiddleware Интеграционные тесты middleware авторизации с Keycloak.
@@ -21,8 +26,21 @@ func TestAuthMiddleware(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
+	mockSink := siemMocks.NewMockSink(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
 
-	middleware := LoginIDToContextMiddleware(mockAuthProvider)
+	rolePolicy := appModels.RolePolicy{DefaultRole: appModels.RoleViewer, AdminLogins: []string{"root"}}
+	middleware := LoginIDToContextMiddleware(mockAuthProvider, mockStorage, mockSink, rolePolicy)
+
+	// expectAuthFailure Ожидает передачу в SIEM события неудачной аутентификации.
+	expectAuthFailure := func(reason string) {
+		mockSink.EXPECT().Export(gomock.Any()).Do(func(event *siem.Event) {
+			assert.Equal(t, siem.EventTypeAuthFailure, event.Type)
+			assert.Equal(t, "192.0.2.1", event.IP)
+			assert.Equal(t, "/test", event.Path)
+			assert.Equal(t, reason, event.Error)
+		})
+	}
 
 	tests := []struct {
 		name          string
@@ -31,6 +49,7 @@ func TestAuthMiddleware(t *testing.T) {
 		wantStatus    int
 		wantCtxLogin  string
 		wantCtxUserID string
+		wantCtxRole   appModels.Role
 	}{
 		{
 			name: "успешная авторизация - пользователь существует",
@@ -45,6 +64,41 @@ func TestAuthMiddleware(t *testing.T) {
 			wantStatus:    http.StatusOK,
 			wantCtxLogin:  "testuser",
 			wantCtxUserID: "any-id-user-1",
+			wantCtxRole:   appModels.RoleViewer,
+		},
+		{
+			name: "успешная авторизация - роль из токена",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer kc-operator-token")
+			},
+			setupMocks: func() {
+				mockAuthProvider.EXPECT().
+					ValidateToken(gomock.Any(), "kc-operator-token").
+					Return(&models.UserClaims{
+						ID:    "any-id-user-3",
+						Login: "operator",
+						Roles: []appModels.Role{appModels.RoleViewer, appModels.RoleOperator},
+					}, nil)
+			},
+			wantStatus:    http.StatusOK,
+			wantCtxLogin:  "operator",
+			wantCtxUserID: "any-id-user-3",
+			wantCtxRole:   appModels.RoleOperator,
+		},
+		{
+			name: "успешная авторизация - администратор из конфигурации",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer kc-root-token")
+			},
+			setupMocks: func() {
+				mockAuthProvider.EXPECT().
+					ValidateToken(gomock.Any(), "kc-root-token").
+					Return(&models.UserClaims{ID: "any-id-user-4", Login: "root"}, nil)
+			},
+			wantStatus:    http.StatusOK,
+			wantCtxLogin:  "root",
+			wantCtxUserID: "any-id-user-4",
+			wantCtxRole:   appModels.RoleAdmin,
 		},
 		{
 			name: "успешная авторизация через cookie",
@@ -59,12 +113,87 @@ func TestAuthMiddleware(t *testing.T) {
 			wantStatus:    http.StatusOK,
 			wantCtxLogin:  "user",
 			wantCtxUserID: "any-id-user-2",
+			wantCtxRole:   appModels.RoleViewer,
+		},
+		{
+			name: "успешная авторизация по API-токену",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+apitoken.Prefix+"valid-api-token")
+			},
+			setupMocks: func() {
+				mockStorage.EXPECT().
+					UseAPIToken(gomock.Any(), apitoken.Hash(apitoken.Prefix+"valid-api-token")).
+					Return(&appModels.APIToken{ID: 5, UserID: "any-id-user-4", Login: "root", Scope: appModels.APITokenScopeControl}, nil)
+			},
+			wantStatus:    http.StatusOK,
+			wantCtxLogin:  "root",
+			wantCtxUserID: "any-id-user-4",
+			// роль API-токена определяется только областью действия, даже для администратора
+			wantCtxRole: appModels.RoleOperator,
+		},
+		{
+			name: "роль API-токена ограничена ролью владельца",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+apitoken.Prefix+"demoted-api-token")
+			},
+			setupMocks: func() {
+				mockStorage.EXPECT().
+					UseAPIToken(gomock.Any(), apitoken.Hash(apitoken.Prefix+"demoted-api-token")).
+					Return(&appModels.APIToken{ID: 6, UserID: "any-id-user-3", Login: "operator",
+						OwnerRole: appModels.RoleViewer, Scope: appModels.APITokenScopeControl}, nil)
+			},
+			wantStatus:    http.StatusOK,
+			wantCtxLogin:  "operator",
+			wantCtxUserID: "any-id-user-3",
+			wantCtxRole:   appModels.RoleViewer,
+		},
+		{
+			name: "роль владельца API-токена неизвестна",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+apitoken.Prefix+"new-owner-api-token")
+			},
+			setupMocks: func() {
+				mockStorage.EXPECT().
+					UseAPIToken(gomock.Any(), apitoken.Hash(apitoken.Prefix+"new-owner-api-token")).
+					Return(&appModels.APIToken{ID: 7, UserID: "any-id-user-1", Login: "testuser", Scope: appModels.APITokenScopeControl}, nil)
+			},
+			wantStatus:    http.StatusOK,
+			wantCtxLogin:  "testuser",
+			wantCtxUserID: "any-id-user-1",
+			// без сохраненной роли действует роль по умолчанию
+			wantCtxRole: appModels.RoleViewer,
+		},
+		{
+			name: "ошибка - неизвестный или истекший API-токен",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+apitoken.Prefix+"revoked-api-token")
+			},
+			setupMocks: func() {
+				mockStorage.EXPECT().
+					UseAPIToken(gomock.Any(), apitoken.Hash(apitoken.Prefix+"revoked-api-token")).
+					Return(nil, errors.New("api token not found"))
+				expectAuthFailure("invalid api token")
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "ошибка - заголовок Basic с cookie",
+			setupAuth: func(r *http.Request) {
+				r.Header.Set("Authorization", "Basic eDp4")
+				r.AddCookie(&http.Cookie{Name: "JWT", Value: "kc-user-token"})
+			},
+			setupMocks: func() {
+				// кука не используется вместо заголовка с другой схемой
+				expectAuthFailure("unsupported authorization scheme")
+			},
+			wantStatus: http.StatusUnauthorized,
 		},
 		{
 			name:      "ошибка - нет токена (нет заголовка и cookie)",
 			setupAuth: func(r *http.Request) {},
 			setupMocks: func() {
-				// Ничего НЕ вызывается
+				// токен не проверяется, неудача передается в SIEM
+				expectAuthFailure("missing token")
 			},
 			wantStatus: http.StatusUnauthorized,
 		},
@@ -77,6 +206,7 @@ func TestAuthMiddleware(t *testing.T) {
 				mockAuthProvider.EXPECT().
 					ValidateToken(gomock.Any(), "kc-invalid-token").
 					Return(nil, errors.New("oidc: token expired"))
+				expectAuthFailure("invalid token")
 			},
 			wantStatus: http.StatusUnauthorized,
 		},
@@ -98,6 +228,11 @@ func TestAuthMiddleware(t *testing.T) {
 					t.Errorf("ожидался userID=%s, получен=%v", tt.wantCtxUserID, userID)
 				}
 
+				role, ok := r.Context().Value(contextkeys.Role).(appModels.Role)
+				if !ok || role != tt.wantCtxRole {
+					t.Errorf("ожидалась роль=%s, получена=%v", tt.wantCtxRole, role)
+				}
+
 				w.WriteHeader(http.StatusOK)
 			})
 
@@ -124,7 +259,7 @@ func TestAuthMiddleware_TokenSources(t *testing.T) {
 
 	mockAuthProvider := authMocks.NewMockAuthProvider(ctrl)
 
-	middleware := LoginIDToContextMiddleware(mockAuthProvider)
+	middleware := LoginIDToContextMiddleware(mockAuthProvider, mocks.NewMockStorage(ctrl), siem.NewNoopSink(), appModels.RolePolicy{DefaultRole: appModels.RoleViewer})
 
 	tests := []struct {
 		name       string
@@ -70,3 +70,33 @@ func ParseServiceIDMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// ParseTeamIDMiddleware извлекает и валидирует teamID из URL параметров роутера Chi.
+func ParseTeamIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "teamID")
+
+		if idStr == "" {
+			logger.Log.Error("В запросе отсутствует teamID")
+			response.ErrorJSON(w, http.StatusBadRequest, "В запросе отсутствует id команды")
+			return
+		}
+
+		// Парсим строку в int64
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			logger.Log.Error("Некорректный id")
+			response.ErrorJSON(w, http.StatusBadRequest, "Некорректный id команды")
+			return
+		}
+
+		if id <= 0 {
+			logger.Log.Error("Некорректный id: должен быть положительным")
+			response.ErrorJSON(w, http.StatusBadRequest, "id команды должен быть положительным числом")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextkeys.TeamID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// RequireRoleMiddleware Пропускает только пользователей, чья роль дает права не ниже требуемой.
+func RequireRoleMiddleware(required models.Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			creds := models.GetContextCreds(r.Context())
+
+			if !creds.Role.Allows(required) {
+				logger.Log.Warn("Недостаточно прав для доступа к маршруту",
+					logger.String("login", creds.Login),
+					logger.String("role", string(creds.Role)),
+					logger.String("required", string(required)),
+					logger.String("uri", r.RequestURI))
+				response.ErrorJSON(w, http.StatusForbidden, "Недостаточно прав")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
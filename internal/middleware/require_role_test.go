@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestRequireRoleMiddleware Проверяет доступ к маршрутам в зависимости от роли пользователя.
+func TestRequireRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           models.Role
+		required       models.Role
+		expectedStatus int
+	}{
+		{"наблюдатель: просмотр", models.RoleViewer, models.RoleViewer, http.StatusOK},
+		{"наблюдатель: управление", models.RoleViewer, models.RoleOperator, http.StatusForbidden},
+		{"оператор: управление", models.RoleOperator, models.RoleOperator, http.StatusOK},
+		{"оператор: администрирование", models.RoleOperator, models.RoleAdmin, http.StatusForbidden},
+		{"администратор: управление", models.RoleAdmin, models.RoleOperator, http.StatusOK},
+		{"администратор: администрирование", models.RoleAdmin, models.RoleAdmin, http.StatusOK},
+		{"неизвестная роль", models.Role("superuser"), models.RoleViewer, http.StatusForbidden},
+		{"нет роли в контексте", "", models.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+			if tt.role != "" {
+				r = r.WithContext(context.WithValue(r.Context(), contextkeys.Role, tt.role))
+			}
+			w := httptest.NewRecorder()
+
+			RequireRoleMiddleware(tt.required)(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// TeamRoleMiddleware Проверяет, что пользователь состоит в команде из URL, и сужает
+// его роль в контексте до роли в этой команде. Должен идти после ParseTeamIDMiddleware.
+func TeamRoleMiddleware(storage storage.Storage) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			creds := models.GetContextCreds(r.Context())
+
+			teamRole, err := storage.GetTeamRole(r.Context(), creds.TeamID, creds.UserID)
+			if err != nil {
+				var errTeamNotFound *errs.ErrTeamNotFound
+
+				if errors.As(err, &errTeamNotFound) {
+					logger.Log.Debug("Пользователь не состоит в команде",
+						logger.Int64("teamID", creds.TeamID),
+						logger.String("login", creds.Login))
+					response.ErrorJSON(w, http.StatusNotFound, "Команда не найдена")
+					return
+				}
+
+				logger.Log.Error("Ошибка получения роли в команде", logger.String("err", err.Error()))
+				response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withEffectiveRole(r.Context(), creds.Role, teamRole)))
+		})
+	}
+}
+
+// ServerTeamRoleMiddleware Проверяет, что сервер из URL принадлежит команде пользователя, и сужает
+// его роль в контексте до роли в этой команде. Должен идти после ParseServerIDMiddleware.
+func ServerTeamRoleMiddleware(storage storage.Storage) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			creds := models.GetContextCreds(r.Context())
+
+			teamRole, err := storage.GetServerTeamRole(r.Context(), creds.ServerID, creds.UserID)
+			if err != nil {
+				var errServerNotFound *errs.ErrServerNotFound
+
+				if errors.As(err, &errServerNotFound) {
+					logger.Log.Debug("Сервер не найден в командах пользователя",
+						logger.Int64("serverID", creds.ServerID),
+						logger.String("login", creds.Login))
+					response.ErrorJSON(w, http.StatusNotFound, "Сервер не найден")
+					return
+				}
+
+				logger.Log.Error("Ошибка получения роли в команде сервера", logger.String("err", err.Error()))
+				response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withEffectiveRole(r.Context(), creds.Role, teamRole)))
+		})
+	}
+}
+
+// withEffectiveRole Кладет в контекст наименьшую из глобальной роли и роли в команде.
+func withEffectiveRole(ctx context.Context, globalRole, teamRole models.Role) context.Context {
+	return context.WithValue(ctx, contextkeys.Role, models.MinRole(globalRole, teamRole))
+}
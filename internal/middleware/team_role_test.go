@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// TestTeamRoleMiddleware Проверяет доступ к маршрутам команды и сужение роли до роли в команде.
+func TestTeamRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		globalRole     models.Role
+		setupMock      func(m *mocks.MockStorage)
+		expectedStatus int
+		expectedRole   models.Role
+	}{
+		{
+			name:       "администратор системы - наблюдатель в команде",
+			globalRole: models.RoleAdmin,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(5), "user-1").Return(models.RoleViewer, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRole:   models.RoleViewer,
+		},
+		{
+			name:       "оператор системы - администратор команды",
+			globalRole: models.RoleOperator,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(5), "user-1").Return(models.RoleAdmin, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRole:   models.RoleOperator,
+		},
+		{
+			name:       "пользователь не состоит в команде",
+			globalRole: models.RoleAdmin,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(5), "user-1").
+					Return(models.Role(""), errs.NewErrTeamNotFound(5, "user-1", errors.New("no rows")))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:       "ошибка БД",
+			globalRole: models.RoleAdmin,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetTeamRole(gomock.Any(), int64(5), "user-1").
+					Return(models.Role(""), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			var capturedRole models.Role
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedRole = models.GetContextCreds(r.Context()).Role
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/user/teams/5/members", nil)
+			ctx := context.WithValue(r.Context(), contextkeys.UserID, "user-1")
+			ctx = context.WithValue(ctx, contextkeys.Role, tt.globalRole)
+			ctx = context.WithValue(ctx, contextkeys.TeamID, int64(5))
+			w := httptest.NewRecorder()
+
+			TeamRoleMiddleware(mockStorage)(next).ServeHTTP(w, r.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedRole, capturedRole)
+		})
+	}
+}
+
+// TestServerTeamRoleMiddleware Проверяет доступ к серверу через команду пользователя.
+func TestServerTeamRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(m *mocks.MockStorage)
+		expectedStatus int
+		expectedRole   models.Role
+	}{
+		{
+			name: "оператор в команде сервера",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetServerTeamRole(gomock.Any(), int64(1), "user-1").Return(models.RoleOperator, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRole:   models.RoleOperator,
+		},
+		{
+			name: "сервер чужой команды",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetServerTeamRole(gomock.Any(), int64(1), "user-1").
+					Return(models.Role(""), errs.NewErrServerNotFound(1, "user-1", errors.New("no rows")))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "ошибка БД",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetServerTeamRole(gomock.Any(), int64(1), "user-1").
+					Return(models.Role(""), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			var capturedRole models.Role
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedRole = models.GetContextCreds(r.Context()).Role
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/user/servers/1", nil)
+			ctx := context.WithValue(r.Context(), contextkeys.UserID, "user-1")
+			ctx = context.WithValue(ctx, contextkeys.Role, models.RoleAdmin)
+			ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
+			w := httptest.NewRecorder()
+
+			ServerTeamRoleMiddleware(mockStorage)(next).ServeHTTP(w, r.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedRole, capturedRole)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// TrustedProxyMiddleware Заменяет адрес соединения (r.RemoteAddr) адресом клиента из заголовков
+// X-Real-IP или X-Forwarded-For, но только если соединение установлено доверенным обратным прокси
+// (адрес входит в trustedProxies). Заголовки от остальных клиентов игнорируются: иначе адрес в журнале
+// аудита, SIEM и проверке отправителей вебхуков можно было бы подделать.
+func TrustedProxyMiddleware(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trustedProxies) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			host, port, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil || !netutils.ContainsIP(trustedProxies, net.ParseIP(host)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ip := forwardedClientIP(r, trustedProxies); ip != nil {
+				r.RemoteAddr = net.JoinHostPort(ip.String(), port)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClientIP Возвращает адрес клиента, переданный доверенным прокси.
+// X-Real-IP выставляется nginx из комплекта поставки. В X-Forwarded-For адреса добавляются справа,
+// поэтому берется самый правый адрес, не являющийся доверенным прокси: левее него значения мог записать сам клиент.
+func forwardedClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			return nil
+		}
+
+		if !netutils.ContainsIP(trustedProxies, ip) {
+			return ip
+		}
+	}
+
+	return nil
+}
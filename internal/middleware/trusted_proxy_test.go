@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// TestTrustedProxyMiddleware Проверяет, что адрес клиента из заголовков учитывается только от доверенных прокси.
+func TestTrustedProxyMiddleware(t *testing.T) {
+	trusted, err := netutils.ParseIPNets([]string{"172.18.0.0/16", "::1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		realIP         string
+		forwardedFor   []string
+		expectedClient string
+	}{
+		{
+			name:           "X-Real-IP от доверенного прокси",
+			remoteAddr:     "172.18.0.5:40000",
+			realIP:         "203.0.113.7",
+			expectedClient: "203.0.113.7",
+		},
+		{
+			name:           "X-Real-IP от клиента напрямую",
+			remoteAddr:     "198.51.100.9:40000",
+			realIP:         "10.0.0.1",
+			expectedClient: "198.51.100.9",
+		},
+		{
+			name:           "X-Forwarded-For от клиента напрямую",
+			remoteAddr:     "198.51.100.9:40000",
+			forwardedFor:   []string{"10.0.0.1"},
+			expectedClient: "198.51.100.9",
+		},
+		{
+			name:           "поддельный адрес левее добавленного прокси",
+			remoteAddr:     "172.18.0.5:40000",
+			forwardedFor:   []string{"10.0.0.1, 203.0.113.7"},
+			expectedClient: "203.0.113.7",
+		},
+		{
+			name:           "цепочка доверенных прокси",
+			remoteAddr:     "[::1]:40000",
+			forwardedFor:   []string{"203.0.113.7", "172.18.0.9"},
+			expectedClient: "203.0.113.7",
+		},
+		{
+			name:           "неверный адрес в заголовке",
+			remoteAddr:     "172.18.0.5:40000",
+			realIP:         "not-an-ip",
+			forwardedFor:   []string{"unknown"},
+			expectedClient: "172.18.0.5",
+		},
+		{
+			name:           "доверенный прокси без заголовков",
+			remoteAddr:     "172.18.0.5:40000",
+			expectedClient: "172.18.0.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientIP string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				clientIP = ClientIP(r)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			TrustedProxyMiddleware(trusted)(next).ServeHTTP(httptest.NewRecorder(), r)
+
+			assert.Equal(t, tt.expectedClient, clientIP)
+		})
+	}
+
+	t.Run("без доверенных прокси заголовки не учитываются", func(t *testing.T) {
+		var clientIP string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP = ClientIP(r)
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "172.18.0.5:40000"
+		r.Header.Set("X-Real-IP", "203.0.113.7")
+
+		TrustedProxyMiddleware(nil)(next).ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.Equal(t, "172.18.0.5", clientIP)
+	})
+}
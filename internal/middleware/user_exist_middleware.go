@@ -1,17 +1,22 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/api/response"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
 
 // UserExistsMiddleware Проверяет, что аутентифицированный пользователь
-// существует в БД проекта. Если нет - возвращает 403.
-func UserExistsMiddleware(storage storage.Storage) func(h http.Handler) http.Handler {
+// существует в БД проекта и не отключен. Если нет - возвращает 403.
+// При jitProvisioning пользователь, вошедший с валидным токеном провайдера, создается при первом запросе
+// (вместо события REGISTER от Keycloak) вместе с личной командой.
+func UserExistsMiddleware(storage storage.Storage, jitProvisioning bool) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userID, ok := r.Context().Value(contextkeys.UserID).(string)
@@ -21,12 +26,27 @@ func UserExistsMiddleware(storage storage.Storage) func(h http.Handler) http.Han
 				return
 			}
 
-			exists, err := storage.UserExists(r.Context(), userID)
+			exists := true
+			user, err := storage.GetUser(r.Context(), userID)
 			if err != nil {
-				// реальная ошибка БД/сети
-				logger.Log.Error("Ошибка БД", logger.String("err", err.Error()))
-				response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
-				return
+				var errNotFound *errs.ErrUserIDNotFound
+				if !errors.As(err, &errNotFound) {
+					// реальная ошибка БД/сети
+					logger.Log.Error("Ошибка БД", logger.String("err", err.Error()))
+					response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+					return
+				}
+				exists = false
+			}
+
+			// API-токен принадлежит существующему пользователю, поэтому создается только пользователь из JWT
+			if !exists && jitProvisioning && r.Context().Value(contextkeys.APIToken) == nil {
+				exists, err = provisionUser(r, storage, userID)
+				if err != nil {
+					logger.Log.Error("Ошибка создания пользователя при первом входе", logger.String("err", err.Error()))
+					response.ErrorJSON(w, http.StatusInternalServerError, "Ошибка сервера")
+					return
+				}
 			}
 
 			if !exists {
@@ -37,7 +57,59 @@ func UserExistsMiddleware(storage storage.Storage) func(h http.Handler) http.Han
 				return
 			}
 
+			// пользователь отключен в Keycloak: доступ закрыт, в том числе по его API-токенам
+			if user != nil && user.Disabled {
+				logger.Log.Warn("Запрос отключенного пользователя", logger.String("user_id", userID))
+				response.ErrorJSON(w, http.StatusForbidden, "Пользователь заблокирован")
+				return
+			}
+
+			// роль из JWT сохраняется, чтобы ограничивать ею роль API-токенов пользователя
+			if r.Context().Value(contextkeys.APIToken) == nil {
+				saveUserRole(r, storage, userID, user)
+			}
+
 			h.ServeHTTP(w, r)
 		})
 	}
 }
+
+// saveUserRole Сохраняет роль пользователя из контекста запроса, если она изменилась.
+// Ошибка сохранения не прерывает запрос: роль обновится при следующем запросе.
+func saveUserRole(r *http.Request, storage storage.Storage, userID string, user *models.User) {
+	role, _ := r.Context().Value(contextkeys.Role).(models.Role)
+	if !models.IsValidRole(role) || (user != nil && user.Role == role) {
+		return
+	}
+
+	if err := storage.SetUserRole(r.Context(), userID, role); err != nil {
+		logger.Log.Warn("Не удалось сохранить роль пользователя",
+			logger.String("user_id", userID),
+			logger.String("err", err.Error()))
+	}
+}
+
+// provisionUser Создает пользователя из данных токена в контексте запроса.
+// Возвращает false, если пользователь не создан, например, логин уже занят другим пользователем.
+func provisionUser(r *http.Request, storage storage.Storage, userID string) (bool, error) {
+	login, _ := r.Context().Value(contextkeys.Login).(string)
+	email, _ := r.Context().Value(contextkeys.Email).(string)
+
+	err := storage.CreateUser(r.Context(), &models.User{ID: userID, Login: login, Email: email})
+	if err != nil {
+		var errUserExists *errs.ErrUserAlreadyExists
+
+		if !errors.As(err, &errUserExists) {
+			return false, err
+		}
+
+		// пользователь мог быть создан параллельным запросом или логин занят другим пользователем
+		return storage.UserExists(r.Context(), userID)
+	}
+
+	logger.Log.Info("Пользователь создан при первом входе",
+		logger.String("userID", userID),
+		logger.String("login", login))
+
+	return true, nil
+}
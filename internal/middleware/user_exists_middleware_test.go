@@ -9,6 +9,8 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
 )
 
@@ -30,6 +32,7 @@ func TestUserExistsMiddleware(t *testing.T) {
 	tests := []struct {
 		name              string
 		ctxUserID         interface{} // nil = ключ отсутствует в контексте
+		ctxRole           models.Role // роль из JWT, пустая - ключ отсутствует в контексте
 		setupMock         func()
 		expectedStatus    int
 		expectNextHandler bool
@@ -60,8 +63,8 @@ func TestUserExistsMiddleware(t *testing.T) {
 			ctxUserID: "user-123",
 			setupMock: func() {
 				mockStorage.EXPECT().
-					UserExists(gomock.Any(), "user-123").
-					Return(false, errors.New("database connection failed"))
+					GetUser(gomock.Any(), "user-123").
+					Return(nil, errors.New("database connection failed"))
 			},
 			expectedStatus:    http.StatusInternalServerError,
 			expectNextHandler: false,
@@ -71,8 +74,8 @@ func TestUserExistsMiddleware(t *testing.T) {
 			ctxUserID: "user-404",
 			setupMock: func() {
 				mockStorage.EXPECT().
-					UserExists(gomock.Any(), "user-404").
-					Return(false, nil)
+					GetUser(gomock.Any(), "user-404").
+					Return(nil, errs.NewErrUserIDNotFound("user-404"))
 			},
 			expectedStatus:    http.StatusForbidden,
 			expectNextHandler: false,
@@ -82,12 +85,61 @@ func TestUserExistsMiddleware(t *testing.T) {
 			ctxUserID: "user-valid",
 			setupMock: func() {
 				mockStorage.EXPECT().
-					UserExists(gomock.Any(), "user-valid").
-					Return(true, nil)
+					GetUser(gomock.Any(), "user-valid").
+					Return(&models.User{ID: "user-valid", Login: "alice"}, nil)
 			},
 			expectedStatus:    http.StatusOK,
 			expectNextHandler: true,
 		},
+		{
+			name:      "Роль пользователя изменилась",
+			ctxUserID: "user-valid",
+			ctxRole:   models.RoleOperator,
+			setupMock: func() {
+				mockStorage.EXPECT().
+					GetUser(gomock.Any(), "user-valid").
+					Return(&models.User{ID: "user-valid", Login: "alice", Role: models.RoleViewer}, nil)
+				mockStorage.EXPECT().SetUserRole(gomock.Any(), "user-valid", models.RoleOperator).Return(nil)
+			},
+			expectedStatus:    http.StatusOK,
+			expectNextHandler: true,
+		},
+		{
+			name:      "Роль пользователя не изменилась",
+			ctxUserID: "user-valid",
+			ctxRole:   models.RoleViewer,
+			setupMock: func() {
+				mockStorage.EXPECT().
+					GetUser(gomock.Any(), "user-valid").
+					Return(&models.User{ID: "user-valid", Login: "alice", Role: models.RoleViewer}, nil)
+			},
+			expectedStatus:    http.StatusOK,
+			expectNextHandler: true,
+		},
+		{
+			name:      "Ошибка сохранения роли не прерывает запрос",
+			ctxUserID: "user-valid",
+			ctxRole:   models.RoleAdmin,
+			setupMock: func() {
+				mockStorage.EXPECT().
+					GetUser(gomock.Any(), "user-valid").
+					Return(&models.User{ID: "user-valid", Login: "alice"}, nil)
+				mockStorage.EXPECT().SetUserRole(gomock.Any(), "user-valid", models.RoleAdmin).Return(errors.New("db error"))
+			},
+			expectedStatus:    http.StatusOK,
+			expectNextHandler: true,
+		},
+		{
+			name:      "Пользователь отключен в Keycloak",
+			ctxUserID: "user-disabled",
+			setupMock: func() {
+				mockStorage.EXPECT().
+					GetUser(gomock.Any(), "user-disabled").
+					Return(&models.User{ID: "user-disabled", Login: "bob", Disabled: true}, nil)
+			},
+			expectedStatus:    http.StatusForbidden,
+			expectNextHandler: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,9 +152,12 @@ func TestUserExistsMiddleware(t *testing.T) {
 				ctx := context.WithValue(req.Context(), contextkeys.UserID, tt.ctxUserID)
 				req = req.WithContext(ctx)
 			}
+			if tt.ctxRole != "" {
+				req = req.WithContext(context.WithValue(req.Context(), contextkeys.Role, tt.ctxRole))
+			}
 
 			rr := httptest.NewRecorder()
-			handler := UserExistsMiddleware(mockStorage)(nextHandler)
+			handler := UserExistsMiddleware(mockStorage, false)(nextHandler)
 			handler.ServeHTTP(rr, req)
 
 			if rr.Code != tt.expectedStatus {
@@ -114,3 +169,88 @@ func TestUserExistsMiddleware(t *testing.T) {
 		})
 	}
 }
+
+// TestUserExistsMiddleware_JITProvisioning Проверяет создание пользователя при первом входе.
+func TestUserExistsMiddleware_JITProvisioning(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiToken       bool
+		setupMock      func(m *mocks.MockStorage)
+		expectedStatus int
+	}{
+		{
+			name: "пользователь создан из данных токена",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetUser(gomock.Any(), "user-new").Return(nil, errs.NewErrUserIDNotFound("user-new"))
+				m.EXPECT().CreateUser(gomock.Any(), &models.User{ID: "user-new", Login: "alice", Email: "alice@example.com"}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "пользователь создан параллельным запросом",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetUser(gomock.Any(), "user-new").Return(nil, errs.NewErrUserIDNotFound("user-new"))
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+					Return(errs.NewErrUserAlreadyExists("user-new", errors.New("duplicate")))
+				m.EXPECT().UserExists(gomock.Any(), "user-new").Return(true, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "логин занят другим пользователем",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetUser(gomock.Any(), "user-new").Return(nil, errs.NewErrUserIDNotFound("user-new"))
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+					Return(errs.NewErrUserAlreadyExists("user-new", errors.New("duplicate login")))
+				m.EXPECT().UserExists(gomock.Any(), "user-new").Return(false, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "ошибка БД при создании",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetUser(gomock.Any(), "user-new").Return(nil, errs.NewErrUserIDNotFound("user-new"))
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:     "запрос с API-токеном не создает пользователя",
+			apiToken: true,
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().GetUser(gomock.Any(), "user-new").Return(nil, errs.NewErrUserIDNotFound("user-new"))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			ctx := context.WithValue(context.Background(), contextkeys.UserID, "user-new")
+			ctx = context.WithValue(ctx, contextkeys.Login, "alice")
+			ctx = context.WithValue(ctx, contextkeys.Email, "alice@example.com")
+			if tt.apiToken {
+				ctx = context.WithValue(ctx, contextkeys.APIToken, &models.APIToken{ID: 1})
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/test", nil).WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			UserExistsMiddleware(mockStorage, true)(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("ожидаемый статус %d, получен %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Области действия API-токена.
+const (
+	APITokenScopeRead    = "read"    // только чтение (роль viewer)
+	APITokenScopeControl = "control" // чтение и управление службами (роль operator)
+)
+
+// APITokenMaxTTL Максимальный срок действия API-токена.
+const APITokenMaxTTL = 365 * 24 * time.Hour
+
+const apiTokenNameMaxLen = 250
+
+// APIToken Модель персонального API-токена пользователя (без самого токена).
+type APIToken struct {
+	ID         int64      `json:"id"`
+	UserID     string     `json:"-"`
+	Login      string     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // начало токена, по которому его можно узнать
+	Scope      string     `json:"scope"`
+	ServerIDs  []int64    `json:"server_ids"` // пустой список у неограниченного токена - доступны все серверы пользователя
+	Restricted bool       `json:"restricted"` // токен ограничен серверами из ServerIDs, даже если их не осталось
+	OwnerRole  Role       `json:"-"`          // роль владельца при последнем интерактивном входе, пустая - неизвестна
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreatedAPIToken Созданный API-токен. Сам токен возвращается только один раз — при создании.
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// Validate Валидация данных нового API-токена.
+func (t APIToken) Validate(now time.Time) error {
+	name := strings.TrimSpace(t.Name)
+
+	if name == "" {
+		return errors.New("необходимо указать название токена")
+	}
+
+	if len(name) > apiTokenNameMaxLen {
+		return errors.New("название токена слишком длинное")
+	}
+
+	if t.Scope != APITokenScopeRead && t.Scope != APITokenScopeControl {
+		return errors.New("неизвестная область действия токена: допустимы read или control")
+	}
+
+	if !t.ExpiresAt.After(now) {
+		return errors.New("срок действия токена должен быть в будущем")
+	}
+
+	if t.ExpiresAt.After(now.Add(APITokenMaxTTL)) {
+		return errors.New("срок действия токена не может превышать 365 дней")
+	}
+
+	for _, id := range t.ServerIDs {
+		if id <= 0 {
+			return errors.New("некорректный id сервера")
+		}
+	}
+
+	return nil
+}
+
+// Role Роль, которую дает область действия токена.
+func (t APIToken) Role() Role {
+	if t.Scope == APITokenScopeControl {
+		return RoleOperator
+	}
+
+	return RoleViewer
+}
+
+// EffectiveRole Роль, с которой выполняются запросы с этим токеном: роль области действия,
+// но не выше текущей роли владельца. Роль владельца определяется по rolePolicy.
+func (t APIToken) EffectiveRole(rolePolicy RolePolicy) Role {
+	var ownerRoles []Role
+	if t.OwnerRole != "" {
+		ownerRoles = []Role{t.OwnerRole}
+	}
+
+	return MinRole(t.Role(), rolePolicy.Resolve(t.Login, ownerRoles))
+}
+
+// AllowsServer Проверяет, что токен дает доступ к серверу.
+// Ограниченный токен, все серверы которого удалены, не дает доступа ни к одному серверу.
+func (t APIToken) AllowsServer(serverID int64) bool {
+	return !t.Restricted || slices.Contains(t.ServerIDs, serverID)
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+)
+
+// Действия с серверами и службами, фиксируемые в журнале аудита.
+// Действия управления службами — ControlActionStart, ControlActionStop, ControlActionRestart.
+const (
+	AuditActionAddServer  = "add_server"
+	AuditActionEditServer = "edit_server"
+	AuditActionDelServer  = "delete_server"
+	AuditActionTestServer = "test_server"
+	AuditActionAddService = "add_service"
+	AuditActionDelService = "delete_service"
+
+	AuditActionSetServicePermission = "set_service_permission"
+	AuditActionDelServicePermission = "delete_service_permission"
+	AuditActionSetServiceCritical   = "set_service_critical"
+)
+
+// Действия с запросами на подтверждение остановки и перезапуска критичных служб.
+const (
+	AuditActionRequestApproval = "request_approval"
+	AuditActionApproveControl  = "approve_control"
+	AuditActionRejectControl   = "reject_control"
+)
+
+// Действия с командами, фиксируемые в журнале аудита.
+const (
+	AuditActionAddTeam          = "add_team"
+	AuditActionDelTeam          = "delete_team"
+	AuditActionInviteMember     = "invite_member"
+	AuditActionDelInvitation    = "delete_invitation"
+	AuditActionAcceptInvitation = "accept_invitation"
+	AuditActionEditMember       = "edit_member"
+	AuditActionDelMember        = "delete_member"
+)
+
+// Действия с персональными API-токенами, фиксируемые в журнале аудита.
+const (
+	AuditActionAddAPIToken = "add_api_token"
+	AuditActionDelAPIToken = "delete_api_token"
+)
+
+// Действия с профилями учетных данных команд, фиксируемые в журнале аудита.
+const (
+	AuditActionAddCredentialProfile  = "add_credential_profile"
+	AuditActionEditCredentialProfile = "edit_credential_profile"
+	AuditActionDelCredentialProfile  = "delete_credential_profile"
+	AuditActionTestCredentialProfile = "test_credential_profile"
+)
+
+// Действия с пользователями встроенной аутентификации, фиксируемые в журнале аудита.
+const (
+	AuditActionAddUser        = "add_user"
+	AuditActionChangePassword = "change_password"
+)
+
+// Ограничения размера страницы журнала аудита.
+const (
+	AuditDefaultLimit = 50
+	AuditMaxLimit     = 500
+)
+
+// AuditEntry Модель записи журнала аудита.
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	UserID     string    `json:"user_id"`
+	Login      string    `json:"login"`
+	Action     string    `json:"action"`
+	ServerID   int64     `json:"server_id,omitempty"`
+	ServiceID  int64     `json:"service_id,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	APITokenID int64     `json:"api_token_id,omitempty"` // действие выполнено с API-токеном
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditFilter Параметры выборки журнала аудита. Пустые поля не участвуют в фильтрации.
+type AuditFilter struct {
+	UserID    string
+	Action    string
+	ServerID  int64
+	ServiceID int64
+	Success   *bool
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// AuditPage Страница журнала аудита.
+type AuditPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// IsValidAuditAction Проверяет, что действие фиксируется в журнале аудита.
+func IsValidAuditAction(action string) bool {
+	switch action {
+	case AuditActionAddServer, AuditActionEditServer, AuditActionDelServer, AuditActionTestServer,
+		AuditActionAddService, AuditActionDelService,
+		AuditActionSetServicePermission, AuditActionDelServicePermission, AuditActionSetServiceCritical,
+		AuditActionRequestApproval, AuditActionApproveControl, AuditActionRejectControl,
+		AuditActionAddTeam, AuditActionDelTeam, AuditActionInviteMember, AuditActionDelInvitation,
+		AuditActionAcceptInvitation, AuditActionEditMember, AuditActionDelMember,
+		AuditActionAddAPIToken, AuditActionDelAPIToken,
+		AuditActionAddCredentialProfile, AuditActionEditCredentialProfile, AuditActionDelCredentialProfile,
+		AuditActionTestCredentialProfile,
+		AuditActionAddUser, AuditActionChangePassword,
+		ControlActionStart, ControlActionStop, ControlActionRestart:
+		return true
+	}
+
+	return false
+}
+
+// IsControlAction Проверяет, что действие относится к управлению службой.
+func IsControlAction(action string) bool {
+	return action == ControlActionStart || action == ControlActionStop || action == ControlActionRestart
+}
+
+// ServerAuditTarget Описание сервера для журнала аудита.
+func ServerAuditTarget(name, address string) string {
+	if name == "" {
+		return address
+	}
+
+	return fmt.Sprintf("%s (%s)", name, address)
+}
+
+// ServiceAuditTarget Описание службы для журнала аудита.
+func ServiceAuditTarget(displayedName, serviceName string) string {
+	if displayedName == "" {
+		return serviceName
+	}
+
+	return fmt.Sprintf("%s (%s)", displayedName, serviceName)
+}
+
+// TeamAuditTarget Описание команды (и, при необходимости, затронутого пользователя) для журнала аудита.
+func TeamAuditTarget(teamID int64, subject string) string {
+	if subject == "" {
+		return fmt.Sprintf("team %d", teamID)
+	}
+
+	return fmt.Sprintf("team %d: %s", teamID, subject)
+}
+
+// WithAuditEntry Сохраняет в контексте запись аудита текущего запроса,
+// чтобы хендлер мог дополнить ее данными о созданном объекте.
+func WithAuditEntry(ctx context.Context, entry *AuditEntry) context.Context {
+	return context.WithValue(ctx, contextkeys.AuditEntry, entry)
+}
+
+// SetAuditTarget Дополняет запись аудита текущего запроса идентификаторами и описанием объекта.
+// Нулевые идентификаторы и пустое описание не перезаписывают уже известные значения.
+// Если запрос не записывается в журнал аудита — ничего не делает.
+func SetAuditTarget(ctx context.Context, serverID, serviceID int64, target string) {
+	entry, ok := ctx.Value(contextkeys.AuditEntry).(*AuditEntry)
+	if !ok || entry == nil {
+		return
+	}
+
+	if serverID != 0 {
+		entry.ServerID = serverID
+	}
+	if serviceID != 0 {
+		entry.ServiceID = serviceID
+	}
+	if target != "" {
+		entry.Target = target
+	}
+}
+
+// SetAuditAction Заменяет действие в записи аудита текущего запроса, если хендлер выполнил
+// не то действие, под которым запрос был зарегистрирован (например, вместо остановки
+// критичной службы создал запрос на подтверждение).
+// Если запрос не записывается в журнал аудита — ничего не делает.
+func SetAuditAction(ctx context.Context, action string) {
+	entry, ok := ctx.Value(contextkeys.AuditEntry).(*AuditEntry)
+	if !ok || entry == nil {
+		return
+	}
+
+	entry.Action = action
+}
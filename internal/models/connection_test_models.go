@@ -0,0 +1,43 @@
+package models
+
+import "github.com/google/uuid"
+
+// Этапы проверки подключения к серверу (в порядке выполнения).
+const (
+	ConnectionStepResolve     = "resolve"     // разрешение имени сервера в IP-адрес
+	ConnectionStepICMP        = "icmp"        // ping (необязательный этап: ICMP часто блокируется)
+	ConnectionStepTCP         = "tcp"         // доступность порта WinRM
+	ConnectionStepWinRM       = "winrm_http"  // ответ WinRM по HTTP(S), в т.ч. TLS-рукопожатие
+	ConnectionStepAuth        = "auth"        // аутентификация и выполнение простой команды (cmd)
+	ConnectionStepCommand     = "command"     // выполнение команды PowerShell
+	ConnectionStepFingerprint = "fingerprint" // получение уникального идентификатора сервера (MachineGuid)
+)
+
+// Результаты этапа проверки подключения.
+const (
+	ConnectionStepOK      = "ok"
+	ConnectionStepWarning = "warning" // этап не пройден, но это не мешает подключению
+	ConnectionStepFailed  = "failed"
+	ConnectionStepSkipped = "skipped" // этап не выполнялся из-за ошибки на предыдущем
+)
+
+// ConnectionTestStep Результат этапа проверки подключения к серверу.
+type ConnectionTestStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Message    string `json:"message,omitempty"` // результат или текст ошибки
+	Hint       string `json:"hint,omitempty"`    // вероятная причина ошибки и способ ее устранения
+}
+
+// ConnectionTestReport Пошаговый отчет о проверке подключения к серверу по WinRM.
+type ConnectionTestReport struct {
+	Success     bool                 `json:"success"`
+	Address     string               `json:"address"`
+	Port        string               `json:"port"`
+	HTTPS       bool                 `json:"https"`
+	Auth        string               `json:"auth"`
+	Fingerprint *uuid.UUID           `json:"fingerprint,omitempty"`
+	DurationMs  int64                `json:"duration_ms"`
+	Steps       []ConnectionTestStep `json:"steps"`
+}
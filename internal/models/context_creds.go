@@ -6,12 +6,16 @@ import (
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
 )
 
-// ContextCredentials Получение login, userID, serverID, serviceID из r.Context()
+// ContextCredentials Получение login, userID, role, teamID, serverID, serviceID, approvalID, API-токена из r.Context()
 type ContextCredentials struct {
-	Login     string
-	UserID    string
-	ServerID  int64
-	ServiceID int64
+	Login      string
+	UserID     string
+	Role       Role
+	TeamID     int64
+	ServerID   int64
+	ServiceID  int64
+	ApprovalID int64
+	APIToken   *APIToken // nil, если запрос аутентифицирован JWT
 }
 
 // GetContextCreds Вытаскивает данные из контекста и возвращает структуру.
@@ -32,6 +36,20 @@ func GetContextCreds(ctx context.Context) *ContextCredentials {
 		}
 	}
 
+	// Role (Role)
+	if v := ctx.Value(contextkeys.Role); v != nil {
+		if role, ok := v.(Role); ok {
+			creds.Role = role
+		}
+	}
+
+	// TeamID (int64)
+	if v := ctx.Value(contextkeys.TeamID); v != nil {
+		if teamID, ok := v.(int64); ok {
+			creds.TeamID = teamID
+		}
+	}
+
 	// ServerID (int64)
 	if v := ctx.Value(contextkeys.ServerID); v != nil {
 		if serverID, ok := v.(int64); ok {
@@ -46,5 +64,28 @@ func GetContextCreds(ctx context.Context) *ContextCredentials {
 		}
 	}
 
+	// ApprovalID (int64)
+	if v := ctx.Value(contextkeys.ApprovalID); v != nil {
+		if approvalID, ok := v.(int64); ok {
+			creds.ApprovalID = approvalID
+		}
+	}
+
+	// APIToken (*APIToken)
+	if v := ctx.Value(contextkeys.APIToken); v != nil {
+		if token, ok := v.(*APIToken); ok {
+			creds.APIToken = token
+		}
+	}
+
 	return creds
 }
+
+// APITokenID Возвращает id API-токена, которым аутентифицирован запрос, или 0 для JWT.
+func (c *ContextCredentials) APITokenID() int64 {
+	if c.APIToken == nil {
+		return 0
+	}
+
+	return c.APIToken.ID
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Статусы запроса на подтверждение действия с критичной службой.
+const (
+	ApprovalStatusPending  = "pending"  // ожидает решения
+	ApprovalStatusApproved = "approved" // подтвержден, действие выполняется
+	ApprovalStatusRejected = "rejected" // отклонен
+	ApprovalStatusExecuted = "executed" // подтвержден и действие выполнено
+	ApprovalStatusFailed   = "failed"   // подтвержден, но действие завершилось ошибкой
+	ApprovalStatusExpired  = "expired"  // истек срок ожидания решения
+)
+
+const approvalCommentMaxLen = 1000
+
+// ControlApproval Модель запроса на подтверждение остановки или перезапуска критичной службы.
+type ControlApproval struct {
+	ID              int64      `json:"id"`
+	TeamID          int64      `json:"team_id"`
+	ServerID        int64      `json:"server_id"`
+	ServiceID       int64      `json:"service_id"`
+	DisplayedName   string     `json:"displayed_name"`
+	Action          string     `json:"action"`
+	Status          string     `json:"status"`
+	RequestedBy     string     `json:"requested_by"`
+	RequestedLogin  string     `json:"requested_by_login"`
+	RequestComment  string     `json:"request_comment,omitempty"`
+	DecidedBy       string     `json:"decided_by,omitempty"`
+	DecidedLogin    string     `json:"decided_by_login,omitempty"`
+	DecisionComment string     `json:"decision_comment,omitempty"`
+	Result          string     `json:"result,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty"`
+}
+
+// ApprovalComment Комментарий к запросу на подтверждение или к решению по нему.
+type ApprovalComment struct {
+	Comment string `json:"comment"`
+}
+
+// Validate Валидация комментария.
+func (c ApprovalComment) Validate() error {
+	if len(c.Comment) > approvalCommentMaxLen {
+		return errors.New("комментарий слишком длинный")
+	}
+
+	return nil
+}
+
+// IsValidApprovalStatus Проверяет, что статус запроса на подтверждение известен.
+func IsValidApprovalStatus(status string) bool {
+	switch status {
+	case ApprovalStatusPending, ApprovalStatusApproved, ApprovalStatusRejected,
+		ApprovalStatusExecuted, ApprovalStatusFailed, ApprovalStatusExpired:
+		return true
+	}
+
+	return false
+}
+
+// RequiresApproval Проверяет, что действие с критичной службой выполняется только после подтверждения.
+func RequiresApproval(action string) bool {
+	return action == ControlActionStop || action == ControlActionRestart
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const credentialProfileNameMaxLen = 250
+
+// CredentialProfile Модель профиля учетных данных команды, общего для нескольких серверов.
+// Связанные с профилем серверы используют его логин, пароль и механизм аутентификации WinRM.
+type CredentialProfile struct {
+	ID           int64     `json:"id,omitempty"`
+	TeamID       int64     `json:"team_id,omitempty"`
+	Name         string    `json:"name"`
+	Username     string    `json:"username"`
+	Password     string    `json:"password,omitempty"`
+	Domain       string    `json:"domain,omitempty"`
+	Auth         *string   `json:"auth,omitempty"` // механизм аутентификации WinRM, если он не задан для сервера
+	ServersCount int       `json:"servers_count"`  // количество связанных серверов
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateValidation Валидация данных при создании профиля.
+func (p CredentialProfile) CreateValidation() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("необходимо указать название профиля")
+	}
+
+	if strings.TrimSpace(p.Username) == "" {
+		return errors.New("необходимо указать логин")
+	}
+
+	// при аутентификации Kerberos пароль не обязателен: билет может быть получен по keytab
+	if p.Password == "" && !(p.Auth != nil && *p.Auth == WinRMAuthKerberos) {
+		return errors.New("необходимо указать пароль")
+	}
+
+	return p.UpdateValidation()
+}
+
+// UpdateValidation Валидация данных при редактировании профиля. Пустые поля не изменяются.
+func (p CredentialProfile) UpdateValidation() error {
+	if len(strings.TrimSpace(p.Name)) > credentialProfileNameMaxLen {
+		return errors.New("название профиля слишком длинное")
+	}
+
+	if strings.ContainsAny(p.Domain, `\@`) {
+		return errors.New("домен указывается без символов '\\' и '@'")
+	}
+
+	if p.Domain != "" && strings.ContainsAny(p.Username, `\@`) {
+		return errors.New("при указанном домене логин указывается без домена")
+	}
+
+	if p.Auth != nil && *p.Auth != "" && !IsValidWinRMAuth(*p.Auth) {
+		return errors.New("неизвестный механизм аутентификации WinRM (допустимо: basic, ntlm, kerberos)")
+	}
+
+	return nil
+}
+
+// Merge Заменяет учетные данные профиля непустыми значениями из update
+// (пустой механизм аутентификации сбрасывает его).
+func (p *CredentialProfile) Merge(update CredentialProfile) {
+	if update.Username != "" {
+		p.Username = update.Username
+	}
+
+	if update.Password != "" {
+		p.Password = update.Password
+	}
+
+	if update.Domain != "" {
+		p.Domain = update.Domain
+	}
+
+	if update.Auth != nil {
+		p.Auth = update.Auth
+		if *update.Auth == "" {
+			p.Auth = nil
+		}
+	}
+}
+
+// Login Возвращает логин для подключения к серверу: DOMAIN\username, если домен задан.
+func (p CredentialProfile) Login() string {
+	if p.Domain == "" {
+		return p.Username
+	}
+
+	return p.Domain + `\` + p.Username
+}
+
+// CredentialTestResult Результат проверки учетных данных профиля на связанном сервере.
+type CredentialTestResult struct {
+	ServerID int64  `json:"server_id"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
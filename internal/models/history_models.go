@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Действия управления службами, фиксируемые в истории.
+const (
+	ControlActionStart   = "start"
+	ControlActionStop    = "stop"
+	ControlActionRestart = "restart"
+)
+
+// ServerStatusEvent Модель записи истории смены статуса сервера.
+type ServerStatusEvent struct {
+	ServerID  int64     `json:"server_id"`
+	Status    Status    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ServiceStatusEvent Модель записи истории смены статуса службы.
+type ServiceStatusEvent struct {
+	ServiceID     int64     `json:"service_id"`
+	ServerID      int64     `json:"server_id"`
+	ServiceName   string    `json:"service_name"`
+	DisplayedName string    `json:"displayed_name"`
+	Status        string    `json:"status"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// ControlAction Модель действия пользователя по управлению службой (start/stop/restart).
+type ControlAction struct {
+	ID        int64     `json:"id,omitempty"`
+	UserID    string    `json:"user_id"`
+	ServerID  int64     `json:"server_id"`
+	ServiceID int64     `json:"service_id"`
+	Action    string    `json:"action"`
+	Success   bool      `json:"success"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
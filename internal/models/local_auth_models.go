@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Ограничения пароля встроенной аутентификации (bcrypt учитывает только первые 72 байта).
+const (
+	PasswordMinLen = 8
+	PasswordMaxLen = 72
+)
+
+const localLoginMaxLen = 250
+
+// LocalUser Пользователь встроенной аутентификации с хэшем пароля.
+// Пустой PasswordHash - пароль не задан (пользователь внешнего провайдера).
+type LocalUser struct {
+	User
+	PasswordHash string
+}
+
+// Credentials Логин и пароль для входа.
+type Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// NewLocalUser Данные нового пользователя встроенной аутентификации.
+type NewLocalUser struct {
+	Login    string `json:"login"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// PasswordChange Запрос на смену пароля.
+type PasswordChange struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// RefreshRequest Запрос на обновление пары токенов или выход.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthTokens Пара токенов встроенной аутентификации.
+type AuthTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"` // окончание срока действия access-токена
+}
+
+// Validate Валидация данных нового пользователя.
+func (u NewLocalUser) Validate() error {
+	if strings.TrimSpace(u.Login) == "" {
+		return errors.New("необходимо указать логин")
+	}
+
+	if len(u.Login) > localLoginMaxLen {
+		return errors.New("логин слишком длинный")
+	}
+
+	if u.Email != "" && !strings.Contains(u.Email, "@") {
+		return errors.New("некорректный email")
+	}
+
+	return ValidatePassword(u.Password)
+}
+
+// Validate Валидация запроса на смену пароля.
+func (p PasswordChange) Validate() error {
+	if p.OldPassword == "" {
+		return errors.New("необходимо указать текущий пароль")
+	}
+
+	if p.OldPassword == p.NewPassword {
+		return errors.New("новый пароль должен отличаться от текущего")
+	}
+
+	return ValidatePassword(p.NewPassword)
+}
+
+// ValidatePassword Проверяет длину пароля.
+func ValidatePassword(password string) error {
+	if len(password) < PasswordMinLen {
+		return errors.New("пароль должен содержать не менее 8 символов")
+	}
+
+	if len(password) > PasswordMaxLen {
+		return errors.New("пароль не должен превышать 72 байта")
+	}
+
+	return nil
+}
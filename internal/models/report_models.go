@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// ReportPeriod Период, за который формируется отчет.
+type ReportPeriod string
+
+const (
+	ReportDaily  ReportPeriod = "daily"
+	ReportWeekly ReportPeriod = "weekly"
+)
+
+// IsValid Валидация периода отчета.
+func (p ReportPeriod) IsValid() bool {
+	switch p {
+	case ReportDaily, ReportWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Duration Длительность периода отчета.
+func (p ReportPeriod) Duration() time.Duration {
+	if p == ReportWeekly {
+		return 7 * 24 * time.Hour
+	}
+
+	return 24 * time.Hour
+}
+
+// Report Модель сводного отчета пользователя за период.
+type Report struct {
+	UserID           string                `json:"user_id"`
+	Login            string                `json:"login"`
+	Period           ReportPeriod          `json:"period"`
+	From             time.Time             `json:"from"`
+	To               time.Time             `json:"to"`
+	ServersMonitored int                   `json:"servers_monitored"`
+	Servers          []ServerUptime        `json:"servers"`
+	ServiceOutages   []ServiceOutage       `json:"service_outages"`
+	ControlActions   []ControlActionsStats `json:"control_actions"`
+}
+
+// ServerUptime Доступность сервера за период отчета.
+type ServerUptime struct {
+	ServerID      int64   `json:"server_id"`
+	Name          string  `json:"name"`
+	Address       string  `json:"address"`
+	UptimePercent float64 `json:"uptime_percent"`
+	// HasData false, если за период по серверу нет ни одной записи истории.
+	HasData bool `json:"has_data"`
+}
+
+// ServiceOutage Суммарный простой службы за период отчета.
+type ServiceOutage struct {
+	ServerID      int64  `json:"server_id"`
+	ServerName    string `json:"server_name"`
+	ServiceID     int64  `json:"service_id"`
+	ServiceName   string `json:"service_name"`
+	DisplayedName string `json:"displayed_name"`
+	Outages       int    `json:"outages"`
+	DownSeconds   int64  `json:"down_seconds"`
+}
+
+// ControlActionsStats Количество действий управления службами за период отчета.
+type ControlActionsStats struct {
+	Action  string `json:"action"`
+	Success int    `json:"success"`
+	Failed  int    `json:"failed"`
+}
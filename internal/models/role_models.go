@@ -0,0 +1,83 @@
+package models
+
+import "slices"
+
+// Role Роль пользователя в приложении.
+type Role string
+
+// Роли пользователей (в порядке возрастания прав).
+const (
+	RoleViewer   Role = "viewer"   // только просмотр серверов, служб, статусов и отчетов
+	RoleOperator Role = "operator" // просмотр и управление службами (запуск, остановка, перезапуск)
+	RoleAdmin    Role = "admin"    // управление серверами и службами, доступ к данным всех пользователей
+)
+
+// roleLevels Уровни прав ролей. Чем больше значение, тем больше прав.
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// IsValidRole Проверяет, что роль известна приложению.
+func IsValidRole(role Role) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// Allows Проверяет, что роль дает права не ниже требуемой.
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+	if !ok {
+		return false
+	}
+
+	return level >= roleLevels[required]
+}
+
+// MinRole Возвращает роль с наименьшими правами из двух.
+// Неизвестная роль не дает прав, поэтому считается наименьшей.
+func MinRole(a, b Role) Role {
+	if !IsValidRole(a) || !IsValidRole(b) {
+		return ""
+	}
+
+	if roleLevels[a] <= roleLevels[b] {
+		return a
+	}
+
+	return b
+}
+
+// HighestRole Возвращает роль с наибольшими правами из списка. Неизвестные роли пропускаются.
+// Если известных ролей нет - возвращает пустую роль.
+func HighestRole(roles []Role) Role {
+	var highest Role
+
+	for _, role := range roles {
+		if IsValidRole(role) && roleLevels[role] > roleLevels[highest] {
+			highest = role
+		}
+	}
+
+	return highest
+}
+
+// RolePolicy Правила определения итоговой роли пользователя.
+type RolePolicy struct {
+	DefaultRole Role     // роль пользователей, которым провайдер не назначил ни одной роли
+	AdminLogins []string // логины, которым всегда назначается роль администратора
+}
+
+// Resolve Определяет итоговую роль пользователя по ролям из токена.
+func (p RolePolicy) Resolve(login string, roles []Role) Role {
+	if login != "" && slices.Contains(p.AdminLogins, login) {
+		return RoleAdmin
+	}
+
+	if role := HighestRole(roles); role != "" {
+		return role
+	}
+
+	return p.DefaultRole
+}
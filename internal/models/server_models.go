@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +13,106 @@ import (
 
 const serverNameLen = 3
 
+// secretPathRegex Допустимый путь к секрету: сегменты из латиницы, цифр, '.', '_' и '-', разделенные '/'.
+var secretPathRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
 // Server Модель сервера.
 type Server struct {
-	ID          int64     `json:"id,omitempty"`
-	Name        string    `json:"name"`
-	Address     string    `json:"address"`
-	Username    string    `json:"username"`
-	Password    string    `json:"password,omitempty"`
-	Fingerprint uuid.UUID `json:"fingerprint"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int64         `json:"id,omitempty"`
+	TeamID      int64         `json:"team_id,omitempty"` // команда-владелец (по умолчанию - собственная команда пользователя)
+	Name        string        `json:"name"`
+	Address     string        `json:"address"`
+	Username    string        `json:"username"`
+	Password    string        `json:"password,omitempty"`
+	Fingerprint uuid.UUID     `json:"fingerprint"`
+	WinRM       WinRMSettings `json:"winrm"` // параметры подключения по WinRM (незаданные берутся из конфигурации)
+	CreatedAt   time.Time     `json:"created_at"`
+
+	// CredentialProfileID Профиль учетных данных, логин и пароль которого использует сервер.
+	// При редактировании 0 отвязывает сервер от профиля.
+	CredentialProfileID *int64 `json:"credential_profile_id,omitempty"`
+	// CredentialAuth Механизм аутентификации WinRM из профиля учетных данных (применяется, если не задан для сервера).
+	CredentialAuth *string `json:"-"`
+	// SecretPath Путь к секрету во внешнем хранилище (Vault KV), из которого берутся пароль и, если задан, логин сервера.
+	// Пароль такого сервера в БД не хранится. При редактировании пустая строка отвязывает сервер от секрета.
+	SecretPath *string `json:"secret_path,omitempty"`
+}
+
+// ConnectionSettings Возвращает параметры подключения к серверу по WinRM с учетом профиля учетных данных.
+func (s Server) ConnectionSettings() WinRMSettings {
+	settings := s.WinRM
+
+	if settings.Auth == nil && s.CredentialAuth != nil {
+		settings.Auth = s.CredentialAuth
+	}
+
+	return settings
+}
+
+// HasCredentialProfile Проверяет, использует ли сервер профиль учетных данных.
+func (s Server) HasCredentialProfile() bool {
+	return s.CredentialProfileID != nil && *s.CredentialProfileID > 0
+}
+
+// HasSecret Проверяет, берутся ли учетные данные сервера из внешнего хранилища секретов.
+func (s Server) HasSecret() bool {
+	return s.SecretPath != nil && *s.SecretPath != ""
+}
+
+// TeamSecretPrefix Возвращает префикс путей к секретам, доступных серверам команды teamID.
+func TeamSecretPrefix(teamID int64) string {
+	return fmt.Sprintf("teams/%d/", teamID)
+}
+
+// IsTeamSecretPath Проверяет, что путь к секрету находится в пространстве команды teamID.
+func IsTeamSecretPath(path string, teamID int64) bool {
+	prefix := TeamSecretPrefix(teamID)
+	return strings.HasPrefix(path, prefix) && len(path) > len(prefix)
+}
+
+// validateSecretPath Проверяет путь к секрету.
+func validateSecretPath(path string) error {
+	if !secretPathRegex.MatchString(path) {
+		return fmt.Errorf("неверный путь к секрету: %s", path)
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("неверный путь к секрету: %s", path)
+		}
+	}
+
+	return nil
+}
+
+// SecretValidation Проверяет ссылку сервера на секрет: путь должен находиться в пространстве команды сервера
+// (teams/<teamID>/...), а учетные данные из секрета не передаются по Basic без HTTPS.
+// Если команда сервера еще не определена, проверяется только формат пути.
+func (s Server) SecretValidation() error {
+	if !s.HasSecret() {
+		return nil
+	}
+
+	path := *s.SecretPath
+
+	if err := validateSecretPath(path); err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(path, "teams/") {
+		return errors.New("путь к секрету должен начинаться с teams/<идентификатор команды>/")
+	}
+
+	if s.TeamID != 0 && !IsTeamSecretPath(path, s.TeamID) {
+		return fmt.Errorf("путь к секрету должен начинаться с %s", TeamSecretPrefix(s.TeamID))
+	}
+
+	auth := s.ConnectionSettings().Auth
+	if auth != nil && *auth == WinRMAuthBasic && s.WinRM.HTTPS != nil && !*s.WinRM.HTTPS {
+		return errors.New("учетные данные из секрета не передаются по Basic без HTTPS")
+	}
+
+	return nil
 }
 
 // CreateValidation Базовая валидация данных при создании сервера.
@@ -29,16 +121,47 @@ func (s Server) CreateValidation() error {
 		return errors.New("необходимо указать имя сервера (минимум 3 символа)")
 	}
 
+	return s.ConnectionValidation()
+}
+
+// ConnectionValidation Валидация адреса, учетных данных и параметров WinRM сервера
+// (при создании сервера и при проверке подключения к нему).
+func (s Server) ConnectionValidation() error {
 	if len(s.Address) == 0 {
 		return errors.New("необходимо указать адрес сервера")
 	}
 
-	if len(s.Username) == 0 {
+	switch {
+	case s.CredentialProfileID != nil && *s.CredentialProfileID <= 0:
+		return errors.New("неверный идентификатор профиля учетных данных")
+	case s.SecretPath != nil:
+		// пароль и, при наличии, логин берутся из секрета
+		if err := validateSecretPath(*s.SecretPath); err != nil {
+			return err
+		}
+		if err := s.SecretValidation(); err != nil {
+			return err
+		}
+		if s.HasCredentialProfile() {
+			return errors.New("сервер не может одновременно использовать профиль учетных данных и секрет")
+		}
+		if s.Password != "" {
+			return errors.New("пароль сервера задается секретом")
+		}
+	case s.HasCredentialProfile():
+		// логин и пароль берутся из профиля учетных данных
+		if s.Username != "" || s.Password != "" {
+			return errors.New("логин и пароль сервера задаются профилем учетных данных")
+		}
+	case len(s.Username) == 0:
 		return errors.New("необходимо указать логин")
+	// при аутентификации Kerberos пароль не обязателен: билет может быть получен по keytab
+	case len(s.Password) == 0 && !s.WinRM.IsKerberos():
+		return errors.New("необходимо указать пароль")
 	}
 
-	if len(s.Password) == 0 {
-		return errors.New("необходимо указать пароль")
+	if err := s.WinRM.Validate(); err != nil {
+		return err
 	}
 
 	addr := s.Address
@@ -99,5 +222,35 @@ func (s Server) UpdateValidation() error {
 		return errors.New("необходимо указать пароль")
 	}
 
-	return nil
+	if s.CredentialProfileID != nil {
+		switch {
+		case *s.CredentialProfileID < 0:
+			return errors.New("неверный идентификатор профиля учетных данных")
+		case *s.CredentialProfileID > 0 && (s.Username != "" || s.Password != ""):
+			return errors.New("логин и пароль сервера задаются профилем учетных данных")
+		case *s.CredentialProfileID == 0 && !s.HasSecret() && (s.Username == "" || (s.Password == "" && !s.WinRM.IsKerberos())):
+			return errors.New("при отвязке профиля учетных данных необходимо указать логин и пароль сервера")
+		}
+	}
+
+	if s.SecretPath != nil {
+		switch {
+		case s.HasSecret():
+			if err := validateSecretPath(*s.SecretPath); err != nil {
+				return err
+			}
+			if s.HasCredentialProfile() {
+				return errors.New("сервер не может одновременно использовать профиль учетных данных и секрет")
+			}
+			if s.Password != "" {
+				return errors.New("пароль сервера задается секретом")
+			}
+		case s.HasCredentialProfile():
+			// сервер переходит на профиль учетных данных, собственные логин и пароль не нужны
+		case s.Username == "" || (s.Password == "" && !s.WinRM.IsKerberos()):
+			return errors.New("при отвязке секрета необходимо указать логин и пароль сервера")
+		}
+	}
+
+	return s.WinRM.Validate()
 }
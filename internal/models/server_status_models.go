@@ -28,6 +28,9 @@ func (s Status) String() string {
 type ServerStatus struct {
 	ServerID int64  `json:"server_id"`
 	UserID   string `json:"user_id"`
+	TeamID   int64  `json:"team_id,omitempty"` // команда-владелец сервера
 	Address  string `json:"address"`
 	Status   Status `json:"status"`
+
+	WinRM WinRMSettings `json:"-"` // параметры подключения по WinRM (нужны только воркеру проверки доступности)
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ServicePermission Правило доступа к управлению службой. Выдается либо конкретному
+// пользователю (UserID), либо роли (Role) — тогда действует для этой роли и всех ролей выше нее.
+type ServicePermission struct {
+	ID        int64     `json:"id,omitempty"`
+	ServiceID int64     `json:"service_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Role      Role      `json:"role,omitempty"`
+	Start     bool      `json:"start"`
+	Stop      bool      `json:"stop"`
+	Restart   bool      `json:"restart"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate Валидация правила доступа: должен быть указан ровно один субъект — пользователь или роль.
+func (p ServicePermission) Validate() error {
+	if (p.UserID == "") == (p.Role == "") {
+		return errors.New("необходимо указать либо пользователя, либо роль")
+	}
+
+	if p.Role != "" && !IsValidRole(p.Role) {
+		return errors.New("неизвестная роль: допустимы viewer, operator или admin")
+	}
+
+	return nil
+}
+
+// Matches Проверяет, что правило относится к пользователю с указанными id и ролью.
+func (p ServicePermission) Matches(userID string, role Role) bool {
+	if p.UserID != "" {
+		return p.UserID == userID
+	}
+
+	return role.Allows(p.Role)
+}
+
+// ServiceCapabilities Действия управления службой, доступные текущему пользователю.
+type ServiceCapabilities struct {
+	Start   bool `json:"start"`
+	Stop    bool `json:"stop"`
+	Restart bool `json:"restart"`
+}
+
+// Allows Проверяет, что действие управления службой (start, stop, restart) доступно.
+func (c ServiceCapabilities) Allows(action string) bool {
+	switch action {
+	case ControlActionStart:
+		return c.Start
+	case ControlActionStop:
+		return c.Stop
+	case ControlActionRestart:
+		return c.Restart
+	}
+
+	return false
+}
+
+// ResolveServiceCapabilities Определяет доступные пользователю действия со службой.
+// Управлять службами может только роль operator и выше. Если для службы не задано ни одного правила,
+// доступны все действия, иначе — только разрешенные подходящими пользователю правилами.
+func ResolveServiceCapabilities(userID string, role Role, permissions []*ServicePermission) ServiceCapabilities {
+	if !role.Allows(RoleOperator) {
+		return ServiceCapabilities{}
+	}
+
+	if len(permissions) == 0 {
+		return ServiceCapabilities{Start: true, Stop: true, Restart: true}
+	}
+
+	var caps ServiceCapabilities
+
+	for _, p := range permissions {
+		if !p.Matches(userID, role) {
+			continue
+		}
+
+		caps.Start = caps.Start || p.Start
+		caps.Stop = caps.Stop || p.Stop
+		caps.Restart = caps.Restart || p.Restart
+	}
+
+	return caps
+}
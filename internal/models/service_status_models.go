@@ -13,6 +13,12 @@ type Service struct {
 	Status        string    `json:"status,omitempty"`
 	CreatedAt     time.Time `json:"created_at,omitempty"`
 	UpdatedAt     time.Time `json:"updated_at,omitempty"`
+
+	// Critical Остановка и перезапуск службы выполняются только после подтверждения другим пользователем.
+	Critical bool `json:"critical"`
+
+	// Capabilities Действия управления, доступные текущему пользователю (заполняется хендлером).
+	Capabilities *ServiceCapabilities `json:"capabilities,omitempty"`
 }
 
 // Validate Базовая валидация данных.
@@ -36,6 +42,15 @@ type ServiceStatus struct {
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
 
+// ServiceState Модель текущего состояния службы (для метрик).
+type ServiceState struct {
+	ServiceID   int64  `json:"service_id"`
+	ServerID    int64  `json:"server_id"`
+	TeamID      int64  `json:"team_id"` // команда-владелец сервера
+	ServiceName string `json:"service_name"`
+	Status      string `json:"status"`
+}
+
 // ServiceName Модель с названием и отображаемым именем службы для отдачи клиенту.
 type ServiceName struct {
 	Name        string `json:"name"`
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// TeamInvitationTTL Срок действия приглашения в команду.
+const TeamInvitationTTL = 7 * 24 * time.Hour
+
+const teamNameMaxLen = 250
+
+// Team Модель команды (рабочего пространства), которой принадлежат серверы и службы.
+type Team struct {
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role,omitempty"` // роль текущего пользователя в команде
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate Валидация данных команды.
+func (t Team) Validate() error {
+	name := strings.TrimSpace(t.Name)
+
+	if name == "" {
+		return errors.New("необходимо указать название команды")
+	}
+
+	if len(name) > teamNameMaxLen {
+		return errors.New("название команды слишком длинное")
+	}
+
+	return nil
+}
+
+// TeamMember Модель участника команды.
+type TeamMember struct {
+	UserID    string    `json:"user_id"`
+	Login     string    `json:"login"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamInvitation Модель приглашения пользователя в команду.
+type TeamInvitation struct {
+	ID        int64     `json:"id,omitempty"`
+	TeamID    int64     `json:"team_id"`
+	TeamName  string    `json:"team_name,omitempty"`
+	Login     string    `json:"login"`
+	Role      Role      `json:"role"`
+	InvitedBy string    `json:"invited_by,omitempty"` // логин пригласившего
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Validate Валидация данных приглашения.
+func (i TeamInvitation) Validate() error {
+	if strings.TrimSpace(i.Login) == "" {
+		return errors.New("необходимо указать логин приглашаемого пользователя")
+	}
+
+	if !IsValidRole(i.Role) {
+		return errors.New("необходимо указать роль: viewer, operator или admin")
+	}
+
+	return nil
+}
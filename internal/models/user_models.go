@@ -4,4 +4,9 @@ package models
 type User struct {
 	ID    string `json:"id,omitempty"`
 	Login string `json:"login"`
+	Email string `json:"email,omitempty"`
+	// Disabled Пользователь отключен в провайдере аутентификации, запросы к API отклоняются.
+	Disabled bool `json:"disabled,omitempty"`
+	// Role Роль при последнем интерактивном входе, пустая - неизвестна. Ограничивает роль API-токенов пользователя.
+	Role Role `json:"-"`
 }
@@ -0,0 +1,38 @@
+package models
+
+// Тип запуска службы Windows.
+const (
+	ServiceStartBoot     = "boot"
+	ServiceStartSystem   = "system"
+	ServiceStartAuto     = "auto"
+	ServiceStartManual   = "manual"
+	ServiceStartDisabled = "disabled"
+)
+
+// WindowsServiceState Текущее состояние службы на удаленном сервере.
+type WindowsServiceState struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+
+	// State Состояние службы (utils.ServiceRunning, utils.ServiceStopped и т.д.), Status — его описание.
+	State  int    `json:"state"`
+	Status string `json:"status"`
+
+	ProcessID               int  `json:"process_id"`                 // 0, если служба не запущена
+	ExitCode                int  `json:"exit_code"`                  // код Win32, с которым служба завершилась в последний раз
+	ServiceSpecificExitCode int  `json:"service_specific_exit_code"` // код самой службы (при ExitCode 1066)
+	AcceptStop              bool `json:"accept_stop"`
+	AcceptPause             bool `json:"accept_pause"`
+}
+
+// WindowsServiceConfig Конфигурация службы на удаленном сервере.
+type WindowsServiceConfig struct {
+	Name             string   `json:"name"`
+	DisplayName      string   `json:"display_name"`
+	Description      string   `json:"description"`
+	StartType        string   `json:"start_type"` // ServiceStartAuto, ServiceStartManual и т.д.
+	DelayedAutoStart bool     `json:"delayed_auto_start"`
+	Account          string   `json:"account"`     // учетная запись, от имени которой запускается служба
+	BinaryPath       string   `json:"binary_path"` // командная строка исполняемого файла службы
+	DependsOn        []string `json:"depends_on"`  // службы, без которых служба не запускается
+}
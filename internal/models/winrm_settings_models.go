@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	maxWinRMTimeout        = 3600 // максимальный таймаут выполнения команд, секунды
+	maxWinRMConnectTimeout = 60   // максимальный таймаут проверки доступности, секунды
+)
+
+// Механизмы аутентификации WinRM.
+const (
+	WinRMAuthBasic    = "basic"    // Basic, требует AllowUnencrypted=true для HTTP
+	WinRMAuthNTLM     = "ntlm"     // NTLM, по HTTP сообщения шифруются (AllowUnencrypted не нужен)
+	WinRMAuthKerberos = "kerberos" // Kerberos по keytab или паролю, только HTTPS
+)
+
+// IsValidWinRMAuth Проверяет, поддерживается ли механизм аутентификации WinRM.
+func IsValidWinRMAuth(auth string) bool {
+	switch auth {
+	case WinRMAuthBasic, WinRMAuthNTLM, WinRMAuthKerberos:
+		return true
+	}
+
+	return false
+}
+
+// WinRMSettings Параметры подключения к серверу по WinRM.
+// Незаданный (nil) параметр берется из глобальной конфигурации (WINRM_PORT, WINRM_USE_HTTPS, WINRM_INSECURE_FOR_HTTPS, WINRM_AUTH).
+type WinRMSettings struct {
+	Auth           *string `json:"auth,omitempty"` // механизм аутентификации: basic, ntlm, kerberos
+	Port           *int    `json:"port,omitempty"`
+	HTTPS          *bool   `json:"https,omitempty"`
+	Insecure       *bool   `json:"insecure,omitempty"`        // не проверять сертификат сервера (только для HTTPS)
+	Timeout        *int    `json:"timeout,omitempty"`         // таймаут выполнения команд, секунды
+	ConnectTimeout *int    `json:"connect_timeout,omitempty"` // таймаут проверки доступности WinRM, секунды
+}
+
+// Validate Валидация параметров WinRM. Нулевые значения допустимы: они сбрасывают параметр к глобальному.
+func (s WinRMSettings) Validate() error {
+	if s.Port != nil && (*s.Port < 0 || *s.Port > 65535) {
+		return fmt.Errorf("неверный порт WinRM: %d", *s.Port)
+	}
+
+	if s.Timeout != nil && (*s.Timeout < 0 || *s.Timeout > maxWinRMTimeout) {
+		return fmt.Errorf("таймаут WinRM должен быть от 1 до %d секунд", maxWinRMTimeout)
+	}
+
+	if s.ConnectTimeout != nil && (*s.ConnectTimeout < 0 || *s.ConnectTimeout > maxWinRMConnectTimeout) {
+		return fmt.Errorf("таймаут проверки доступности WinRM должен быть от 1 до %d секунд", maxWinRMConnectTimeout)
+	}
+
+	if s.Insecure != nil && *s.Insecure && s.HTTPS != nil && !*s.HTTPS {
+		return errors.New("отключение проверки сертификата допустимо только для HTTPS")
+	}
+
+	if s.Auth != nil && *s.Auth != "" {
+		if !IsValidWinRMAuth(*s.Auth) {
+			return fmt.Errorf("неизвестный механизм аутентификации WinRM: %s (допустимо: basic, ntlm, kerberos)", *s.Auth)
+		}
+
+		if *s.Auth == WinRMAuthKerberos && s.HTTPS != nil && !*s.HTTPS {
+			return errors.New("аутентификация Kerberos допустима только для HTTPS")
+		}
+	}
+
+	return nil
+}
+
+// Merge Возвращает параметры, в которых заданные в input значения заменяют текущие.
+// Нулевой порт или таймаут, а также пустой механизм аутентификации сбрасывает параметр к глобальному значению.
+func (s WinRMSettings) Merge(input WinRMSettings) WinRMSettings {
+	if input.Auth != nil {
+		s.Auth = input.Auth
+		if *input.Auth == "" {
+			s.Auth = nil
+		}
+	}
+
+	if input.Port != nil {
+		s.Port = nonZero(input.Port)
+	}
+
+	if input.HTTPS != nil {
+		s.HTTPS = input.HTTPS
+	}
+
+	if input.Insecure != nil {
+		s.Insecure = input.Insecure
+	}
+
+	if input.Timeout != nil {
+		s.Timeout = nonZero(input.Timeout)
+	}
+
+	if input.ConnectTimeout != nil {
+		s.ConnectTimeout = nonZero(input.ConnectTimeout)
+	}
+
+	return s
+}
+
+// IsKerberos Проверяет, задана ли для сервера аутентификация Kerberos.
+func (s WinRMSettings) IsKerberos() bool {
+	return s.Auth != nil && *s.Auth == WinRMAuthKerberos
+}
+
+// nonZero Возвращает nil для нулевого значения.
+func nonZero(v *int) *int {
+	if *v == 0 {
+		return nil
+	}
+
+	return v
+}
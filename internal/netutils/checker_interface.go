@@ -9,6 +9,8 @@ import (
 
 // Checker Интерфейс для проверки доступности серверов по сети.
 type Checker interface {
-	CheckWinRM(ctx context.Context, address string, port string, timeout time.Duration) bool
+	CheckWinRM(ctx context.Context, address string, port string, useHTTPS bool, timeout time.Duration) bool
 	CheckICMP(ctx context.Context, address string, timeout time.Duration) bool
+	CheckTCP(ctx context.Context, address string, port string, timeout time.Duration) error
+	LookupHost(ctx context.Context, address string) ([]string, error)
 }
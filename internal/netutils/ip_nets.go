@@ -0,0 +1,49 @@
+package netutils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseIPNets Разбор списка адресов и подсетей (CIDR). Одиночный адрес считается подсетью из одного адреса.
+func ParseIPNets(items []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(items))
+
+	for _, item := range items {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("неверный IP-адрес: %s", item)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("неверная подсеть %s: %w", item, err)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+// ContainsIP Проверяет, что адрес входит в одну из подсетей.
+func ContainsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
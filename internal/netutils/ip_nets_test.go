@@ -0,0 +1,29 @@
+package netutils
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseIPNets Проверяет разбор списка адресов и подсетей.
+func TestParseIPNets(t *testing.T) {
+	nets, err := ParseIPNets([]string{"192.0.2.1", "10.0.0.0/8", "2001:db8::/32", "::1"})
+	require.NoError(t, err)
+	require.Len(t, nets, 4)
+	assert.Equal(t, "192.0.2.1/32", nets[0].String())
+	assert.Equal(t, "10.0.0.0/8", nets[1].String())
+	assert.Equal(t, "::1/128", nets[3].String())
+
+	assert.True(t, ContainsIP(nets, net.ParseIP("10.1.2.3")))
+	assert.True(t, ContainsIP(nets, net.ParseIP("2001:db8::5")))
+	assert.False(t, ContainsIP(nets, net.ParseIP("192.0.2.2")))
+
+	_, err = ParseIPNets([]string{"not-an-ip"})
+	assert.Error(t, err)
+
+	_, err = ParseIPNets([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
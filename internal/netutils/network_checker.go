@@ -24,11 +24,11 @@ func NewNetworkChecker() *NetworkChecker {
 }
 
 // CheckWinRM CheckWinRM проверяет доступность WinRM сервиса.
-// Поддерживает HTTP (5985) и HTTPS (5986).
+// Поддерживает HTTP (по умолчанию 5985) и HTTPS (useHTTPS, по умолчанию 5986) на любом порту.
 // Если соединение успешно установлено — хост считается доступным.
 // Отправляет POST запрос к /wsman и проверяет HTTP ответ.
 // Если timeout <= 0, используется DefaultHostTimeout.
-func (nc *NetworkChecker) CheckWinRM(ctx context.Context, address, port string, timeout time.Duration) bool {
+func (nc *NetworkChecker) CheckWinRM(ctx context.Context, address, port string, useHTTPS bool, timeout time.Duration) bool {
 	if timeout <= 0 {
 		timeout = DefaultHostTimeout
 	}
@@ -53,8 +53,8 @@ func (nc *NetworkChecker) CheckWinRM(ctx context.Context, address, port string,
 		}
 	}()
 
-	// WinRM over HTTPS (5986)
-	if port == "5986" {
+	// WinRM over HTTPS
+	if useHTTPS {
 		tlsConn := tls.Client(rawConn, &tls.Config{
 			InsecureSkipVerify: true, // допустимо для мониторинга
 			ServerName:         address,
@@ -147,3 +147,33 @@ func (nc *NetworkChecker) CheckICMP(ctx context.Context, address string, timeout
 		return ok
 	}
 }
+
+// CheckTCP Проверяет, что TCP-порт сервера принимает соединения. В отличие от CheckWinRM,
+// возвращает ошибку подключения (отказ в соединении, таймаут), чтобы по ней можно было определить причину.
+// Если timeout <= 0, используется DefaultHostTimeout.
+func (nc *NetworkChecker) CheckTCP(ctx context.Context, address, port string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultHostTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, port))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// LookupHost Разрешает имя сервера в IP-адреса. Для IP-адреса возвращает его самого.
+func (nc *NetworkChecker) LookupHost(ctx context.Context, address string) ([]string, error) {
+	if net.ParseIP(address) != nil {
+		return []string{address}, nil
+	}
+
+	return net.DefaultResolver.LookupHost(ctx, address)
+}
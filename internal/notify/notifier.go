@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+//go:generate mockgen -destination=mocks/notifier_mock.go -package=mocks . Notifier
+
+// Notifier Интерфейс канала отправки уведомлений.
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// Message Уведомление, отправляемое пользователю.
+type Message struct {
+	Recipient   string       `json:"recipient"` // email получателя, может быть пустым
+	Subject     string       `json:"subject"`
+	Text        string       `json:"text"`
+	HTML        string       `json:"-"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment Вложение уведомления.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// MultiNotifier Отправляет уведомление во все настроенные каналы.
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier Конструктор MultiNotifier.
+func NewMultiNotifier(notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{
+		notifiers: notifiers,
+	}
+}
+
+// Notify Отправляет уведомление во все каналы. Ошибка одного канала
+// не прерывает отправку в остальные, все ошибки возвращаются вместе.
+func (m *MultiNotifier) Notify(ctx context.Context, msg *Message) error {
+	var errList []error
+
+	for _, n := range m.notifiers {
+		if err := n.Notify(ctx, msg); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return errors.Join(errList...)
+}
+
+// Len Количество настроенных каналов.
+func (m *MultiNotifier) Len() int {
+	return len(m.notifiers)
+}
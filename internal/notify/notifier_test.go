@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestMessage Создает тестовое уведомление.
+func createTestMessage() *Message {
+	return &Message{
+		Recipient: "user@example.com",
+		Subject:   "Отчет",
+		Text:      "текст",
+		HTML:      "<p>отчет</p>",
+		Attachments: []Attachment{
+			{Name: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n")},
+		},
+	}
+}
+
+// fakeNotifier Канал уведомлений для тестов, запоминающий полученные сообщения.
+// Мок из пакета mocks здесь использовать нельзя из-за циклического импорта.
+type fakeNotifier struct {
+	err      error
+	received []*Message
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, msg *Message) error {
+	f.received = append(f.received, msg)
+	return f.err
+}
+
+// TestMultiNotifier Проверяет отправку уведомления во все каналы.
+func TestMultiNotifier(t *testing.T) {
+	sendErr := errors.New("send error")
+
+	first := &fakeNotifier{err: sendErr}
+	second := &fakeNotifier{}
+
+	msg := createTestMessage()
+
+	multi := NewMultiNotifier(first, second)
+	assert.Equal(t, 2, multi.Len())
+
+	// ошибка первого канала не должна прерывать отправку во второй
+	err := multi.Notify(context.Background(), msg)
+	assert.ErrorIs(t, err, sendErr)
+	assert.Equal(t, []*Message{msg}, first.received)
+	assert.Equal(t, []*Message{msg}, second.received)
+}
+
+// TestSMTPNotifier Проверяет формирование и отправку письма.
+func TestSMTPNotifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		recipient   string
+		sendErr     error
+		expectSend  bool
+		expectError bool
+	}{
+		{"успешная отправка", "swsm@example.com", "user@example.com", nil, true, false},
+		{"нет получателя - письмо не отправляется", "swsm@example.com", "", nil, false, false},
+		{"не задан отправитель", "", "user@example.com", nil, false, true},
+		{"ошибка SMTP", "swsm@example.com", "user@example.com", errors.New("smtp error"), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewSMTPNotifier("smtp.example.com", "587", "user", "pass", tt.from)
+
+			var sent bool
+			var sentTo []string
+			var sentBody string
+
+			n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				sent = true
+				assert.Equal(t, "smtp.example.com:587", addr)
+				assert.NotNil(t, a)
+				sentTo = to
+				sentBody = string(msg)
+				return tt.sendErr
+			}
+
+			msg := createTestMessage()
+			msg.Recipient = tt.recipient
+
+			err := n.Notify(context.Background(), msg)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectSend, sent)
+			if tt.expectSend {
+				assert.Equal(t, []string{"user@example.com"}, sentTo)
+				assert.Contains(t, sentBody, "Content-Type: multipart/mixed")
+				assert.Contains(t, sentBody, "text/html; charset=utf-8")
+				assert.Contains(t, sentBody, `attachment; filename=report.csv`)
+			}
+		})
+	}
+}
+
+// TestSMTPNotifierWithoutAuth Проверяет отправку без аутентификации.
+func TestSMTPNotifierWithoutAuth(t *testing.T) {
+	n := NewSMTPNotifier("smtp.example.com", "25", "", "", "swsm@example.com")
+
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Nil(t, a)
+		return nil
+	}
+
+	assert.NoError(t, n.Notify(context.Background(), createTestMessage()))
+}
+
+// TestWebhookNotifier Проверяет отправку уведомления на вебхук.
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectError bool
+	}{
+		{"успешная отправка", http.StatusOK, false},
+		{"вебхук вернул ошибку", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]any
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				body, _ := io.ReadAll(r.Body)
+				require.NoError(t, json.Unmarshal(body, &received))
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewWebhookNotifier(srv.URL).Notify(context.Background(), createTestMessage())
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.True(t, strings.Contains(err.Error(), "500"))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, "user@example.com", received["recipient"])
+			assert.Equal(t, "Отчет", received["subject"])
+			assert.NotContains(t, received, "HTML")
+			assert.Len(t, received["attachments"], 1)
+		})
+	}
+}
+
+// TestWebhookNotifierUnavailable Проверяет ошибку при недоступном вебхуке.
+func TestWebhookNotifierUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	err := NewWebhookNotifier(url).Notify(context.Background(), createTestMessage())
+	assert.Error(t, err)
+}
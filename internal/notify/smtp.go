@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// SMTPNotifier Отправка уведомлений по email через SMTP.
+type SMTPNotifier struct {
+	addr     string
+	from     string
+	auth     smtp.Auth
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPNotifier Конструктор SMTPNotifier. Если username пустой, отправка производится без аутентификации.
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPNotifier{
+		addr:     net.JoinHostPort(host, port),
+		from:     from,
+		auth:     auth,
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Notify Отправляет письмо получателю уведомления. Уведомления без получателя пропускаются.
+func (n *SMTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	if msg.Recipient == "" {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body, err := n.buildMessage(msg)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования письма: %w", err)
+	}
+
+	if err = n.sendMail(n.addr, n.auth, n.from, []string{msg.Recipient}, body); err != nil {
+		return fmt.Errorf("ошибка отправки письма на %s: %w", msg.Recipient, err)
+	}
+
+	return nil
+}
+
+// Формирует MIME-письмо: текст (или HTML, если он задан) и вложения.
+func (n *SMTPNotifier) buildMessage(msg *Message) ([]byte, error) {
+	if n.from == "" {
+		return nil, errors.New("не задан адрес отправителя")
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	contentType, content := "text/plain; charset=utf-8", msg.Text
+	if msg.HTML != "" {
+		contentType, content = "text/html; charset=utf-8", msg.HTML
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writeBase64(part, []byte(content)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		part, err = mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Пишет данные в base64 с переносом строк по 76 символов (RFC 2045).
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
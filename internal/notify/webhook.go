@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier Отправка уведомлений JSON POST-запросом на заданный URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier Конструктор WebhookNotifier.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify Отправляет уведомление на вебхук. Ответ с кодом вне диапазона 2xx считается ошибкой.
+func (n *WebhookNotifier) Notify(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации уведомления: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса к вебхуку: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки уведомления на вебхук: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("вебхук вернул статус %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// порядок вывода действий управления службами в отчете
+var controlActionsOrder = []string{models.ControlActionStart, models.ControlActionStop, models.ControlActionRestart}
+
+// Builder Формирует сводные отчеты пользователей на основе истории статусов и действий.
+type Builder struct {
+	storage storage.Storage
+}
+
+// NewBuilder Конструктор Builder.
+func NewBuilder(storage storage.Storage) *Builder {
+	return &Builder{
+		storage: storage,
+	}
+}
+
+// Build Формирует отчет пользователя за период, заканчивающийся в момент to.
+func (b *Builder) Build(ctx context.Context, user *models.User, period models.ReportPeriod, to time.Time) (*models.Report, error) {
+	from := to.Add(-period.Duration())
+
+	servers, err := b.storage.ListServers(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения серверов для отчета: %w", err)
+	}
+
+	serverEvents, err := b.storage.ListServerStatusHistory(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории статусов серверов для отчета: %w", err)
+	}
+
+	serviceEvents, err := b.storage.ListServiceStatusHistory(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории статусов служб для отчета: %w", err)
+	}
+
+	actions, err := b.storage.ListControlActions(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения действий управления службами для отчета: %w", err)
+	}
+
+	// отчет по API-токену, ограниченному отдельными серверами, строится только по ним
+	if token := models.GetContextCreds(ctx).APIToken; token != nil {
+		servers = slices.DeleteFunc(servers, func(s *models.Server) bool { return !token.AllowsServer(s.ID) })
+		serverEvents = slices.DeleteFunc(serverEvents, func(e *models.ServerStatusEvent) bool { return !token.AllowsServer(e.ServerID) })
+		serviceEvents = slices.DeleteFunc(serviceEvents, func(e *models.ServiceStatusEvent) bool { return !token.AllowsServer(e.ServerID) })
+		actions = slices.DeleteFunc(actions, func(a *models.ControlAction) bool { return !token.AllowsServer(a.ServerID) })
+	}
+
+	report := &models.Report{
+		UserID:           user.ID,
+		Login:            user.Login,
+		Period:           period,
+		From:             from,
+		To:               to,
+		ServersMonitored: len(servers),
+		Servers:          serversUptime(servers, serverEvents, from, to),
+		ServiceOutages:   servicesOutages(servers, serviceEvents, from, to),
+		ControlActions:   controlActionsStats(actions),
+	}
+
+	return report, nil
+}
+
+// Вычисляет процент времени, в течение которого сервер был в статусе OK.
+//
+// Учитывается только время, по которому есть данные истории: если первое событие сервера
+// произошло позже начала периода, время до него в расчет не входит.
+func serversUptime(servers []*models.Server, events []*models.ServerStatusEvent, from, to time.Time) []models.ServerUptime {
+	byServer := make(map[int64][]*models.ServerStatusEvent)
+	for _, e := range events {
+		byServer[e.ServerID] = append(byServer[e.ServerID], e)
+	}
+
+	result := make([]models.ServerUptime, 0, len(servers))
+
+	for _, server := range servers {
+		uptime := models.ServerUptime{
+			ServerID: server.ID,
+			Name:     server.Name,
+			Address:  server.Address,
+		}
+
+		var total, up time.Duration
+
+		serverEvents := byServer[server.ID]
+		for i, e := range serverEvents {
+			start := maxTime(e.ChangedAt, from)
+			end := to
+			if i+1 < len(serverEvents) {
+				end = serverEvents[i+1].ChangedAt
+			}
+
+			if !end.After(start) {
+				continue
+			}
+
+			total += end.Sub(start)
+			if e.Status == models.StatusOK {
+				up += end.Sub(start)
+			}
+		}
+
+		if total > 0 {
+			uptime.HasData = true
+			uptime.UptimePercent = roundPercent(float64(up) / float64(total) * 100)
+		}
+
+		result = append(result, uptime)
+	}
+
+	return result
+}
+
+// Вычисляет простои служб: количество переходов в нерабочее состояние и суммарное время простоя.
+// В результат попадают только службы, которые не работали хотя бы часть периода.
+func servicesOutages(servers []*models.Server, events []*models.ServiceStatusEvent, from, to time.Time) []models.ServiceOutage {
+	serverNames := make(map[int64]string, len(servers))
+	for _, server := range servers {
+		serverNames[server.ID] = server.Name
+	}
+
+	byService := make(map[int64][]*models.ServiceStatusEvent)
+	var order []int64
+	for _, e := range events {
+		if _, ok := byService[e.ServiceID]; !ok {
+			order = append(order, e.ServiceID)
+		}
+		byService[e.ServiceID] = append(byService[e.ServiceID], e)
+	}
+
+	running := utils.GetStatusByINT(utils.ServiceRunning)
+
+	var result []models.ServiceOutage
+
+	for _, serviceID := range order {
+		serviceEvents := byService[serviceID]
+		first := serviceEvents[0]
+
+		outage := models.ServiceOutage{
+			ServerID:      first.ServerID,
+			ServerName:    serverNames[first.ServerID],
+			ServiceID:     serviceID,
+			ServiceName:   first.ServiceName,
+			DisplayedName: first.DisplayedName,
+		}
+
+		wasDown := false
+		for i, e := range serviceEvents {
+			start := maxTime(e.ChangedAt, from)
+			end := to
+			if i+1 < len(serviceEvents) {
+				end = serviceEvents[i+1].ChangedAt
+			}
+
+			isDown := e.Status != running
+			if isDown && !wasDown {
+				outage.Outages++
+			}
+			wasDown = isDown
+
+			if isDown && end.After(start) {
+				outage.DownSeconds += int64(end.Sub(start).Seconds())
+			}
+		}
+
+		if outage.DownSeconds > 0 {
+			result = append(result, outage)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DownSeconds > result[j].DownSeconds
+	})
+
+	return result
+}
+
+// Группирует действия управления службами по типу действия и результату.
+func controlActionsStats(actions []*models.ControlAction) []models.ControlActionsStats {
+	stats := make(map[string]*models.ControlActionsStats)
+
+	for _, a := range actions {
+		s, ok := stats[a.Action]
+		if !ok {
+			s = &models.ControlActionsStats{Action: a.Action}
+			stats[a.Action] = s
+		}
+
+		if a.Success {
+			s.Success++
+		} else {
+			s.Failed++
+		}
+	}
+
+	var result []models.ControlActionsStats
+	for _, action := range controlActionsOrder {
+		if s, ok := stats[action]; ok {
+			result = append(result, *s)
+		}
+	}
+
+	return result
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func roundPercent(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
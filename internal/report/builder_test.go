@@ -0,0 +1,229 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+var (
+	testTo   = time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	testFrom = testTo.Add(-24 * time.Hour)
+	testUser = &models.User{ID: "any-id-user-1", Login: "testuser"}
+)
+
+// TestServersUptime Проверяет расчет доступности серверов.
+func TestServersUptime(t *testing.T) {
+	servers := []*models.Server{
+		{ID: 1, Name: "srv1", Address: "10.0.0.1"},
+		{ID: 2, Name: "srv2", Address: "10.0.0.2"},
+		{ID: 3, Name: "srv3", Address: "10.0.0.3"},
+	}
+
+	events := []*models.ServerStatusEvent{
+		// srv1: OK до начала периода, недоступен 6 часов
+		{ServerID: 1, Status: models.StatusOK, ChangedAt: testFrom.Add(-time.Hour)},
+		{ServerID: 1, Status: models.StatusUnreachable, ChangedAt: testFrom.Add(6 * time.Hour)},
+		{ServerID: 1, Status: models.StatusOK, ChangedAt: testFrom.Add(12 * time.Hour)},
+		// srv2: появился в середине периода и все время OK
+		{ServerID: 2, Status: models.StatusOK, ChangedAt: testFrom.Add(12 * time.Hour)},
+	}
+
+	result := serversUptime(servers, events, testFrom, testTo)
+	require.Len(t, result, 3)
+
+	assert.True(t, result[0].HasData)
+	assert.Equal(t, 75.0, result[0].UptimePercent)
+
+	// время до первого события не учитывается
+	assert.True(t, result[1].HasData)
+	assert.Equal(t, 100.0, result[1].UptimePercent)
+
+	// по srv3 нет истории
+	assert.False(t, result[2].HasData)
+	assert.Equal(t, 0.0, result[2].UptimePercent)
+}
+
+// TestServicesOutages Проверяет расчет простоев служб.
+func TestServicesOutages(t *testing.T) {
+	servers := []*models.Server{{ID: 1, Name: "srv1"}}
+
+	events := []*models.ServiceStatusEvent{
+		// служба 10: остановлена до начала периода, запущена через час, затем еще 2 часа простоя
+		{ServiceID: 10, ServerID: 1, ServiceName: "spooler", Status: "Остановлена", ChangedAt: testFrom.Add(-time.Hour)},
+		{ServiceID: 10, ServerID: 1, ServiceName: "spooler", Status: "Работает", ChangedAt: testFrom.Add(time.Hour)},
+		{ServiceID: 10, ServerID: 1, ServiceName: "spooler", Status: "Остановлена", ChangedAt: testFrom.Add(10 * time.Hour)},
+		{ServiceID: 10, ServerID: 1, ServiceName: "spooler", Status: "Работает", ChangedAt: testFrom.Add(12 * time.Hour)},
+		// служба 11: все время работала
+		{ServiceID: 11, ServerID: 1, ServiceName: "w32time", Status: "Работает", ChangedAt: testFrom.Add(-time.Hour)},
+		// служба 12: остановлена за 4 часа до конца периода
+		{ServiceID: 12, ServerID: 1, ServiceName: "bits", Status: "Работает", ChangedAt: testFrom},
+		{ServiceID: 12, ServerID: 1, ServiceName: "bits", Status: "Остановлена", ChangedAt: testTo.Add(-4 * time.Hour)},
+	}
+
+	result := servicesOutages(servers, events, testFrom, testTo)
+	require.Len(t, result, 2)
+
+	// сортировка по убыванию времени простоя
+	assert.Equal(t, "bits", result[0].ServiceName)
+	assert.Equal(t, 1, result[0].Outages)
+	assert.Equal(t, int64(4*3600), result[0].DownSeconds)
+
+	assert.Equal(t, "spooler", result[1].ServiceName)
+	assert.Equal(t, "srv1", result[1].ServerName)
+	assert.Equal(t, 2, result[1].Outages)
+	assert.Equal(t, int64(3*3600), result[1].DownSeconds)
+}
+
+// TestControlActionsStats Проверяет группировку действий управления службами.
+func TestControlActionsStats(t *testing.T) {
+	actions := []*models.ControlAction{
+		{Action: models.ControlActionRestart, Success: true},
+		{Action: models.ControlActionStart, Success: true},
+		{Action: models.ControlActionStart, Success: false},
+		{Action: models.ControlActionStart, Success: true},
+	}
+
+	result := controlActionsStats(actions)
+
+	assert.Equal(t, []models.ControlActionsStats{
+		{Action: models.ControlActionStart, Success: 2, Failed: 1},
+		{Action: models.ControlActionRestart, Success: 1, Failed: 0},
+	}, result)
+
+	assert.Nil(t, controlActionsStats(nil))
+}
+
+// TestBuilderBuild Проверяет формирование отчета и обработку ошибок хранилища.
+func TestBuilderBuild(t *testing.T) {
+	dbErr := errors.New("db error")
+
+	tests := []struct {
+		name        string
+		setupMock   func(m *mocks.MockStorage)
+		expectError bool
+	}{
+		{
+			name: "успешное формирование отчета",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "any-id-user-1").
+					Return([]*models.Server{{ID: 1, Name: "srv1"}}, nil)
+				m.EXPECT().ListServerStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).
+					Return([]*models.ServerStatusEvent{{ServerID: 1, Status: models.StatusOK, ChangedAt: testFrom}}, nil)
+				m.EXPECT().ListServiceStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).
+					Return(nil, nil)
+				m.EXPECT().ListControlActions(gomock.Any(), "any-id-user-1", testFrom, testTo).
+					Return([]*models.ControlAction{{Action: models.ControlActionStop, Success: true}}, nil)
+			},
+		},
+		{
+			name: "ошибка получения серверов",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "any-id-user-1").Return(nil, dbErr)
+			},
+			expectError: true,
+		},
+		{
+			name: "ошибка получения истории серверов",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "any-id-user-1").Return(nil, nil)
+				m.EXPECT().ListServerStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, dbErr)
+			},
+			expectError: true,
+		},
+		{
+			name: "ошибка получения истории служб",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "any-id-user-1").Return(nil, nil)
+				m.EXPECT().ListServerStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, nil)
+				m.EXPECT().ListServiceStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, dbErr)
+			},
+			expectError: true,
+		},
+		{
+			name: "ошибка получения действий",
+			setupMock: func(m *mocks.MockStorage) {
+				m.EXPECT().ListServers(gomock.Any(), "any-id-user-1").Return(nil, nil)
+				m.EXPECT().ListServerStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, nil)
+				m.EXPECT().ListServiceStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, nil)
+				m.EXPECT().ListControlActions(gomock.Any(), "any-id-user-1", testFrom, testTo).Return(nil, dbErr)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := mocks.NewMockStorage(ctrl)
+			tt.setupMock(mockStorage)
+
+			report, err := NewBuilder(mockStorage).Build(context.Background(), testUser, models.ReportDaily, testTo)
+			if tt.expectError {
+				assert.ErrorIs(t, err, dbErr)
+				assert.Nil(t, report)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "testuser", report.Login)
+			assert.Equal(t, testFrom, report.From)
+			assert.Equal(t, 1, report.ServersMonitored)
+			assert.Equal(t, 100.0, report.Servers[0].UptimePercent)
+			assert.Equal(t, []models.ControlActionsStats{{Action: models.ControlActionStop, Success: 1}}, report.ControlActions)
+		})
+	}
+}
+
+// TestBuilderBuildAPIToken Проверяет, что отчет по API-токену, ограниченному серверами, строится только по ним.
+func TestBuilderBuildAPIToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().ListServers(gomock.Any(), "any-id-user-1").
+		Return([]*models.Server{{ID: 1, Name: "srv1"}, {ID: 2, Name: "srv2"}}, nil)
+	mockStorage.EXPECT().ListServerStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).
+		Return([]*models.ServerStatusEvent{
+			{ServerID: 1, Status: models.StatusOK, ChangedAt: testFrom},
+			{ServerID: 2, Status: models.StatusOK, ChangedAt: testFrom},
+		}, nil)
+	mockStorage.EXPECT().ListServiceStatusHistory(gomock.Any(), "any-id-user-1", testFrom, testTo).
+		Return([]*models.ServiceStatusEvent{
+			{ServiceID: 10, ServerID: 1, ServiceName: "svc1", Status: "Stopped", ChangedAt: testFrom},
+			{ServiceID: 20, ServerID: 2, ServiceName: "svc2", Status: "Stopped", ChangedAt: testFrom},
+		}, nil)
+	mockStorage.EXPECT().ListControlActions(gomock.Any(), "any-id-user-1", testFrom, testTo).
+		Return([]*models.ControlAction{
+			{ServerID: 1, Action: models.ControlActionStop, Success: true},
+			{ServerID: 2, Action: models.ControlActionStart, Success: true},
+		}, nil)
+
+	token := &models.APIToken{ID: 1, ServerIDs: []int64{2}, Restricted: true}
+	ctx := context.WithValue(context.Background(), contextkeys.APIToken, token)
+
+	report, err := NewBuilder(mockStorage).Build(ctx, testUser, models.ReportDaily, testTo)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.ServersMonitored)
+	require.Len(t, report.Servers, 1)
+	assert.Equal(t, int64(2), report.Servers[0].ServerID)
+	require.Len(t, report.ServiceOutages, 1)
+	assert.Equal(t, int64(20), report.ServiceOutages[0].ServiceID)
+	assert.Equal(t, []models.ControlActionsStats{{Action: models.ControlActionStart, Success: 1}}, report.ControlActions)
+}
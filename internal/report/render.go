@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+const timeLayout = "2006-01-02 15:04 MST"
+
+var actionNames = map[string]string{
+	models.ControlActionStart:   "Запуск",
+	models.ControlActionStop:    "Остановка",
+	models.ControlActionRestart: "Перезапуск",
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time":     func(t time.Time) string { return t.Format(timeLayout) },
+	"duration": formatDuration,
+	"action":   actionName,
+	"uptime":   formatUptime,
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Отчет SWSM: {{.Login}}</title>
+</head>
+<body>
+<h2>Отчет о состоянии серверов и служб</h2>
+<p>Пользователь: {{.Login}}<br>Период: {{time .From}} — {{time .To}}<br>Серверов под мониторингом: {{.ServersMonitored}}</p>
+
+<h3>Доступность серверов</h3>
+{{if .Servers}}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Сервер</th><th>Адрес</th><th>Доступность</th></tr>
+{{range .Servers}}<tr><td>{{.Name}}</td><td>{{.Address}}</td><td>{{uptime .}}</td></tr>
+{{end}}</table>{{else}}<p>Нет серверов</p>{{end}}
+
+<h3>Простои служб</h3>
+{{if .ServiceOutages}}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Сервер</th><th>Служба</th><th>Имя службы</th><th>Простоев</th><th>Время простоя</th></tr>
+{{range .ServiceOutages}}<tr><td>{{.ServerName}}</td><td>{{.DisplayedName}}</td><td>{{.ServiceName}}</td><td>{{.Outages}}</td><td>{{duration .DownSeconds}}</td></tr>
+{{end}}</table>{{else}}<p>Простоев служб не было</p>{{end}}
+
+<h3>Действия управления службами</h3>
+{{if .ControlActions}}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Действие</th><th>Успешно</th><th>С ошибкой</th></tr>
+{{range .ControlActions}}<tr><td>{{action .Action}}</td><td>{{.Success}}</td><td>{{.Failed}}</td></tr>
+{{end}}</table>{{else}}<p>Действий не было</p>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML Выводит отчет в виде HTML-страницы.
+func RenderHTML(w io.Writer, report *models.Report) error {
+	return htmlTemplate.Execute(w, report)
+}
+
+// RenderCSV Выводит отчет в CSV. Разделы отчета отделены пустой строкой,
+// первая строка каждого раздела — заголовок колонок.
+func RenderCSV(w io.Writer, report *models.Report) error {
+	cw := csv.NewWriter(w)
+
+	records := [][]string{
+		{"Пользователь", "Период с", "Период по", "Серверов под мониторингом"},
+		{report.Login, report.From.Format(timeLayout), report.To.Format(timeLayout), strconv.Itoa(report.ServersMonitored)},
+		{},
+		{"Сервер", "Адрес", "Доступность, %"},
+	}
+
+	for _, s := range report.Servers {
+		records = append(records, []string{s.Name, s.Address, formatUptime(s)})
+	}
+
+	records = append(records, []string{}, []string{"Сервер", "Служба", "Имя службы", "Простоев", "Время простоя, сек"})
+	for _, o := range report.ServiceOutages {
+		records = append(records, []string{o.ServerName, o.DisplayedName, o.ServiceName,
+			strconv.Itoa(o.Outages), strconv.FormatInt(o.DownSeconds, 10)})
+	}
+
+	records = append(records, []string{}, []string{"Действие", "Успешно", "С ошибкой"})
+	for _, a := range report.ControlActions {
+		records = append(records, []string{actionName(a.Action), strconv.Itoa(a.Success), strconv.Itoa(a.Failed)})
+	}
+
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("ошибка формирования CSV отчета: %w", err)
+	}
+
+	return nil
+}
+
+func formatUptime(s models.ServerUptime) string {
+	if !s.HasData {
+		return "нет данных"
+	}
+
+	return strconv.FormatFloat(s.UptimePercent, 'f', 2, 64)
+}
+
+func formatDuration(seconds int64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func actionName(action string) string {
+	if name, ok := actionNames[action]; ok {
+		return name
+	}
+
+	return action
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// createTestReport Создает тестовый отчет.
+func createTestReport() *models.Report {
+	return &models.Report{
+		UserID:           "any-id-user-1",
+		Login:            "testuser",
+		Period:           models.ReportDaily,
+		From:             testFrom,
+		To:               testTo,
+		ServersMonitored: 2,
+		Servers: []models.ServerUptime{
+			{ServerID: 1, Name: "srv1", Address: "10.0.0.1", UptimePercent: 99.5, HasData: true},
+			{ServerID: 2, Name: "<srv2>", Address: "10.0.0.2"},
+		},
+		ServiceOutages: []models.ServiceOutage{
+			{ServerName: "srv1", ServiceName: "spooler", DisplayedName: "Диспетчер печати", Outages: 2, DownSeconds: 3600},
+		},
+		ControlActions: []models.ControlActionsStats{
+			{Action: models.ControlActionRestart, Success: 3, Failed: 1},
+		},
+	}
+}
+
+// TestRenderCSV Проверяет вывод отчета в CSV.
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, RenderCSV(&buf, createTestReport()))
+
+	r := csv.NewReader(&buf)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"testuser", "2025-01-01 00:00 UTC", "2025-01-02 00:00 UTC", "2"}, records[1])
+	assert.Contains(t, records, []string{"srv1", "10.0.0.1", "99.50"})
+	assert.Contains(t, records, []string{"<srv2>", "10.0.0.2", "нет данных"})
+	assert.Contains(t, records, []string{"srv1", "Диспетчер печати", "spooler", "2", "3600"})
+	assert.Contains(t, records, []string{"Перезапуск", "3", "1"})
+}
+
+// TestRenderHTML Проверяет вывод отчета в HTML.
+func TestRenderHTML(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, RenderHTML(&buf, createTestReport()))
+
+	html := buf.String()
+	assert.Contains(t, html, "Пользователь: testuser")
+	assert.Contains(t, html, "<td>99.50</td>")
+	assert.Contains(t, html, "<td>1h0m0s</td>")
+	assert.Contains(t, html, "<td>Перезапуск</td>")
+
+	// данные экранируются
+	assert.Contains(t, html, "&lt;srv2&gt;")
+	assert.NotContains(t, html, "<srv2>")
+}
+
+// TestRenderHTMLEmpty Проверяет вывод пустого отчета в HTML.
+func TestRenderHTMLEmpty(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, RenderHTML(&buf, &models.Report{Login: "testuser"}))
+
+	html := buf.String()
+	assert.Contains(t, html, "Нет серверов")
+	assert.Contains(t, html, "Простоев служб не было")
+	assert.Contains(t, html, "Действий не было")
+}
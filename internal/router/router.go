@@ -1,45 +1,174 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/di_containers"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/middleware"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
 // Router Роутер.
 func Router(h *di_containers.HandlersContainer) chi.Router {
 	router := chi.NewRouter()
 
+	// audit Middleware записи действия в журнал аудита (с передачей в SIEM)
+	audit := func(action string) func(http.Handler) http.Handler {
+		return middleware.AuditMiddleware(h.Storage, h.EventSink, action)
+	}
+
+	// requireRole Middleware проверки роли пользователя
+	requireRole := middleware.RequireRoleMiddleware
+
+	// userOnly Middleware запрета действия по API-токену (доступно только самому пользователю)
+	userOnly := middleware.RejectAPITokenMiddleware
+
+	// адрес клиента из заголовков доверенного обратного прокси (для журнала аудита, SIEM и вебхуков)
+	router.Use(middleware.TrustedProxyMiddleware(h.TrustedProxies))
+
 	router.Use(middleware.CorsMiddleware)
 
 	// middleware логгера всех запросов
 	router.Use(middleware.LogMiddleware)
 
+	// middleware сбора метрик времени обработки запросов
+	router.Use(metrics.HTTPMiddleware)
+
 	// публичные маршруты
 	router.Get("/health", h.HealthHandler.GetHealth)
 
-	// обработка событий из Keycloak
-	router.Post("/keycloak-events", h.WebhooksHandler.HandleEvent)
+	// метрики Prometheus (защищены собственным токеном)
+	if h.MetricsHandler != nil {
+		router.Handle("/metrics", h.MetricsHandler)
+	}
+
+	// обработка событий из Keycloak (подлинность событий проверяется в обработчике)
+	if h.WebhooksHandler != nil {
+		router.Post("/keycloak-events", h.WebhooksHandler.HandleEvent)
+	}
+
+	// встроенная аутентификация: вход, обновление пары токенов и выход
+	if h.LocalAuthHandler != nil {
+		router.Route("/api/auth", func(r chi.Router) {
+			r.Post("/login", h.LocalAuthHandler.Login)
+			r.Post("/refresh", h.LocalAuthHandler.Refresh)
+			r.Post("/logout", h.LocalAuthHandler.Logout)
+		})
+	}
+
+	// SSE: подписка на события служб, серверов и запросов на подтверждение.
+	// EventSource не передает заголовок Authorization, поэтому пользователь определяется
+	// по одноразовому билету (или по сессионной куке) в самом обработчике.
+	// h.Broadcaster.HTTPHandler() — это http.Handler для всех топиков
+	router.Handle("/api/user/broadcasting", h.AppHandler.Broadcaster.HTTPHandler())
 
 	// маршруты, требующие авторизацию
 	router.Route("/api/user", func(r chi.Router) {
 
 		// middleware для всех приватных маршрутов
-		r.Use(middleware.LoginIDToContextMiddleware(h.AppHandler.AuthProvider))
-		r.Use(middleware.UserExistsMiddleware(h.Storage))
+		r.Use(middleware.CSRFMiddleware)
+		r.Use(middleware.LoginIDToContextMiddleware(h.AppHandler.AuthProvider, h.Storage, h.EventSink, h.RolePolicy))
+		r.Use(middleware.UserExistsMiddleware(h.Storage, h.JITProvisioning))
 		r.Use(middleware.RequireAuthMiddleware)
 
+		// все маршруты доступны на чтение любой роли (viewer и выше),
+		// изменения требуют роли operator (управление службами) или admin (управление серверами и службами)
+		r.Use(requireRole(models.RoleViewer))
+
 		// Эндпоинт для установки сессионной куки для работы SSE (Server Sent Events) на фронтенде
-		r.Post("/session", h.SessionHandler.SetSessionCookie)
+		// и ее удаления при выходе
+		r.With(userOnly).Post("/session", h.SessionHandler.SetSessionCookie)
+		r.With(userOnly).Delete("/session", h.SessionHandler.ClearSessionCookie)
 
-		// SSE: подписка на события служб
-		// h.Broadcaster.HTTPHandler() — это http.Handler для всех топиков
-		r.Handle("/broadcasting", h.AppHandler.Broadcaster.HTTPHandler())
+		// выдача одноразового билета для подключения к SSE
+		r.Post("/broadcasting/ticket", h.SessionHandler.IssueSSETicket)
 
 		// маршруты БЕЗ ServerID параметра
-		r.Post("/servers", h.ServerHandler.AddServer)               // создание сервера
 		r.Get("/servers", h.ServerHandler.GetServerList)            // список серверов пользователя
 		r.Get("/servers/statuses", h.HealthHandler.ServersStatuses) // статусы серверов пользователя
+		r.Get("/reports", h.ReportHandler.GetReport)                // сводный отчет пользователя (json/csv/html)
+		r.Get("/audit", h.AuditHandler.GetAudit)                    // журнал аудита пользователя
+		r.Get("/me", h.UserHandler.GetMe)                           // сведения о текущем пользователе и его роли
+		r.Get("/teams", h.TeamHandler.GetTeams)                     // команды пользователя и его роли в них
+		r.Get("/invitations", h.TeamHandler.GetUserInvitations)     // приглашения пользователя в команды
+
+		// создание команды (создатель становится ее администратором)
+		r.With(audit(models.AuditActionAddTeam), userOnly, requireRole(models.RoleOperator)).
+			Post("/teams", h.TeamHandler.CreateTeam)
+
+		// принятие и отклонение приглашения в команду
+		r.With(audit(models.AuditActionAcceptInvitation), userOnly).
+			Post("/invitations/{invitationID}/accept", h.TeamHandler.AcceptInvitation)
+		r.With(userOnly).Delete("/invitations/{invitationID}", h.TeamHandler.DeclineInvitation)
+
+		// персональные API-токены пользователя для автоматизации и CI
+		r.Get("/tokens", h.TokenHandler.GetTokens)
+		r.With(audit(models.AuditActionAddAPIToken)).Post("/tokens", h.TokenHandler.CreateToken)
+		r.With(audit(models.AuditActionDelAPIToken)).Delete("/tokens/{tokenID}", h.TokenHandler.DelToken)
+
+		// смена собственного пароля (только при встроенной аутентификации)
+		if h.LocalAuthHandler != nil {
+			r.With(audit(models.AuditActionChangePassword)).Post("/password", h.LocalAuthHandler.ChangePassword)
+		}
+
+		// запросы на подтверждение остановки и перезапуска критичных служб;
+		// права в команде сервера и на действие со службой проверяет хендлер
+		r.Route("/approvals", func(r chi.Router) {
+			r.Get("/", h.ApprovalHandler.GetApprovals)
+			r.Get("/{approvalID}", h.ApprovalHandler.GetApproval)
+			r.With(audit(models.AuditActionApproveControl), requireRole(models.RoleOperator)).
+				Post("/{approvalID}/approve", h.ApprovalHandler.Approve)
+			r.With(audit(models.AuditActionRejectControl)).
+				Post("/{approvalID}/reject", h.ApprovalHandler.Reject)
+		})
+
+		// маршруты С teamID параметром: роль пользователя сужается до его роли в команде
+		r.Route("/teams/{teamID}", func(r chi.Router) {
+			r.Use(middleware.ParseTeamIDMiddleware)
+			r.Use(middleware.TeamRoleMiddleware(h.Storage))
+
+			r.With(audit(models.AuditActionDelTeam), requireRole(models.RoleAdmin)).
+				Delete("/", h.TeamHandler.DelTeam)
+
+			r.Get("/members", h.TeamHandler.GetMembers) // участники команды
+			r.With(audit(models.AuditActionEditMember), requireRole(models.RoleAdmin)).
+				Patch("/members/{userID}", h.TeamHandler.SetMemberRole)
+			// исключить другого участника может администратор, покинуть команду - любой участник
+			r.With(audit(models.AuditActionDelMember), userOnly).
+				Delete("/members/{userID}", h.TeamHandler.DelMember)
+
+			r.With(requireRole(models.RoleAdmin)).Get("/invitations", h.TeamHandler.GetInvitations)
+			r.With(audit(models.AuditActionInviteMember), requireRole(models.RoleAdmin)).
+				Post("/invitations", h.TeamHandler.CreateInvitation)
+			r.With(audit(models.AuditActionDelInvitation), requireRole(models.RoleAdmin)).
+				Delete("/invitations/{invitationID}", h.TeamHandler.DelInvitation)
+
+			// профили учетных данных, общие для серверов команды
+			r.Route("/credentials", func(r chi.Router) {
+				r.Get("/", h.CredentialHandler.GetProfiles)
+				r.Get("/{profileID}", h.CredentialHandler.GetProfile)
+				r.With(audit(models.AuditActionAddCredentialProfile), requireRole(models.RoleAdmin)).
+					Post("/", h.CredentialHandler.CreateProfile)
+				r.With(audit(models.AuditActionEditCredentialProfile), requireRole(models.RoleAdmin)).
+					Patch("/{profileID}", h.CredentialHandler.EditProfile)
+				r.With(audit(models.AuditActionDelCredentialProfile), requireRole(models.RoleAdmin)).
+					Delete("/{profileID}", h.CredentialHandler.DelProfile)
+				// проверка учетных данных на всех связанных серверах
+				r.With(audit(models.AuditActionTestCredentialProfile), requireRole(models.RoleAdmin)).
+					Post("/{profileID}/test", h.CredentialHandler.TestProfile)
+			})
+		})
+
+		// добавление сервера (с записью в журнал аудита, в т.ч. отказов в доступе);
+		// права в целевой команде проверяет хендлер
+		r.With(audit(models.AuditActionAddServer), requireRole(models.RoleAdmin)).
+			Post("/servers", h.ServerHandler.AddServer)
+
+		// пошаговая проверка подключения к серверу перед его добавлением
+		r.With(audit(models.AuditActionTestServer), requireRole(models.RoleAdmin)).
+			Post("/servers/test", h.ServerHandler.TestConnection)
 
 		// маршруты С serverID параметром
 		r.Route("/servers/{serverID}", func(r chi.Router) {
@@ -47,15 +176,32 @@ func Router(h *di_containers.HandlersContainer) chi.Router {
 			// извлекаем serverID из параметров роутера
 			r.Use(middleware.ParseServerIDMiddleware)
 
-			r.Patch("/", h.ServerHandler.EditServer)       // редактирование сервера
-			r.Delete("/", h.ServerHandler.DelServer)       // удаление сервера
+			// API-токен может быть ограничен отдельными серверами
+			r.Use(middleware.APITokenServerScopeMiddleware)
+
+			// проверяем доступ к серверу через команду и сужаем роль до роли в ней
+			r.Use(middleware.ServerTeamRoleMiddleware(h.Storage))
+
+			// редактирование и удаление сервера (с записью в журнал аудита)
+			r.With(audit(models.AuditActionEditServer), requireRole(models.RoleAdmin)).
+				Patch("/", h.ServerHandler.EditServer)
+			r.With(audit(models.AuditActionDelServer), requireRole(models.RoleAdmin)).
+				Delete("/", h.ServerHandler.DelServer)
+
 			r.Get("/", h.ServerHandler.GetServer)          // получение сервера
 			r.Get("/status", h.HealthHandler.ServerStatus) // получение статуса сервера
 
+			// пошаговая проверка подключения к серверу с сохраненными учетными данными
+			r.With(audit(models.AuditActionTestServer), requireRole(models.RoleOperator)).
+				Post("/test", h.ServerHandler.TestServerConnection)
+
 			r.Route("/services", func(r chi.Router) {
-				r.Post("/", h.ServiceHandler.AddService)     // добавление службы
 				r.Get("/", h.ServiceHandler.GetServicesList) // список служб сервера
 
+				// добавление службы (с записью в журнал аудита)
+				r.With(audit(models.AuditActionAddService), requireRole(models.RoleAdmin)).
+					Post("/", h.ServiceHandler.AddService)
+
 				r.Get("/available", h.ServiceHandler.ListOfServices) // получение всех доступных служб на удаленном сервере
 
 				// маршруты С serviceID параметром
@@ -64,17 +210,52 @@ func Router(h *di_containers.HandlersContainer) chi.Router {
 					// извлекаем serviceID из параметров роутера
 					r.Use(middleware.ParseServiceIDMiddleware)
 
-					r.Delete("/", h.ServiceHandler.DelService) //удаление службы
-					r.Get("/", h.ServiceHandler.GetService)    // получение службы
+					r.Get("/", h.ServiceHandler.GetService)             // получение службы
+					r.Get("/config", h.ServiceHandler.GetServiceConfig) // конфигурация службы на удаленном сервере
+
+					// удаление службы (с записью в журнал аудита)
+					r.With(audit(models.AuditActionDelService), requireRole(models.RoleAdmin)).
+						Delete("/", h.ServiceHandler.DelService)
+
+					// управление службами (с записью в журнал аудита)
+					r.With(audit(models.ControlActionStart), requireRole(models.RoleOperator)).
+						Post("/start", h.ControlHandler.ServiceStart) // запуск службы
+					r.With(audit(models.ControlActionStop), requireRole(models.RoleOperator)).
+						Post("/stop", h.ControlHandler.ServiceStop) // остановка службы
+					r.With(audit(models.ControlActionRestart), requireRole(models.RoleOperator)).
+						Post("/restart", h.ControlHandler.ServiceRestart) // перезапуск службы
 
-					// управление службами
-					r.Post("/start", h.ControlHandler.ServiceStart)     // запуск службы
-					r.Post("/stop", h.ControlHandler.ServiceStop)       // остановка службы
-					r.Post("/restart", h.ControlHandler.ServiceRestart) // перезапуск службы
+					// отметка критичной службы: остановка и перезапуск только после подтверждения
+					r.With(audit(models.AuditActionSetServiceCritical), requireRole(models.RoleAdmin)).
+						Put("/critical", h.ServiceHandler.SetServiceCritical)
+
+					// правила доступа к управлению службой (кто из участников команды может ею управлять)
+					r.With(requireRole(models.RoleAdmin)).Get("/permissions", h.ServiceHandler.GetServicePermissions)
+					r.With(audit(models.AuditActionSetServicePermission), requireRole(models.RoleAdmin)).
+						Put("/permissions", h.ServiceHandler.SetServicePermission)
+					r.With(audit(models.AuditActionDelServicePermission), requireRole(models.RoleAdmin)).
+						Delete("/permissions/{permissionID}", h.ServiceHandler.DelServicePermission)
 				})
 			})
 		})
 	})
 
+	// маршруты администраторов
+	router.Route("/api/admin", func(r chi.Router) {
+		r.Use(middleware.CSRFMiddleware)
+		r.Use(middleware.LoginIDToContextMiddleware(h.AppHandler.AuthProvider, h.Storage, h.EventSink, h.RolePolicy))
+		r.Use(middleware.UserExistsMiddleware(h.Storage, h.JITProvisioning))
+		r.Use(middleware.RequireAuthMiddleware)
+		r.Use(requireRole(models.RoleAdmin))
+
+		r.Get("/users", h.UserHandler.GetUsers)     // список всех пользователей
+		r.Get("/audit", h.AuditHandler.GetAllAudit) // журнал аудита всех пользователей
+
+		// создание пользователя с паролем (только при встроенной аутентификации)
+		if h.LocalAuthHandler != nil {
+			r.With(audit(models.AuditActionAddUser)).Post("/users", h.LocalAuthHandler.CreateUser)
+		}
+	})
+
 	return router
 }
@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+//go:generate mockgen -destination=mocks/provider_mock.go -package=mocks . Provider
+
+// Провайдеры секретов.
+const (
+	ProviderPostgres = "postgres"
+	ProviderVault    = "vault"
+)
+
+// IsValidProvider Проверяет, поддерживается ли провайдер секретов.
+func IsValidProvider(provider string) bool {
+	return provider == ProviderPostgres || provider == ProviderVault
+}
+
+// Provider Интерфейс источника учетных данных серверов для подключения по WinRM.
+type Provider interface {
+	// ResolveServer Подставляет в сервер логин и пароль из хранилища секретов.
+	// Сервер без ссылки на секрет (SecretPath) не изменяется.
+	ResolveServer(ctx context.Context, server *models.Server) error
+}
+
+// DatabaseProvider Провайдер по умолчанию: пароли серверов хранятся в БД, зашифрованными AES-ключом,
+// и расшифровываются хранилищем. Ссылки на внешние секреты не поддерживаются.
+type DatabaseProvider struct{}
+
+// NewDatabaseProvider Конструктор DatabaseProvider.
+func NewDatabaseProvider() *DatabaseProvider {
+	return &DatabaseProvider{}
+}
+
+// ResolveServer Возвращает ошибку для сервера, ссылающегося на секрет, иначе ничего не делает.
+func (p *DatabaseProvider) ResolveServer(ctx context.Context, server *models.Server) error {
+	if server.HasSecret() {
+		return errs.NewErrSecretProviderDisabled(*server.SecretPath)
+	}
+
+	return nil
+}
+
+// Storage Хранилище, подставляющее в серверы учетные данные из провайдера секретов.
+type Storage struct {
+	storage.Storage
+	provider Provider
+}
+
+// NewStorage Конструктор хранилища с провайдером секретов.
+func NewStorage(s storage.Storage, provider Provider) *Storage {
+	return &Storage{Storage: s, provider: provider}
+}
+
+// GetServerWithPassword Получение сервера с паролем: для сервера, ссылающегося на секрет,
+// логин и пароль берутся из провайдера секретов.
+func (s *Storage) GetServerWithPassword(ctx context.Context, serverID int64, userID string) (*models.Server, error) {
+	server, err := s.Storage.GetServerWithPassword(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.provider.ResolveServer(ctx, server); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/secrets/mocks"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// TestDatabaseProvider Проверяет, что провайдер по умолчанию не поддерживает ссылки на секреты.
+func TestDatabaseProvider(t *testing.T) {
+	provider := NewDatabaseProvider()
+	path := "teams/1/servers/web-01"
+
+	server := &models.Server{Username: "admin", Password: "password"}
+	require.NoError(t, provider.ResolveServer(context.Background(), server))
+	assert.Equal(t, "password", server.Password)
+
+	err := provider.ResolveServer(context.Background(), &models.Server{SecretPath: &path})
+
+	var errDisabled *errs.ErrSecretProviderDisabled
+	require.True(t, errors.As(err, &errDisabled))
+	assert.Equal(t, path, errDisabled.Path)
+}
+
+// TestStorageGetServerWithPassword Проверяет подстановку учетных данных провайдером секретов.
+func TestStorageGetServerWithPassword(t *testing.T) {
+	path := "teams/1/servers/web-01"
+
+	tests := []struct {
+		name         string
+		setupStorage func(m *storageMocks.MockStorage)
+		setupSecrets func(m *mocks.MockProvider)
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name: "учетные данные из секрета",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(1), "user-1").
+					Return(&models.Server{ID: 1, Username: "admin", SecretPath: &path}, nil)
+			},
+			setupSecrets: func(m *mocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, server *models.Server) error {
+						server.Password = "vault-password"
+						return nil
+					})
+			},
+			wantPassword: "vault-password",
+		},
+		{
+			name: "ошибка провайдера секретов",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(1), "user-1").
+					Return(&models.Server{ID: 1, SecretPath: &path}, nil)
+			},
+			setupSecrets: func(m *mocks.MockProvider) {
+				m.EXPECT().ResolveServer(gomock.Any(), gomock.Any()).Return(errs.NewErrSecretNotFound(path, nil))
+			},
+			wantErr: true,
+		},
+		{
+			name: "ошибка хранилища",
+			setupStorage: func(m *storageMocks.MockStorage) {
+				m.EXPECT().GetServerWithPassword(gomock.Any(), int64(1), "user-1").
+					Return(nil, errs.NewErrServerNotFound(1, "user-1", nil))
+			},
+			setupSecrets: func(m *mocks.MockProvider) {},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockSecrets := mocks.NewMockProvider(ctrl)
+			tt.setupStorage(mockStorage)
+			tt.setupSecrets(mockSecrets)
+
+			server, err := NewStorage(mockStorage, mockSecrets).GetServerWithPassword(context.Background(), 1, "user-1")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, server)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassword, server.Password)
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// Ключи секрета Vault с учетными данными сервера.
+const (
+	vaultUsernameKey = "username"
+	vaultPasswordKey = "password"
+)
+
+// vaultRetryInterval Пауза перед повторной попыткой продлить токен Vault после ошибки.
+const vaultRetryInterval = 30 * time.Second
+
+// vaultMinRenewInterval Минимальный интервал продления токена Vault.
+const vaultMinRenewInterval = 5 * time.Second
+
+// VaultConfig Конфигурация провайдера секретов HashiCorp Vault (KV v2).
+type VaultConfig struct {
+	Address   string        // http(s)://<host>:<port>
+	Token     string        // токен доступа к Vault
+	Namespace string        // пространство имен Vault Enterprise (необязательно)
+	Mount     string        // путь монтирования KV v2, по умолчанию secret
+	CacheTTL  time.Duration // время кэширования секретов (0 - без кэша)
+	Timeout   time.Duration // таймаут запросов к Vault, по умолчанию 10s
+}
+
+// VaultProvider Провайдер секретов HashiCorp Vault: логин и пароль сервера читаются из секрета KV v2
+// по пути server.SecretPath (ключи username и password). Прочитанные секреты кэшируются,
+// токен доступа периодически продлевается (Run).
+type VaultProvider struct {
+	address   string
+	namespace string
+	mount     string
+	cacheTTL  time.Duration
+	client    *http.Client
+
+	tokenMu sync.RWMutex
+	token   string
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedCredentials
+}
+
+// Credentials Логин и пароль сервера из секрета.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// cachedCredentials Закэшированные учетные данные и время истечения кэша.
+type cachedCredentials struct {
+	credentials Credentials
+	expiresAt   time.Time
+}
+
+// NewVaultProvider Конструктор VaultProvider.
+func NewVaultProvider(config VaultConfig) (*VaultProvider, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("не задан адрес Vault")
+	}
+
+	if config.Token == "" {
+		return nil, fmt.Errorf("не задан токен Vault")
+	}
+
+	mount := strings.Trim(config.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &VaultProvider{
+		address:   strings.TrimRight(config.Address, "/"),
+		namespace: config.Namespace,
+		mount:     mount,
+		cacheTTL:  config.CacheTTL,
+		client:    &http.Client{Timeout: timeout},
+		token:     config.Token,
+		cache:     make(map[string]cachedCredentials),
+	}, nil
+}
+
+// ResolveServer Подставляет в сервер пароль и, если он задан в секрете, логин из Vault.
+// Серверу доступны только секреты из пространства его команды (teams/<teamID>/...): для пути вне его
+// возвращается та же ошибка, что и для отсутствующего секрета, чтобы не раскрывать существование чужих путей.
+func (p *VaultProvider) ResolveServer(ctx context.Context, server *models.Server) error {
+	if !server.HasSecret() {
+		return nil
+	}
+
+	if !models.IsTeamSecretPath(*server.SecretPath, server.TeamID) {
+		return errs.NewErrSecretNotFound(*server.SecretPath, fmt.Errorf("путь вне пространства команды %d", server.TeamID))
+	}
+
+	credentials, err := p.Credentials(ctx, *server.SecretPath)
+	if err != nil {
+		return err
+	}
+
+	if credentials.Username != "" {
+		server.Username = credentials.Username
+	}
+	server.Password = credentials.Password
+
+	return nil
+}
+
+// Credentials Возвращает учетные данные из секрета path (из кэша, если он не истек).
+func (p *VaultProvider) Credentials(ctx context.Context, path string) (Credentials, error) {
+	p.cacheMu.Lock()
+	cached, ok := p.cache[path]
+	p.cacheMu.Unlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.credentials, nil
+	}
+
+	credentials, ttl, err := p.readSecret(ctx, path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	if ttl > 0 {
+		p.cacheMu.Lock()
+		p.cache[path] = cachedCredentials{credentials: credentials, expiresAt: time.Now().Add(ttl)}
+		p.cacheMu.Unlock()
+	}
+
+	return credentials, nil
+}
+
+// Check Проверяет доступность Vault и действительность токена.
+func (p *VaultProvider) Check(ctx context.Context) error {
+	_, _, err := p.lookupToken(ctx)
+	return err
+}
+
+// Run Продлевает токен Vault до отмены контекста. Если токен не продлевается (например, бессрочный root-токен),
+// завершается сразу.
+func (p *VaultProvider) Run(ctx context.Context) {
+	ttl, renewable, err := p.lookupToken(ctx)
+	for err != nil {
+		logger.Log.Error("Не удалось получить информацию о токене Vault", logger.String("err", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(vaultRetryInterval):
+		}
+
+		ttl, renewable, err = p.lookupToken(ctx)
+	}
+
+	if !renewable || ttl <= 0 {
+		logger.Log.Info("Токен Vault не требует продления")
+		return
+	}
+
+	// продлеваем токен, когда истекли две трети срока его действия
+	wait := max(ttl*2/3, vaultMinRenewInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		ttl, err = p.renewToken(ctx)
+		if err != nil {
+			logger.Log.Error("Не удалось продлить токен Vault", logger.String("err", err.Error()))
+			wait = vaultRetryInterval
+			continue
+		}
+
+		logger.Log.Debug("Токен Vault продлен", logger.String("ttl", ttl.String()))
+		wait = max(ttl*2/3, vaultMinRenewInterval)
+	}
+}
+
+// readSecret Читает секрет KV v2. Возвращает учетные данные и время, на которое их можно закэшировать.
+func (p *VaultProvider) readSecret(ctx context.Context, path string) (Credentials, time.Duration, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	var secret struct {
+		LeaseDuration int `json:"lease_duration"`
+		Data          struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+
+	status, err := p.do(ctx, http.MethodGet, fmt.Sprintf("/v1/%s/data/%s", p.mount, strings.Join(segments, "/")), &secret)
+	if err != nil {
+		// отказ в доступе не отличается от отсутствия секрета
+		if status == http.StatusNotFound || status == http.StatusForbidden {
+			return Credentials{}, 0, errs.NewErrSecretNotFound(path, err)
+		}
+		return Credentials{}, 0, fmt.Errorf("ошибка чтения секрета %s из Vault: %w", path, err)
+	}
+
+	// удаленная версия секрета возвращается без данных
+	if secret.Data.Data == nil {
+		return Credentials{}, 0, errs.NewErrSecretNotFound(path, nil)
+	}
+
+	password, ok := secret.Data.Data[vaultPasswordKey].(string)
+	if !ok || password == "" {
+		return Credentials{}, 0, fmt.Errorf("в секрете %s не задан ключ %s", path, vaultPasswordKey)
+	}
+
+	username, _ := secret.Data.Data[vaultUsernameKey].(string)
+
+	ttl := p.cacheTTL
+	if lease := time.Duration(secret.LeaseDuration) * time.Second; lease > 0 && lease < ttl {
+		ttl = lease
+	}
+
+	return Credentials{Username: username, Password: password}, ttl, nil
+}
+
+// lookupToken Возвращает оставшееся время действия токена и признак возможности его продления.
+func (p *VaultProvider) lookupToken(ctx context.Context) (time.Duration, bool, error) {
+	var lookup struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+
+	if _, err := p.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", &lookup); err != nil {
+		return 0, false, fmt.Errorf("ошибка проверки токена Vault: %w", err)
+	}
+
+	return time.Duration(lookup.Data.TTL) * time.Second, lookup.Data.Renewable, nil
+}
+
+// renewToken Продлевает токен и возвращает новое время его действия.
+func (p *VaultProvider) renewToken(ctx context.Context) (time.Duration, error) {
+	var renew struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	if _, err := p.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", &renew); err != nil {
+		return 0, err
+	}
+
+	if renew.Auth.ClientToken != "" {
+		p.tokenMu.Lock()
+		p.token = renew.Auth.ClientToken
+		p.tokenMu.Unlock()
+	}
+
+	return time.Duration(renew.Auth.LeaseDuration) * time.Second, nil
+}
+
+// do Выполняет запрос к API Vault и разбирает JSON-ответ в result. Возвращает HTTP-статус ответа.
+func (p *VaultProvider) do(ctx context.Context, method, path string, result any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.address+path, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса к Vault: %w", err)
+	}
+
+	p.tokenMu.RLock()
+	req.Header.Set("X-Vault-Token", p.token)
+	p.tokenMu.RUnlock()
+
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("vault вернул статус %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return resp.StatusCode, fmt.Errorf("ошибка разбора ответа Vault: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
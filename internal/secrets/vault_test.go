@@ -0,0 +1,240 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// fakeVault Минимальная имитация API Vault (KV v2 и токены) для тестов.
+type fakeVault struct {
+	token   string
+	secrets map[string]map[string]any
+	reads   atomic.Int32
+	renews  atomic.Int32
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != v.token {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/auth/token/lookup-self":
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"ttl": 3, "renewable": true}})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/token/renew-self":
+		v.renews.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": v.token, "lease_duration": 3}})
+	case r.Method == http.MethodGet:
+		v.reads.Add(1)
+		if strings.HasPrefix(r.URL.Path, "/v1/secret/data/teams/1/forbidden/") {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		data, ok := v.secrets[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+	default:
+		http.Error(w, `{"errors":["unsupported path"]}`, http.StatusNotFound)
+	}
+}
+
+// TestVaultProviderResolveServer Проверяет получение учетных данных сервера из Vault.
+func TestVaultProviderResolveServer(t *testing.T) {
+	vault := &fakeVault{
+		token: "test-token",
+		secrets: map[string]map[string]any{
+			"/v1/secret/data/teams/1/web-01": {"username": `CORP\monitor`, "password": "vault-password"},
+			"/v1/secret/data/teams/1/web-02": {"password": "only-password"},
+			"/v1/secret/data/teams/1/empty":  {"username": "admin"},
+		},
+	}
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		secretPath    *string
+		wantUsername  string
+		wantPassword  string
+		isExpectedErr func(err error) bool // проверка ожидаемой ошибки (nil - ошибки нет)
+	}{
+		{name: "логин и пароль из секрета", secretPath: ptr("teams/1/web-01"), wantUsername: `CORP\monitor`, wantPassword: "vault-password"},
+		{name: "логин сервера сохраняется, если не задан в секрете", secretPath: ptr("teams/1/web-02"), wantUsername: "admin", wantPassword: "only-password"},
+		{name: "сервер без секрета не изменяется", wantUsername: "admin", wantPassword: "db-password"},
+		{
+			name:          "секрет не найден",
+			secretPath:    ptr("teams/1/missing"),
+			isExpectedErr: func(err error) bool { var target *errs.ErrSecretNotFound; return errors.As(err, &target) },
+		},
+		{
+			name:          "секрет другой команды не отличается от отсутствующего",
+			secretPath:    ptr("teams/2/web-01"),
+			isExpectedErr: func(err error) bool { var target *errs.ErrSecretNotFound; return errors.As(err, &target) },
+		},
+		{
+			name:          "отказ Vault в доступе не отличается от отсутствующего секрета",
+			secretPath:    ptr("teams/1/forbidden/web-01"),
+			isExpectedErr: func(err error) bool { var target *errs.ErrSecretNotFound; return errors.As(err, &target) },
+		},
+		{
+			name:          "секрет вне пространства команд",
+			secretPath:    ptr("swsm/web-01"),
+			isExpectedErr: func(err error) bool { var target *errs.ErrSecretNotFound; return errors.As(err, &target) },
+		},
+		{
+			name:          "в секрете нет пароля",
+			secretPath:    ptr("teams/1/empty"),
+			isExpectedErr: func(err error) bool { return err != nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewVaultProvider(VaultConfig{Address: srv.URL, Token: "test-token", CacheTTL: time.Minute})
+			require.NoError(t, err)
+
+			server := &models.Server{TeamID: 1, Username: "admin", Password: "db-password", SecretPath: tt.secretPath}
+			err = provider.ResolveServer(context.Background(), server)
+
+			if tt.isExpectedErr != nil {
+				assert.True(t, tt.isExpectedErr(err), "неожиданная ошибка: %v", err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUsername, server.Username)
+			assert.Equal(t, tt.wantPassword, server.Password)
+		})
+	}
+}
+
+// TestVaultProviderCache Проверяет кэширование прочитанных секретов.
+func TestVaultProviderCache(t *testing.T) {
+	vault := &fakeVault{
+		token:   "test-token",
+		secrets: map[string]map[string]any{"/v1/kv/data/swsm/web-01": {"password": "vault-password"}},
+	}
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+
+	t.Run("секрет читается из кэша", func(t *testing.T) {
+		vault.reads.Store(0)
+
+		provider, err := NewVaultProvider(VaultConfig{Address: srv.URL, Token: "test-token", Mount: "/kv/", CacheTTL: time.Minute})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			credentials, err := provider.Credentials(context.Background(), "swsm/web-01")
+			require.NoError(t, err)
+			assert.Equal(t, "vault-password", credentials.Password)
+		}
+
+		assert.Equal(t, int32(1), vault.reads.Load())
+	})
+
+	t.Run("без кэша секрет читается каждый раз", func(t *testing.T) {
+		vault.reads.Store(0)
+
+		provider, err := NewVaultProvider(VaultConfig{Address: srv.URL, Token: "test-token", Mount: "kv"})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err := provider.Credentials(context.Background(), "swsm/web-01")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(3), vault.reads.Load())
+	})
+}
+
+// TestVaultProviderToken Проверяет проверку и продление токена Vault.
+func TestVaultProviderToken(t *testing.T) {
+	vault := &fakeVault{token: "test-token"}
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+
+	t.Run("неверный токен", func(t *testing.T) {
+		provider, err := NewVaultProvider(VaultConfig{Address: srv.URL, Token: "wrong-token"})
+		require.NoError(t, err)
+
+		assert.Error(t, provider.Check(context.Background()))
+	})
+
+	t.Run("продление токена", func(t *testing.T) {
+		provider, err := NewVaultProvider(VaultConfig{Address: srv.URL, Token: "test-token"})
+		require.NoError(t, err)
+		require.NoError(t, provider.Check(context.Background()))
+
+		// ttl токена 3s: первое продление не раньше чем через минимальный интервал
+		ctx, cancel := context.WithTimeout(context.Background(), vaultMinRenewInterval+time.Second)
+		defer cancel()
+
+		provider.Run(ctx)
+
+		assert.Equal(t, int32(1), vault.renews.Load())
+	})
+
+	t.Run("конфигурация без адреса или токена", func(t *testing.T) {
+		_, err := NewVaultProvider(VaultConfig{Token: "test-token"})
+		assert.Error(t, err)
+
+		_, err = NewVaultProvider(VaultConfig{Address: srv.URL})
+		assert.Error(t, err)
+	})
+}
+
+// TestVaultProviderDevServer Проверяет чтение секрета из локального dev-сервера Vault
+// (vault server -dev). Адрес и root-токен задаются переменными SWSM_TEST_VAULT_ADDR и SWSM_TEST_VAULT_TOKEN.
+func TestVaultProviderDevServer(t *testing.T) {
+	address, token := os.Getenv("SWSM_TEST_VAULT_ADDR"), os.Getenv("SWSM_TEST_VAULT_TOKEN")
+	if address == "" || token == "" {
+		t.Skip("SWSM_TEST_VAULT_ADDR и SWSM_TEST_VAULT_TOKEN не заданы, тест с dev-сервером Vault пропущен")
+	}
+
+	path := fmt.Sprintf("teams/1/swsm-test/%d", time.Now().UnixNano())
+
+	// dev-сервер по умолчанию монтирует KV v2 по пути secret
+	body, err := json.Marshal(map[string]any{"data": map[string]string{"username": "admin", "password": "dev-password"}})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, address+"/v1/secret/data/"+path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	provider, err := NewVaultProvider(VaultConfig{Address: address, Token: token})
+	require.NoError(t, err)
+	require.NoError(t, provider.Check(context.Background()))
+
+	server := &models.Server{TeamID: 1, SecretPath: &path}
+	require.NoError(t, provider.ResolveServer(context.Background(), server))
+	assert.Equal(t, "admin", server.Username)
+	assert.Equal(t, "dev-password", server.Password)
+}
+
+func ptr(s string) *string {
+	return &s
+}
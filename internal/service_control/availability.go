@@ -0,0 +1,17 @@
+package service_control
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// IsWinRMAvailable Проверяет доступность WinRM на сервере по его параметрам подключения
+// (порт, схема и таймаут проверки сервера заменяют глобальные из winRMConfig).
+func IsWinRMAvailable(ctx context.Context, checker netutils.Checker, winRMConfig *config.WinRMConfig, address string, settings models.WinRMSettings) bool {
+	cfg := winRMConfig.ForServer(settings)
+
+	return checker.CheckWinRM(ctx, address, cfg.Port, cfg.UseHTTPS, cfg.ConnectTimeout)
+}
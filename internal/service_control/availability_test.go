@@ -0,0 +1,75 @@
+package service_control_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	netutilsMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+)
+
+// TestIsWinRMAvailable Проверяет, что параметры сервера заменяют глобальные при проверке доступности WinRM.
+func TestIsWinRMAvailable(t *testing.T) {
+	globalConfig := &config.WinRMConfig{
+		Port:     "5985",
+		UseHTTPS: false,
+		Timeout:  10 * time.Second,
+	}
+
+	port, https, connectTimeout := 5986, true, 3
+
+	tests := []struct {
+		name            string               // название теста
+		settings        models.WinRMSettings // параметры сервера
+		expectedPort    string               // ожидаемый порт проверки
+		expectedHTTPS   bool                 // ожидаемая схема
+		expectedTimeout time.Duration        // ожидаемый таймаут проверки
+		available       bool                 // результат проверки
+	}{
+		{
+			name:          "глобальные параметры",
+			settings:      models.WinRMSettings{},
+			expectedPort:  "5985",
+			expectedHTTPS: false,
+			available:     true,
+		},
+		{
+			name:            "параметры сервера",
+			settings:        models.WinRMSettings{Port: &port, HTTPS: &https, ConnectTimeout: &connectTimeout},
+			expectedPort:    "5986",
+			expectedHTTPS:   true,
+			expectedTimeout: 3 * time.Second,
+			available:       true,
+		},
+		{
+			name:          "WinRM недоступен",
+			settings:      models.WinRMSettings{},
+			expectedPort:  "5985",
+			expectedHTTPS: false,
+			available:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			checker := netutilsMocks.NewMockChecker(ctrl)
+			checker.EXPECT().
+				CheckWinRM(gomock.Any(), "192.168.1.100", tt.expectedPort, tt.expectedHTTPS, tt.expectedTimeout).
+				Return(tt.available)
+
+			result := service_control.IsWinRMAvailable(context.Background(), checker, globalConfig, "192.168.1.100", tt.settings)
+
+			assert.Equal(t, tt.available, result)
+			// глобальный конфиг не должен изменяться
+			assert.Equal(t, "5985", globalConfig.Port)
+		})
+	}
+}
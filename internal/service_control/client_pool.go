@@ -0,0 +1,278 @@
+package service_control
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// WinRMClientPool Пул WinRM клиентов, общий для хендлеров и воркеров.
+// Клиенты переиспользуются между командами, поэтому повторные запросы к серверу идут по уже установленным
+// соединениям без нового TLS/NTLM рукопожатия. Каждый клиент в один момент времени выполняет только одну команду,
+// а число одновременно выполняемых на сервере команд ограничено maxPerHost, чтобы не превышать
+// MaxConcurrentOperationsPerUser и MaxShellsPerUser на стороне WinRM.
+type WinRMClientPool struct {
+	factory     ClientFactory
+	maxPerHost  int           // 0 - без ограничения
+	idleTimeout time.Duration // 0 - клиенты не переиспользуются
+
+	mu        sync.Mutex
+	hosts     map[string]*hostClients
+	lastSweep time.Time
+}
+
+// hostClients Клиенты одного сервера.
+type hostClients struct {
+	slots      chan struct{}            // занятые слоты выполнения команд, nil - без ограничения
+	idle       map[string][]*idleClient // свободные клиенты по ключу учетных данных и параметров подключения
+	generation uint64                   // увеличивается при Invalidate, клиенты прошлых поколений в пул не возвращаются
+	inFlight   int                      // команды, ожидающие слот или выполняемые; пока они есть, запись сервера не удаляется
+}
+
+// idleClient Свободный клиент и время его последнего использования.
+type idleClient struct {
+	client   Client
+	lastUsed time.Time
+}
+
+// NewWinRMClientPool Конструктор пула, создающего клиентов через factory.
+func NewWinRMClientPool(factory ClientFactory, maxPerHost int, idleTimeout time.Duration) *WinRMClientPool {
+	return &WinRMClientPool{
+		factory:     factory,
+		maxPerHost:  maxPerHost,
+		idleTimeout: idleTimeout,
+		hosts:       make(map[string]*hostClients),
+	}
+}
+
+// CreateClient Возвращает клиента, который на время каждой команды берет из пула свободного клиента
+// с теми же учетными данными и параметрами подключения или создает нового.
+// Клиенты со старым паролем после его смены не используются: пароль входит в ключ клиента.
+func (p *WinRMClientPool) CreateClient(address, username, password string, settings models.WinRMSettings) (Client, error) {
+	pc := &pooledClient{
+		pool: p,
+		host: hostKey(address),
+		key:  clientKey(username, password, settings),
+		create: func() (Client, error) {
+			return p.factory.CreateClient(address, username, password, settings)
+		},
+	}
+
+	// если свободного клиента нет, он создается сразу, чтобы ошибки параметров подключения
+	// возвращались здесь, а не при выполнении команды
+	if !p.hasIdle(pc.host, pc.key) {
+		client, err := pc.create()
+		if err != nil {
+			return nil, err
+		}
+		pc.initial = client
+	}
+
+	return pc, nil
+}
+
+// Invalidate Удаляет свободных клиентов сервера. Клиенты, выполняющие команду, в пул не возвращаются.
+func (p *WinRMClientPool) Invalidate(address string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if h, ok := p.hosts[hostKey(address)]; ok {
+		h.idle = make(map[string][]*idleClient)
+		h.generation++
+	}
+}
+
+// host Возвращает клиентов сервера, создавая запись при первом обращении. Вызывается под p.mu.
+func (p *WinRMClientPool) host(host string) *hostClients {
+	h, ok := p.hosts[host]
+	if !ok {
+		h = &hostClients{idle: make(map[string][]*idleClient)}
+		if p.maxPerHost > 0 {
+			h.slots = make(chan struct{}, p.maxPerHost)
+		}
+		p.hosts[host] = h
+	}
+
+	return h
+}
+
+// acquire Регистрирует команду сервера и возвращает его слоты выполнения команд и текущее поколение клиентов.
+// После выполнения команды вызывается release.
+func (p *WinRMClientPool) acquire(host string) (chan struct{}, uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	h.inFlight++
+
+	return h.slots, h.generation
+}
+
+// release Снимает регистрацию команды сервера, сделанную в acquire.
+func (p *WinRMClientPool) release(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if h, ok := p.hosts[host]; ok {
+		h.inFlight--
+	}
+}
+
+// hasIdle Проверяет, есть ли для ключа свободный клиент, не простаивающий дольше idleTimeout.
+func (p *WinRMClientPool) hasIdle(host, key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sweep()
+
+	return len(p.host(host).idle[key]) > 0
+}
+
+// take Забирает из пула последний использованный свободный клиент, nil - свободных клиентов нет.
+func (p *WinRMClientPool) take(host, key string) Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	clients := h.idle[key]
+	n := len(clients)
+
+	// клиенты добавляются в конец, поэтому если простаивает слишком долго последний, то и все остальные
+	if n == 0 || time.Since(clients[n-1].lastUsed) >= p.idleTimeout {
+		delete(h.idle, key)
+		return nil
+	}
+
+	h.idle[key] = clients[:n-1]
+
+	return clients[n-1].client
+}
+
+// put Возвращает клиента в пул. Клиенты, взятые до Invalidate, и лишние клиенты сверх maxPerHost не сохраняются.
+func (p *WinRMClientPool) put(host, key string, client Client, generation uint64) {
+	if p.idleTimeout <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	if h.generation != generation || (p.maxPerHost > 0 && len(h.idle[key]) >= p.maxPerHost) {
+		return
+	}
+
+	h.idle[key] = append(h.idle[key], &idleClient{client: client, lastUsed: time.Now()})
+}
+
+// sweep Удаляет клиентов, простаивающих дольше idleTimeout, и записи серверов без свободных клиентов
+// и выполняемых команд. Выполняется не чаще раза в idleTimeout, вызывается под p.mu.
+func (p *WinRMClientPool) sweep() {
+	now := time.Now()
+	if now.Sub(p.lastSweep) < p.idleTimeout {
+		return
+	}
+	p.lastSweep = now
+
+	for host, h := range p.hosts {
+		for key, clients := range h.idle {
+			fresh := clients[:0]
+			for _, c := range clients {
+				if now.Sub(c.lastUsed) < p.idleTimeout {
+					fresh = append(fresh, c)
+				}
+			}
+
+			if len(fresh) == 0 {
+				delete(h.idle, key)
+				continue
+			}
+			h.idle[key] = fresh
+		}
+
+		// запись удаленного или давно не используемого сервера не должна оставаться в пуле навсегда
+		if len(h.idle) == 0 && len(h.slots) == 0 && h.inFlight == 0 {
+			delete(p.hosts, host)
+		}
+	}
+}
+
+// pooledClient Клиент, выполняющий каждую команду свободным клиентом из пула.
+type pooledClient struct {
+	pool   *WinRMClientPool
+	host   string
+	key    string
+	create func() (Client, error)
+
+	mu      sync.Mutex
+	initial Client // клиент, созданный в CreateClient, для первой команды
+}
+
+// RunCommand Ожидает свободный слот сервера, выполняет команду и возвращает клиента в пул.
+// После ошибки клиент в пул не возвращается: соединение могло быть разорвано или учетные данные устарели.
+func (c *pooledClient) RunCommand(ctx context.Context, cmd string) (string, error) {
+	slots, generation := c.pool.acquire(c.host)
+	defer c.pool.release(c.host)
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return "", fmt.Errorf("не дождались свободного подключения к серверу: %w", ctx.Err())
+		}
+	}
+
+	client := c.takeInitial()
+	if client == nil {
+		client = c.pool.take(c.host, c.key)
+	}
+
+	if client == nil {
+		var err error
+		if client, err = c.create(); err != nil {
+			return "", err
+		}
+	}
+
+	output, err := client.RunCommand(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	c.pool.put(c.host, c.key, client, generation)
+
+	return output, nil
+}
+
+// takeInitial Забирает клиента, созданного в CreateClient, если он еще не использовался.
+func (c *pooledClient) takeInitial() Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client := c.initial
+	c.initial = nil
+
+	return client
+}
+
+// hostKey Ключ сервера в пуле.
+func hostKey(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+// clientKey Ключ клиента: хэш учетных данных и параметров подключения.
+func clientKey(username, password string, settings models.WinRMSettings) string {
+	encodedSettings, _ := json.Marshal(settings)
+
+	sum := sha256.Sum256([]byte(username + "\x00" + password + "\x00" + string(encodedSettings)))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,11 @@
+package service_control
+
+//go:generate mockgen -destination=mocks/mock_client_pool.go -package=mocks . ClientPool
+
+// ClientPool Интерфейс фабрики, переиспользующей клиентов между запросами.
+type ClientPool interface {
+	ClientFactory
+
+	// Invalidate Удаляет сохраненных для сервера клиентов (например, после смены учетных данных).
+	Invalidate(address string)
+}
@@ -0,0 +1,84 @@
+package service_control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// stubClient Клиент, выполняющий команду до закрытия release.
+type stubClient struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *stubClient) RunCommand(ctx context.Context, cmd string) (string, error) {
+	if c.started != nil {
+		c.started <- struct{}{}
+		<-c.release
+	}
+
+	return "ok", nil
+}
+
+// stubFactory Фабрика, возвращающая клиента по адресу сервера.
+type stubFactory map[string]Client
+
+func (f stubFactory) CreateClient(address, username, password string, settings models.WinRMSettings) (Client, error) {
+	return f[address], nil
+}
+
+// TestWinRMClientPoolSweepRemovesHosts Проверяет, что записи серверов без свободных клиентов
+// и выполняемых команд удаляются из пула, а запись сервера с выполняемой командой остается.
+func TestWinRMClientPoolSweepRemovesHosts(t *testing.T) {
+	const idleTimeout = 20 * time.Millisecond
+
+	busy := &stubClient{started: make(chan struct{}), release: make(chan struct{})}
+	pool := NewWinRMClientPool(stubFactory{"srv01": &stubClient{}, "srv02": busy}, 2, idleTimeout)
+
+	// srv01: клиент вернулся в пул и простаивает
+	client, err := pool.CreateClient("srv01", "admin", "password", models.WinRMSettings{})
+	require.NoError(t, err)
+	_, err = client.RunCommand(context.Background(), "Get-Service")
+	require.NoError(t, err)
+
+	// srv02: команда выполняется дольше idleTimeout
+	client, err = pool.CreateClient("srv02", "admin", "password", models.WinRMSettings{})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = client.RunCommand(context.Background(), "Get-Service")
+	}()
+	<-busy.started
+
+	time.Sleep(2 * idleTimeout)
+
+	pool.mu.Lock()
+	pool.sweep()
+	_, idleHostKept := pool.hosts["srv01"]
+	_, busyHostKept := pool.hosts["srv02"]
+	pool.mu.Unlock()
+
+	assert.False(t, idleHostKept, "запись сервера без клиентов и команд должна удаляться")
+	assert.True(t, busyHostKept, "запись сервера с выполняемой командой не должна удаляться")
+
+	close(busy.release)
+	<-done
+
+	// после завершения команды клиент простаивает в пуле, пока не истечет idleTimeout
+	time.Sleep(2 * idleTimeout)
+
+	pool.mu.Lock()
+	pool.sweep()
+	hosts := len(pool.hosts)
+	pool.mu.Unlock()
+
+	assert.Zero(t, hosts)
+}
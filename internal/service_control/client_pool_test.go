@@ -0,0 +1,230 @@
+package service_control_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+)
+
+// TestWinRMClientPoolImplementsInterface Проверяет что WinRMClientPool реализует интерфейс.
+func TestWinRMClientPoolImplementsInterface(t *testing.T) {
+	var _ service_control.ClientPool = (*service_control.WinRMClientPool)(nil)
+}
+
+// TestWinRMClientPoolReuse Проверяет, когда пул переиспользует клиента, а когда создает нового.
+func TestWinRMClientPoolReuse(t *testing.T) {
+	tests := []struct {
+		name           string
+		idleTimeout    time.Duration
+		run            func(t *testing.T, pool *service_control.WinRMClientPool)
+		expectedCreate int // сколько клиентов создает фабрика
+	}{
+		{
+			name:        "повторные команды и запросы с теми же учетными данными",
+			idleTimeout: time.Minute,
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 2)
+				runCommands(t, pool, "SRV01", "admin", "password", 2)
+			},
+			expectedCreate: 1,
+		},
+		{
+			name:        "смена пароля",
+			idleTimeout: time.Minute,
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+				runCommands(t, pool, "srv01", "admin", "new-password", 1)
+			},
+			expectedCreate: 2,
+		},
+		{
+			name:        "разные серверы",
+			idleTimeout: time.Minute,
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+				runCommands(t, pool, "srv02", "admin", "password", 1)
+			},
+			expectedCreate: 2,
+		},
+		{
+			name:        "сброс клиентов сервера",
+			idleTimeout: time.Minute,
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+				pool.Invalidate("srv01")
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+			},
+			expectedCreate: 2,
+		},
+		{
+			name:        "клиент простаивал дольше idleTimeout",
+			idleTimeout: 20 * time.Millisecond,
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+				time.Sleep(40 * time.Millisecond)
+				runCommands(t, pool, "srv01", "admin", "password", 1)
+			},
+			expectedCreate: 2,
+		},
+		{
+			name: "переиспользование отключено",
+			run: func(t *testing.T, pool *service_control.WinRMClientPool) {
+				runCommands(t, pool, "srv01", "admin", "password", 3)
+			},
+			expectedCreate: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+			mockFactory.EXPECT().
+				CreateClient(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(string, string, string, models.WinRMSettings) (service_control.Client, error) {
+					mockClient := serviceControlMocks.NewMockClient(ctrl)
+					mockClient.EXPECT().RunCommand(gomock.Any(), "Get-Service").Return("ok", nil).AnyTimes()
+					return mockClient, nil
+				}).
+				Times(tt.expectedCreate)
+
+			tt.run(t, service_control.NewWinRMClientPool(mockFactory, 4, tt.idleTimeout))
+		})
+	}
+}
+
+// TestWinRMClientPoolErrors Проверяет ошибки создания клиента и выполнения команды.
+func TestWinRMClientPoolErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	pool := service_control.NewWinRMClientPool(mockFactory, 4, time.Minute)
+
+	// ошибка параметров подключения возвращается при создании клиента
+	mockFactory.EXPECT().
+		CreateClient("srv01", "admin", "", gomock.Any()).
+		Return(nil, errors.New("пароль не может быть пустым"))
+
+	client, err := pool.CreateClient("srv01", "admin", "", models.WinRMSettings{})
+	assert.Error(t, err)
+	assert.Nil(t, client)
+
+	// клиент, команда которого завершилась ошибкой, в пул не возвращается
+	broken := serviceControlMocks.NewMockClient(ctrl)
+	broken.EXPECT().RunCommand(gomock.Any(), "Get-Service").Return("", errors.New("connection reset"))
+
+	healthy := serviceControlMocks.NewMockClient(ctrl)
+	healthy.EXPECT().RunCommand(gomock.Any(), "Get-Service").Return("ok", nil)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().CreateClient("srv01", "admin", "password", gomock.Any()).Return(broken, nil),
+		mockFactory.EXPECT().CreateClient("srv01", "admin", "password", gomock.Any()).Return(healthy, nil),
+	)
+
+	client, err = pool.CreateClient("srv01", "admin", "password", models.WinRMSettings{})
+	require.NoError(t, err)
+
+	_, err = client.RunCommand(context.Background(), "Get-Service")
+	assert.Error(t, err)
+
+	output, err := client.RunCommand(context.Background(), "Get-Service")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", output)
+}
+
+// TestWinRMClientPoolMaxPerHost Проверяет ограничение числа одновременных команд на сервере.
+func TestWinRMClientPoolMaxPerHost(t *testing.T) {
+	const maxPerHost = 2
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+	mockFactory.EXPECT().
+		CreateClient(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(string, string, string, models.WinRMSettings) (service_control.Client, error) {
+			mockClient := serviceControlMocks.NewMockClient(ctrl)
+			mockClient.EXPECT().
+				RunCommand(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(context.Context, string) (string, error) {
+					n := running.Add(1)
+					for {
+						current := maxRunning.Load()
+						if n <= current || maxRunning.CompareAndSwap(current, n) {
+							break
+						}
+					}
+
+					<-release
+					running.Add(-1)
+
+					return "ok", nil
+				}).
+				AnyTimes()
+			return mockClient, nil
+		}).
+		AnyTimes()
+
+	pool := service_control.NewWinRMClientPool(mockFactory, maxPerHost, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		client, err := pool.CreateClient("srv01", "admin", "password", models.WinRMSettings{})
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.RunCommand(context.Background(), "Get-Service")
+			assert.NoError(t, err)
+		}()
+	}
+
+	// пока слоты заняты, команда с истекшим контекстом не дожидается подключения
+	require.Eventually(t, func() bool { return running.Load() == maxPerHost }, time.Second, time.Millisecond)
+
+	client, err := pool.CreateClient("srv01", "admin", "password", models.WinRMSettings{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.RunCommand(ctx, "Get-Service")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(maxPerHost), maxRunning.Load())
+}
+
+// runCommands Выполняет count команд клиентом из пула.
+func runCommands(t *testing.T, pool *service_control.WinRMClientPool, address, username, password string, count int) {
+	t.Helper()
+
+	client, err := pool.CreateClient(address, username, password, models.WinRMSettings{})
+	require.NoError(t, err)
+
+	for i := 0; i < count; i++ {
+		output, err := client.RunCommand(context.Background(), "Get-Service")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", output)
+	}
+}
@@ -0,0 +1,195 @@
+package service_control
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+)
+
+// MaxServiceNameLen Максимальная длина имени службы Windows.
+const MaxServiceNameLen = 256
+
+// powerShellPrefix Запуск PowerShell со скриптом, переданным в -EncodedCommand (base64 от UTF-16LE):
+// командная строка состоит только из этого префикса и символов base64, поэтому ни cmd.exe,
+// ни разбор аргументов PowerShell не видят содержимого скрипта.
+const powerShellPrefix = "powershell.exe -NoProfile -NonInteractive -EncodedCommand "
+
+// serviceNameRegex Допустимое имя службы: латинские буквы, цифры, пробел (не в начале и не в конце)
+// и символы _ . - $ @ # + { } (например, MSSQL$SQLEXPRESS или {GUID}-имена драйверов).
+// Кавычки, разделители команд (& ; |), скобки, перенаправления, символы подстановки PowerShell (* ? [ ])
+// и переменные cmd (%) недопустимы.
+var serviceNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.$@#+{}-](?:[A-Za-z0-9 _.$@#+{}-]*[A-Za-z0-9_.$@#+{}-])?$`)
+
+// serviceScriptPrelude Начало скриптов для одной службы: служба ищется через CIM (Win32_Service),
+// а если она не установлена, выводится {"Exists":false}. Имя подставляется в литерал $name
+// и дальше используется только как значение переменной.
+const serviceScriptPrelude = `$ErrorActionPreference = 'Stop'
+$name = %s
+$svc = Get-CimInstance -ClassName Win32_Service -Filter "Name='$name'"
+if ($null -eq $svc) { [Console]::Out.Write('{"Exists":false}'); exit 0 }
+`
+
+// queryServiceScript Состояние службы: State — числовое значение ServiceControllerStatus (как dwCurrentState в Win32),
+// коды завершения — из Win32_Service.
+const queryServiceScript = `$status = Get-Service -Name $name
+[Console]::Out.Write((ConvertTo-Json -Compress -InputObject ([ordered]@{
+	Exists                  = $true
+	Name                    = $svc.Name
+	DisplayName             = $svc.DisplayName
+	State                   = [int]$status.Status
+	ProcessId               = [long]$svc.ProcessId
+	ExitCode                = [long]$svc.ExitCode
+	ServiceSpecificExitCode = [long]$svc.ServiceSpecificExitCode
+	AcceptStop              = [bool]$svc.AcceptStop
+	AcceptPause             = [bool]$svc.AcceptPause
+})))`
+
+// controlServiceScript Вызов метода Win32_Service (StartService, StopService, PauseService, ResumeService),
+// ReturnValue — код результата метода.
+const controlServiceScript = `$result = Invoke-CimMethod -InputObject $svc -MethodName %s
+[Console]::Out.Write((ConvertTo-Json -Compress -InputObject ([ordered]@{
+	Exists      = $true
+	ReturnValue = [int]$result.ReturnValue
+})))`
+
+// serviceConfigScript Конфигурация службы: StartType — числовое значение ServiceStartMode (как dwStartType в Win32).
+const serviceConfigScript = `$status = Get-Service -Name $name
+[Console]::Out.Write((ConvertTo-Json -Compress -InputObject ([ordered]@{
+	Exists           = $true
+	Name             = $svc.Name
+	DisplayName      = $svc.DisplayName
+	Description      = [string]$svc.Description
+	StartType        = [int]$status.StartType
+	DelayedAutoStart = [bool]$svc.DelayedAutoStart
+	StartName        = [string]$svc.StartName
+	PathName         = [string]$svc.PathName
+	DependsOn        = [string[]]@($status.ServicesDependedOn | ForEach-Object { $_.ServiceName })
+})))`
+
+// serviceStatesScript Состояния нескольких служб одним запросом, всегда массив JSON;
+// службы, которых нет на сервере, в вывод не попадают.
+const serviceStatesScript = `$names = @(%s)
+$states = @(Get-Service -Name $names -ErrorAction SilentlyContinue | ForEach-Object { [ordered]@{ Name = $_.Name; State = [int]$_.Status } })
+[Console]::Out.Write((ConvertTo-Json -Compress -InputObject $states))`
+
+// ServiceCommands Команды PowerShell для одной службы. Каждая выводит один объект JSON
+// с полем Exists (false, если служба не установлена).
+type ServiceCommands struct {
+	Query    string
+	Start    string
+	Stop     string
+	Pause    string
+	Continue string
+	Config   string
+}
+
+// NewServiceCommands Строит команды для службы serviceName, предварительно проверив ее имя.
+func NewServiceCommands(serviceName string) (*ServiceCommands, error) {
+	if err := ValidateServiceName(serviceName); err != nil {
+		return nil, err
+	}
+
+	prelude := fmt.Sprintf(serviceScriptPrelude, quotePowerShell(serviceName))
+
+	return &ServiceCommands{
+		Query:    PowerShellCommand(prelude + queryServiceScript),
+		Start:    PowerShellCommand(prelude + fmt.Sprintf(controlServiceScript, "StartService")),
+		Stop:     PowerShellCommand(prelude + fmt.Sprintf(controlServiceScript, "StopService")),
+		Pause:    PowerShellCommand(prelude + fmt.Sprintf(controlServiceScript, "PauseService")),
+		Continue: PowerShellCommand(prelude + fmt.Sprintf(controlServiceScript, "ResumeService")),
+		Config:   PowerShellCommand(prelude + serviceConfigScript),
+	}, nil
+}
+
+// ServiceStatesCommand Строит команду получения состояний нескольких служб одним запросом
+// (массив JSON с полями Name и State).
+func ServiceStatesCommand(serviceNames []string) (string, error) {
+	names := make([]string, len(serviceNames))
+	for i, serviceName := range serviceNames {
+		if err := ValidateServiceName(serviceName); err != nil {
+			return "", err
+		}
+
+		names[i] = quotePowerShell(serviceName)
+	}
+
+	return PowerShellCommand(fmt.Sprintf(serviceStatesScript, strings.Join(names, ","))), nil
+}
+
+// ListServicesCommand Строит команду получения всех служб сервера (JSON с полями name и display_name).
+func ListServicesCommand() string {
+	return PowerShellCommand(
+		`Get-Service | ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName } } | ConvertTo-Json -Compress`,
+	)
+}
+
+// ValidateServiceName Проверяет, что имя службы может быть безопасно передано в команду.
+func ValidateServiceName(serviceName string) error {
+	switch {
+	case serviceName == "":
+		return errs.NewErrInvalidServiceName(serviceName, "имя не может быть пустым")
+	case len(serviceName) > MaxServiceNameLen:
+		return errs.NewErrInvalidServiceName(serviceName, fmt.Sprintf("имя длиннее %d символов", MaxServiceNameLen))
+	case !serviceNameRegex.MatchString(serviceName):
+		return errs.NewErrInvalidServiceName(serviceName,
+			"допустимы латинские буквы, цифры, пробел и символы _ . - $ @ # + { }")
+	}
+
+	return nil
+}
+
+// PowerShellCommand Возвращает команду запуска скрипта PowerShell через -EncodedCommand.
+func PowerShellCommand(script string) string {
+	encoded := utf16.Encode([]rune(script))
+
+	buf := make([]byte, 0, len(encoded)*2)
+	for _, c := range encoded {
+		buf = append(buf, byte(c), byte(c>>8))
+	}
+
+	return powerShellPrefix + base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodePowerShellCommand Возвращает скрипт из команды, построенной PowerShellCommand
+// (например, для отображения в трассировке), и false для любой другой команды.
+func DecodePowerShellCommand(cmd string) (string, bool) {
+	encoded, ok := strings.CutPrefix(cmd, powerShellPrefix)
+	if !ok {
+		return "", false
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(buf)%2 != 0 {
+		return "", false
+	}
+
+	chars := make([]uint16, len(buf)/2)
+	for i := range chars {
+		chars[i] = uint16(buf[2*i]) | uint16(buf[2*i+1])<<8
+	}
+
+	return string(utf16.Decode(chars)), true
+}
+
+// quotePowerShell Возвращает строку в виде литерала PowerShell в одинарных кавычках,
+// внутри которого не подставляются переменные и подвыражения ($x, $(...)).
+// Одинарные кавычки (включая типографские, которые PowerShell тоже считает кавычками) удваиваются;
+// имена служб проходят ValidateServiceName и кавычек не содержат, экранирование — дополнительная защита.
+func quotePowerShell(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('\'')
+	for _, r := range s {
+		if strings.ContainsRune("'\u2018\u2019\u201A\u201B", r) {
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+
+	return b.String()
+}
@@ -0,0 +1,213 @@
+package service_control_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+)
+
+// encodedCommandRegex Команда PowerShell с -EncodedCommand: после префикса допустимы только символы base64.
+var encodedCommandRegex = regexp.MustCompile(`^powershell\.exe -NoProfile -NonInteractive -EncodedCommand [A-Za-z0-9+/]+=*$`)
+
+// injectionSeeds Имена служб с попытками выйти за пределы аргумента в cmd.exe или PowerShell.
+var injectionSeeds = []string{
+	`spooler`,
+	`MSSQL$SQLEXPRESS`,
+	`Apple Mobile Device Service`,
+	`spooler" & calc & "`,
+	`spooler"; calc; "`,
+	`spooler' ; Remove-Item C:\ -Recurse ; '`,
+	`spooler'; calc; '`,
+	`$(calc)`,
+	`spooler$(Start-Process calc)`,
+	"spooler`; calc",
+	`spooler | calc`,
+	`spooler && calc`,
+	`spooler > C:\out.txt`,
+	`%COMSPEC%`,
+	`spooler’; calc; ‘`,
+	"spooler\n calc",
+	"spooler\r\ncalc",
+	`*`,
+	`[a-z]*`,
+	` spooler`,
+	`spooler `,
+	`спулер`,
+	"spooler\x00calc",
+}
+
+// TestValidateServiceName Проверяет допустимые и недопустимые имена служб.
+func TestValidateServiceName(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		wantErr     bool
+	}{
+		{name: "простое имя", serviceName: "spooler"},
+		{name: "имя экземпляра SQL Server", serviceName: "MSSQL$SQLEXPRESS"},
+		{name: "имя с пробелами", serviceName: "Apple Mobile Device Service"},
+		{name: "имя в фигурных скобках", serviceName: "{4D36E972-E325-11CE-BFC1-08002BE10318}"},
+		{name: "имя со знаками", serviceName: "svc_1.0-beta@host#2+x"},
+		{name: "пустое имя", serviceName: "", wantErr: true},
+		{name: "слишком длинное имя", serviceName: strings.Repeat("a", service_control.MaxServiceNameLen+1), wantErr: true},
+		{name: "двойная кавычка", serviceName: `spooler" & calc & "`, wantErr: true},
+		{name: "одинарная кавычка", serviceName: `spooler'`, wantErr: true},
+		{name: "типографская кавычка", serviceName: `spooler’`, wantErr: true},
+		{name: "точка с запятой", serviceName: `spooler;calc`, wantErr: true},
+		{name: "амперсанд", serviceName: `spooler&calc`, wantErr: true},
+		{name: "подвыражение PowerShell", serviceName: `$(calc)`, wantErr: true},
+		{name: "обратная кавычка", serviceName: "spooler`n", wantErr: true},
+		{name: "символ подстановки", serviceName: `spool*`, wantErr: true},
+		{name: "переменная cmd", serviceName: `%COMSPEC%`, wantErr: true},
+		{name: "перевод строки", serviceName: "spooler\ncalc", wantErr: true},
+		{name: "пробел в начале", serviceName: " spooler", wantErr: true},
+		{name: "не латинские буквы", serviceName: "спулер", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service_control.ValidateServiceName(tt.serviceName)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errInvalid *errs.ErrInvalidServiceName
+			require.True(t, errors.As(err, &errInvalid))
+			assert.Equal(t, tt.serviceName, errInvalid.ServiceName)
+		})
+	}
+}
+
+// TestNewServiceCommands Проверяет скрипты PowerShell, передаваемые через -EncodedCommand.
+func TestNewServiceCommands(t *testing.T) {
+	commands, err := service_control.NewServiceCommands("MSSQL$SQLEXPRESS")
+	require.NoError(t, err)
+
+	for cmd, want := range map[string]string{
+		commands.Query:    "Get-Service -Name $name",
+		commands.Start:    "-MethodName StartService",
+		commands.Stop:     "-MethodName StopService",
+		commands.Pause:    "-MethodName PauseService",
+		commands.Continue: "-MethodName ResumeService",
+		commands.Config:   "StartType        = [int]$status.StartType",
+	} {
+		assert.Regexp(t, encodedCommandRegex, cmd)
+
+		script, ok := service_control.DecodePowerShellCommand(cmd)
+		require.True(t, ok)
+		assert.True(t, strings.HasPrefix(script, "$ErrorActionPreference = 'Stop'\n$name = 'MSSQL$SQLEXPRESS'\n"))
+		assert.Contains(t, script, want)
+	}
+
+	_, err = service_control.NewServiceCommands(`spooler" & calc & "`)
+	assert.Error(t, err)
+}
+
+// TestServiceStatesCommand Проверяет команду получения состояний нескольких служб.
+func TestServiceStatesCommand(t *testing.T) {
+	cmd, err := service_control.ServiceStatesCommand([]string{"spooler", "wuauserv"})
+	require.NoError(t, err)
+	assert.Regexp(t, encodedCommandRegex, cmd)
+
+	script, ok := service_control.DecodePowerShellCommand(cmd)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(script, "$names = @('spooler','wuauserv')\n"))
+
+	_, err = service_control.ServiceStatesCommand([]string{"spooler", "x'); calc; ('"})
+	assert.Error(t, err)
+}
+
+// TestDecodePowerShellCommand Проверяет, что обычные команды не принимаются за закодированные.
+func TestDecodePowerShellCommand(t *testing.T) {
+	script, ok := service_control.DecodePowerShellCommand(service_control.ListServicesCommand())
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(script, "Get-Service |"))
+
+	_, ok = service_control.DecodePowerShellCommand(`sc query "spooler"`)
+	assert.False(t, ok)
+
+	_, ok = service_control.DecodePowerShellCommand("powershell.exe -NoProfile -NonInteractive -EncodedCommand %%%")
+	assert.False(t, ok)
+}
+
+// FuzzNewServiceCommands Проверяет, что никакое имя службы не выходит за пределы своего аргумента:
+// либо имя отклоняется, либо командная строка состоит только из префикса и base64,
+// а в скрипте имя целиком находится внутри литерала в одинарных кавычках, который оно не может закрыть.
+func FuzzNewServiceCommands(f *testing.F) {
+	for _, seed := range injectionSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, serviceName string) {
+		commands, err := service_control.NewServiceCommands(serviceName)
+		if err != nil {
+			return
+		}
+
+		assertSafeServiceName(t, serviceName)
+
+		for _, cmd := range []string{commands.Query, commands.Start, commands.Stop, commands.Pause, commands.Continue, commands.Config} {
+			if !encodedCommandRegex.MatchString(cmd) {
+				t.Fatalf("командная строка содержит не только base64: %q", cmd)
+			}
+
+			script, ok := service_control.DecodePowerShellCommand(cmd)
+			if !ok {
+				t.Fatalf("не удалось декодировать команду %q", cmd)
+			}
+
+			if want := "$ErrorActionPreference = 'Stop'\n$name = '" + serviceName + "'\n"; !strings.HasPrefix(script, want) {
+				t.Fatalf("скрипт %q, ожидалось начало %q", script, want)
+			}
+		}
+	})
+}
+
+// FuzzServiceStatesCommand Проверяет команду состояний для набора имен: недопустимое имя
+// отклоняет весь набор, иначе каждое имя находится в отдельном литерале.
+func FuzzServiceStatesCommand(f *testing.F) {
+	for _, seed := range injectionSeeds {
+		f.Add("spooler", seed)
+	}
+
+	f.Fuzz(func(t *testing.T, first, second string) {
+		cmd, err := service_control.ServiceStatesCommand([]string{first, second})
+		if err != nil {
+			return
+		}
+
+		assertSafeServiceName(t, first)
+		assertSafeServiceName(t, second)
+
+		if !encodedCommandRegex.MatchString(cmd) {
+			t.Fatalf("командная строка содержит не только base64: %q", cmd)
+		}
+
+		script, ok := service_control.DecodePowerShellCommand(cmd)
+		if !ok {
+			t.Fatalf("не удалось декодировать команду %q", cmd)
+		}
+
+		if want := "$names = @('" + first + "','" + second + "')\n"; !strings.HasPrefix(script, want) {
+			t.Fatalf("скрипт %q, ожидалось начало %q", script, want)
+		}
+	})
+}
+
+// assertSafeServiceName Проверяет, что принятое имя не содержит символов, с помощью которых
+// можно закрыть литерал PowerShell или аргумент cmd.exe и выполнить другую команду.
+func assertSafeServiceName(t *testing.T, serviceName string) {
+	t.Helper()
+
+	if strings.ContainsAny(serviceName, "'\"`‘’‚‛&;|<>()%^!*?[]\r\n\x00") {
+		t.Fatalf("принято небезопасное имя службы %q", serviceName)
+	}
+}
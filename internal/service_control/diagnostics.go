@@ -0,0 +1,322 @@
+package service_control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// Команды, выполняемые при проверке подключения: простая команда cmd для проверки аутентификации
+// и команда PowerShell для проверки того, что PowerShell доступен и не ограничен.
+const (
+	authCheckCommand       = `hostname`
+	powerShellCheckCommand = `powershell -NoProfile -NonInteractive -ExecutionPolicy Bypass -Command "[Console]::Out.Write($PSVersionTable.PSVersion.ToString())"`
+)
+
+// WinRMDiagnoser Пошаговая проверка подключения к серверу по WinRM: каждый этап
+// (DNS, ICMP, TCP, HTTP(S), аутентификация, PowerShell, fingerprint) выполняется отдельно,
+// чтобы по отчету можно было понять, на каком из них и почему подключение не удалось.
+type WinRMDiagnoser struct {
+	clientFactory ClientFactory
+	netChecker    netutils.Checker
+	winRMConfig   *config.WinRMConfig
+}
+
+// NewWinRMDiagnoser Конструктор.
+func NewWinRMDiagnoser(clientFactory ClientFactory, netChecker netutils.Checker, winRMConfig *config.WinRMConfig) *WinRMDiagnoser {
+	return &WinRMDiagnoser{
+		clientFactory: clientFactory,
+		netChecker:    netChecker,
+		winRMConfig:   winRMConfig,
+	}
+}
+
+// stepResult Результат этапа проверки.
+type stepResult struct {
+	status  string
+	message string
+	hint    string
+}
+
+// Diagnose Выполняет этапы проверки подключения по порядку. После проваленного этапа
+// остальные не выполняются и отмечаются пропущенными. Недоступность по ICMP считается предупреждением.
+// Параметры подключения, заданные для сервера в settings, заменяют глобальные.
+func (d *WinRMDiagnoser) Diagnose(ctx context.Context, address, username, password string, settings models.WinRMSettings) *models.ConnectionTestReport {
+	cfg := d.winRMConfig.ForServer(settings)
+
+	auth := cfg.Auth
+	if auth == "" {
+		auth = models.WinRMAuthBasic
+	}
+
+	report := &models.ConnectionTestReport{
+		Address: address,
+		Port:    cfg.Port,
+		HTTPS:   cfg.UseHTTPS,
+		Auth:    auth,
+		Steps:   make([]models.ConnectionTestStep, 0, 7),
+	}
+
+	var client Client
+
+	steps := []struct {
+		name string
+		run  func(ctx context.Context) stepResult
+	}{
+		{models.ConnectionStepResolve, func(ctx context.Context) stepResult {
+			return d.resolve(ctx, address, cfg)
+		}},
+		{models.ConnectionStepICMP, func(ctx context.Context) stepResult {
+			return d.icmp(ctx, address, cfg)
+		}},
+		{models.ConnectionStepTCP, func(ctx context.Context) stepResult {
+			return d.tcp(ctx, address, cfg)
+		}},
+		{models.ConnectionStepWinRM, func(ctx context.Context) stepResult {
+			return d.winRMHTTP(ctx, address, cfg)
+		}},
+		{models.ConnectionStepAuth, func(ctx context.Context) stepResult {
+			var result stepResult
+			client, result = d.auth(ctx, address, username, password, settings, cfg, auth)
+			return result
+		}},
+		{models.ConnectionStepCommand, func(ctx context.Context) stepResult {
+			return d.powerShell(ctx, client, cfg)
+		}},
+		{models.ConnectionStepFingerprint, func(ctx context.Context) stepResult {
+			fingerprint, result := d.fingerprint(ctx, client, cfg)
+			if result.status == models.ConnectionStepOK {
+				report.Fingerprint = &fingerprint
+			}
+			return result
+		}},
+	}
+
+	start := time.Now()
+	failed := false
+
+	for _, step := range steps {
+		if failed {
+			report.Steps = append(report.Steps, models.ConnectionTestStep{Name: step.name, Status: models.ConnectionStepSkipped})
+			continue
+		}
+
+		stepStart := time.Now()
+		result := step.run(ctx)
+
+		report.Steps = append(report.Steps, models.ConnectionTestStep{
+			Name:       step.name,
+			Status:     result.status,
+			DurationMs: time.Since(stepStart).Milliseconds(),
+			Message:    result.message,
+			Hint:       result.hint,
+		})
+
+		failed = result.status == models.ConnectionStepFailed
+	}
+
+	report.Success = !failed
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	return report
+}
+
+// resolve Разрешает имя сервера в IP-адреса.
+func (d *WinRMDiagnoser) resolve(ctx context.Context, address string, cfg *config.WinRMConfig) stepResult {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout(cfg))
+	defer cancel()
+
+	addrs, err := d.netChecker.LookupHost(ctx, address)
+	if err != nil {
+		hint := "проверьте DNS-сервер, указанный на сервере мониторинга"
+
+		var dnsErr *net.DNSError
+		switch {
+		case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+			hint = "имя не найдено в DNS: проверьте написание имени сервера или укажите его IP-адрес"
+		case isTimeout(err):
+			hint = "DNS-сервер не ответил вовремя: проверьте его доступность с сервера мониторинга"
+		}
+
+		return failedStep(err, hint)
+	}
+
+	return stepResult{status: models.ConnectionStepOK, message: strings.Join(addrs, ", ")}
+}
+
+// icmp Проверяет ответ сервера на ping. Неудача не прерывает проверку: ICMP часто блокируется брандмауэром.
+func (d *WinRMDiagnoser) icmp(ctx context.Context, address string, cfg *config.WinRMConfig) stepResult {
+	if !d.netChecker.CheckICMP(ctx, address, connectTimeout(cfg)) {
+		return stepResult{
+			status:  models.ConnectionStepWarning,
+			message: "сервер не ответил на ping",
+			hint:    "ICMP может быть заблокирован брандмауэром сервера или сети; для работы WinRM ping не требуется",
+		}
+	}
+
+	return stepResult{status: models.ConnectionStepOK, message: "сервер отвечает на ping"}
+}
+
+// tcp Проверяет, что порт WinRM принимает соединения.
+func (d *WinRMDiagnoser) tcp(ctx context.Context, address string, cfg *config.WinRMConfig) stepResult {
+	err := d.netChecker.CheckTCP(ctx, address, cfg.Port, connectTimeout(cfg))
+	if err != nil {
+		hint := "проверьте сетевую доступность сервера с сервера мониторинга"
+
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			hint = fmt.Sprintf("порт %s закрыт: проверьте, что служба WinRM запущена и слушает этот порт (winrm quickconfig)", cfg.Port)
+		case isTimeout(err):
+			hint = fmt.Sprintf("порт %s не отвечает: вероятно, подключение блокируется брандмауэром; при медленной сети увеличьте connect_timeout сервера", cfg.Port)
+		}
+
+		return failedStep(err, hint)
+	}
+
+	return stepResult{status: models.ConnectionStepOK, message: fmt.Sprintf("порт %s открыт", cfg.Port)}
+}
+
+// winRMHTTP Проверяет, что на порту отвечает WinRM по HTTP или HTTPS (с TLS-рукопожатием).
+func (d *WinRMDiagnoser) winRMHTTP(ctx context.Context, address string, cfg *config.WinRMConfig) stepResult {
+	scheme := "HTTP"
+	hint := "порт открыт, но WinRM не ответил по HTTP: возможно, на порту настроен HTTPS-слушатель (https: true) или порт занят другой службой"
+
+	if cfg.UseHTTPS {
+		scheme = "HTTPS"
+		hint = "порт открыт, но не удалось установить TLS-соединение или получить ответ WinRM: проверьте HTTPS-слушатель WinRM и его сертификат (winrm enumerate winrm/config/listener)"
+	}
+
+	if !d.netChecker.CheckWinRM(ctx, address, cfg.Port, cfg.UseHTTPS, cfg.ConnectTimeout) {
+		return stepResult{
+			status:  models.ConnectionStepFailed,
+			message: fmt.Sprintf("WinRM не ответил по %s", scheme),
+			hint:    hint,
+		}
+	}
+
+	return stepResult{status: models.ConnectionStepOK, message: fmt.Sprintf("WinRM отвечает по %s", scheme)}
+}
+
+// auth Создает клиент WinRM и выполняет простую команду cmd: ошибка на этом этапе означает,
+// что сервер не принял учетные данные или у пользователя нет прав на удаленное управление.
+func (d *WinRMDiagnoser) auth(ctx context.Context, address, username, password string, settings models.WinRMSettings, cfg *config.WinRMConfig, auth string) (Client, stepResult) {
+	client, err := d.clientFactory.CreateClient(address, username, password, settings)
+	if err != nil {
+		return nil, failedStep(err, "проверьте логин, пароль и параметры подключения WinRM (порт, механизм аутентификации, настройки Kerberos)")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout(cfg))
+	defer cancel()
+
+	hostname, err := client.RunCommand(ctx, authCheckCommand)
+	if err != nil {
+		return nil, failedStep(err, authHint(err, auth, cfg))
+	}
+
+	return client, stepResult{status: models.ConnectionStepOK, message: fmt.Sprintf("выполнен вход пользователя %s на %s", username, strings.TrimSpace(hostname))}
+}
+
+// powerShell Проверяет, что на сервере можно выполнять команды PowerShell.
+func (d *WinRMDiagnoser) powerShell(ctx context.Context, client Client, cfg *config.WinRMConfig) stepResult {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout(cfg))
+	defer cancel()
+
+	version, err := client.RunCommand(ctx, powerShellCheckCommand)
+	if err != nil {
+		hint := "PowerShell недоступен или ограничен: проверьте политику выполнения (ExecutionPolicy), режим Constrained Language и правила AppLocker/WDAC"
+		if isTimeout(err) {
+			hint = "PowerShell не ответил вовремя: сервер может быть перегружен; увеличьте таймаут WinRM сервера"
+		}
+
+		return failedStep(err, hint)
+	}
+
+	return stepResult{status: models.ConnectionStepOK, message: "PowerShell " + strings.TrimSpace(version)}
+}
+
+// fingerprint Получает уникальный идентификатор сервера (MachineGuid).
+func (d *WinRMDiagnoser) fingerprint(ctx context.Context, client Client, cfg *config.WinRMConfig) (uuid.UUID, stepResult) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout(cfg))
+	defer cancel()
+
+	output, err := client.RunCommand(ctx, fingerprintCommand)
+	if err != nil {
+		return uuid.Nil, failedStep(err, `не удалось прочитать MachineGuid из HKLM:\SOFTWARE\Microsoft\Cryptography: проверьте права пользователя на чтение реестра`)
+	}
+
+	fingerprint, err := uuid.Parse(strings.TrimSpace(output))
+	if err != nil {
+		return uuid.Nil, stepResult{
+			status:  models.ConnectionStepFailed,
+			message: fmt.Sprintf("неожиданный ответ сервера: %q", output),
+			hint:    "MachineGuid сервера поврежден или команда вернула лишний вывод (например, из профиля пользователя)",
+		}
+	}
+
+	return fingerprint, stepResult{status: models.ConnectionStepOK, message: fingerprint.String()}
+}
+
+// authHint Возвращает вероятную причину ошибки аутентификации по тексту ошибки WinRM.
+func authHint(err error, auth string, cfg *config.WinRMConfig) string {
+	msg := err.Error()
+
+	switch {
+	case strings.Contains(msg, "401"):
+		if auth == models.WinRMAuthBasic && !cfg.UseHTTPS {
+			return "неверный логин или пароль либо Basic-аутентификация не разрешена: для Basic по HTTP на сервере нужны Auth/Basic=true и AllowUnencrypted=true (или используйте ntlm)"
+		}
+		return fmt.Sprintf("неверный логин или пароль либо механизм аутентификации %s не разрешен в настройках службы WinRM сервера", auth)
+	case strings.Contains(msg, "x509") || strings.Contains(msg, "certificate"):
+		return "сертификат сервера не прошел проверку: установите доверенный сертификат или отключите его проверку для сервера (insecure: true)"
+	case strings.Contains(msg, "Access is denied") || strings.Contains(msg, "AccessDenied") || strings.Contains(msg, "Отказано в доступе"):
+		return "у пользователя нет прав на удаленное управление: добавьте его в группу Remote Management Users или Administrators сервера"
+	case isTimeout(err):
+		return "сервер не ответил вовремя: увеличьте таймаут WinRM сервера"
+	case auth == models.WinRMAuthKerberos:
+		return "проверьте krb5.conf, keytab, realm пользователя и то, что сервер указан по имени, для которого зарегистрирован SPN (HTTP/<имя сервера>)"
+	}
+
+	return "проверьте логин, пароль и механизм аутентификации WinRM"
+}
+
+// failedStep Возвращает результат проваленного этапа с текстом ошибки и подсказкой.
+func failedStep(err error, hint string) stepResult {
+	return stepResult{status: models.ConnectionStepFailed, message: err.Error(), hint: hint}
+}
+
+// isTimeout Проверяет, что ошибка вызвана истечением таймаута.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// connectTimeout Таймаут сетевых проверок сервера (как при проверке доступности WinRM).
+func connectTimeout(cfg *config.WinRMConfig) time.Duration {
+	if cfg.ConnectTimeout > 0 {
+		return cfg.ConnectTimeout
+	}
+
+	return netutils.DefaultHostTimeout
+}
+
+// commandTimeout Таймаут выполнения команды при проверке подключения.
+func commandTimeout(cfg *config.WinRMConfig) time.Duration {
+	if cfg.Timeout > 0 {
+		return cfg.Timeout
+	}
+
+	return defaultTimeout
+}
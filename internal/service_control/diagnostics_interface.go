@@ -0,0 +1,14 @@
+package service_control
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+//go:generate mockgen -destination=mocks/mock_diagnoser.go -package=mocks . Diagnoser
+
+// Diagnoser Интерфейс для пошаговой проверки подключения к серверу.
+type Diagnoser interface {
+	Diagnose(ctx context.Context, address, username, password string, settings models.WinRMSettings) *models.ConnectionTestReport
+}
@@ -0,0 +1,165 @@
+package service_control_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	netutisMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+)
+
+// TestDiagnose Проверяет пошаговую проверку подключения и подсказки для проваленных этапов.
+func TestDiagnose(t *testing.T) {
+	const (
+		address = "srv-01.corp.local"
+		guid    = "550e8400-e29b-41d4-a716-446655440000"
+	)
+
+	// networkOK Настраивает успешное прохождение сетевых этапов
+	networkOK := func(m *netutisMocks.MockChecker) {
+		m.EXPECT().LookupHost(gomock.Any(), address).Return([]string{"10.0.0.5"}, nil)
+		m.EXPECT().CheckICMP(gomock.Any(), address, gomock.Any()).Return(true)
+		m.EXPECT().CheckTCP(gomock.Any(), address, "5985", gomock.Any()).Return(nil)
+		m.EXPECT().CheckWinRM(gomock.Any(), address, "5985", false, gomock.Any()).Return(true)
+	}
+
+	tests := []struct {
+		name            string
+		setupChecker    func(m *netutisMocks.MockChecker)
+		setupClient     func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient)
+		expectedSuccess bool
+		expectedStatus  []string // результаты этапов по порядку
+		failedHint      string   // фрагмент подсказки проваленного этапа
+	}{
+		{
+			name:         "все этапы пройдены",
+			setupChecker: networkOK,
+			setupClient: func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {
+				f.EXPECT().CreateClient(address, "admin", "password", models.WinRMSettings{}).Return(c, nil)
+				c.EXPECT().RunCommand(gomock.Any(), "hostname").Return("SRV-01\r\n", nil)
+				c.EXPECT().RunCommand(gomock.Any(), gomock.Any()).Return("5.1.17763.1", nil)
+				c.EXPECT().RunCommand(gomock.Any(), gomock.Any()).Return(guid, nil)
+			},
+			expectedSuccess: true,
+			expectedStatus:  []string{"ok", "ok", "ok", "ok", "ok", "ok", "ok"},
+		},
+		{
+			name: "имя не найдено в DNS",
+			setupChecker: func(m *netutisMocks.MockChecker) {
+				m.EXPECT().LookupHost(gomock.Any(), address).
+					Return(nil, &net.DNSError{Err: "no such host", Name: address, IsNotFound: true})
+			},
+			setupClient:    func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {},
+			expectedStatus: []string{"failed", "skipped", "skipped", "skipped", "skipped", "skipped", "skipped"},
+			failedHint:     "имя не найдено в DNS",
+		},
+		{
+			name: "ICMP заблокирован, порт закрыт",
+			setupChecker: func(m *netutisMocks.MockChecker) {
+				m.EXPECT().LookupHost(gomock.Any(), address).Return([]string{"10.0.0.5"}, nil)
+				m.EXPECT().CheckICMP(gomock.Any(), address, gomock.Any()).Return(false)
+				m.EXPECT().CheckTCP(gomock.Any(), address, "5985", gomock.Any()).
+					Return(fmt.Errorf("dial tcp 10.0.0.5:5985: %w", syscall.ECONNREFUSED))
+			},
+			setupClient:    func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {},
+			expectedStatus: []string{"ok", "warning", "failed", "skipped", "skipped", "skipped", "skipped"},
+			failedHint:     "порт 5985 закрыт",
+		},
+		{
+			name: "порт не отвечает по HTTP",
+			setupChecker: func(m *netutisMocks.MockChecker) {
+				m.EXPECT().LookupHost(gomock.Any(), address).Return([]string{"10.0.0.5"}, nil)
+				m.EXPECT().CheckICMP(gomock.Any(), address, gomock.Any()).Return(true)
+				m.EXPECT().CheckTCP(gomock.Any(), address, "5985", gomock.Any()).Return(nil)
+				m.EXPECT().CheckWinRM(gomock.Any(), address, "5985", false, gomock.Any()).Return(false)
+			},
+			setupClient:    func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {},
+			expectedStatus: []string{"ok", "ok", "ok", "failed", "skipped", "skipped", "skipped"},
+			failedHint:     "HTTPS-слушатель",
+		},
+		{
+			name:         "неверные учетные данные",
+			setupChecker: networkOK,
+			setupClient: func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {
+				f.EXPECT().CreateClient(address, "admin", "password", models.WinRMSettings{}).Return(c, nil)
+				c.EXPECT().RunCommand(gomock.Any(), "hostname").
+					Return("", errors.New("ошибка выполнения команды: http error 401: ; stderr: "))
+			},
+			expectedStatus: []string{"ok", "ok", "ok", "ok", "failed", "skipped", "skipped"},
+			failedHint:     "AllowUnencrypted",
+		},
+		{
+			name:         "PowerShell ограничен",
+			setupChecker: networkOK,
+			setupClient: func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {
+				f.EXPECT().CreateClient(address, "admin", "password", models.WinRMSettings{}).Return(c, nil)
+				c.EXPECT().RunCommand(gomock.Any(), "hostname").Return("SRV-01", nil)
+				c.EXPECT().RunCommand(gomock.Any(), gomock.Any()).
+					Return("", errors.New("ошибка выполнения команды: exit status 1; stderr: PSSecurityException"))
+			},
+			expectedStatus: []string{"ok", "ok", "ok", "ok", "ok", "failed", "skipped"},
+			failedHint:     "ExecutionPolicy",
+		},
+		{
+			name:         "неверный fingerprint",
+			setupChecker: networkOK,
+			setupClient: func(f *serviceControlMocks.MockClientFactory, c *serviceControlMocks.MockClient) {
+				f.EXPECT().CreateClient(address, "admin", "password", models.WinRMSettings{}).Return(c, nil)
+				c.EXPECT().RunCommand(gomock.Any(), "hostname").Return("SRV-01", nil)
+				c.EXPECT().RunCommand(gomock.Any(), gomock.Any()).Return("5.1.17763.1", nil)
+				c.EXPECT().RunCommand(gomock.Any(), gomock.Any()).Return("Welcome!\r\n"+guid, nil)
+			},
+			expectedStatus: []string{"ok", "ok", "ok", "ok", "ok", "ok", "failed"},
+			failedHint:     "лишний вывод",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockChecker := netutisMocks.NewMockChecker(ctrl)
+			mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+			mockClient := serviceControlMocks.NewMockClient(ctrl)
+
+			tt.setupChecker(mockChecker)
+			tt.setupClient(mockFactory, mockClient)
+
+			diagnoser := service_control.NewWinRMDiagnoser(mockFactory, mockChecker, &config.WinRMConfig{Port: "5985"})
+
+			report := diagnoser.Diagnose(context.Background(), address, "admin", "password", models.WinRMSettings{})
+
+			require.Len(t, report.Steps, len(tt.expectedStatus))
+			assert.Equal(t, tt.expectedSuccess, report.Success)
+			assert.Equal(t, "5985", report.Port)
+			assert.Equal(t, models.WinRMAuthBasic, report.Auth)
+
+			for i, step := range report.Steps {
+				assert.Equal(t, tt.expectedStatus[i], step.Status, "этап %s", step.Name)
+
+				if step.Status == models.ConnectionStepFailed {
+					assert.Contains(t, step.Hint, tt.failedHint)
+				}
+			}
+
+			if tt.expectedSuccess {
+				require.NotNil(t, report.Fingerprint)
+				assert.Equal(t, guid, report.Fingerprint.String())
+			} else {
+				assert.Nil(t, report.Fingerprint)
+			}
+		})
+	}
+}
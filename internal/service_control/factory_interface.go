@@ -1,8 +1,10 @@
 package service_control
 
+import "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+
 //go:generate mockgen -destination=mocks/mock_client_factory.go -package=mocks . ClientFactory
 
 // ClientFactory Интерфейс для создания новых Client-объектов.
 type ClientFactory interface {
-	CreateClient(address, username, password string) (Client, error)
+	CreateClient(address, username, password string, settings models.WinRMSettings) (Client, error)
 }
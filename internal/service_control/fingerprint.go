@@ -6,51 +6,54 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
 )
 
+// fingerprintCommand Команда для получения fingerprint (MachineGuid) сервера.
+const fingerprintCommand = `powershell -NoProfile -NonInteractive -ExecutionPolicy Bypass -Command "[Console]::Out.Write((Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Cryptography').MachineGuid)"`
+
 // WinRMFingerprinter Реальная реализация получения fingerprint через WinRM.
 type WinRMFingerprinter struct {
 	clientFactory ClientFactory
 	netChecker    netutils.Checker
-	winRMPort     string
+	winRMConfig   *config.WinRMConfig
 }
 
 // NewWinRMFingerprinter Конструктор.
-func NewWinRMFingerprinter(clientFactory ClientFactory, netChecker netutils.Checker, winRMPort string) *WinRMFingerprinter {
+func NewWinRMFingerprinter(clientFactory ClientFactory, netChecker netutils.Checker, winRMConfig *config.WinRMConfig) *WinRMFingerprinter {
 	return &WinRMFingerprinter{
 		clientFactory: clientFactory,
 		netChecker:    netChecker,
-		winRMPort:     winRMPort,
+		winRMConfig:   winRMConfig,
 	}
 }
 
 // GetFingerprint Получение fingerprint (MachineGuid) с Windows сервера.
-func (wf *WinRMFingerprinter) GetFingerprint(ctx context.Context, address, username, password string) (uuid.UUID, error) {
+// Параметры подключения, заданные для сервера в settings, заменяют глобальные.
+func (wf *WinRMFingerprinter) GetFingerprint(ctx context.Context, address, username, password string, settings models.WinRMSettings) (uuid.UUID, error) {
 	// проверяем доступность сервера, если недоступен - возвращаем ошибку
-	if !wf.netChecker.CheckWinRM(ctx, address, wf.winRMPort, 0) {
+	if !IsWinRMAvailable(ctx, wf.netChecker, wf.winRMConfig, address, settings) {
 		logger.Log.Warn(fmt.Sprintf("Сервер %s недоступен", address))
 		return uuid.Nil, fmt.Errorf("сервер %s недоступен", address)
 	}
 
 	// создаём WinRM клиент для получения fingerprint (MachineGuid) с Windows сервера
-	client, err := wf.clientFactory.CreateClient(address, username, password)
+	client, err := wf.clientFactory.CreateClient(address, username, password, settings)
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
 		return uuid.Nil, fmt.Errorf("ошибка создания WinRM клиента: %w", err)
 	}
 
-	// команда для получения fingerprint сервера
-	fingerprintCmd := `powershell -NoProfile -NonInteractive -ExecutionPolicy Bypass -Command "[Console]::Out.Write((Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Cryptography').MachineGuid)"`
-
 	// контекст для получения fingerprint
 	fingerprintCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// получение fingerprint сервера
-	fingerprintStr, err := client.RunCommand(fingerprintCtx, fingerprintCmd)
+	fingerprintStr, err := client.RunCommand(fingerprintCtx, fingerprintCommand)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("не удалось получить уникальный идентификатор сервера: %w", err)
 	}
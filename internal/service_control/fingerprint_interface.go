@@ -4,11 +4,12 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
 //go:generate mockgen -destination=mocks/mock_fingerprinter.go -package=mocks . Fingerprinter
 
 // Fingerprinter Интерфейс для получения fingerprint сервера.
 type Fingerprinter interface {
-	GetFingerprint(ctx context.Context, address, username, password string) (uuid.UUID, error)
+	GetFingerprint(ctx context.Context, address, username, password string, settings models.WinRMSettings) (uuid.UUID, error)
 }
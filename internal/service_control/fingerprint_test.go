@@ -10,7 +10,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	netutisMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
@@ -29,7 +31,7 @@ func TestNewWinRMFingerprinter(t *testing.T) {
 	mockChecker := netutisMocks.NewMockChecker(ctrl)
 	mockWinRMPort := "5985"
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
 	assert.NotNil(t, fp)
 }
@@ -49,20 +51,20 @@ func TestGetFingerprintSuccess(t *testing.T) {
 	expectedGUID := "550e8400-e29b-41d4-a716-446655440000"
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(expectedGUID, nil)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGUID, fingerprint.String())
@@ -80,12 +82,12 @@ func TestGetFingerprintHostUnreachable(t *testing.T) {
 	ctx := context.Background()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(false)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -104,16 +106,16 @@ func TestGetFingerprintClientFactoryError(t *testing.T) {
 	ctx := context.Background()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("authentication failed"))
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -133,20 +135,20 @@ func TestGetFingerprintRunCommandError(t *testing.T) {
 	ctx := context.Background()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("", errors.New("PowerShell error"))
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -168,20 +170,20 @@ func TestGetFingerprintInvalidUUID(t *testing.T) {
 	invalidGUID := "not-a-valid-guid"
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(invalidGUID, nil)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -201,20 +203,20 @@ func TestGetFingerprintEmptyResponse(t *testing.T) {
 	ctx := context.Background()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("", nil)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -248,20 +250,20 @@ func TestGetFingerprintDifferentGUIDs(t *testing.T) {
 			ctx := context.Background()
 
 			mockChecker.EXPECT().
-				CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, gomock.Any()).
+				CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, gomock.Any()).
 				Return(true)
 
 			mockFactory.EXPECT().
-				CreateClient("192.168.1.100", "admin", "password").
+				CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 				Return(mockClient, nil)
 
 			mockClient.EXPECT().
 				RunCommand(gomock.Any(), gomock.Any()).
 				Return(tt.guid, nil)
 
-			fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+			fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-			fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+			fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 			if tt.valid {
 				assert.NoError(t, err)
@@ -291,20 +293,20 @@ func TestGetFingerprintContextTimeout(t *testing.T) {
 	cancel()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("", context.DeadlineExceeded)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
+	fingerprint, err := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, fingerprint)
@@ -328,11 +330,11 @@ func TestGetFingerprintMultipleServers(t *testing.T) {
 
 	// Первый сервер
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.100", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient1, nil)
 
 	mockClient1.EXPECT().
@@ -341,21 +343,21 @@ func TestGetFingerprintMultipleServers(t *testing.T) {
 
 	// Второй сервер
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, "192.168.1.101", mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, "192.168.1.101", mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.101", "admin", "password").
+		CreateClient("192.168.1.101", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient2, nil)
 
 	mockClient2.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(guid2, nil)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	fingerprint1, err1 := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password")
-	fingerprint2, err2 := fp.GetFingerprint(ctx, "192.168.1.101", "admin", "password")
+	fingerprint1, err1 := fp.GetFingerprint(ctx, "192.168.1.100", "admin", "password", models.WinRMSettings{})
+	fingerprint2, err2 := fp.GetFingerprint(ctx, "192.168.1.101", "admin", "password", models.WinRMSettings{})
 
 	assert.NoError(t, err1)
 	assert.NoError(t, err2)
@@ -381,20 +383,20 @@ func TestGetFingerprintCredentials(t *testing.T) {
 	ctx := context.Background()
 
 	mockChecker.EXPECT().
-		CheckWinRM(ctx, expectedAddress, mockWinRMPort, time.Duration(0)).
+		CheckWinRM(ctx, expectedAddress, mockWinRMPort, false, time.Duration(0)).
 		Return(true)
 
 	mockFactory.EXPECT().
-		CreateClient(expectedAddress, expectedUsername, expectedPassword).
+		CreateClient(expectedAddress, expectedUsername, expectedPassword, models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("550e8400-e29b-41d4-a716-446655440000", nil)
 
-	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, mockWinRMPort)
+	fp := service_control.NewWinRMFingerprinter(mockFactory, mockChecker, &config.WinRMConfig{Port: mockWinRMPort})
 
-	_, err := fp.GetFingerprint(ctx, expectedAddress, expectedUsername, expectedPassword)
+	_, err := fp.GetFingerprint(ctx, expectedAddress, expectedUsername, expectedPassword, models.WinRMSettings{})
 
 	assert.NoError(t, err)
 }
@@ -0,0 +1,177 @@
+package service_control
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/masterzen/winrm"
+	"github.com/masterzen/winrm/soap"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+)
+
+// kerberosTransporter Транспорт WinRM с аутентификацией Kerberos (SPNEGO) по keytab или паролю.
+// Шифрование сообщений Kerberos библиотекой winrm не поддерживается, поэтому транспорт используется только поверх HTTPS.
+type kerberosTransporter struct {
+	krbClient  *client.Client
+	httpClient *http.Client
+	url        string
+	timeout    time.Duration // таймаут запросов, отправляемых после отмены команды
+
+	mu  sync.Mutex
+	ctx context.Context // контекст выполняемой команды, задается в setContext
+}
+
+// newKerberosTransporter Конструктор, возвращающий транспорт Kerberos для пользователя user.
+// Пользователь может быть задан как "user", "user@REALM" или "DOMAIN\user".
+func newKerberosTransporter(user, password string, cfg *config.WinRMConfig) (*kerberosTransporter, error) {
+	krbConf, err := krbconfig.Load(cfg.Krb5Config)
+	if err != nil {
+		return nil, fmt.Errorf("невозможно загрузить конфигурацию Kerberos %s: %w", cfg.Krb5Config, err)
+	}
+
+	username, realm := splitPrincipal(user)
+	if cfg.Krb5Realm != "" {
+		realm = cfg.Krb5Realm
+	}
+	if realm == "" {
+		realm = krbConf.LibDefaults.DefaultRealm
+	}
+	if realm == "" {
+		return nil, errors.New("не задан realm Kerberos: укажите WINRM_KRB5_REALM или default_realm в krb5.conf")
+	}
+
+	var krbClient *client.Client
+
+	switch {
+	case cfg.Krb5Keytab != "":
+		kt, err := keytab.Load(cfg.Krb5Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("невозможно загрузить keytab %s: %w", cfg.Krb5Keytab, err)
+		}
+
+		krbClient = client.NewWithKeytab(username, realm, kt, krbConf, client.DisablePAFXFAST(true))
+	case password != "":
+		krbClient = client.NewWithPassword(username, realm, password, krbConf,
+			client.DisablePAFXFAST(true), client.AssumePreAuthentication(true))
+	default:
+		return nil, errors.New("для аутентификации Kerberos нужен keytab (WINRM_KRB5_KEYTAB) или пароль сервера")
+	}
+
+	return &kerberosTransporter{krbClient: krbClient}, nil
+}
+
+// Transport Настраивает HTTP-транспорт для подключения к endpoint.
+func (t *kerberosTransporter) Transport(endpoint *winrm.Endpoint) error {
+	if !endpoint.HTTPS {
+		return errors.New("аутентификация Kerberos допустима только для HTTPS")
+	}
+
+	//nolint:gosec
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: endpoint.Insecure,
+			ServerName:         endpoint.TLSServerName,
+		},
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ResponseHeaderTimeout: endpoint.Timeout,
+	}
+
+	t.httpClient = &http.Client{Transport: transport}
+	t.timeout = endpoint.Timeout
+	t.url = (&url.URL{Scheme: "https", Host: net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port)), Path: "/wsman"}).String()
+
+	return nil
+}
+
+// setContext Задает контекст команды, с которым отправляются ее запросы. Библиотека winrm не передает
+// контекст в Transporter.Post, поэтому он задается перед каждой командой (клиент выполняет команды по одной).
+func (t *kerberosTransporter) setContext(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ctx = ctx
+}
+
+// requestContext Возвращает контекст запроса. После отмены команды библиотека отправляет запросы
+// завершения команды и удаления shell: они выполняются без отмены, но не дольше таймаута,
+// иначе shell оставался бы открытым на сервере.
+func (t *kerberosTransporter) requestContext() (context.Context, context.CancelFunc) {
+	t.mu.Lock()
+	ctx := t.ctx
+	t.mu.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if ctx.Err() != nil {
+		return context.WithTimeout(context.WithoutCancel(ctx), t.timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// Post Отправляет SOAP-сообщение с заголовком SPNEGO. Ошибки HTTP возвращаются в формате библиотеки winrm,
+// чтобы она распознавала штатные таймауты операций (OperationTimeout) при чтении вывода команды.
+func (t *kerberosTransporter) Post(_ *winrm.Client, request *soap.SoapMessage) (string, error) {
+	ctx, cancel := t.requestContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, strings.NewReader(request.String()))
+	if err != nil {
+		return "", fmt.Errorf("impossible to create http request %w", err)
+	}
+	req.Header.Set("Content-Type", "application/soap+xml;charset=UTF-8")
+
+	if err := spnego.SetSPNEGOHeader(t.krbClient, req, ""); err != nil {
+		return "", fmt.Errorf("ошибка получения билета Kerberos: %w", err)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unknown error %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error while reading request body %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("http error %d: %s", resp.StatusCode, body)
+	}
+
+	return string(body), nil
+}
+
+// splitPrincipal Разбирает имя пользователя на имя и realm ("user@realm" или "DOMAIN\user").
+func splitPrincipal(user string) (string, string) {
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		return user[:i], strings.ToUpper(user[i+1:])
+	}
+
+	if i := strings.Index(user, `\`); i >= 0 {
+		return user[i+1:], ""
+	}
+
+	return user, ""
+}
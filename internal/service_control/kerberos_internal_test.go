@@ -0,0 +1,45 @@
+package service_control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ctxKey Ключ значения контекста в тесте.
+type ctxKey struct{}
+
+// TestKerberosTransporterRequestContext Проверяет контекст запросов транспорта Kerberos:
+// запросы выполняемой команды отменяются вместе с ней, а запросы после отмены ограничены таймаутом.
+func TestKerberosTransporterRequestContext(t *testing.T) {
+	transporter := &kerberosTransporter{timeout: time.Second}
+
+	// контекст не задан
+	ctx, cancel := transporter.requestContext()
+	assert.Equal(t, context.Background(), ctx)
+	cancel()
+
+	// контекст выполняемой команды передается как есть
+	commandCtx, cancelCommand := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "trace"))
+	transporter.setContext(commandCtx)
+
+	ctx, cancel = transporter.requestContext()
+	assert.Equal(t, commandCtx, ctx)
+	cancel()
+
+	// после отмены команды запросы завершения команды и удаления shell не отменяются сразу
+	cancelCommand()
+
+	ctx, cancel = transporter.requestContext()
+	defer cancel()
+
+	require.NoError(t, ctx.Err())
+	assert.Equal(t, "trace", ctx.Value(ctxKey{}))
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+}
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	service_control "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 )
 
@@ -35,16 +36,16 @@ func (m *MockClientFactory) EXPECT() *MockClientFactoryMockRecorder {
 }
 
 // CreateClient mocks base method.
-func (m *MockClientFactory) CreateClient(arg0, arg1, arg2 string) (service_control.Client, error) {
+func (m *MockClientFactory) CreateClient(arg0, arg1, arg2 string, arg3 models.WinRMSettings) (service_control.Client, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(service_control.Client)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateClient indicates an expected call of CreateClient.
-func (mr *MockClientFactoryMockRecorder) CreateClient(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockClientFactoryMockRecorder) CreateClient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockClientFactory)(nil).CreateClient), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockClientFactory)(nil).CreateClient), arg0, arg1, arg2, arg3)
 }
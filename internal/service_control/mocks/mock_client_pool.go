@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/trsv-dev/simple-windows-services-monitor/internal/service_control (interfaces: ClientPool)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	service_control "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+)
+
+// MockClientPool is a mock of ClientPool interface.
+type MockClientPool struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientPoolMockRecorder
+}
+
+// MockClientPoolMockRecorder is the mock recorder for MockClientPool.
+type MockClientPoolMockRecorder struct {
+	mock *MockClientPool
+}
+
+// NewMockClientPool creates a new mock instance.
+func NewMockClientPool(ctrl *gomock.Controller) *MockClientPool {
+	mock := &MockClientPool{ctrl: ctrl}
+	mock.recorder = &MockClientPoolMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientPool) EXPECT() *MockClientPoolMockRecorder {
+	return m.recorder
+}
+
+// CreateClient mocks base method.
+func (m *MockClientPool) CreateClient(arg0, arg1, arg2 string, arg3 models.WinRMSettings) (service_control.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(service_control.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateClient indicates an expected call of CreateClient.
+func (mr *MockClientPoolMockRecorder) CreateClient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockClientPool)(nil).CreateClient), arg0, arg1, arg2, arg3)
+}
+
+// Invalidate mocks base method.
+func (m *MockClientPool) Invalidate(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Invalidate", arg0)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockClientPoolMockRecorder) Invalidate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockClientPool)(nil).Invalidate), arg0)
+}
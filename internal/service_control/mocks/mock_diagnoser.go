@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/trsv-dev/simple-windows-services-monitor/internal/service_control (interfaces: Diagnoser)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// MockDiagnoser is a mock of Diagnoser interface.
+type MockDiagnoser struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiagnoserMockRecorder
+}
+
+// MockDiagnoserMockRecorder is the mock recorder for MockDiagnoser.
+type MockDiagnoserMockRecorder struct {
+	mock *MockDiagnoser
+}
+
+// NewMockDiagnoser creates a new mock instance.
+func NewMockDiagnoser(ctrl *gomock.Controller) *MockDiagnoser {
+	mock := &MockDiagnoser{ctrl: ctrl}
+	mock.recorder = &MockDiagnoserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiagnoser) EXPECT() *MockDiagnoserMockRecorder {
+	return m.recorder
+}
+
+// Diagnose mocks base method.
+func (m *MockDiagnoser) Diagnose(arg0 context.Context, arg1, arg2, arg3 string, arg4 models.WinRMSettings) *models.ConnectionTestReport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diagnose", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.ConnectionTestReport)
+	return ret0
+}
+
+// Diagnose indicates an expected call of Diagnose.
+func (mr *MockDiagnoserMockRecorder) Diagnose(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diagnose", reflect.TypeOf((*MockDiagnoser)(nil).Diagnose), arg0, arg1, arg2, arg3, arg4)
+}
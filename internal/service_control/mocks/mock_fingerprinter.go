@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
 // MockFingerprinter is a mock of Fingerprinter interface.
@@ -36,16 +37,16 @@ func (m *MockFingerprinter) EXPECT() *MockFingerprinterMockRecorder {
 }
 
 // GetFingerprint mocks base method.
-func (m *MockFingerprinter) GetFingerprint(arg0 context.Context, arg1, arg2, arg3 string) (uuid.UUID, error) {
+func (m *MockFingerprinter) GetFingerprint(arg0 context.Context, arg1, arg2, arg3 string, arg4 models.WinRMSettings) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFingerprint", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetFingerprint", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(uuid.UUID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFingerprint indicates an expected call of GetFingerprint.
-func (mr *MockFingerprinterMockRecorder) GetFingerprint(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockFingerprinterMockRecorder) GetFingerprint(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFingerprint", reflect.TypeOf((*MockFingerprinter)(nil).GetFingerprint), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFingerprint", reflect.TypeOf((*MockFingerprinter)(nil).GetFingerprint), arg0, arg1, arg2, arg3, arg4)
 }
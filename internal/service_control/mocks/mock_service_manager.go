@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/trsv-dev/simple-windows-services-monitor/internal/service_control (interfaces: ServiceManager)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	service_control "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+)
+
+// MockServiceManager is a mock of ServiceManager interface.
+type MockServiceManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceManagerMockRecorder
+}
+
+// MockServiceManagerMockRecorder is the mock recorder for MockServiceManager.
+type MockServiceManagerMockRecorder struct {
+	mock *MockServiceManager
+}
+
+// NewMockServiceManager creates a new mock instance.
+func NewMockServiceManager(ctrl *gomock.Controller) *MockServiceManager {
+	mock := &MockServiceManager{ctrl: ctrl}
+	mock.recorder = &MockServiceManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceManager) EXPECT() *MockServiceManagerMockRecorder {
+	return m.recorder
+}
+
+// Config mocks base method.
+func (m *MockServiceManager) Config(arg0 context.Context, arg1 service_control.Client, arg2 string) (*models.WindowsServiceConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Config", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.WindowsServiceConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Config indicates an expected call of Config.
+func (mr *MockServiceManagerMockRecorder) Config(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Config", reflect.TypeOf((*MockServiceManager)(nil).Config), arg0, arg1, arg2)
+}
+
+// Continue mocks base method.
+func (m *MockServiceManager) Continue(arg0 context.Context, arg1 service_control.Client, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Continue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Continue indicates an expected call of Continue.
+func (mr *MockServiceManagerMockRecorder) Continue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Continue", reflect.TypeOf((*MockServiceManager)(nil).Continue), arg0, arg1, arg2)
+}
+
+// Pause mocks base method.
+func (m *MockServiceManager) Pause(arg0 context.Context, arg1 service_control.Client, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockServiceManagerMockRecorder) Pause(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockServiceManager)(nil).Pause), arg0, arg1, arg2)
+}
+
+// Query mocks base method.
+func (m *MockServiceManager) Query(arg0 context.Context, arg1 service_control.Client, arg2 string) (*models.WindowsServiceState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.WindowsServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockServiceManagerMockRecorder) Query(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockServiceManager)(nil).Query), arg0, arg1, arg2)
+}
+
+// QueryStates mocks base method.
+func (m *MockServiceManager) QueryStates(arg0 context.Context, arg1 service_control.Client, arg2 []string) ([]*models.WindowsServiceState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryStates", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.WindowsServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryStates indicates an expected call of QueryStates.
+func (mr *MockServiceManagerMockRecorder) QueryStates(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryStates", reflect.TypeOf((*MockServiceManager)(nil).QueryStates), arg0, arg1, arg2)
+}
+
+// Start mocks base method.
+func (m *MockServiceManager) Start(arg0 context.Context, arg1 service_control.Client, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockServiceManagerMockRecorder) Start(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockServiceManager)(nil).Start), arg0, arg1, arg2)
+}
+
+// Stop mocks base method.
+func (m *MockServiceManager) Stop(arg0 context.Context, arg1 service_control.Client, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockServiceManagerMockRecorder) Stop(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockServiceManager)(nil).Stop), arg0, arg1, arg2)
+}
@@ -0,0 +1,255 @@
+package service_control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
+)
+
+// win32States Соответствие состояния службы в Windows (dwCurrentState, ServiceControllerStatus) состоянию из utils.
+var win32States = map[int]int{
+	1: utils.ServiceStopped,
+	2: utils.ServiceStartPending,
+	3: utils.ServiceStopPending,
+	4: utils.ServiceRunning,
+	5: utils.ServiceContinuePending,
+	6: utils.ServicePausePending,
+	7: utils.ServicePaused,
+}
+
+// win32StartTypes Соответствие типа запуска службы в Windows (dwStartType, ServiceStartMode) типу из models.
+var win32StartTypes = map[int]string{
+	0: models.ServiceStartBoot,
+	1: models.ServiceStartSystem,
+	2: models.ServiceStartAuto,
+	3: models.ServiceStartManual,
+	4: models.ServiceStartDisabled,
+}
+
+// cimReturnCodes Соответствие кодов результата методов Win32_Service (StartService, StopService и т.д.)
+// кодам ошибок Win32, которые возвращает Service Control Manager.
+var cimReturnCodes = map[int]int{
+	1:  50,   // Not Supported
+	2:  5,    // Access Denied
+	3:  1051, // Dependent Services Running
+	4:  1052, // Invalid Service Control
+	5:  1061, // Service Cannot Accept Control
+	6:  1062, // Service Not Active
+	7:  1053, // Service Request Timeout
+	9:  3,    // Path Not Found
+	10: 1056, // Service Already Running
+	11: 1055, // Service Database Locked
+	12: 1075, // Service Dependency Deleted
+	13: 1068, // Service Dependency Failure
+	14: 1058, // Service Disabled
+	15: 1069, // Service Logon Failed
+	16: 1072, // Service Marked For Deletion
+	17: 1054, // Service No Thread
+	18: 1059, // Status Circular Dependency
+	19: 1078, // Status Duplicate Name
+	20: 123,  // Status Invalid Name
+	21: 87,   // Status Invalid Parameter
+	22: 1057, // Status Invalid Service Account
+	23: 1073, // Status Service Exists
+}
+
+// serviceOutput Вывод команд ServiceCommands (набор полей зависит от команды).
+type serviceOutput struct {
+	Exists                  bool     `json:"Exists"`
+	Name                    string   `json:"Name"`
+	DisplayName             string   `json:"DisplayName"`
+	State                   int      `json:"State"`
+	ProcessID               int      `json:"ProcessId"`
+	ExitCode                int      `json:"ExitCode"`
+	ServiceSpecificExitCode int      `json:"ServiceSpecificExitCode"`
+	AcceptStop              bool     `json:"AcceptStop"`
+	AcceptPause             bool     `json:"AcceptPause"`
+	ReturnValue             int      `json:"ReturnValue"`
+	Description             string   `json:"Description"`
+	StartType               int      `json:"StartType"`
+	DelayedAutoStart        bool     `json:"DelayedAutoStart"`
+	StartName               string   `json:"StartName"`
+	PathName                string   `json:"PathName"`
+	DependsOn               []string `json:"DependsOn"`
+}
+
+// CIMServiceManager Управление службами через PowerShell и CIM (Win32_Service).
+// Состояния и результаты команд разбираются из JSON с числовыми кодами, поэтому не зависят
+// от языка системы и формата текстового вывода sc.exe.
+type CIMServiceManager struct{}
+
+// NewCIMServiceManager Конструктор.
+func NewCIMServiceManager() *CIMServiceManager {
+	return &CIMServiceManager{}
+}
+
+// Query Получение текущего состояния службы.
+func (m *CIMServiceManager) Query(ctx context.Context, client Client, serviceName string) (*models.WindowsServiceState, error) {
+	commands, err := NewServiceCommands(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := m.run(ctx, client, serviceName, commands.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	state := win32States[out.State]
+
+	return &models.WindowsServiceState{
+		Name:                    out.Name,
+		DisplayName:             out.DisplayName,
+		State:                   state,
+		Status:                  utils.GetStatusByINT(state),
+		ProcessID:               out.ProcessID,
+		ExitCode:                out.ExitCode,
+		ServiceSpecificExitCode: out.ServiceSpecificExitCode,
+		AcceptStop:              out.AcceptStop,
+		AcceptPause:             out.AcceptPause,
+	}, nil
+}
+
+// QueryStates Получение состояний нескольких служб одним запросом. Службы, которых нет на сервере,
+// в результат не попадают; имя службы возвращается в том регистре, в котором его хранит Windows.
+func (m *CIMServiceManager) QueryStates(ctx context.Context, client Client, serviceNames []string) ([]*models.WindowsServiceState, error) {
+	cmd, err := ServiceStatesCommand(serviceNames)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.RunCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить состояния служб: %w", err)
+	}
+
+	output = strings.TrimSpace(output)
+
+	var outs []serviceOutput
+
+	switch {
+	case output == "":
+		return []*models.WindowsServiceState{}, nil
+	case strings.HasPrefix(output, "{"):
+		// на случай, если PowerShell вернул одиночный объект вместо массива
+		var out serviceOutput
+		if err = json.Unmarshal([]byte(output), &out); err == nil {
+			outs = append(outs, out)
+		}
+	default:
+		err = json.Unmarshal([]byte(output), &outs)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("неожиданный вывод команды получения состояний служб: %w", err)
+	}
+
+	states := make([]*models.WindowsServiceState, 0, len(outs))
+	for _, out := range outs {
+		state := win32States[out.State]
+		states = append(states, &models.WindowsServiceState{
+			Name:   out.Name,
+			State:  state,
+			Status: utils.GetStatusByINT(state),
+		})
+	}
+
+	return states, nil
+}
+
+// Start Запуск службы.
+func (m *CIMServiceManager) Start(ctx context.Context, client Client, serviceName string) error {
+	return m.control(ctx, client, serviceName, func(c *ServiceCommands) string { return c.Start })
+}
+
+// Stop Остановка службы. Если от службы зависят запущенные службы, возвращается ошибка 1051,
+// зависимые службы не останавливаются.
+func (m *CIMServiceManager) Stop(ctx context.Context, client Client, serviceName string) error {
+	return m.control(ctx, client, serviceName, func(c *ServiceCommands) string { return c.Stop })
+}
+
+// Pause Приостановка службы.
+func (m *CIMServiceManager) Pause(ctx context.Context, client Client, serviceName string) error {
+	return m.control(ctx, client, serviceName, func(c *ServiceCommands) string { return c.Pause })
+}
+
+// Continue Возобновление приостановленной службы.
+func (m *CIMServiceManager) Continue(ctx context.Context, client Client, serviceName string) error {
+	return m.control(ctx, client, serviceName, func(c *ServiceCommands) string { return c.Continue })
+}
+
+// Config Получение конфигурации службы.
+func (m *CIMServiceManager) Config(ctx context.Context, client Client, serviceName string) (*models.WindowsServiceConfig, error) {
+	commands, err := NewServiceCommands(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := m.run(ctx, client, serviceName, commands.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	dependsOn := out.DependsOn
+	if dependsOn == nil {
+		dependsOn = []string{}
+	}
+
+	return &models.WindowsServiceConfig{
+		Name:             out.Name,
+		DisplayName:      out.DisplayName,
+		Description:      out.Description,
+		StartType:        win32StartTypes[out.StartType],
+		DelayedAutoStart: out.DelayedAutoStart,
+		Account:          out.StartName,
+		BinaryPath:       out.PathName,
+		DependsOn:        dependsOn,
+	}, nil
+}
+
+// control Выполняет команду управления службой и преобразует код результата метода Win32_Service в ошибку.
+func (m *CIMServiceManager) control(ctx context.Context, client Client, serviceName string, command func(c *ServiceCommands) string) error {
+	commands, err := NewServiceCommands(serviceName)
+	if err != nil {
+		return err
+	}
+
+	out, err := m.run(ctx, client, serviceName, command(commands))
+	if err != nil {
+		return err
+	}
+
+	if out.ReturnValue == 0 {
+		return nil
+	}
+
+	if code, ok := cimReturnCodes[out.ReturnValue]; ok {
+		return errs.NewServiceError(errs.ParseErrorCode(code), code)
+	}
+
+	return fmt.Errorf("команда управления службой `%s` завершилась с кодом %d", serviceName, out.ReturnValue)
+}
+
+// run Выполняет команду для одной службы и разбирает ее вывод.
+func (m *CIMServiceManager) run(ctx context.Context, client Client, serviceName, cmd string) (*serviceOutput, error) {
+	output, err := client.RunCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось выполнить команду для службы `%s`: %w", serviceName, err)
+	}
+
+	var out serviceOutput
+	if err = json.Unmarshal([]byte(strings.TrimSpace(output)), &out); err != nil {
+		return nil, fmt.Errorf("неожиданный вывод команды для службы `%s`: %w", serviceName, err)
+	}
+
+	if !out.Exists {
+		return nil, errs.NewServiceError(errs.ParseErrorCode(errs.CodeServiceDoesNotExist), errs.CodeServiceDoesNotExist)
+	}
+
+	return &out, nil
+}
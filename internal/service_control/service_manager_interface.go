@@ -0,0 +1,22 @@
+package service_control
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+//go:generate mockgen -destination=mocks/mock_service_manager.go -package=mocks . ServiceManager
+
+// ServiceManager Интерфейс управления службами Windows на удаленном сервере.
+// Ошибки самой службы возвращаются как *errs.ServiceError с кодом Win32
+// (например, errs.CodeServiceDoesNotExist, если служба не установлена).
+type ServiceManager interface {
+	Query(ctx context.Context, client Client, serviceName string) (*models.WindowsServiceState, error)
+	QueryStates(ctx context.Context, client Client, serviceNames []string) ([]*models.WindowsServiceState, error)
+	Start(ctx context.Context, client Client, serviceName string) error
+	Stop(ctx context.Context, client Client, serviceName string) error
+	Pause(ctx context.Context, client Client, serviceName string) error
+	Continue(ctx context.Context, client Client, serviceName string) error
+	Config(ctx context.Context, client Client, serviceName string) (*models.WindowsServiceConfig, error)
+}
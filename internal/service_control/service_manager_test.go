@@ -0,0 +1,266 @@
+package service_control_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
+)
+
+// TestServiceManagerQuery Проверяет разбор состояния службы из JSON с числовыми кодами.
+func TestServiceManagerQuery(t *testing.T) {
+	commands, err := service_control.NewServiceCommands("spooler")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		output        string
+		runErr        error
+		expectedState *models.WindowsServiceState
+		expectedCode  int  // код *errs.ServiceError
+		wantErr       bool // ошибка, не связанная со службой
+	}{
+		{
+			name:   "служба работает",
+			output: `{"Exists":true,"Name":"Spooler","DisplayName":"Print Spooler","State":4,"ProcessId":1234,"ExitCode":0,"ServiceSpecificExitCode":0,"AcceptStop":true,"AcceptPause":false}`,
+			expectedState: &models.WindowsServiceState{
+				Name:        "Spooler",
+				DisplayName: "Print Spooler",
+				State:       utils.ServiceRunning,
+				Status:      "Работает",
+				ProcessID:   1234,
+				AcceptStop:  true,
+			},
+		},
+		{
+			name:   "служба остановилась с ошибкой",
+			output: "{\"Exists\":true,\"Name\":\"Spooler\",\"DisplayName\":\"Print Spooler\",\"State\":1,\"ProcessId\":0,\"ExitCode\":1066,\"ServiceSpecificExitCode\":3221225477}\r\n",
+			expectedState: &models.WindowsServiceState{
+				Name:                    "Spooler",
+				DisplayName:             "Print Spooler",
+				State:                   utils.ServiceStopped,
+				Status:                  "Остановлена",
+				ExitCode:                1066,
+				ServiceSpecificExitCode: 3221225477,
+			},
+		},
+		{
+			name:   "неизвестное состояние",
+			output: `{"Exists":true,"Name":"Spooler","State":42}`,
+			expectedState: &models.WindowsServiceState{
+				Name:   "Spooler",
+				State:  utils.Unknown,
+				Status: "Неизвестно",
+			},
+		},
+		{
+			name:         "служба не установлена",
+			output:       `{"Exists":false}`,
+			expectedCode: errs.CodeServiceDoesNotExist,
+		},
+		{
+			name:    "ошибка WinRM",
+			runErr:  errors.New("WinRM connection lost"),
+			wantErr: true,
+		},
+		{
+			name:    "неожиданный вывод",
+			output:  "Get-CimInstance : Access denied",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := serviceControlMocks.NewMockClient(ctrl)
+			mockClient.EXPECT().RunCommand(gomock.Any(), commands.Query).Return(tt.output, tt.runErr)
+
+			state, err := service_control.NewCIMServiceManager().Query(context.Background(), mockClient, "spooler")
+
+			switch {
+			case tt.expectedCode != 0:
+				var serviceErr *errs.ServiceError
+				require.True(t, errors.As(err, &serviceErr))
+				assert.Equal(t, tt.expectedCode, serviceErr.Code)
+			case tt.wantErr:
+				assert.Error(t, err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedState, state)
+			}
+		})
+	}
+}
+
+// TestServiceManagerControl Проверяет преобразование результата методов Win32_Service в коды ошибок Win32.
+func TestServiceManagerControl(t *testing.T) {
+	commands, err := service_control.NewServiceCommands("spooler")
+	require.NoError(t, err)
+
+	manager := service_control.NewCIMServiceManager()
+
+	tests := []struct {
+		name         string
+		run          func(ctx context.Context, client service_control.Client) error
+		cmd          string
+		output       string
+		expectedCode int  // код *errs.ServiceError, 0 — без ошибки службы
+		wantErr      bool // ошибка, не связанная со службой
+	}{
+		{
+			name: "запуск",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Start(ctx, client, "spooler")
+			},
+			cmd:    commands.Start,
+			output: `{"Exists":true,"ReturnValue":0}`,
+		},
+		{
+			name: "служба отключена",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Start(ctx, client, "spooler")
+			},
+			cmd:          commands.Start,
+			output:       `{"Exists":true,"ReturnValue":14}`,
+			expectedCode: 1058,
+		},
+		{
+			name: "остановка при запущенных зависимых службах",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Stop(ctx, client, "spooler")
+			},
+			cmd:          commands.Stop,
+			output:       `{"Exists":true,"ReturnValue":3}`,
+			expectedCode: 1051,
+		},
+		{
+			name: "приостановка",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Pause(ctx, client, "spooler")
+			},
+			cmd:    commands.Pause,
+			output: `{"Exists":true,"ReturnValue":0}`,
+		},
+		{
+			name: "возобновление не запущенной службы",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Continue(ctx, client, "spooler")
+			},
+			cmd:          commands.Continue,
+			output:       `{"Exists":true,"ReturnValue":6}`,
+			expectedCode: 1062,
+		},
+		{
+			name: "служба не установлена",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Stop(ctx, client, "spooler")
+			},
+			cmd:          commands.Stop,
+			output:       `{"Exists":false}`,
+			expectedCode: errs.CodeServiceDoesNotExist,
+		},
+		{
+			name: "неизвестный код результата",
+			run: func(ctx context.Context, client service_control.Client) error {
+				return manager.Start(ctx, client, "spooler")
+			},
+			cmd:     commands.Start,
+			output:  `{"Exists":true,"ReturnValue":8}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := serviceControlMocks.NewMockClient(ctrl)
+			mockClient.EXPECT().RunCommand(gomock.Any(), tt.cmd).Return(tt.output, nil)
+
+			err := tt.run(context.Background(), mockClient)
+
+			var serviceErr *errs.ServiceError
+
+			switch {
+			case tt.expectedCode != 0:
+				require.True(t, errors.As(err, &serviceErr))
+				assert.Equal(t, tt.expectedCode, serviceErr.Code)
+			case tt.wantErr:
+				require.Error(t, err)
+				assert.False(t, errors.As(err, &serviceErr))
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestServiceManagerConfig Проверяет разбор конфигурации службы.
+func TestServiceManagerConfig(t *testing.T) {
+	commands, err := service_control.NewServiceCommands("spooler")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := serviceControlMocks.NewMockClient(ctrl)
+	mockClient.EXPECT().RunCommand(gomock.Any(), commands.Config).
+		Return(`{"Exists":true,"Name":"Spooler","DisplayName":"Print Spooler","Description":"Печать","StartType":2,"DelayedAutoStart":true,"StartName":"LocalSystem","PathName":"C:\\Windows\\System32\\spoolsv.exe","DependsOn":["RPCSS","http"]}`, nil)
+	mockClient.EXPECT().RunCommand(gomock.Any(), commands.Config).
+		Return(`{"Exists":true,"Name":"Spooler","StartType":4,"DependsOn":null}`, nil)
+
+	manager := service_control.NewCIMServiceManager()
+
+	serviceConfig, err := manager.Config(context.Background(), mockClient, "spooler")
+	require.NoError(t, err)
+	assert.Equal(t, &models.WindowsServiceConfig{
+		Name:             "Spooler",
+		DisplayName:      "Print Spooler",
+		Description:      "Печать",
+		StartType:        models.ServiceStartAuto,
+		DelayedAutoStart: true,
+		Account:          "LocalSystem",
+		BinaryPath:       `C:\Windows\System32\spoolsv.exe`,
+		DependsOn:        []string{"RPCSS", "http"},
+	}, serviceConfig)
+
+	// отключенная служба без зависимостей
+	serviceConfig, err = manager.Config(context.Background(), mockClient, "spooler")
+	require.NoError(t, err)
+	assert.Equal(t, models.ServiceStartDisabled, serviceConfig.StartType)
+	assert.Equal(t, []string{}, serviceConfig.DependsOn)
+}
+
+// TestServiceManagerInvalidName Проверяет, что для недопустимого имени команды не выполняются.
+func TestServiceManagerInvalidName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// RunCommand не должен вызываться
+	mockClient := serviceControlMocks.NewMockClient(ctrl)
+	manager := service_control.NewCIMServiceManager()
+
+	var errInvalid *errs.ErrInvalidServiceName
+
+	_, err := manager.Query(context.Background(), mockClient, `spooler" & calc & "`)
+	assert.True(t, errors.As(err, &errInvalid))
+
+	err = manager.Stop(context.Background(), mockClient, "$(calc)")
+	assert.True(t, errors.As(err, &errInvalid))
+
+	_, err = manager.QueryStates(context.Background(), mockClient, []string{"spooler", "a;b"})
+	assert.True(t, errors.As(err, &errInvalid))
+}
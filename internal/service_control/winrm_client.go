@@ -8,18 +8,32 @@ import (
 	"time"
 
 	"github.com/masterzen/winrm"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
+// defaultTimeout Таймаут выполнения команд, если он не задан в конфиге.
+const defaultTimeout = 10 * time.Second
+
 // WinRMClient Структура WinRM клиента.
 type WinRMClient struct {
 	client   *winrm.Client
 	endpoint *winrm.Endpoint
 	user     string
 	password string
+	kerberos *kerberosTransporter // транспорт Kerberos, nil для basic и ntlm
 }
 
-// NewWinRMClient Конструктор, возвращающий новый WinRM клиент с нужными настройками.
-func NewWinRMClient(addr, port, user, password string, useHTTPS, insecureForHTTPS bool) (*WinRMClient, error) {
+// NewWinRMClient Конструктор, возвращающий новый WinRM клиент с настройками подключения из cfg.
+func NewWinRMClient(addr, user, password string, cfg *config.WinRMConfig) (*WinRMClient, error) {
+	port, useHTTPS := cfg.Port, cfg.UseHTTPS
+
+	auth := cfg.Auth
+	if auth == "" {
+		auth = models.WinRMAuthBasic
+	}
+
 	switch {
 	case addr == "":
 		return nil, fmt.Errorf("адрес хоста не может быть пустым")
@@ -27,7 +41,7 @@ func NewWinRMClient(addr, port, user, password string, useHTTPS, insecureForHTTP
 		return nil, fmt.Errorf("порт не может быть пустым")
 	case user == "":
 		return nil, fmt.Errorf("имя пользователя не может быть пустым")
-	case password == "":
+	case password == "" && auth != models.WinRMAuthKerberos:
 		return nil, fmt.Errorf("пароль не может быть пустым")
 	}
 
@@ -40,21 +54,31 @@ func NewWinRMClient(addr, port, user, password string, useHTTPS, insecureForHTTP
 		return nil, fmt.Errorf("неверный порт %q", port)
 	}
 
-	insecure := useHTTPS && insecureForHTTPS
+	insecure := useHTTPS && cfg.InsecureHTTPS
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
 
 	endpoint := &winrm.Endpoint{
 		Host:     addr,
 		Port:     winrmPort,
 		HTTPS:    useHTTPS,
 		Insecure: insecure,
-		Timeout:  10 * time.Second,
+		Timeout:  timeout,
 	}
 
-	newClient, err := winrm.NewClient(endpoint, user, password)
+	params, kerberos, err := clientParameters(auth, user, password, useHTTPS, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("невозможно создать клиент WinRM %s:%d (auth=%s): %w", addr, winrmPort, auth, err)
+	}
+
+	newClient, err := winrm.NewClientWithParameters(endpoint, user, password, params)
 	if err != nil {
 		return nil, fmt.Errorf(
-			"невозможно создать клиент WinRM %s:%d (https=%t): %w",
-			addr, winrmPort, useHTTPS, err,
+			"невозможно создать клиент WinRM %s:%d (https=%t, auth=%s): %w",
+			addr, winrmPort, useHTTPS, auth, err,
 		)
 	}
 
@@ -63,13 +87,64 @@ func NewWinRMClient(addr, port, user, password string, useHTTPS, insecureForHTTP
 		endpoint: endpoint,
 		user:     user,
 		password: password,
+		kerberos: kerberos,
 	}, nil
 }
 
+// clientParameters Возвращает параметры клиента winrm с транспортом, соответствующим механизму аутентификации:
+// basic - транспорт библиотеки по умолчанию, ntlm - NTLM с шифрованием сообщений по HTTP
+// (по HTTPS сообщения защищены TLS), kerberos - SPNEGO по keytab или паролю, только HTTPS.
+// Для kerberos также возвращается транспорт, которому перед каждой командой передается ее контекст.
+func clientParameters(auth, user, password string, useHTTPS bool, cfg *config.WinRMConfig) (*winrm.Parameters, *kerberosTransporter, error) {
+	params := *winrm.DefaultParameters
+
+	switch auth {
+	case models.WinRMAuthBasic:
+	case models.WinRMAuthNTLM:
+		if useHTTPS {
+			params.TransportDecorator = func() winrm.Transporter { return &winrm.ClientNTLM{} }
+			break
+		}
+
+		// проверяем поддержку шифрования заранее, чтобы декоратор не возвращал nil
+		if _, err := winrm.NewEncryption("ntlm"); err != nil {
+			return nil, nil, err
+		}
+		params.TransportDecorator = func() winrm.Transporter {
+			encryption, _ := winrm.NewEncryption("ntlm")
+			return encryption
+		}
+	case models.WinRMAuthKerberos:
+		if !useHTTPS {
+			return nil, nil, fmt.Errorf("аутентификация Kerberos допустима только для HTTPS")
+		}
+
+		transporter, err := newKerberosTransporter(user, password, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		params.TransportDecorator = func() winrm.Transporter { return transporter }
+
+		return &params, transporter, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный механизм аутентификации: %s", auth)
+	}
+
+	return &params, nil, nil
+}
+
 // RunCommand Выполнение команды на удаленном сервере.
 func (c *WinRMClient) RunCommand(ctx context.Context, cmd string) (string, error) {
 	var stdout, stderr bytes.Buffer
+
+	if c.kerberos != nil {
+		c.kerberos.setContext(ctx)
+	}
+
+	start := time.Now()
 	_, err := c.client.RunWithContext(ctx, cmd, &stdout, &stderr)
+	metrics.WinRMCommandDuration.WithLabelValues(metrics.Result(err)).Observe(time.Since(start).Seconds())
+
 	if err != nil {
 		return "", fmt.Errorf("ошибка выполнения команды: %w; stderr: %s", err, stderr.String())
 	}
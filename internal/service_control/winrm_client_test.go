@@ -2,11 +2,16 @@ package service_control_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
@@ -135,3 +140,86 @@ func TestRunCommandMultipleCalls(t *testing.T) {
 	assert.Equal(t, "Output2", result2)
 	assert.Equal(t, "Output3", result3)
 }
+
+// TestNewWinRMClientAuth Проверяет создание клиента с разными механизмами аутентификации.
+func TestNewWinRMClientAuth(t *testing.T) {
+	krb5Conf := filepath.Join(t.TempDir(), "krb5.conf")
+	err := os.WriteFile(krb5Conf, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0o600)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string              // название теста
+		password    string              // пароль пользователя
+		cfg         *config.WinRMConfig // конфиг подключения
+		expectError bool                // ожидается ли ошибка
+	}{
+		{
+			name:     "basic по умолчанию",
+			password: "password",
+			cfg:      &config.WinRMConfig{Port: "5985"},
+		},
+		{
+			name:     "ntlm по HTTP с шифрованием сообщений",
+			password: "password",
+			cfg:      &config.WinRMConfig{Port: "5985", Auth: models.WinRMAuthNTLM},
+		},
+		{
+			name:     "ntlm по HTTPS",
+			password: "password",
+			cfg:      &config.WinRMConfig{Port: "5986", UseHTTPS: true, Auth: models.WinRMAuthNTLM},
+		},
+		{
+			name:     "kerberos по паролю",
+			password: "password",
+			cfg:      &config.WinRMConfig{Port: "5986", UseHTTPS: true, Auth: models.WinRMAuthKerberos, Krb5Config: krb5Conf},
+		},
+		{
+			name:        "kerberos по HTTP запрещен",
+			password:    "password",
+			cfg:         &config.WinRMConfig{Port: "5985", Auth: models.WinRMAuthKerberos, Krb5Config: krb5Conf},
+			expectError: true,
+		},
+		{
+			name:        "kerberos без пароля и keytab",
+			cfg:         &config.WinRMConfig{Port: "5986", UseHTTPS: true, Auth: models.WinRMAuthKerberos, Krb5Config: krb5Conf},
+			expectError: true,
+		},
+		{
+			name:        "kerberos с отсутствующим keytab",
+			cfg:         &config.WinRMConfig{Port: "5986", UseHTTPS: true, Auth: models.WinRMAuthKerberos, Krb5Config: krb5Conf, Krb5Keytab: "/nonexistent/swsm.keytab"},
+			expectError: true,
+		},
+		{
+			name:        "kerberos с отсутствующим krb5.conf",
+			password:    "password",
+			cfg:         &config.WinRMConfig{Port: "5986", UseHTTPS: true, Auth: models.WinRMAuthKerberos, Krb5Config: "/nonexistent/krb5.conf"},
+			expectError: true,
+		},
+		{
+			name:        "basic без пароля",
+			cfg:         &config.WinRMConfig{Port: "5985", Auth: models.WinRMAuthBasic},
+			expectError: true,
+		},
+		{
+			name:        "неизвестный механизм",
+			password:    "password",
+			cfg:         &config.WinRMConfig{Port: "5985", Auth: "digest"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := service_control.NewWinRMClient("host.example.com", "admin", tt.password, tt.cfg)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, client)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, client)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package service_control
 
-import "github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+import (
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
 
 // WinRMClientFactory Структура WinRMClientFactory фабрики.
 type WinRMClientFactory struct {
@@ -15,6 +18,7 @@ func NewWinRMClientFactory(winrmConfig *config.WinRMConfig) *WinRMClientFactory
 }
 
 // CreateClient Фабрика WinRMClient. Создаёт WinRMClient для указанных в сигнатуре параметров.
-func (f *WinRMClientFactory) CreateClient(address, username, password string) (Client, error) {
-	return NewWinRMClient(address, f.winRMConfig.Port, username, password, f.winRMConfig.UseHTTPS, f.winRMConfig.InsecureHTTPS)
+// Параметры подключения, заданные для сервера в settings, заменяют глобальные.
+func (f *WinRMClientFactory) CreateClient(address, username, password string, settings models.WinRMSettings) (Client, error) {
+	return NewWinRMClient(address, username, password, f.winRMConfig.ForServer(settings))
 }
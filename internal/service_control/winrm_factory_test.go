@@ -10,6 +10,7 @@ import (
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
 )
@@ -45,7 +46,7 @@ func TestWinRMClientFactoryImplementsInterface(t *testing.T) {
 func TestCreateClientSuccess(t *testing.T) {
 	factory := service_control.NewWinRMClientFactory(MockWinRMConfig)
 
-	client, err := factory.CreateClient("192.168.1.100", "admin", "password")
+	client, err := factory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	if err == nil {
 		assert.NotNil(t, client)
@@ -57,7 +58,7 @@ func TestCreateClientSuccess(t *testing.T) {
 func TestCreateClientReturnsClientInterface(t *testing.T) {
 	factory := service_control.NewWinRMClientFactory(MockWinRMConfig)
 
-	client, err := factory.CreateClient("192.168.1.100", "admin", "password")
+	client, err := factory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	if err == nil {
 		// проверяем что это реально Client
@@ -85,7 +86,7 @@ func TestCreateClientWithDifferentAddresses(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client, err := factory.CreateClient(tt.address, "admin", "password")
+			client, err := factory.CreateClient(tt.address, "admin", "password", models.WinRMSettings{})
 
 			if err == nil {
 				assert.NotNil(t, client)
@@ -112,7 +113,7 @@ func TestCreateClientWithDifferentCredentials(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client, err := factory.CreateClient("192.168.1.100", tt.username, tt.password)
+			client, err := factory.CreateClient("192.168.1.100", tt.username, tt.password, models.WinRMSettings{})
 
 			if err == nil {
 				assert.NotNil(t, client)
@@ -126,7 +127,7 @@ func TestCreateClientWithDifferentCredentials(t *testing.T) {
 func TestCreateClientEmptyAddress(t *testing.T) {
 	factory := service_control.NewWinRMClientFactory(MockWinRMConfig)
 
-	client, err := factory.CreateClient("", "admin", "password")
+	client, err := factory.CreateClient("", "admin", "password", models.WinRMSettings{})
 
 	if err == nil {
 		assert.NotNil(t, client)
@@ -146,8 +147,8 @@ func TestCreateClientConsistency(t *testing.T) {
 	password := "password"
 
 	// Создаём клиента дважды с одинаковыми параметрами
-	client1, err1 := factory.CreateClient(address, username, password)
-	client2, err2 := factory.CreateClient(address, username, password)
+	client1, err1 := factory.CreateClient(address, username, password, models.WinRMSettings{})
+	client2, err2 := factory.CreateClient(address, username, password, models.WinRMSettings{})
 
 	// Оба должны быть успешны или оба должны быть ошибкой
 	if err1 == nil && err2 == nil {
@@ -169,10 +170,10 @@ func TestMockClientFactorySuccess(t *testing.T) {
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	client, err := mockFactory.CreateClient("192.168.1.100", "admin", "password")
+	client, err := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, client)
@@ -187,10 +188,10 @@ func TestMockClientFactoryError(t *testing.T) {
 	mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
 
 	mockFactory.EXPECT().
-		CreateClient("invalid.address", "admin", "password").
+		CreateClient("invalid.address", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("cannot create client"))
 
-	client, err := mockFactory.CreateClient("invalid.address", "admin", "password")
+	client, err := mockFactory.CreateClient("invalid.address", "admin", "password", models.WinRMSettings{})
 
 	assert.Error(t, err)
 	assert.Nil(t, client)
@@ -206,13 +207,13 @@ func TestMockClientFactoryWithAnyTimes(t *testing.T) {
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 
 	mockFactory.EXPECT().
-		CreateClient(gomock.Any(), gomock.Any(), gomock.Any()).
+		CreateClient(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(mockClient, nil).
 		AnyTimes()
 
 	// Несколько вызовов с разными параметрами
 	for i := 0; i < 5; i++ {
-		client, err := mockFactory.CreateClient("192.168.1.100", "admin", "password")
+		client, err := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, client)
@@ -228,13 +229,13 @@ func TestMockClientFactoryWithTimes(t *testing.T) {
 	mockClient := serviceControlMocks.NewMockClient(ctrl)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil).
 		Times(3)
 
-	client1, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password")
-	client2, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password")
-	client3, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password")
+	client1, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
+	client2, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
+	client3, _ := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
 
 	assert.NotNil(t, client1)
 	assert.NotNil(t, client2)
@@ -251,15 +252,15 @@ func TestMockClientFactoryWithDifferentAddresses(t *testing.T) {
 	mockClient2 := serviceControlMocks.NewMockClient(ctrl)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", gomock.Any(), gomock.Any()).
+		CreateClient("192.168.1.100", gomock.Any(), gomock.Any(), models.WinRMSettings{}).
 		Return(mockClient1, nil)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.101", gomock.Any(), gomock.Any()).
+		CreateClient("192.168.1.101", gomock.Any(), gomock.Any(), models.WinRMSettings{}).
 		Return(mockClient2, nil)
 
-	client1, err1 := mockFactory.CreateClient("192.168.1.100", "admin", "password")
-	client2, err2 := mockFactory.CreateClient("192.168.1.101", "admin", "password")
+	client1, err1 := mockFactory.CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{})
+	client2, err2 := mockFactory.CreateClient("192.168.1.101", "admin", "password", models.WinRMSettings{})
 
 	assert.NoError(t, err1)
 	assert.NoError(t, err2)
@@ -280,10 +281,10 @@ func TestMockClientFactoryWithSpecificCredentials(t *testing.T) {
 	expectedPass := "SecureP@ss123"
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", expectedUser, expectedPass).
+		CreateClient("192.168.1.100", expectedUser, expectedPass, models.WinRMSettings{}).
 		Return(mockClient, nil)
 
-	client, err := mockFactory.CreateClient("192.168.1.100", expectedUser, expectedPass)
+	client, err := mockFactory.CreateClient("192.168.1.100", expectedUser, expectedPass, models.WinRMSettings{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, client)
@@ -294,7 +295,7 @@ func TestCreateClientEmptyPassword(t *testing.T) {
 	factory := service_control.NewWinRMClientFactory(MockWinRMConfig)
 
 	// пустой пароль может быть OK для некоторых конфигураций
-	client, err := factory.CreateClient("192.168.1.100", "admin", "")
+	client, err := factory.CreateClient("192.168.1.100", "admin", "", models.WinRMSettings{})
 
 	// просто проверяем что функция отрабатывает
 	if err == nil {
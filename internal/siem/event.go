@@ -0,0 +1,79 @@
+package siem
+
+import (
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+//go:generate mockgen -destination=mocks/sink_mock.go -package=mocks . Sink
+
+// Типы событий безопасности.
+const (
+	EventTypeAudit       = "audit"
+	EventTypeAuthFailure = "auth_failure"
+)
+
+// Sink Интерфейс получателя событий безопасности.
+// Export не должен блокировать вызывающего: события при необходимости отбрасываются.
+type Sink interface {
+	Export(event *Event)
+}
+
+// Event Событие безопасности для передачи в SIEM.
+type Event struct {
+	Type       string
+	Time       time.Time
+	UserID     string
+	Login      string
+	Action     string
+	ServerID   int64
+	ServiceID  int64
+	Target     string
+	IP         string
+	Path       string
+	Success    bool
+	Error      string
+	DurationMs int64
+}
+
+// NewAuditEvent Создает событие из записи журнала аудита.
+func NewAuditEvent(entry *models.AuditEntry) *Event {
+	return &Event{
+		Type:       EventTypeAudit,
+		Time:       time.Now(),
+		UserID:     entry.UserID,
+		Login:      entry.Login,
+		Action:     entry.Action,
+		ServerID:   entry.ServerID,
+		ServiceID:  entry.ServiceID,
+		Target:     entry.Target,
+		IP:         entry.IP,
+		Success:    entry.Success,
+		Error:      entry.Error,
+		DurationMs: entry.DurationMs,
+	}
+}
+
+// NewAuthFailureEvent Создает событие неудачной аутентификации.
+func NewAuthFailureEvent(ip, path, reason string) *Event {
+	return &Event{
+		Type:   EventTypeAuthFailure,
+		Time:   time.Now(),
+		Action: "authenticate",
+		IP:     ip,
+		Path:   path,
+		Error:  reason,
+	}
+}
+
+// NoopSink Получатель событий для режима без SIEM: события отбрасываются.
+type NoopSink struct{}
+
+// NewNoopSink Конструктор NoopSink.
+func NewNoopSink() *NoopSink {
+	return &NoopSink{}
+}
+
+// Export Ничего не делает.
+func (n *NoopSink) Export(event *Event) {}
@@ -0,0 +1,265 @@
+package siem
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+)
+
+// Транспорты syslog.
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+)
+
+const (
+	defaultBufferSize = 1000
+	writeTimeout      = 5 * time.Second
+	minBackoff        = 500 * time.Millisecond
+	maxBackoff        = 30 * time.Second
+	drainTimeout      = 2 * time.Second
+)
+
+// Config Настройки экспорта событий в syslog.
+type Config struct {
+	Address     string // host:port приемника syslog
+	Network     string // udp (по умолчанию), tcp или tls
+	Format      string // rfc5424 (по умолчанию) или cef
+	TLSCAFile   string // PEM файл с сертификатом CA приемника (для tls), пустой — системные CA
+	TLSInsecure bool   // не проверять сертификат приемника (для tls)
+	BufferSize  int    // размер буфера событий, по умолчанию 1000
+}
+
+// Exporter Передает события безопасности в syslog приемник (SIEM).
+//
+// Export только кладет событие в ограниченный буфер и никогда не блокирует вызывающего:
+// при переполненном буфере (приемник недоступен) событие отбрасывается.
+// Отправка выполняется в Run с переподключением к приемнику при ошибках.
+type Exporter struct {
+	network  string
+	address  string
+	format   formatter
+	dial     func(ctx context.Context) (net.Conn, error)
+	events   chan *Event
+	hostname string
+	appName  string
+	pid      int
+
+	// состояние соединения, используется только горутиной Run
+	conn    net.Conn
+	backoff time.Duration
+}
+
+// NewExporter Конструктор Exporter.
+func NewExporter(cfg Config) (*Exporter, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("не задан адрес syslog приемника")
+	}
+
+	format, err := newFormatter(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	network := cfg.Network
+	if network == "" {
+		network = NetworkUDP
+	}
+
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	hostname, _ := os.Hostname()
+
+	e := &Exporter{
+		network:  network,
+		address:  cfg.Address,
+		format:   format,
+		events:   make(chan *Event, bufferSize),
+		hostname: hostname,
+		appName:  "swsm",
+		pid:      os.Getpid(),
+		backoff:  minBackoff,
+	}
+
+	dialer := &net.Dialer{Timeout: writeTimeout}
+
+	switch network {
+	case NetworkUDP, NetworkTCP:
+		e.dial = func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, cfg.Address)
+		}
+	case NetworkTLS:
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		e.dial = func(ctx context.Context) (net.Conn, error) {
+			return tlsDialer.DialContext(ctx, "tcp", cfg.Address)
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный транспорт syslog: %q, допустимые значения: udp, tcp, tls", network)
+	}
+
+	return e, nil
+}
+
+// newTLSConfig Формирует настройки TLS соединения с приемником.
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("неверный адрес syslog приемника: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: cfg.TLSInsecure,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CA сертификата syslog приемника: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("не удалось загрузить CA сертификат syslog приемника")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// Export Ставит событие в очередь на отправку. Не блокирует: при переполненном буфере событие отбрасывается.
+func (e *Exporter) Export(event *Event) {
+	select {
+	case e.events <- event:
+	default:
+		metrics.SIEMEvents.WithLabelValues("dropped").Inc()
+		logger.Log.Debug("Буфер событий syslog переполнен, событие отброшено",
+			logger.String("type", event.Type), logger.String("action", event.Action))
+	}
+}
+
+// Run Отправляет события из буфера до отмены контекста.
+// После отмены пытается отправить оставшиеся в буфере события в течение drainTimeout.
+func (e *Exporter) Run(ctx context.Context) {
+	logger.Log.Info("Экспорт событий в syslog запущен",
+		logger.String("address", e.address), logger.String("network", e.network))
+
+	defer e.closeConn()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.drain()
+			logger.Log.Info("Экспорт событий в syslog остановлен")
+			return
+		case event := <-e.events:
+			if !e.send(ctx, event) {
+				metrics.SIEMEvents.WithLabelValues("dropped").Inc()
+			}
+		}
+	}
+}
+
+// drain Отправляет оставшиеся в буфере события, ограничивая время отправки drainTimeout.
+func (e *Exporter) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case event := <-e.events:
+			if !e.send(ctx, event) {
+				metrics.SIEMEvents.WithLabelValues("dropped").Add(float64(len(e.events) + 1))
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// send Отправляет событие, при необходимости (пере)подключаясь к приемнику с нарастающей паузой.
+// Возвращает false, если событие не удалось отправить до отмены контекста.
+func (e *Exporter) send(ctx context.Context, event *Event) bool {
+	for {
+		err := e.connect(ctx)
+		if err == nil {
+			err = e.write(e.conn, event)
+			if err == nil {
+				metrics.SIEMEvents.WithLabelValues("sent").Inc()
+				e.backoff = minBackoff
+				return true
+			}
+			e.closeConn()
+		}
+
+		logger.Log.Warn("Ошибка отправки события в syslog, повтор через "+e.backoff.String(),
+			logger.String("address", e.address), logger.String("err", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(e.backoff):
+		}
+
+		e.backoff = min(e.backoff*2, maxBackoff)
+	}
+}
+
+// connect Подключается к приемнику, если соединения еще нет.
+func (e *Exporter) connect(ctx context.Context) error {
+	if e.conn != nil {
+		return nil
+	}
+
+	conn, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.conn = conn
+	return nil
+}
+
+// closeConn Закрывает текущее соединение с приемником.
+func (e *Exporter) closeConn() {
+	if e.conn != nil {
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
+
+// write Записывает событие в соединение. Для TCP и TLS используется
+// octet-counting framing (RFC 6587, RFC 5425), для UDP — одна датаграмма на сообщение.
+func (e *Exporter) write(conn net.Conn, event *Event) error {
+	msg := e.format(event, e.hostname, e.appName, e.pid)
+	if e.network != NetworkUDP {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+
+	_, err := conn.Write([]byte(msg))
+	return err
+}
@@ -0,0 +1,226 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// readFrame Читает одно сообщение с octet-counting framing (RFC 6587).
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	lenStr, err := r.ReadString(' ')
+	require.NoError(t, err)
+
+	n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+	require.NoError(t, err)
+
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+
+	return string(buf)
+}
+
+// startExporter Запускает экспортер и возвращает функцию его остановки.
+func startExporter(t *testing.T, e *Exporter) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	return func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("экспортер не остановился")
+		}
+	}
+}
+
+// TestNewExporter Проверяет валидацию настроек экспортера.
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectError bool
+	}{
+		{"udp по умолчанию", Config{Address: "127.0.0.1:514"}, false},
+		{"tcp и cef", Config{Address: "127.0.0.1:514", Network: NetworkTCP, Format: FormatCEF}, false},
+		{"tls", Config{Address: "siem.example.com:6514", Network: NetworkTLS}, false},
+		{"нет адреса", Config{}, true},
+		{"неизвестный транспорт", Config{Address: "127.0.0.1:514", Network: "http"}, true},
+		{"неизвестный формат", Config{Address: "127.0.0.1:514", Format: "json"}, true},
+		{"tls без порта", Config{Address: "siem.example.com", Network: NetworkTLS}, true},
+		{"tls с отсутствующим CA", Config{Address: "siem.example.com:6514", Network: NetworkTLS, TLSCAFile: "/nonexistent/ca.pem"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExporter(tt.cfg)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, e)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, defaultBufferSize, cap(e.events))
+			}
+		})
+	}
+}
+
+// TestExporter_UDP Проверяет отправку событий датаграммами UDP.
+func TestExporter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	e, err := NewExporter(Config{Address: pc.LocalAddr().String()})
+	require.NoError(t, err)
+
+	stop := startExporter(t, e)
+	defer stop()
+
+	e.Export(createTestEvent())
+
+	require.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<108>1 "), msg)
+	assert.Contains(t, msg, `action="stop"`)
+}
+
+// TestExporter_TCPReconnect Проверяет octet-counting framing и переподключение после обрыва соединения.
+func TestExporter_TCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	e, err := NewExporter(Config{Address: ln.Addr().String(), Network: NetworkTCP, Format: FormatCEF})
+	require.NoError(t, err)
+
+	stop := startExporter(t, e)
+	defer stop()
+
+	// первое соединение: получаем событие и закрываем соединение со стороны приемника
+	e.Export(createTestEvent())
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	msg := readFrame(t, bufio.NewReader(conn))
+	assert.Contains(t, msg, "CEF:0|swsm|")
+	conn.Close()
+
+	// после обрыва события продолжают доставляться через новое соединение
+	delivered := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		delivered <- readFrame(t, bufio.NewReader(conn))
+	}()
+
+	deadline := time.After(10 * time.Second)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-delivered:
+			assert.Contains(t, msg, "CEF:0|swsm|")
+			return
+		case <-ticker.C:
+			// запись в закрытое соединение может пройти успешно до обнаружения обрыва,
+			// поэтому продолжаем отправлять события до получения по новому соединению
+			e.Export(createTestEvent())
+		case <-deadline:
+			t.Fatal("событие не доставлено после переподключения")
+		}
+	}
+}
+
+// TestExporter_TLS Проверяет отправку событий по TLS.
+func TestExporter_TLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	certificates := srv.TLS.Certificates
+	srv.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certificates})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	e, err := NewExporter(Config{Address: ln.Addr().String(), Network: NetworkTLS, TLSInsecure: true})
+	require.NoError(t, err)
+
+	stop := startExporter(t, e)
+	defer stop()
+
+	e.Export(NewAuthFailureEvent("10.0.0.6", "/api/user/servers", "invalid token"))
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	msg := readFrame(t, bufio.NewReader(conn))
+	assert.Contains(t, msg, "AUTHFAIL")
+}
+
+// TestExporter_NonBlocking Проверяет, что недоступность приемника не блокирует Export.
+func TestExporter_NonBlocking(t *testing.T) {
+	// адрес, на котором никто не слушает
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+	ln.Close()
+
+	e, err := NewExporter(Config{Address: address, Network: NetworkTCP, BufferSize: 2})
+	require.NoError(t, err)
+
+	stop := startExporter(t, e)
+
+	dropped := metrics.SIEMEvents.WithLabelValues("dropped")
+	before := testutil.ToFloat64(dropped)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		e.Export(createTestEvent())
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	// в буфер помещается 2 события, одно может находиться в отправке
+	assert.GreaterOrEqual(t, testutil.ToFloat64(dropped)-before, float64(7))
+
+	// остановка не зависает при недоступном приемнике
+	stop()
+}
@@ -0,0 +1,209 @@
+package siem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы сообщений.
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatCEF     = "cef"
+)
+
+// Facility и severity syslog (RFC 5424, раздел 6.2.1).
+const (
+	facilityAuthPriv = 10 // security/authorization messages
+	facilityLogAudit = 13 // log audit
+
+	severityWarning = 4
+	severityNotice  = 5
+)
+
+// sdID Идентификатор структурированных данных RFC 5424.
+// 32473 — номер предприятия, зарезервированный для примеров и документации (RFC 5612).
+const sdID = "swsm@32473"
+
+// formatter Формирует текст сообщения syslog для события.
+type formatter func(event *Event, hostname, appName string, pid int) string
+
+// newFormatter Возвращает форматтер по названию формата.
+func newFormatter(format string) (formatter, error) {
+	switch format {
+	case FormatRFC5424, "":
+		return formatRFC5424, nil
+	case FormatCEF:
+		return formatCEF, nil
+	}
+
+	return nil, fmt.Errorf("неизвестный формат syslog: %q, допустимые значения: %s, %s", format, FormatRFC5424, FormatCEF)
+}
+
+// priority Возвращает значение PRI сообщения syslog.
+func priority(event *Event) int {
+	facility := facilityLogAudit
+	if event.Type == EventTypeAuthFailure {
+		facility = facilityAuthPriv
+	}
+
+	severity := severityNotice
+	if !event.Success {
+		severity = severityWarning
+	}
+
+	return facility*8 + severity
+}
+
+// header Формирует заголовок сообщения RFC 5424 (без структурированных данных).
+func header(event *Event, hostname, appName string, pid int, msgID string) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s",
+		priority(event),
+		event.Time.UTC().Format(time.RFC3339Nano),
+		nilValue(hostname),
+		nilValue(appName),
+		pid,
+		msgID,
+	)
+}
+
+// formatRFC5424 Формирует сообщение RFC 5424 со структурированными данными события.
+func formatRFC5424(event *Event, hostname, appName string, pid int) string {
+	var sd strings.Builder
+	sd.WriteString("[" + sdID)
+
+	param := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sd, ` %s="%s"`, name, escapeSDParam(value))
+		}
+	}
+
+	param("type", event.Type)
+	param("userID", event.UserID)
+	param("login", event.Login)
+	param("action", event.Action)
+	if event.ServerID != 0 {
+		param("serverID", strconv.FormatInt(event.ServerID, 10))
+	}
+	if event.ServiceID != 0 {
+		param("serviceID", strconv.FormatInt(event.ServiceID, 10))
+	}
+	param("target", event.Target)
+	param("ip", event.IP)
+	param("path", event.Path)
+	param("outcome", outcome(event))
+	param("error", event.Error)
+	if event.Type == EventTypeAudit {
+		param("durationMs", strconv.FormatInt(event.DurationMs, 10))
+	}
+
+	sd.WriteString("]")
+
+	return header(event, hostname, appName, pid, msgID(event)) + " " + sd.String() + " " + summary(event)
+}
+
+// formatCEF Формирует сообщение в формате ArcSight CEF с заголовком RFC 5424.
+func formatCEF(event *Event, hostname, appName string, pid int) string {
+	severity := 3
+	if !event.Success {
+		severity = 7
+	}
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+escapeCEFExtension(value))
+		}
+	}
+
+	add("rt", strconv.FormatInt(event.Time.UnixMilli(), 10))
+	add("suid", event.UserID)
+	add("suser", event.Login)
+	add("act", event.Action)
+	add("src", event.IP)
+	add("request", event.Path)
+	add("outcome", outcome(event))
+	add("msg", event.Error)
+	if event.ServerID != 0 {
+		add("cn1Label", "serverID")
+		add("cn1", strconv.FormatInt(event.ServerID, 10))
+	}
+	if event.ServiceID != 0 {
+		add("cn2Label", "serviceID")
+		add("cn2", strconv.FormatInt(event.ServiceID, 10))
+	}
+	if event.Type == EventTypeAudit {
+		add("cn3Label", "durationMs")
+		add("cn3", strconv.FormatInt(event.DurationMs, 10))
+	}
+	if event.Target != "" {
+		add("cs1Label", "target")
+		add("cs1", event.Target)
+	}
+
+	cef := fmt.Sprintf("CEF:0|swsm|Simple Windows Services Monitor|1.0|%s|%s|%d|%s",
+		escapeCEFHeader(event.Type+":"+event.Action),
+		escapeCEFHeader(summary(event)),
+		severity,
+		strings.Join(ext, " "),
+	)
+
+	return header(event, hostname, appName, pid, msgID(event)) + " - " + cef
+}
+
+// msgID Возвращает MSGID сообщения по типу события.
+func msgID(event *Event) string {
+	if event.Type == EventTypeAuthFailure {
+		return "AUTHFAIL"
+	}
+
+	return "AUDIT"
+}
+
+// outcome Возвращает результат действия.
+func outcome(event *Event) string {
+	if event.Success {
+		return "success"
+	}
+
+	return "failure"
+}
+
+// summary Возвращает краткое описание события.
+func summary(event *Event) string {
+	if event.Type == EventTypeAuthFailure {
+		return fmt.Sprintf("authentication failure from %s", nilValue(event.IP))
+	}
+
+	s := fmt.Sprintf("%s %s", nilValue(event.Login), event.Action)
+	if event.Target != "" {
+		s += " " + event.Target
+	}
+
+	return s + ": " + outcome(event)
+}
+
+// nilValue Возвращает NILVALUE RFC 5424 для пустых полей заголовка.
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// escapeSDParam Экранирует значение параметра структурированных данных RFC 5424.
+func escapeSDParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// escapeCEFHeader Экранирует поле заголовка CEF.
+func escapeCEFHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ").Replace(s)
+}
+
+// escapeCEFExtension Экранирует значение расширения CEF.
+func escapeCEFExtension(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,99 @@
+package siem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// createTestEvent Создает тестовое событие аудита.
+func createTestEvent() *Event {
+	event := NewAuditEvent(&models.AuditEntry{
+		UserID:     "user-1",
+		Login:      "tester",
+		Action:     models.ControlActionStop,
+		ServerID:   1,
+		ServiceID:  2,
+		Target:     `Диспетчер "печати" (spooler)`,
+		IP:         "10.0.0.5",
+		Success:    false,
+		Error:      "Сервер недоступен",
+		DurationMs: 120,
+	})
+	event.Time = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return event
+}
+
+// TestNewFormatter Проверяет выбор формата сообщений.
+func TestNewFormatter(t *testing.T) {
+	for _, format := range []string{"", FormatRFC5424, FormatCEF} {
+		f, err := newFormatter(format)
+		assert.NoError(t, err)
+		assert.NotNil(t, f)
+	}
+
+	_, err := newFormatter("json")
+	assert.Error(t, err)
+}
+
+// TestFormatRFC5424 Проверяет формирование сообщений RFC 5424.
+func TestFormatRFC5424(t *testing.T) {
+	t.Run("аудит", func(t *testing.T) {
+		msg := formatRFC5424(createTestEvent(), "host1", "swsm", 42)
+
+		// facility log audit (13), severity warning (4) для неуспешного действия
+		assert.Equal(t, `<108>1 2025-01-02T03:04:05Z host1 swsm 42 AUDIT `+
+			`[swsm@32473 type="audit" userID="user-1" login="tester" action="stop" serverID="1" serviceID="2" `+
+			`target="Диспетчер \"печати\" (spooler)" ip="10.0.0.5" outcome="failure" error="Сервер недоступен" durationMs="120"] `+
+			`tester stop Диспетчер "печати" (spooler): failure`, msg)
+	})
+
+	t.Run("неудачная аутентификация", func(t *testing.T) {
+		event := NewAuthFailureEvent("10.0.0.6", "/api/user/servers", "invalid token")
+		event.Time = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		msg := formatRFC5424(event, "", "swsm", 42)
+
+		// facility authpriv (10), severity warning (4), пустой hostname — NILVALUE
+		assert.Equal(t, `<84>1 2025-01-02T03:04:05Z - swsm 42 AUTHFAIL `+
+			`[swsm@32473 type="auth_failure" action="authenticate" ip="10.0.0.6" path="/api/user/servers" outcome="failure" error="invalid token"] `+
+			`authentication failure from 10.0.0.6`, msg)
+	})
+
+	t.Run("успешное действие", func(t *testing.T) {
+		event := createTestEvent()
+		event.Success = true
+		event.Error = ""
+
+		msg := formatRFC5424(event, "host1", "swsm", 42)
+		require.True(t, len(msg) > 5)
+		// severity notice (5)
+		assert.Equal(t, "<109>", msg[:5])
+		assert.Contains(t, msg, `outcome="success"`)
+		assert.NotContains(t, msg, "error=")
+	})
+}
+
+// TestFormatCEF Проверяет формирование сообщений CEF.
+func TestFormatCEF(t *testing.T) {
+	event := createTestEvent()
+	event.Target = "a=b|c"
+
+	msg := formatCEF(event, "host1", "swsm", 42)
+
+	assert.Equal(t, `<108>1 2025-01-02T03:04:05Z host1 swsm 42 AUDIT - `+
+		`CEF:0|swsm|Simple Windows Services Monitor|1.0|audit:stop|tester stop a=b\|c: failure|7|`+
+		`rt=1735787045000 suid=user-1 suser=tester act=stop src=10.0.0.5 outcome=failure msg=Сервер недоступен `+
+		`cn1Label=serverID cn1=1 cn2Label=serviceID cn2=2 cn3Label=durationMs cn3=120 cs1Label=target cs1=a\=b|c`, msg)
+}
+
+// TestEscape Проверяет экранирование значений.
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\]`, escapeSDParam(`a\b"c]`))
+	assert.Equal(t, `a\\b\|c d`, escapeCEFHeader("a\\b|c\nd"))
+	assert.Equal(t, `a\\b\=c\nd`, escapeCEFExtension("a\\b=c\nd"))
+}
@@ -0,0 +1,47 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/trsv-dev/simple-windows-services-monitor/internal/siem (interfaces: Sink)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	siem "github.com/trsv-dev/simple-windows-services-monitor/internal/siem"
+)
+
+// MockSink is a mock of Sink interface.
+type MockSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockSinkMockRecorder
+}
+
+// MockSinkMockRecorder is the mock recorder for MockSink.
+type MockSinkMockRecorder struct {
+	mock *MockSink
+}
+
+// NewMockSink creates a new mock instance.
+func NewMockSink(ctrl *gomock.Controller) *MockSink {
+	mock := &MockSink{ctrl: ctrl}
+	mock.recorder = &MockSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSink) EXPECT() *MockSinkMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockSink) Export(arg0 *siem.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Export", arg0)
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockSinkMockRecorder) Export(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockSink)(nil).Export), arg0)
+}
@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// APITokenStorage Интерфейс для персональных API-токенов пользователей.
+type APITokenStorage interface {
+	AddAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (*models.APIToken, error)
+	ListAPITokens(ctx context.Context, userID string) ([]*models.APIToken, error)
+	DelAPIToken(ctx context.Context, tokenID int64, userID string) error
+	UseAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error)
+}
@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// AuditStorage Интерфейс для журнала аудита действий пользователей.
+type AuditStorage interface {
+	AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error)
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// ControlApprovalStorage Интерфейс для запросов на подтверждение действий с критичными службами.
+type ControlApprovalStorage interface {
+	AddControlApproval(ctx context.Context, approval models.ControlApproval) (*models.ControlApproval, error)
+	ListControlApprovals(ctx context.Context, userID string, status string) ([]*models.ControlApproval, error)
+	GetControlApproval(ctx context.Context, approvalID int64, userID string) (*models.ControlApproval, error)
+	DecideControlApproval(ctx context.Context, approvalID int64, status string, userID string, login string, comment string) error
+	FinishControlApproval(ctx context.Context, approvalID int64, status string, result string) error
+}
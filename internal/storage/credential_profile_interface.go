@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// CredentialProfileStorage Интерфейс для профилей учетных данных команды.
+type CredentialProfileStorage interface {
+	AddCredentialProfile(ctx context.Context, profile models.CredentialProfile) (*models.CredentialProfile, error)
+	EditCredentialProfile(ctx context.Context, profile *models.CredentialProfile, profileID, teamID int64) (*models.CredentialProfile, error)
+	DelCredentialProfile(ctx context.Context, profileID, teamID int64) error
+	GetCredentialProfile(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error)
+	GetCredentialProfileWithPassword(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error)
+	ListCredentialProfiles(ctx context.Context, teamID int64) ([]*models.CredentialProfile, error)
+	ListCredentialProfileServers(ctx context.Context, profileID, teamID int64) ([]*models.Server, error)
+}
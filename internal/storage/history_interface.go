@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// HistoryStorage Интерфейс для истории статусов и действий пользователей.
+type HistoryStorage interface {
+	ListServerStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServerStatusEvent, error)
+	ListServiceStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServiceStatusEvent, error)
+	ListControlActions(ctx context.Context, userID string, from, to time.Time) ([]*models.ControlAction, error)
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// LocalAuthStorage Интерфейс для пользователей и refresh-токенов встроенной аутентификации.
+type LocalAuthStorage interface {
+	CreateLocalUser(ctx context.Context, user *models.User, passwordHash string) error
+	GetLocalUser(ctx context.Context, login string) (*models.LocalUser, error)
+	SetUserPassword(ctx context.Context, userID string, passwordHash string) error
+	AddRefreshToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error
+	UseRefreshToken(ctx context.Context, tokenHash string) (*models.User, error)
+	DelRefreshToken(ctx context.Context, tokenHash string) error
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
@@ -35,6 +36,108 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// AcceptTeamInvitation mocks base method.
+func (m *MockStorage) AcceptTeamInvitation(arg0 context.Context, arg1 int64, arg2, arg3 string) (*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptTeamInvitation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptTeamInvitation indicates an expected call of AcceptTeamInvitation.
+func (mr *MockStorageMockRecorder) AcceptTeamInvitation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptTeamInvitation", reflect.TypeOf((*MockStorage)(nil).AcceptTeamInvitation), arg0, arg1, arg2, arg3)
+}
+
+// AddAPIToken mocks base method.
+func (m *MockStorage) AddAPIToken(arg0 context.Context, arg1 models.APIToken, arg2 string) (*models.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAPIToken indicates an expected call of AddAPIToken.
+func (mr *MockStorageMockRecorder) AddAPIToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIToken", reflect.TypeOf((*MockStorage)(nil).AddAPIToken), arg0, arg1, arg2)
+}
+
+// AddAuditEntry mocks base method.
+func (m *MockStorage) AddAuditEntry(arg0 context.Context, arg1 *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditEntry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAuditEntry indicates an expected call of AddAuditEntry.
+func (mr *MockStorageMockRecorder) AddAuditEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditEntry", reflect.TypeOf((*MockStorage)(nil).AddAuditEntry), arg0, arg1)
+}
+
+// AddControlApproval mocks base method.
+func (m *MockStorage) AddControlApproval(arg0 context.Context, arg1 models.ControlApproval) (*models.ControlApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddControlApproval", arg0, arg1)
+	ret0, _ := ret[0].(*models.ControlApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddControlApproval indicates an expected call of AddControlApproval.
+func (mr *MockStorageMockRecorder) AddControlApproval(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddControlApproval", reflect.TypeOf((*MockStorage)(nil).AddControlApproval), arg0, arg1)
+}
+
+// AddCredentialProfile mocks base method.
+func (m *MockStorage) AddCredentialProfile(arg0 context.Context, arg1 models.CredentialProfile) (*models.CredentialProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCredentialProfile", arg0, arg1)
+	ret0, _ := ret[0].(*models.CredentialProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCredentialProfile indicates an expected call of AddCredentialProfile.
+func (mr *MockStorageMockRecorder) AddCredentialProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCredentialProfile", reflect.TypeOf((*MockStorage)(nil).AddCredentialProfile), arg0, arg1)
+}
+
+// AddRefreshToken mocks base method.
+func (m *MockStorage) AddRefreshToken(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRefreshToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRefreshToken indicates an expected call of AddRefreshToken.
+func (mr *MockStorageMockRecorder) AddRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRefreshToken", reflect.TypeOf((*MockStorage)(nil).AddRefreshToken), arg0, arg1, arg2, arg3)
+}
+
+// AddSSETicket mocks base method.
+func (m *MockStorage) AddSSETicket(arg0 context.Context, arg1, arg2, arg3 string, arg4 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSSETicket", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSSETicket indicates an expected call of AddSSETicket.
+func (mr *MockStorageMockRecorder) AddSSETicket(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSSETicket", reflect.TypeOf((*MockStorage)(nil).AddSSETicket), arg0, arg1, arg2, arg3, arg4)
+}
+
 // AddServer mocks base method.
 func (m *MockStorage) AddServer(arg0 context.Context, arg1 models.Server, arg2 string) (*models.Server, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +168,35 @@ func (mr *MockStorageMockRecorder) AddService(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddService", reflect.TypeOf((*MockStorage)(nil).AddService), arg0, arg1, arg2, arg3)
 }
 
+// AddTeamInvitation mocks base method.
+func (m *MockStorage) AddTeamInvitation(arg0 context.Context, arg1 models.TeamInvitation, arg2 string) (*models.TeamInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTeamInvitation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.TeamInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTeamInvitation indicates an expected call of AddTeamInvitation.
+func (mr *MockStorageMockRecorder) AddTeamInvitation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTeamInvitation", reflect.TypeOf((*MockStorage)(nil).AddTeamInvitation), arg0, arg1, arg2)
+}
+
+// AddWebhookEvent mocks base method.
+func (m *MockStorage) AddWebhookEvent(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebhookEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWebhookEvent indicates an expected call of AddWebhookEvent.
+func (mr *MockStorageMockRecorder) AddWebhookEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebhookEvent", reflect.TypeOf((*MockStorage)(nil).AddWebhookEvent), arg0, arg1, arg2)
+}
+
 // BatchChangeServiceStatus mocks base method.
 func (m *MockStorage) BatchChangeServiceStatus(arg0 context.Context, arg1 int64, arg2 []*models.Service) error {
 	m.ctrl.T.Helper()
@@ -107,6 +239,35 @@ func (mr *MockStorageMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorage)(nil).Close))
 }
 
+// CreateLocalUser mocks base method.
+func (m *MockStorage) CreateLocalUser(arg0 context.Context, arg1 *models.User, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLocalUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLocalUser indicates an expected call of CreateLocalUser.
+func (mr *MockStorageMockRecorder) CreateLocalUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLocalUser", reflect.TypeOf((*MockStorage)(nil).CreateLocalUser), arg0, arg1, arg2)
+}
+
+// CreateTeam mocks base method.
+func (m *MockStorage) CreateTeam(arg0 context.Context, arg1 models.Team, arg2 string) (*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTeam", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTeam indicates an expected call of CreateTeam.
+func (mr *MockStorageMockRecorder) CreateTeam(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeam", reflect.TypeOf((*MockStorage)(nil).CreateTeam), arg0, arg1, arg2)
+}
+
 // CreateUser mocks base method.
 func (m *MockStorage) CreateUser(arg0 context.Context, arg1 *models.User) error {
 	m.ctrl.T.Helper()
@@ -121,6 +282,76 @@ func (mr *MockStorageMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStorage)(nil).CreateUser), arg0, arg1)
 }
 
+// DecideControlApproval mocks base method.
+func (m *MockStorage) DecideControlApproval(arg0 context.Context, arg1 int64, arg2, arg3, arg4, arg5 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideControlApproval", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideControlApproval indicates an expected call of DecideControlApproval.
+func (mr *MockStorageMockRecorder) DecideControlApproval(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideControlApproval", reflect.TypeOf((*MockStorage)(nil).DecideControlApproval), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// DeclineTeamInvitation mocks base method.
+func (m *MockStorage) DeclineTeamInvitation(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineTeamInvitation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineTeamInvitation indicates an expected call of DeclineTeamInvitation.
+func (mr *MockStorageMockRecorder) DeclineTeamInvitation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineTeamInvitation", reflect.TypeOf((*MockStorage)(nil).DeclineTeamInvitation), arg0, arg1, arg2)
+}
+
+// DelAPIToken mocks base method.
+func (m *MockStorage) DelAPIToken(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelAPIToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelAPIToken indicates an expected call of DelAPIToken.
+func (mr *MockStorageMockRecorder) DelAPIToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelAPIToken", reflect.TypeOf((*MockStorage)(nil).DelAPIToken), arg0, arg1, arg2)
+}
+
+// DelCredentialProfile mocks base method.
+func (m *MockStorage) DelCredentialProfile(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelCredentialProfile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelCredentialProfile indicates an expected call of DelCredentialProfile.
+func (mr *MockStorageMockRecorder) DelCredentialProfile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelCredentialProfile", reflect.TypeOf((*MockStorage)(nil).DelCredentialProfile), arg0, arg1, arg2)
+}
+
+// DelRefreshToken mocks base method.
+func (m *MockStorage) DelRefreshToken(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelRefreshToken indicates an expected call of DelRefreshToken.
+func (mr *MockStorageMockRecorder) DelRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelRefreshToken", reflect.TypeOf((*MockStorage)(nil).DelRefreshToken), arg0, arg1)
+}
+
 // DelServer mocks base method.
 func (m *MockStorage) DelServer(arg0 context.Context, arg1 int64, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -149,6 +380,76 @@ func (mr *MockStorageMockRecorder) DelService(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelService", reflect.TypeOf((*MockStorage)(nil).DelService), arg0, arg1, arg2, arg3)
 }
 
+// DelServicePermission mocks base method.
+func (m *MockStorage) DelServicePermission(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelServicePermission", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelServicePermission indicates an expected call of DelServicePermission.
+func (mr *MockStorageMockRecorder) DelServicePermission(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelServicePermission", reflect.TypeOf((*MockStorage)(nil).DelServicePermission), arg0, arg1, arg2)
+}
+
+// DelTeam mocks base method.
+func (m *MockStorage) DelTeam(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelTeam", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelTeam indicates an expected call of DelTeam.
+func (mr *MockStorageMockRecorder) DelTeam(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelTeam", reflect.TypeOf((*MockStorage)(nil).DelTeam), arg0, arg1)
+}
+
+// DelTeamInvitation mocks base method.
+func (m *MockStorage) DelTeamInvitation(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelTeamInvitation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelTeamInvitation indicates an expected call of DelTeamInvitation.
+func (mr *MockStorageMockRecorder) DelTeamInvitation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelTeamInvitation", reflect.TypeOf((*MockStorage)(nil).DelTeamInvitation), arg0, arg1, arg2)
+}
+
+// DelTeamMember mocks base method.
+func (m *MockStorage) DelTeamMember(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelTeamMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelTeamMember indicates an expected call of DelTeamMember.
+func (mr *MockStorageMockRecorder) DelTeamMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelTeamMember", reflect.TypeOf((*MockStorage)(nil).DelTeamMember), arg0, arg1, arg2)
+}
+
+// DelWebhookEvent mocks base method.
+func (m *MockStorage) DelWebhookEvent(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelWebhookEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelWebhookEvent indicates an expected call of DelWebhookEvent.
+func (mr *MockStorageMockRecorder) DelWebhookEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelWebhookEvent", reflect.TypeOf((*MockStorage)(nil).DelWebhookEvent), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockStorage) DeleteUser(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -163,6 +464,21 @@ func (mr *MockStorageMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStorage)(nil).DeleteUser), arg0, arg1)
 }
 
+// EditCredentialProfile mocks base method.
+func (m *MockStorage) EditCredentialProfile(arg0 context.Context, arg1 *models.CredentialProfile, arg2, arg3 int64) (*models.CredentialProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditCredentialProfile", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.CredentialProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditCredentialProfile indicates an expected call of EditCredentialProfile.
+func (mr *MockStorageMockRecorder) EditCredentialProfile(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditCredentialProfile", reflect.TypeOf((*MockStorage)(nil).EditCredentialProfile), arg0, arg1, arg2, arg3)
+}
+
 // EditServer mocks base method.
 func (m *MockStorage) EditServer(arg0 context.Context, arg1 *models.Server, arg2 int64, arg3 string) (*models.Server, error) {
 	m.ctrl.T.Helper()
@@ -178,6 +494,95 @@ func (mr *MockStorageMockRecorder) EditServer(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditServer", reflect.TypeOf((*MockStorage)(nil).EditServer), arg0, arg1, arg2, arg3)
 }
 
+// FinishControlApproval mocks base method.
+func (m *MockStorage) FinishControlApproval(arg0 context.Context, arg1 int64, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishControlApproval", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishControlApproval indicates an expected call of FinishControlApproval.
+func (mr *MockStorageMockRecorder) FinishControlApproval(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishControlApproval", reflect.TypeOf((*MockStorage)(nil).FinishControlApproval), arg0, arg1, arg2, arg3)
+}
+
+// GetControlApproval mocks base method.
+func (m *MockStorage) GetControlApproval(arg0 context.Context, arg1 int64, arg2 string) (*models.ControlApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetControlApproval", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ControlApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetControlApproval indicates an expected call of GetControlApproval.
+func (mr *MockStorageMockRecorder) GetControlApproval(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetControlApproval", reflect.TypeOf((*MockStorage)(nil).GetControlApproval), arg0, arg1, arg2)
+}
+
+// GetCredentialProfile mocks base method.
+func (m *MockStorage) GetCredentialProfile(arg0 context.Context, arg1, arg2 int64) (*models.CredentialProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCredentialProfile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CredentialProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCredentialProfile indicates an expected call of GetCredentialProfile.
+func (mr *MockStorageMockRecorder) GetCredentialProfile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentialProfile", reflect.TypeOf((*MockStorage)(nil).GetCredentialProfile), arg0, arg1, arg2)
+}
+
+// GetCredentialProfileWithPassword mocks base method.
+func (m *MockStorage) GetCredentialProfileWithPassword(arg0 context.Context, arg1, arg2 int64) (*models.CredentialProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCredentialProfileWithPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CredentialProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCredentialProfileWithPassword indicates an expected call of GetCredentialProfileWithPassword.
+func (mr *MockStorageMockRecorder) GetCredentialProfileWithPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentialProfileWithPassword", reflect.TypeOf((*MockStorage)(nil).GetCredentialProfileWithPassword), arg0, arg1, arg2)
+}
+
+// GetDefaultTeamID mocks base method.
+func (m *MockStorage) GetDefaultTeamID(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultTeamID", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultTeamID indicates an expected call of GetDefaultTeamID.
+func (mr *MockStorageMockRecorder) GetDefaultTeamID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultTeamID", reflect.TypeOf((*MockStorage)(nil).GetDefaultTeamID), arg0, arg1)
+}
+
+// GetLocalUser mocks base method.
+func (m *MockStorage) GetLocalUser(arg0 context.Context, arg1 string) (*models.LocalUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocalUser", arg0, arg1)
+	ret0, _ := ret[0].(*models.LocalUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocalUser indicates an expected call of GetLocalUser.
+func (mr *MockStorageMockRecorder) GetLocalUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocalUser", reflect.TypeOf((*MockStorage)(nil).GetLocalUser), arg0, arg1)
+}
+
 // GetServer mocks base method.
 func (m *MockStorage) GetServer(arg0 context.Context, arg1 int64, arg2 string) (*models.Server, error) {
 	m.ctrl.T.Helper()
@@ -193,6 +598,21 @@ func (mr *MockStorageMockRecorder) GetServer(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServer", reflect.TypeOf((*MockStorage)(nil).GetServer), arg0, arg1, arg2)
 }
 
+// GetServerTeamRole mocks base method.
+func (m *MockStorage) GetServerTeamRole(arg0 context.Context, arg1 int64, arg2 string) (models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerTeamRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerTeamRole indicates an expected call of GetServerTeamRole.
+func (mr *MockStorageMockRecorder) GetServerTeamRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerTeamRole", reflect.TypeOf((*MockStorage)(nil).GetServerTeamRole), arg0, arg1, arg2)
+}
+
 // GetServerWithPassword mocks base method.
 func (m *MockStorage) GetServerWithPassword(arg0 context.Context, arg1 int64, arg2 string) (*models.Server, error) {
 	m.ctrl.T.Helper()
@@ -223,6 +643,36 @@ func (mr *MockStorageMockRecorder) GetService(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockStorage)(nil).GetService), arg0, arg1, arg2, arg3)
 }
 
+// GetTeamRole mocks base method.
+func (m *MockStorage) GetTeamRole(arg0 context.Context, arg1 int64, arg2 string) (models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamRole indicates an expected call of GetTeamRole.
+func (mr *MockStorageMockRecorder) GetTeamRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamRole", reflect.TypeOf((*MockStorage)(nil).GetTeamRole), arg0, arg1, arg2)
+}
+
+// GetUser mocks base method.
+func (m *MockStorage) GetUser(arg0 context.Context, arg1 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStorageMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStorage)(nil).GetUser), arg0, arg1)
+}
+
 // GetUserServiceStatuses mocks base method.
 func (m *MockStorage) GetUserServiceStatuses(arg0 context.Context, arg1 string) ([]*models.ServiceStatus, error) {
 	m.ctrl.T.Helper()
@@ -238,6 +688,127 @@ func (mr *MockStorageMockRecorder) GetUserServiceStatuses(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserServiceStatuses", reflect.TypeOf((*MockStorage)(nil).GetUserServiceStatuses), arg0, arg1)
 }
 
+// ListAPITokens mocks base method.
+func (m *MockStorage) ListAPITokens(arg0 context.Context, arg1 string) ([]*models.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPITokens", arg0, arg1)
+	ret0, _ := ret[0].([]*models.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPITokens indicates an expected call of ListAPITokens.
+func (mr *MockStorageMockRecorder) ListAPITokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokens", reflect.TypeOf((*MockStorage)(nil).ListAPITokens), arg0, arg1)
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockStorage) ListAuditEntries(arg0 context.Context, arg1 *models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", arg0, arg1)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockStorageMockRecorder) ListAuditEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockStorage)(nil).ListAuditEntries), arg0, arg1)
+}
+
+// ListControlActions mocks base method.
+func (m *MockStorage) ListControlActions(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*models.ControlAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListControlActions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.ControlAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListControlActions indicates an expected call of ListControlActions.
+func (mr *MockStorageMockRecorder) ListControlActions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListControlActions", reflect.TypeOf((*MockStorage)(nil).ListControlActions), arg0, arg1, arg2, arg3)
+}
+
+// ListControlApprovals mocks base method.
+func (m *MockStorage) ListControlApprovals(arg0 context.Context, arg1, arg2 string) ([]*models.ControlApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListControlApprovals", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.ControlApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListControlApprovals indicates an expected call of ListControlApprovals.
+func (mr *MockStorageMockRecorder) ListControlApprovals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListControlApprovals", reflect.TypeOf((*MockStorage)(nil).ListControlApprovals), arg0, arg1, arg2)
+}
+
+// ListCredentialProfileServers mocks base method.
+func (m *MockStorage) ListCredentialProfileServers(arg0 context.Context, arg1, arg2 int64) ([]*models.Server, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCredentialProfileServers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Server)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCredentialProfileServers indicates an expected call of ListCredentialProfileServers.
+func (mr *MockStorageMockRecorder) ListCredentialProfileServers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCredentialProfileServers", reflect.TypeOf((*MockStorage)(nil).ListCredentialProfileServers), arg0, arg1, arg2)
+}
+
+// ListCredentialProfiles mocks base method.
+func (m *MockStorage) ListCredentialProfiles(arg0 context.Context, arg1 int64) ([]*models.CredentialProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCredentialProfiles", arg0, arg1)
+	ret0, _ := ret[0].([]*models.CredentialProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCredentialProfiles indicates an expected call of ListCredentialProfiles.
+func (mr *MockStorageMockRecorder) ListCredentialProfiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCredentialProfiles", reflect.TypeOf((*MockStorage)(nil).ListCredentialProfiles), arg0, arg1)
+}
+
+// ListServerServicePermissions mocks base method.
+func (m *MockStorage) ListServerServicePermissions(arg0 context.Context, arg1 int64) ([]*models.ServicePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServerServicePermissions", arg0, arg1)
+	ret0, _ := ret[0].([]*models.ServicePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServerServicePermissions indicates an expected call of ListServerServicePermissions.
+func (mr *MockStorageMockRecorder) ListServerServicePermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServerServicePermissions", reflect.TypeOf((*MockStorage)(nil).ListServerServicePermissions), arg0, arg1)
+}
+
+// ListServerStatusHistory mocks base method.
+func (m *MockStorage) ListServerStatusHistory(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*models.ServerStatusEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServerStatusHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.ServerStatusEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServerStatusHistory indicates an expected call of ListServerStatusHistory.
+func (mr *MockStorageMockRecorder) ListServerStatusHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServerStatusHistory", reflect.TypeOf((*MockStorage)(nil).ListServerStatusHistory), arg0, arg1, arg2, arg3)
+}
+
 // ListServers mocks base method.
 func (m *MockStorage) ListServers(arg0 context.Context, arg1 string) ([]*models.Server, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +824,36 @@ func (mr *MockStorageMockRecorder) ListServers(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServers", reflect.TypeOf((*MockStorage)(nil).ListServers), arg0, arg1)
 }
 
+// ListServicePermissions mocks base method.
+func (m *MockStorage) ListServicePermissions(arg0 context.Context, arg1 int64) ([]*models.ServicePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServicePermissions", arg0, arg1)
+	ret0, _ := ret[0].([]*models.ServicePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServicePermissions indicates an expected call of ListServicePermissions.
+func (mr *MockStorageMockRecorder) ListServicePermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicePermissions", reflect.TypeOf((*MockStorage)(nil).ListServicePermissions), arg0, arg1)
+}
+
+// ListServiceStatusHistory mocks base method.
+func (m *MockStorage) ListServiceStatusHistory(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*models.ServiceStatusEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceStatusHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.ServiceStatusEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceStatusHistory indicates an expected call of ListServiceStatusHistory.
+func (mr *MockStorageMockRecorder) ListServiceStatusHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceStatusHistory", reflect.TypeOf((*MockStorage)(nil).ListServiceStatusHistory), arg0, arg1, arg2, arg3)
+}
+
 // ListServices mocks base method.
 func (m *MockStorage) ListServices(arg0 context.Context, arg1 int64, arg2 string) ([]*models.Service, error) {
 	m.ctrl.T.Helper()
@@ -268,6 +869,81 @@ func (mr *MockStorageMockRecorder) ListServices(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockStorage)(nil).ListServices), arg0, arg1, arg2)
 }
 
+// ListServicesStates mocks base method.
+func (m *MockStorage) ListServicesStates(arg0 context.Context) ([]*models.ServiceState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServicesStates", arg0)
+	ret0, _ := ret[0].([]*models.ServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServicesStates indicates an expected call of ListServicesStates.
+func (mr *MockStorageMockRecorder) ListServicesStates(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicesStates", reflect.TypeOf((*MockStorage)(nil).ListServicesStates), arg0)
+}
+
+// ListTeamInvitations mocks base method.
+func (m *MockStorage) ListTeamInvitations(arg0 context.Context, arg1 int64) ([]*models.TeamInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]*models.TeamInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamInvitations indicates an expected call of ListTeamInvitations.
+func (mr *MockStorageMockRecorder) ListTeamInvitations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamInvitations", reflect.TypeOf((*MockStorage)(nil).ListTeamInvitations), arg0, arg1)
+}
+
+// ListTeamMembers mocks base method.
+func (m *MockStorage) ListTeamMembers(arg0 context.Context, arg1 int64) ([]*models.TeamMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMembers", arg0, arg1)
+	ret0, _ := ret[0].([]*models.TeamMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMembers indicates an expected call of ListTeamMembers.
+func (mr *MockStorageMockRecorder) ListTeamMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembers", reflect.TypeOf((*MockStorage)(nil).ListTeamMembers), arg0, arg1)
+}
+
+// ListTeams mocks base method.
+func (m *MockStorage) ListTeams(arg0 context.Context, arg1 string) ([]*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeams", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeams indicates an expected call of ListTeams.
+func (mr *MockStorageMockRecorder) ListTeams(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockStorage)(nil).ListTeams), arg0, arg1)
+}
+
+// ListUserInvitations mocks base method.
+func (m *MockStorage) ListUserInvitations(arg0 context.Context, arg1 string) ([]*models.TeamInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]*models.TeamInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserInvitations indicates an expected call of ListUserInvitations.
+func (mr *MockStorageMockRecorder) ListUserInvitations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserInvitations", reflect.TypeOf((*MockStorage)(nil).ListUserInvitations), arg0, arg1)
+}
+
 // ListUsers mocks base method.
 func (m *MockStorage) ListUsers(arg0 context.Context) ([]*models.User, error) {
 	m.ctrl.T.Helper()
@@ -297,6 +973,136 @@ func (mr *MockStorageMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorage)(nil).Ping), arg0)
 }
 
+// SetServiceCritical mocks base method.
+func (m *MockStorage) SetServiceCritical(arg0 context.Context, arg1, arg2 int64, arg3 string, arg4 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetServiceCritical", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetServiceCritical indicates an expected call of SetServiceCritical.
+func (mr *MockStorageMockRecorder) SetServiceCritical(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServiceCritical", reflect.TypeOf((*MockStorage)(nil).SetServiceCritical), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SetServicePermission mocks base method.
+func (m *MockStorage) SetServicePermission(arg0 context.Context, arg1 models.ServicePermission) (*models.ServicePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetServicePermission", arg0, arg1)
+	ret0, _ := ret[0].(*models.ServicePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetServicePermission indicates an expected call of SetServicePermission.
+func (mr *MockStorageMockRecorder) SetServicePermission(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServicePermission", reflect.TypeOf((*MockStorage)(nil).SetServicePermission), arg0, arg1)
+}
+
+// SetTeamMemberRole mocks base method.
+func (m *MockStorage) SetTeamMemberRole(arg0 context.Context, arg1 int64, arg2 string, arg3 models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTeamMemberRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTeamMemberRole indicates an expected call of SetTeamMemberRole.
+func (mr *MockStorageMockRecorder) SetTeamMemberRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTeamMemberRole", reflect.TypeOf((*MockStorage)(nil).SetTeamMemberRole), arg0, arg1, arg2, arg3)
+}
+
+// SetUserPassword mocks base method.
+func (m *MockStorage) SetUserPassword(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserPassword indicates an expected call of SetUserPassword.
+func (mr *MockStorageMockRecorder) SetUserPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserPassword", reflect.TypeOf((*MockStorage)(nil).SetUserPassword), arg0, arg1, arg2)
+}
+
+// SetUserRole mocks base method.
+func (m *MockStorage) SetUserRole(arg0 context.Context, arg1 string, arg2 models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockStorageMockRecorder) SetUserRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockStorage)(nil).SetUserRole), arg0, arg1, arg2)
+}
+
+// UpdateUser mocks base method.
+func (m *MockStorage) UpdateUser(arg0 context.Context, arg1 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStorageMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStorage)(nil).UpdateUser), arg0, arg1)
+}
+
+// UseAPIToken mocks base method.
+func (m *MockStorage) UseAPIToken(arg0 context.Context, arg1 string) (*models.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAPIToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAPIToken indicates an expected call of UseAPIToken.
+func (mr *MockStorageMockRecorder) UseAPIToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAPIToken", reflect.TypeOf((*MockStorage)(nil).UseAPIToken), arg0, arg1)
+}
+
+// UseRefreshToken mocks base method.
+func (m *MockStorage) UseRefreshToken(arg0 context.Context, arg1 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRefreshToken indicates an expected call of UseRefreshToken.
+func (mr *MockStorageMockRecorder) UseRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRefreshToken", reflect.TypeOf((*MockStorage)(nil).UseRefreshToken), arg0, arg1)
+}
+
+// UseSSETicket mocks base method.
+func (m *MockStorage) UseSSETicket(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseSSETicket", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseSSETicket indicates an expected call of UseSSETicket.
+func (mr *MockStorageMockRecorder) UseSSETicket(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseSSETicket", reflect.TypeOf((*MockStorage)(nil).UseSSETicket), arg0, arg1, arg2)
+}
+
 // UserExists mocks base method.
 func (m *MockStorage) UserExists(arg0 context.Context, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -35,6 +35,20 @@ func (m *MockWorkerStorage) EXPECT() *MockWorkerStorageMockRecorder {
 	return m.recorder
 }
 
+// AddServerStatusEvent mocks base method.
+func (m *MockWorkerStorage) AddServerStatusEvent(arg0 context.Context, arg1 int64, arg2 models.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddServerStatusEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddServerStatusEvent indicates an expected call of AddServerStatusEvent.
+func (mr *MockWorkerStorageMockRecorder) AddServerStatusEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddServerStatusEvent", reflect.TypeOf((*MockWorkerStorage)(nil).AddServerStatusEvent), arg0, arg1, arg2)
+}
+
 // ListServersAddresses mocks base method.
 func (m *MockWorkerStorage) ListServersAddresses(arg0 context.Context) ([]*models.ServerStatus, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+)
+
+// legacyAESKeyID Версия ключа паролей, сохраненных до появления версий AES-ключей.
+const legacyAESKeyID = 1
+
+// passwordTables Таблицы, хранящие зашифрованные пароли (колонки password и password_key_id).
+var passwordTables = []string{"servers", "credential_profiles"}
+
+// encryptPassword Шифрует пароль текущим AES-ключом. Для пустого пароля возвращает пустую строку без версии ключа.
+func (pg *PgStorage) encryptPassword(password string) (string, *int, error) {
+	if password == "" {
+		return "", nil, nil
+	}
+
+	encrypted, keyID, err := pg.Keys.Encrypt(password)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return encrypted, &keyID, nil
+}
+
+// decryptPassword Расшифровывает пароль AES-ключом версии keyID (без версии — ключом версии 1).
+func (pg *PgStorage) decryptPassword(encrypted string, keyID *int) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+
+	id := legacyAESKeyID
+	if keyID != nil {
+		id = *keyID
+	}
+
+	return pg.Keys.Decrypt(encrypted, id)
+}
+
+// CheckAESKeys Проверяет, что в конфигурации заданы все версии AES-ключей, которыми зашифрованы пароли в БД.
+func (pg *PgStorage) CheckAESKeys(ctx context.Context) error {
+	query := `SELECT COALESCE(password_key_id, 1) FROM servers WHERE password <> ''
+			  UNION
+			  SELECT COALESCE(password_key_id, 1) FROM credential_profiles WHERE password <> ''
+			  ORDER BY 1`
+
+	rows, err := pg.DB.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении версий AES-ключей паролей: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []int
+
+	for rows.Next() {
+		var keyID int
+		if err = rows.Scan(&keyID); err != nil {
+			return fmt.Errorf("ошибка парсинга версий AES-ключей паролей: %w", err)
+		}
+
+		if !pg.Keys.Has(keyID) {
+			missing = append(missing, keyID)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при обработке версий AES-ключей паролей: %w", err)
+	}
+
+	if len(missing) > 0 {
+		versions := make([]string, 0, len(missing))
+		for _, keyID := range missing {
+			versions = append(versions, strconv.Itoa(keyID))
+		}
+
+		return errs.NewErrAESKeyNotFound(missing[0],
+			fmt.Errorf("пароли в БД зашифрованы ключами версий %s, которые не заданы в AES_KEY_ID/AES_PREVIOUS_KEYS", strings.Join(versions, ", ")))
+	}
+
+	return nil
+}
+
+// RotateAESKey Перешифровывает текущим AES-ключом пароли серверов и профилей учетных данных,
+// зашифрованные предыдущими версиями ключа. Пароли обрабатываются пачками по batchSize строк,
+// каждая пачка — в отдельной транзакции. Возвращает количество перешифрованных паролей.
+func (pg *PgStorage) RotateAESKey(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("неверный размер пачки: %d", batchSize)
+	}
+
+	var total int
+
+	for _, table := range passwordTables {
+		for {
+			count, err := pg.rotateAESKeyBatch(ctx, table, batchSize)
+			total += count
+			if err != nil {
+				return total, err
+			}
+
+			if count == 0 {
+				break
+			}
+
+			logger.Log.Info("Пароли перешифрованы текущим AES-ключом",
+				logger.String("table", table), logger.Int("count", count), logger.Int("total", total))
+		}
+	}
+
+	return total, nil
+}
+
+// rotateAESKeyBatch Перешифровывает текущим AES-ключом не более batchSize паролей таблицы table.
+// Возвращает количество перешифрованных паролей (0 — паролей со старыми ключами не осталось).
+func (pg *PgStorage) rotateAESKeyBatch(ctx context.Context, table string, batchSize int) (int, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию перешифрования паролей: %w", err)
+	}
+	defer tx.Rollback()
+
+	// строки блокируются до конца транзакции, чтобы пароль не изменился между чтением и записью
+	selectQuery := `SELECT id, password, password_key_id FROM ` + table + `
+			  WHERE password <> '' AND password_key_id IS DISTINCT FROM $1
+			  ORDER BY id
+			  LIMIT $2
+			  FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, selectQuery, pg.Keys.CurrentID(), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении паролей для перешифрования (%s): %w", table, err)
+	}
+
+	type encryptedPassword struct {
+		id       int64
+		password string
+		keyID    *int
+	}
+
+	var batch []encryptedPassword
+
+	for rows.Next() {
+		var p encryptedPassword
+		if err = rows.Scan(&p.id, &p.password, &p.keyID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ошибка парсинга паролей для перешифрования (%s): %w", table, err)
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка при обработке паролей для перешифрования (%s): %w", table, err)
+	}
+
+	updateQuery := `UPDATE ` + table + ` SET password = $1, password_key_id = $2 WHERE id = $3`
+
+	for _, p := range batch {
+		decrypted, err := pg.decryptPassword(p.password, p.keyID)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось расшифровать пароль (%s, id=%d): %w", table, p.id, err)
+		}
+
+		encrypted, keyID, err := pg.encryptPassword(decrypted)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось зашифровать пароль (%s, id=%d): %w", table, p.id, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, updateQuery, encrypted, keyID, p.id); err != nil {
+			return 0, fmt.Errorf("ошибка при сохранении перешифрованного пароля (%s, id=%d): %w", table, p.id, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции перешифрования паролей: %w", err)
+	}
+
+	return len(batch), nil
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/postgres/utils"
+)
+
+// testKeyRing Набор из одного AES-ключа версии 1 для тестов.
+func testKeyRing(t *testing.T, key []byte) *utils.KeyRing {
+	t.Helper()
+
+	ring, err := utils.NewKeyRing(1, map[int][]byte{1: key})
+	require.NoError(t, err)
+
+	return ring
+}
+
+// TestCheckAESKeys Проверяет обнаружение версий AES-ключей, которых нет в конфигурации.
+func TestCheckAESKeys(t *testing.T) {
+	query := `SELECT COALESCE(password_key_id, 1) FROM servers WHERE password <> ''
+			  UNION
+			  SELECT COALESCE(password_key_id, 1) FROM credential_profiles WHERE password <> ''
+			  ORDER BY 1`
+
+	ring, err := utils.NewKeyRing(2, map[int][]byte{
+		1: []byte("12345678901234567890123456789012"),
+		2: []byte("abcdefghijklmnopqrstuvwxyz123456"),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		versions    []int
+		missingKeys int // версия первого отсутствующего ключа (0 - все ключи заданы)
+	}{
+		{name: "все версии заданы", versions: []int{1, 2}},
+		{name: "в БД нет паролей"},
+		{name: "версия ключа не задана", versions: []int{1, 3, 4}, missingKeys: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			rows := sqlmock.NewRows([]string{"key_id"})
+			for _, v := range tt.versions {
+				rows.AddRow(v)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
+
+			pg := &PgStorage{DB: db, Keys: ring}
+			err = pg.CheckAESKeys(context.Background())
+
+			if tt.missingKeys == 0 {
+				assert.NoError(t, err)
+			} else {
+				var errKey *errs.ErrAESKeyNotFound
+				require.True(t, errors.As(err, &errKey))
+				assert.Equal(t, tt.missingKeys, errKey.KeyID)
+				assert.Contains(t, err.Error(), "3, 4")
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestRotateAESKey Проверяет перешифрование паролей текущим AES-ключом пачками.
+func TestRotateAESKey(t *testing.T) {
+	oldKey := []byte("12345678901234567890123456789012")
+	newKey := []byte("abcdefghijklmnopqrstuvwxyz123456")
+
+	ring, err := utils.NewKeyRing(2, map[int][]byte{1: oldKey, 2: newKey})
+	require.NoError(t, err)
+
+	first, err := utils.EncryptAES([]byte("first"), oldKey)
+	require.NoError(t, err)
+	second, err := utils.EncryptAES([]byte("second"), oldKey)
+	require.NoError(t, err)
+
+	selectQuery := func(table string) string {
+		return `SELECT id, password, password_key_id FROM ` + table + `
+			  WHERE password <> '' AND password_key_id IS DISTINCT FROM $1
+			  ORDER BY id
+			  LIMIT $2
+			  FOR UPDATE`
+	}
+	updateQuery := func(table string) string {
+		return `UPDATE ` + table + ` SET password = $1, password_key_id = $2 WHERE id = $3`
+	}
+	columns := []string{"id", "password", "password_key_id"}
+
+	t.Run("успешное перешифрование", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// первая пачка серверов: пароль без версии ключа (до миграции) и пароль с версией 1
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery("servers"))).
+			WithArgs(2, 2).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(1), first, nil).AddRow(int64(2), second, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery("servers"))).
+			WithArgs(sqlmock.AnyArg(), 2, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery("servers"))).
+			WithArgs(sqlmock.AnyArg(), 2, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// серверов со старыми ключами не осталось
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery("servers"))).
+			WithArgs(2, 2).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectCommit()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery("credential_profiles"))).
+			WithArgs(2, 2).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(5), first, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery("credential_profiles"))).
+			WithArgs(sqlmock.AnyArg(), 2, int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery("credential_profiles"))).
+			WithArgs(2, 2).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectCommit()
+
+		pg := &PgStorage{DB: db, Keys: ring}
+		count, err := pg.RotateAESKey(context.Background(), 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пароль зашифрован неизвестным ключом", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery("servers"))).
+			WithArgs(2, 10).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(1), first, 7))
+		mock.ExpectRollback()
+
+		pg := &PgStorage{DB: db, Keys: ring}
+		count, err := pg.RotateAESKey(context.Background(), 10)
+
+		var errKey *errs.ErrAESKeyNotFound
+		require.True(t, errors.As(err, &errKey))
+		assert.Equal(t, 7, errKey.KeyID)
+		assert.Zero(t, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// AddAPIToken Создание API-токена пользователя (хранится только хэш токена).
+func (pg *PgStorage) AddAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (*models.APIToken, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Log.Error("Ошибка транзакции при создании API-токена", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("не удалось начать транзакцию создания API-токена: %w", err)
+	}
+	defer tx.Rollback()
+
+	// ограничение токена хранится явно: строки api_token_servers удаляются вместе с серверами
+	token.Restricted = len(token.ServerIDs) > 0
+
+	query := `INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scope, restricted, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, query, token.UserID, token.Name, tokenHash, token.Prefix, token.Scope, token.Restricted, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.NewErrDuplicatedAPIToken(token.Name, err)
+		}
+		return nil, fmt.Errorf("ошибка при создании API-токена: %w", err)
+	}
+
+	queryServer := `INSERT INTO api_token_servers (token_id, server_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	for _, serverID := range token.ServerIDs {
+		if _, err = tx.ExecContext(ctx, queryServer, token.ID, serverID); err != nil {
+			return nil, fmt.Errorf("ошибка при ограничении API-токена сервером id=%d: %w", serverID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error("Ошибка при коммите транзакции создания API-токена", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при коммите транзакции создания API-токена: %w", err)
+	}
+
+	return &token, nil
+}
+
+// ListAPITokens Получение API-токенов пользователя (включая истекшие), новые — первыми.
+func (pg *PgStorage) ListAPITokens(ctx context.Context, userID string) ([]*models.APIToken, error) {
+	query := `SELECT id, name, token_prefix, scope, restricted, created_at, expires_at, last_used_at
+			  FROM api_tokens
+			  WHERE user_id = $1
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении API-токенов пользователя", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении API-токенов пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*models.APIToken
+	byID := make(map[int64]*models.APIToken)
+
+	for rows.Next() {
+		var (
+			token      models.APIToken
+			lastUsedAt sql.NullTime
+		)
+
+		err = rows.Scan(&token.ID, &token.Name, &token.Prefix, &token.Scope, &token.Restricted, &token.CreatedAt, &token.ExpiresAt, &lastUsedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования API-токена", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		if lastUsedAt.Valid {
+			token.LastUsedAt = &lastUsedAt.Time
+		}
+		token.UserID = userID
+		token.ServerIDs = []int64{}
+
+		tokens = append(tokens, &token)
+		byID[token.ID] = &token
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк API-токенов", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return tokens, nil
+	}
+
+	queryServers := `SELECT ts.token_id, ts.server_id
+					 FROM api_token_servers ts
+					 JOIN api_tokens t ON t.id = ts.token_id
+					 WHERE t.user_id = $1
+					 ORDER BY ts.token_id, ts.server_id`
+
+	serverRows, err := pg.DB.QueryContext(ctx, queryServers, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении серверов API-токенов", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении серверов API-токенов: %w", err)
+	}
+	defer serverRows.Close()
+
+	for serverRows.Next() {
+		var tokenID, serverID int64
+
+		if err = serverRows.Scan(&tokenID, &serverID); err != nil {
+			logger.Log.Error("Ошибка сканирования сервера API-токена", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		if token, ok := byID[tokenID]; ok {
+			token.ServerIDs = append(token.ServerIDs, serverID)
+		}
+	}
+
+	if err = serverRows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк серверов API-токенов", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DelAPIToken Отзыв (удаление) API-токена пользователя.
+func (pg *PgStorage) DelAPIToken(ctx context.Context, tokenID int64, userID string) error {
+	query := `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`
+
+	result, err := pg.DB.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrAPITokenNotFound(tokenID, fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
+// UseAPIToken Поиск действующего API-токена по хэшу с отметкой времени последнего использования.
+// Вместе с токеном возвращается роль его владельца при последнем интерактивном входе.
+// Истекший или отозванный токен не найден.
+func (pg *PgStorage) UseAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	query := `UPDATE api_tokens t SET last_used_at = CURRENT_TIMESTAMP
+			  FROM users u
+			  WHERE t.token_hash = $1 AND t.expires_at > CURRENT_TIMESTAMP AND u.id = t.user_id
+			  RETURNING t.id, t.user_id, u.login, COALESCE(u.role, ''), t.name, t.token_prefix, t.scope, t.restricted,
+			  t.created_at, t.expires_at, t.last_used_at`
+
+	var (
+		token      models.APIToken
+		lastUsedAt sql.NullTime
+	)
+
+	err := pg.DB.QueryRowContext(ctx, query, tokenHash).
+		Scan(&token.ID, &token.UserID, &token.Login, &token.OwnerRole, &token.Name, &token.Prefix, &token.Scope,
+			&token.Restricted, &token.CreatedAt, &token.ExpiresAt, &lastUsedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrAPITokenNotFound(0, err)
+		}
+		return nil, fmt.Errorf("ошибка при проверке API-токена: %w", err)
+	}
+
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+
+	queryServers := `SELECT server_id FROM api_token_servers WHERE token_id = $1 ORDER BY server_id`
+
+	rows, err := pg.DB.QueryContext(ctx, queryServers, token.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении серверов API-токена: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serverID int64
+
+		if err = rows.Scan(&serverID); err != nil {
+			return nil, err
+		}
+
+		token.ServerIDs = append(token.ServerIDs, serverID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// AddAuditEntry Добавление записи в журнал аудита.
+func (pg *PgStorage) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	query := `INSERT INTO audit_log (user_id, login, action, server_id, service_id, target, ip, success, error, duration_ms, api_token_id)
+			  VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, 0), $6, $7, $8, $9, $10, NULLIF($11, 0))`
+
+	_, err := pg.DB.ExecContext(ctx, query, entry.UserID, entry.Login, entry.Action, entry.ServerID, entry.ServiceID,
+		entry.Target, entry.IP, entry.Success, entry.Error, entry.DurationMs, entry.APITokenID)
+	if err != nil {
+		logger.Log.Error("Ошибка при записи в журнал аудита", logger.String("action", entry.Action), logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка записи в журнал аудита: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEntries Возвращает страницу журнала аудита по фильтру (новые записи первыми)
+// и общее количество записей, подходящих под фильтр.
+// Пустой filter.UserID — записи всех пользователей.
+func (pg *PgStorage) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	where, args := auditFilterConditions(filter)
+
+	countQuery := `SELECT COUNT(*) FROM audit_log` + where
+
+	var total int
+	err := pg.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		logger.Log.Error("Ошибка при подсчете записей журнала аудита", logger.String("err", err.Error()))
+		return nil, 0, fmt.Errorf("ошибка при получении журнала аудита: %w", err)
+	}
+
+	query := `SELECT id, user_id, login, action, COALESCE(server_id, 0), COALESCE(service_id, 0),
+				target, ip, success, error, duration_ms, COALESCE(api_token_id, 0), created_at
+			  FROM audit_log` + where +
+		fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := pg.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении журнала аудита", logger.String("err", err.Error()))
+		return nil, 0, fmt.Errorf("ошибка при получении журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditEntry, 0)
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		err = rows.Scan(&entry.ID, &entry.UserID, &entry.Login, &entry.Action, &entry.ServerID, &entry.ServiceID,
+			&entry.Target, &entry.IP, &entry.Success, &entry.Error, &entry.DurationMs, &entry.APITokenID, &entry.CreatedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки журнала аудита", logger.String("err", err.Error()))
+			return nil, 0, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк журнала аудита", logger.String("err", err.Error()))
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// auditFilterConditions Формирует условие WHERE и аргументы запроса по фильтру журнала аудита.
+func auditFilterConditions(filter *models.AuditFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != "" {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.ServerID != 0 {
+		add("server_id = $%d", filter.ServerID)
+	}
+	if filter.ServiceID != 0 {
+		add("service_id = $%d", filter.ServiceID)
+	}
+	if filter.Success != nil {
+		add("success = $%d", *filter.Success)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
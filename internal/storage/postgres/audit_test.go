@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestAddAuditEntry Проверяет запись в журнал аудита.
+func TestAddAuditEntry(t *testing.T) {
+	query := `INSERT INTO audit_log (user_id, login, action, server_id, service_id, target, ip, success, error, duration_ms, api_token_id)
+			  VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, 0), $6, $7, $8, $9, $10, NULLIF($11, 0))`
+
+	entry := &models.AuditEntry{
+		UserID:     "any-id-user-1",
+		Login:      "testuser",
+		Action:     models.ControlActionStop,
+		ServerID:   1,
+		ServiceID:  2,
+		Target:     "Диспетчер печати (spooler)",
+		IP:         "10.0.0.5",
+		Success:    false,
+		Error:      "Сервер недоступен",
+		DurationMs: 120,
+		APITokenID: 5,
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+	}{
+		{
+			name: "успешная запись",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", "testuser", "stop", int64(1), int64(2), "Диспетчер печати (spooler)",
+						"10.0.0.5", false, "Сервер недоступен", int64(120), int64(5)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			err = pg.AddAuditEntry(context.Background(), entry)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "ошибка записи в журнал аудита")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListAuditEntries Проверяет получение страницы журнала аудита по фильтру.
+func TestListAuditEntries(t *testing.T) {
+	selectQuery := `SELECT id, user_id, login, action, COALESCE(server_id, 0), COALESCE(service_id, 0),
+				target, ip, success, error, duration_ms, COALESCE(api_token_id, 0), created_at
+			  FROM audit_log`
+
+	columns := []string{"id", "user_id", "login", "action", "server_id", "service_id",
+		"target", "ip", "success", "error", "duration_ms", "api_token_id", "created_at"}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	success := false
+
+	tests := []struct {
+		name        string
+		filter      *models.AuditFilter
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+		expectTotal int
+		expectLen   int
+	}{
+		{
+			name:   "без фильтров - все пользователи",
+			filter: &models.AuditFilter{Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM audit_log`)).
+					WithArgs().
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery+` ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`)).
+					WithArgs(50, 0).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(2), "user-1", "tester", "stop", int64(1), int64(2), "spooler", "10.0.0.5", false, "err", int64(10), int64(0), to).
+						AddRow(int64(1), "user-2", "other", "add_server", int64(3), int64(0), "srv", "10.0.0.6", true, "", int64(5), int64(0), from))
+			},
+			expectTotal: 2,
+			expectLen:   2,
+		},
+		{
+			name: "все фильтры",
+			filter: &models.AuditFilter{
+				UserID: "user-1", Action: "stop", ServerID: 1, ServiceID: 2, Success: &success,
+				From: from, To: to, Limit: 10, Offset: 20,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				where := ` WHERE user_id = $1 AND action = $2 AND server_id = $3 AND service_id = $4 AND success = $5 AND created_at >= $6 AND created_at < $7`
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM audit_log`+where)).
+					WithArgs("user-1", "stop", int64(1), int64(2), false, from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery+where+` ORDER BY created_at DESC, id DESC LIMIT $8 OFFSET $9`)).
+					WithArgs("user-1", "stop", int64(1), int64(2), false, from, to, 10, 20).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(2), "user-1", "tester", "stop", int64(1), int64(2), "spooler", "10.0.0.5", false, "err", int64(10), int64(0), to))
+			},
+			expectTotal: 21,
+			expectLen:   1,
+		},
+		{
+			name:   "ошибка подсчета",
+			filter: &models.AuditFilter{UserID: "user-1", Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM audit_log WHERE user_id = $1`)).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+		},
+		{
+			name:   "ошибка выборки",
+			filter: &models.AuditFilter{UserID: "user-1", Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM audit_log WHERE user_id = $1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			entries, total, err := pg.ListAuditEntries(context.Background(), tt.filter)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, entries)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectTotal, total)
+				assert.Len(t, entries, tt.expectLen)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
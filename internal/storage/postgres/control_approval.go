@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// controlApprovalListLimit Максимальное количество запросов на подтверждение в одном ответе.
+const controlApprovalListLimit = 200
+
+// selectControlApprovals Выборка запросов на подтверждение из команд пользователя ($1).
+// Ожидающий запрос с истекшим сроком возвращается со статусом expired.
+const selectControlApprovals = `SELECT * FROM (
+	SELECT a.id, s.team_id, a.server_id, a.service_id, sv.displayed_name, a.action,
+		CASE WHEN a.status = 'pending' AND a.expires_at <= CURRENT_TIMESTAMP THEN 'expired' ELSE a.status END AS status,
+		COALESCE(a.requested_by, ''), a.requested_by_login, a.request_comment,
+		COALESCE(a.decided_by, ''), a.decided_by_login, a.decision_comment, a.result,
+		a.created_at, a.expires_at, a.decided_at
+	FROM control_approvals a
+	JOIN servers s ON s.id = a.server_id
+	JOIN services sv ON sv.id = a.service_id
+	WHERE s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
+) approvals`
+
+// AddControlApproval Создание запроса на подтверждение действия со службой.
+// Истекший запрос на то же действие закрывается, действующий — не перезаписывается.
+func (pg *PgStorage) AddControlApproval(ctx context.Context, approval models.ControlApproval) (*models.ControlApproval, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Log.Error("Ошибка транзакции при создании запроса на подтверждение", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("не удалось начать транзакцию создания запроса на подтверждение: %w", err)
+	}
+	defer tx.Rollback()
+
+	queryExpire := `UPDATE control_approvals SET status = 'expired'
+					WHERE service_id = $1 AND action = $2 AND status = 'pending' AND expires_at <= CURRENT_TIMESTAMP`
+
+	if _, err = tx.ExecContext(ctx, queryExpire, approval.ServiceID, approval.Action); err != nil {
+		return nil, fmt.Errorf("ошибка при закрытии истекших запросов на подтверждение: %w", err)
+	}
+
+	query := `INSERT INTO control_approvals (server_id, service_id, action, requested_by, requested_by_login, request_comment, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, status, created_at`
+
+	err = tx.QueryRowContext(ctx, query, approval.ServerID, approval.ServiceID, approval.Action,
+		approval.RequestedBy, approval.RequestedLogin, approval.RequestComment, approval.ExpiresAt).
+		Scan(&approval.ID, &approval.Status, &approval.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.NewErrDuplicatedApproval(approval.ServiceID, approval.Action, err)
+		}
+		return nil, fmt.Errorf("ошибка при создании запроса на подтверждение: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error("Ошибка при коммите транзакции создания запроса на подтверждение", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при коммите транзакции создания запроса на подтверждение: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// ListControlApprovals Получение запросов на подтверждение из команд пользователя, новые — первыми.
+// Пустой статус — запросы в любом статусе.
+func (pg *PgStorage) ListControlApprovals(ctx context.Context, userID string, status string) ([]*models.ControlApproval, error) {
+	query := selectControlApprovals + `
+			  WHERE $2 = '' OR status = $2
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $3`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID, status, controlApprovalListLimit)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении запросов на подтверждение", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении запросов на подтверждение: %w", err)
+	}
+	defer rows.Close()
+
+	var approvals []*models.ControlApproval
+
+	for rows.Next() {
+		approval, err := scanControlApproval(rows)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования запроса на подтверждение", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		approvals = append(approvals, approval)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк запросов на подтверждение", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// GetControlApproval Получение запроса на подтверждение из команды, в которой состоит пользователь.
+func (pg *PgStorage) GetControlApproval(ctx context.Context, approvalID int64, userID string) (*models.ControlApproval, error) {
+	query := selectControlApprovals + ` WHERE id = $2`
+
+	approval, err := scanControlApproval(pg.DB.QueryRowContext(ctx, query, userID, approvalID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrApprovalNotFound(approvalID, userID, err)
+		}
+		return nil, fmt.Errorf("ошибка при получении запроса на подтверждение: %w", err)
+	}
+
+	return approval, nil
+}
+
+// DecideControlApproval Подтверждение (approved) или отклонение (rejected) ожидающего запроса пользователем userID.
+// Подтвердить запрос может только не его автор; запрос с истекшим сроком не рассматривается.
+func (pg *PgStorage) DecideControlApproval(ctx context.Context, approvalID int64, status string, userID string, login string, comment string) error {
+	query := `UPDATE control_approvals
+			  SET status = $2, decided_by = $3, decided_by_login = $4, decision_comment = $5, decided_at = CURRENT_TIMESTAMP
+			  WHERE id = $1
+				AND status = 'pending'
+				AND expires_at > CURRENT_TIMESTAMP
+				AND ($2 <> 'approved' OR requested_by IS DISTINCT FROM $3)`
+
+	result, err := pg.DB.ExecContext(ctx, query, approvalID, status, userID, login, comment)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrApprovalNotPending(approvalID)
+	}
+
+	return nil
+}
+
+// FinishControlApproval Сохранение результата выполнения подтвержденного действия (executed или failed).
+func (pg *PgStorage) FinishControlApproval(ctx context.Context, approvalID int64, status string, result string) error {
+	query := `UPDATE control_approvals SET status = $2, result = $3 WHERE id = $1 AND status = 'approved'`
+
+	_, err := pg.DB.ExecContext(ctx, query, approvalID, status, result)
+	if err != nil {
+		logger.Log.Error("Ошибка при сохранении результата запроса на подтверждение", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при сохранении результата запроса на подтверждение: %w", err)
+	}
+
+	return nil
+}
+
+// scanControlApproval Сканирует строку выборки selectControlApprovals.
+func scanControlApproval(row interface{ Scan(dest ...any) error }) (*models.ControlApproval, error) {
+	var (
+		a         models.ControlApproval
+		decidedAt sql.NullTime
+	)
+
+	err := row.Scan(&a.ID, &a.TeamID, &a.ServerID, &a.ServiceID, &a.DisplayedName, &a.Action, &a.Status,
+		&a.RequestedBy, &a.RequestedLogin, &a.RequestComment,
+		&a.DecidedBy, &a.DecidedLogin, &a.DecisionComment, &a.Result,
+		&a.CreatedAt, &a.ExpiresAt, &decidedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if decidedAt.Valid {
+		a.DecidedAt = &decidedAt.Time
+	}
+
+	return &a, nil
+}
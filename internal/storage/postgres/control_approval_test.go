@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// approvalColumns Колонки выборки запросов на подтверждение.
+var approvalColumns = []string{"id", "team_id", "server_id", "service_id", "displayed_name", "action", "status",
+	"requested_by", "requested_by_login", "request_comment", "decided_by", "decided_by_login", "decision_comment",
+	"result", "created_at", "expires_at", "decided_at"}
+
+// TestAddControlApproval Проверяет создание запроса на подтверждение.
+func TestAddControlApproval(t *testing.T) {
+	fixedTime := time.Now()
+	approval := models.ControlApproval{
+		ServerID:       100,
+		ServiceID:      10,
+		Action:         models.ControlActionStop,
+		RequestedBy:    "user-1",
+		RequestedLogin: "alice",
+		RequestComment: "плановые работы",
+		ExpiresAt:      fixedTime.Add(time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		insertErr error
+		checkErr  func(t *testing.T, err error)
+	}{
+		{
+			name: "успешное создание",
+		},
+		{
+			name:      "действие уже ожидает подтверждения",
+			insertErr: &pgconn.PgError{Code: "23505"},
+			checkErr: func(t *testing.T, err error) {
+				var errDuplicated *errs.ErrDuplicatedApproval
+				assert.True(t, errors.As(err, &errDuplicated))
+			},
+		},
+		{
+			name:      "ошибка базы данных",
+			insertErr: errors.New("db error"),
+			checkErr: func(t *testing.T, err error) {
+				var errDuplicated *errs.ErrDuplicatedApproval
+				assert.False(t, errors.As(err, &errDuplicated))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE control_approvals SET status = 'expired'`)).
+				WithArgs(int64(10), models.ControlActionStop).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			insert := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO control_approvals`)).
+				WithArgs(int64(100), int64(10), models.ControlActionStop, "user-1", "alice", "плановые работы", approval.ExpiresAt)
+
+			if tt.insertErr != nil {
+				insert.WillReturnError(tt.insertErr)
+				mock.ExpectRollback()
+			} else {
+				insert.WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).
+					AddRow(int64(3), models.ApprovalStatusPending, fixedTime))
+				mock.ExpectCommit()
+			}
+
+			pg := &PgStorage{DB: db}
+			created, err := pg.AddControlApproval(context.Background(), approval)
+
+			if tt.checkErr != nil {
+				require.Error(t, err)
+				tt.checkErr(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), created.ID)
+				assert.Equal(t, models.ApprovalStatusPending, created.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestGetControlApproval Проверяет получение запроса на подтверждение.
+func TestGetControlApproval(t *testing.T) {
+	fixedTime := time.Now()
+
+	t.Run("запрос найден", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(selectControlApprovals+` WHERE id = $2`)).
+			WithArgs("user-2", int64(3)).
+			WillReturnRows(sqlmock.NewRows(approvalColumns).
+				AddRow(int64(3), int64(7), int64(100), int64(10), "Database", "stop", "rejected",
+					"user-1", "alice", "", "user-2", "bob", "не сейчас", "", fixedTime, fixedTime, fixedTime))
+
+		pg := &PgStorage{DB: db}
+		approval, err := pg.GetControlApproval(context.Background(), 3, "user-2")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), approval.TeamID)
+		assert.Equal(t, models.ApprovalStatusRejected, approval.Status)
+		assert.Equal(t, "bob", approval.DecidedLogin)
+		require.NotNil(t, approval.DecidedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("запрос не найден", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(selectControlApprovals+` WHERE id = $2`)).
+			WithArgs("user-2", int64(3)).
+			WillReturnError(sql.ErrNoRows)
+
+		pg := &PgStorage{DB: db}
+		_, err = pg.GetControlApproval(context.Background(), 3, "user-2")
+
+		var errNotFound *errs.ErrApprovalNotFound
+		assert.True(t, errors.As(err, &errNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestListControlApprovals Проверяет получение списка запросов с фильтром по статусу.
+func TestListControlApprovals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	fixedTime := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE $2 = '' OR status = $2`)).
+		WithArgs("user-2", models.ApprovalStatusPending, controlApprovalListLimit).
+		WillReturnRows(sqlmock.NewRows(approvalColumns).
+			AddRow(int64(4), int64(7), int64(100), int64(10), "Database", "restart", "pending",
+				"user-1", "alice", "", "", "", "", "", fixedTime, fixedTime.Add(time.Hour), nil).
+			AddRow(int64(3), int64(7), int64(100), int64(10), "Database", "stop", "pending",
+				"", "alice", "", "", "", "", "", fixedTime, fixedTime.Add(time.Hour), nil))
+
+	pg := &PgStorage{DB: db}
+	approvals, err := pg.ListControlApprovals(context.Background(), "user-2", models.ApprovalStatusPending)
+
+	require.NoError(t, err)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, models.ControlActionRestart, approvals[0].Action)
+	assert.Nil(t, approvals[0].DecidedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDecideControlApproval Проверяет сохранение решения по запросу.
+func TestDecideControlApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantErr      bool
+	}{
+		{"решение сохранено", 1, false},
+		{"запрос уже рассмотрен, истек или принадлежит подтверждающему", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`AND ($2 <> 'approved' OR requested_by IS DISTINCT FROM $3)`)).
+				WithArgs(int64(3), models.ApprovalStatusApproved, "user-2", "bob", "ок").
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			pg := &PgStorage{DB: db}
+			err = pg.DecideControlApproval(context.Background(), 3, models.ApprovalStatusApproved, "user-2", "bob", "ок")
+
+			if tt.wantErr {
+				var errNotPending *errs.ErrApprovalNotPending
+				assert.True(t, errors.As(err, &errNotPending))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestSetServiceCritical Проверяет изменение признака критичной службы.
+func TestSetServiceCritical(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantErr      bool
+	}{
+		{"признак изменен", 1, false},
+		{"служба не найдена", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE services SET critical = $1`)).
+				WithArgs(true, int64(10), int64(100), "user-1").
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			pg := &PgStorage{DB: db}
+			err = pg.SetServiceCritical(context.Background(), 100, 10, "user-1", true)
+
+			if tt.wantErr {
+				var errNotFound *errs.ErrServiceNotFound
+				assert.True(t, errors.As(err, &errNotFound))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
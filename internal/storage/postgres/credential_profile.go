@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// AddCredentialProfile Создание профиля учетных данных команды profile.TeamID (пароль хранится зашифрованным).
+func (pg *PgStorage) AddCredentialProfile(ctx context.Context, profile models.CredentialProfile) (*models.CredentialProfile, error) {
+	password, keyID, err := pg.encryptPassword(profile.Password)
+	if err != nil {
+		logger.Log.Error("Не удалось зашифровать пароль профиля", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	query := `INSERT INTO credential_profiles (team_id, name, username, password, domain, auth, password_key_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at, updated_at`
+
+	err = pg.DB.QueryRowContext(ctx, query, profile.TeamID, profile.Name, profile.Username, password, profile.Domain, nullableAuth(profile.Auth), keyID).
+		Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.NewErrDuplicatedCredentialProfile(profile.Name, err)
+		}
+		return nil, fmt.Errorf("ошибка при создании профиля учетных данных: %w", err)
+	}
+
+	// не показываем пароль в возвращаемом "наружу" профиле
+	profile.Password = ""
+
+	return &profile, nil
+}
+
+// EditCredentialProfile Редактирование профиля учетных данных команды. Пустые поля не изменяются,
+// пустой механизм аутентификации (auth = "") сбрасывает его. Новый пароль сразу применяется ко всем связанным серверам.
+func (pg *PgStorage) EditCredentialProfile(ctx context.Context, profile *models.CredentialProfile, profileID, teamID int64) (*models.CredentialProfile, error) {
+	password, keyID, err := pg.encryptPassword(profile.Password)
+	if err != nil {
+		logger.Log.Error("Не удалось зашифровать пароль профиля", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	// $6 - признак изменения механизма аутентификации (nil в запросе - оставить как есть)
+	query := `UPDATE credential_profiles SET
+			  	name = COALESCE(NULLIF($1, ''), name),
+			  	username = COALESCE(NULLIF($2, ''), username),
+			  	password = COALESCE(NULLIF($3, ''), password),
+			  	password_key_id = CASE WHEN $3 = '' THEN password_key_id ELSE $9 END,
+			  	domain = COALESCE(NULLIF($4, ''), domain),
+			  	auth = CASE WHEN $6 THEN NULLIF($5, '') ELSE auth END,
+			  	updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $7 AND team_id = $8
+			  RETURNING id, team_id, name, username, domain, auth, created_at, updated_at,
+			  	(SELECT COUNT(*) FROM servers WHERE credential_profile_id = $7)`
+
+	var (
+		auth       string
+		authChange bool
+	)
+
+	if profile.Auth != nil {
+		auth, authChange = *profile.Auth, true
+	}
+
+	var edited models.CredentialProfile
+
+	err = pg.DB.QueryRowContext(ctx, query, strings.TrimSpace(profile.Name), profile.Username, password, profile.Domain, auth, authChange, profileID, teamID, keyID).
+		Scan(&edited.ID, &edited.TeamID, &edited.Name, &edited.Username, &edited.Domain, &edited.Auth,
+			&edited.CreatedAt, &edited.UpdatedAt, &edited.ServersCount)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errs.NewErrCredentialProfileNotFound(profileID, teamID, err)
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return nil, errs.NewErrDuplicatedCredentialProfile(profile.Name, err)
+		default:
+			return nil, fmt.Errorf("ошибка при обновлении профиля учетных данных: %w", err)
+		}
+	}
+
+	return &edited, nil
+}
+
+// DelCredentialProfile Удаление профиля учетных данных команды. Профиль, к которому привязаны серверы, не удаляется.
+func (pg *PgStorage) DelCredentialProfile(ctx context.Context, profileID, teamID int64) error {
+	query := `DELETE FROM credential_profiles WHERE id = $1 AND team_id = $2`
+
+	result, err := pg.DB.ExecContext(ctx, query, profileID, teamID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.NewErrCredentialProfileInUse(profileID, err)
+		}
+
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrCredentialProfileNotFound(profileID, teamID, fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
+// GetCredentialProfile Получение профиля учетных данных команды (без пароля).
+func (pg *PgStorage) GetCredentialProfile(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error) {
+	query := `SELECT p.id, p.team_id, p.name, p.username, p.domain, p.auth, p.created_at, p.updated_at,
+			  	(SELECT COUNT(*) FROM servers s WHERE s.credential_profile_id = p.id)
+			  FROM credential_profiles p
+			  WHERE p.id = $1 AND p.team_id = $2`
+
+	var profile models.CredentialProfile
+
+	err := pg.DB.QueryRowContext(ctx, query, profileID, teamID).
+		Scan(&profile.ID, &profile.TeamID, &profile.Name, &profile.Username, &profile.Domain, &profile.Auth,
+			&profile.CreatedAt, &profile.UpdatedAt, &profile.ServersCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrCredentialProfileNotFound(profileID, teamID, err)
+		}
+		return nil, fmt.Errorf("ошибка при получении профиля учетных данных: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// GetCredentialProfileWithPassword Получение профиля учетных данных команды (с ПАРОЛЕМ).
+// Использовать ТОЛЬКО внутри бизнес-логики (WinRM).
+// Никогда не отдавать наружу через API!
+func (pg *PgStorage) GetCredentialProfileWithPassword(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error) {
+	query := `SELECT id, team_id, name, username, password, domain, auth, created_at, updated_at, password_key_id
+			  FROM credential_profiles
+			  WHERE id = $1 AND team_id = $2`
+
+	var (
+		profile models.CredentialProfile
+		keyID   *int
+	)
+
+	err := pg.DB.QueryRowContext(ctx, query, profileID, teamID).
+		Scan(&profile.ID, &profile.TeamID, &profile.Name, &profile.Username, &profile.Password, &profile.Domain, &profile.Auth,
+			&profile.CreatedAt, &profile.UpdatedAt, &keyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrCredentialProfileNotFound(profileID, teamID, err)
+		}
+		return nil, fmt.Errorf("ошибка при получении профиля учетных данных: %w", err)
+	}
+
+	// расшифровываем пароль
+	decrypted, err := pg.decryptPassword(profile.Password, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать пароль профиля: %w", err)
+	}
+	profile.Password = decrypted
+
+	return &profile, nil
+}
+
+// ListCredentialProfiles Получение профилей учетных данных команды (без паролей) с количеством связанных серверов.
+func (pg *PgStorage) ListCredentialProfiles(ctx context.Context, teamID int64) ([]*models.CredentialProfile, error) {
+	query := `SELECT p.id, p.team_id, p.name, p.username, p.domain, p.auth, p.created_at, p.updated_at,
+			  	(SELECT COUNT(*) FROM servers s WHERE s.credential_profile_id = p.id)
+			  FROM credential_profiles p
+			  WHERE p.team_id = $1
+			  ORDER BY p.name, p.id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, teamID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении профилей учетных данных команды", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении профилей учетных данных команды: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*models.CredentialProfile
+
+	for rows.Next() {
+		var profile models.CredentialProfile
+
+		err = rows.Scan(&profile.ID, &profile.TeamID, &profile.Name, &profile.Username, &profile.Domain, &profile.Auth,
+			&profile.CreatedAt, &profile.UpdatedAt, &profile.ServersCount)
+		if err != nil {
+			logger.Log.Error("Ошибка парсинга профилей учетных данных команды", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		profiles = append(profiles, &profile)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк профилей учетных данных команды", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// ListCredentialProfileServers Получение серверов, связанных с профилем учетных данных команды
+// (без учетных данных: они берутся из профиля).
+func (pg *PgStorage) ListCredentialProfileServers(ctx context.Context, profileID, teamID int64) ([]*models.Server, error) {
+	query := `SELECT id, team_id, name, address, fingerprint, created_at,
+			  	winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth, credential_profile_id
+			  FROM servers
+			  WHERE credential_profile_id = $1 AND team_id = $2
+			  ORDER BY name, id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, profileID, teamID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении серверов профиля учетных данных", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении серверов профиля учетных данных: %w", err)
+	}
+	defer rows.Close()
+
+	var servers []*models.Server
+
+	for rows.Next() {
+		var server models.Server
+
+		err = rows.Scan(append(append([]any{&server.ID, &server.TeamID, &server.Name, &server.Address, &server.Fingerprint, &server.CreatedAt},
+			winRMDest(&server.WinRM)...), &server.CredentialProfileID)...)
+		if err != nil {
+			logger.Log.Error("Ошибка парсинга серверов профиля учетных данных", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		servers = append(servers, &server)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк серверов профиля учетных данных", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return servers, nil
+}
+
+// nullableAuth Возвращает nil для незаданного или пустого механизма аутентификации (NULL в БД).
+func nullableAuth(auth *string) *string {
+	if auth == nil || *auth == "" {
+		return nil
+	}
+
+	return auth
+}
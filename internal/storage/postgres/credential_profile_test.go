@@ -0,0 +1,335 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage/postgres/utils"
+)
+
+// TestAddCredentialProfile Проверяет создание профиля учетных данных с шифрованием пароля.
+func TestAddCredentialProfile(t *testing.T) {
+	fixedTime := time.Now()
+	aesKey := []byte("12345678901234567890123456789012")
+
+	query := `INSERT INTO credential_profiles (team_id, name, username, password, domain, auth, password_key_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at, updated_at`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		isExpectedErr func(err error) bool // проверка типа ожидаемой ошибки (nil - ошибки нет)
+	}{
+		{
+			name: "успешное создание",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(int64(10), "Мониторинг", "monitor", sqlmock.AnyArg(), "CORP", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(int64(3), fixedTime, fixedTime))
+			},
+		},
+		{
+			name: "дубликат названия",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			isExpectedErr: func(err error) bool { var target *errs.ErrDuplicatedCredentialProfile; return errors.As(err, &target) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
+			result, err := pg.AddCredentialProfile(context.Background(), models.CredentialProfile{
+				TeamID:   10,
+				Name:     "Мониторинг",
+				Username: "monitor",
+				Password: "secret",
+				Domain:   "CORP",
+			})
+
+			if tt.isExpectedErr != nil {
+				assert.True(t, tt.isExpectedErr(err), "неожиданная ошибка: %v", err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), result.ID)
+				assert.Empty(t, result.Password) // пароль не возвращается
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestEditCredentialProfile Проверяет редактирование (ротацию) профиля учетных данных.
+func TestEditCredentialProfile(t *testing.T) {
+	fixedTime := time.Now()
+	aesKey := []byte("12345678901234567890123456789012")
+	ntlm := models.WinRMAuthNTLM
+	reset := ""
+
+	columns := []string{"id", "team_id", "name", "username", "domain", "auth", "created_at", "updated_at", "count"}
+
+	tests := []struct {
+		name          string
+		profile       models.CredentialProfile
+		mockSetup     func(mock sqlmock.Sqlmock)
+		isExpectedErr func(err error) bool // проверка типа ожидаемой ошибки (nil - ошибки нет)
+	}{
+		{
+			name:    "смена пароля без изменения остальных полей",
+			profile: models.CredentialProfile{Password: "new-secret"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credential_profiles SET`)).
+					WithArgs("", "", sqlmock.AnyArg(), "", "", false, int64(3), int64(10), 1).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(3), int64(10), "Мониторинг", "monitor", "", nil, fixedTime, fixedTime, 4))
+			},
+		},
+		{
+			name:    "смена механизма аутентификации",
+			profile: models.CredentialProfile{Auth: &ntlm},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credential_profiles SET`)).
+					WithArgs("", "", "", "", ntlm, true, int64(3), int64(10), nil).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(3), int64(10), "Мониторинг", "monitor", "", ntlm, fixedTime, fixedTime, 4))
+			},
+		},
+		{
+			name:    "сброс механизма аутентификации",
+			profile: models.CredentialProfile{Auth: &reset},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credential_profiles SET`)).
+					WithArgs("", "", "", "", "", true, int64(3), int64(10), nil).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(3), int64(10), "Мониторинг", "monitor", "", nil, fixedTime, fixedTime, 4))
+			},
+		},
+		{
+			name:    "профиль не найден",
+			profile: models.CredentialProfile{Name: "Другой"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credential_profiles SET`)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			isExpectedErr: func(err error) bool { var target *errs.ErrCredentialProfileNotFound; return errors.As(err, &target) },
+		},
+		{
+			name:    "дубликат названия",
+			profile: models.CredentialProfile{Name: "Другой"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credential_profiles SET`)).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			isExpectedErr: func(err error) bool { var target *errs.ErrDuplicatedCredentialProfile; return errors.As(err, &target) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
+			result, err := pg.EditCredentialProfile(context.Background(), &tt.profile, 3, 10)
+
+			if tt.isExpectedErr != nil {
+				assert.True(t, tt.isExpectedErr(err), "неожиданная ошибка: %v", err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 4, result.ServersCount)
+				assert.Empty(t, result.Password)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestDelCredentialProfile Проверяет удаление профиля учетных данных.
+func TestDelCredentialProfile(t *testing.T) {
+	query := `DELETE FROM credential_profiles WHERE id = $1 AND team_id = $2`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		isExpectedErr func(err error) bool // проверка типа ожидаемой ошибки (nil - ошибки нет)
+	}{
+		{
+			name: "успешное удаление",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(int64(3), int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "профиль не найден",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(int64(3), int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			isExpectedErr: func(err error) bool { var target *errs.ErrCredentialProfileNotFound; return errors.As(err, &target) },
+		},
+		{
+			name: "профиль используется серверами",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(int64(3), int64(10)).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+			},
+			isExpectedErr: func(err error) bool { var target *errs.ErrCredentialProfileInUse; return errors.As(err, &target) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			err = pg.DelCredentialProfile(context.Background(), 3, 10)
+
+			if tt.isExpectedErr != nil {
+				assert.True(t, tt.isExpectedErr(err), "неожиданная ошибка: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestGetCredentialProfileWithPassword Проверяет получение профиля с расшифровкой пароля.
+func TestGetCredentialProfileWithPassword(t *testing.T) {
+	fixedTime := time.Now()
+	aesKey := []byte("12345678901234567890123456789012")
+
+	encrypted, err := utils.EncryptAES([]byte("secret"), aesKey)
+	require.NoError(t, err)
+
+	query := `SELECT id, team_id, name, username, password, domain, auth, created_at, updated_at, password_key_id
+			  FROM credential_profiles
+			  WHERE id = $1 AND team_id = $2`
+
+	columns := []string{"id", "team_id", "name", "username", "password", "domain", "auth", "created_at", "updated_at", "password_key_id"}
+
+	t.Run("успешное получение", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(int64(3), int64(10)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(int64(3), int64(10), "Мониторинг", "monitor", encrypted, "CORP", "ntlm", fixedTime, fixedTime, 1))
+
+		pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
+		profile, err := pg.GetCredentialProfileWithPassword(context.Background(), 3, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, "secret", profile.Password)
+		assert.Equal(t, `CORP\monitor`, profile.Login())
+		require.NotNil(t, profile.Auth)
+		assert.Equal(t, "ntlm", *profile.Auth)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пароль зашифрован ключом, которого нет в конфигурации", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(int64(3), int64(10)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(int64(3), int64(10), "Мониторинг", "monitor", encrypted, "CORP", nil, fixedTime, fixedTime, 5))
+
+		pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
+		profile, err := pg.GetCredentialProfileWithPassword(context.Background(), 3, 10)
+
+		var errKey *errs.ErrAESKeyNotFound
+		require.True(t, errors.As(err, &errKey))
+		assert.Equal(t, 5, errKey.KeyID)
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("профиль другой команды", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(int64(3), int64(20)).
+			WillReturnError(sql.ErrNoRows)
+
+		pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
+		profile, err := pg.GetCredentialProfileWithPassword(context.Background(), 3, 20)
+
+		var errNotFound *errs.ErrCredentialProfileNotFound
+		assert.True(t, errors.As(err, &errNotFound))
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestListCredentialProfileServers Проверяет получение серверов, связанных с профилем.
+func TestListCredentialProfileServers(t *testing.T) {
+	fixedTime := time.Now()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, team_id, name, address, fingerprint, created_at,
+			  	winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth, credential_profile_id
+			  FROM servers
+			  WHERE credential_profile_id = $1 AND team_id = $2
+			  ORDER BY name, id`)).
+		WithArgs(int64(3), int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "name", "address", "fingerprint", "created_at",
+			"winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id"}).
+			AddRow(int64(1), int64(10), "DC1", "10.0.0.1", "00000000-0000-0000-0000-000000000001", fixedTime, int64(5986), true, nil, nil, nil, nil, int64(3)).
+			AddRow(int64(2), int64(10), "DC2", "10.0.0.2", "00000000-0000-0000-0000-000000000002", fixedTime, nil, nil, nil, nil, nil, "ntlm", int64(3)))
+
+	pg := &PgStorage{DB: db}
+	servers, err := pg.ListCredentialProfileServers(context.Background(), 3, 10)
+
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.NotNil(t, servers[0].WinRM.Port)
+	assert.Equal(t, 5986, *servers[0].WinRM.Port)
+	require.NotNil(t, servers[1].WinRM.Auth)
+	assert.Equal(t, "ntlm", *servers[1].WinRM.Auth)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
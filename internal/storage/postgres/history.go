@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// AddServerStatusEvent Запись в историю смены статуса сервера.
+func (pg *PgStorage) AddServerStatusEvent(ctx context.Context, serverID int64, status models.Status) error {
+	query := `INSERT INTO server_status_history (server_id, status) VALUES ($1, $2)`
+
+	_, err := pg.DB.ExecContext(ctx, query, serverID, status.String())
+	if err != nil {
+		logger.Log.Error("Ошибка при записи истории статуса сервера", logger.Int64("serverID", serverID), logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка записи истории статуса сервера: %w", err)
+	}
+
+	return nil
+}
+
+// ListServerStatusHistory Возвращает историю статусов серверов команд пользователя за период [from, to).
+//
+// Помимо событий внутри периода возвращается последнее событие до его начала,
+// чтобы можно было определить статус сервера на момент from.
+// Результат упорядочен по серверу и времени события.
+func (pg *PgStorage) ListServerStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServerStatusEvent, error) {
+	query := `SELECT h.server_id, h.status, h.changed_at
+			  FROM server_status_history h
+			  JOIN servers s ON s.id = h.server_id
+			  WHERE s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1) AND h.changed_at < $3
+				AND (h.changed_at >= $2 OR h.id = (
+					SELECT MAX(p.id) FROM server_status_history p
+					WHERE p.server_id = h.server_id AND p.changed_at < $2))
+			  ORDER BY h.server_id, h.changed_at, h.id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении истории статусов серверов", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении истории статусов серверов: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.ServerStatusEvent
+
+	for rows.Next() {
+		var event models.ServerStatusEvent
+		err = rows.Scan(&event.ServerID, &event.Status, &event.ChangedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки истории статусов серверов", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк истории статусов серверов", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListServiceStatusHistory Возвращает историю статусов служб команд пользователя за период [from, to).
+//
+// Аналогично ListServerStatusHistory, включает последнее событие до начала периода.
+// Результат упорядочен по службе и времени события.
+func (pg *PgStorage) ListServiceStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServiceStatusEvent, error) {
+	query := `SELECT h.service_id, sv.server_id, sv.service_name, sv.displayed_name, h.status, h.changed_at
+			  FROM service_status_history h
+			  JOIN services sv ON sv.id = h.service_id
+			  JOIN servers s ON s.id = sv.server_id
+			  WHERE s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1) AND h.changed_at < $3
+				AND (h.changed_at >= $2 OR h.id = (
+					SELECT MAX(p.id) FROM service_status_history p
+					WHERE p.service_id = h.service_id AND p.changed_at < $2))
+			  ORDER BY h.service_id, h.changed_at, h.id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении истории статусов служб", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении истории статусов служб: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.ServiceStatusEvent
+
+	for rows.Next() {
+		var event models.ServiceStatusEvent
+		err = rows.Scan(&event.ServiceID, &event.ServerID, &event.ServiceName, &event.DisplayedName, &event.Status, &event.ChangedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки истории статусов служб", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк истории статусов служб", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListControlActions Возвращает действия участников команд пользователя по управлению службами
+// на серверах этих команд за период [from, to) из журнала аудита.
+func (pg *PgStorage) ListControlActions(ctx context.Context, userID string, from, to time.Time) ([]*models.ControlAction, error) {
+	query := `SELECT id, user_id, COALESCE(server_id, 0), COALESCE(service_id, 0), action, success, created_at
+			  FROM audit_log
+			  WHERE server_id IN (SELECT id FROM servers WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $1))
+				AND created_at >= $2 AND created_at < $3
+				AND action IN ('start', 'stop', 'restart')
+			  ORDER BY created_at, id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении действий управления службами", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении действий управления службами: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*models.ControlAction
+
+	for rows.Next() {
+		var action models.ControlAction
+		err = rows.Scan(&action.ID, &action.UserID, &action.ServerID, &action.ServiceID, &action.Action, &action.Success, &action.CreatedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки действий управления службами", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		actions = append(actions, &action)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк действий управления службами", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return actions, nil
+}
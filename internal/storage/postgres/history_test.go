@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestAddServerStatusEvent Проверяет запись истории смены статуса сервера.
+func TestAddServerStatusEvent(t *testing.T) {
+	query := `INSERT INTO server_status_history (server_id, status) VALUES ($1, $2)`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+	}{
+		{
+			name: "успешная запись",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(int64(1), "Unreachable").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(int64(1), "Unreachable").
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			err = pg.AddServerStatusEvent(context.Background(), 1, models.StatusUnreachable)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "ошибка записи истории статуса сервера")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListServerStatusHistory Проверяет получение истории статусов серверов пользователя.
+func TestListServerStatusHistory(t *testing.T) {
+	query := `SELECT h.server_id, h.status, h.changed_at
+			  FROM server_status_history h`
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+		validate    func(t *testing.T, result []*models.ServerStatusEvent)
+	}{
+		{
+			name: "успешное получение истории",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"server_id", "status", "changed_at"}).
+					AddRow(int64(1), "OK", from.Add(-time.Hour)).
+					AddRow(int64(1), "Unreachable", from.Add(time.Hour))
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnRows(rows)
+			},
+			validate: func(t *testing.T, result []*models.ServerStatusEvent) {
+				require.Len(t, result, 2)
+				assert.Equal(t, models.StatusOK, result[0].Status)
+				assert.Equal(t, models.StatusUnreachable, result[1].Status)
+				assert.Equal(t, from.Add(time.Hour), result[1].ChangedAt)
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ServerStatusEvent) {
+				assert.Nil(t, result)
+			},
+		},
+		{
+			name: "ошибка сканирования строки",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"server_id", "status", "changed_at"}).
+					AddRow("not-int", "OK", from)
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnRows(rows)
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ServerStatusEvent) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			result, err := pg.ListServerStatusHistory(context.Background(), "any-id-user-1", from, to)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validate(t, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListServiceStatusHistory Проверяет получение истории статусов служб пользователя.
+func TestListServiceStatusHistory(t *testing.T) {
+	query := `SELECT h.service_id, sv.server_id, sv.service_name, sv.displayed_name, h.status, h.changed_at
+			  FROM service_status_history h`
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+		validate    func(t *testing.T, result []*models.ServiceStatusEvent)
+	}{
+		{
+			name: "успешное получение истории",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"service_id", "server_id", "service_name", "displayed_name", "status", "changed_at"}).
+					AddRow(int64(5), int64(1), "spooler", "Диспетчер печати", "Остановлена", from.Add(time.Hour))
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnRows(rows)
+			},
+			validate: func(t *testing.T, result []*models.ServiceStatusEvent) {
+				require.Len(t, result, 1)
+				assert.Equal(t, int64(5), result[0].ServiceID)
+				assert.Equal(t, int64(1), result[0].ServerID)
+				assert.Equal(t, "spooler", result[0].ServiceName)
+				assert.Equal(t, "Остановлена", result[0].Status)
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ServiceStatusEvent) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			result, err := pg.ListServiceStatusHistory(context.Background(), "any-id-user-1", from, to)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validate(t, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListControlActions Проверяет получение действий управления службами за период.
+func TestListControlActions(t *testing.T) {
+	query := `SELECT id, user_id, COALESCE(server_id, 0), COALESCE(service_id, 0), action, success, created_at
+			  FROM audit_log`
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+		validate    func(t *testing.T, result []*models.ControlAction)
+	}{
+		{
+			name: "успешное получение действий",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "server_id", "service_id", "action", "success", "created_at"}).
+					AddRow(int64(1), "any-id-user-1", int64(1), int64(2), "start", true, from.Add(time.Hour)).
+					AddRow(int64(2), "any-id-user-1", int64(1), int64(2), "stop", false, from.Add(2*time.Hour))
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnRows(rows)
+			},
+			validate: func(t *testing.T, result []*models.ControlAction) {
+				require.Len(t, result, 2)
+				assert.Equal(t, "start", result[0].Action)
+				assert.True(t, result[0].Success)
+				assert.Equal(t, "stop", result[1].Action)
+				assert.False(t, result[1].Success)
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("any-id-user-1", from, to).
+					WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ControlAction) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			result, err := pg.ListControlActions(context.Background(), "any-id-user-1", from, to)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validate(t, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
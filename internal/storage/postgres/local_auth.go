@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// CreateLocalUser Создание пользователя встроенной аутентификации с хэшем пароля.
+func (pg *PgStorage) CreateLocalUser(ctx context.Context, user *models.User, passwordHash string) error {
+	query := `INSERT INTO users (id, login, email, password_hash) VALUES ($1, $2, NULLIF($3, ''), $4)`
+
+	_, err := pg.DB.ExecContext(ctx, query, user.ID, user.Login, user.Email, passwordHash)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errs.NewErrUserAlreadyExists(user.Login, err)
+		}
+
+		logger.Log.Error("Ошибка при создании пользователя", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка создания пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// GetLocalUser Получение пользователя с хэшем пароля по логину.
+// У пользователя внешнего провайдера хэш пароля пустой.
+func (pg *PgStorage) GetLocalUser(ctx context.Context, login string) (*models.LocalUser, error) {
+	query := `SELECT id, login, COALESCE(email, ''), COALESCE(password_hash, '') FROM users WHERE login = $1`
+
+	var user models.LocalUser
+
+	err := pg.DB.QueryRowContext(ctx, query, login).Scan(&user.ID, &user.Login, &user.Email, &user.PasswordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrWrongLoginOrPassword(err)
+		}
+		return nil, fmt.Errorf("ошибка при получении пользователя: %w", err)
+	}
+
+	return &user, nil
+}
+
+// SetUserPassword Изменение пароля пользователя. Все refresh-токены пользователя отзываются.
+func (pg *PgStorage) SetUserPassword(ctx context.Context, userID string, passwordHash string) error {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Log.Error("Ошибка транзакции при изменении пароля", logger.String("err", err.Error()))
+		return fmt.Errorf("не удалось начать транзакцию изменения пароля: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении пароля: %w", err)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrUserIDNotFound(userID)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка при отзыве refresh-токенов: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error("Ошибка при коммите транзакции изменения пароля", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при коммите транзакции изменения пароля: %w", err)
+	}
+
+	return nil
+}
+
+// AddRefreshToken Сохранение хэша refresh-токена пользователя.
+func (pg *PgStorage) AddRefreshToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+
+	if _, err := pg.DB.ExecContext(ctx, query, userID, tokenHash, expiresAt); err != nil {
+		logger.Log.Error("Ошибка при сохранении refresh-токена", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при сохранении refresh-токена: %w", err)
+	}
+
+	return nil
+}
+
+// UseRefreshToken Использование refresh-токена: токен удаляется (одноразовый),
+// возвращается его владелец. Истекший токен тоже удаляется, но не принимается.
+func (pg *PgStorage) UseRefreshToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := `WITH used AS (
+				  DELETE FROM refresh_tokens WHERE token_hash = $1 RETURNING user_id, expires_at
+			  )
+			  SELECT u.id, u.login, COALESCE(u.email, '')
+			  FROM used
+			  JOIN users u ON u.id = used.user_id
+			  WHERE used.expires_at > CURRENT_TIMESTAMP`
+
+	var user models.User
+
+	err := pg.DB.QueryRowContext(ctx, query, tokenHash).Scan(&user.ID, &user.Login, &user.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrRefreshTokenNotFound(err)
+		}
+		return nil, fmt.Errorf("ошибка при проверке refresh-токена: %w", err)
+	}
+
+	return &user, nil
+}
+
+// DelRefreshToken Отзыв refresh-токена (выход). Отсутствие токена ошибкой не считается.
+func (pg *PgStorage) DelRefreshToken(ctx context.Context, tokenHash string) error {
+	if _, err := pg.DB.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE token_hash = $1`, tokenHash); err != nil {
+		logger.Log.Error("Ошибка при отзыве refresh-токена", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при отзыве refresh-токена: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestCreateLocalUser Проверяет создание пользователя встроенной аутентификации.
+func TestCreateLocalUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		insertErr error
+		checkErr  func(t *testing.T, err error)
+	}{
+		{
+			name: "успешное создание",
+		},
+		{
+			name:      "логин занят",
+			insertErr: &pgconn.PgError{Code: "23505"},
+			checkErr: func(t *testing.T, err error) {
+				var errExists *errs.ErrUserAlreadyExists
+				assert.True(t, errors.As(err, &errExists))
+			},
+		},
+		{
+			name:      "ошибка базы данных",
+			insertErr: errors.New("db error"),
+			checkErr: func(t *testing.T, err error) {
+				var errExists *errs.ErrUserAlreadyExists
+				assert.False(t, errors.As(err, &errExists))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			exec := mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, login, email, password_hash)`)).
+				WithArgs("user-1", "alice", "", "hash")
+			if tt.insertErr != nil {
+				exec.WillReturnError(tt.insertErr)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			pg := &PgStorage{DB: db}
+			err = pg.CreateLocalUser(context.Background(), &models.User{ID: "user-1", Login: "alice"}, "hash")
+
+			if tt.checkErr != nil {
+				require.Error(t, err)
+				tt.checkErr(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestGetLocalUser Проверяет получение пользователя с хэшем пароля по логину.
+func TestGetLocalUser(t *testing.T) {
+	t.Run("пользователь найден", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, login, COALESCE(email, ''), COALESCE(password_hash, '') FROM users WHERE login = $1`)).
+			WithArgs("alice").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "email", "password_hash"}).
+				AddRow("user-1", "alice", "alice@example.com", "hash"))
+
+		pg := &PgStorage{DB: db}
+		user, err := pg.GetLocalUser(context.Background(), "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.ID)
+		assert.Equal(t, "hash", user.PasswordHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пользователь не найден", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM users WHERE login = $1`)).
+			WithArgs("bob").
+			WillReturnError(sql.ErrNoRows)
+
+		pg := &PgStorage{DB: db}
+		_, err = pg.GetLocalUser(context.Background(), "bob")
+
+		var errWrongLogin *errs.ErrWrongLogin
+		assert.True(t, errors.As(err, &errWrongLogin))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestSetUserPassword Проверяет смену пароля с отзывом refresh-токенов.
+func TestSetUserPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantErr      bool
+	}{
+		{"пароль изменен", 1, false},
+		{"пользователь не найден", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password_hash = $2 WHERE id = $1`)).
+				WithArgs("user-1", "new-hash").
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			if tt.wantErr {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM refresh_tokens WHERE user_id = $1`)).
+					WithArgs("user-1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			}
+
+			pg := &PgStorage{DB: db}
+			err = pg.SetUserPassword(context.Background(), "user-1", "new-hash")
+
+			if tt.wantErr {
+				var errNotFound *errs.ErrUserIDNotFound
+				assert.True(t, errors.As(err, &errNotFound))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestUseRefreshToken Проверяет одноразовое использование refresh-токена.
+func TestUseRefreshToken(t *testing.T) {
+	t.Run("токен действителен", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM refresh_tokens WHERE token_hash = $1 RETURNING user_id, expires_at`)).
+			WithArgs("token-hash").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "email"}).AddRow("user-1", "alice", ""))
+
+		pg := &PgStorage{DB: db}
+		user, err := pg.UseRefreshToken(context.Background(), "token-hash")
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Login)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("токен использован или истек", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM refresh_tokens`)).
+			WithArgs("token-hash").
+			WillReturnError(sql.ErrNoRows)
+
+		pg := &PgStorage{DB: db}
+		_, err = pg.UseRefreshToken(context.Background(), "token-hash")
+
+		var errNotFound *errs.ErrRefreshTokenNotFound
+		assert.True(t, errors.As(err, &errNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAddRefreshToken Проверяет сохранение refresh-токена.
+func TestAddRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO refresh_tokens (user_id, token_hash, expires_at)`)).
+		WithArgs("user-1", "token-hash", expiresAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	pg := &PgStorage{DB: db}
+	assert.NoError(t, pg.AddRefreshToken(context.Background(), "user-1", "token-hash", expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -19,12 +19,13 @@ import (
 
 // PgStorage Структура хранилища в PostgreSQL, удовлетворяющая интерфейсу Storage.
 type PgStorage struct {
-	DB     *sql.DB
-	AESKey []byte
+	DB   *sql.DB
+	Keys *utils.KeyRing // AES-ключи для шифрования паролей серверов и профилей учетных данных
 }
 
-// InitStorage Инициализация хранилища.
-func InitStorage(DatabaseURI string, AESKey []byte) (*PgStorage, error) {
+// InitStorage Инициализация хранилища. Возвращает ошибку, если пароли в БД зашифрованы
+// версией AES-ключа, которой нет в keys.
+func InitStorage(DatabaseURI string, keys *utils.KeyRing) (*PgStorage, error) {
 	// открываем соединение с БД
 	pg, err := sql.Open("pgx", DatabaseURI)
 	if err != nil {
@@ -46,33 +47,54 @@ func InitStorage(DatabaseURI string, AESKey []byte) (*PgStorage, error) {
 		return nil, fmt.Errorf("ошибка применения миграций к БД PostgreSQL: %w", err)
 	}
 
-	pgStorage := &PgStorage{DB: pg, AESKey: AESKey}
+	pgStorage := &PgStorage{DB: pg, Keys: keys}
+
+	// без нужной версии ключа пароли невозможно расшифровать: лучше не запускаться, чем падать на каждом подключении
+	if err = pgStorage.CheckAESKeys(context.Background()); err != nil {
+		logger.Log.Error("Не заданы AES-ключи, которыми зашифрованы пароли в БД", logger.String("err", err.Error()))
+		_ = pg.Close()
+		return nil, err
+	}
 
 	logger.Log.Info("В качестве хранилища используется БД PostgreSQL")
 	return pgStorage, nil
 }
 
-// AddServer Добавление нового сервера в БД.
+// AddServer Добавление нового сервера в команду server.TeamID. userID сохраняется как автор сервера.
 func (pg *PgStorage) AddServer(ctx context.Context, server models.Server, userID string) (*models.Server, error) {
-	var newPassword string
+	var (
+		newPassword string
+		keyID       *int
+	)
 
-	if server.Password != "" {
+	// сервер, связанный с профилем учетных данных, не хранит собственные логин и пароль
+	username := server.Username
+
+	switch {
+	case server.HasCredentialProfile():
+		username = ""
+	case server.HasSecret():
+		// пароль сервера хранится во внешнем хранилище секретов
+	default:
 		// шифруем пароль для хранения в БД
-		encryptedPassword, err := utils.EncryptAES([]byte(server.Password), pg.AESKey)
+		encryptedPassword, passwordKeyID, err := pg.encryptPassword(server.Password)
 		if err != nil {
 			logger.Log.Error("Не удалось зашифровать пароль", logger.String("err", err.Error()))
 			return nil, err
 		}
-		newPassword = encryptedPassword
-	} else {
-		newPassword = server.Password
+		newPassword, keyID = encryptedPassword, passwordKeyID
 	}
 
-	query := `INSERT INTO servers (user_id, name, address, username, password, fingerprint) VALUES ($1, $2, $3, $4, $5, $6)
+	query := `INSERT INTO servers (user_id, team_id, name, address, username, password, fingerprint,
+			  	winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth, credential_profile_id, password_key_id, secret_path)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 			  RETURNING id, created_at`
 
+	winRM := server.WinRM
+
 	// обновляем значение id, created_at у уже переданной модели сервера
-	err := pg.DB.QueryRowContext(ctx, query, userID, server.Name, server.Address, server.Username, newPassword, server.Fingerprint).
+	err := pg.DB.QueryRowContext(ctx, query, userID, server.TeamID, server.Name, server.Address, username, newPassword, server.Fingerprint,
+		winRM.Port, winRM.HTTPS, winRM.Insecure, winRM.Timeout, winRM.ConnectTimeout, winRM.Auth, server.CredentialProfileID, keyID, nullableSecretPath(server.SecretPath)).
 		Scan(&server.ID, &server.CreatedAt)
 
 	var pgErr *pgconn.PgError
@@ -92,43 +114,62 @@ func (pg *PgStorage) AddServer(ctx context.Context, server models.Server, userID
 	return &server, nil
 }
 
-// EditServer Редактирование сервера, принадлежащего пользователю.
+// EditServer Редактирование сервера команды, в которой состоит пользователь.
 func (pg *PgStorage) EditServer(ctx context.Context, editedServer *models.Server, serverID int64, userID string) (*models.Server, error) {
-	var password string
+	var (
+		password string
+		keyID    *int
+	)
 
+	// сервер, связанный с профилем учетных данных, не хранит собственные логин и пароль
+	username := editedServer.Username
+
+	switch {
+	case editedServer.HasCredentialProfile():
+		username = ""
+	case editedServer.HasSecret():
+		// пароль сервера хранится во внешнем хранилище секретов, сохраненный ранее пароль удаляется
 	// если был передан новый пароль - шифруем его для передачи в БД
-	if editedServer.Password != "" {
-		encryptedPassword, err := utils.EncryptAES([]byte(editedServer.Password), pg.AESKey)
+	case editedServer.Password != "":
+		encryptedPassword, passwordKeyID, err := pg.encryptPassword(editedServer.Password)
 		if err != nil {
 			logger.Log.Error("Не удалось зашифровать пароль", logger.String("err", err.Error()))
 			return nil, err
 		}
 
-		password = encryptedPassword
-	} else {
-		// Если пароль не был передан, получаем текущий из БД
-		var currentPassword string
-		getCurrentPasswordQuery := `SELECT password FROM servers WHERE id = $1 AND user_id = $2`
-		err := pg.DB.QueryRowContext(ctx, getCurrentPasswordQuery, serverID, userID).Scan(&currentPassword)
+		password, keyID = encryptedPassword, passwordKeyID
+	default:
+		// Если пароль не был передан, получаем текущий из БД (вместе с версией ключа, которым он зашифрован)
+		getCurrentPasswordQuery := `SELECT password, password_key_id FROM servers WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
+		err := pg.DB.QueryRowContext(ctx, getCurrentPasswordQuery, serverID, userID).Scan(&password, &keyID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return nil, errs.NewErrServerNotFound(serverID, userID, err)
 			}
 			return nil, fmt.Errorf("ошибка при получении текущего пароля: %w", err)
 		}
-		password = currentPassword
 	}
 
 	// обновляем сервер собранными данными и сразу возвращаем данные для создания возвращаемого "наружу" сервера
-	updateQuery := `UPDATE servers SET name = $1, username = $2, address = $3, password = $4 
-              WHERE id = $5 AND user_id = $6 
-              RETURNING id, name, username, address, fingerprint, created_at`
+	updateQuery := `WITH s AS (
+              	UPDATE servers SET name = $1, username = $2, address = $3, password = $4,
+              		winrm_port = $7, winrm_https = $8, winrm_insecure = $9, winrm_timeout = $10, winrm_connect_timeout = $11, winrm_auth = $12,
+              		credential_profile_id = $13, password_key_id = $14, secret_path = $15
+              	WHERE id = $5 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $6)
+              	RETURNING *)
+              SELECT s.id, s.team_id, s.name, ` + serverLoginColumn + `, s.address, s.fingerprint, s.created_at,
+              	s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+              FROM s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id`
 
 	var returnedServer models.Server
 
+	winRM := editedServer.WinRM
+
 	// не показываем пароль в возвращаемом "наружу" сервере
-	err := pg.DB.QueryRowContext(ctx, updateQuery, editedServer.Name, editedServer.Username, editedServer.Address, password, serverID, userID).
-		Scan(&returnedServer.ID, &returnedServer.Name, &returnedServer.Username, &returnedServer.Address, &returnedServer.Fingerprint, &returnedServer.CreatedAt)
+	err := pg.DB.QueryRowContext(ctx, updateQuery, editedServer.Name, username, editedServer.Address, password, serverID, userID,
+		winRM.Port, winRM.HTTPS, winRM.Insecure, winRM.Timeout, winRM.ConnectTimeout, winRM.Auth, editedServer.CredentialProfileID, keyID, nullableSecretPath(editedServer.SecretPath)).
+		Scan(append(append([]any{&returnedServer.ID, &returnedServer.TeamID, &returnedServer.Name, &returnedServer.Username, &returnedServer.Address,
+			&returnedServer.Fingerprint, &returnedServer.CreatedAt}, winRMDest(&returnedServer.WinRM)...), &returnedServer.CredentialProfileID, &returnedServer.SecretPath)...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -140,10 +181,10 @@ func (pg *PgStorage) EditServer(ctx context.Context, editedServer *models.Server
 	return &returnedServer, nil
 }
 
-// DelServer Удаление сервера, принадлежащего пользователю.
+// DelServer Удаление сервера команды, в которой состоит пользователь.
 func (pg *PgStorage) DelServer(ctx context.Context, serverID int64, userID string) error {
 	query := `DELETE FROM servers 
-       		  WHERE id = $1 AND user_id = $2`
+       		  WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	Result, err := pg.DB.ExecContext(ctx, query, serverID, userID)
 
@@ -164,16 +205,19 @@ func (pg *PgStorage) DelServer(ctx context.Context, serverID int64, userID strin
 	return nil
 }
 
-// GetServer Получение информации о сервере, принадлежащем пользователю.
+// GetServer Получение информации о сервере команды, в которой состоит пользователь.
 // Вызывается когда нужно отдать наружу инфо о сервере через API.
 func (pg *PgStorage) GetServer(ctx context.Context, serverID int64, userID string) (*models.Server, error) {
 	var server models.Server
 
-	query := `SELECT id, name, address, username, fingerprint, created_at FROM servers 
-              WHERE id = $1 AND user_id = $2`
+	query := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, s.fingerprint, s.created_at,
+              	s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+              FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+              WHERE s.id = $1 AND s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	err := pg.DB.QueryRowContext(ctx, query, serverID, userID).
-		Scan(&server.ID, &server.Name, &server.Address, &server.Username, &server.Fingerprint, &server.CreatedAt)
+		Scan(append(append([]any{&server.ID, &server.TeamID, &server.Name, &server.Address, &server.Username, &server.Fingerprint, &server.CreatedAt},
+			winRMDest(&server.WinRM)...), &server.CredentialProfileID, &server.SecretPath)...)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -186,17 +230,26 @@ func (pg *PgStorage) GetServer(ctx context.Context, serverID int64, userID strin
 	return &server, nil
 }
 
-// GetServerWithPassword Получение информации о сервере (с ПАРОЛЕМ), принадлежащем пользователю.
+// GetServerWithPassword Получение информации о сервере (с ПАРОЛЕМ) команды, в которой состоит пользователь.
 // Использовать ТОЛЬКО внутри бизнес-логики (WinRM).
 // Никогда не отдавать наружу через API!
+// Для сервера, связанного с профилем учетных данных, возвращаются логин и пароль профиля.
+// Для сервера, ссылающегося на секрет (SecretPath), пароль не хранится в БД и подставляется провайдером секретов.
 func (pg *PgStorage) GetServerWithPassword(ctx context.Context, serverID int64, userID string) (*models.Server, error) {
-	var server models.Server
+	var (
+		server models.Server
+		keyID  *int
+	)
 
-	query := `SELECT id, name, address, username, password, fingerprint, created_at FROM servers 
-              WHERE id = $1 AND user_id = $2`
+	query := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, COALESCE(cp.password, s.password), s.fingerprint, s.created_at,
+              	s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, cp.auth,
+              	CASE WHEN cp.id IS NULL THEN s.password_key_id ELSE cp.password_key_id END, s.secret_path
+              FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+              WHERE s.id = $1 AND s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	err := pg.DB.QueryRowContext(ctx, query, serverID, userID).
-		Scan(&server.ID, &server.Name, &server.Address, &server.Username, &server.Password, &server.Fingerprint, &server.CreatedAt)
+		Scan(append(append([]any{&server.ID, &server.TeamID, &server.Name, &server.Address, &server.Username, &server.Password, &server.Fingerprint, &server.CreatedAt},
+			winRMDest(&server.WinRM)...), &server.CredentialProfileID, &server.CredentialAuth, &keyID, &server.SecretPath)...)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -207,22 +260,22 @@ func (pg *PgStorage) GetServerWithPassword(ctx context.Context, serverID int64,
 	}
 
 	// расшифровываем пароль
-	if server.Password != "" {
-		decrypted, err := utils.DecryptAES(server.Password, pg.AESKey)
-		if err != nil {
-			return nil, fmt.Errorf("не удалось расшифровать пароль: %w", err)
-		}
-		server.Password = decrypted
+	decrypted, err := pg.decryptPassword(server.Password, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать пароль: %w", err)
 	}
+	server.Password = decrypted
 
 	return &server, nil
 }
 
-// ListServers Отображение списка серверов, принадлежащих пользователю.
+// ListServers Отображение списка серверов всех команд, в которых состоит пользователь.
 func (pg *PgStorage) ListServers(ctx context.Context, userID string) ([]*models.Server, error) {
-	query := `SELECT id, name, address, username, fingerprint, created_at 
-			  FROM servers WHERE user_id = $1
-			  ORDER BY name`
+	query := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, s.fingerprint, s.created_at,
+			  	s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+			  FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+			  WHERE s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
+			  ORDER BY s.name`
 
 	rows, err := pg.DB.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -235,7 +288,8 @@ func (pg *PgStorage) ListServers(ctx context.Context, userID string) ([]*models.
 
 	for rows.Next() {
 		var server models.Server
-		err = rows.Scan(&server.ID, &server.Name, &server.Address, &server.Username, &server.Fingerprint, &server.CreatedAt)
+		err = rows.Scan(append(append([]any{&server.ID, &server.TeamID, &server.Name, &server.Address, &server.Username, &server.Fingerprint, &server.CreatedAt},
+			winRMDest(&server.WinRM)...), &server.CredentialProfileID, &server.SecretPath)...)
 		if err != nil {
 			logger.Log.Error("ошибка парсинга запроса на получение серверов пользователя", logger.String("err", err.Error()))
 			return nil, err
@@ -253,7 +307,7 @@ func (pg *PgStorage) ListServers(ctx context.Context, userID string) ([]*models.
 	return servers, nil
 }
 
-// AddService Добавление службы на сервер, принадлежащий пользователю.
+// AddService Добавление службы на сервер команды, в которой состоит пользователь.
 func (pg *PgStorage) AddService(ctx context.Context, serverID int64, userID string, service models.Service) (*models.Service, error) {
 	// создаем транзакцию при добавлении службы, чтобы гарантированно получить из базы актуальный
 	// статус службы и время его изменения и не попасть в ситуацию, когда кто-то параллельно изменил ее статус
@@ -269,7 +323,7 @@ func (pg *PgStorage) AddService(ctx context.Context, serverID int64, userID stri
 	var fingerprint uuid.UUID
 	queryFingerprint := `SELECT fingerprint 
                     FROM servers 
-                    WHERE id = $1 AND user_id = $2`
+                    WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 	err = tx.QueryRowContext(ctx, queryFingerprint, serverID, userID).Scan(&fingerprint)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -335,14 +389,14 @@ func (pg *PgStorage) AddService(ctx context.Context, serverID int64, userID stri
 	return &service, nil
 }
 
-// DelService Удаление службы с сервера пользователя.
+// DelService Удаление службы с сервера команды, в которой состоит пользователь.
 func (pg *PgStorage) DelService(ctx context.Context, serverID int64, serviceID int64, userID string) error {
 	query := `DELETE FROM services 
               WHERE id = $1 
                 AND server_id = $2
                 AND server_id IN (
                 	SELECT id FROM servers 
-                	WHERE user_id = $3
+                	WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $3)
                 )`
 
 	Result, err := pg.DB.ExecContext(ctx, query, serviceID, serverID, userID)
@@ -363,6 +417,35 @@ func (pg *PgStorage) DelService(ctx context.Context, serverID int64, serviceID i
 	return nil
 }
 
+// SetServiceCritical Отметка службы сервера команды, в которой состоит пользователь, как критичной
+// (остановка и перезапуск только после подтверждения) или снятие отметки.
+func (pg *PgStorage) SetServiceCritical(ctx context.Context, serverID int64, serviceID int64, userID string, critical bool) error {
+	query := `UPDATE services SET critical = $1
+			  WHERE id = $2
+			    AND server_id = $3
+			    AND server_id IN (
+					SELECT id FROM servers
+					WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $4)
+			    )`
+
+	result, err := pg.DB.ExecContext(ctx, query, critical, serviceID, serverID, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrServiceNotFound(userID, serverID, serviceID, fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
 // ChangeServiceStatus Изменение статуса службы.
 func (pg *PgStorage) ChangeServiceStatus(ctx context.Context, serverID int64, serviceName string, status string) error {
 	// если один пользователь обновляет статус и время службы на сервере,
@@ -446,21 +529,21 @@ func (pg *PgStorage) BatchChangeServiceStatus(ctx context.Context, serverID int6
 	return nil
 }
 
-// GetService Получение службы с сервера пользователя.
+// GetService Получение службы с сервера команды, в которой состоит пользователь.
 func (pg *PgStorage) GetService(ctx context.Context, serverID int64, serviceID int64, userID string) (*models.Service, error) {
-	query := `SELECT id, displayed_name, service_name, status, created_at, updated_at 
+	query := `SELECT id, displayed_name, service_name, status, critical, created_at, updated_at 
 			  FROM services 
 			  WHERE id = $1 
 			    AND server_id = $2 
 			    AND server_id IN (
 					SELECT id FROM servers 
-					WHERE user_id = $3
+					WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $3)
 			    )`
 
 	var service models.Service
 
 	err := pg.DB.QueryRowContext(ctx, query, serviceID, serverID, userID).
-		Scan(&service.ID, &service.DisplayedName, &service.ServiceName, &service.Status, &service.CreatedAt, &service.UpdatedAt)
+		Scan(&service.ID, &service.DisplayedName, &service.ServiceName, &service.Status, &service.Critical, &service.CreatedAt, &service.UpdatedAt)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -473,15 +556,15 @@ func (pg *PgStorage) GetService(ctx context.Context, serverID int64, serviceID i
 	return &service, nil
 }
 
-// ListServices Получение списка служб сервера, принадлежащего пользователю.
+// ListServices Получение списка служб сервера команды, в которой состоит пользователь.
 func (pg *PgStorage) ListServices(ctx context.Context, serverID int64, userID string) ([]*models.Service, error) {
 
-	// Сначала проверяем, принадлежит ли сервер пользователю
+	// Сначала проверяем, принадлежит ли сервер команде пользователя
 	var exists bool
 
 	checkOwnershipQuery := `SELECT EXISTS(
 							SELECT 1 FROM servers
-							WHERE id = $1 AND user_id = $2
+							WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)
 							)`
 
 	err := pg.DB.QueryRowContext(ctx, checkOwnershipQuery, serverID, userID).Scan(&exists)
@@ -494,7 +577,7 @@ func (pg *PgStorage) ListServices(ctx context.Context, serverID int64, userID st
 	}
 
 	// Теперь получаем службы
-	query := `SELECT id, displayed_name, service_name, status, created_at, updated_at
+	query := `SELECT id, displayed_name, service_name, status, critical, created_at, updated_at
 			  FROM services 
 			  WHERE server_id = $1
 			  ORDER BY service_name`
@@ -511,7 +594,7 @@ func (pg *PgStorage) ListServices(ctx context.Context, serverID int64, userID st
 	for rows.Next() {
 		var service models.Service
 
-		err = rows.Scan(&service.ID, &service.DisplayedName, &service.ServiceName, &service.Status, &service.CreatedAt, &service.UpdatedAt)
+		err = rows.Scan(&service.ID, &service.DisplayedName, &service.ServiceName, &service.Status, &service.Critical, &service.CreatedAt, &service.UpdatedAt)
 		if err != nil {
 			logger.Log.Error("ошибка парсинга запроса на получение серверов пользователя", logger.String("err", err.Error()))
 			return nil, err
@@ -529,11 +612,47 @@ func (pg *PgStorage) ListServices(ctx context.Context, serverID int64, userID st
 	return services, nil
 }
 
+// ListServicesStates Возвращает текущие статусы всех служб всех команд.
+func (pg *PgStorage) ListServicesStates(ctx context.Context) ([]*models.ServiceState, error) {
+	query := `SELECT sv.id, sv.server_id, s.team_id, sv.service_name, sv.status
+			  FROM services sv
+			  JOIN servers s ON s.id = sv.server_id
+			  ORDER BY sv.id`
+
+	rows, err := pg.DB.QueryContext(ctx, query)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении статусов всех служб", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении статусов всех служб: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*models.ServiceState
+
+	for rows.Next() {
+		var state models.ServiceState
+		err = rows.Scan(&state.ServiceID, &state.ServerID, &state.TeamID, &state.ServiceName, &state.Status)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки статусов всех служб", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		states = append(states, &state)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк статусов всех служб", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return states, nil
+}
+
 // CreateUser Создание пользователя.
 func (pg *PgStorage) CreateUser(ctx context.Context, user *models.User) error {
-	query := `INSERT INTO users (id, login) VALUES ($1, $2)`
+	query := `INSERT INTO users (id, login, email) VALUES ($1, $2, NULLIF($3, ''))`
 
-	_, err := pg.DB.ExecContext(ctx, query, user.ID, user.Login)
+	_, err := pg.DB.ExecContext(ctx, query, user.ID, user.Login, user.Email)
 	var pgErr *pgconn.PgError
 	if err != nil {
 		switch {
@@ -579,24 +698,78 @@ func (pg *PgStorage) DeleteUser(ctx context.Context, userID string) error {
 	return nil
 }
 
-//// GetUser Возвращает пользователя если он зарегистрирован.
-//func (pg *PgStorage) GetUser(ctx context.Context, user *models.User) (*models.User, error) {
-//	var userFromDB models.User
-//
-//	query := `SELECT id, login FROM users WHERE id = $1`
-//	err := pg.DB.QueryRowContext(ctx, query, user.ID).Scan(&userFromDB.ID, &userFromDB.Login)
-//
-//	if err != nil {
-//		switch {
-//		case errors.Is(err, sql.ErrNoRows):
-//			return nil, errs.NewErrUserIDNotFound(user.ID)
-//		default:
-//			return nil, err
-//		}
-//	}
-//
-//	return &userFromDB, nil
-//}
+// GetUser Возвращает пользователя по ID.
+func (pg *PgStorage) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	var user models.User
+
+	query := `SELECT id, login, COALESCE(email, ''), disabled, COALESCE(role, '') FROM users WHERE id = $1`
+	err := pg.DB.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.Login, &user.Email, &user.Disabled, &user.Role)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errs.NewErrUserIDNotFound(userID)
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// UpdateUser Обновление логина, email и признака отключения пользователя.
+// Пустой логин не изменяет текущий.
+func (pg *PgStorage) UpdateUser(ctx context.Context, user *models.User) error {
+	query := `UPDATE users SET login = COALESCE(NULLIF($2, ''), login), email = NULLIF($3, ''), disabled = $4 WHERE id = $1`
+
+	result, err := pg.DB.ExecContext(ctx, query, user.ID, user.Login, user.Email, user.Disabled)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errs.NewErrUserAlreadyExists(user.Login, err)
+		}
+
+		logger.Log.Error("Ошибка при обновлении пользователя",
+			logger.String("user_id", user.ID),
+			logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка обновления пользователя: %w", err)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrUserIDNotFound(user.ID)
+	}
+
+	return nil
+}
+
+// SetUserRole Сохранение роли пользователя. Пустая роль сбрасывает сохраненную.
+func (pg *PgStorage) SetUserRole(ctx context.Context, userID string, role models.Role) error {
+	query := `UPDATE users SET role = NULLIF($2, '') WHERE id = $1`
+
+	result, err := pg.DB.ExecContext(ctx, query, userID, string(role))
+	if err != nil {
+		logger.Log.Error("Ошибка при сохранении роли пользователя",
+			logger.String("user_id", userID),
+			logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка сохранения роли пользователя: %w", err)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrUserIDNotFound(userID)
+	}
+
+	return nil
+}
 
 // UserExists Возвращает (true, nil) если пользователь найден,
 // (false, nil) если не найден, (false, error) при ошибке БД.
@@ -624,7 +797,7 @@ func (pg *PgStorage) UserExists(ctx context.Context, userID string) (bool, error
 func (pg *PgStorage) ListUsers(ctx context.Context) ([]*models.User, error) {
 	var users []*models.User
 
-	query := `SELECT id, login FROM users`
+	query := `SELECT id, login, COALESCE(email, ''), disabled FROM users`
 
 	rows, err := pg.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -636,7 +809,7 @@ func (pg *PgStorage) ListUsers(ctx context.Context) ([]*models.User, error) {
 	for rows.Next() {
 		var user models.User
 
-		err = rows.Scan(&user.ID, &user.Login)
+		err = rows.Scan(&user.ID, &user.Login, &user.Email, &user.Disabled)
 		if err != nil {
 			logger.Log.Error("Ошибка сканирования строки списка пользователей", logger.String("err", err.Error()))
 			return nil, err
@@ -654,11 +827,11 @@ func (pg *PgStorage) ListUsers(ctx context.Context) ([]*models.User, error) {
 	return users, nil
 }
 
-// GetUserServiceStatuses Возвращает все службы со статусами, которые принадлежат указанному пользователю.
+// GetUserServiceStatuses Возвращает все службы со статусами на серверах команд указанного пользователя.
 func (pg *PgStorage) GetUserServiceStatuses(ctx context.Context, userID string) ([]*models.ServiceStatus, error) {
 	query := `SELECT id, server_id, status, updated_at 
 			  FROM services
-			  WHERE server_id IN (SELECT id FROM servers WHERE user_id = $1)`
+			  WHERE server_id IN (SELECT id FROM servers WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $1))`
 
 	var statuses []*models.ServiceStatus
 
@@ -712,7 +885,7 @@ func (pg *PgStorage) Close() error {
 }
 
 // ListServersAddresses Возвращает список всех зарегистрированных серверов
-// с минимально необходимыми данными — id сервера, id пользователя и сетевым адресом.
+// с минимально необходимыми данными — id сервера, id автора сервера, командой-владельцем и сетевым адресом.
 //
 // Метод используется фоновыми воркерами для получения перечня серверов,
 // которые необходимо периодически опрашивать или мониторить.
@@ -720,7 +893,9 @@ func (pg *PgStorage) Close() error {
 // Результат упорядочен по идентификатору сервера, чтобы обеспечить
 // детерминированный порядок обработки.
 func (pg *PgStorage) ListServersAddresses(ctx context.Context) ([]*models.ServerStatus, error) {
-	query := `SELECT id, address, user_id FROM servers ORDER BY id`
+	query := `SELECT id, address, COALESCE(user_id, ''), team_id,
+			  	winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth
+			  FROM servers ORDER BY id`
 
 	rows, err := pg.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -733,7 +908,7 @@ func (pg *PgStorage) ListServersAddresses(ctx context.Context) ([]*models.Server
 
 	for rows.Next() {
 		var server models.ServerStatus
-		err = rows.Scan(&server.ServerID, &server.Address, &server.UserID)
+		err = rows.Scan(append([]any{&server.ServerID, &server.Address, &server.UserID, &server.TeamID}, winRMDest(&server.WinRM)...)...)
 		if err != nil {
 			logger.Log.Error("ошибка парсинга запроса на получение всех серверов", logger.String("err", err.Error()))
 			return nil, err
@@ -750,3 +925,23 @@ func (pg *PgStorage) ListServersAddresses(ctx context.Context) ([]*models.Server
 
 	return servers, nil
 }
+
+// serverLoginColumn Логин сервера (таблица servers s) с учетом профиля учетных данных (таблица credential_profiles cp):
+// логин профиля (DOMAIN\username, если домен задан) заменяет собственный логин сервера.
+const serverLoginColumn = `COALESCE(CASE WHEN cp.domain = '' THEN cp.username ELSE cp.domain || '\' || cp.username END, s.username)`
+
+// winRMDest Возвращает приемники для сканирования параметров WinRM сервера
+// (winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth).
+// NULL в колонке оставляет параметр незаданным.
+func winRMDest(settings *models.WinRMSettings) []any {
+	return []any{&settings.Port, &settings.HTTPS, &settings.Insecure, &settings.Timeout, &settings.ConnectTimeout, &settings.Auth}
+}
+
+// nullableSecretPath Возвращает путь к секрету для записи в БД: пустой путь сохраняется как NULL.
+func nullableSecretPath(path *string) *string {
+	if path == nil || *path == "" {
+		return nil
+	}
+
+	return path
+}
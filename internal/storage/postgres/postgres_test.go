@@ -31,11 +31,16 @@ func TestAddServer(t *testing.T) {
 	fixedTime := time.Now()
 	testUserID := "any-id-user-1"
 	testServerID := int64(100)
+	testTeamID := int64(10)
 	// AES ключ должен быть ровно 32 байта для AES-256
 	aesKey := []byte("12345678901234567890123456789012")
+	winRMPort, winRMHTTPS := 5986, true
+	profileID := int64(7)
+	secretPath := "swsm/servers/web-01"
 
-	addServerQuery := `INSERT INTO servers (user_id, name, address, username, password, fingerprint) 
-			  VALUES ($1, $2, $3, $4, $5, $6)
+	addServerQuery := `INSERT INTO servers (user_id, team_id, name, address, username, password, fingerprint,
+			  	winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth, credential_profile_id, password_key_id, secret_path)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
               RETURNING id, created_at`
 
 	tests := []struct {
@@ -50,6 +55,7 @@ func TestAddServer(t *testing.T) {
 		{
 			name: "успешное добавление сервера с паролем",
 			server: models.Server{
+				TeamID:      testTeamID,
 				Name:        "Test Server",
 				Address:     "192.168.1.100",
 				Username:    "admin",
@@ -60,7 +66,7 @@ func TestAddServer(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Ожидаем SQL запрос с определенными параметрами
 				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
-					WithArgs(testUserID, "Test Server", "192.168.1.100", "admin", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs(testUserID, testTeamID, "Test Server", "192.168.1.100", "admin", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil, nil, nil, 1, nil).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
 						AddRow(testServerID, fixedTime))
 			},
@@ -68,6 +74,7 @@ func TestAddServer(t *testing.T) {
 			validate: func(t *testing.T, result *models.Server) {
 				assert.NotNil(t, result)
 				assert.Equal(t, testServerID, result.ID)
+				assert.Equal(t, testTeamID, result.TeamID)
 				assert.Equal(t, "Test Server", result.Name)
 				assert.Equal(t, "192.168.1.100", result.Address)
 				assert.Equal(t, "admin", result.Username)
@@ -78,6 +85,7 @@ func TestAddServer(t *testing.T) {
 		{
 			name: "успешное добавление сервера без пароля",
 			server: models.Server{
+				TeamID:      testTeamID,
 				Name:        "Test Server No Pass",
 				Address:     "192.168.1.101",
 				Username:    "user",
@@ -87,7 +95,7 @@ func TestAddServer(t *testing.T) {
 			userID: testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
-					WithArgs(testUserID, "Test Server No Pass", "192.168.1.101", "user", "", sqlmock.AnyArg()).
+					WithArgs(testUserID, testTeamID, "Test Server No Pass", "192.168.1.101", "user", "", sqlmock.AnyArg(), nil, nil, nil, nil, nil, nil, nil, nil, nil).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
 						AddRow(testServerID, fixedTime))
 			},
@@ -98,6 +106,86 @@ func TestAddServer(t *testing.T) {
 				assert.Empty(t, result.Password)
 			},
 		},
+		{
+			name: "успешное добавление сервера с настройками WinRM",
+			server: models.Server{
+				TeamID:      testTeamID,
+				Name:        "Test Server WinRM",
+				Address:     "192.168.1.104",
+				Username:    "user",
+				Fingerprint: uuid.New(),
+				WinRM:       models.WinRMSettings{Port: &winRMPort, HTTPS: &winRMHTTPS},
+			},
+			userID: testUserID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
+					WithArgs(testUserID, testTeamID, "Test Server WinRM", "192.168.1.104", "user", "", sqlmock.AnyArg(),
+						int64(winRMPort), winRMHTTPS, nil, nil, nil, nil, nil, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+						AddRow(testServerID, fixedTime))
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.Server) {
+				require.NotNil(t, result)
+				assert.Equal(t, testServerID, result.ID)
+				require.NotNil(t, result.WinRM.Port)
+				assert.Equal(t, winRMPort, *result.WinRM.Port)
+			},
+		},
+		{
+			name: "сервер с профилем учетных данных не хранит собственные логин и пароль",
+			server: models.Server{
+				TeamID:              testTeamID,
+				Name:                "Test Server Profile",
+				Address:             "192.168.1.105",
+				Username:            `CORP\monitor`,
+				Password:            "profile-password",
+				Fingerprint:         uuid.New(),
+				CredentialProfileID: &profileID,
+			},
+			userID: testUserID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
+					WithArgs(testUserID, testTeamID, "Test Server Profile", "192.168.1.105", "", "", sqlmock.AnyArg(),
+						nil, nil, nil, nil, nil, nil, profileID, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+						AddRow(testServerID, fixedTime))
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.Server) {
+				require.NotNil(t, result)
+				require.NotNil(t, result.CredentialProfileID)
+				assert.Equal(t, profileID, *result.CredentialProfileID)
+				assert.Empty(t, result.Password)
+			},
+		},
+		{
+			name: "сервер с секретом не хранит пароль",
+			server: models.Server{
+				TeamID:      testTeamID,
+				Name:        "Test Server Secret",
+				Address:     "192.168.1.106",
+				Username:    "admin",
+				Password:    "vault-password",
+				Fingerprint: uuid.New(),
+				SecretPath:  &secretPath,
+			},
+			userID: testUserID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
+					WithArgs(testUserID, testTeamID, "Test Server Secret", "192.168.1.106", "admin", "", sqlmock.AnyArg(),
+						nil, nil, nil, nil, nil, nil, nil, nil, &secretPath).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+						AddRow(testServerID, fixedTime))
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.Server) {
+				require.NotNil(t, result)
+				require.NotNil(t, result.SecretPath)
+				assert.Equal(t, secretPath, *result.SecretPath)
+				assert.Empty(t, result.Password)
+			},
+		},
 		{
 			name: "ошибка дубликата сервера",
 			server: models.Server{
@@ -111,8 +199,9 @@ func TestAddServer(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Симулируем ошибку уникального ограничения PostgreSQL
 				mock.ExpectQuery(regexp.QuoteMeta(addServerQuery)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
-						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(&pgconn.PgError{Code: "23505"})
 			},
 			expectError: true,
@@ -162,8 +251,8 @@ func TestAddServer(t *testing.T) {
 
 			// Создаем экземпляр PgStorage с mock БД
 			pg := &PgStorage{
-				DB:     db,
-				AESKey: aesKey,
+				DB:   db,
+				Keys: testKeyRing(t, aesKey),
 			}
 
 			// Выполняем тестируемый метод
@@ -193,13 +282,24 @@ func TestEditServer(t *testing.T) {
 	fixedTime := time.Now()
 	testUserID := "any-id-user-1"
 	testServerID := int64(100)
+	testTeamID := int64(10)
 	// AES ключ должен быть ровно 32 байта для AES-256
 	aesKey := []byte("12345678901234567890123456789012")
 	testFingerprint := uuid.New()
 
-	editServerQuery := `UPDATE servers SET name = $1, username = $2, address = $3, password = $4
-	         			WHERE id = $5 AND user_id = $6
-	         			RETURNING id, name, username, address, fingerprint, created_at`
+	editServerQuery := `WITH s AS (
+	         			UPDATE servers SET name = $1, username = $2, address = $3, password = $4,
+	         			winrm_port = $7, winrm_https = $8, winrm_insecure = $9, winrm_timeout = $10, winrm_connect_timeout = $11, winrm_auth = $12,
+	         			credential_profile_id = $13, password_key_id = $14, secret_path = $15
+	         			WHERE id = $5 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $6)
+	         			RETURNING *)
+	         			SELECT s.id, s.team_id, s.name, ` + serverLoginColumn + `, s.address, s.fingerprint, s.created_at,
+	         			s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+	         			FROM s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id`
+
+	editServerColumns := []string{"id", "team_id", "name", "username", "address", "fingerprint", "created_at",
+		"winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "secret_path"}
+	profileID := int64(7)
 
 	tests := []struct {
 		name           string                                    // название теста
@@ -225,14 +325,15 @@ func TestEditServer(t *testing.T) {
 				// Ожидаем UPDATE запрос
 				mock.ExpectQuery(regexp.QuoteMeta(editServerQuery)).
 					WithArgs("Updated Server", "newadmin", "192.168.1.200",
-						sqlmock.AnyArg(), testServerID, testUserID).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "address", "fingerprint", "created_at"}).
-						AddRow(testServerID, "Updated Server", "newadmin", "192.168.1.200", testFingerprint, fixedTime))
+						sqlmock.AnyArg(), testServerID, testUserID, nil, nil, nil, nil, nil, nil, nil, 1, nil).
+					WillReturnRows(sqlmock.NewRows(editServerColumns).
+						AddRow(testServerID, testTeamID, "Updated Server", "newadmin", "192.168.1.200", testFingerprint, fixedTime, nil, nil, nil, nil, nil, nil, nil, nil))
 			},
 			expectError: false,
 			validate: func(t *testing.T, result *models.Server) {
 				assert.NotNil(t, result)
 				assert.Equal(t, testServerID, result.ID)
+				assert.Equal(t, testTeamID, result.TeamID)
 				assert.Equal(t, "Updated Server", result.Name)
 				assert.Equal(t, "newadmin", result.Username)
 				assert.Empty(t, result.Password) // пароль не возвращается
@@ -250,17 +351,17 @@ func TestEditServer(t *testing.T) {
 			userID:   testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Ожидаем SELECT для получения текущего пароля
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT password FROM servers WHERE id = $1 AND user_id = $2`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT password, password_key_id FROM servers WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`)).
 					WithArgs(testServerID, testUserID).
-					WillReturnRows(sqlmock.NewRows([]string{"password"}).
-						AddRow("encrypted_old_password"))
+					WillReturnRows(sqlmock.NewRows([]string{"password", "password_key_id"}).
+						AddRow("encrypted_old_password", 2))
 
 				// Ожидаем UPDATE запрос
 				mock.ExpectQuery(regexp.QuoteMeta(editServerQuery)).
 					WithArgs("Updated Server No Pass", "admin", "192.168.1.201",
-						"encrypted_old_password", testServerID, testUserID).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "address", "fingerprint", "created_at"}).
-						AddRow(testServerID, "Updated Server No Pass", "admin", "192.168.1.201", testFingerprint, fixedTime))
+						"encrypted_old_password", testServerID, testUserID, nil, nil, nil, nil, nil, nil, nil, 2, nil).
+					WillReturnRows(sqlmock.NewRows(editServerColumns).
+						AddRow(testServerID, testTeamID, "Updated Server No Pass", "admin", "192.168.1.201", testFingerprint, fixedTime, nil, nil, nil, nil, nil, nil, nil, nil))
 			},
 			expectError: false,
 			validate: func(t *testing.T, result *models.Server) {
@@ -269,6 +370,33 @@ func TestEditServer(t *testing.T) {
 				assert.Equal(t, "Updated Server No Pass", result.Name)
 			},
 		},
+		{
+			name: "привязка сервера к профилю учетных данных",
+			editedServer: &models.Server{
+				Name:                "Profile Server",
+				Address:             "192.168.1.204",
+				Username:            `CORP\monitor`,
+				Password:            "profile-password",
+				CredentialProfileID: &profileID,
+			},
+			serverID: testServerID,
+			userID:   testUserID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// собственные логин и пароль сервера очищаются, текущий пароль не запрашивается
+				mock.ExpectQuery(regexp.QuoteMeta(editServerQuery)).
+					WithArgs("Profile Server", "", "192.168.1.204",
+						"", testServerID, testUserID, nil, nil, nil, nil, nil, nil, profileID, nil, nil).
+					WillReturnRows(sqlmock.NewRows(editServerColumns).
+						AddRow(testServerID, testTeamID, "Profile Server", `CORP\monitor`, "192.168.1.204", testFingerprint, fixedTime, nil, nil, nil, nil, nil, nil, profileID, nil))
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.Server) {
+				require.NotNil(t, result)
+				assert.Equal(t, `CORP\monitor`, result.Username)
+				require.NotNil(t, result.CredentialProfileID)
+				assert.Equal(t, profileID, *result.CredentialProfileID)
+			},
+		},
 		{
 			name: "ошибка - сервер не найден",
 			editedServer: &models.Server{
@@ -282,7 +410,7 @@ func TestEditServer(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(regexp.QuoteMeta(editServerQuery)).
 					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
-						sqlmock.AnyArg(), testServerID, testUserID).
+						sqlmock.AnyArg(), testServerID, testUserID, nil, nil, nil, nil, nil, nil, nil, 1, nil).
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectError: true,
@@ -305,7 +433,7 @@ func TestEditServer(t *testing.T) {
 			serverID: testServerID,
 			userID:   testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT password FROM servers WHERE id = $1 AND user_id = $2`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT password, password_key_id FROM servers WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`)).
 					WithArgs(testServerID, testUserID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -329,8 +457,8 @@ func TestEditServer(t *testing.T) {
 			tt.mockSetup(mock)
 
 			pg := &PgStorage{
-				DB:     db,
-				AESKey: aesKey,
+				DB:   db,
+				Keys: testKeyRing(t, aesKey),
 			}
 
 			result, err := pg.EditServer(context.Background(), tt.editedServer, tt.serverID, tt.userID)
@@ -356,7 +484,7 @@ func TestDelServer(t *testing.T) {
 	testServerID := int64(100)
 
 	deleteServerQuery := `DELETE FROM servers 
-             			  WHERE id = $1 AND user_id = $2`
+             			  WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	tests := []struct {
 		name           string                     // название теста
@@ -439,11 +567,13 @@ func TestGetServer(t *testing.T) {
 	fixedTime := time.Now()
 	testUserID := "any-id-user-1"
 	testServerID := int64(100)
+	testTeamID := int64(10)
 	testFingerprint := uuid.New()
 
-	getServerQuery := `SELECT id, name, address, username, fingerprint, created_at 
-					   FROM servers 
-              		   WHERE id = $1 AND user_id = $2`
+	getServerQuery := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, s.fingerprint, s.created_at,
+					   s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+					   FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+              		   WHERE s.id = $1 AND s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	tests := []struct {
 		name           string                                    // название теста
@@ -459,8 +589,8 @@ func TestGetServer(t *testing.T) {
 			serverID: testServerID,
 			userID:   testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "address", "username", "fingerprint", "created_at"}).
-					AddRow(testServerID, "Test Server", "192.168.1.100", "admin", testFingerprint, fixedTime)
+				rows := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "secret_path"}).
+					AddRow(testServerID, testTeamID, "Test Server", "192.168.1.100", "admin", testFingerprint, fixedTime, nil, nil, nil, nil, nil, nil, nil, nil)
 				mock.ExpectQuery(regexp.QuoteMeta(getServerQuery)).
 					WithArgs(testServerID, testUserID).
 					WillReturnRows(rows)
@@ -469,6 +599,7 @@ func TestGetServer(t *testing.T) {
 			validate: func(t *testing.T, result *models.Server) {
 				assert.NotNil(t, result)
 				assert.Equal(t, testServerID, result.ID)
+				assert.Equal(t, testTeamID, result.TeamID)
 				assert.Equal(t, "Test Server", result.Name)
 				assert.Equal(t, "192.168.1.100", result.Address)
 				assert.Equal(t, "admin", result.Username)
@@ -476,6 +607,33 @@ func TestGetServer(t *testing.T) {
 				assert.Equal(t, fixedTime, result.CreatedAt)
 			},
 		},
+		{
+			name:     "успешное получение сервера с настройками WinRM",
+			serverID: testServerID,
+			userID:   testUserID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "secret_path"}).
+					AddRow(testServerID, testTeamID, "Test Server", "192.168.1.100", "admin", testFingerprint, fixedTime, int64(5986), true, false, int64(30), nil, "ntlm", nil, nil)
+				mock.ExpectQuery(regexp.QuoteMeta(getServerQuery)).
+					WithArgs(testServerID, testUserID).
+					WillReturnRows(rows)
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.Server) {
+				require.NotNil(t, result)
+				require.NotNil(t, result.WinRM.Port)
+				assert.Equal(t, 5986, *result.WinRM.Port)
+				require.NotNil(t, result.WinRM.HTTPS)
+				assert.True(t, *result.WinRM.HTTPS)
+				require.NotNil(t, result.WinRM.Insecure)
+				assert.False(t, *result.WinRM.Insecure)
+				require.NotNil(t, result.WinRM.Timeout)
+				assert.Equal(t, 30, *result.WinRM.Timeout)
+				assert.Nil(t, result.WinRM.ConnectTimeout)
+				require.NotNil(t, result.WinRM.Auth)
+				assert.Equal(t, models.WinRMAuthNTLM, *result.WinRM.Auth)
+			},
+		},
 		{
 			name:     "ошибка - сервер не найден",
 			serverID: testServerID,
@@ -545,12 +703,15 @@ func TestGetServerWithPassword(t *testing.T) {
 	fixedTime := time.Now()
 	testUserID := "any-id-user-1"
 	testServerID := int64(100)
+	testTeamID := int64(10)
 	// тестовый AES ключ 32 байта
 	aesKey := []byte("12345678901234567890123456789012")
 
-	getUserDataQuery := `SELECT id, name, address, username, password, fingerprint, created_at 
-			  FROM servers 
-              WHERE id = $1 AND user_id = $2`
+	getUserDataQuery := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, COALESCE(cp.password, s.password), s.fingerprint, s.created_at,
+			  s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, cp.auth,
+			  CASE WHEN cp.id IS NULL THEN s.password_key_id ELSE cp.password_key_id END, s.secret_path
+			  FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+              WHERE s.id = $1 AND s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	tests := []struct {
 		name           string                                    // название теста
@@ -569,8 +730,8 @@ func TestGetServerWithPassword(t *testing.T) {
 			dbPassword: "dGVzdFBhc3M=", // base64 testPass -> utils.DecryptAES не поддерживает base64, вызовет ошибку
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// возвращаем данные с не пустым паролем
-				row := sqlmock.NewRows([]string{"id", "name", "address", "username", "password", "fingerprint", "created_at"}).
-					AddRow(testServerID, "TestSrv", "addr", "user", "invalidcipher", uuid.New(), fixedTime)
+				row := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "password", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "auth", "password_key_id", "secret_path"}).
+					AddRow(testServerID, testTeamID, "TestSrv", "addr", "user", "invalidcipher", uuid.New(), fixedTime, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 				mock.ExpectQuery(regexp.QuoteMeta(getUserDataQuery)).
 					WithArgs(testServerID, testUserID).
 					WillReturnRows(row)
@@ -586,8 +747,8 @@ func TestGetServerWithPassword(t *testing.T) {
 			userID:     testUserID,
 			dbPassword: "", // пустой пароль
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				row := sqlmock.NewRows([]string{"id", "name", "address", "username", "password", "fingerprint", "created_at"}).
-					AddRow(testServerID, "TestSrv", "addr", "user", "", uuid.New(), fixedTime)
+				row := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "password", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "auth", "password_key_id", "secret_path"}).
+					AddRow(testServerID, testTeamID, "TestSrv", "addr", "user", "", uuid.New(), fixedTime, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 				mock.ExpectQuery(regexp.QuoteMeta(getUserDataQuery)).
 					WithArgs(testServerID, testUserID).
 					WillReturnRows(row)
@@ -638,7 +799,7 @@ func TestGetServerWithPassword(t *testing.T) {
 
 			tt.mockSetup(mock)
 
-			pg := &PgStorage{DB: db, AESKey: aesKey}
+			pg := &PgStorage{DB: db, Keys: testKeyRing(t, aesKey)}
 
 			result, err := pg.GetServerWithPassword(context.Background(), tt.serverID, tt.userID)
 
@@ -667,9 +828,11 @@ func TestListServers(t *testing.T) {
 	fp1 := uuid.New()
 	fp2 := uuid.New()
 
-	listServersQuery := `SELECT id, name, address, username, fingerprint, created_at 
-                         FROM servers WHERE user_id = $1
-            			 ORDER BY name`
+	listServersQuery := `SELECT s.id, s.team_id, s.name, s.address, ` + serverLoginColumn + `, s.fingerprint, s.created_at,
+                         s.winrm_port, s.winrm_https, s.winrm_insecure, s.winrm_timeout, s.winrm_connect_timeout, s.winrm_auth, s.credential_profile_id, s.secret_path
+                         FROM servers s LEFT JOIN credential_profiles cp ON cp.id = s.credential_profile_id
+                         WHERE s.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
+            			 ORDER BY s.name`
 
 	tests := []struct {
 		name           string                                      // название теста
@@ -683,9 +846,9 @@ func TestListServers(t *testing.T) {
 			name:   "успешное получение списка серверов",
 			userID: testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "address", "username", "fingerprint", "created_at"}).
-					AddRow(1, "Server 1", "192.168.1.1", "admin1", fp1, fixedTime).
-					AddRow(2, "Server 2", "192.168.1.2", "admin2", fp2, fixedTime)
+				rows := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "secret_path"}).
+					AddRow(1, int64(10), "Server 1", "192.168.1.1", "admin1", fp1, fixedTime, nil, nil, nil, nil, nil, nil, nil, nil).
+					AddRow(2, int64(10), "Server 2", "192.168.1.2", "admin2", fp2, fixedTime, nil, nil, nil, nil, nil, nil, nil, "swsm/servers/web-02")
 				mock.ExpectQuery(regexp.QuoteMeta(listServersQuery)).
 					WithArgs(testUserID).
 					WillReturnRows(rows)
@@ -696,13 +859,16 @@ func TestListServers(t *testing.T) {
 				assert.Len(t, result, 2)
 				assert.Equal(t, "Server 1", result[0].Name)
 				assert.Equal(t, "Server 2", result[1].Name)
+				assert.Nil(t, result[0].SecretPath)
+				require.NotNil(t, result[1].SecretPath)
+				assert.Equal(t, "swsm/servers/web-02", *result[1].SecretPath)
 			},
 		},
 		{
 			name:   "пустой список серверов",
 			userID: testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "address", "username", "fingerprint", "created_at"})
+				rows := sqlmock.NewRows([]string{"id", "team_id", "name", "address", "username", "fingerprint", "created_at", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth", "credential_profile_id", "secret_path"})
 				mock.ExpectQuery(regexp.QuoteMeta(listServersQuery)).
 					WithArgs(testUserID).
 					WillReturnRows(rows)
@@ -765,13 +931,13 @@ func TestGetService(t *testing.T) {
 	testServerID := int64(100)
 	testServiceID := int64(10)
 
-	getServerQuery := `SELECT id, displayed_name, service_name, status, created_at, updated_at 
+	getServerQuery := `SELECT id, displayed_name, service_name, status, critical, created_at, updated_at 
                        FROM services 
                        WHERE id = $1 
                           AND server_id = $2 
                           AND server_id IN (
                                SELECT id FROM servers 
-                               WHERE user_id = $3
+                               WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $3)
                           )`
 
 	tests := []struct {
@@ -790,8 +956,8 @@ func TestGetService(t *testing.T) {
 			serviceID: testServiceID,
 			userID:    testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "created_at", "updated_at"}).
-					AddRow(testServiceID, "Web Server", "nginx", "Running", fixedTime, fixedTime)
+				rows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "critical", "created_at", "updated_at"}).
+					AddRow(testServiceID, "Web Server", "nginx", "Running", true, fixedTime, fixedTime)
 				mock.ExpectQuery(regexp.QuoteMeta(getServerQuery)).
 					WithArgs(testServiceID, testServerID, testUserID).
 					WillReturnRows(rows)
@@ -803,6 +969,7 @@ func TestGetService(t *testing.T) {
 				assert.Equal(t, "Web Server", result.DisplayedName)
 				assert.Equal(t, "nginx", result.ServiceName)
 				assert.Equal(t, "Running", result.Status)
+				assert.True(t, result.Critical)
 				assert.Equal(t, fixedTime, result.CreatedAt)
 				assert.Equal(t, fixedTime, result.UpdatedAt)
 			},
@@ -870,8 +1037,8 @@ func TestGetService(t *testing.T) {
 			userID:    testUserID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Возвращаем строку вместо int64 для ID
-				rows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "created_at", "updated_at"}).
-					AddRow("invalid_id", "Web Server", "nginx", "Running", fixedTime, fixedTime)
+				rows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "critical", "created_at", "updated_at"}).
+					AddRow("invalid_id", "Web Server", "nginx", "Running", false, fixedTime, fixedTime)
 				mock.ExpectQuery(regexp.QuoteMeta(getServerQuery)).
 					WithArgs(testServiceID, testServerID, testUserID).
 					WillReturnRows(rows)
@@ -921,10 +1088,10 @@ func TestListServices(t *testing.T) {
 
 	checkOwnershipQuery := `SELECT EXISTS(
 						  	SELECT 1 FROM servers
-                            WHERE id = $1 AND user_id = $2
+                            WHERE id = $1 AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)
                           )`
 
-	getServicesQuery := `SELECT id, displayed_name, service_name, status, created_at, updated_at
+	getServicesQuery := `SELECT id, displayed_name, service_name, status, critical, created_at, updated_at
                          FROM services 
                          WHERE server_id = $1
                          ORDER BY service_name`
@@ -950,10 +1117,10 @@ func TestListServices(t *testing.T) {
 					WillReturnRows(ownershipRows)
 
 				// ожидаем запрос списка служб
-				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "created_at", "updated_at"}).
-					AddRow(1, "Application Service", "AppService", "Running", fixedTime, fixedTime).
-					AddRow(2, "Database Service", "DbService", "Stopped", fixedTime, fixedTime).
-					AddRow(3, "Web Service", "WebService", "Running", fixedTime, fixedTime)
+				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "critical", "created_at", "updated_at"}).
+					AddRow(1, "Application Service", "AppService", "Running", false, fixedTime, fixedTime).
+					AddRow(2, "Database Service", "DbService", "Stopped", false, fixedTime, fixedTime).
+					AddRow(3, "Web Service", "WebService", "Running", false, fixedTime, fixedTime)
 				mock.ExpectQuery(regexp.QuoteMeta(getServicesQuery)).
 					WithArgs(testServerID).
 					WillReturnRows(servicesRows)
@@ -988,7 +1155,7 @@ func TestListServices(t *testing.T) {
 					WillReturnRows(ownershipRows)
 
 				// ожидаем запрос списка служб - пустой результат
-				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "created_at", "updated_at"})
+				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "critical", "created_at", "updated_at"})
 				mock.ExpectQuery(regexp.QuoteMeta(getServicesQuery)).
 					WithArgs(testServerID).
 					WillReturnRows(servicesRows)
@@ -1072,8 +1239,8 @@ func TestListServices(t *testing.T) {
 					WillReturnRows(ownershipRows)
 
 				// возвращаем строку с неправильным типом данных
-				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "created_at", "updated_at"}).
-					AddRow("invalid_id", "Service", "SvcName", "Running", fixedTime, fixedTime)
+				servicesRows := sqlmock.NewRows([]string{"id", "displayed_name", "service_name", "status", "critical", "created_at", "updated_at"}).
+					AddRow("invalid_id", "Service", "SvcName", "Running", false, fixedTime, fixedTime)
 				mock.ExpectQuery(regexp.QuoteMeta(getServicesQuery)).
 					WithArgs(testServerID).
 					WillReturnRows(servicesRows)
@@ -1117,7 +1284,7 @@ func TestListServices(t *testing.T) {
 
 // TestCreateUser Проверяет создание пользователя.
 func TestCreateUser(t *testing.T) {
-	createUserQuery := `INSERT INTO users (id, login) VALUES ($1, $2)`
+	createUserQuery := `INSERT INTO users (id, login, email) VALUES ($1, $2, NULLIF($3, ''))`
 
 	pgErr := &pgconn.PgError{
 		Code: "23505",
@@ -1138,7 +1305,21 @@ func TestCreateUser(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(regexp.QuoteMeta(createUserQuery)).
-					WithArgs("any-id-user-1", "testuser").
+					WithArgs("any-id-user-1", "testuser", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			expectError: false,
+		},
+		{
+			name: "успешное создание пользователя с email",
+			user: &models.User{
+				ID:    "any-id-user-1",
+				Login: "testuser",
+				Email: "testuser@example.com",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(createUserQuery)).
+					WithArgs("any-id-user-1", "testuser", "testuser@example.com").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectError: false,
@@ -1151,7 +1332,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(regexp.QuoteMeta(createUserQuery)).
-					WithArgs("any-id-user-1", "existinguser").
+					WithArgs("any-id-user-1", "existinguser", "").
 					WillReturnError(pgErr)
 			},
 			expectError: true,
@@ -1169,7 +1350,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(regexp.QuoteMeta(createUserQuery)).
-					WithArgs("existing-any-id-user-1", "user").
+					WithArgs("existing-any-id-user-1", "user", "").
 					WillReturnError(pgErr)
 			},
 			expectError: true,
@@ -1187,7 +1368,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(regexp.QuoteMeta(createUserQuery)).
-					WithArgs("any-id-user-1", "testuser").
+					WithArgs("any-id-user-1", "testuser", "").
 					WillReturnError(errors.New("database error"))
 			},
 			expectError: true,
@@ -1223,86 +1404,142 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
-//// TestGetUser Проверяет получение пользователя по логину и паролю.
-//func TestGetUser(t *testing.T) {
-//	getUserQuery := `SELECT id, login FROM users WHERE id = $1`
-//
-//	tests := []struct {
-//		name           string                                  // название теста
-//		user           *models.User                            // входные данные
-//		mockSetup      func(mock sqlmock.Sqlmock)              // настройка мока
-//		expectError    bool                                    // ожидается ли ошибка
-//		errorAssertion func(t *testing.T, err error)           // дополнительная проверка ошибки
-//		validate       func(t *testing.T, result *models.User) // валидация результата
-//	}{
-//		{
-//			name: "успешная авторизация пользователя",
-//			user: &models.User{
-//				ID:    "any-id-user-1",
-//				Login: "testuser",
-//			},
-//			mockSetup: func(mock sqlmock.Sqlmock) {
-//				rows := sqlmock.NewRows([]string{"id", "login"}).
-//					AddRow("any-id-user-1", "testuser")
-//				mock.ExpectQuery(regexp.QuoteMeta(getUserQuery)).
-//					WithArgs("any-id-user-1").
-//					WillReturnRows(rows)
-//			},
-//			expectError: false,
-//			validate: func(t *testing.T, result *models.User) {
-//				assert.NotNil(t, result)
-//				assert.Equal(t, "any-id-user-1", result.ID)
-//				assert.Equal(t, "testuser", result.Login)
-//			},
-//		},
-//		{
-//			name: "ошибка - пользователь не найден",
-//			user: &models.User{
-//				ID:    "non-existent-user-id",
-//				Login: "nonexistent",
-//			},
-//			mockSetup: func(mock sqlmock.Sqlmock) {
-//				mock.ExpectQuery(regexp.QuoteMeta(getUserQuery)).
-//					WithArgs("non-existent-user-id").
-//					WillReturnError(sql.ErrNoRows)
-//			},
-//			expectError: true,
-//			errorAssertion: func(t *testing.T, err error) {
-//				var userNotFoundErr *errs.ErrUserIDNotFound
-//				assert.True(t, errors.As(err, &userNotFoundErr), "ошибка должна быть типа ErrUserIDNotFound")
-//			},
-//			validate: func(t *testing.T, result *models.User) {
-//				assert.Nil(t, result)
-//			},
-//		},
-//	}
-//
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			db, mock, err := sqlmock.New()
-//			require.NoError(t, err)
-//			defer db.Close()
-//
-//			tt.mockSetup(mock)
-//
-//			pg := &PgStorage{DB: db}
-//
-//			result, err := pg.GetUser(context.Background(), tt.user)
-//
-//			if tt.expectError {
-//				assert.Error(t, err)
-//				if tt.errorAssertion != nil {
-//					tt.errorAssertion(t, err)
-//				}
-//			} else {
-//				assert.NoError(t, err)
-//			}
-//
-//			tt.validate(t, result)
-//			assert.NoError(t, mock.ExpectationsWereMet())
-//		})
-//	}
-//}
+// TestGetUser Проверяет получение пользователя по ID.
+func TestGetUser(t *testing.T) {
+	getUserQuery := `SELECT id, login, COALESCE(email, ''), disabled, COALESCE(role, '') FROM users WHERE id = $1`
+
+	tests := []struct {
+		name           string                                  // название теста
+		userID         string                                  // входные данные
+		mockSetup      func(mock sqlmock.Sqlmock)              // настройка мока
+		expectError    bool                                    // ожидается ли ошибка
+		errorAssertion func(t *testing.T, err error)           // дополнительная проверка ошибки
+		validate       func(t *testing.T, result *models.User) // валидация результата
+	}{
+		{
+			name:   "успешное получение пользователя",
+			userID: "any-id-user-1",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "login", "email", "disabled", "role"}).
+					AddRow("any-id-user-1", "testuser", "testuser@example.com", true, "operator")
+				mock.ExpectQuery(regexp.QuoteMeta(getUserQuery)).
+					WithArgs("any-id-user-1").
+					WillReturnRows(rows)
+			},
+			expectError: false,
+			validate: func(t *testing.T, result *models.User) {
+				assert.NotNil(t, result)
+				assert.Equal(t, "any-id-user-1", result.ID)
+				assert.Equal(t, "testuser", result.Login)
+				assert.True(t, result.Disabled)
+				assert.Equal(t, models.RoleOperator, result.Role)
+			},
+		},
+		{
+			name:   "ошибка - пользователь не найден",
+			userID: "non-existent-user-id",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(getUserQuery)).
+					WithArgs("non-existent-user-id").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectError: true,
+			errorAssertion: func(t *testing.T, err error) {
+				var userNotFoundErr *errs.ErrUserIDNotFound
+				assert.True(t, errors.As(err, &userNotFoundErr), "ошибка должна быть типа ErrUserIDNotFound")
+			},
+			validate: func(t *testing.T, result *models.User) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			result, err := pg.GetUser(context.Background(), tt.userID)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorAssertion != nil {
+					tt.errorAssertion(t, err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validate(t, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestUpdateUser Проверяет обновление логина, email и признака отключения пользователя.
+func TestUpdateUser(t *testing.T) {
+	updateUserQuery := `UPDATE users SET login = COALESCE(NULLIF($2, ''), login), email = NULLIF($3, ''), disabled = $4 WHERE id = $1`
+	user := &models.User{ID: "user-1", Login: "renamed", Email: "renamed@example.com", Disabled: true}
+
+	tests := []struct {
+		name           string
+		mockSetup      func(mock sqlmock.Sqlmock)
+		errorAssertion func(t *testing.T, err error)
+	}{
+		{
+			name: "пользователь обновлен",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(updateUserQuery)).
+					WithArgs("user-1", "renamed", "renamed@example.com", true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			errorAssertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "пользователь не найден",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(updateUserQuery)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			errorAssertion: func(t *testing.T, err error) {
+				var userNotFoundErr *errs.ErrUserIDNotFound
+				assert.True(t, errors.As(err, &userNotFoundErr))
+			},
+		},
+		{
+			name: "логин занят другим пользователем",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(updateUserQuery)).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			errorAssertion: func(t *testing.T, err error) {
+				var userExistsErr *errs.ErrUserAlreadyExists
+				assert.True(t, errors.As(err, &userExistsErr))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			tt.errorAssertion(t, pg.UpdateUser(context.Background(), user))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
 
 // TestUserExists Проверяет, существует ли пользователь с данным ID в БД.
 func TestPgStorage_UserExists(t *testing.T) {
@@ -1381,7 +1618,7 @@ func TestAddService(t *testing.T) {
 	fingerprintQuery := `SELECT fingerprint 
                     	 FROM servers 
                     	 WHERE id = $1 
-                    	   AND user_id = $2`
+                    	   AND team_id IN (SELECT team_id FROM team_members WHERE user_id = $2)`
 
 	statusLookupQuery := `SELECT status, updated_at
                           FROM services
@@ -1601,7 +1838,7 @@ func TestDelService(t *testing.T) {
                  	AND server_id = $2
                 	AND server_id IN (
                         SELECT id FROM servers 
-                        WHERE user_id = $3
+                        WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $3)
                  )`
 
 	tests := []struct {
@@ -1991,7 +2228,7 @@ func TestBatchChangeServiceStatus(t *testing.T) {
 
 // TestListUsers Проверяет получение списка всех пользователей.
 func TestListUsers(t *testing.T) {
-	listUsersQuery := `SELECT id, login FROM users`
+	listUsersQuery := `SELECT id, login, COALESCE(email, ''), disabled FROM users`
 
 	tests := []struct {
 		name           string                                    // название теста
@@ -2003,10 +2240,10 @@ func TestListUsers(t *testing.T) {
 		{
 			name: "успешное получение списка пользователей",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "login"}).
-					AddRow(1, "user1").
-					AddRow(2, "user2").
-					AddRow(3, "user3")
+				rows := sqlmock.NewRows([]string{"id", "login", "email", "disabled"}).
+					AddRow(1, "user1", "user1@example.com", false).
+					AddRow(2, "user2", "", true).
+					AddRow(3, "user3", "", false)
 				mock.ExpectQuery(regexp.QuoteMeta(listUsersQuery)).
 					WillReturnRows(rows)
 			},
@@ -2017,12 +2254,15 @@ func TestListUsers(t *testing.T) {
 				assert.Equal(t, "user1", result[0].Login)
 				assert.Equal(t, "user2", result[1].Login)
 				assert.Equal(t, "user3", result[2].Login)
+				assert.Equal(t, "user1@example.com", result[0].Email)
+				assert.Empty(t, result[1].Email)
+				assert.True(t, result[1].Disabled)
 			},
 		},
 		{
 			name: "пустой список пользователей",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "login"})
+				rows := sqlmock.NewRows([]string{"id", "login", "email", "disabled"})
 				mock.ExpectQuery(regexp.QuoteMeta(listUsersQuery)).
 					WillReturnRows(rows)
 			},
@@ -2081,7 +2321,7 @@ func TestGetUserServiceStatuses(t *testing.T) {
 
 	getUserServiceStatusesQuery := `SELECT id, server_id, status, updated_at 
                           			FROM services
-                          			WHERE server_id IN (SELECT id FROM servers WHERE user_id = $1)`
+                          			WHERE server_id IN (SELECT id FROM servers WHERE team_id IN (SELECT team_id FROM team_members WHERE user_id = $1))`
 
 	tests := []struct {
 		name           string                                             // название теста
@@ -2302,7 +2542,9 @@ func TestClose(t *testing.T) {
 
 // TestListServersAddresses Проверяет корректность работы метода PgStorage.ListServersAddresses.
 func TestListServersAddresses(t *testing.T) {
-	query := `SELECT id, address, user_id FROM servers ORDER BY id`
+	query := `SELECT id, address, COALESCE(user_id, ''), team_id,
+			  winrm_port, winrm_https, winrm_insecure, winrm_timeout, winrm_connect_timeout, winrm_auth
+			  FROM servers ORDER BY id`
 
 	tests := []struct {
 		name           string
@@ -2316,10 +2558,10 @@ func TestListServersAddresses(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// порядок колонок должен соответствовать порядку Scan:
 				// Scan(&server.ServerID, &server.UserID, &server.Address)
-				rows := sqlmock.NewRows([]string{"id", "address", "user_id"}).
-					AddRow(int64(1), "10.0.0.1", "any-id-user-10").
-					AddRow(int64(2), "10.0.0.2", "any-id-user-20").
-					AddRow(int64(3), "10.0.0.3", "any-id-user-30")
+				rows := sqlmock.NewRows([]string{"id", "address", "user_id", "team_id", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth"}).
+					AddRow(int64(1), "10.0.0.1", "any-id-user-10", int64(10), nil, nil, nil, nil, nil, nil).
+					AddRow(int64(2), "10.0.0.2", "any-id-user-20", int64(20), nil, nil, nil, nil, nil, nil).
+					AddRow(int64(3), "10.0.0.3", "any-id-user-30", int64(30), nil, nil, nil, nil, nil, nil)
 
 				mock.ExpectQuery(regexp.QuoteMeta(query)).
 					WillReturnRows(rows)
@@ -2331,6 +2573,7 @@ func TestListServersAddresses(t *testing.T) {
 
 				assert.Equal(t, int64(1), result[0].ServerID)
 				assert.Equal(t, "any-id-user-10", result[0].UserID)
+				assert.Equal(t, int64(10), result[0].TeamID)
 				assert.Equal(t, "10.0.0.1", result[0].Address)
 
 				assert.Equal(t, int64(2), result[1].ServerID)
@@ -2346,7 +2589,7 @@ func TestListServersAddresses(t *testing.T) {
 			name: "пустой список серверов",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// те же три колонки, но без строк
-				rows := sqlmock.NewRows([]string{"id", "user_id", "address"})
+				rows := sqlmock.NewRows([]string{"id", "address", "user_id", "team_id", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth"})
 				mock.ExpectQuery(regexp.QuoteMeta(query)).
 					WillReturnRows(rows)
 			},
@@ -2374,8 +2617,8 @@ func TestListServersAddresses(t *testing.T) {
 			name: "ошибка парсинга строки (неправильный тип id)",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// три колонки, но id как строка — Scan в int64 упадет
-				rows := sqlmock.NewRows([]string{"id", "user_id", "address"}).
-					AddRow("not-int", int64(10), "10.0.0.1")
+				rows := sqlmock.NewRows([]string{"id", "address", "user_id", "team_id", "winrm_port", "winrm_https", "winrm_insecure", "winrm_timeout", "winrm_connect_timeout", "winrm_auth"}).
+					AddRow("not-int", int64(10), "10.0.0.1", int64(1), nil, nil, nil, nil, nil, nil)
 				mock.ExpectQuery(regexp.QuoteMeta(query)).
 					WillReturnRows(rows)
 			},
@@ -2419,3 +2662,75 @@ func TestListServersAddresses(t *testing.T) {
 		})
 	}
 }
+
+// TestListServicesStates Проверяет получение текущих статусов всех служб.
+func TestListServicesStates(t *testing.T) {
+	query := `SELECT sv.id, sv.server_id, s.team_id, sv.service_name, sv.status
+			  FROM services sv`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+		validate    func(t *testing.T, result []*models.ServiceState)
+	}{
+		{
+			name: "успешное получение статусов",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "server_id", "team_id", "service_name", "status"}).
+					AddRow(int64(5), int64(1), int64(10), "spooler", "Работает").
+					AddRow(int64(6), int64(2), int64(20), "bits", "Остановлена")
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
+			},
+			validate: func(t *testing.T, result []*models.ServiceState) {
+				require.Len(t, result, 2)
+				assert.Equal(t, &models.ServiceState{ServiceID: 5, ServerID: 1, TeamID: 10, ServiceName: "spooler", Status: "Работает"}, result[0])
+				assert.Equal(t, int64(20), result[1].TeamID)
+			},
+		},
+		{
+			name: "ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("database error"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ServiceState) {
+				assert.Nil(t, result)
+			},
+		},
+		{
+			name: "ошибка сканирования строки",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "server_id", "user_id", "service_name", "status"}).
+					AddRow("not-int", int64(1), "any-id-user-1", "spooler", "Работает")
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
+			},
+			expectError: true,
+			validate: func(t *testing.T, result []*models.ServiceState) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+
+			result, err := pg.ListServicesStates(context.Background())
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validate(t, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
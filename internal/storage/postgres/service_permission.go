@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// ListServicePermissions Получение правил доступа к управлению службой.
+func (pg *PgStorage) ListServicePermissions(ctx context.Context, serviceID int64) ([]*models.ServicePermission, error) {
+	query := `SELECT id, service_id, COALESCE(user_id, ''), COALESCE(role, ''), can_start, can_stop, can_restart, created_at
+			  FROM service_permissions
+			  WHERE service_id = $1
+			  ORDER BY id`
+
+	return pg.listServicePermissions(ctx, query, serviceID)
+}
+
+// ListServerServicePermissions Получение правил доступа ко всем службам сервера.
+func (pg *PgStorage) ListServerServicePermissions(ctx context.Context, serverID int64) ([]*models.ServicePermission, error) {
+	query := `SELECT p.id, p.service_id, COALESCE(p.user_id, ''), COALESCE(p.role, ''), p.can_start, p.can_stop, p.can_restart, p.created_at
+			  FROM service_permissions p
+			  JOIN services s ON s.id = p.service_id
+			  WHERE s.server_id = $1
+			  ORDER BY p.service_id, p.id`
+
+	return pg.listServicePermissions(ctx, query, serverID)
+}
+
+// SetServicePermission Создание или обновление правила доступа к службе для пользователя или роли.
+func (pg *PgStorage) SetServicePermission(ctx context.Context, permission models.ServicePermission) (*models.ServicePermission, error) {
+	var (
+		query   string
+		subject any
+	)
+
+	// у каждого субъекта (пользователя или роли) не больше одного правила на службу
+	if permission.UserID != "" {
+		query = `INSERT INTO service_permissions (service_id, user_id, can_start, can_stop, can_restart)
+				 VALUES ($1, $2, $3, $4, $5)
+				 ON CONFLICT (service_id, user_id) WHERE user_id IS NOT NULL
+				 DO UPDATE SET can_start = EXCLUDED.can_start, can_stop = EXCLUDED.can_stop, can_restart = EXCLUDED.can_restart
+				 RETURNING id, created_at`
+		subject = permission.UserID
+	} else {
+		query = `INSERT INTO service_permissions (service_id, role, can_start, can_stop, can_restart)
+				 VALUES ($1, $2, $3, $4, $5)
+				 ON CONFLICT (service_id, role) WHERE role IS NOT NULL
+				 DO UPDATE SET can_start = EXCLUDED.can_start, can_stop = EXCLUDED.can_stop, can_restart = EXCLUDED.can_restart
+				 RETURNING id, created_at`
+		subject = permission.Role
+	}
+
+	err := pg.DB.QueryRowContext(ctx, query, permission.ServiceID, subject, permission.Start, permission.Stop, permission.Restart).
+		Scan(&permission.ID, &permission.CreatedAt)
+	if err != nil {
+		logger.Log.Error("Ошибка при сохранении правила доступа к службе", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при сохранении правила доступа к службе: %w", err)
+	}
+
+	return &permission, nil
+}
+
+// DelServicePermission Удаление правила доступа к службе.
+func (pg *PgStorage) DelServicePermission(ctx context.Context, serviceID int64, permissionID int64) error {
+	query := `DELETE FROM service_permissions WHERE id = $1 AND service_id = $2`
+
+	result, err := pg.DB.ExecContext(ctx, query, permissionID, serviceID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrServicePermissionNotFound(serviceID, permissionID, fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
+// listServicePermissions Выполняет выборку правил доступа к службам.
+func (pg *PgStorage) listServicePermissions(ctx context.Context, query string, args ...any) ([]*models.ServicePermission, error) {
+	rows, err := pg.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении правил доступа к службам", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении правил доступа к службам: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []*models.ServicePermission
+
+	for rows.Next() {
+		var p models.ServicePermission
+
+		err = rows.Scan(&p.ID, &p.ServiceID, &p.UserID, &p.Role, &p.Start, &p.Stop, &p.Restart, &p.CreatedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования правила доступа к службе", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		permissions = append(permissions, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Log.Error("Ошибка при обработке строк правил доступа к службам", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return permissions, nil
+}
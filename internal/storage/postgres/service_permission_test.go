@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestSetServicePermission Проверяет сохранение правил доступа к службе для пользователя и для роли.
+func TestSetServicePermission(t *testing.T) {
+	fixedTime := time.Now()
+
+	tests := []struct {
+		name       string
+		permission models.ServicePermission
+		query      string
+		subject    any
+	}{
+		{
+			name:       "правило для пользователя",
+			permission: models.ServicePermission{ServiceID: 10, UserID: "user-2", Restart: true},
+			query:      `ON CONFLICT (service_id, user_id) WHERE user_id IS NOT NULL`,
+			subject:    "user-2",
+		},
+		{
+			name:       "правило для роли",
+			permission: models.ServicePermission{ServiceID: 10, Role: models.RoleAdmin, Start: true, Stop: true, Restart: true},
+			query:      `ON CONFLICT (service_id, role) WHERE role IS NOT NULL`,
+			subject:    models.RoleAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+				WithArgs(int64(10), tt.subject, tt.permission.Start, tt.permission.Stop, tt.permission.Restart).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(3), fixedTime))
+
+			pg := &PgStorage{DB: db}
+			saved, err := pg.SetServicePermission(context.Background(), tt.permission)
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), saved.ID)
+			assert.Equal(t, fixedTime, saved.CreatedAt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListServerServicePermissions Проверяет получение правил доступа ко всем службам сервера.
+func TestListServerServicePermissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	fixedTime := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT p.id, p.service_id, COALESCE(p.user_id, ''), COALESCE(p.role, ''), p.can_start, p.can_stop, p.can_restart, p.created_at
+			  FROM service_permissions p
+			  JOIN services s ON s.id = p.service_id
+			  WHERE s.server_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "user_id", "role", "can_start", "can_stop", "can_restart", "created_at"}).
+			AddRow(int64(1), int64(10), "", "admin", true, true, true, fixedTime).
+			AddRow(int64(2), int64(10), "user-2", "", false, false, true, fixedTime))
+
+	pg := &PgStorage{DB: db}
+	permissions, err := pg.ListServerServicePermissions(context.Background(), 1)
+
+	require.NoError(t, err)
+	require.Len(t, permissions, 2)
+	assert.Equal(t, models.RoleAdmin, permissions[0].Role)
+	assert.Equal(t, "user-2", permissions[1].UserID)
+	assert.True(t, permissions[1].Restart)
+	assert.False(t, permissions[1].Start)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDelServicePermission Проверяет удаление правила доступа к службе.
+func TestDelServicePermission(t *testing.T) {
+	tests := []struct {
+		name         string
+		affectedRows int64
+		expectError  bool
+	}{
+		{"успешное удаление", 1, false},
+		{"правило не найдено", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM service_permissions WHERE id = $1 AND service_id = $2`)).
+				WithArgs(int64(3), int64(10)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affectedRows))
+
+			pg := &PgStorage{DB: db}
+			err = pg.DelServicePermission(context.Background(), 10, 3)
+
+			if tt.expectError {
+				var notFound *errs.ErrServicePermissionNotFound
+				assert.ErrorAs(t, err, &notFound)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
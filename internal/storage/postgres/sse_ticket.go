@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+)
+
+// AddSSETicket Сохранение хэша билета SSE, выданного пользователю на поток stream.
+// Попутно удаляются билеты с истекшим сроком действия.
+func (pg *PgStorage) AddSSETicket(ctx context.Context, ticketHash string, userID string, stream string, expiresAt time.Time) error {
+	if _, err := pg.DB.ExecContext(ctx, `DELETE FROM sse_tickets WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		logger.Log.Warn("Ошибка при удалении истекших билетов SSE", logger.String("err", err.Error()))
+	}
+
+	query := `INSERT INTO sse_tickets (ticket_hash, user_id, stream, expires_at) VALUES ($1, $2, $3, $4)`
+
+	if _, err := pg.DB.ExecContext(ctx, query, ticketHash, userID, stream, expiresAt); err != nil {
+		logger.Log.Error("Ошибка при сохранении билета SSE", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при сохранении билета SSE: %w", err)
+	}
+
+	return nil
+}
+
+// UseSSETicket Использование билета SSE: билет удаляется (одноразовый), возвращается id его владельца.
+// Истекший, выданный на другой поток или принадлежащий заблокированному пользователю билет
+// тоже удаляется, но не принимается.
+func (pg *PgStorage) UseSSETicket(ctx context.Context, ticketHash string, stream string) (string, error) {
+	query := `WITH used AS (
+				  DELETE FROM sse_tickets WHERE ticket_hash = $1 RETURNING user_id, stream, expires_at
+			  )
+			  SELECT u.id
+			  FROM used
+			  JOIN users u ON u.id = used.user_id
+			  WHERE used.stream = $2 AND used.expires_at > CURRENT_TIMESTAMP AND NOT u.disabled`
+
+	var userID string
+
+	err := pg.DB.QueryRowContext(ctx, query, ticketHash, stream).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.NewErrSSETicketNotFound(err)
+		}
+		return "", fmt.Errorf("ошибка при проверке билета SSE: %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+)
+
+// TestAddSSETicket Проверяет сохранение билета SSE с удалением истекших билетов.
+func TestAddSSETicket(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expiresAt := time.Now().Add(30 * time.Second)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM sse_tickets WHERE expires_at < CURRENT_TIMESTAMP`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO sse_tickets (ticket_hash, user_id, stream, expires_at)`)).
+		WithArgs("ticket-hash", "user-1", "services", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pg := &PgStorage{DB: db}
+	assert.NoError(t, pg.AddSSETicket(context.Background(), "ticket-hash", "user-1", "services", expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUseSSETicket Проверяет использование билета SSE.
+func TestUseSSETicket(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         *sqlmock.Rows
+		queryErr     error
+		wantUserID   string
+		wantNotFound bool
+		wantErr      bool
+	}{
+		{
+			name:       "билет действителен",
+			rows:       sqlmock.NewRows([]string{"id"}).AddRow("user-1"),
+			wantUserID: "user-1",
+		},
+		{
+			name:         "билет использован, истек или выдан на другой поток",
+			queryErr:     sql.ErrNoRows,
+			wantNotFound: true,
+			wantErr:      true,
+		},
+		{
+			name:     "ошибка БД",
+			queryErr: errors.New("db error"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			query := mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM sse_tickets WHERE ticket_hash = $1 RETURNING user_id, stream, expires_at`)).
+				WithArgs("ticket-hash", "services")
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+
+			pg := &PgStorage{DB: db}
+			userID, err := pg.UseSSETicket(context.Background(), "ticket-hash", "services")
+
+			if tt.wantErr {
+				require.Error(t, err)
+				var errNotFound *errs.ErrSSETicketNotFound
+				assert.Equal(t, tt.wantNotFound, errors.As(err, &errNotFound))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantUserID, userID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
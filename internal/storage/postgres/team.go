@@ -0,0 +1,486 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// CreateTeam Создание команды. Пользователь, создавший команду, становится ее администратором.
+func (pg *PgStorage) CreateTeam(ctx context.Context, team models.Team, userID string) (*models.Team, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Log.Error("Ошибка транзакции при создании команды", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("не удалось начать транзакцию создания команды: %w", err)
+	}
+	defer tx.Rollback()
+
+	queryTeam := `INSERT INTO teams (name, created_by) VALUES ($1, $2) RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, queryTeam, team.Name, userID).Scan(&team.ID, &team.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании команды: %w", err)
+	}
+
+	queryMember := `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`
+
+	_, err = tx.ExecContext(ctx, queryMember, team.ID, userID, models.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при добавлении администратора команды: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error("Ошибка при коммите транзакции создания команды", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при коммите транзакции создания команды: %w", err)
+	}
+
+	team.Role = models.RoleAdmin
+
+	return &team, nil
+}
+
+// DelTeam Удаление команды вместе с ее серверами, службами и приглашениями.
+func (pg *PgStorage) DelTeam(ctx context.Context, teamID int64) error {
+	query := `DELETE FROM teams WHERE id = $1`
+
+	result, err := pg.DB.ExecContext(ctx, query, teamID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrTeamNotFound(teamID, "", fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
+// ListTeams Получение списка команд пользователя с его ролью в каждой из них.
+func (pg *PgStorage) ListTeams(ctx context.Context, userID string) ([]*models.Team, error) {
+	query := `SELECT t.id, t.name, m.role, t.created_at
+			  FROM teams t
+			  JOIN team_members m ON m.team_id = t.id
+			  WHERE m.user_id = $1
+			  ORDER BY t.name, t.id`
+
+	rows, err := pg.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении списка команд пользователя", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении списка команд пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []*models.Team
+
+	for rows.Next() {
+		var team models.Team
+		err = rows.Scan(&team.ID, &team.Name, &team.Role, &team.CreatedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки списка команд", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		teams = append(teams, &team)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк списка команд", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// GetTeamRole Возвращает роль пользователя в команде.
+// Если пользователь не состоит в команде - возвращает ErrTeamNotFound.
+func (pg *PgStorage) GetTeamRole(ctx context.Context, teamID int64, userID string) (models.Role, error) {
+	query := `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`
+
+	var role models.Role
+
+	err := pg.DB.QueryRowContext(ctx, query, teamID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.NewErrTeamNotFound(teamID, userID, err)
+		}
+		return "", fmt.Errorf("ошибка при получении роли в команде: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetServerTeamRole Возвращает роль пользователя в команде, которой принадлежит сервер.
+// Если сервер не найден или пользователь не состоит в его команде - возвращает ErrServerNotFound.
+func (pg *PgStorage) GetServerTeamRole(ctx context.Context, serverID int64, userID string) (models.Role, error) {
+	query := `SELECT m.role
+			  FROM servers s
+			  JOIN team_members m ON m.team_id = s.team_id
+			  WHERE s.id = $1 AND m.user_id = $2`
+
+	var role models.Role
+
+	err := pg.DB.QueryRowContext(ctx, query, serverID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.NewErrServerNotFound(serverID, userID, err)
+		}
+		return "", fmt.Errorf("ошибка при получении роли в команде сервера: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetDefaultTeamID Возвращает команду, в которую по умолчанию добавляются серверы пользователя:
+// созданную им команду (в первую очередь - собственную, созданную при регистрации),
+// иначе - любую команду, где пользователь администратор.
+func (pg *PgStorage) GetDefaultTeamID(ctx context.Context, userID string) (int64, error) {
+	query := `SELECT t.id
+			  FROM teams t
+			  JOIN team_members m ON m.team_id = t.id
+			  WHERE m.user_id = $1 AND m.role = 'admin'
+			  ORDER BY COALESCE(t.created_by = $1, false) DESC, t.id
+			  LIMIT 1`
+
+	var teamID int64
+
+	err := pg.DB.QueryRowContext(ctx, query, userID).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.NewErrTeamNotFound(0, userID, err)
+		}
+		return 0, fmt.Errorf("ошибка при получении команды по умолчанию: %w", err)
+	}
+
+	return teamID, nil
+}
+
+// ListTeamMembers Получение списка участников команды.
+func (pg *PgStorage) ListTeamMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error) {
+	query := `SELECT m.user_id, u.login, m.role, m.created_at
+			  FROM team_members m
+			  JOIN users u ON u.id = m.user_id
+			  WHERE m.team_id = $1
+			  ORDER BY u.login`
+
+	rows, err := pg.DB.QueryContext(ctx, query, teamID)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении участников команды", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении участников команды: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*models.TeamMember
+
+	for rows.Next() {
+		var member models.TeamMember
+		err = rows.Scan(&member.UserID, &member.Login, &member.Role, &member.CreatedAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки участников команды", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		members = append(members, &member)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк участников команды", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetTeamMemberRole Изменение роли участника команды.
+// Последнего администратора команды понизить нельзя.
+func (pg *PgStorage) SetTeamMemberRole(ctx context.Context, teamID int64, userID string, role models.Role) error {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания транзакции %w ", err)
+	}
+	defer tx.Rollback()
+
+	if err = checkTeamMemberChange(ctx, tx, teamID, userID, role != models.RoleAdmin); err != nil {
+		return err
+	}
+
+	query := `UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3`
+
+	_, err = tx.ExecContext(ctx, query, role, teamID, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %v", err)
+	}
+
+	return nil
+}
+
+// DelTeamMember Исключение пользователя из команды (или выход из нее).
+// Последнего администратора команды исключить нельзя.
+func (pg *PgStorage) DelTeamMember(ctx context.Context, teamID int64, userID string) error {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания транзакции %w ", err)
+	}
+	defer tx.Rollback()
+
+	if err = checkTeamMemberChange(ctx, tx, teamID, userID, true); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`
+
+	_, err = tx.ExecContext(ctx, query, teamID, userID)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %v", err)
+	}
+
+	return nil
+}
+
+// Вспомогательная функция. Блокирует команду до конца транзакции и проверяет, что пользователь состоит в ней.
+// Если участник лишается роли администратора (revokeAdmin) - проверяет, что в команде останется другой администратор.
+func checkTeamMemberChange(ctx context.Context, tx *sql.Tx, teamID int64, userID string, revokeAdmin bool) error {
+	// блокировка строки команды исключает одновременное понижение двух последних администраторов
+	var lockedID int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&lockedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.NewErrTeamNotFound(teamID, userID, err)
+		}
+		return fmt.Errorf("ошибка при блокировке команды: %w", err)
+	}
+
+	var currentRole models.Role
+	err = tx.QueryRowContext(ctx, `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID).
+		Scan(&currentRole)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.NewErrTeamMemberNotFound(teamID, userID, err)
+		}
+		return fmt.Errorf("ошибка при получении роли участника: %w", err)
+	}
+
+	if !revokeAdmin || currentRole != models.RoleAdmin {
+		return nil
+	}
+
+	var admins int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND role = 'admin'`, teamID).
+		Scan(&admins)
+	if err != nil {
+		return fmt.Errorf("ошибка при подсчете администраторов команды: %w", err)
+	}
+
+	if admins <= 1 {
+		return errs.NewErrLastTeamAdmin(teamID)
+	}
+
+	return nil
+}
+
+// AddTeamInvitation Создание приглашения в команду от пользователя userID.
+// Истекшее приглашение того же пользователя заменяется новым.
+func (pg *PgStorage) AddTeamInvitation(ctx context.Context, invitation models.TeamInvitation, userID string) (*models.TeamInvitation, error) {
+	var isMember bool
+
+	queryMember := `SELECT EXISTS(
+						SELECT 1 FROM team_members m
+						JOIN users u ON u.id = m.user_id
+						WHERE m.team_id = $1 AND u.login = $2
+					)`
+
+	err := pg.DB.QueryRowContext(ctx, queryMember, invitation.TeamID, invitation.Login).Scan(&isMember)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при проверке участников команды: %w", err)
+	}
+
+	if isMember {
+		return nil, errs.NewErrDuplicatedInvitation(invitation.Login, fmt.Errorf("пользователь уже состоит в команде"))
+	}
+
+	// действующее приглашение не перезаписывается: RETURNING не вернет строк
+	query := `INSERT INTO team_invitations (team_id, login, role, invited_by, expires_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (team_id, login) DO UPDATE
+				SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by,
+					created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at
+				WHERE team_invitations.expires_at <= CURRENT_TIMESTAMP
+			  RETURNING id, created_at`
+
+	err = pg.DB.QueryRowContext(ctx, query, invitation.TeamID, invitation.Login, invitation.Role, userID, invitation.ExpiresAt).
+		Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrDuplicatedInvitation(invitation.Login, err)
+		}
+		return nil, fmt.Errorf("ошибка при создании приглашения: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// ListTeamInvitations Получение действующих приглашений в команду.
+func (pg *PgStorage) ListTeamInvitations(ctx context.Context, teamID int64) ([]*models.TeamInvitation, error) {
+	query := `SELECT i.id, i.team_id, t.name, i.login, i.role, COALESCE(u.login, ''), i.created_at, i.expires_at
+			  FROM team_invitations i
+			  JOIN teams t ON t.id = i.team_id
+			  LEFT JOIN users u ON u.id = i.invited_by
+			  WHERE i.team_id = $1 AND i.expires_at > CURRENT_TIMESTAMP
+			  ORDER BY i.created_at, i.id`
+
+	return pg.listInvitations(ctx, query, teamID)
+}
+
+// ListUserInvitations Получение действующих приглашений пользователя с логином login.
+func (pg *PgStorage) ListUserInvitations(ctx context.Context, login string) ([]*models.TeamInvitation, error) {
+	query := `SELECT i.id, i.team_id, t.name, i.login, i.role, COALESCE(u.login, ''), i.created_at, i.expires_at
+			  FROM team_invitations i
+			  JOIN teams t ON t.id = i.team_id
+			  LEFT JOIN users u ON u.id = i.invited_by
+			  WHERE i.login = $1 AND i.expires_at > CURRENT_TIMESTAMP
+			  ORDER BY i.created_at, i.id`
+
+	return pg.listInvitations(ctx, query, login)
+}
+
+// Вспомогательная функция. Выполняет запрос списка приглашений с одним аргументом.
+func (pg *PgStorage) listInvitations(ctx context.Context, query string, arg any) ([]*models.TeamInvitation, error) {
+	rows, err := pg.DB.QueryContext(ctx, query, arg)
+	if err != nil {
+		logger.Log.Error("Ошибка при получении приглашений в команды", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при получении приглашений в команды: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []*models.TeamInvitation
+
+	for rows.Next() {
+		var invitation models.TeamInvitation
+		err = rows.Scan(&invitation.ID, &invitation.TeamID, &invitation.TeamName, &invitation.Login, &invitation.Role,
+			&invitation.InvitedBy, &invitation.CreatedAt, &invitation.ExpiresAt)
+		if err != nil {
+			logger.Log.Error("Ошибка сканирования строки приглашений в команды", logger.String("err", err.Error()))
+			return nil, err
+		}
+
+		invitations = append(invitations, &invitation)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.Log.Error("Ошибка при обработке строк приглашений в команды", logger.String("err", err.Error()))
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// DelTeamInvitation Отзыв приглашения в команду.
+func (pg *PgStorage) DelTeamInvitation(ctx context.Context, teamID int64, invitationID int64) error {
+	query := `DELETE FROM team_invitations WHERE id = $1 AND team_id = $2`
+
+	return pg.deleteInvitation(ctx, query, invitationID, teamID)
+}
+
+// DeclineTeamInvitation Отклонение приглашения пользователем с логином login.
+func (pg *PgStorage) DeclineTeamInvitation(ctx context.Context, invitationID int64, login string) error {
+	query := `DELETE FROM team_invitations WHERE id = $1 AND login = $2`
+
+	return pg.deleteInvitation(ctx, query, invitationID, login)
+}
+
+// Вспомогательная функция. Удаляет приглашение запросом query с аргументами (invitationID, arg).
+func (pg *PgStorage) deleteInvitation(ctx context.Context, query string, invitationID int64, arg any) error {
+	result, err := pg.DB.ExecContext(ctx, query, invitationID, arg)
+	if err != nil {
+		logger.Log.Error("Ошибка запроса", logger.String("err", err.Error()))
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrInvitationNotFound(invitationID, fmt.Errorf("%w: затронутых строк %d", sql.ErrNoRows, affectedRows))
+	}
+
+	return nil
+}
+
+// AcceptTeamInvitation Принятие приглашения пользователем: пользователь добавляется в команду с ролью из приглашения,
+// приглашение удаляется. Возвращает команду с ролью пользователя в ней.
+func (pg *PgStorage) AcceptTeamInvitation(ctx context.Context, invitationID int64, userID string, login string) (*models.Team, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Log.Error("Ошибка транзакции при принятии приглашения", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("не удалось начать транзакцию принятия приглашения: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID int64
+	var role models.Role
+
+	queryInvitation := `DELETE FROM team_invitations
+						WHERE id = $1 AND login = $2 AND expires_at > CURRENT_TIMESTAMP
+						RETURNING team_id, role`
+
+	err = tx.QueryRowContext(ctx, queryInvitation, invitationID, login).Scan(&teamID, &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewErrInvitationNotFound(invitationID, err)
+		}
+		return nil, fmt.Errorf("ошибка при получении приглашения: %w", err)
+	}
+
+	// если пользователь уже состоит в команде - его роль не меняется
+	queryMember := `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)
+					ON CONFLICT (team_id, user_id) DO NOTHING`
+
+	_, err = tx.ExecContext(ctx, queryMember, teamID, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при добавлении участника команды: %w", err)
+	}
+
+	var team models.Team
+
+	queryTeam := `SELECT t.id, t.name, m.role, t.created_at
+				  FROM teams t
+				  JOIN team_members m ON m.team_id = t.id
+				  WHERE t.id = $1 AND m.user_id = $2`
+
+	err = tx.QueryRowContext(ctx, queryTeam, teamID, userID).Scan(&team.ID, &team.Name, &team.Role, &team.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении команды: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error("Ошибка при коммите транзакции принятия приглашения", logger.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка при коммите транзакции принятия приглашения: %w", err)
+	}
+
+	return &team, nil
+}
@@ -0,0 +1,439 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TestCreateTeam Проверяет создание команды с администратором-создателем.
+func TestCreateTeam(t *testing.T) {
+	fixedTime := time.Now()
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectError bool
+	}{
+		{
+			name: "успешное создание",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO teams (name, created_by) VALUES ($1, $2) RETURNING id, created_at`)).
+					WithArgs("Ops", "user-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(5), fixedTime))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`)).
+					WithArgs(int64(5), "user-1", models.RoleAdmin).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "ошибка добавления администратора",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO teams`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(5), fixedTime))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO team_members`)).
+					WillReturnError(errors.New("database error"))
+				mock.ExpectRollback()
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			result, err := pg.CreateTeam(context.Background(), models.Team{Name: "Ops"}, "user-1")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), result.ID)
+				assert.Equal(t, models.RoleAdmin, result.Role)
+				assert.Equal(t, fixedTime, result.CreatedAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestGetServerTeamRole Проверяет получение роли пользователя в команде сервера.
+func TestGetServerTeamRole(t *testing.T) {
+	query := `SELECT m.role
+			  FROM servers s
+			  JOIN team_members m ON m.team_id = s.team_id
+			  WHERE s.id = $1 AND m.user_id = $2`
+
+	tests := []struct {
+		name         string
+		mockSetup    func(mock sqlmock.Sqlmock)
+		expectedRole models.Role
+		expectedErr  any
+	}{
+		{
+			name: "участник команды",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(int64(1), "user-1").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("operator"))
+			},
+			expectedRole: models.RoleOperator,
+		},
+		{
+			name: "не участник или сервер не найден",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(int64(1), "user-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: new(*errs.ErrServerNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			role, err := pg.GetServerTeamRole(context.Background(), 1, "user-1")
+
+			if tt.expectedErr != nil {
+				assert.ErrorAs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedRole, role)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestSetTeamMemberRole Проверяет изменение роли участника и защиту последнего администратора.
+func TestSetTeamMemberRole(t *testing.T) {
+	lockQuery := `SELECT id FROM teams WHERE id = $1 FOR UPDATE`
+	roleQuery := `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`
+	countQuery := `SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND role = 'admin'`
+	updateQuery := `UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3`
+
+	tests := []struct {
+		name        string
+		role        models.Role
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr any
+	}{
+		{
+			name: "повышение участника",
+			role: models.RoleAdmin,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+				mock.ExpectQuery(regexp.QuoteMeta(roleQuery)).WithArgs(int64(5), "user-2").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("viewer"))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(models.RoleAdmin, int64(5), "user-2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "понижение администратора при наличии другого",
+			role: models.RoleViewer,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+				mock.ExpectQuery(regexp.QuoteMeta(roleQuery)).WithArgs(int64(5), "user-2").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(models.RoleViewer, int64(5), "user-2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "понижение последнего администратора",
+			role: models.RoleOperator,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+				mock.ExpectQuery(regexp.QuoteMeta(roleQuery)).WithArgs(int64(5), "user-2").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedErr: new(*errs.ErrLastTeamAdmin),
+		},
+		{
+			name: "пользователь не состоит в команде",
+			role: models.RoleViewer,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+				mock.ExpectQuery(regexp.QuoteMeta(roleQuery)).WithArgs(int64(5), "user-2").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: new(*errs.ErrTeamMemberNotFound),
+		},
+		{
+			name: "команда не найдена",
+			role: models.RoleViewer,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(int64(5)).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: new(*errs.ErrTeamNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			err = pg.SetTeamMemberRole(context.Background(), 5, "user-2", tt.role)
+
+			if tt.expectedErr != nil {
+				assert.ErrorAs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestDelTeamMember Проверяет исключение участника из команды.
+func TestDelTeamMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM teams WHERE id = $1 FOR UPDATE`)).WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`)).
+		WithArgs(int64(5), "user-2").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("operator"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`)).
+		WithArgs(int64(5), "user-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pg := &PgStorage{DB: db}
+	assert.NoError(t, pg.DelTeamMember(context.Background(), 5, "user-2"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddTeamInvitation Проверяет создание приглашения в команду.
+func TestAddTeamInvitation(t *testing.T) {
+	fixedTime := time.Now()
+	expiresAt := fixedTime.Add(models.TeamInvitationTTL)
+
+	memberQuery := `SELECT EXISTS(
+						SELECT 1 FROM team_members m
+						JOIN users u ON u.id = m.user_id
+						WHERE m.team_id = $1 AND u.login = $2
+					)`
+	insertQuery := `INSERT INTO team_invitations (team_id, login, role, invited_by, expires_at)`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr any
+	}{
+		{
+			name: "успешное приглашение",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(memberQuery)).WithArgs(int64(5), "bob").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).
+					WithArgs(int64(5), "bob", models.RoleOperator, "user-1", expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(7), fixedTime))
+			},
+		},
+		{
+			name: "пользователь уже в команде",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(memberQuery)).WithArgs(int64(5), "bob").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expectedErr: new(*errs.ErrDuplicatedInvitation),
+		},
+		{
+			name: "действующее приглашение уже есть",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(memberQuery)).WithArgs(int64(5), "bob").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: new(*errs.ErrDuplicatedInvitation),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			result, err := pg.AddTeamInvitation(context.Background(), models.TeamInvitation{
+				TeamID: 5, Login: "bob", Role: models.RoleOperator, ExpiresAt: expiresAt,
+			}, "user-1")
+
+			if tt.expectedErr != nil {
+				assert.ErrorAs(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(7), result.ID)
+				assert.Equal(t, fixedTime, result.CreatedAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestAcceptTeamInvitation Проверяет принятие приглашения в команду.
+func TestAcceptTeamInvitation(t *testing.T) {
+	fixedTime := time.Now()
+
+	deleteQuery := `DELETE FROM team_invitations
+					WHERE id = $1 AND login = $2 AND expires_at > CURRENT_TIMESTAMP
+					RETURNING team_id, role`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr any
+	}{
+		{
+			name: "успешное принятие",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(deleteQuery)).WithArgs(int64(7), "bob").
+					WillReturnRows(sqlmock.NewRows([]string{"team_id", "role"}).AddRow(int64(5), "operator"))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)
+					ON CONFLICT (team_id, user_id) DO NOTHING`)).
+					WithArgs(int64(5), "user-2", models.RoleOperator).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, t.name, m.role, t.created_at`)).
+					WithArgs(int64(5), "user-2").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role", "created_at"}).
+						AddRow(int64(5), "Ops", "operator", fixedTime))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "приглашение не найдено или истекло",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(deleteQuery)).WithArgs(int64(7), "bob").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: new(*errs.ErrInvitationNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			pg := &PgStorage{DB: db}
+			team, err := pg.AcceptTeamInvitation(context.Background(), 7, "user-2", "bob")
+
+			if tt.expectedErr != nil {
+				assert.ErrorAs(t, err, tt.expectedErr)
+				assert.Nil(t, team)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, &models.Team{ID: 5, Name: "Ops", Role: models.RoleOperator, CreatedAt: fixedTime}, team)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestDeclineTeamInvitation Проверяет отклонение приглашения в команду.
+func TestDeclineTeamInvitation(t *testing.T) {
+	query := `DELETE FROM team_invitations WHERE id = $1 AND login = $2`
+
+	tests := []struct {
+		name         string
+		affectedRows int64
+		expectError  bool
+	}{
+		{"успешное отклонение", 1, false},
+		{"приглашение не найдено", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int64(7), "bob").
+				WillReturnResult(sqlmock.NewResult(0, tt.affectedRows))
+
+			pg := &PgStorage{DB: db}
+			err = pg.DeclineTeamInvitation(context.Background(), 7, "bob")
+
+			if tt.expectError {
+				var notFound *errs.ErrInvitationNotFound
+				assert.ErrorAs(t, err, &notFound)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+)
+
+// aesKeyLen Длина ключа AES-256 в байтах.
+const aesKeyLen = 32
+
+// KeyRing Набор версионированных AES-ключей: текущий ключ используется для шифрования,
+// все ключи — для расшифровки данных, зашифрованных ранее. Версия ключа хранится рядом с шифротекстом.
+type KeyRing struct {
+	currentID int
+	keys      map[int][]byte
+}
+
+// NewKeyRing Конструктор набора ключей с текущей версией currentID.
+func NewKeyRing(currentID int, keys map[int][]byte) (*KeyRing, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("текущий AES-ключ версии %d не задан", currentID)
+	}
+
+	ring := &KeyRing{currentID: currentID, keys: make(map[int][]byte, len(keys))}
+
+	for id, key := range keys {
+		if id <= 0 {
+			return nil, fmt.Errorf("неверная версия AES-ключа %d: ожидается положительное число", id)
+		}
+		if len(key) != aesKeyLen {
+			return nil, fmt.Errorf("AES-ключ версии %d должен быть длиной %d байта, получено %d", id, aesKeyLen, len(key))
+		}
+
+		ring.keys[id] = key
+	}
+
+	return ring, nil
+}
+
+// ParseKeyRing Создает набор ключей из конфигурации: текущего ключа (base64) с версией currentID
+// и предыдущих ключей в формате "версия:base64,версия:base64".
+func ParseKeyRing(currentKey string, currentID int, previousKeys string) (*KeyRing, error) {
+	key, err := base64.StdEncoding.DecodeString(currentKey)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось декодировать AES-ключ: %w", err)
+	}
+
+	keys := map[int][]byte{currentID: key}
+
+	for _, pair := range strings.Split(previousKeys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		idStr, keyStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("неверный формат предыдущего AES-ключа: ожидается версия:ключ")
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			return nil, fmt.Errorf("неверная версия предыдущего AES-ключа %q: %w", idStr, err)
+		}
+
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("AES-ключ версии %d задан несколько раз", id)
+		}
+
+		previous, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyStr))
+		if err != nil {
+			return nil, fmt.Errorf("не удалось декодировать AES-ключ версии %d: %w", id, err)
+		}
+
+		keys[id] = previous
+	}
+
+	return NewKeyRing(currentID, keys)
+}
+
+// CurrentID Возвращает версию текущего ключа.
+func (r *KeyRing) CurrentID() int {
+	return r.currentID
+}
+
+// Has Проверяет, задан ли ключ версии keyID.
+func (r *KeyRing) Has(keyID int) bool {
+	_, ok := r.keys[keyID]
+	return ok
+}
+
+// Encrypt Шифрует строку текущим ключом и возвращает шифротекст (base64) и версию ключа.
+func (r *KeyRing) Encrypt(plaintext string) (string, int, error) {
+	encrypted, err := EncryptAES([]byte(plaintext), r.keys[r.currentID])
+	if err != nil {
+		return "", 0, err
+	}
+
+	return encrypted, r.currentID, nil
+}
+
+// Decrypt Расшифровывает шифротекст ключом версии keyID.
+func (r *KeyRing) Decrypt(encryptedText string, keyID int) (string, error) {
+	key, ok := r.keys[keyID]
+	if !ok {
+		return "", errs.NewErrAESKeyNotFound(keyID, nil)
+	}
+
+	return DecryptAES(encryptedText, key)
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+)
+
+// TestParseKeyRing Проверяет разбор текущего и предыдущих AES-ключей из конфигурации.
+func TestParseKeyRing(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012"))
+	key2 := base64.StdEncoding.EncodeToString([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+
+	tests := []struct {
+		name         string
+		currentKey   string
+		currentID    int
+		previousKeys string
+		expectError  bool
+		expectedKeys []int
+	}{
+		{name: "только текущий ключ", currentKey: key1, currentID: 1, expectedKeys: []int{1}},
+		{name: "текущий и предыдущий ключи", currentKey: key2, currentID: 2, previousKeys: " 1:" + key1 + " ,", expectedKeys: []int{1, 2}},
+		{name: "неверный base64", currentKey: "%%%", currentID: 1, expectError: true},
+		{name: "неверная длина ключа", currentKey: short, currentID: 1, expectError: true},
+		{name: "версия без ключа", currentKey: key2, currentID: 2, previousKeys: key1, expectError: true},
+		{name: "нечисловая версия", currentKey: key2, currentID: 2, previousKeys: "v1:" + key1, expectError: true},
+		{name: "повтор версии", currentKey: key2, currentID: 2, previousKeys: "2:" + key1, expectError: true},
+		{name: "неположительная версия", currentKey: key1, currentID: 0, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := ParseKeyRing(tt.currentKey, tt.currentID, tt.previousKeys)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, ring)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.currentID, ring.CurrentID())
+			for _, id := range tt.expectedKeys {
+				assert.True(t, ring.Has(id), "ключ версии %d должен быть задан", id)
+			}
+		})
+	}
+}
+
+// TestKeyRingEncryptDecrypt Проверяет шифрование текущим ключом и расшифровку ключом нужной версии.
+func TestKeyRingEncryptDecrypt(t *testing.T) {
+	oldKey := []byte("12345678901234567890123456789012")
+	newKey := []byte("abcdefghijklmnopqrstuvwxyz123456")
+
+	ring, err := NewKeyRing(2, map[int][]byte{1: oldKey, 2: newKey})
+	require.NoError(t, err)
+
+	encrypted, keyID, err := ring.Encrypt("secret")
+	require.NoError(t, err)
+	assert.Equal(t, 2, keyID)
+
+	decrypted, err := ring.Decrypt(encrypted, keyID)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", decrypted)
+
+	// данные, зашифрованные предыдущим ключом, расшифровываются по его версии
+	legacy, err := EncryptAES([]byte("legacy"), oldKey)
+	require.NoError(t, err)
+
+	decrypted, err = ring.Decrypt(legacy, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", decrypted)
+
+	_, err = ring.Decrypt(legacy, 3)
+	var errKey *errs.ErrAESKeyNotFound
+	require.True(t, errors.As(err, &errKey))
+	assert.Equal(t, 3, errKey.KeyID)
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+)
+
+// AddWebhookEvent Отметка события вебхука как обработанного. Если событие с таким идентификатором
+// уже отмечено, возвращается ErrWebhookEventDuplicate. Попутно удаляются записи с истекшим сроком хранения.
+func (pg *PgStorage) AddWebhookEvent(ctx context.Context, eventID string, expiresAt time.Time) error {
+	if _, err := pg.DB.ExecContext(ctx, `DELETE FROM webhook_events WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		logger.Log.Warn("Ошибка при удалении устаревших событий вебхуков", logger.String("err", err.Error()))
+	}
+
+	query := `INSERT INTO webhook_events (event_id, expires_at) VALUES ($1, $2) ON CONFLICT (event_id) DO NOTHING`
+
+	result, err := pg.DB.ExecContext(ctx, query, eventID, expiresAt)
+	if err != nil {
+		logger.Log.Error("Ошибка при сохранении события вебхука", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при сохранении события вебхука: %w", err)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса %w", err)
+	}
+
+	if affectedRows == 0 {
+		return errs.NewErrWebhookEventDuplicate(eventID, fmt.Errorf("событие уже обработано"))
+	}
+
+	return nil
+}
+
+// DelWebhookEvent Снятие отметки об обработке события, чтобы повторная доставка
+// после ошибки обработки не была отброшена как дубликат.
+func (pg *PgStorage) DelWebhookEvent(ctx context.Context, eventID string) error {
+	if _, err := pg.DB.ExecContext(ctx, `DELETE FROM webhook_events WHERE event_id = $1`, eventID); err != nil {
+		logger.Log.Error("Ошибка при удалении события вебхука", logger.String("err", err.Error()))
+		return fmt.Errorf("ошибка при удалении события вебхука: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+)
+
+// TestAddWebhookEvent Проверяет отметку события вебхука как обработанного.
+func TestAddWebhookEvent(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		affectedRows int64
+		wantDup      bool
+	}{
+		{name: "новое событие", affectedRows: 1},
+		{name: "событие уже обработано", affectedRows: 0, wantDup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM webhook_events WHERE expires_at < CURRENT_TIMESTAMP`)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_events (event_id, expires_at)`)).
+				WithArgs("event-1", expiresAt).
+				WillReturnResult(sqlmock.NewResult(0, tt.affectedRows))
+
+			pg := &PgStorage{DB: db}
+			err = pg.AddWebhookEvent(context.Background(), "event-1", expiresAt)
+
+			if tt.wantDup {
+				var errDup *errs.ErrWebhookEventDuplicate
+				assert.True(t, errors.As(err, &errDup))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestDelWebhookEvent Проверяет снятие отметки об обработке события.
+func TestDelWebhookEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM webhook_events WHERE event_id = $1`)).
+		WithArgs("event-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pg := &PgStorage{DB: db}
+	assert.NoError(t, pg.DelWebhookEvent(context.Background(), "event-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -14,4 +14,6 @@ type ServiceStorage interface {
 	BatchChangeServiceStatus(ctx context.Context, serverID int64, servicesBatch []*models.Service) error
 	GetService(ctx context.Context, serverID int64, serviceID int64, userID string) (*models.Service, error)
 	ListServices(ctx context.Context, serverID int64, userID string) ([]*models.Service, error)
+	ListServicesStates(ctx context.Context) ([]*models.ServiceState, error)
+	SetServiceCritical(ctx context.Context, serverID int64, serviceID int64, userID string, critical bool) error
 }
@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// ServicePermissionStorage Интерфейс для правил доступа к управлению службами.
+type ServicePermissionStorage interface {
+	ListServicePermissions(ctx context.Context, serviceID int64) ([]*models.ServicePermission, error)
+	ListServerServicePermissions(ctx context.Context, serverID int64) ([]*models.ServicePermission, error)
+	SetServicePermission(ctx context.Context, permission models.ServicePermission) (*models.ServicePermission, error)
+	DelServicePermission(ctx context.Context, serviceID int64, permissionID int64) error
+}
@@ -0,0 +1,12 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// SSETicketStorage Интерфейс для одноразовых билетов подключения к SSE.
+type SSETicketStorage interface {
+	AddSSETicket(ctx context.Context, ticketHash string, userID string, stream string, expiresAt time.Time) error
+	UseSSETicket(ctx context.Context, ticketHash string, stream string) (string, error)
+}
@@ -9,6 +9,16 @@ type Storage interface {
 	ServerStorage
 	ServiceStorage
 	UserStorage
+	HistoryStorage
+	AuditStorage
+	TeamStorage
+	ServicePermissionStorage
+	ControlApprovalStorage
+	APITokenStorage
+	LocalAuthStorage
+	WebhookEventStorage
+	SSETicketStorage
+	CredentialProfileStorage
 	Ping(ctx context.Context) error
 	Close() error
 }
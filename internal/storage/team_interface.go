@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// TeamStorage Интерфейс для команд, их участников и приглашений.
+type TeamStorage interface {
+	CreateTeam(ctx context.Context, team models.Team, userID string) (*models.Team, error)
+	DelTeam(ctx context.Context, teamID int64) error
+	ListTeams(ctx context.Context, userID string) ([]*models.Team, error)
+	GetTeamRole(ctx context.Context, teamID int64, userID string) (models.Role, error)
+	GetServerTeamRole(ctx context.Context, serverID int64, userID string) (models.Role, error)
+	GetDefaultTeamID(ctx context.Context, userID string) (int64, error)
+	ListTeamMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error)
+	SetTeamMemberRole(ctx context.Context, teamID int64, userID string, role models.Role) error
+	DelTeamMember(ctx context.Context, teamID int64, userID string) error
+	AddTeamInvitation(ctx context.Context, invitation models.TeamInvitation, userID string) (*models.TeamInvitation, error)
+	ListTeamInvitations(ctx context.Context, teamID int64) ([]*models.TeamInvitation, error)
+	ListUserInvitations(ctx context.Context, login string) ([]*models.TeamInvitation, error)
+	DelTeamInvitation(ctx context.Context, teamID int64, invitationID int64) error
+	AcceptTeamInvitation(ctx context.Context, invitationID int64, userID string, login string) (*models.Team, error)
+	DeclineTeamInvitation(ctx context.Context, invitationID int64, login string) error
+}
@@ -9,6 +9,9 @@ import (
 // UserStorage Интерфейс для пользователей.
 type UserStorage interface {
 	CreateUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	SetUserRole(ctx context.Context, userID string, role models.Role) error
 	DeleteUser(ctx context.Context, userID string) error
 	UserExists(ctx context.Context, userID string) (bool, error)
 	ListUsers(ctx context.Context) ([]*models.User, error)
@@ -0,0 +1,12 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// WebhookEventStorage Интерфейс для идентификаторов обработанных событий вебхуков.
+type WebhookEventStorage interface {
+	AddWebhookEvent(ctx context.Context, eventID string, expiresAt time.Time) error
+	DelWebhookEvent(ctx context.Context, eventID string) error
+}
@@ -18,7 +18,7 @@ import (
 //   - явно зафиксировать, какие операции разрешены воркерам
 //
 // Используется в ServerStatusWorker для получения списка серверов,
-// которые необходимо периодически проверять.
+// которые необходимо периодически проверять, и для записи истории их статусов.
 type WorkerStorage interface {
 	// ListServersAddresses Возвращает список серверов,
 	// подлежащих периодической проверке доступности.
@@ -26,4 +26,7 @@ type WorkerStorage interface {
 	// Возвращаемый срез содержит минимальный набор данных
 	// (ID и Address), достаточный для работы воркера.
 	ListServersAddresses(ctx context.Context) ([]*models.ServerStatus, error)
+
+	// AddServerStatusEvent Записывает в историю смену статуса сервера.
+	AddServerStatusEvent(ctx context.Context, serverID int64, status models.Status) error
 }
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartHTTPSpan Начинает серверный спан входящего HTTP-запроса.
+// Контекст трассировки вызывающей стороны (traceparent) извлекается из заголовков.
+func StartHTTPSpan(r *http.Request) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	return Tracer().Start(ctx, "HTTP "+r.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+}
+
+// EndHTTPSpan Завершает спан HTTP-запроса: имя спана уточняется шаблоном маршрута chi,
+// ответы 5xx отмечаются как ошибка.
+func EndHTTPSpan(span trace.Span, r *http.Request, status int) {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(attribute.String("http.route", pattern))
+		}
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+
+	span.End()
+}
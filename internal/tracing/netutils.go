@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+)
+
+// Checker Сетевые проверки доступности сервера, оборачивающие каждую проверку в спан.
+type Checker struct {
+	netutils.Checker
+}
+
+// NewChecker Конструктор сетевых проверок с трассировкой.
+func NewChecker(checker netutils.Checker) *Checker {
+	return &Checker{Checker: checker}
+}
+
+func (c *Checker) CheckWinRM(ctx context.Context, address string, port string, useHTTPS bool, timeout time.Duration) bool {
+	ctx, span := Start(ctx, "netutils.CheckWinRM",
+		AttrServerAddress.String(address),
+		attribute.String("server.port", port),
+		attribute.Bool("swsm.winrm.https", useHTTPS),
+	)
+	defer span.End()
+
+	ok := c.Checker.CheckWinRM(ctx, address, port, useHTTPS, timeout)
+	span.SetAttributes(attribute.Bool("swsm.probe.ok", ok))
+
+	return ok
+}
+
+func (c *Checker) CheckICMP(ctx context.Context, address string, timeout time.Duration) bool {
+	ctx, span := Start(ctx, "netutils.CheckICMP", AttrServerAddress.String(address))
+	defer span.End()
+
+	ok := c.Checker.CheckICMP(ctx, address, timeout)
+	span.SetAttributes(attribute.Bool("swsm.probe.ok", ok))
+
+	return ok
+}
+
+func (c *Checker) CheckTCP(ctx context.Context, address string, port string, timeout time.Duration) error {
+	ctx, span := Start(ctx, "netutils.CheckTCP",
+		AttrServerAddress.String(address),
+		attribute.String("server.port", port),
+	)
+
+	err := c.Checker.CheckTCP(ctx, address, port, timeout)
+	End(span, err)
+
+	return err
+}
+
+func (c *Checker) LookupHost(ctx context.Context, address string) ([]string, error) {
+	ctx, span := Start(ctx, "netutils.LookupHost", AttrServerAddress.String(address))
+
+	addrs, err := c.Checker.LookupHost(ctx, address)
+	End(span, err)
+
+	return addrs, err
+}
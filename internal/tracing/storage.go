@@ -0,0 +1,543 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// Storage Хранилище, оборачивающее каждый вызов в спан.
+type Storage struct {
+	storage.Storage
+}
+
+// NewStorage Конструктор хранилища с трассировкой.
+func NewStorage(s storage.Storage) *Storage {
+	return &Storage{Storage: s}
+}
+
+// startStorageSpan Начинает спан вызова хранилища.
+func startStorageSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", method),
+	)
+
+	return Start(ctx, "storage."+method, attrs...)
+}
+
+func (s *Storage) AddServer(ctx context.Context, server models.Server, userID string) (*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "AddServer", AttrServerAddress.String(server.Address))
+	result, err := s.Storage.AddServer(ctx, server, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) EditServer(ctx context.Context, input *models.Server, serverID int64, userID string) (*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "EditServer", AttrServerID.Int64(serverID))
+	result, err := s.Storage.EditServer(ctx, input, serverID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DelServer(ctx context.Context, serverID int64, userID string) error {
+	ctx, span := startStorageSpan(ctx, "DelServer", AttrServerID.Int64(serverID))
+	err := s.Storage.DelServer(ctx, serverID, userID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) GetServer(ctx context.Context, serverID int64, userID string) (*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "GetServer", AttrServerID.Int64(serverID))
+	result, err := s.Storage.GetServer(ctx, serverID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetServerWithPassword(ctx context.Context, serverID int64, userID string) (*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "GetServerWithPassword", AttrServerID.Int64(serverID))
+	result, err := s.Storage.GetServerWithPassword(ctx, serverID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListServers(ctx context.Context, userID string) ([]*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "ListServers")
+	result, err := s.Storage.ListServers(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) AddService(ctx context.Context, serverID int64, userID string, service models.Service) (*models.Service, error) {
+	ctx, span := startStorageSpan(ctx, "AddService", AttrServerID.Int64(serverID), AttrServiceName.String(service.ServiceName))
+	result, err := s.Storage.AddService(ctx, serverID, userID, service)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DelService(ctx context.Context, serverID int64, serviceID int64, userID string) error {
+	ctx, span := startStorageSpan(ctx, "DelService", AttrServerID.Int64(serverID), AttrServiceID.Int64(serviceID))
+	err := s.Storage.DelService(ctx, serverID, serviceID, userID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) ChangeServiceStatus(ctx context.Context, serverID int64, serviceName string, status string) error {
+	ctx, span := startStorageSpan(ctx, "ChangeServiceStatus", AttrServerID.Int64(serverID), AttrServiceName.String(serviceName))
+	err := s.Storage.ChangeServiceStatus(ctx, serverID, serviceName, status)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) BatchChangeServiceStatus(ctx context.Context, serverID int64, servicesBatch []*models.Service) error {
+	ctx, span := startStorageSpan(ctx, "BatchChangeServiceStatus", AttrServerID.Int64(serverID), attribute.Int("swsm.batch.size", len(servicesBatch)))
+	err := s.Storage.BatchChangeServiceStatus(ctx, serverID, servicesBatch)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) GetService(ctx context.Context, serverID int64, serviceID int64, userID string) (*models.Service, error) {
+	ctx, span := startStorageSpan(ctx, "GetService", AttrServerID.Int64(serverID), AttrServiceID.Int64(serviceID))
+	result, err := s.Storage.GetService(ctx, serverID, serviceID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListServices(ctx context.Context, serverID int64, userID string) ([]*models.Service, error) {
+	ctx, span := startStorageSpan(ctx, "ListServices", AttrServerID.Int64(serverID))
+	result, err := s.Storage.ListServices(ctx, serverID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListServicesStates(ctx context.Context) ([]*models.ServiceState, error) {
+	ctx, span := startStorageSpan(ctx, "ListServicesStates")
+	result, err := s.Storage.ListServicesStates(ctx)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, span := startStorageSpan(ctx, "CreateUser", AttrUserID.String(user.ID))
+	err := s.Storage.CreateUser(ctx, user)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, userID string) error {
+	ctx, span := startStorageSpan(ctx, "DeleteUser", AttrUserID.String(userID))
+	err := s.Storage.DeleteUser(ctx, userID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	ctx, span := startStorageSpan(ctx, "GetUser", AttrUserID.String(userID))
+	result, err := s.Storage.GetUser(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, user *models.User) error {
+	ctx, span := startStorageSpan(ctx, "UpdateUser", AttrUserID.String(user.ID))
+	err := s.Storage.UpdateUser(ctx, user)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) SetUserRole(ctx context.Context, userID string, role models.Role) error {
+	ctx, span := startStorageSpan(ctx, "SetUserRole", AttrUserID.String(userID))
+	err := s.Storage.SetUserRole(ctx, userID, role)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) UserExists(ctx context.Context, userID string) (bool, error) {
+	ctx, span := startStorageSpan(ctx, "UserExists", AttrUserID.String(userID))
+	result, err := s.Storage.UserExists(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListUsers(ctx context.Context) ([]*models.User, error) {
+	ctx, span := startStorageSpan(ctx, "ListUsers")
+	result, err := s.Storage.ListUsers(ctx)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetUserServiceStatuses(ctx context.Context, userID string) ([]*models.ServiceStatus, error) {
+	ctx, span := startStorageSpan(ctx, "GetUserServiceStatuses", AttrUserID.String(userID))
+	result, err := s.Storage.GetUserServiceStatuses(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListServerStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServerStatusEvent, error) {
+	ctx, span := startStorageSpan(ctx, "ListServerStatusHistory")
+	result, err := s.Storage.ListServerStatusHistory(ctx, userID, from, to)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListServiceStatusHistory(ctx context.Context, userID string, from, to time.Time) ([]*models.ServiceStatusEvent, error) {
+	ctx, span := startStorageSpan(ctx, "ListServiceStatusHistory")
+	result, err := s.Storage.ListServiceStatusHistory(ctx, userID, from, to)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListControlActions(ctx context.Context, userID string, from, to time.Time) ([]*models.ControlAction, error) {
+	ctx, span := startStorageSpan(ctx, "ListControlActions")
+	result, err := s.Storage.ListControlActions(ctx, userID, from, to)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := startStorageSpan(ctx, "AddAuditEntry", attribute.String("swsm.action", entry.Action))
+	err := s.Storage.AddAuditEntry(ctx, entry)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	ctx, span := startStorageSpan(ctx, "ListAuditEntries")
+	result, total, err := s.Storage.ListAuditEntries(ctx, filter)
+	End(span, err)
+	return result, total, err
+}
+
+func (s *Storage) CreateTeam(ctx context.Context, team models.Team, userID string) (*models.Team, error) {
+	ctx, span := startStorageSpan(ctx, "CreateTeam")
+	result, err := s.Storage.CreateTeam(ctx, team, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DelTeam(ctx context.Context, teamID int64) error {
+	ctx, span := startStorageSpan(ctx, "DelTeam", AttrTeamID.Int64(teamID))
+	err := s.Storage.DelTeam(ctx, teamID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) ListTeams(ctx context.Context, userID string) ([]*models.Team, error) {
+	ctx, span := startStorageSpan(ctx, "ListTeams")
+	result, err := s.Storage.ListTeams(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetTeamRole(ctx context.Context, teamID int64, userID string) (models.Role, error) {
+	ctx, span := startStorageSpan(ctx, "GetTeamRole", AttrTeamID.Int64(teamID))
+	result, err := s.Storage.GetTeamRole(ctx, teamID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetServerTeamRole(ctx context.Context, serverID int64, userID string) (models.Role, error) {
+	ctx, span := startStorageSpan(ctx, "GetServerTeamRole", AttrServerID.Int64(serverID))
+	result, err := s.Storage.GetServerTeamRole(ctx, serverID, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetDefaultTeamID(ctx context.Context, userID string) (int64, error) {
+	ctx, span := startStorageSpan(ctx, "GetDefaultTeamID")
+	result, err := s.Storage.GetDefaultTeamID(ctx, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListTeamMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error) {
+	ctx, span := startStorageSpan(ctx, "ListTeamMembers", AttrTeamID.Int64(teamID))
+	result, err := s.Storage.ListTeamMembers(ctx, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) SetTeamMemberRole(ctx context.Context, teamID int64, userID string, role models.Role) error {
+	ctx, span := startStorageSpan(ctx, "SetTeamMemberRole", AttrTeamID.Int64(teamID))
+	err := s.Storage.SetTeamMemberRole(ctx, teamID, userID, role)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) DelTeamMember(ctx context.Context, teamID int64, userID string) error {
+	ctx, span := startStorageSpan(ctx, "DelTeamMember", AttrTeamID.Int64(teamID))
+	err := s.Storage.DelTeamMember(ctx, teamID, userID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddTeamInvitation(ctx context.Context, invitation models.TeamInvitation, userID string) (*models.TeamInvitation, error) {
+	ctx, span := startStorageSpan(ctx, "AddTeamInvitation", AttrTeamID.Int64(invitation.TeamID))
+	result, err := s.Storage.AddTeamInvitation(ctx, invitation, userID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListTeamInvitations(ctx context.Context, teamID int64) ([]*models.TeamInvitation, error) {
+	ctx, span := startStorageSpan(ctx, "ListTeamInvitations", AttrTeamID.Int64(teamID))
+	result, err := s.Storage.ListTeamInvitations(ctx, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListUserInvitations(ctx context.Context, login string) ([]*models.TeamInvitation, error) {
+	ctx, span := startStorageSpan(ctx, "ListUserInvitations")
+	result, err := s.Storage.ListUserInvitations(ctx, login)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DelTeamInvitation(ctx context.Context, teamID int64, invitationID int64) error {
+	ctx, span := startStorageSpan(ctx, "DelTeamInvitation", AttrTeamID.Int64(teamID))
+	err := s.Storage.DelTeamInvitation(ctx, teamID, invitationID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AcceptTeamInvitation(ctx context.Context, invitationID int64, userID string, login string) (*models.Team, error) {
+	ctx, span := startStorageSpan(ctx, "AcceptTeamInvitation")
+	result, err := s.Storage.AcceptTeamInvitation(ctx, invitationID, userID, login)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DeclineTeamInvitation(ctx context.Context, invitationID int64, login string) error {
+	ctx, span := startStorageSpan(ctx, "DeclineTeamInvitation")
+	err := s.Storage.DeclineTeamInvitation(ctx, invitationID, login)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) ListServicePermissions(ctx context.Context, serviceID int64) ([]*models.ServicePermission, error) {
+	ctx, span := startStorageSpan(ctx, "ListServicePermissions", AttrServiceID.Int64(serviceID))
+	permissions, err := s.Storage.ListServicePermissions(ctx, serviceID)
+	End(span, err)
+	return permissions, err
+}
+
+func (s *Storage) ListServerServicePermissions(ctx context.Context, serverID int64) ([]*models.ServicePermission, error) {
+	ctx, span := startStorageSpan(ctx, "ListServerServicePermissions", AttrServerID.Int64(serverID))
+	permissions, err := s.Storage.ListServerServicePermissions(ctx, serverID)
+	End(span, err)
+	return permissions, err
+}
+
+func (s *Storage) SetServicePermission(ctx context.Context, permission models.ServicePermission) (*models.ServicePermission, error) {
+	ctx, span := startStorageSpan(ctx, "SetServicePermission", AttrServiceID.Int64(permission.ServiceID))
+	saved, err := s.Storage.SetServicePermission(ctx, permission)
+	End(span, err)
+	return saved, err
+}
+
+func (s *Storage) DelServicePermission(ctx context.Context, serviceID int64, permissionID int64) error {
+	ctx, span := startStorageSpan(ctx, "DelServicePermission", AttrServiceID.Int64(serviceID))
+	err := s.Storage.DelServicePermission(ctx, serviceID, permissionID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) SetServiceCritical(ctx context.Context, serverID int64, serviceID int64, userID string, critical bool) error {
+	ctx, span := startStorageSpan(ctx, "SetServiceCritical", AttrServerID.Int64(serverID), AttrServiceID.Int64(serviceID))
+	err := s.Storage.SetServiceCritical(ctx, serverID, serviceID, userID, critical)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddControlApproval(ctx context.Context, approval models.ControlApproval) (*models.ControlApproval, error) {
+	ctx, span := startStorageSpan(ctx, "AddControlApproval", AttrServiceID.Int64(approval.ServiceID), attribute.String("swsm.action", approval.Action))
+	created, err := s.Storage.AddControlApproval(ctx, approval)
+	End(span, err)
+	return created, err
+}
+
+func (s *Storage) ListControlApprovals(ctx context.Context, userID string, status string) ([]*models.ControlApproval, error) {
+	ctx, span := startStorageSpan(ctx, "ListControlApprovals", AttrUserID.String(userID))
+	approvals, err := s.Storage.ListControlApprovals(ctx, userID, status)
+	End(span, err)
+	return approvals, err
+}
+
+func (s *Storage) GetControlApproval(ctx context.Context, approvalID int64, userID string) (*models.ControlApproval, error) {
+	ctx, span := startStorageSpan(ctx, "GetControlApproval", AttrApprovalID.Int64(approvalID))
+	approval, err := s.Storage.GetControlApproval(ctx, approvalID, userID)
+	End(span, err)
+	return approval, err
+}
+
+func (s *Storage) DecideControlApproval(ctx context.Context, approvalID int64, status string, userID string, login string, comment string) error {
+	ctx, span := startStorageSpan(ctx, "DecideControlApproval", AttrApprovalID.Int64(approvalID), attribute.String("swsm.approval.status", status))
+	err := s.Storage.DecideControlApproval(ctx, approvalID, status, userID, login, comment)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) FinishControlApproval(ctx context.Context, approvalID int64, status string, result string) error {
+	ctx, span := startStorageSpan(ctx, "FinishControlApproval", AttrApprovalID.Int64(approvalID), attribute.String("swsm.approval.status", status))
+	err := s.Storage.FinishControlApproval(ctx, approvalID, status, result)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddAPIToken(ctx context.Context, token models.APIToken, tokenHash string) (*models.APIToken, error) {
+	ctx, span := startStorageSpan(ctx, "AddAPIToken", AttrUserID.String(token.UserID))
+	created, err := s.Storage.AddAPIToken(ctx, token, tokenHash)
+	End(span, err)
+	return created, err
+}
+
+func (s *Storage) ListAPITokens(ctx context.Context, userID string) ([]*models.APIToken, error) {
+	ctx, span := startStorageSpan(ctx, "ListAPITokens", AttrUserID.String(userID))
+	tokens, err := s.Storage.ListAPITokens(ctx, userID)
+	End(span, err)
+	return tokens, err
+}
+
+func (s *Storage) DelAPIToken(ctx context.Context, tokenID int64, userID string) error {
+	ctx, span := startStorageSpan(ctx, "DelAPIToken", AttrUserID.String(userID))
+	err := s.Storage.DelAPIToken(ctx, tokenID, userID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) UseAPIToken(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	ctx, span := startStorageSpan(ctx, "UseAPIToken")
+	token, err := s.Storage.UseAPIToken(ctx, tokenHash)
+	End(span, err)
+	return token, err
+}
+
+func (s *Storage) CreateLocalUser(ctx context.Context, user *models.User, passwordHash string) error {
+	ctx, span := startStorageSpan(ctx, "CreateLocalUser", AttrUserID.String(user.ID))
+	err := s.Storage.CreateLocalUser(ctx, user, passwordHash)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) GetLocalUser(ctx context.Context, login string) (*models.LocalUser, error) {
+	ctx, span := startStorageSpan(ctx, "GetLocalUser")
+	user, err := s.Storage.GetLocalUser(ctx, login)
+	End(span, err)
+	return user, err
+}
+
+func (s *Storage) SetUserPassword(ctx context.Context, userID string, passwordHash string) error {
+	ctx, span := startStorageSpan(ctx, "SetUserPassword", AttrUserID.String(userID))
+	err := s.Storage.SetUserPassword(ctx, userID, passwordHash)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddRefreshToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error {
+	ctx, span := startStorageSpan(ctx, "AddRefreshToken", AttrUserID.String(userID))
+	err := s.Storage.AddRefreshToken(ctx, userID, tokenHash, expiresAt)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) UseRefreshToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	ctx, span := startStorageSpan(ctx, "UseRefreshToken")
+	user, err := s.Storage.UseRefreshToken(ctx, tokenHash)
+	End(span, err)
+	return user, err
+}
+
+func (s *Storage) DelRefreshToken(ctx context.Context, tokenHash string) error {
+	ctx, span := startStorageSpan(ctx, "DelRefreshToken")
+	err := s.Storage.DelRefreshToken(ctx, tokenHash)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddWebhookEvent(ctx context.Context, eventID string, expiresAt time.Time) error {
+	ctx, span := startStorageSpan(ctx, "AddWebhookEvent")
+	err := s.Storage.AddWebhookEvent(ctx, eventID, expiresAt)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) DelWebhookEvent(ctx context.Context, eventID string) error {
+	ctx, span := startStorageSpan(ctx, "DelWebhookEvent")
+	err := s.Storage.DelWebhookEvent(ctx, eventID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) AddSSETicket(ctx context.Context, ticketHash string, userID string, stream string, expiresAt time.Time) error {
+	ctx, span := startStorageSpan(ctx, "AddSSETicket", AttrUserID.String(userID))
+	err := s.Storage.AddSSETicket(ctx, ticketHash, userID, stream, expiresAt)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) UseSSETicket(ctx context.Context, ticketHash string, stream string) (string, error) {
+	ctx, span := startStorageSpan(ctx, "UseSSETicket")
+	userID, err := s.Storage.UseSSETicket(ctx, ticketHash, stream)
+	End(span, err)
+	return userID, err
+}
+
+func (s *Storage) AddCredentialProfile(ctx context.Context, profile models.CredentialProfile) (*models.CredentialProfile, error) {
+	ctx, span := startStorageSpan(ctx, "AddCredentialProfile", AttrTeamID.Int64(profile.TeamID))
+	result, err := s.Storage.AddCredentialProfile(ctx, profile)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) EditCredentialProfile(ctx context.Context, profile *models.CredentialProfile, profileID, teamID int64) (*models.CredentialProfile, error) {
+	ctx, span := startStorageSpan(ctx, "EditCredentialProfile", AttrProfileID.Int64(profileID), AttrTeamID.Int64(teamID))
+	result, err := s.Storage.EditCredentialProfile(ctx, profile, profileID, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) DelCredentialProfile(ctx context.Context, profileID, teamID int64) error {
+	ctx, span := startStorageSpan(ctx, "DelCredentialProfile", AttrProfileID.Int64(profileID), AttrTeamID.Int64(teamID))
+	err := s.Storage.DelCredentialProfile(ctx, profileID, teamID)
+	End(span, err)
+	return err
+}
+
+func (s *Storage) GetCredentialProfile(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error) {
+	ctx, span := startStorageSpan(ctx, "GetCredentialProfile", AttrProfileID.Int64(profileID), AttrTeamID.Int64(teamID))
+	result, err := s.Storage.GetCredentialProfile(ctx, profileID, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) GetCredentialProfileWithPassword(ctx context.Context, profileID, teamID int64) (*models.CredentialProfile, error) {
+	ctx, span := startStorageSpan(ctx, "GetCredentialProfileWithPassword", AttrProfileID.Int64(profileID), AttrTeamID.Int64(teamID))
+	result, err := s.Storage.GetCredentialProfileWithPassword(ctx, profileID, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListCredentialProfiles(ctx context.Context, teamID int64) ([]*models.CredentialProfile, error) {
+	ctx, span := startStorageSpan(ctx, "ListCredentialProfiles", AttrTeamID.Int64(teamID))
+	result, err := s.Storage.ListCredentialProfiles(ctx, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) ListCredentialProfileServers(ctx context.Context, profileID, teamID int64) ([]*models.Server, error) {
+	ctx, span := startStorageSpan(ctx, "ListCredentialProfileServers", AttrProfileID.Int64(profileID), AttrTeamID.Int64(teamID))
+	result, err := s.Storage.ListCredentialProfileServers(ctx, profileID, teamID)
+	End(span, err)
+	return result, err
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	ctx, span := startStorageSpan(ctx, "Ping")
+	err := s.Storage.Ping(ctx)
+	End(span, err)
+	return err
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+const tracerName = "github.com/trsv-dev/simple-windows-services-monitor"
+
+// Ключи атрибутов спанов, общие для всех компонентов.
+const (
+	AttrUserID        = attribute.Key("swsm.user.id")
+	AttrServerID      = attribute.Key("swsm.server.id")
+	AttrServiceID     = attribute.Key("swsm.service.id")
+	AttrServerAddress = attribute.Key("server.address")
+	AttrServiceName   = attribute.Key("swsm.service.name")
+	AttrTeamID        = attribute.Key("swsm.team.id")
+	AttrApprovalID    = attribute.Key("swsm.approval.id")
+	AttrProfileID     = attribute.Key("swsm.credential_profile.id")
+)
+
+// Config Настройки экспорта трассировок.
+type Config struct {
+	Endpoint    string  // адрес OTLP/HTTP коллектора: host:port или полный URL; пустая строка отключает экспорт
+	Insecure    bool    // отправка без TLS (локальный коллектор)
+	SampleRatio float64 // доля трассируемых запросов, от 0 до 1
+}
+
+// Init Инициализирует глобальный TracerProvider с экспортом по OTLP/HTTP.
+//
+// Если Endpoint не задан, остается провайдер по умолчанию (noop) — все спаны
+// создаются, но никуда не отправляются и почти ничего не стоят.
+// Возвращает функцию для корректной остановки провайдера с отправкой оставшихся спанов.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if strings.Contains(cfg.Endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания OTLP экспортера: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES из окружения имеют приоритет
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "swsm")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ресурса трассировки: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer Возвращает трейсер приложения.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start Начинает спан с атрибутами пользователя, сервера и службы из контекста запроса
+// (если они там есть) и дополнительными атрибутами.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(append(CredsAttributes(ctx), attrs...)...))
+}
+
+// End Завершает спан, отмечая в нем ошибку, если она есть.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// CredsAttributes Возвращает атрибуты пользователя, сервера и службы из контекста запроса.
+func CredsAttributes(ctx context.Context) []attribute.KeyValue {
+	creds := models.GetContextCreds(ctx)
+
+	var attrs []attribute.KeyValue
+
+	if creds.UserID != "" {
+		attrs = append(attrs, AttrUserID.String(creds.UserID))
+	}
+	if creds.ServerID != 0 {
+		attrs = append(attrs, AttrServerID.Int64(creds.ServerID))
+	}
+	if creds.ServiceID != 0 {
+		attrs = append(attrs, AttrServiceID.Int64(creds.ServiceID))
+	}
+
+	return attrs
+}
+
+// TraceID Возвращает идентификатор трассировки из контекста или пустую строку.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
@@ -0,0 +1,286 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/contextkeys"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	netutilsMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
+	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+func init() {
+	logger.InitLogger("error", "stdout")
+}
+
+// setupRecorder Устанавливает глобальный провайдер, сохраняющий завершенные спаны в памяти.
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// createContextWithCreds Создает контекст с данными пользователя, сервера и службы.
+func createContextWithCreds() context.Context {
+	ctx := context.WithValue(context.Background(), contextkeys.UserID, "user-1")
+	ctx = context.WithValue(ctx, contextkeys.ServerID, int64(1))
+	ctx = context.WithValue(ctx, contextkeys.ServiceID, int64(2))
+	return ctx
+}
+
+// spanAttributes Возвращает атрибуты спана в виде map.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// TestInit_Disabled Проверяет, что без адреса коллектора экспорт не настраивается.
+func TestInit_Disabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), Config{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+// TestInit_Enabled Проверяет инициализацию экспортера для локального коллектора.
+func TestInit_Enabled(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	for _, endpoint := range []string{"localhost:4318", "http://localhost:4318/v1/traces"} {
+		t.Run(endpoint, func(t *testing.T) {
+			shutdown, err := Init(context.Background(), Config{Endpoint: endpoint, Insecure: true, SampleRatio: 1})
+			require.NoError(t, err)
+
+			_, isSDK := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			assert.True(t, isSDK)
+
+			// спанов нет, поэтому остановка не обращается к коллектору
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			assert.NoError(t, shutdown(ctx))
+		})
+	}
+}
+
+// TestCredsAttributes Проверяет атрибуты из контекста запроса.
+func TestCredsAttributes(t *testing.T) {
+	assert.Empty(t, CredsAttributes(context.Background()))
+
+	attrs := CredsAttributes(createContextWithCreds())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		AttrUserID.String("user-1"),
+		AttrServerID.Int64(1),
+		AttrServiceID.Int64(2),
+	}, attrs)
+}
+
+// TestStorage Проверяет спаны вызовов хранилища.
+func TestStorage(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		expStatus codes.Code
+	}{
+		{"успешный вызов", nil, codes.Unset},
+		{"ошибка хранилища", errors.New("db error"), codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := setupRecorder(t)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockStorage.EXPECT().GetService(gomock.Any(), int64(1), int64(2), "user-1").
+				Return(&models.Service{ID: 2}, tt.err)
+
+			s := NewStorage(mockStorage)
+			_, err := s.GetService(createContextWithCreds(), 1, 2, "user-1")
+			assert.Equal(t, tt.err, err)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "storage.GetService", spans[0].Name())
+			assert.Equal(t, tt.expStatus, spans[0].Status().Code)
+
+			attrs := spanAttributes(spans[0])
+			assert.Equal(t, "postgresql", attrs["db.system"].AsString())
+			assert.Equal(t, int64(1), attrs[AttrServerID].AsInt64())
+			assert.Equal(t, int64(2), attrs[AttrServiceID].AsInt64())
+			assert.Equal(t, "user-1", attrs[AttrUserID].AsString())
+		})
+	}
+}
+
+// TestChecker Проверяет спаны сетевых проверок.
+func TestChecker(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockChecker := netutilsMocks.NewMockChecker(ctrl)
+	mockChecker.EXPECT().CheckWinRM(gomock.Any(), "10.0.0.1", "5985", false, time.Second).Return(false)
+	mockChecker.EXPECT().CheckICMP(gomock.Any(), "10.0.0.1", time.Second).Return(true)
+
+	c := NewChecker(mockChecker)
+	assert.False(t, c.CheckWinRM(context.Background(), "10.0.0.1", "5985", false, time.Second))
+	assert.True(t, c.CheckICMP(context.Background(), "10.0.0.1", time.Second))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "netutils.CheckWinRM", spans[0].Name())
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "10.0.0.1", attrs[AttrServerAddress].AsString())
+	assert.Equal(t, "5985", attrs["server.port"].AsString())
+	assert.False(t, attrs["swsm.probe.ok"].AsBool())
+
+	assert.Equal(t, "netutils.CheckICMP", spans[1].Name())
+	assert.True(t, spanAttributes(spans[1])["swsm.probe.ok"].AsBool())
+}
+
+// TestClientFactory Проверяет спаны выполнения WinRM команд.
+func TestClientFactory(t *testing.T) {
+	t.Run("ошибка создания клиента", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+		mockFactory.EXPECT().CreateClient("10.0.0.1", "admin", "pass", models.WinRMSettings{}).Return(nil, errors.New("winrm error"))
+
+		client, err := NewClientFactory(mockFactory).CreateClient("10.0.0.1", "admin", "pass", models.WinRMSettings{})
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("спан команды с атрибутами сервера и службы", func(t *testing.T) {
+		recorder := setupRecorder(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmdErr := errors.New("access denied")
+
+		mockClient := serviceControlMocks.NewMockClient(ctrl)
+		mockClient.EXPECT().RunCommand(gomock.Any(), `sc stop "spooler"`).Return("", cmdErr)
+
+		mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
+		mockFactory.EXPECT().CreateClient("10.0.0.1", "admin", "pass", models.WinRMSettings{}).Return(mockClient, nil)
+
+		client, err := NewClientFactory(mockFactory).CreateClient("10.0.0.1", "admin", "pass", models.WinRMSettings{})
+		require.NoError(t, err)
+
+		_, err = client.RunCommand(createContextWithCreds(), `sc stop "spooler"`)
+		assert.ErrorIs(t, err, cmdErr)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "winrm.RunCommand", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+		attrs := spanAttributes(spans[0])
+		assert.Equal(t, "10.0.0.1", attrs[AttrServerAddress].AsString())
+		assert.Equal(t, `sc stop "spooler"`, attrs["swsm.winrm.command"].AsString())
+		assert.Equal(t, "sc", attrs["swsm.winrm.program"].AsString())
+		assert.Equal(t, int64(1), attrs[AttrServerID].AsInt64())
+		assert.Equal(t, int64(2), attrs[AttrServiceID].AsInt64())
+	})
+}
+
+// TestTruncateCommand Проверяет обрезку длинных команд.
+func TestTruncateCommand(t *testing.T) {
+	assert.Equal(t, "sc query spooler", truncateCommand("sc query spooler"))
+
+	long := strings.Repeat("a", maxCommandLength+10)
+	assert.Equal(t, strings.Repeat("a", maxCommandLength)+"...", truncateCommand(long))
+
+	assert.Equal(t, "powershell", commandProgram("PowerShell -Command Get-Service"))
+	assert.Equal(t, "", commandProgram("  "))
+}
+
+// TestHTTPSpan Проверяет корневой спан запроса: имя по шаблону маршрута, статус и
+// продолжение внешней трассировки.
+func TestHTTPSpan(t *testing.T) {
+	recorder := setupRecorder(t)
+	_, err := Init(context.Background(), Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		status     int
+		expName    string
+		expCode    codes.Code
+		withParent bool
+	}{
+		{"успешный запрос", http.StatusOK, "GET /servers/{serverID}", codes.Unset, false},
+		{"ошибка сервера", http.StatusBadGateway, "GET /servers/{serverID}", codes.Error, false},
+		{"внешний traceparent", http.StatusOK, "GET /servers/{serverID}", codes.Unset, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Reset()
+
+			router := chi.NewRouter()
+			router.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ctx, span := StartHTTPSpan(r)
+					r = r.WithContext(ctx)
+					next.ServeHTTP(w, r)
+					EndHTTPSpan(span, r, tt.status)
+				})
+			})
+			router.Get("/servers/{serverID}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/servers/42", nil)
+			if tt.withParent {
+				r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			}
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.expName, spans[0].Name())
+			assert.Equal(t, tt.expCode, spans[0].Status().Code)
+
+			attrs := spanAttributes(spans[0])
+			assert.Equal(t, "/servers/{serverID}", attrs["http.route"].AsString())
+			assert.Equal(t, int64(tt.status), attrs["http.response.status_code"].AsInt64())
+
+			if tt.withParent {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+			}
+		})
+	}
+}
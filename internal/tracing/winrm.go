@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
+)
+
+// maxCommandLength Максимальная длина команды, сохраняемой в атрибуте спана.
+const maxCommandLength = 256
+
+// ClientFactory Фабрика WinRM клиентов, оборачивающих каждую команду в спан.
+type ClientFactory struct {
+	service_control.ClientFactory
+}
+
+// NewClientFactory Конструктор фабрики клиентов с трассировкой.
+func NewClientFactory(factory service_control.ClientFactory) *ClientFactory {
+	return &ClientFactory{ClientFactory: factory}
+}
+
+// CreateClient Создает клиента с трассировкой команд.
+func (f *ClientFactory) CreateClient(address, username, password string, settings models.WinRMSettings) (service_control.Client, error) {
+	client, err := f.ClientFactory.CreateClient(address, username, password, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{client: client, address: address}, nil
+}
+
+// Client WinRM клиент с трассировкой команд.
+type Client struct {
+	client  service_control.Client
+	address string
+}
+
+// RunCommand Выполняет команду в спане с адресом сервера и текстом команды.
+func (c *Client) RunCommand(ctx context.Context, cmd string) (string, error) {
+	ctx, span := Start(ctx, "winrm.RunCommand",
+		AttrServerAddress.String(c.address),
+		attribute.String("swsm.winrm.command", truncateCommand(displayCommand(cmd))),
+		attribute.String("swsm.winrm.program", commandProgram(cmd)),
+	)
+
+	out, err := c.client.RunCommand(ctx, cmd)
+	End(span, err)
+
+	return out, err
+}
+
+// displayCommand Возвращает текст команды для атрибута спана: для PowerShell с -EncodedCommand —
+// исходный скрипт вместо base64.
+func displayCommand(cmd string) string {
+	if script, ok := service_control.DecodePowerShellCommand(cmd); ok {
+		return script
+	}
+
+	return cmd
+}
+
+// truncateCommand Обрезает длинную команду (скрипты PowerShell) для атрибута спана.
+func truncateCommand(cmd string) string {
+	if len(cmd) <= maxCommandLength {
+		return cmd
+	}
+
+	return strings.ToValidUTF8(cmd[:maxCommandLength], "") + "..."
+}
+
+// commandProgram Возвращает имя запускаемой программы (sc, powershell и т.д.).
+func commandProgram(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/trsv-dev/simple-windows-services-monitor/internal/worker (interfaces: UserDirectory)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+// MockUserDirectory is a mock of UserDirectory interface.
+type MockUserDirectory struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserDirectoryMockRecorder
+}
+
+// MockUserDirectoryMockRecorder is the mock recorder for MockUserDirectory.
+type MockUserDirectoryMockRecorder struct {
+	mock *MockUserDirectory
+}
+
+// NewMockUserDirectory creates a new mock instance.
+func NewMockUserDirectory(ctrl *gomock.Controller) *MockUserDirectory {
+	mock := &MockUserDirectory{ctrl: ctrl}
+	mock.recorder = &MockUserDirectoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserDirectory) EXPECT() *MockUserDirectoryMockRecorder {
+	return m.recorder
+}
+
+// ListUsers mocks base method.
+func (m *MockUserDirectory) ListUsers(arg0 context.Context) ([]*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0)
+	ret0, _ := ret[0].([]*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserDirectoryMockRecorder) ListUsers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserDirectory)(nil).ListUsers), arg0)
+}
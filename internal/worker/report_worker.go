@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/notify"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// ReportWorker Фоновый воркер рассылки сводных отчетов пользователям.
+//
+// Отчеты формируются по истории статусов серверов и служб и по действиям управления службами
+// и отправляются через настроенные каналы уведомлений:
+//   - daily — каждый день в 00:00 (локальное время сервера) за прошедшие сутки,
+//   - weekly — каждый понедельник в 00:00 за прошедшую неделю.
+func ReportWorker(ctx context.Context,
+	storage storage.Storage,
+	builder *report.Builder,
+	notifier notify.Notifier,
+	period models.ReportPeriod,
+) {
+	for {
+		next := nextReportTime(time.Now(), period)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Log.Info("Завершение работы воркера ReportWorker по контексту", logger.String("info", ctx.Err().Error()))
+			return
+		case <-timer.C:
+			if err := sendReports(ctx, storage, builder, notifier, period, next); err != nil {
+				logger.Log.Error("ошибка воркера ReportWorker", logger.String("err", err.Error()))
+			}
+		}
+	}
+}
+
+// Вычисляет момент следующей рассылки отчетов.
+func nextReportTime(now time.Time, period models.ReportPeriod) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+
+	if period == models.ReportWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+
+	return next
+}
+
+// Формирует и отправляет отчеты всем пользователям, кроме отключенных.
+// Ошибка формирования или отправки отчета одного пользователя не прерывает рассылку остальным.
+func sendReports(ctx context.Context, storage storage.Storage, builder *report.Builder, notifier notify.Notifier, period models.ReportPeriod, to time.Time) error {
+	users, err := storage.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		// отключенным пользователям отчеты не отправляются
+		if user.Disabled {
+			continue
+		}
+
+		msg, err := buildReportMessage(ctx, builder, user, period, to)
+		if err != nil {
+			logger.Log.Error("ошибка формирования отчета пользователя",
+				logger.String("login", user.Login),
+				logger.String("err", err.Error()))
+			continue
+		}
+
+		if err = notifier.Notify(ctx, msg); err != nil {
+			logger.Log.Error("ошибка отправки отчета пользователя",
+				logger.String("login", user.Login),
+				logger.String("err", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// Формирует уведомление с отчетом: краткая сводка текстом, полный отчет в HTML и CSV-вложение.
+func buildReportMessage(ctx context.Context, builder *report.Builder, user *models.User, period models.ReportPeriod, to time.Time) (*notify.Message, error) {
+	rep, err := builder.Build(ctx, user, period, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var html, csv bytes.Buffer
+
+	if err = report.RenderHTML(&html, rep); err != nil {
+		return nil, err
+	}
+
+	if err = report.RenderCSV(&csv, rep); err != nil {
+		return nil, err
+	}
+
+	title := "ежедневный"
+	if period == models.ReportWeekly {
+		title = "еженедельный"
+	}
+
+	return &notify.Message{
+		Recipient: user.Email,
+		Subject:   fmt.Sprintf("SWSM: %s отчет для %s", title, user.Login),
+		Text: fmt.Sprintf("Серверов под мониторингом: %d, служб с простоями: %d, действий управления: %d",
+			rep.ServersMonitored, len(rep.ServiceOutages), countControlActions(rep.ControlActions)),
+		HTML: html.String(),
+		Attachments: []notify.Attachment{{
+			Name:        fmt.Sprintf("swsm-report-%s-%s.csv", period, to.Format("2006-01-02")),
+			ContentType: "text/csv; charset=utf-8",
+			Data:        csv.Bytes(),
+		}},
+	}, nil
+}
+
+func countControlActions(stats []models.ControlActionsStats) int {
+	var total int
+	for _, s := range stats {
+		total += s.Success + s.Failed
+	}
+
+	return total
+}
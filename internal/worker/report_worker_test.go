@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/notify"
+	notifyMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/notify/mocks"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/report"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+)
+
+// TestNextReportTime Проверяет вычисление момента следующей рассылки отчетов.
+func TestNextReportTime(t *testing.T) {
+	// 2025-01-01 — среда
+	wednesday := time.Date(2025, 1, 1, 15, 30, 0, 0, time.UTC)
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		period   models.ReportPeriod
+		expected time.Time
+	}{
+		{"daily - следующая полночь", wednesday, models.ReportDaily, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"weekly - ближайший понедельник", wednesday, models.ReportWeekly, monday},
+		{"weekly - ровно в понедельник полночь - следующий понедельник", monday, models.ReportWeekly, monday.AddDate(0, 0, 7)},
+		{"daily - ровно в полночь - следующие сутки", monday, models.ReportDaily, monday.AddDate(0, 0, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, nextReportTime(tt.now, tt.period))
+		})
+	}
+}
+
+// TestSendReports Проверяет формирование и отправку отчетов пользователям.
+func TestSendReports(t *testing.T) {
+	to := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	t.Run("ошибка получения пользователей", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := storageMocks.NewMockStorage(ctrl)
+		mockNotifier := notifyMocks.NewMockNotifier(ctrl)
+
+		mockStorage.EXPECT().ListUsers(gomock.Any()).Return(nil, errors.New("db error"))
+
+		err := sendReports(context.Background(), mockStorage, report.NewBuilder(mockStorage), mockNotifier, models.ReportDaily, to)
+		assert.Error(t, err)
+	})
+
+	t.Run("ошибка одного пользователя не прерывает рассылку", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := storageMocks.NewMockStorage(ctrl)
+		mockNotifier := notifyMocks.NewMockNotifier(ctrl)
+
+		mockStorage.EXPECT().ListUsers(gomock.Any()).Return([]*models.User{
+			{ID: "user-1", Login: "broken"},
+			{ID: "user-2", Login: "testuser", Email: "testuser@example.com"},
+		}, nil)
+
+		// у первого пользователя отчет не формируется
+		mockStorage.EXPECT().ListServers(gomock.Any(), "user-1").Return(nil, errors.New("db error"))
+
+		// у второго — формируется и отправляется
+		mockStorage.EXPECT().ListServers(gomock.Any(), "user-2").Return([]*models.Server{{ID: 1, Name: "srv1"}}, nil)
+		mockStorage.EXPECT().ListServerStatusHistory(gomock.Any(), "user-2", from, to).Return(nil, nil)
+		mockStorage.EXPECT().ListServiceStatusHistory(gomock.Any(), "user-2", from, to).Return(nil, nil)
+		mockStorage.EXPECT().ListControlActions(gomock.Any(), "user-2", from, to).Return([]*models.ControlAction{
+			{Action: models.ControlActionStart, Success: true},
+			{Action: models.ControlActionStop, Success: false},
+		}, nil)
+
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg *notify.Message) error {
+				assert.Equal(t, "testuser@example.com", msg.Recipient)
+				assert.Equal(t, "SWSM: ежедневный отчет для testuser", msg.Subject)
+				assert.Contains(t, msg.Text, "Серверов под мониторингом: 1")
+				assert.Contains(t, msg.Text, "действий управления: 2")
+				assert.Contains(t, msg.HTML, "srv1")
+				require.Len(t, msg.Attachments, 1)
+				assert.Equal(t, "swsm-report-daily-2025-01-02.csv", msg.Attachments[0].Name)
+				return errors.New("notify error")
+			})
+
+		err := sendReports(context.Background(), mockStorage, report.NewBuilder(mockStorage), mockNotifier, models.ReportDaily, to)
+		assert.NoError(t, err)
+	})
+
+	t.Run("отмена контекста", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := storageMocks.NewMockStorage(ctrl)
+		mockNotifier := notifyMocks.NewMockNotifier(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockStorage.EXPECT().ListUsers(gomock.Any()).Return([]*models.User{{ID: "user-1"}}, nil)
+
+		err := sendReports(ctx, mockStorage, report.NewBuilder(mockStorage), mockNotifier, models.ReportDaily, to)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestReportWorker_StopsOnContextCancel Проверяет завершение воркера по контексту.
+func TestReportWorker_StopsOnContextCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMocks.NewMockStorage(ctrl)
+	mockNotifier := notifyMocks.NewMockNotifier(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		ReportWorker(ctx, mockStorage, report.NewBuilder(mockStorage), mockNotifier, models.ReportDaily)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("воркер не завершился после отмены контекста")
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/netutils"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
 
@@ -16,11 +18,12 @@ import (
 // проверки сетевой доступности зарегистрированных серверов.
 //
 // Воркер с заданным интервалом:
-//   - получает список серверов (id и address) из хранилища,
-//   - проверяет доступность каждого сервера по сети (WinRM порт),
-//   - обновляет in-memory кэш статусов серверов.
+//   - получает список серверов (id, address и параметры WinRM) из хранилища,
+//   - проверяет доступность каждого сервера по сети (WinRM порт сервера),
+//   - обновляет in-memory кэш статусов серверов,
+//   - при смене статуса сервера записывает событие в историю статусов.
 //
-// Воркер не изменяет состояние базы данных и не содержит бизнес-логики.
+// Кроме истории статусов воркер не изменяет состояние базы данных и не содержит бизнес-логики.
 // Его задача - формирование и поддержание актуального состояния доступности
 // серверов для использования другими компонентами приложения (HTTP-хендлерами,
 // SSE-рассылкой и т.п.).
@@ -35,13 +38,13 @@ func ServerStatusWorker(ctx context.Context,
 	storage storage.WorkerStorage,
 	statusCache health_storage.StatusCacheStorage,
 	netChecker netutils.Checker,
-	winrmPort string,
+	winRMConfig *config.WinRMConfig,
 	interval time.Duration,
 	poolSize int,
 ) {
 	// создаем пул воркеров
 	workerFunc := func(ctx context.Context, serverStatus *models.ServerStatus) {
-		if checkServerErr := checkServerStatus(ctx, serverStatus, statusCache, netChecker, winrmPort); checkServerErr != nil {
+		if checkServerErr := checkServerStatus(ctx, serverStatus, storage, statusCache, netChecker, winRMConfig); checkServerErr != nil {
 			// проверяем доступность сервера и записываем статус
 			// если из checkServerStatus вернулась ошибка - пропускаем сервер
 			logger.Log.Debug("Ошибка проверки статуса сервера",
@@ -123,9 +126,17 @@ func ServerStatusWorker(ctx context.Context,
 }
 
 // Вычисление статуса сервера (CheckWinRM, CheckICMP) и запись модели статуса сервера в in-memory хранилище статусов.
-func checkServerStatus(ctx context.Context, server *models.ServerStatus, statusCache health_storage.StatusCacheStorage, netChecker netutils.Checker, winrmPort string) error {
+// WinRM проверяется с параметрами подключения сервера (порт, схема, таймаут проверки).
+// Если статус изменился, событие записывается в историю статусов серверов.
+func checkServerStatus(ctx context.Context, server *models.ServerStatus, storage storage.WorkerStorage, statusCache health_storage.StatusCacheStorage, netChecker netutils.Checker, winRMConfig *config.WinRMConfig) error {
 	// ограничиваем суммарное время проверки одного сервера
-	checkCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	// (с увеличенным таймаутом проверки WinRM, если он задан для сервера)
+	checkTimeout := 2 * time.Second
+	if connectTimeout := winRMConfig.ForServer(server.WinRM).ConnectTimeout; connectTimeout > 0 {
+		checkTimeout = netutils.DefaultHostTimeout + connectTimeout
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
 	defer cancel()
 
 	var status models.Status
@@ -136,7 +147,7 @@ func checkServerStatus(ctx context.Context, server *models.ServerStatus, statusC
 	if !icmpOK {
 		status = models.StatusUnreachable
 	} else {
-		winrmOK = netChecker.CheckWinRM(checkCtx, server.Address, winrmPort, 0)
+		winrmOK = service_control.IsWinRMAvailable(checkCtx, netChecker, winRMConfig, server.Address, server.WinRM)
 		if winrmOK {
 			status = models.StatusOK
 		} else {
@@ -148,8 +159,15 @@ func checkServerStatus(ctx context.Context, server *models.ServerStatus, statusC
 		logger.Log.Debug(fmt.Sprintf("Сервер %s, id=%d — %s (winrm=%v icmp=%v)", server.Address, server.ServerID, status, winrmOK, icmpOK))
 	}
 
-	serverStatus := models.ServerStatus{ServerID: server.ServerID, UserID: server.UserID, Address: server.Address, Status: status}
+	serverStatus := models.ServerStatus{ServerID: server.ServerID, UserID: server.UserID, TeamID: server.TeamID, Address: server.Address, Status: status}
+
+	if !statusCache.Set(serverStatus) {
+		return nil
+	}
+
+	if err := storage.AddServerStatusEvent(ctx, server.ServerID, status); err != nil {
+		return fmt.Errorf("ошибка записи истории статуса сервера: %w", err)
+	}
 
-	statusCache.Set(serverStatus)
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/config"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage/mocks"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	netutilsMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/netutils/mocks"
@@ -40,14 +41,14 @@ func TestServerStatusWorker(t *testing.T) {
 
 				// оба сервера доступны
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), "192.168.0.1", "5985", time.Duration(0)).
+					CheckWinRM(gomock.Any(), "192.168.0.1", "5985", false, time.Duration(0)).
 					Return(true)
 				ch.EXPECT().
 					CheckICMP(gomock.Any(), "192.168.0.1", time.Duration(0)).
 					Return(true)
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), "192.168.0.2", "5985", time.Duration(0)).
+					CheckWinRM(gomock.Any(), "192.168.0.2", "5985", false, time.Duration(0)).
 					Return(true)
 				ch.EXPECT().
 					CheckICMP(gomock.Any(), "192.168.0.2", time.Duration(0)).
@@ -78,7 +79,7 @@ func TestServerStatusWorker(t *testing.T) {
 					AnyTimes()
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(true).
 					AnyTimes()
 
@@ -140,7 +141,7 @@ func TestServerStatusWorker(t *testing.T) {
 					MinTimes(2)
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(true).
 					AnyTimes()
 
@@ -174,7 +175,7 @@ func TestServerStatusWorker(t *testing.T) {
 					Times(1)
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(true).
 					AnyTimes()
 
@@ -217,7 +218,7 @@ func TestServerStatusWorker(t *testing.T) {
 
 			// запускаем воркер в отдельной горутине
 			poolSize := 100
-			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, "5985", tt.interval, poolSize)
+			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, &config.WinRMConfig{Port: "5985"}, tt.interval, poolSize)
 
 			// ждём завершения воркера
 			<-ctx.Done()
@@ -289,7 +290,7 @@ func TestServerStatusWorker_AllStatuses(t *testing.T) {
 			// WinRM дергаем только если icmpOK == true
 			if tt.icmpOK {
 				mockChecker.EXPECT().
-					CheckWinRM(gomock.Any(), "192.168.0.1", "5985", time.Duration(0)).
+					CheckWinRM(gomock.Any(), "192.168.0.1", "5985", false, time.Duration(0)).
 					Return(tt.winrmOK)
 			}
 
@@ -306,7 +307,7 @@ func TestServerStatusWorker_AllStatuses(t *testing.T) {
 			defer cancel()
 
 			poolSize := 100
-			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, "5985", 100*time.Millisecond, poolSize)
+			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, &config.WinRMConfig{Port: "5985"}, 100*time.Millisecond, poolSize)
 
 			<-ctx.Done()
 			time.Sleep(50 * time.Millisecond)
@@ -343,7 +344,7 @@ func TestServerStatusWorker_RetryBehavior(t *testing.T) {
 				)
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(true).
 					AnyTimes()
 
@@ -373,7 +374,7 @@ func TestServerStatusWorker_RetryBehavior(t *testing.T) {
 					MinTimes(2)
 
 				ch.EXPECT().
-					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					CheckWinRM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(true).
 					AnyTimes()
 
@@ -405,7 +406,7 @@ func TestServerStatusWorker_RetryBehavior(t *testing.T) {
 			defer cancel()
 
 			poolSize := 100
-			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, "5985", 100*time.Millisecond, poolSize)
+			go ServerStatusWorker(ctx, mockStorage, mockCache, mockChecker, &config.WinRMConfig{Port: "5985"}, 100*time.Millisecond, poolSize)
 
 			<-ctx.Done()
 			time.Sleep(100 * time.Millisecond)
@@ -445,7 +446,7 @@ func TestCheckServerStatus_AllCombinations(t *testing.T) {
 
 			if tt.icmpOK {
 				checker.EXPECT().
-					CheckWinRM(gomock.Any(), "10.0.0.1", "5985", time.Duration(0)).
+					CheckWinRM(gomock.Any(), "10.0.0.1", "5985", false, time.Duration(0)).
 					Return(tt.winrmOK)
 			}
 
@@ -455,8 +456,51 @@ func TestCheckServerStatus_AllCombinations(t *testing.T) {
 					assert.Equal(t, tt.expectedStatus, st.Status)
 				})
 
-			err := checkServerStatus(context.Background(), srv, cache, checker, "5985")
+			err := checkServerStatus(context.Background(), srv, storageMocks.NewMockWorkerStorage(ctrl), cache, checker, &config.WinRMConfig{Port: "5985"})
 			assert.NoError(t, err)
 		})
 	}
 }
+
+// TestCheckServerStatus_RecordsHistory Проверяет запись истории при смене статуса сервера.
+func TestCheckServerStatus_RecordsHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		changed     bool
+		storageErr  error
+		expectError bool
+	}{
+		{"статус не изменился - история не пишется", false, nil, false},
+		{"статус изменился - событие записано", true, nil, false},
+		{"статус изменился - ошибка записи истории", true, errors.New("db error"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			checker := netutilsMocks.NewMockChecker(ctrl)
+			cache := mocks.NewMockStatusCacheStorage(ctrl)
+			storage := storageMocks.NewMockWorkerStorage(ctrl)
+
+			srv := &models.ServerStatus{ServerID: 7, Address: "10.0.0.7"}
+
+			checker.EXPECT().CheckICMP(gomock.Any(), "10.0.0.7", time.Duration(0)).Return(false)
+			cache.EXPECT().Set(gomock.Any()).Return(tt.changed)
+
+			if tt.changed {
+				storage.EXPECT().
+					AddServerStatusEvent(gomock.Any(), int64(7), models.StatusUnreachable).
+					Return(tt.storageErr)
+			}
+
+			err := checkServerStatus(context.Background(), srv, storage, cache, checker, &config.WinRMConfig{Port: "5985"})
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,33 +2,32 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
-	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/utils"
 )
 
 // ServiceStatusesChecker Структура ServiceStatusesChecker.
 type ServiceStatusesChecker struct {
 	clientFactory service_control.ClientFactory
+	manager       service_control.ServiceManager
 }
 
 // NewServiceStatusesChecker Конструктор ServiceStatusesChecker.
-func NewServiceStatusesChecker(clientFactory service_control.ClientFactory) *ServiceStatusesChecker {
+func NewServiceStatusesChecker(clientFactory service_control.ClientFactory, manager service_control.ServiceManager) *ServiceStatusesChecker {
 	return &ServiceStatusesChecker{
 		clientFactory: clientFactory,
+		manager:       manager,
 	}
 }
 
 // CheckServiceStatuses Получение с сервера статусов запрашиваемого слайса служб.
 func (cs ServiceStatusesChecker) CheckServiceStatuses(ctx context.Context, server *models.Server, services []*models.Service) ([]*models.Service, bool) {
 	// создаём WinRM клиент
-	client, err := cs.clientFactory.CreateClient(server.Address, server.Username, server.Password)
+	client, err := cs.clientFactory.CreateClient(server.Address, server.Username, server.Password, server.ConnectionSettings())
 
 	if err != nil {
 		logger.Log.Error("Ошибка создания WinRM клиента", logger.String("err", err.Error()))
@@ -40,82 +39,53 @@ func (cs ServiceStatusesChecker) CheckServiceStatuses(ctx context.Context, serve
 	winRMCtx, winRMCtxCancel := context.WithTimeout(ctx, timeout)
 	defer winRMCtxCancel()
 
-	// формируем слайс имён служб для PowerShell,
-	// PowerShell ожидает название службы в виде `service`
-	serviceNames := make([]string, len(services))
-	for i, svc := range services {
-		// экранируем на всякий случай
-		escaped := strings.ReplaceAll(svc.ServiceName, "'", "''")
-		serviceNames[i] = fmt.Sprintf("'%s'", escaped)
-	}
-
-	// PowerShell-запрос для получения всех служб одним запросом
-	psCmd := fmt.Sprintf(
-		`powershell.exe -NoProfile -NonInteractive -Command "& {Get-Service -Name @(%s) -ErrorAction SilentlyContinue | Select-Object Name, @{Name='Status';Expression={$_.Status.ToString()}} | ConvertTo-Json -Compress}"`,
-		strings.Join(serviceNames, ","),
-	)
+	// формируем слайс имён служб для PowerShell; службы с недопустимыми именами
+	// (добавленные до введения проверки имени) пропускаем, чтобы не блокировать проверку остальных
+	serviceNames := make([]string, 0, len(services))
+	for _, svc := range services {
+		if err := service_control.ValidateServiceName(svc.ServiceName); err != nil {
+			logger.Log.Warn("Служба с недопустимым именем пропущена при проверке статусов",
+				logger.Int64("serverID", server.ID), logger.String("err", err.Error()))
+			continue
+		}
 
-	// один PowerShell-запрос через WinRM для получения статусов нужных служб с сервера
-	result, err := client.RunCommand(winRMCtx, psCmd)
-	if err != nil {
-		logger.Log.Error("Ошибка выполнения PowerShell-команды на получение статусов служб", logger.String("err", err.Error()))
-		return nil, false
+		serviceNames = append(serviceNames, svc.ServiceName)
 	}
 
-	// на всякий случай убираем лишние пробелы
-	trimmed := strings.TrimSpace(result)
-
-	if trimmed == "" || trimmed == "[]" {
-		logger.Log.Warn("PowerShell вернул пустой вывод", logger.String("server", server.Address), logger.Int64("serverID", server.ID))
+	if len(serviceNames) == 0 {
 		return []*models.Service{}, true
 	}
 
-	// парсим JSON результат и обновляем службы
-	type powerShellService struct {
-		Name   string `json:"Name"`
-		Status string `json:"Status"`
+	// один запрос через WinRM для получения состояний нужных служб с сервера
+	states, err := cs.manager.QueryStates(winRMCtx, client, serviceNames)
+	if err != nil {
+		logger.Log.Error("Ошибка получения статусов служб", logger.String("err", err.Error()))
+		return nil, false
 	}
 
-	var psService powerShellService
-	var psServices []powerShellService
-
-	// если вернулся один объект - PowerShell возвращает его не как массив, а как `{}`
-	if strings.HasPrefix(trimmed, "{") {
-		if err := json.Unmarshal([]byte(trimmed), &psService); err != nil {
-			logger.Log.Error("Ошибка анмаршаллинга данных PowerShell-команды на получение статусов служб", logger.String("err", err.Error()))
-			return nil, false
-		}
-
-		psServices = append(psServices, psService)
-
-		// если вернулся массив объектов `[{}]`
-	} else if strings.HasPrefix(trimmed, "[") {
-		if err := json.Unmarshal([]byte(trimmed), &psServices); err != nil {
-			logger.Log.Error("Ошибка анмаршаллинга данных PowerShell-команды на получение статусов служб", logger.String("err", err.Error()))
-			return nil, false
-		}
+	if len(states) == 0 {
+		logger.Log.Warn("Сервер не вернул ни одной службы", logger.String("server", server.Address), logger.Int64("serverID", server.ID))
+		return []*models.Service{}, true
 	}
 
-	psMap := make(map[string]string, len(psServices))
-	for _, ps := range psServices {
+	statesMap := make(map[string]string, len(states))
+	for _, state := range states {
 		// Get-Service может вернуть Name в любом регистре, зависящем от внутреннего регистра в Windows,
 		// поэтому лучше привести возвращаемое имя службы к нижнему регистру, т.к. в БД у нас названия служб в нижнем регистре.
 		// Если этого не сделать, то возможно перестанет обновляться статус и время у некоторых служб,
 		// названия которых были возвращены Get-Service в смешанном регистре
-		psMap[strings.ToLower(ps.Name)] = ps.Status
+		statesMap[strings.ToLower(state.Name)] = state.Status
 	}
 
-	updates := make([]*models.Service, 0, len(psServices))
+	updates := make([]*models.Service, 0, len(states))
 
 	// обновляем статусы в исходном слайсе
 	updateTime := time.Now()
 	for _, svc := range services {
 		// хотя serviceName возвращается из базы в нижнем регистре, чтобы избежать неожиданного поведения,
 		// тут тоже переведем serviceName в нижний регистр
-		if status, ok := psMap[strings.ToLower(svc.ServiceName)]; ok {
-			// статус конвертируется для перевода на русский следующим образом:
-			// `Running -> ServiceRunning (в int-представлении, 1) -> Работает`
-			svc.Status = utils.GetStatusByINT(utils.GetStatus(status))
+		if status, ok := statesMap[strings.ToLower(svc.ServiceName)]; ok {
+			svc.Status = status
 			svc.UpdatedAt = updateTime
 			updates = append(updates, svc)
 		}
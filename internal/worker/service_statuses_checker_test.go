@@ -11,6 +11,7 @@ import (
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/service_control"
 	serviceControlMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/service_control/mocks"
 )
 
@@ -25,7 +26,7 @@ func TestNewServicesStatusesWorker(t *testing.T) {
 
 	mockFactory := serviceControlMocks.NewMockClientFactory(ctrl)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 
 	assert.NotNil(t, worker)
 }
@@ -51,17 +52,17 @@ func TestCheckServicesStatusesSuccess(t *testing.T) {
 		{ID: 2, ServiceName: "service2", Status: "unknown", UpdatedAt: time.Time{}},
 	}
 
-	psResponse := `[{"Name":"service1","Status":"Running"},{"Name":"service2","Status":"Stopped"}]`
+	psResponse := `[{"Name":"service1","State":4},{"Name":"service2","State":1}]`
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(psResponse, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	// реализация работает с мокированными зависимостями
@@ -71,6 +72,10 @@ func TestCheckServicesStatusesSuccess(t *testing.T) {
 	assert.Equal(t, 2, len(updates))
 	assert.NotNil(t, updates[0].UpdatedAt)
 	assert.NotNil(t, updates[1].UpdatedAt)
+
+	// числовое состояние службы переводится в описание статуса
+	assert.Equal(t, "Работает", updates[0].Status)
+	assert.Equal(t, "Остановлена", updates[1].Status)
 }
 
 // TestCheckServicesStatusesClientFactoryError Проверяет ошибку при создании клиента.
@@ -93,10 +98,10 @@ func TestCheckServicesStatusesClientFactoryError(t *testing.T) {
 	}
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(nil, errors.New("connection failed"))
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -126,14 +131,14 @@ func TestCheckServicesStatusesRunCommandError(t *testing.T) {
 	}
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("", errors.New("PowerShell error"))
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -163,14 +168,14 @@ func TestCheckServicesStatusesEmptyArrayResponse(t *testing.T) {
 	}
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("[]", nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -199,17 +204,17 @@ func TestCheckServicesStatusesSingleService(t *testing.T) {
 		{ID: 1, ServiceName: "service1", Status: "unknown", UpdatedAt: time.Time{}},
 	}
 
-	psResponse := `{"Name":"service1","Status":"Running"}`
+	psResponse := `{"Name":"service1","State":4}`
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(psResponse, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -238,17 +243,17 @@ func TestCheckServicesStatusesMixedCase(t *testing.T) {
 		{ID: 1, ServiceName: "myservice", Status: "unknown", UpdatedAt: time.Time{}},
 	}
 
-	psResponse := `{"Name":"MyService","Status":"Running"}`
+	psResponse := `{"Name":"MyService","State":4}`
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(psResponse, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -281,14 +286,14 @@ func TestCheckServicesStatusesUnmarshalError(t *testing.T) {
 	psResponse := `{invalid json}`
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(psResponse, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -318,14 +323,14 @@ func TestCheckServicesStatusesEmptyResponse(t *testing.T) {
 	}
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return("", nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -356,17 +361,17 @@ func TestCheckServicesStatusesMultipleServicesPartialMatch(t *testing.T) {
 		{ID: 3, ServiceName: "service3", Status: "unknown", UpdatedAt: time.Time{}},
 	}
 
-	psResponse := `[{"Name":"service1","Status":"Running"},{"Name":"service2","Status":"Stopped"}]`
+	psResponse := `[{"Name":"service1","State":4},{"Name":"service2","State":1}]`
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
 		RunCommand(gomock.Any(), gomock.Any()).
 		Return(psResponse, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
@@ -375,8 +380,9 @@ func TestCheckServicesStatusesMultipleServicesPartialMatch(t *testing.T) {
 	assert.Equal(t, 2, len(updates))
 }
 
-// TestCheckServicesStatusesServiceNameWithQuote Проверяет экранирование кавычек в имени службы.
-func TestCheckServicesStatusesServiceNameWithQuote(t *testing.T) {
+// TestCheckServicesStatusesSkipsInvalidServiceName Проверяет, что служба с недопустимым именем
+// (например, с кавычкой) не попадает в команду, а статусы остальных служб проверяются.
+func TestCheckServicesStatusesSkipsInvalidServiceName(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -393,23 +399,26 @@ func TestCheckServicesStatusesServiceNameWithQuote(t *testing.T) {
 
 	services := []*models.Service{
 		{ID: 1, ServiceName: "service'name", Status: "unknown", UpdatedAt: time.Time{}},
+		{ID: 2, ServiceName: "spooler", Status: "unknown", UpdatedAt: time.Time{}},
 	}
 
-	psResponse := `{"Name":"service'name","Status":"Running"}`
+	expectedCmd, err := service_control.ServiceStatesCommand([]string{"spooler"})
+	assert.NoError(t, err)
 
 	mockFactory.EXPECT().
-		CreateClient("192.168.1.100", "admin", "password").
+		CreateClient("192.168.1.100", "admin", "password", models.WinRMSettings{}).
 		Return(mockClient, nil)
 
 	mockClient.EXPECT().
-		RunCommand(gomock.Any(), gomock.Any()).
-		Return(psResponse, nil)
+		RunCommand(gomock.Any(), expectedCmd).
+		Return(`{"Name":"Spooler","State":4}`, nil)
 
-	worker := NewServiceStatusesChecker(mockFactory)
+	worker := NewServiceStatusesChecker(mockFactory, service_control.NewCIMServiceManager())
 	ctx := context.Background()
 
 	updates, success := worker.CheckServiceStatuses(ctx, server, services)
 
 	assert.True(t, success)
 	assert.Equal(t, 1, len(updates))
+	assert.Equal(t, int64(2), updates[0].ID)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/broadcast"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/health_storage"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
 )
 
@@ -33,7 +34,7 @@ func StatusBroadcastWorker(ctx context.Context, storage storage.Storage, statusC
 	}
 }
 
-// Получает все текущие статусы серверов каждого пользователя из in-memory БД и публикует их через Publisher.
+// Получает текущие статусы серверов команд каждого пользователя из in-memory БД и публикует их через Publisher.
 func publishServerStatuses(ctx context.Context, storage storage.Storage, statusCache health_storage.StatusCacheStorage, publisher broadcast.Broadcaster) error {
 	users, err := storage.ListUsers(ctx)
 	if err != nil {
@@ -41,7 +42,22 @@ func publishServerStatuses(ctx context.Context, storage storage.Storage, statusC
 	}
 
 	for _, user := range users {
-		statuses := statusCache.GetAllServerStatusesByUser(user.ID)
+		// серверы пользователя определяются по его командам, а не по автору сервера
+		servers, err := storage.ListServers(ctx, user.ID)
+		if err != nil {
+			logger.Log.Error("ошибка получения серверов пользователя",
+				logger.String("login", user.Login),
+				logger.String("err", err.Error()))
+			continue
+		}
+
+		var statuses []models.ServerStatus
+
+		for _, server := range servers {
+			if status, ok := statusCache.Get(server.ID); ok {
+				statuses = append(statuses, status)
+			}
+		}
 
 		b, err := json.Marshal(statuses)
 		if err != nil {
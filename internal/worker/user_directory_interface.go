@@ -0,0 +1,14 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+)
+
+//go:generate mockgen -destination=mocks/mock_user_directory.go -package=mocks . UserDirectory
+
+// UserDirectory Интерфейс внешнего каталога пользователей (Keycloak), с которым сверяется БД SWSM.
+type UserDirectory interface {
+	ListUsers(ctx context.Context) ([]*models.User, error)
+}
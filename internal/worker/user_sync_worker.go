@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/storage"
+)
+
+// UserSyncWorker Фоновый воркер сверки пользователей БД SWSM с Keycloak.
+//
+// Исправляет расхождения, возникшие из-за пропущенных вебхуков: сразу после запуска
+// и далее с заданным интервалом
+//   - создает пользователей, которых нет в БД (кроме отключенных),
+//   - обновляет логин, email и признак отключения,
+//   - удаляет пользователей, которых больше нет в Keycloak.
+func UserSyncWorker(ctx context.Context, storage storage.UserStorage, directory UserDirectory, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := syncUsers(ctx, storage, directory); err != nil {
+			logger.Log.Error("ошибка воркера UserSyncWorker", logger.String("err", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Завершение работы воркера UserSyncWorker по контексту", logger.String("info", ctx.Err().Error()))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Сверяет пользователей БД с каталогом. Ошибка по одному пользователю не прерывает сверку остальных.
+func syncUsers(ctx context.Context, storage storage.UserStorage, directory UserDirectory) error {
+	// БД читается первой: пользователь, зарегистрированный между запросами, окажется только
+	// в каталоге и не будет удален как отсутствующий в Keycloak
+	dbUsers, err := storage.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось получить пользователей БД: %w", err)
+	}
+
+	dirUsers, err := directory.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось получить пользователей каталога: %w", err)
+	}
+
+	// пустой каталог при непустой БД скорее говорит об ошибке настройки (другой realm, нет прав),
+	// чем об удалении всех пользователей
+	if len(dirUsers) == 0 && len(dbUsers) > 0 {
+		return fmt.Errorf("каталог вернул пустой список пользователей, сверка пропущена")
+	}
+
+	known := make(map[string]*models.User, len(dbUsers))
+	for _, user := range dbUsers {
+		known[user.ID] = user
+	}
+
+	var created, updated, deleted int
+
+	for _, dirUser := range dirUsers {
+		dbUser, ok := known[dirUser.ID]
+		delete(known, dirUser.ID)
+
+		switch {
+		case !ok && dirUser.Disabled:
+			// отключенный пользователь не может войти, создавать его незачем
+			continue
+		case !ok:
+			if err = storage.CreateUser(ctx, &models.User{ID: dirUser.ID, Login: dirUser.Login, Email: dirUser.Email}); err != nil {
+				// пользователь мог быть создан вебхуком после чтения БД, иначе логин занят другим пользователем
+				logSyncError("создания", dirUser, err)
+				continue
+			}
+			created++
+		case *dbUser != *dirUser:
+			if err = storage.UpdateUser(ctx, dirUser); err != nil {
+				logSyncError("обновления", dirUser, err)
+				continue
+			}
+			updated++
+		}
+	}
+
+	// оставшихся пользователей в Keycloak нет
+	for _, dbUser := range known {
+		if err = storage.DeleteUser(ctx, dbUser.ID); err != nil {
+			var errNotFound *errs.ErrUserIDNotFound
+			if !errors.As(err, &errNotFound) {
+				logSyncError("удаления", dbUser, err)
+			}
+			continue
+		}
+		deleted++
+	}
+
+	if created+updated+deleted > 0 {
+		logger.Log.Info("Пользователи сверены с Keycloak",
+			logger.Int("created", created),
+			logger.Int("updated", updated),
+			logger.Int("deleted", deleted))
+	}
+
+	return nil
+}
+
+func logSyncError(operation string, user *models.User, err error) {
+	logger.Log.Warn("Ошибка "+operation+" пользователя при сверке с Keycloak",
+		logger.String("userID", user.ID),
+		logger.String("login", user.Login),
+		logger.String("err", err.Error()))
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/errs"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
+	storageMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/storage/mocks"
+	workerMocks "github.com/trsv-dev/simple-windows-services-monitor/internal/worker/mocks"
+)
+
+// TestSyncUsers Проверяет сверку пользователей БД с каталогом Keycloak.
+func TestSyncUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		dbUsers   []*models.User
+		dirUsers  []*models.User
+		dirErr    error
+		setupMock func(m *storageMocks.MockStorage)
+		wantErr   bool
+	}{
+		{
+			name:    "расхождений нет",
+			dbUsers: []*models.User{{ID: "user-1", Login: "alice"}},
+			dirUsers: []*models.User{
+				{ID: "user-1", Login: "alice"},
+				{ID: "user-2", Login: "bob", Disabled: true}, // отключенный пользователь не создается
+			},
+			setupMock: func(m *storageMocks.MockStorage) {},
+		},
+		{
+			name:     "пропущенная регистрация",
+			dbUsers:  []*models.User{},
+			dirUsers: []*models.User{{ID: "user-1", Login: "alice", Email: "alice@example.com"}},
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().CreateUser(gomock.Any(), &models.User{ID: "user-1", Login: "alice", Email: "alice@example.com"}).Return(nil)
+			},
+		},
+		{
+			name:     "смена логина и отключение",
+			dbUsers:  []*models.User{{ID: "user-1", Login: "alice"}, {ID: "user-2", Login: "bob"}},
+			dirUsers: []*models.User{{ID: "user-1", Login: "alice.smith"}, {ID: "user-2", Login: "bob", Disabled: true}},
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), &models.User{ID: "user-1", Login: "alice.smith"}).Return(nil)
+				m.EXPECT().UpdateUser(gomock.Any(), &models.User{ID: "user-2", Login: "bob", Disabled: true}).Return(nil)
+			},
+		},
+		{
+			name:     "пропущенное удаление",
+			dbUsers:  []*models.User{{ID: "user-1", Login: "alice"}, {ID: "user-2", Login: "bob"}},
+			dirUsers: []*models.User{{ID: "user-1", Login: "alice"}},
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().DeleteUser(gomock.Any(), "user-2").Return(nil)
+			},
+		},
+		{
+			name:     "ошибка по одному пользователю не прерывает сверку",
+			dbUsers:  []*models.User{{ID: "user-1", Login: "alice"}, {ID: "user-3", Login: "carol"}},
+			dirUsers: []*models.User{{ID: "user-1", Login: "taken"}, {ID: "user-2", Login: "bob"}},
+			setupMock: func(m *storageMocks.MockStorage) {
+				m.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(errs.NewErrUserAlreadyExists("taken", errors.New("duplicate")))
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().DeleteUser(gomock.Any(), "user-3").Return(errs.NewErrUserIDNotFound("user-3"))
+			},
+		},
+		{
+			name:      "пустой каталог при непустой БД",
+			dbUsers:   []*models.User{{ID: "user-1", Login: "alice"}},
+			dirUsers:  []*models.User{},
+			setupMock: func(m *storageMocks.MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name:      "ошибка каталога",
+			dbUsers:   []*models.User{{ID: "user-1", Login: "alice"}},
+			dirErr:    errors.New("keycloak unavailable"),
+			setupMock: func(m *storageMocks.MockStorage) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storageMocks.NewMockStorage(ctrl)
+			mockDirectory := workerMocks.NewMockUserDirectory(ctrl)
+
+			mockStorage.EXPECT().ListUsers(gomock.Any()).Return(tt.dbUsers, nil)
+			mockDirectory.EXPECT().ListUsers(gomock.Any()).Return(tt.dirUsers, tt.dirErr)
+			tt.setupMock(mockStorage)
+
+			err := syncUsers(context.Background(), mockStorage, mockDirectory)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("ошибка чтения БД", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStorage := storageMocks.NewMockStorage(ctrl)
+		mockDirectory := workerMocks.NewMockUserDirectory(ctrl)
+
+		mockStorage.EXPECT().ListUsers(gomock.Any()).Return(nil, errors.New("db error"))
+
+		assert.Error(t, syncUsers(context.Background(), mockStorage, mockDirectory))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
@@ -56,14 +57,17 @@ func (wp *StatusWorkerPool) Stop() {
 func (wp *StatusWorkerPool) Submit(server *models.ServerStatus) bool {
 	// проверяем, не закрыл ли уже канал (не вызван ли уже Stop())
 	if wp.closed.Load() {
+		metrics.WorkerPoolSkipped.WithLabelValues("closed").Inc()
 		return false
 	}
 
 	select {
 	case wp.tasks <- server:
+		metrics.WorkerPoolQueueDepth.Set(float64(len(wp.tasks)))
 		return true
 	default:
 		// очередь переполнена, пропускаем задачу
+		metrics.WorkerPoolSkipped.WithLabelValues("full").Inc()
 		return false
 	}
 }
@@ -83,6 +87,7 @@ func (wp *StatusWorkerPool) worker(ctx context.Context, id int) {
 				return
 			}
 
+			metrics.WorkerPoolQueueDepth.Set(float64(len(wp.tasks)))
 			wp.workerFunc(ctx, server)
 		}
 	}
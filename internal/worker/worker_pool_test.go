@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/logger"
+	"github.com/trsv-dev/simple-windows-services-monitor/internal/metrics"
 	"github.com/trsv-dev/simple-windows-services-monitor/internal/models"
 )
 
@@ -150,6 +152,8 @@ func TestStatusWorkerPool_Submit_QueueFull(t *testing.T) {
 	successCount := 0
 	failCount := 0
 
+	skippedBefore := testutil.ToFloat64(metrics.WorkerPoolSkipped.WithLabelValues("full"))
+
 	for i := 0; i < maxQueueSize+5; i++ {
 		serverStatus := &models.ServerStatus{
 			ServerID: int64(i),
@@ -166,6 +170,10 @@ func TestStatusWorkerPool_Submit_QueueFull(t *testing.T) {
 	assert.Equal(t, maxQueueSize, successCount)
 	assert.Equal(t, 5, failCount) // лишние задачи не поместились
 
+	// пропущенные задачи учтены в метриках, очередь заполнена
+	assert.Equal(t, skippedBefore+5, testutil.ToFloat64(metrics.WorkerPoolSkipped.WithLabelValues("full")))
+	assert.Equal(t, float64(maxQueueSize), testutil.ToFloat64(metrics.WorkerPoolQueueDepth))
+
 	close(blockCh)
 	pool.Stop()
 }
@@ -353,9 +361,12 @@ func TestStatusWorkerPool_SubmitAfterStop(t *testing.T) {
 		Address:  "192.168.0.1",
 	}
 
+	skippedBefore := testutil.ToFloat64(metrics.WorkerPoolSkipped.WithLabelValues("closed"))
+
 	// должно быть false, так как канал закрыт
 	result := pool.Submit(serverStatus)
 	assert.False(t, result)
+	assert.Equal(t, skippedBefore+1, testutil.ToFloat64(metrics.WorkerPoolSkipped.WithLabelValues("closed")))
 }
 
 // TestStatusWorkerPool_WorkerFuncError Проверяет обработку ошибок в workerFunc.
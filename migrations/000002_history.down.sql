@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS control_actions;
+DROP TRIGGER IF EXISTS trg_services_status_update ON services;
+DROP TRIGGER IF EXISTS trg_services_status_insert ON services;
+DROP FUNCTION IF EXISTS log_service_status_change();
+DROP TABLE IF EXISTS service_status_history;
+DROP TABLE IF EXISTS server_status_history;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
@@ -0,0 +1,54 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(250);
+
+CREATE TABLE IF NOT EXISTS server_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    server_id BIGINT NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_server_status_history_server_changed ON server_status_history(server_id, changed_at);
+
+CREATE TABLE IF NOT EXISTS service_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    service_id BIGINT NOT NULL,
+    status VARCHAR(250) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_status_history_service_changed ON service_status_history(service_id, changed_at);
+
+-- История статусов служб ведется триггером, чтобы не дублировать запись
+-- во всех местах, где меняется services.status (AddService, ChangeServiceStatus, BatchChangeServiceStatus)
+CREATE OR REPLACE FUNCTION log_service_status_change() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO service_status_history (service_id, status) VALUES (NEW.id, NEW.status);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_services_status_insert
+    AFTER INSERT ON services
+    FOR EACH ROW EXECUTE FUNCTION log_service_status_change();
+
+CREATE TRIGGER trg_services_status_update
+    AFTER UPDATE OF status ON services
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION log_service_status_change();
+
+CREATE TABLE IF NOT EXISTS control_actions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    server_id BIGINT NOT NULL,
+    service_id BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_control_actions_user_created ON control_actions(user_id, created_at);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS control_actions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    server_id BIGINT NOT NULL,
+    service_id BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_control_actions_user_created ON control_actions(user_id, created_at);
+
+INSERT INTO control_actions (user_id, server_id, service_id, action, success, created_at)
+SELECT a.user_id, a.server_id, a.service_id, a.action, a.success, a.created_at
+FROM audit_log a
+JOIN users u ON u.id = a.user_id
+JOIN services sv ON sv.id = a.service_id AND sv.server_id = a.server_id
+WHERE a.action IN ('start', 'stop', 'restart')
+ORDER BY a.id;
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_forbid_change();
+DROP TABLE IF EXISTS audit_log;
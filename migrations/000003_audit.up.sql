@@ -0,0 +1,39 @@
+-- Журнал аудита действий пользователей. Записи только добавляются:
+-- журнал не ссылается на серверы и службы внешними ключами, чтобы записи
+-- сохранялись после их удаления.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    login VARCHAR(250) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    server_id BIGINT,
+    service_id BIGINT,
+    target VARCHAR(500) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_user_created ON audit_log(user_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+
+CREATE OR REPLACE FUNCTION audit_log_forbid_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_forbid_change();
+
+-- действия управления службами теперь хранятся в журнале аудита
+INSERT INTO audit_log (user_id, login, action, server_id, service_id, success, created_at)
+SELECT ca.user_id, COALESCE(u.login, ''), ca.action, ca.server_id, ca.service_id, ca.success, ca.created_at
+FROM control_actions ca
+LEFT JOIN users u ON u.id = ca.user_id
+ORDER BY ca.id;
+
+DROP TABLE IF EXISTS control_actions;
@@ -0,0 +1,26 @@
+-- серверы без автора нельзя вернуть пользователю - удаляем их
+DELETE FROM servers WHERE user_id IS NULL;
+
+ALTER TABLE servers DROP CONSTRAINT servers_user_id_fkey;
+ALTER TABLE servers ADD CONSTRAINT servers_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE servers ALTER COLUMN user_id SET NOT NULL;
+
+-- дубликаты, добавленные одним пользователем в разные команды, не совместимы с прежним ограничением
+DELETE FROM servers s USING servers d
+WHERE s.user_id = d.user_id AND s.fingerprint = d.fingerprint AND s.id > d.id;
+
+ALTER TABLE servers DROP CONSTRAINT unique_team_fingerprint;
+ALTER TABLE servers ADD CONSTRAINT unique_user_fingerprint UNIQUE (user_id, fingerprint);
+
+DROP INDEX IF EXISTS idx_servers_team_id;
+ALTER TABLE servers DROP CONSTRAINT servers_team_id_fkey;
+ALTER TABLE servers DROP COLUMN team_id;
+
+DROP TRIGGER IF EXISTS trg_users_delete_teams ON users;
+DROP FUNCTION IF EXISTS delete_user_teams();
+DROP TRIGGER IF EXISTS trg_users_create_team ON users;
+DROP FUNCTION IF EXISTS create_user_team();
+
+DROP TABLE IF EXISTS team_invitations;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
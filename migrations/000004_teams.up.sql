@@ -0,0 +1,87 @@
+-- Команды (рабочие пространства). Серверы и службы принадлежат команде и видны всем ее участникам.
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(250) NOT NULL,
+    created_by VARCHAR(250),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Участники команд и их роли в команде (viewer, operator, admin)
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id BIGINT NOT NULL,
+    user_id VARCHAR(250) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_team_members_user_id ON team_members(user_id);
+
+-- Приглашения в команду по логину пользователя
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL,
+    login VARCHAR(250) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    invited_by VARCHAR(250),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT unique_team_invitation UNIQUE (team_id, login)
+);
+
+CREATE INDEX idx_team_invitations_login ON team_invitations(login);
+
+-- Каждому пользователю при регистрации создается собственная команда, в которой он администратор.
+-- Создание ведется триггером, чтобы команда появлялась при любом способе создания пользователя.
+CREATE OR REPLACE FUNCTION create_user_team() RETURNS TRIGGER AS $$
+DECLARE
+    new_team_id BIGINT;
+BEGIN
+    INSERT INTO teams (name, created_by) VALUES (NEW.login, NEW.id) RETURNING id INTO new_team_id;
+    INSERT INTO team_members (team_id, user_id, role) VALUES (new_team_id, NEW.id, 'admin');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_create_team
+    AFTER INSERT ON users
+    FOR EACH ROW EXECUTE FUNCTION create_user_team();
+
+-- При удалении пользователя удаляются команды, в которых он был единственным участником
+-- (вместе с их серверами), как раньше удалялись серверы пользователя.
+CREATE OR REPLACE FUNCTION delete_user_teams() RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM teams t
+    WHERE EXISTS (SELECT 1 FROM team_members m WHERE m.team_id = t.id AND m.user_id = OLD.id)
+      AND NOT EXISTS (SELECT 1 FROM team_members m WHERE m.team_id = t.id AND m.user_id <> OLD.id);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_delete_teams
+    BEFORE DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION delete_user_teams();
+
+-- Перенос существующих данных: каждому пользователю - собственная команда с его серверами
+INSERT INTO teams (name, created_by) SELECT login, id FROM users ORDER BY created_at, id;
+INSERT INTO team_members (team_id, user_id, role) SELECT id, created_by, 'admin' FROM teams;
+
+ALTER TABLE servers ADD COLUMN team_id BIGINT;
+UPDATE servers s SET team_id = t.id FROM teams t WHERE t.created_by = s.user_id;
+ALTER TABLE servers ALTER COLUMN team_id SET NOT NULL;
+ALTER TABLE servers ADD CONSTRAINT servers_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE;
+CREATE INDEX idx_servers_team_id ON servers(team_id);
+
+-- сервер добавляется в команду один раз
+ALTER TABLE servers DROP CONSTRAINT unique_user_fingerprint;
+ALTER TABLE servers ADD CONSTRAINT unique_team_fingerprint UNIQUE (team_id, fingerprint);
+
+-- user_id теперь хранит автора сервера: сервер остается в команде после удаления автора
+ALTER TABLE servers ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE servers DROP CONSTRAINT servers_user_id_fkey;
+ALTER TABLE servers ADD CONSTRAINT servers_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
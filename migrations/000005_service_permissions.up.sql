@@ -0,0 +1,19 @@
+-- Правила доступа к управлению отдельными службами. Правило выдается либо пользователю,
+-- либо роли (и всем ролям выше нее). Если у службы нет ни одного правила, управлять ею
+-- может любой участник команды с ролью operator и выше.
+CREATE TABLE IF NOT EXISTS service_permissions (
+    id BIGSERIAL PRIMARY KEY,
+    service_id BIGINT NOT NULL,
+    user_id VARCHAR(250),
+    role VARCHAR(50),
+    can_start BOOLEAN NOT NULL DEFAULT FALSE,
+    can_stop BOOLEAN NOT NULL DEFAULT FALSE,
+    can_restart BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT service_permissions_subject_check CHECK ((user_id IS NULL) <> (role IS NULL))
+);
+
+CREATE UNIQUE INDEX unique_service_permission_user ON service_permissions(service_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX unique_service_permission_role ON service_permissions(service_id, role) WHERE role IS NOT NULL;
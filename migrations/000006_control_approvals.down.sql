@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS control_approvals;
+ALTER TABLE services DROP COLUMN IF EXISTS critical;
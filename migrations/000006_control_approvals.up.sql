@@ -0,0 +1,31 @@
+-- Критичные службы: остановка и перезапуск выполняются только после подтверждения другим пользователем
+ALTER TABLE services ADD COLUMN critical BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Запросы на подтверждение действий с критичными службами.
+-- Статусы: pending, approved, rejected, executed, failed, expired.
+CREATE TABLE IF NOT EXISTS control_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    server_id BIGINT NOT NULL,
+    service_id BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(250),
+    requested_by_login VARCHAR(250) NOT NULL DEFAULT '',
+    request_comment TEXT NOT NULL DEFAULT '',
+    decided_by VARCHAR(250),
+    decided_by_login VARCHAR(250) NOT NULL DEFAULT '',
+    decision_comment TEXT NOT NULL DEFAULT '',
+    result TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE,
+    FOREIGN KEY (requested_by) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (decided_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_control_approvals_server_id ON control_approvals(server_id);
+
+-- на одно действие со службой может ожидать подтверждения только один запрос
+CREATE UNIQUE INDEX unique_pending_control_approval ON control_approvals(service_id, action) WHERE status = 'pending';
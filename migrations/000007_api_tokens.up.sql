@@ -0,0 +1,28 @@
+-- Персональные API-токены пользователей для автоматизации (CI/CD).
+-- Хранится только хэш токена, сам токен показывается пользователю один раз при создании.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    name VARCHAR(250) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_api_token_hash UNIQUE (token_hash),
+    CONSTRAINT unique_user_api_token_name UNIQUE (user_id, name)
+);
+
+-- Серверы, к которым ограничен доступ токена. Нет строк - доступны все серверы пользователя.
+CREATE TABLE IF NOT EXISTS api_token_servers (
+    token_id BIGINT NOT NULL,
+    server_id BIGINT NOT NULL,
+    PRIMARY KEY (token_id, server_id),
+    FOREIGN KEY (token_id) REFERENCES api_tokens(id) ON DELETE CASCADE,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+-- действие, выполненное с API-токеном, помечается его id (без внешнего ключа: журнал хранит записи и после отзыва токена)
+ALTER TABLE audit_log ADD COLUMN api_token_id BIGINT;
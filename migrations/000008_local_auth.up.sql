@@ -0,0 +1,17 @@
+-- Пароль пользователя для встроенной аутентификации (AUTH_PROVIDER=local), хэш bcrypt.
+-- У пользователей внешнего провайдера (Keycloak, OIDC) пароль не задан.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+-- Refresh-токены встроенной аутентификации. Хранится только хэш токена,
+-- при обновлении пары токенов использованный refresh-токен удаляется.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_refresh_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
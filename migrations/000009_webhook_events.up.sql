@@ -0,0 +1,10 @@
+-- Идентификаторы обработанных событий Keycloak. Повторно доставленное (или перехваченное и
+-- отправленное повторно) событие с тем же идентификатором не обрабатывается.
+-- Записи старше expires_at удаляются: такие события отклоняются по времени события.
+CREATE TABLE IF NOT EXISTS webhook_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_webhook_events_expires_at ON webhook_events(expires_at);
@@ -0,0 +1,2 @@
+-- Пользователь, отключенный в Keycloak: запросы к API отклоняются, данные сохраняются.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sse_tickets;
@@ -0,0 +1,12 @@
+-- Одноразовые билеты для подключения к SSE. Билет выдается аутентифицированному пользователю
+-- на один поток (servers / services / approvals), хранится только его хэш.
+-- Использованный билет удаляется, записи старше expires_at удаляются при выдаче новых билетов.
+CREATE TABLE IF NOT EXISTS sse_tickets (
+    ticket_hash VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(250) NOT NULL,
+    stream VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sse_tickets_expires_at ON sse_tickets(expires_at);
@@ -0,0 +1,6 @@
+ALTER TABLE servers
+    DROP COLUMN IF EXISTS winrm_port,
+    DROP COLUMN IF EXISTS winrm_https,
+    DROP COLUMN IF EXISTS winrm_insecure,
+    DROP COLUMN IF EXISTS winrm_timeout,
+    DROP COLUMN IF EXISTS winrm_connect_timeout;
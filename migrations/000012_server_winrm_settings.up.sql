@@ -0,0 +1,7 @@
+-- Параметры подключения к серверу по WinRM. NULL - используется значение из глобальной конфигурации.
+ALTER TABLE servers
+    ADD COLUMN IF NOT EXISTS winrm_port INTEGER CHECK (winrm_port BETWEEN 1 AND 65535),
+    ADD COLUMN IF NOT EXISTS winrm_https BOOLEAN,
+    ADD COLUMN IF NOT EXISTS winrm_insecure BOOLEAN,
+    ADD COLUMN IF NOT EXISTS winrm_timeout INTEGER CHECK (winrm_timeout > 0),
+    ADD COLUMN IF NOT EXISTS winrm_connect_timeout INTEGER CHECK (winrm_connect_timeout > 0);
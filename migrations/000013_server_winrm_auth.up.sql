@@ -0,0 +1,3 @@
+-- Механизм аутентификации WinRM для сервера. NULL - используется значение из глобальной конфигурации.
+ALTER TABLE servers
+    ADD COLUMN IF NOT EXISTS winrm_auth TEXT CHECK (winrm_auth IN ('basic', 'ntlm', 'kerberos'));
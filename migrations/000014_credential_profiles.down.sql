@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_servers_credential_profile_id;
+ALTER TABLE servers DROP COLUMN IF EXISTS credential_profile_id;
+DROP TABLE IF EXISTS credential_profiles;
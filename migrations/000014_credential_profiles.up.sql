@@ -0,0 +1,22 @@
+-- Профили учетных данных команды, общие для нескольких серверов.
+-- Пароль хранится зашифрованным (AES), смена пароля профиля применяется ко всем связанным серверам.
+CREATE TABLE IF NOT EXISTS credential_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL,
+    name VARCHAR(250) NOT NULL,
+    username VARCHAR(250) NOT NULL,
+    password TEXT NOT NULL DEFAULT '',
+    domain VARCHAR(250) NOT NULL DEFAULT '',
+    auth TEXT CHECK (auth IN ('basic', 'ntlm', 'kerberos')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    CONSTRAINT unique_team_credential_profile_name UNIQUE (team_id, name)
+);
+
+-- Сервер, связанный с профилем, использует его логин и пароль вместо собственных.
+-- Профиль, к которому привязаны серверы, удалить нельзя (NO ACTION, а не RESTRICT: проверка в конце запроса
+-- не мешает каскадному удалению команды вместе с ее серверами и профилями).
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS credential_profile_id BIGINT REFERENCES credential_profiles(id) ON DELETE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS idx_servers_credential_profile_id ON servers(credential_profile_id);
@@ -0,0 +1,2 @@
+ALTER TABLE credential_profiles DROP COLUMN IF EXISTS password_key_id;
+ALTER TABLE servers DROP COLUMN IF EXISTS password_key_id;
@@ -0,0 +1,7 @@
+-- Версия AES-ключа, которым зашифрован пароль (NULL, если пароль не задан).
+-- Пароли, сохраненные до появления версий ключей, зашифрованы ключом версии 1.
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS password_key_id INTEGER;
+UPDATE servers SET password_key_id = 1 WHERE password <> '';
+
+ALTER TABLE credential_profiles ADD COLUMN IF NOT EXISTS password_key_id INTEGER;
+UPDATE credential_profiles SET password_key_id = 1 WHERE password <> '';
@@ -0,0 +1,3 @@
+-- Путь к секрету во внешнем хранилище (HashiCorp Vault KV v2), из которого берутся логин и пароль сервера.
+-- Пароль такого сервера в БД не хранится.
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS secret_path TEXT;
@@ -0,0 +1,7 @@
+-- Явный признак ограничения API-токена списком серверов: после удаления последнего из них
+-- токен не должен получать доступ ко всем серверам пользователя.
+ALTER TABLE api_tokens ADD COLUMN IF NOT EXISTS restricted BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE api_tokens SET restricted = TRUE WHERE id IN (SELECT token_id FROM api_token_servers);
+
+-- Роль пользователя, определенная при последнем интерактивном входе. Ограничивает роль его API-токенов.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20);